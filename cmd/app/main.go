@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"github.com/playconomy/wallet-service/docs"
+	"github.com/playconomy/wallet-service/internal/cli"
 	"github.com/playconomy/wallet-service/internal/module"
 	
 	"go.uber.org/fx"
@@ -46,6 +47,15 @@ import (
 //	@description				User role for authentication
 
 func main() {
+	// Run a CLI subcommand instead of the server when one is given
+	args, err := cli.ParseGlobalFlags(os.Args[1:])
+	if err != nil {
+		os.Exit(cli.ExitUsage)
+	}
+	if len(args) > 0 {
+		os.Exit(cli.Run(args))
+	}
+
 	// Programmatically set swagger info
 	docs.SwaggerInfo.Title = "Wallet Service API"
 	docs.SwaggerInfo.Description = "This is a wallet service API for managing platform tokens"
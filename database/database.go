@@ -2,28 +2,72 @@ package database
 
 import (
 	"database/sql"
+	"fmt"
+	"time"
 
 	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
 
 	_ "github.com/lib/pq"
 	"go.uber.org/fx"
+	"go.uber.org/zap"
 )
 
 // Module provides database dependencies
 var Module = fx.Options(
 	fx.Provide(NewConnection),
+	fx.Provide(NewReplicas),
 )
 
-func NewConnection(cfg *config.Config) (*sql.DB, error) {
+// NewConnection opens the connection pool and waits for the database to accept connections, retrying
+// with exponential backoff so the service survives starting before the database
+func NewConnection(cfg *config.Config, obs *observability.Observability) (*sql.DB, error) {
 	db, err := sql.Open("postgres", cfg.Database.GetDSN())
 	if err != nil {
 		return nil, err
 	}
 
-	err = db.Ping()
-	if err != nil {
-		return nil, err
+	db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+	logger := obs.Logger.With(zap.String("component", "database"))
+	for attempt := 0; ; attempt++ {
+		err = db.Ping()
+		if err == nil {
+			break
+		}
+
+		if attempt >= cfg.Database.ConnectRetries {
+			db.Close()
+			return nil, fmt.Errorf("connect to database after %d attempts: %w", attempt+1, err)
+		}
+
+		delay := connectRetryDelay(attempt, cfg.Database.ConnectRetryDelay, cfg.Database.ConnectRetryMaxDelay)
+		logger.Warn("Database not reachable, retrying",
+			zap.Int("attempt", attempt+1),
+			zap.Duration("retry_in", delay),
+			zap.Error(err))
+		time.Sleep(delay)
+	}
+
+	if obs.Metrics != nil {
+		obs.Metrics.MonitorDBPool(db)
 	}
 
 	return db, nil
 }
+
+// connectRetryDelay returns the wait before retry attempt+1: initial doubled for each earlier retry,
+// capped at maxDelay
+func connectRetryDelay(attempt int, initial, maxDelay time.Duration) time.Duration {
+	delay := initial
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		return maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,51 @@
+package database
+
+import (
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConnectRetryDelay(t *testing.T) {
+	testCases := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 0, expected: time.Second},
+		{attempt: 1, expected: 2 * time.Second},
+		{attempt: 3, expected: 8 * time.Second},
+		{attempt: 5, expected: 30 * time.Second},
+		{attempt: 100, expected: 30 * time.Second},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, connectRetryDelay(tc.attempt, time.Second, 30*time.Second), "attempt %d", tc.attempt)
+	}
+}
+
+func TestLatestMigrationVersion(t *testing.T) {
+	version, err := LatestMigrationVersion()
+	assert.NoError(t, err)
+	assert.GreaterOrEqual(t, version, 17)
+}
+
+func TestReplicaDatabaseConfig(t *testing.T) {
+	primary := config.DatabaseConfig{Host: "db-primary", Port: 5432, User: "wallet", DBName: "wallet_db"}
+
+	replica, err := replicaDatabaseConfig(primary, "db-replica-1")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-replica-1", replica.Host)
+	assert.Equal(t, 5432, replica.Port)
+	assert.Equal(t, "wallet", replica.User)
+
+	replica, err = replicaDatabaseConfig(primary, "db-replica-2:6432")
+	assert.NoError(t, err)
+	assert.Equal(t, "db-replica-2", replica.Host)
+	assert.Equal(t, 6432, replica.Port)
+
+	_, err = replicaDatabaseConfig(primary, "db-replica-3:replication")
+	assert.ErrorContains(t, err, "invalid port")
+}
@@ -0,0 +1,37 @@
+-- Wallets flagged by reconciliation are frozen until an admin reviews them
+ALTER TABLE wallets ADD COLUMN frozen BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- Reconciliation runs table
+CREATE TABLE reconciliation_runs (
+    id SERIAL PRIMARY KEY,
+    trigger VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    wallets_checked INT NOT NULL DEFAULT 0,
+    mismatches INT NOT NULL DEFAULT 0,
+    total_drift NUMERIC(20, 2) NOT NULL DEFAULT 0,
+    error TEXT,
+    started_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- Reconciliation mismatches table
+CREATE TABLE reconciliation_mismatches (
+    id SERIAL PRIMARY KEY,
+    run_id INT NOT NULL,
+    wallet_id INT NOT NULL,
+    user_id INT NOT NULL,
+    balance NUMERIC(20, 2) NOT NULL,
+    ledger_balance NUMERIC(20, 2) NOT NULL,
+    drift NUMERIC(20, 2) NOT NULL,
+    frozen BOOLEAN NOT NULL DEFAULT FALSE,
+    resolved_by INT,
+    resolved_at TIMESTAMP,
+    resolution_note TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (run_id) REFERENCES reconciliation_runs(id),
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX idx_wallet_logs_wallet_id ON wallet_logs (wallet_id);
+CREATE INDEX idx_reconciliation_mismatches_run_id ON reconciliation_mismatches (run_id);
+CREATE INDEX idx_reconciliation_mismatches_unresolved ON reconciliation_mismatches (wallet_id) WHERE resolved_at IS NULL;
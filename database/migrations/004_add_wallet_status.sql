@@ -0,0 +1,26 @@
+-- Wallet status replaces the reconciliation frozen flag
+ALTER TABLE wallets ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'active';
+ALTER TABLE wallets ADD COLUMN status_reason TEXT;
+ALTER TABLE wallets ADD COLUMN status_changed_at TIMESTAMP;
+
+UPDATE wallets
+SET status = 'frozen', status_reason = 'reconciliation drift detected', status_changed_at = CURRENT_TIMESTAMP
+WHERE frozen;
+
+ALTER TABLE wallets DROP COLUMN frozen;
+ALTER TABLE wallets ADD CONSTRAINT wallets_status_check CHECK (status IN ('active', 'frozen', 'closed'));
+
+-- Wallet status history table
+CREATE TABLE wallet_status_changes (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL,
+    user_id INT NOT NULL,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    changed_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+);
+
+CREATE INDEX idx_wallet_status_changes_user_id ON wallet_status_changes (user_id, created_at);
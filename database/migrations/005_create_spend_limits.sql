@@ -0,0 +1,14 @@
+-- Per-user spend limit overrides; NULL columns fall back to the configured defaults
+CREATE TABLE spend_limit_overrides (
+    user_id INT PRIMARY KEY,
+    max_per_transaction NUMERIC(20, 2) CHECK (max_per_transaction >= 0),
+    daily_cap NUMERIC(20, 2) CHECK (daily_cap >= 0),
+    weekly_cap NUMERIC(20, 2) CHECK (weekly_cap >= 0),
+    max_spends_per_minute INT CHECK (max_spends_per_minute >= 0),
+    reason TEXT NOT NULL,
+    updated_by INT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Spend usage is summed over recent debits of a user
+CREATE INDEX idx_wallet_logs_user_debits ON wallet_logs (user_id, created_at) WHERE platform_amount < 0;
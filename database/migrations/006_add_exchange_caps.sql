@@ -0,0 +1,7 @@
+-- Exchange caps in game tokens, stored with the rate; NULL means unlimited
+ALTER TABLE exchange_rates ADD COLUMN max_per_transaction NUMERIC(20, 2) CHECK (max_per_transaction >= 0);
+ALTER TABLE exchange_rates ADD COLUMN user_daily_cap NUMERIC(20, 2) CHECK (user_daily_cap >= 0);
+ALTER TABLE exchange_rates ADD COLUMN game_daily_cap NUMERIC(20, 2) CHECK (game_daily_cap >= 0);
+
+-- Exchange usage is summed over recent exchanges of a game token
+CREATE INDEX idx_wallet_logs_game_exchanges ON wallet_logs (game_id, token_type, created_at) WHERE source = 'exchange';
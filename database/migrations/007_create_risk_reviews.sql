@@ -0,0 +1,21 @@
+-- Operations held for manual review by the risk evaluator
+CREATE TABLE risk_reviews (
+    id SERIAL PRIMARY KEY,
+    operation VARCHAR(20) NOT NULL,
+    user_id INT NOT NULL,
+    principal_id INT NOT NULL,
+    principal_role VARCHAR(20) NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL,
+    platform_amount NUMERIC(20, 2) NOT NULL,
+    matched_rules TEXT NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'approved', 'rejected', 'failed')),
+    reviewed_by INT,
+    reviewed_at TIMESTAMP,
+    review_note TEXT,
+    result_error TEXT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_risk_reviews_status ON risk_reviews (status, created_at);
+CREATE INDEX idx_risk_reviews_user_id ON risk_reviews (user_id);
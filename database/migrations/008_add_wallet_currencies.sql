@@ -0,0 +1,12 @@
+-- Users hold one wallet per platform currency; existing balances are in the default currency
+ALTER TABLE wallets ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'platform';
+ALTER TABLE wallets DROP CONSTRAINT wallets_user_id_key;
+ALTER TABLE wallets ADD CONSTRAINT wallets_user_id_currency_key UNIQUE (user_id, currency);
+
+-- Each log entry records the currency of the wallet it changed
+ALTER TABLE wallet_logs ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'platform';
+
+-- A game token may be exchanged into several currencies, each at its own rate
+ALTER TABLE exchange_rates ADD COLUMN currency VARCHAR(32) NOT NULL DEFAULT 'platform';
+ALTER TABLE exchange_rates DROP CONSTRAINT exchange_rates_game_id_token_type_key;
+ALTER TABLE exchange_rates ADD CONSTRAINT exchange_rates_game_id_token_type_currency_key UNIQUE (game_id, token_type, currency);
@@ -0,0 +1,24 @@
+-- Balance lots split each wallet balance by origin; the remaining amounts of a wallet's lots sum up to its balance
+CREATE TABLE wallet_balance_lots (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets(id),
+    amount NUMERIC(20, 2) NOT NULL CHECK (amount > 0),
+    remaining NUMERIC(20, 2) NOT NULL CHECK (remaining >= 0),
+    source VARCHAR(20) NOT NULL,
+    reference_id VARCHAR(50),
+    expires_at TIMESTAMP,
+    expired_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Spends consume the open lots of a wallet, expiring ones first
+CREATE INDEX idx_wallet_balance_lots_open ON wallet_balance_lots (wallet_id, expires_at) WHERE remaining > 0;
+
+-- The expiry job looks up open lots that are past their expiry date
+CREATE INDEX idx_wallet_balance_lots_due ON wallet_balance_lots (expires_at) WHERE remaining > 0 AND expires_at IS NOT NULL;
+
+-- Existing balances become lots that never expire
+INSERT INTO wallet_balance_lots (wallet_id, amount, remaining, source)
+SELECT id, balance, balance, 'exchange'
+FROM wallets
+WHERE balance > 0;
@@ -0,0 +1,28 @@
+-- Game tokens granted per platform token when cashing platform tokens back; NULL disables the reverse direction
+ALTER TABLE exchange_rates ADD COLUMN from_platform_ratio NUMERIC(10, 4) CHECK (from_platform_ratio > 0);
+
+-- Reverse exchange caps in platform tokens; NULL means unlimited
+ALTER TABLE exchange_rates ADD COLUMN reverse_max_per_transaction NUMERIC(20, 2) CHECK (reverse_max_per_transaction >= 0);
+ALTER TABLE exchange_rates ADD COLUMN reverse_user_daily_cap NUMERIC(20, 2) CHECK (reverse_user_daily_cap >= 0);
+
+-- Reverse exchange usage is summed over recent reverse exchanges of a user
+CREATE INDEX idx_wallet_logs_reverse_exchanges ON wallet_logs (user_id, game_id, token_type, created_at) WHERE source = 'reverse_exchange';
+
+-- Signed grants of game tokens issued by reverse exchanges; each grant can be redeemed once before it expires
+CREATE TABLE reverse_exchange_grants (
+    id SERIAL PRIMARY KEY,
+    grant_id VARCHAR(64) UNIQUE NOT NULL,
+    wallet_id INT NOT NULL REFERENCES wallets(id),
+    user_id INT NOT NULL,
+    game_id VARCHAR(50) NOT NULL,
+    token_type VARCHAR(20) NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    platform_amount NUMERIC(20, 2) NOT NULL,
+    game_amount NUMERIC(20, 2) NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    redeemed_at TIMESTAMP,
+    redeemed_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_reverse_exchange_grants_user_id ON reverse_exchange_grants (user_id, created_at);
@@ -0,0 +1,29 @@
+-- Idempotency keys of bulk items; a key is claimed in the transaction that applies its item
+CREATE TABLE idempotency_keys (
+    key VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    operation VARCHAR(20) NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL,
+    new_balance NUMERIC(20, 2),
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Bulk operations processed in the background
+CREATE TABLE bulk_jobs (
+    id SERIAL PRIMARY KEY,
+    status VARCHAR(20) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'running', 'completed', 'failed')),
+    total_items INT NOT NULL,
+    succeeded INT NOT NULL DEFAULT 0,
+    failed INT NOT NULL DEFAULT 0,
+    duplicates INT NOT NULL DEFAULT 0,
+    request JSONB NOT NULL,
+    results JSONB,
+    error TEXT,
+    requested_by INT NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+CREATE INDEX idx_bulk_jobs_status ON bulk_jobs (status);
@@ -0,0 +1,27 @@
+-- Background jobs run by the job workers. A worker leases a job by setting locked_by and locked_until and
+-- extends the lease with heartbeats; a running job whose lease ran out is picked up again by another worker.
+CREATE TABLE jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(50) NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'queued' CHECK (status IN ('queued', 'running', 'succeeded', 'failed', 'cancelled')),
+    payload JSONB NOT NULL,
+    result JSONB,
+    error TEXT,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL CHECK (max_attempts > 0),
+    run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_by VARCHAR(100),
+    locked_until TIMESTAMP,
+    heartbeat_at TIMESTAMP,
+    cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+    created_by INT,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    started_at TIMESTAMP,
+    finished_at TIMESTAMP
+);
+
+-- Workers look up queued jobs that are due and running jobs whose lease ran out
+CREATE INDEX idx_jobs_runnable ON jobs (run_at, id) WHERE status IN ('queued', 'running');
+
+-- The admin job list filters by kind and status, newest first
+CREATE INDEX idx_jobs_kind_status ON jobs (kind, status, created_at DESC);
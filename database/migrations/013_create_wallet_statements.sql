@@ -0,0 +1,32 @@
+-- Monthly wallet statements. A statement is generated once its month has ended and never changes afterwards;
+-- its entries are copied from wallet_logs so it stays readable when old logs are removed.
+CREATE TABLE wallet_statements (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets(id),
+    user_id INT NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    period CHAR(7) NOT NULL,
+    period_start TIMESTAMP NOT NULL,
+    period_end TIMESTAMP NOT NULL,
+    opening_balance NUMERIC(20, 2) NOT NULL,
+    closing_balance NUMERIC(20, 2) NOT NULL,
+    total_credits NUMERIC(20, 2) NOT NULL,
+    total_debits NUMERIC(20, 2) NOT NULL,
+    entry_count INT NOT NULL,
+    entries JSONB NOT NULL,
+    generated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wallet_id, period)
+);
+
+CREATE FUNCTION reject_wallet_statement_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'wallet statements are immutable';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallet_statements_immutable
+    BEFORE UPDATE OR DELETE ON wallet_statements
+    FOR EACH ROW EXECUTE FUNCTION reject_wallet_statement_change();
+
+-- Statements sum up a wallet's logs before and within a month
+CREATE INDEX idx_wallet_logs_wallet_created ON wallet_logs (wallet_id, created_at);
@@ -0,0 +1,12 @@
+-- Periodic balance snapshots. A snapshot holds the sum of a wallet's logs created up to taken_at, so the balance
+-- at any later point is the snapshot plus the logs after it.
+CREATE TABLE wallet_balance_snapshots (
+    id SERIAL PRIMARY KEY,
+    wallet_id INT NOT NULL REFERENCES wallets(id),
+    user_id INT NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    balance NUMERIC(20, 2) NOT NULL,
+    taken_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wallet_id, taken_at)
+);
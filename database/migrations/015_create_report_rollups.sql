@@ -0,0 +1,35 @@
+-- Daily rollups of wallet_logs for the reporting API, maintained incrementally by the rollup job.
+-- game_id and token_type are '' for logs without them so they can be part of the key.
+CREATE TABLE report_daily_activity (
+    day DATE NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    game_id VARCHAR(50) NOT NULL DEFAULT '',
+    token_type VARCHAR(20) NOT NULL DEFAULT '',
+    log_count INT NOT NULL,
+    amount NUMERIC(20, 2) NOT NULL,
+    platform_credits NUMERIC(20, 2) NOT NULL,
+    platform_debits NUMERIC(20, 2) NOT NULL,
+    PRIMARY KEY (day, currency, source, game_id, token_type)
+);
+
+-- Wallets with at least one log per day, counted by the active wallet report
+CREATE TABLE report_daily_active_wallets (
+    day DATE NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    wallet_id INT NOT NULL,
+    PRIMARY KEY (day, currency, wallet_id)
+);
+
+-- Position of the rollup job in wallet_logs; logs up to last_log_id are part of the rollups
+CREATE TABLE report_rollup_state (
+    name VARCHAR(50) PRIMARY KEY,
+    last_log_id INT NOT NULL DEFAULT 0,
+    last_log_at TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO report_rollup_state (name) VALUES ('wallet_logs');
+
+-- The top wallet report lists the largest balances of a currency
+CREATE INDEX idx_wallets_currency_balance ON wallets (currency, balance DESC);
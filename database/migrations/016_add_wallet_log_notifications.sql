@@ -0,0 +1,19 @@
+-- Every new wallet log is announced on the wallet_events channel once its transaction commits, so balance
+-- streams can push it without polling. The payload only identifies the log; listeners read it from the table.
+CREATE FUNCTION notify_wallet_log() RETURNS trigger AS $$
+BEGIN
+    PERFORM pg_notify('wallet_events', json_build_object(
+        'log_id', NEW.id,
+        'user_id', NEW.user_id,
+        'wallet_id', NEW.wallet_id
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallet_logs_notify
+    AFTER INSERT ON wallet_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_wallet_log();
+
+-- Streams replay a user's logs after the last one a client received
+CREATE INDEX idx_wallet_logs_user_id_id ON wallet_logs (user_id, id);
@@ -0,0 +1,35 @@
+-- Append-only audit trail of privileged calls. Every entry carries the hash of the entry before it, so
+-- changing or removing an entry breaks the chain from that point on.
+CREATE TABLE admin_audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    principal_id INT NOT NULL,
+    principal_role VARCHAR(20) NOT NULL,
+    action VARCHAR(150) NOT NULL,
+    method VARCHAR(10) NOT NULL,
+    path TEXT NOT NULL,
+    target_user_id INT,
+    payload_hash CHAR(64) NOT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    status_code INT NOT NULL,
+    outcome VARCHAR(20) NOT NULL,
+    created_at TIMESTAMP NOT NULL,
+    prev_hash CHAR(64) NOT NULL,
+    hash CHAR(64) NOT NULL UNIQUE
+);
+
+CREATE FUNCTION reject_admin_audit_log_change() RETURNS trigger AS $$
+BEGIN
+    RAISE EXCEPTION 'the admin audit log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER admin_audit_log_append_only
+    BEFORE UPDATE OR DELETE ON admin_audit_log
+    FOR EACH ROW EXECUTE FUNCTION reject_admin_audit_log_change();
+
+CREATE TRIGGER admin_audit_log_no_truncate
+    BEFORE TRUNCATE ON admin_audit_log
+    FOR EACH STATEMENT EXECUTE FUNCTION reject_admin_audit_log_change();
+
+CREATE INDEX idx_admin_audit_log_principal ON admin_audit_log (principal_id, id);
+CREATE INDEX idx_admin_audit_log_target ON admin_audit_log (target_user_id, id);
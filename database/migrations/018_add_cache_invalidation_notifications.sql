@@ -0,0 +1,54 @@
+-- Changes of wallets and exchange rates are announced on the cache_invalidations channel once their
+-- transaction commits, so every instance drops its cached copies, including changes made outside the service.
+CREATE FUNCTION notify_wallet_change() RETURNS trigger AS $$
+DECLARE
+    changed_user_id INTEGER;
+BEGIN
+    IF TG_OP = 'DELETE' THEN
+        changed_user_id := OLD.user_id;
+    ELSE
+        changed_user_id := NEW.user_id;
+    END IF;
+
+    PERFORM pg_notify('cache_invalidations', json_build_object(
+        'table', 'wallets',
+        'user_id', changed_user_id
+    )::text);
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER wallets_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON wallets
+    FOR EACH ROW EXECUTE FUNCTION notify_wallet_change();
+
+-- Rates are cached by ID and by game, token type and currency; an update announces the old and the new
+-- identifiers so a rate moved to another game or currency is dropped under both
+CREATE FUNCTION notify_exchange_rate_change() RETURNS trigger AS $$
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        PERFORM pg_notify('cache_invalidations', json_build_object(
+            'table', 'exchange_rates',
+            'rate_id', OLD.id,
+            'game_id', OLD.game_id,
+            'token_type', OLD.token_type,
+            'currency', OLD.currency
+        )::text);
+    END IF;
+
+    IF TG_OP <> 'DELETE' THEN
+        PERFORM pg_notify('cache_invalidations', json_build_object(
+            'table', 'exchange_rates',
+            'rate_id', NEW.id,
+            'game_id', NEW.game_id,
+            'token_type', NEW.token_type,
+            'currency', NEW.currency
+        )::text);
+    END IF;
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER exchange_rates_notify_change
+    AFTER INSERT OR UPDATE OR DELETE ON exchange_rates
+    FOR EACH ROW EXECUTE FUNCTION notify_exchange_rate_change();
@@ -0,0 +1,94 @@
+-- wallet_logs becomes a table partitioned by the month of created_at. The service creates the partitions of the
+-- coming months ahead of time and archives the partitions past the retention period; logs outside every monthly
+-- partition land in wallet_logs_default until their month's partition is created.
+
+-- reference_id has always been written by the service but was never added by a migration
+ALTER TABLE wallet_logs ADD COLUMN IF NOT EXISTS reference_id VARCHAR(50);
+
+ALTER TABLE wallet_logs RENAME TO wallet_logs_unpartitioned;
+ALTER INDEX wallet_logs_pkey RENAME TO wallet_logs_unpartitioned_pkey;
+DROP TRIGGER wallet_logs_notify ON wallet_logs_unpartitioned;
+
+-- The partition key has to be part of the primary key; log IDs stay unique as they come from one sequence
+CREATE TABLE wallet_logs (
+    id BIGINT NOT NULL DEFAULT nextval('wallet_logs_id_seq'),
+    wallet_id INT NOT NULL REFERENCES wallets(id),
+    user_id INT NOT NULL,
+    currency VARCHAR(32) NOT NULL DEFAULT 'platform',
+    game_id VARCHAR(50),
+    token_type VARCHAR(20),
+    amount NUMERIC(20, 2) NOT NULL,
+    platform_amount NUMERIC(20, 2) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    reference_id VARCHAR(50),
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (id, created_at)
+) PARTITION BY RANGE (created_at);
+
+ALTER SEQUENCE wallet_logs_id_seq AS BIGINT;
+ALTER SEQUENCE wallet_logs_id_seq OWNED BY wallet_logs.id;
+
+-- One partition per month from the oldest log to three months ahead
+DO $$
+DECLARE
+    partition_month DATE := date_trunc('month', COALESCE(
+        (SELECT MIN(created_at) FROM wallet_logs_unpartitioned), CURRENT_TIMESTAMP))::date;
+    last_month DATE := (date_trunc('month', CURRENT_TIMESTAMP) + INTERVAL '3 months')::date;
+BEGIN
+    WHILE partition_month <= last_month LOOP
+        EXECUTE format('CREATE TABLE %I PARTITION OF wallet_logs FOR VALUES FROM (%L) TO (%L)',
+            'wallet_logs_' || to_char(partition_month, 'YYYY_MM'), partition_month,
+            (partition_month + INTERVAL '1 month')::date);
+        partition_month := (partition_month + INTERVAL '1 month')::date;
+    END LOOP;
+END
+$$;
+
+CREATE TABLE wallet_logs_default PARTITION OF wallet_logs DEFAULT;
+
+INSERT INTO wallet_logs (id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at)
+SELECT id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id,
+    COALESCE(created_at, CURRENT_TIMESTAMP)
+FROM wallet_logs_unpartitioned;
+
+DROP TABLE wallet_logs_unpartitioned;
+
+-- Indexes are created on every partition. A user's history is listed newest first; statements, balances at a
+-- point in time and snapshots read a wallet's logs by time.
+CREATE INDEX idx_wallet_logs_user_created_id ON wallet_logs (user_id, created_at DESC, id DESC);
+CREATE INDEX idx_wallet_logs_wallet_created ON wallet_logs (wallet_id, created_at, id);
+CREATE INDEX idx_wallet_logs_user_id_id ON wallet_logs (user_id, id);
+CREATE INDEX idx_wallet_logs_user_debits ON wallet_logs (user_id, created_at) WHERE platform_amount < 0;
+CREATE INDEX idx_wallet_logs_game_exchanges ON wallet_logs (game_id, token_type, created_at) WHERE source = 'exchange';
+CREATE INDEX idx_wallet_logs_reverse_exchanges ON wallet_logs (user_id, game_id, token_type, created_at) WHERE source = 'reverse_exchange';
+
+CREATE TRIGGER wallet_logs_notify
+    AFTER INSERT ON wallet_logs
+    FOR EACH ROW EXECUTE FUNCTION notify_wallet_log();
+
+-- Detached partitions are moved here, out of the way of the service, until they are exported and dropped
+CREATE SCHEMA IF NOT EXISTS wallet_logs_archive;
+
+-- The sum of each wallet's archived logs. Ledger balances are this sum plus the wallet's remaining logs; a
+-- wallet's history can be replayed from its remaining logs after last_log_at, its newest archived log.
+CREATE TABLE wallet_log_archive_balances (
+    wallet_id INT PRIMARY KEY REFERENCES wallets(id),
+    user_id INT NOT NULL,
+    currency VARCHAR(32) NOT NULL,
+    balance NUMERIC(20, 2) NOT NULL,
+    log_count INT NOT NULL,
+    last_log_at TIMESTAMP NOT NULL,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- One row per archived partition
+CREATE TABLE wallet_log_archives (
+    id SERIAL PRIMARY KEY,
+    partition_name VARCHAR(63) NOT NULL,
+    range_start TIMESTAMP NOT NULL UNIQUE,
+    range_end TIMESTAMP NOT NULL,
+    log_count INT NOT NULL,
+    wallet_count INT NOT NULL,
+    mode VARCHAR(10) NOT NULL,
+    archived_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,12 @@
+-- Feature flag overrides set through the admin API. An override replaces the targeting of a flag defined in the
+-- feature flags file until it is removed.
+CREATE TABLE feature_flag_overrides (
+    name VARCHAR(100) PRIMARY KEY,
+    enabled BOOLEAN NOT NULL,
+    percentage INT NOT NULL CHECK (percentage BETWEEN 0 AND 100),
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    game_ids TEXT[] NOT NULL DEFAULT '{}',
+    reason TEXT NOT NULL,
+    updated_by INT,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
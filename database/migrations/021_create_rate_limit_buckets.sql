@@ -0,0 +1,10 @@
+-- Token buckets of the API rate limits when they are shared between instances (RATE_LIMIT_STORE=postgres).
+-- The table is unlogged: losing the buckets in a crash only refills them.
+CREATE UNLOGGED TABLE rate_limit_buckets (
+    key VARCHAR(255) PRIMARY KEY,
+    tokens DOUBLE PRECISION NOT NULL,
+    granted BOOLEAN NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_rate_limit_buckets_updated_at ON rate_limit_buckets(updated_at);
@@ -0,0 +1,13 @@
+-- Every log records the ID of the transaction that wrote it. Log IDs are taken when a log is inserted, so logs
+-- commit out of ID order; the rollup job reads the logs of transactions older than every running one, whose
+-- set can no longer change, in transaction order. The column is added without a default first, so adding it
+-- does not rewrite the table: existing logs keep no transaction ID.
+ALTER TABLE wallet_logs ADD COLUMN xact_id xid8;
+ALTER TABLE wallet_logs ALTER COLUMN xact_id SET DEFAULT pg_current_xact_id();
+
+-- Existing logs that are not rolled up yet are not backfilled here, which would update them all in this
+-- migration's transaction. The rollup job gives them transaction ID 0, one batch per transaction, before it
+-- rolls up any later log; the logs already rolled up keep no transaction ID and are never read by it again.
+ALTER TABLE report_rollup_state ADD COLUMN last_xact_id xid8 NOT NULL DEFAULT '0';
+
+CREATE INDEX idx_wallet_logs_xact_id_id ON wallet_logs (xact_id, id) WHERE xact_id IS NOT NULL;
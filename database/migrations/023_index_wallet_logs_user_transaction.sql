@@ -0,0 +1,3 @@
+-- Wallet event streams read the logs of a user committed since a snapshot, which are the logs of transactions
+-- at or above the snapshot's xmin
+CREATE INDEX idx_wallet_logs_user_xact_id ON wallet_logs (user_id, xact_id);
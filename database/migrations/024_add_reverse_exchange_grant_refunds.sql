@@ -0,0 +1,11 @@
+-- The platform tokens of a grant that expires unredeemed are credited back to its wallet. refunded_at records the
+-- refund, so a grant is refunded once and can no longer be redeemed once it is refunded.
+ALTER TABLE reverse_exchange_grants ADD COLUMN refunded_at TIMESTAMP;
+
+CREATE INDEX idx_reverse_exchange_grants_refundable ON reverse_exchange_grants (expires_at, id)
+    WHERE redeemed_at IS NULL AND refunded_at IS NULL;
+
+-- 'reverse_exchange_refund' is longer than the sources so far; raising a VARCHAR limit rewrites no rows
+ALTER TABLE wallet_logs ALTER COLUMN source TYPE VARCHAR(30);
+ALTER TABLE wallet_balance_lots ALTER COLUMN source TYPE VARCHAR(30);
+ALTER TABLE report_daily_activity ALTER COLUMN source TYPE VARCHAR(30);
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/config"
+)
+
+// Replica is the connection pool of a read replica
+type Replica struct {
+	// Name identifies the replica in logs and metrics
+	Name string
+	DB   *sql.DB
+}
+
+// NewReplicas opens a connection pool per configured read replica. Unlike the primary, replicas are not
+// waited for: an unreachable replica is only marked unhealthy, and reads stay on the primary until it
+// recovers.
+func NewReplicas(cfg *config.Config) ([]*Replica, error) {
+	replicas := make([]*Replica, 0, len(cfg.Replicas.Hosts))
+	for _, address := range cfg.Replicas.Hosts {
+		replicaCfg, err := replicaDatabaseConfig(cfg.Database, address)
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, err
+		}
+
+		db, err := sql.Open("postgres", replicaCfg.GetDSN())
+		if err != nil {
+			closeReplicas(replicas)
+			return nil, fmt.Errorf("open replica %s: %w", address, err)
+		}
+
+		db.SetMaxOpenConns(cfg.Database.MaxOpenConns)
+		db.SetMaxIdleConns(cfg.Database.MaxIdleConns)
+		db.SetConnMaxLifetime(cfg.Database.ConnMaxLifetime)
+		db.SetConnMaxIdleTime(cfg.Database.ConnMaxIdleTime)
+
+		replicas = append(replicas, &Replica{Name: address, DB: db})
+	}
+
+	return replicas, nil
+}
+
+// replicaDatabaseConfig returns the primary's configuration pointed at a replica given as host or
+// host:port; without a port the primary's port is used
+func replicaDatabaseConfig(primary config.DatabaseConfig, address string) (config.DatabaseConfig, error) {
+	replica := primary
+	replica.Host = address
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		// No port
+		return replica, nil
+	}
+
+	replica.Host = host
+	replica.Port, err = strconv.Atoi(port)
+	if err != nil || replica.Port < 1 || replica.Port > 65535 {
+		return replica, fmt.Errorf("invalid port in replica address %q", address)
+	}
+
+	return replica, nil
+}
+
+func closeReplicas(replicas []*Replica) {
+	for _, replica := range replicas {
+		replica.DB.Close()
+	}
+}
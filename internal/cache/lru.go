@@ -0,0 +1,138 @@
+// Package cache provides the in-process cache of the service and the interface of shared remote caches
+package cache
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+// LRU is a cache of at most size entries that expire ttl after they were added; when it is full, the
+// least recently used entry is evicted. It is safe for concurrent use.
+//
+// Every removal advances the cache's version. A caller that loads a value after a miss takes the version
+// before loading and stores the value with AddSince, so a value loaded before an invalidation is not
+// cached after it.
+type LRU[K comparable, V any] struct {
+	mu      sync.Mutex
+	size    int
+	ttl     time.Duration
+	items   map[K]*list.Element
+	order   *list.List
+	version uint64
+	now     func() time.Time
+}
+
+type lruEntry[K comparable, V any] struct {
+	key       K
+	value     V
+	expiresAt time.Time
+}
+
+// NewLRU creates a cache holding at most size entries for ttl each
+func NewLRU[K comparable, V any](size int, ttl time.Duration) *LRU[K, V] {
+	return &LRU[K, V]{
+		size:  size,
+		ttl:   ttl,
+		items: make(map[K]*list.Element),
+		order: list.New(),
+		now:   time.Now,
+	}
+}
+
+// Get returns the value of a key that is cached and not expired
+func (c *LRU[K, V]) Get(key K) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var zero V
+	element, ok := c.items[key]
+	if !ok {
+		return zero, false
+	}
+
+	entry := element.Value.(*lruEntry[K, V])
+	if !c.now().Before(entry.expiresAt) {
+		c.removeElement(element)
+		return zero, false
+	}
+
+	c.order.MoveToFront(element)
+	return entry.value, true
+}
+
+// Version returns the current version of the cache, to be passed to AddSince
+func (c *LRU[K, V]) Version() uint64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.version
+}
+
+// Add caches the value of a key, evicting the least recently used entry when the cache is full
+func (c *LRU[K, V]) Add(key K, value V) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.add(key, value)
+}
+
+// AddSince caches the value of a key unless an entry was removed after the cache had the given
+// version, and reports whether the value was cached
+func (c *LRU[K, V]) AddSince(version uint64, key K, value V) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.version != version {
+		return false
+	}
+	c.add(key, value)
+	return true
+}
+
+// Remove drops the entry of a key
+func (c *LRU[K, V]) Remove(key K) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	if element, ok := c.items[key]; ok {
+		c.removeElement(element)
+	}
+}
+
+// Purge drops every entry
+func (c *LRU[K, V]) Purge() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.version++
+	c.items = make(map[K]*list.Element)
+	c.order.Init()
+}
+
+// Len returns the number of cached entries, including expired ones not yet dropped
+func (c *LRU[K, V]) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
+
+func (c *LRU[K, V]) add(key K, value V) {
+	expiresAt := c.now().Add(c.ttl)
+	if element, ok := c.items[key]; ok {
+		entry := element.Value.(*lruEntry[K, V])
+		entry.value = value
+		entry.expiresAt = expiresAt
+		c.order.MoveToFront(element)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&lruEntry[K, V]{key: key, value: value, expiresAt: expiresAt})
+	for c.order.Len() > c.size {
+		c.removeElement(c.order.Back())
+	}
+}
+
+func (c *LRU[K, V]) removeElement(element *list.Element) {
+	c.order.Remove(element)
+	delete(c.items, element.Value.(*lruEntry[K, V]).key)
+}
@@ -0,0 +1,66 @@
+package cache
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLRUExpiry(t *testing.T) {
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.UTC)
+	c := NewLRU[string, int](10, time.Minute)
+	c.now = func() time.Time { return now }
+
+	c.Add("rate", 1)
+	value, ok := c.Get("rate")
+	assert.True(t, ok)
+	assert.Equal(t, 1, value)
+
+	now = now.Add(time.Minute)
+	_, ok = c.Get("rate")
+	assert.False(t, ok)
+	assert.Equal(t, 0, c.Len(), "expired entries are dropped when read")
+}
+
+func TestLRUEviction(t *testing.T) {
+	c := NewLRU[int, string](2, time.Minute)
+	c.Add(1, "one")
+	c.Add(2, "two")
+
+	// Reading 1 makes 2 the least recently used entry
+	c.Get(1)
+	c.Add(3, "three")
+
+	_, ok := c.Get(2)
+	assert.False(t, ok)
+	_, ok = c.Get(1)
+	assert.True(t, ok)
+	_, ok = c.Get(3)
+	assert.True(t, ok)
+	assert.Equal(t, 2, c.Len())
+
+	c.Add(1, "uno")
+	value, _ := c.Get(1)
+	assert.Equal(t, "uno", value)
+	assert.Equal(t, 2, c.Len())
+}
+
+func TestLRUAddSince(t *testing.T) {
+	c := NewLRU[int, string](10, time.Minute)
+
+	version := c.Version()
+	assert.True(t, c.AddSince(version, 1, "loaded"))
+
+	// An invalidation while a value is loaded keeps the stale value out of the cache
+	version = c.Version()
+	c.Remove(2)
+	assert.False(t, c.AddSince(version, 2, "stale"))
+	_, ok := c.Get(2)
+	assert.False(t, ok)
+
+	version = c.Version()
+	c.Purge()
+	assert.False(t, c.AddSince(version, 3, "stale"))
+	assert.Equal(t, 0, c.Len())
+}
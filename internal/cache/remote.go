@@ -0,0 +1,14 @@
+package cache
+
+import (
+	"context"
+	"time"
+)
+
+// Remote is a cache shared by the instances of the service, such as Redis or Memcached. It is consulted
+// after a miss of the in-process cache. Values are opaque; a missing key is not an error.
+type Remote interface {
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Delete(ctx context.Context, keys ...string) error
+}
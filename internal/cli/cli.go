@@ -0,0 +1,136 @@
+// Package cli implements the command-line subcommands of the wallet service binary
+package cli
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/module"
+
+	"go.uber.org/fx"
+)
+
+// Exit codes returned by Run
+const (
+	ExitOK    = 0
+	ExitError = 1
+	ExitUsage = 2
+)
+
+// command executes a subcommand against a started application and returns its exit code
+type command func(ctx context.Context) (int, error)
+
+// ParseGlobalFlags applies the flags given before the command and returns the remaining arguments.
+// --config selects the configuration file like CONFIG_FILE.
+func ParseGlobalFlags(args []string) ([]string, error) {
+	flags := flag.NewFlagSet("wallet-service", flag.ContinueOnError)
+	flags.SetOutput(io.Discard)
+	configFile := flags.String("config", "", "Configuration file (YAML, TOML, JSON or env)")
+
+	if err := flags.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return []string{"help"}, nil
+		}
+		fmt.Fprintf(os.Stderr, "%v\n\n", err)
+		printUsage(os.Stderr)
+		return nil, err
+	}
+
+	if *configFile != "" {
+		if err := os.Setenv(config.EnvConfigFile, *configFile); err != nil {
+			return nil, err
+		}
+	}
+	return flags.Args(), nil
+}
+
+// Run executes the subcommand named by args[0] and returns the process exit code
+func Run(args []string) int {
+	if len(args) == 0 {
+		printUsage(os.Stderr)
+		return ExitUsage
+	}
+
+	switch args[0] {
+	case "reconcile":
+		return runReconcile(args[1:])
+	case "expire":
+		return runExpire(args[1:])
+	case "export":
+		return runExport(args[1:])
+	case "snapshot":
+		return runSnapshot(args[1:])
+	case "partitions":
+		return runPartitions(args[1:])
+	case "config":
+		return runConfig(args[1:])
+	case "help", "-h", "--help":
+		printUsage(os.Stdout)
+		return ExitOK
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n", args[0])
+		printUsage(os.Stderr)
+		return ExitUsage
+	}
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: wallet-service [--config file] [command] [flags]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Without a command the HTTP server is started.")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	fmt.Fprintln(w, "  reconcile   Compare wallet balances with their log history (exit code 3 if drift is found)")
+	fmt.Fprintln(w, "  expire      Remove expired balance lots from their wallets")
+	fmt.Fprintln(w, "  export      Write wallet logs as CSV or NDJSON to stdout or a file")
+	fmt.Fprintln(w, "  snapshot    Snapshot the balance of every wallet at the latest snapshot time")
+	fmt.Fprintln(w, "  partitions  Create upcoming wallet log partitions and archive the ones past retention")
+	fmt.Fprintln(w, "  config      Print the effective configuration (config print [--redacted])")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "The configuration is read from --config or CONFIG_FILE, or from the APP_PROFILE profile in")
+	fmt.Fprintln(w, "CONFIG_DIR (default profiles), then from environment variables.")
+}
+
+// execute starts the core application (no HTTP server), runs cmd and stops the application
+func execute(cmd command, opts ...fx.Option) int {
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	app := fx.New(append([]fx.Option{module.CoreModule, fx.NopLogger}, opts...)...)
+
+	if err := app.Start(ctx); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to start: %v\n", err)
+		return ExitError
+	}
+
+	code, err := cmd(ctx)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		code = ExitError
+	}
+
+	stopCtx, stopCancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer stopCancel()
+
+	if err := app.Stop(stopCtx); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to stop: %v\n", err)
+	}
+
+	return code
+}
+
+// writeJSON prints v as indented JSON on stdout
+func writeJSON(v interface{}) error {
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(v)
+}
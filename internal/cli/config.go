@@ -0,0 +1,43 @@
+package cli
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/playconomy/wallet-service/internal/config"
+)
+
+func runConfig(args []string) int {
+	if len(args) == 0 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, "Usage: wallet-service config print [--redacted]")
+		return ExitUsage
+	}
+
+	flags := flag.NewFlagSet("config print", flag.ContinueOnError)
+	redacted := flags.Bool("redacted", false, "Replace secrets with "+config.RedactedValue)
+	if err := flags.Parse(args[1:]); err != nil {
+		return ExitUsage
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		var validationErr *config.ValidationError
+		if errors.As(err, &validationErr) {
+			fmt.Fprintln(os.Stderr, "invalid configuration:")
+			for _, problem := range validationErr.Problems {
+				fmt.Fprintf(os.Stderr, "  - %s\n", problem)
+			}
+			return ExitError
+		}
+		fmt.Fprintf(os.Stderr, "error: %v\n", err)
+		return ExitError
+	}
+
+	// One NAME=value line per setting, which can be read back as an env profile
+	for _, setting := range cfg.Settings(*redacted) {
+		fmt.Fprintf(os.Stdout, "%s=%s\n", setting.Name, setting.Value)
+	}
+	return ExitOK
+}
@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"flag"
+
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"go.uber.org/fx"
+)
+
+func runExpire(args []string) int {
+	flags := flag.NewFlagSet("expire", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	var walletService service.WalletServiceInterface
+
+	return execute(func(ctx context.Context) (int, error) {
+		report, err := walletService.ExpireBalanceLots(ctx)
+		if err != nil {
+			return ExitError, err
+		}
+
+		if err := writeJSON(report); err != nil {
+			return ExitError, err
+		}
+		return ExitOK, nil
+	}, fx.Populate(&walletService))
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"go.uber.org/fx"
+)
+
+func runExport(args []string) int {
+	var req dto.WalletLogExportRequest
+
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	flags.StringVar(&req.Format, "format", service.ExportFormatCSV, "output format (csv, ndjson)")
+	flags.IntVar(&req.UserID, "user-id", 0, "only export logs of this user")
+	flags.StringVar(&req.GameID, "game-id", "", "only export logs of this game")
+	flags.StringVar(&req.TokenType, "token-type", "", "only export logs of this token type")
+	flags.StringVar(&req.Currency, "currency", "", "only export logs of this currency")
+	flags.StringVar(&req.Source, "source", "", "only export logs of this source")
+	flags.StringVar(&req.From, "from", "", "start of the range, inclusive (RFC 3339 or YYYY-MM-DD)")
+	flags.StringVar(&req.To, "to", "", "end of the range, exclusive (RFC 3339 or YYYY-MM-DD)")
+	output := flags.String("output", "", "file to write to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %v\n", err)
+		return ExitUsage
+	}
+
+	filter, err := service.NewWalletLogFilter(&req)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "invalid flags: %v\n", err)
+		return ExitUsage
+	}
+
+	var exportService service.ExportServiceInterface
+
+	return execute(func(ctx context.Context) (int, error) {
+		var w io.Writer = os.Stdout
+		if *output != "" {
+			file, err := os.Create(*output)
+			if err != nil {
+				return ExitError, err
+			}
+			defer file.Close()
+			w = file
+		}
+
+		buffered := bufio.NewWriter(w)
+		count, err := exportService.ExportWalletLogs(ctx, filter, req.Format, buffered)
+		if flushErr := buffered.Flush(); err == nil {
+			err = flushErr
+		}
+		if err != nil {
+			return ExitError, err
+		}
+
+		fmt.Fprintf(os.Stderr, "exported %d wallet logs\n", count)
+		return ExitOK, nil
+	}, fx.Populate(&exportService))
+}
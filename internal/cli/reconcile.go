@@ -0,0 +1,49 @@
+package cli
+
+import (
+	"context"
+	"flag"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"go.uber.org/fx"
+)
+
+// ExitDrift is returned by the reconcile command when at least one wallet drifted
+const ExitDrift = 3
+
+func runReconcile(args []string) int {
+	flags := flag.NewFlagSet("reconcile", flag.ContinueOnError)
+	freeze := flags.Bool("freeze", false, "freeze drifted wallets even if RECONCILIATION_FREEZE_ON_DRIFT is disabled")
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	var reconciliationService service.ReconciliationServiceInterface
+	opts := []fx.Option{fx.Populate(&reconciliationService)}
+
+	if *freeze {
+		opts = append(opts, fx.Decorate(func(cfg *config.Config) *config.Config {
+			cfg.Reconciliation.FreezeOnDrift = true
+			return cfg
+		}))
+	}
+
+	return execute(func(ctx context.Context) (int, error) {
+		report, err := reconciliationService.Run(ctx, model.ReconciliationTriggerCLI)
+		if err != nil {
+			return ExitError, err
+		}
+
+		if err := writeJSON(report); err != nil {
+			return ExitError, err
+		}
+
+		if report.MismatchCount > 0 {
+			return ExitDrift, nil
+		}
+		return ExitOK, nil
+	}, opts...)
+}
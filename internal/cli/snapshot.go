@@ -0,0 +1,31 @@
+package cli
+
+import (
+	"context"
+	"flag"
+
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"go.uber.org/fx"
+)
+
+func runSnapshot(args []string) int {
+	flags := flag.NewFlagSet("snapshot", flag.ContinueOnError)
+	if err := flags.Parse(args); err != nil {
+		return ExitUsage
+	}
+
+	var snapshotService service.SnapshotServiceInterface
+
+	return execute(func(ctx context.Context) (int, error) {
+		report, err := snapshotService.TakeBalanceSnapshots(ctx)
+		if err != nil {
+			return ExitError, err
+		}
+
+		if err := writeJSON(report); err != nil {
+			return ExitError, err
+		}
+		return ExitOK, nil
+	}, fx.Populate(&snapshotService))
+}
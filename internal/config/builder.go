@@ -2,24 +2,79 @@ package config
 
 import (
 	"fmt"
-	"path/filepath"
-	"runtime"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/playconomy/wallet-service/internal/ratelimit"
 	"github.com/playconomy/wallet-service/internal/utils"
 	
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server        ServerConfig        `validate:"required"`
-	Database      DatabaseConfig      `validate:"required"`
-	App           AppConfig           `validate:"required"`
-	Observability ObservabilityConfig `validate:"required"`
+	Server          ServerConfig         `validate:"required"`
+	Database        DatabaseConfig       `validate:"required"`
+	App             AppConfig            `validate:"required"`
+	Observability   ObservabilityConfig  `validate:"required"`
+	Reconciliation  ReconciliationConfig `validate:"required"`
+	SpendLimits     SpendLimitsConfig
+	Risk            RiskConfig
+	Currency        CurrencyConfig `validate:"required"`
+	Expiry          ExpiryConfig   `validate:"required"`
+	ReverseExchange ReverseExchangeConfig
+	Bulk            BulkConfig `validate:"required"`
+	Jobs            JobsConfig `validate:"required"`
+	Statements      StatementsConfig
+	Snapshots       SnapshotsConfig    `validate:"required"`
+	Reporting       ReportingConfig    `validate:"required"`
+	Stream          StreamConfig       `validate:"required"`
+	Audit           AuditConfig        `validate:"required"`
+	Health          HealthConfig       `validate:"required"`
+	Replicas        ReplicaConfig      `validate:"required"`
+	Cache           CacheConfig        `validate:"required"`
+	Partitions      PartitionsConfig   `validate:"required"`
+	Reload          ReloadConfig       `validate:"required"`
+	FeatureFlags    FeatureFlagsConfig `validate:"required"`
+
+	// settings are the effective values the configuration was built from
+	settings []Setting
+	// files are the configuration and secret files the configuration was read from
+	files []string
 }
 
 type ServerConfig struct {
 	Host string `validate:"required"`
 	Port int    `validate:"required,gte=1,lte=65535"`
+
+	// ProxyHeader is the header holding the client IP address, such as X-Forwarded-For, when the server runs
+	// behind a proxy; the connection's address is used when it is empty. Only TrustedProxies may set it when
+	// any are listed.
+	ProxyHeader    string
+	TrustedProxies []string
+
+	// RateLimit limits the requests of each client
+	RateLimit RateLimitConfig `validate:"required"`
+}
+
+// RateLimitConfig controls the rate limiting of the API. A request takes a token from the bucket of its
+// principal for its route and from the bucket of its IP address, which is shared by every route.
+type RateLimitConfig struct {
+	Enabled bool
+	// Store keeps the buckets: memory limits each instance on its own, postgres shares them between instances
+	Store string `validate:"required,oneof=memory postgres"`
+	// Default is the limit of a principal on the routes without a limit of their own
+	Default ratelimit.Limit
+	// Routes are the limits of a principal per route, keyed by <METHOD> <path> as registered with the router
+	Routes map[string]ratelimit.Limit
+	// IP is the limit of an IP address over every route
+	IP ratelimit.Limit
+	// ServiceRoles are the roles of service principals such as game servers, which act for many users; their
+	// limits are multiplied by ServiceFactor
+	ServiceRoles  []string
+	ServiceFactor int `validate:"required,gte=1"`
+	// PruneInterval is the time between removals of unused buckets
+	PruneInterval time.Duration `validate:"required,gt=0"`
 }
 
 type DatabaseConfig struct {
@@ -29,6 +84,18 @@ type DatabaseConfig struct {
 	Password string `validate:"required"`
 	DBName   string `validate:"required"`
 	SSLMode  string `validate:"required,oneof=disable enable verify-ca verify-full"`
+
+	// Connection pool
+	MaxOpenConns    int           `validate:"required,gte=1"`
+	MaxIdleConns    int           `validate:"gte=0,ltefield=MaxOpenConns"`
+	ConnMaxLifetime time.Duration `validate:"gte=0"`
+	ConnMaxIdleTime time.Duration `validate:"gte=0"`
+
+	// ConnectRetries is how many times connecting is retried at startup before giving up; the delay
+	// between attempts starts at ConnectRetryDelay and doubles up to ConnectRetryMaxDelay
+	ConnectRetries       int           `validate:"gte=0"`
+	ConnectRetryDelay    time.Duration `validate:"required,gt=0"`
+	ConnectRetryMaxDelay time.Duration `validate:"required,gtefield=ConnectRetryDelay"`
 }
 
 type AppConfig struct {
@@ -44,41 +111,274 @@ type ObservabilityConfig struct {
 }
 
 type TracingConfig struct {
-	Enabled        bool    `validate:"required"`
+	Enabled        bool
 	Endpoint       string  `validate:"required_if=Enabled true"`
-	SamplingRatio  float64 `validate:"required,gte=0,lte=1"`
+	SamplingRatio  float64 `validate:"gte=0,lte=1"`
 }
 
 type MetricsConfig struct {
-	Enabled bool `validate:"required"`
+	Enabled bool
 }
 
-// LoadConfig loads configuration from environment file and environment variables
-func LoadConfig() (*Config, error) {
-	// Get the project root directory
-	_, b, _, _ := runtime.Caller(0)
-	projectRoot := filepath.Join(filepath.Dir(b), "../..")
+type ReconciliationConfig struct {
+	Enabled       bool
+	Interval      time.Duration `validate:"required,gt=0"`
+	BatchSize     int           `validate:"required,gte=1,lte=10000"`
+	Tolerance     float64       `validate:"gte=0"`
+	FreezeOnDrift bool
+}
 
-	viper.SetConfigName("default")
-	viper.SetConfigType("env")
-	viper.AddConfigPath(filepath.Join(projectRoot, "profiles"))
+// SpendLimitsConfig holds the default spend limits; a zero value disables the limit
+type SpendLimitsConfig struct {
+	MaxPerTransaction  float64 `validate:"gte=0"`
+	DailyCap           float64 `validate:"gte=0"`
+	WeeklyCap          float64 `validate:"gte=0"`
+	MaxSpendsPerMinute int     `validate:"gte=0"`
+}
 
-	// Read config file
-	if err := viper.ReadInConfig(); err != nil {
-		return nil, fmt.Errorf("error reading config file: %w", err)
+// RiskConfig controls the risk evaluation of exchanges and spends
+type RiskConfig struct {
+	Enabled     bool
+	RulesFile   string `validate:"required_if=Enabled true"`
+	HistorySize int    `validate:"gte=1,lte=1000"`
+}
+
+// CurrencyConfig lists the platform currencies wallets may hold.
+// Requests that do not name a currency use the default one.
+type CurrencyConfig struct {
+	Default   string   `validate:"required,max=32"`
+	Supported []string `validate:"required,min=1,dive,required,max=32"`
+}
+
+// IsSupported reports whether wallets may hold the currency
+func (c *CurrencyConfig) IsSupported(currency string) bool {
+	for _, supported := range c.Supported {
+		if supported == currency {
+			return true
+		}
 	}
+	return false
+}
+
+// ExpiryConfig controls the expiry of time-limited balance lots
+type ExpiryConfig struct {
+	Enabled   bool
+	Interval  time.Duration `validate:"required,gt=0"`
+	BatchSize int           `validate:"required,gte=1,lte=10000"`
+	// Lookahead is how far ahead GET /:user_id lists upcoming expirations
+	Lookahead time.Duration `validate:"required,gt=0"`
+}
 
-	// Set default values
+// minSigningKeyLength is the shortest reverse exchange signing key accepted
+const minSigningKeyLength = 32
+
+// ReverseExchangeConfig controls the exchange of platform tokens back into game tokens
+type ReverseExchangeConfig struct {
+	Enabled bool
+	// SigningKey signs the grants game servers redeem; it must be shared with them
+	SigningKey string        `validate:"required_if=Enabled true"`
+	GrantTTL   time.Duration `validate:"required,gt=0"`
+}
+
+// BulkConfig controls bulk credit and spend operations
+type BulkConfig struct {
+	// ChunkSize is the number of items applied per transaction
+	ChunkSize     int `validate:"required,gte=1,lte=10000"`
+	MaxSyncItems  int `validate:"required,gte=1"`
+	MaxAsyncItems int `validate:"required,gtefield=MaxSyncItems"`
+}
+
+// JobsConfig controls the workers that run background jobs
+type JobsConfig struct {
+	Enabled bool
+	Workers int `validate:"required,gte=1,lte=100"`
+	// PollInterval is how long an idle worker waits before looking for due jobs again
+	PollInterval time.Duration `validate:"required,gt=0"`
+	// LeaseDuration is how long a job stays leased to a worker without a heartbeat
+	LeaseDuration     time.Duration `validate:"required,gt=0"`
+	HeartbeatInterval time.Duration `validate:"required,gt=0,ltfield=LeaseDuration"`
+	MaxAttempts       int           `validate:"required,gte=1"`
+	// RetryBackoff is the delay before the second attempt; it doubles with every further attempt
+	RetryBackoff time.Duration `validate:"required,gt=0"`
+}
+
+// StatementsConfig controls the generation of monthly wallet statements
+type StatementsConfig struct {
+	// SettleDelay is how long after the end of a month its statement becomes available, so logs of
+	// transactions committed around midnight are part of it
+	SettleDelay time.Duration `validate:"gte=0"`
+}
+
+// SnapshotsConfig controls the periodic balance snapshots used by point-in-time balance queries
+type SnapshotsConfig struct {
+	Enabled bool
+	// Interval is the time between snapshots; snapshots are taken at multiples of it, so all instances
+	// snapshot the same points in time
+	Interval  time.Duration `validate:"required,gt=0"`
+	BatchSize int           `validate:"required,gte=1,lte=10000"`
+	// Lag is how far a snapshot stays behind the current time, so it misses no log of a transaction
+	// that was still running when the snapshot was taken
+	Lag time.Duration `validate:"gte=0"`
+}
+
+// ReportingConfig controls the rollup job that maintains the daily tables behind the reporting API
+type ReportingConfig struct {
+	Enabled   bool
+	Interval  time.Duration `validate:"required,gt=0"`
+	BatchSize int           `validate:"required,gte=1,lte=100000"`
+	// Lag is how far the rollups stay behind the current time
+	Lag time.Duration `validate:"gte=0"`
+	// MaxRange is the longest date range a report may cover
+	MaxRange time.Duration `validate:"required,gt=0"`
+}
+
+// StreamConfig controls the Server-Sent Events streams of wallet balance changes
+type StreamConfig struct {
+	// HeartbeatInterval is the time between comments sent on an idle stream, so proxies keep it open
+	HeartbeatInterval time.Duration `validate:"required,gt=0"`
+	// RetryDelay is how long clients wait before reconnecting a dropped stream
+	RetryDelay time.Duration `validate:"gte=0"`
+	// ReplayLimit is the number of logs read per query when a reconnected stream catches up
+	ReplayLimit int `validate:"required,gte=1,lte=10000"`
+}
+
+// AuditConfig controls the audit log of privileged calls
+type AuditConfig struct {
+	// Roles are the roles whose calls are audited
+	Roles []string `validate:"required,min=1"`
+	// VerifyBatchSize is the number of entries read per query when the audit chain is verified
+	VerifyBatchSize int `validate:"required,gte=1,lte=10000"`
+}
+
+// HealthConfig controls the liveness and readiness probes
+type HealthConfig struct {
+	// CheckTimeout is how long a single check may run before it counts as failed
+	CheckTimeout time.Duration `validate:"required,gt=0"`
+	// CacheTTL is how long a check result is reused by later probes
+	CacheTTL time.Duration `validate:"gte=0"`
+	// ShutdownDelay is how long the readiness probe fails before the server stops accepting connections,
+	// so load balancers stop routing to the instance first
+	ShutdownDelay time.Duration `validate:"gte=0"`
+	// CheckMigrations makes readiness require the schema version this build ships with
+	CheckMigrations bool
+	// JobBacklogMaxAge is how long a due job may wait for a worker before the job check fails
+	JobBacklogMaxAge time.Duration `validate:"required,gt=0"`
+}
+
+// ReplicaConfig controls the routing of read-only queries to read replicas
+type ReplicaConfig struct {
+	// Hosts are the read replicas as host or host:port; they share the credentials and pool settings of
+	// the primary. Without hosts every query goes to the primary
+	Hosts []string
+	// MaxLag is the replication lag above which a replica stops serving reads
+	MaxLag time.Duration `validate:"required,gt=0"`
+	// ReadYourWritesWindow is how long the reads of a user go to the primary after a write to their
+	// wallet on this instance; it should exceed MaxLag
+	ReadYourWritesWindow time.Duration `validate:"gte=0"`
+	// CheckInterval is the time between replica health and lag checks
+	CheckInterval time.Duration `validate:"required,gt=0"`
+}
+
+// CacheConfig controls the caching of wallets and exchange rates
+type CacheConfig struct {
+	Enabled bool
+	// WalletTTL is how long a user's wallets are cached; changes are also dropped from the cache as they
+	// are committed
+	WalletTTL  time.Duration `validate:"required,gt=0"`
+	WalletSize int           `validate:"required,gte=1"`
+	// RateTTL is how long an exchange rate is cached
+	RateTTL  time.Duration `validate:"required,gt=0"`
+	RateSize int           `validate:"required,gte=1"`
+}
+
+// PartitionsConfig controls the maintenance of the monthly wallet_logs partitions and the retention of logs
+type PartitionsConfig struct {
+	Enabled bool
+	// Interval is the time between maintenance runs
+	Interval time.Duration `validate:"required,gt=0"`
+	// Ahead is the number of months after the current one whose partitions are created in advance
+	Ahead int `validate:"required,gte=1,lte=24"`
+	// RetentionMonths is the number of full months kept before the current one; older partitions are
+	// archived. 0 keeps all logs. Spend limit and exchange cap windows need at least the last two months.
+	RetentionMonths int `validate:"omitempty,gte=2"`
+	// ArchiveMode is what happens to an archived partition: detach keeps it as a table in the
+	// wallet_logs_archive schema, drop deletes it
+	ArchiveMode string `validate:"required,oneof=detach drop"`
+	// StatementBatchSize is the number of wallets read per query when the statements of a partition's month
+	// are generated before it is archived
+	StatementBatchSize int `validate:"required,gte=1,lte=10000"`
+}
+
+// ReloadConfig controls the reload of the configuration when its files change
+type ReloadConfig struct {
+	Enabled bool
+	// Debounce is the quiet period after a change before the configuration is reloaded, so a file written
+	// in several steps is read once
+	Debounce time.Duration `validate:"required,gt=0"`
+}
+
+// FeatureFlagsConfig controls the feature flags. Flags are defined in File; overrides set through the admin
+// API are read from the database every RefreshInterval.
+type FeatureFlagsConfig struct {
+	// File is the JSON file defining the flags; empty defines none
+	File            string
+	RefreshInterval time.Duration `validate:"required,gt=0"`
+}
+
+// loadMu serializes loads, which share the global viper instance
+var loadMu sync.Mutex
+
+// LoadConfig loads the configuration from the file named by CONFIG_FILE, or the profile named by APP_PROFILE,
+// and environment variables
+func LoadConfig() (*Config, error) {
+	return Load(SourceFromEnv())
+}
+
+// Load loads the configuration from the files of source and environment variables. Environment variables
+// take precedence over files, which take precedence over defaults; a setting whose <NAME>_FILE variable is set
+// is read from that file. Every invalid setting is reported in one *ValidationError.
+func Load(source Source) (*Config, error) {
+	loadMu.Lock()
+	defer loadMu.Unlock()
+
+	viper.Reset()
+
+	// Set default values; every setting has one, which makes them the known settings
 	setDefaults()
+	keys := viper.AllKeys()
+	known := make(map[string]bool, len(keys))
+	for _, key := range keys {
+		known[key] = true
+	}
+
+	files, problems, err := readSource(source, known)
+	if err != nil {
+		return nil, fmt.Errorf("error reading config file: %w", err)
+	}
 
 	// Read environment variables
 	viper.AutomaticEnv()
 
+	secretFiles, secretKeys, secretProblems := readSecretFiles(keys)
+	problems = append(problems, secretProblems...)
+
 	var config Config
 	config.Server = ServerConfig{
 		Host: viper.GetString("SERVER_HOST"),
 		Port: viper.GetInt("SERVER_PORT"),
+
+		ProxyHeader:    viper.GetString("SERVER_PROXY_HEADER"),
+		TrustedProxies: splitList(viper.GetString("SERVER_TRUSTED_PROXIES")),
+
+		RateLimit: RateLimitConfig{
+			Enabled:       viper.GetBool("RATE_LIMIT_ENABLED"),
+			Store:         viper.GetString("RATE_LIMIT_STORE"),
+			ServiceRoles:  splitList(viper.GetString("RATE_LIMIT_SERVICE_ROLES")),
+			ServiceFactor: viper.GetInt("RATE_LIMIT_SERVICE_FACTOR"),
+			PruneInterval: viper.GetDuration("RATE_LIMIT_PRUNE_INTERVAL"),
+		},
 	}
+	problems = append(problems, config.Server.RateLimit.readLimits()...)
 
 	config.Database = DatabaseConfig{
 		Host:     viper.GetString("DB_HOST"),
@@ -87,6 +387,15 @@ func LoadConfig() (*Config, error) {
 		Password: viper.GetString("DB_PASSWORD"),
 		DBName:   viper.GetString("DB_NAME"),
 		SSLMode:  viper.GetString("DB_SSL_MODE"),
+
+		MaxOpenConns:    viper.GetInt("DB_MAX_OPEN_CONNS"),
+		MaxIdleConns:    viper.GetInt("DB_MAX_IDLE_CONNS"),
+		ConnMaxLifetime: viper.GetDuration("DB_CONN_MAX_LIFETIME"),
+		ConnMaxIdleTime: viper.GetDuration("DB_CONN_MAX_IDLE_TIME"),
+
+		ConnectRetries:       viper.GetInt("DB_CONNECT_RETRIES"),
+		ConnectRetryDelay:    viper.GetDuration("DB_CONNECT_RETRY_DELAY"),
+		ConnectRetryMaxDelay: viper.GetDuration("DB_CONNECT_RETRY_MAX_DELAY"),
 	}
 
 	config.App = AppConfig{
@@ -107,10 +416,156 @@ func LoadConfig() (*Config, error) {
 		},
 	}
 
+	config.Reconciliation = ReconciliationConfig{
+		Enabled:       viper.GetBool("RECONCILIATION_ENABLED"),
+		Interval:      viper.GetDuration("RECONCILIATION_INTERVAL"),
+		BatchSize:     viper.GetInt("RECONCILIATION_BATCH_SIZE"),
+		Tolerance:     viper.GetFloat64("RECONCILIATION_TOLERANCE"),
+		FreezeOnDrift: viper.GetBool("RECONCILIATION_FREEZE_ON_DRIFT"),
+	}
+
+	config.SpendLimits = SpendLimitsConfig{
+		MaxPerTransaction:  viper.GetFloat64("SPEND_LIMIT_MAX_PER_TRANSACTION"),
+		DailyCap:           viper.GetFloat64("SPEND_LIMIT_DAILY_CAP"),
+		WeeklyCap:          viper.GetFloat64("SPEND_LIMIT_WEEKLY_CAP"),
+		MaxSpendsPerMinute: viper.GetInt("SPEND_LIMIT_MAX_PER_MINUTE"),
+	}
+
+	config.Risk = RiskConfig{
+		Enabled:     viper.GetBool("RISK_ENABLED"),
+		RulesFile:   viper.GetString("RISK_RULES_FILE"),
+		HistorySize: viper.GetInt("RISK_HISTORY_SIZE"),
+	}
+
+	config.Currency = CurrencyConfig{
+		Default:   viper.GetString("CURRENCY_DEFAULT"),
+		Supported: splitList(viper.GetString("CURRENCY_SUPPORTED")),
+	}
+
+	config.Expiry = ExpiryConfig{
+		Enabled:   viper.GetBool("EXPIRY_ENABLED"),
+		Interval:  viper.GetDuration("EXPIRY_INTERVAL"),
+		BatchSize: viper.GetInt("EXPIRY_BATCH_SIZE"),
+		Lookahead: viper.GetDuration("EXPIRY_LOOKAHEAD"),
+	}
+
+	config.ReverseExchange = ReverseExchangeConfig{
+		Enabled:    viper.GetBool("REVERSE_EXCHANGE_ENABLED"),
+		SigningKey: viper.GetString("REVERSE_EXCHANGE_SIGNING_KEY"),
+		GrantTTL:   viper.GetDuration("REVERSE_EXCHANGE_GRANT_TTL"),
+	}
+
+	config.Bulk = BulkConfig{
+		ChunkSize:     viper.GetInt("BULK_CHUNK_SIZE"),
+		MaxSyncItems:  viper.GetInt("BULK_MAX_SYNC_ITEMS"),
+		MaxAsyncItems: viper.GetInt("BULK_MAX_ASYNC_ITEMS"),
+	}
+
+	config.Jobs = JobsConfig{
+		Enabled:           viper.GetBool("JOBS_ENABLED"),
+		Workers:           viper.GetInt("JOBS_WORKERS"),
+		PollInterval:      viper.GetDuration("JOBS_POLL_INTERVAL"),
+		LeaseDuration:     viper.GetDuration("JOBS_LEASE_DURATION"),
+		HeartbeatInterval: viper.GetDuration("JOBS_HEARTBEAT_INTERVAL"),
+		MaxAttempts:       viper.GetInt("JOBS_MAX_ATTEMPTS"),
+		RetryBackoff:      viper.GetDuration("JOBS_RETRY_BACKOFF"),
+	}
+
+	config.Statements = StatementsConfig{
+		SettleDelay: viper.GetDuration("STATEMENTS_SETTLE_DELAY"),
+	}
+
+	config.Snapshots = SnapshotsConfig{
+		Enabled:   viper.GetBool("SNAPSHOTS_ENABLED"),
+		Interval:  viper.GetDuration("SNAPSHOTS_INTERVAL"),
+		BatchSize: viper.GetInt("SNAPSHOTS_BATCH_SIZE"),
+		Lag:       viper.GetDuration("SNAPSHOTS_LAG"),
+	}
+
+	config.Reporting = ReportingConfig{
+		Enabled:   viper.GetBool("REPORTING_ENABLED"),
+		Interval:  viper.GetDuration("REPORTING_INTERVAL"),
+		BatchSize: viper.GetInt("REPORTING_BATCH_SIZE"),
+		Lag:       viper.GetDuration("REPORTING_LAG"),
+		MaxRange:  viper.GetDuration("REPORTING_MAX_RANGE"),
+	}
+
+	config.Stream = StreamConfig{
+		HeartbeatInterval: viper.GetDuration("STREAM_HEARTBEAT_INTERVAL"),
+		RetryDelay:        viper.GetDuration("STREAM_RETRY_DELAY"),
+		ReplayLimit:       viper.GetInt("STREAM_REPLAY_LIMIT"),
+	}
+
+	config.Audit = AuditConfig{
+		Roles:           splitList(viper.GetString("AUDIT_ROLES")),
+		VerifyBatchSize: viper.GetInt("AUDIT_VERIFY_BATCH_SIZE"),
+	}
+
+	config.Health = HealthConfig{
+		CheckTimeout:     viper.GetDuration("HEALTH_CHECK_TIMEOUT"),
+		CacheTTL:         viper.GetDuration("HEALTH_CACHE_TTL"),
+		ShutdownDelay:    viper.GetDuration("HEALTH_SHUTDOWN_DELAY"),
+		CheckMigrations:  viper.GetBool("HEALTH_CHECK_MIGRATIONS"),
+		JobBacklogMaxAge: viper.GetDuration("HEALTH_JOB_BACKLOG_MAX_AGE"),
+	}
+
+	config.Replicas = ReplicaConfig{
+		Hosts:                splitList(viper.GetString("DB_REPLICA_HOSTS")),
+		MaxLag:               viper.GetDuration("DB_REPLICA_MAX_LAG"),
+		ReadYourWritesWindow: viper.GetDuration("DB_REPLICA_READ_YOUR_WRITES_WINDOW"),
+		CheckInterval:        viper.GetDuration("DB_REPLICA_CHECK_INTERVAL"),
+	}
+
+	config.Cache = CacheConfig{
+		Enabled:    viper.GetBool("CACHE_ENABLED"),
+		WalletTTL:  viper.GetDuration("CACHE_WALLET_TTL"),
+		WalletSize: viper.GetInt("CACHE_WALLET_SIZE"),
+		RateTTL:    viper.GetDuration("CACHE_RATE_TTL"),
+		RateSize:   viper.GetInt("CACHE_RATE_SIZE"),
+	}
+
+	config.Partitions = PartitionsConfig{
+		Enabled:            viper.GetBool("WALLET_LOG_PARTITIONS_ENABLED"),
+		Interval:           viper.GetDuration("WALLET_LOG_PARTITIONS_INTERVAL"),
+		Ahead:              viper.GetInt("WALLET_LOG_PARTITIONS_AHEAD"),
+		RetentionMonths:    viper.GetInt("WALLET_LOG_RETENTION_MONTHS"),
+		ArchiveMode:        viper.GetString("WALLET_LOG_ARCHIVE_MODE"),
+		StatementBatchSize: viper.GetInt("WALLET_LOG_ARCHIVE_STATEMENT_BATCH_SIZE"),
+	}
+
+	config.Reload = ReloadConfig{
+		Enabled:  viper.GetBool("CONFIG_RELOAD_ENABLED"),
+		Debounce: viper.GetDuration("CONFIG_RELOAD_DEBOUNCE"),
+	}
+
+	config.FeatureFlags = FeatureFlagsConfig{
+		File:            viper.GetString("FEATURE_FLAGS_FILE"),
+		RefreshInterval: viper.GetDuration("FEATURE_FLAGS_REFRESH_INTERVAL"),
+	}
+
+	config.settings = effectiveSettings(keys, secretKeys)
+	config.files = append(files, secretFiles...)
+
 	// Validate config
-	if err := utils.ValidateStruct(&config); err != nil {
+	validationProblems, err := utils.ValidationProblems(&config)
+	if err != nil {
 		return nil, fmt.Errorf("invalid configuration: %w", err)
 	}
+	problems = append(problems, validationProblems...)
+
+	if !config.Currency.IsSupported(config.Currency.Default) {
+		problems = append(problems, fmt.Sprintf("default currency %q is not in CURRENCY_SUPPORTED",
+			config.Currency.Default))
+	}
+
+	if config.ReverseExchange.Enabled && len(config.ReverseExchange.SigningKey) < minSigningKeyLength {
+		problems = append(problems, fmt.Sprintf("REVERSE_EXCHANGE_SIGNING_KEY must be at least %d characters",
+			minSigningKeyLength))
+	}
+
+	if len(problems) > 0 {
+		return nil, &ValidationError{Problems: problems}
+	}
 
 	return &config, nil
 }
@@ -119,6 +574,8 @@ func setDefaults() {
 	// Server defaults
 	viper.SetDefault("SERVER_HOST", "localhost")
 	viper.SetDefault("SERVER_PORT", 3000)
+	viper.SetDefault("SERVER_PROXY_HEADER", "")
+	viper.SetDefault("SERVER_TRUSTED_PROXIES", "")
 
 	// Database defaults
 	viper.SetDefault("DB_HOST", "localhost")
@@ -127,6 +584,13 @@ func setDefaults() {
 	viper.SetDefault("DB_PASSWORD", "postgres")
 	viper.SetDefault("DB_NAME", "wallet_db")
 	viper.SetDefault("DB_SSL_MODE", "disable")
+	viper.SetDefault("DB_MAX_OPEN_CONNS", 25)
+	viper.SetDefault("DB_MAX_IDLE_CONNS", 10)
+	viper.SetDefault("DB_CONN_MAX_LIFETIME", "30m")
+	viper.SetDefault("DB_CONN_MAX_IDLE_TIME", "5m")
+	viper.SetDefault("DB_CONNECT_RETRIES", 5)
+	viper.SetDefault("DB_CONNECT_RETRY_DELAY", "1s")
+	viper.SetDefault("DB_CONNECT_RETRY_MAX_DELAY", "30s")
 
 	// App defaults
 	viper.SetDefault("APP_NAME", "wallet-service")
@@ -139,6 +603,163 @@ func setDefaults() {
 	viper.SetDefault("TRACING_ENDPOINT", "localhost:4317")
 	viper.SetDefault("TRACING_SAMPLING_RATIO", 0.1)
 	viper.SetDefault("METRICS_ENABLED", true)
+
+	// Reconciliation defaults
+	viper.SetDefault("RECONCILIATION_ENABLED", true)
+	viper.SetDefault("RECONCILIATION_INTERVAL", "1h")
+	viper.SetDefault("RECONCILIATION_BATCH_SIZE", 500)
+	viper.SetDefault("RECONCILIATION_TOLERANCE", 0)
+	viper.SetDefault("RECONCILIATION_FREEZE_ON_DRIFT", false)
+
+	// Spend limit defaults (0 = unlimited)
+	viper.SetDefault("SPEND_LIMIT_MAX_PER_TRANSACTION", 0)
+	viper.SetDefault("SPEND_LIMIT_DAILY_CAP", 0)
+	viper.SetDefault("SPEND_LIMIT_WEEKLY_CAP", 0)
+	viper.SetDefault("SPEND_LIMIT_MAX_PER_MINUTE", 0)
+
+	// Risk defaults
+	viper.SetDefault("RISK_ENABLED", false)
+	viper.SetDefault("RISK_RULES_FILE", "profiles/risk_rules.json")
+	viper.SetDefault("RISK_HISTORY_SIZE", 50)
+
+	// Currency defaults
+	viper.SetDefault("CURRENCY_DEFAULT", "platform")
+	viper.SetDefault("CURRENCY_SUPPORTED", "platform")
+
+	// Expiry defaults
+	viper.SetDefault("EXPIRY_ENABLED", true)
+	viper.SetDefault("EXPIRY_INTERVAL", "5m")
+	viper.SetDefault("EXPIRY_BATCH_SIZE", 500)
+	viper.SetDefault("EXPIRY_LOOKAHEAD", "720h")
+
+	// Reverse exchange defaults
+	viper.SetDefault("REVERSE_EXCHANGE_ENABLED", false)
+	viper.SetDefault("REVERSE_EXCHANGE_SIGNING_KEY", "")
+	viper.SetDefault("REVERSE_EXCHANGE_GRANT_TTL", "15m")
+
+	// Bulk defaults
+	viper.SetDefault("BULK_CHUNK_SIZE", 100)
+	viper.SetDefault("BULK_MAX_SYNC_ITEMS", 1000)
+	viper.SetDefault("BULK_MAX_ASYNC_ITEMS", 20000)
+
+	// Job defaults
+	viper.SetDefault("JOBS_ENABLED", true)
+	viper.SetDefault("JOBS_WORKERS", 2)
+	viper.SetDefault("JOBS_POLL_INTERVAL", "1s")
+	viper.SetDefault("JOBS_LEASE_DURATION", "1m")
+	viper.SetDefault("JOBS_HEARTBEAT_INTERVAL", "15s")
+	viper.SetDefault("JOBS_MAX_ATTEMPTS", 3)
+	viper.SetDefault("JOBS_RETRY_BACKOFF", "30s")
+
+	// Statement defaults
+	viper.SetDefault("STATEMENTS_SETTLE_DELAY", "1h")
+
+	// Snapshot defaults
+	viper.SetDefault("SNAPSHOTS_ENABLED", true)
+	viper.SetDefault("SNAPSHOTS_INTERVAL", "24h")
+	viper.SetDefault("SNAPSHOTS_BATCH_SIZE", 500)
+	viper.SetDefault("SNAPSHOTS_LAG", "5m")
+
+	// Reporting defaults
+	viper.SetDefault("REPORTING_ENABLED", true)
+	viper.SetDefault("REPORTING_INTERVAL", "1m")
+	viper.SetDefault("REPORTING_BATCH_SIZE", 10000)
+	viper.SetDefault("REPORTING_LAG", "1m")
+	viper.SetDefault("REPORTING_MAX_RANGE", "8784h")
+
+	// Stream defaults
+	viper.SetDefault("STREAM_HEARTBEAT_INTERVAL", "15s")
+	viper.SetDefault("STREAM_RETRY_DELAY", "3s")
+	viper.SetDefault("STREAM_REPLAY_LIMIT", 500)
+
+	// Audit defaults
+	viper.SetDefault("AUDIT_ROLES", "admin,game_server")
+	viper.SetDefault("AUDIT_VERIFY_BATCH_SIZE", 1000)
+
+	// Health defaults
+	viper.SetDefault("HEALTH_CHECK_TIMEOUT", "2s")
+	viper.SetDefault("HEALTH_CACHE_TTL", "2s")
+	viper.SetDefault("HEALTH_SHUTDOWN_DELAY", "5s")
+	viper.SetDefault("HEALTH_CHECK_MIGRATIONS", true)
+	viper.SetDefault("HEALTH_JOB_BACKLOG_MAX_AGE", "5m")
+
+	// Replica defaults
+	viper.SetDefault("DB_REPLICA_HOSTS", "")
+	viper.SetDefault("DB_REPLICA_MAX_LAG", "5s")
+	viper.SetDefault("DB_REPLICA_READ_YOUR_WRITES_WINDOW", "10s")
+	viper.SetDefault("DB_REPLICA_CHECK_INTERVAL", "5s")
+
+	// Cache defaults
+	viper.SetDefault("CACHE_ENABLED", true)
+	viper.SetDefault("CACHE_WALLET_TTL", "5s")
+	viper.SetDefault("CACHE_WALLET_SIZE", 10000)
+	viper.SetDefault("CACHE_RATE_TTL", "5m")
+	viper.SetDefault("CACHE_RATE_SIZE", 1000)
+
+	// Partition defaults
+	viper.SetDefault("WALLET_LOG_PARTITIONS_ENABLED", true)
+	viper.SetDefault("WALLET_LOG_PARTITIONS_INTERVAL", "1h")
+	viper.SetDefault("WALLET_LOG_PARTITIONS_AHEAD", 3)
+	viper.SetDefault("WALLET_LOG_RETENTION_MONTHS", 0)
+	viper.SetDefault("WALLET_LOG_ARCHIVE_MODE", "detach")
+	viper.SetDefault("WALLET_LOG_ARCHIVE_STATEMENT_BATCH_SIZE", 500)
+
+	// Config reload defaults
+	viper.SetDefault("CONFIG_RELOAD_ENABLED", true)
+	viper.SetDefault("CONFIG_RELOAD_DEBOUNCE", "1s")
+
+	// Feature flag defaults
+	viper.SetDefault("FEATURE_FLAGS_FILE", "profiles/feature_flags.json")
+	viper.SetDefault("FEATURE_FLAGS_REFRESH_INTERVAL", "30s")
+
+	// Rate limit defaults
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_STORE", "memory")
+	viper.SetDefault("RATE_LIMIT_DEFAULT", "300/1m")
+	viper.SetDefault("RATE_LIMIT_ROUTES", "POST /exchange=20/1m,POST /exchange/reverse=20/1m,POST /spend=60/1m")
+	viper.SetDefault("RATE_LIMIT_IP", "1200/1m")
+	viper.SetDefault("RATE_LIMIT_SERVICE_ROLES", "game_server")
+	viper.SetDefault("RATE_LIMIT_SERVICE_FACTOR", 20)
+	viper.SetDefault("RATE_LIMIT_PRUNE_INTERVAL", "1m")
+}
+
+// splitList splits a comma separated list, dropping empty items
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// readLimits reads the limits of the rate limit settings and returns the invalid ones
+func (c *RateLimitConfig) readLimits() []string {
+	var problems []string
+	var err error
+
+	if c.Default, err = ratelimit.ParseLimit(viper.GetString("RATE_LIMIT_DEFAULT")); err != nil {
+		problems = append(problems, "RATE_LIMIT_DEFAULT: "+err.Error())
+	}
+	if c.IP, err = ratelimit.ParseLimit(viper.GetString("RATE_LIMIT_IP")); err != nil {
+		problems = append(problems, "RATE_LIMIT_IP: "+err.Error())
+	}
+	if c.Routes, err = ratelimit.ParseRouteLimits(splitList(viper.GetString("RATE_LIMIT_ROUTES"))); err != nil {
+		problems = append(problems, "RATE_LIMIT_ROUTES: "+err.Error())
+	}
+
+	return problems
+}
+
+// IsService reports whether a role is the role of a service principal
+func (c *RateLimitConfig) IsService(role string) bool {
+	for _, serviceRole := range c.ServiceRoles {
+		if role == serviceRole {
+			return true
+		}
+	}
+	return false
 }
 
 // GetDSN returns database connection string
@@ -0,0 +1,111 @@
+package config_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeFile(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+func settingValue(cfg *config.Config, name string, redacted bool) string {
+	for _, setting := range cfg.Settings(redacted) {
+		if setting.Name == name {
+			return setting.Value
+		}
+	}
+	return ""
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("Defaults Without Files", func(t *testing.T) {
+		cfg, err := config.Load(config.Source{Dir: t.TempDir()})
+		require.NoError(t, err)
+		assert.Equal(t, "localhost", cfg.Server.Host)
+		assert.Equal(t, 3000, cfg.Server.Port)
+	})
+
+	t.Run("Profile Over Default Profile", func(t *testing.T) {
+		dir := t.TempDir()
+		writeFile(t, dir, "default.env", "DB_NAME=wallets\nSERVER_PORT=4000\n")
+		writeFile(t, dir, "staging.yaml", "app:\n  env: staging\nserver:\n  port: 5000\ncurrency:\n  supported:\n    - platform\n    - gems\n")
+
+		cfg, err := config.Load(config.Source{Profile: "staging", Dir: dir})
+		require.NoError(t, err)
+		assert.Equal(t, "wallets", cfg.Database.DBName)
+		assert.Equal(t, "staging", cfg.App.Env)
+		assert.Equal(t, 5000, cfg.Server.Port)
+		assert.Equal(t, []string{"platform", "gems"}, cfg.Currency.Supported)
+	})
+
+	t.Run("Environment Over Files", func(t *testing.T) {
+		path := writeFile(t, t.TempDir(), "wallet.toml", "[server]\nport = 5000\n")
+		t.Setenv("SERVER_PORT", "6000")
+
+		cfg, err := config.Load(config.Source{File: path})
+		require.NoError(t, err)
+		assert.Equal(t, 6000, cfg.Server.Port)
+	})
+
+	t.Run("Missing Profile", func(t *testing.T) {
+		_, err := config.Load(config.Source{Profile: "production", Dir: t.TempDir()})
+		assert.Error(t, err)
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		_, err := config.Load(config.Source{File: filepath.Join(t.TempDir(), "missing.yaml")})
+		assert.Error(t, err)
+	})
+
+	t.Run("Secret File", func(t *testing.T) {
+		secret := writeFile(t, t.TempDir(), "db_password", "s3cret\n")
+		t.Setenv("DB_PASSWORD", "ignored")
+		t.Setenv("DB_PASSWORD_FILE", secret)
+
+		cfg, err := config.Load(config.Source{Dir: t.TempDir()})
+		require.NoError(t, err)
+		assert.Equal(t, "s3cret", cfg.Database.Password)
+		assert.Equal(t, "s3cret", settingValue(cfg, "DB_PASSWORD", false))
+		assert.Equal(t, config.RedactedValue, settingValue(cfg, "DB_PASSWORD", true))
+	})
+
+	t.Run("Settings Read From Files Redacted", func(t *testing.T) {
+		host := writeFile(t, t.TempDir(), "db_host", "db.internal\n")
+		t.Setenv("DB_HOST_FILE", host)
+		t.Setenv("REVERSE_EXCHANGE_SIGNING_KEY", "0123456789abcdef0123456789abcdef")
+
+		cfg, err := config.Load(config.Source{Dir: t.TempDir()})
+		require.NoError(t, err)
+		assert.Equal(t, "db.internal", settingValue(cfg, "DB_HOST", false))
+		assert.Equal(t, config.RedactedValue, settingValue(cfg, "DB_HOST", true))
+		assert.Equal(t, config.RedactedValue, settingValue(cfg, "REVERSE_EXCHANGE_SIGNING_KEY", true))
+		assert.Equal(t, "5432", settingValue(cfg, "DB_PORT", true))
+	})
+
+	t.Run("Every Problem Reported", func(t *testing.T) {
+		path := writeFile(t, t.TempDir(), "wallet.yaml", "server:\n  port: 0\nlog:\n  level: loud\nunknown: 1\n")
+		t.Setenv("REVERSE_EXCHANGE_SIGNING_KEY_FILE", filepath.Join(t.TempDir(), "missing"))
+
+		_, err := config.Load(config.Source{File: path})
+		require.Error(t, err)
+
+		var validationErr *config.ValidationError
+		require.True(t, errors.As(err, &validationErr))
+		assert.Len(t, validationErr.Problems, 4)
+		assert.Contains(t, err.Error(), "unknown setting UNKNOWN")
+		assert.Contains(t, err.Error(), "REVERSE_EXCHANGE_SIGNING_KEY_FILE")
+		assert.Contains(t, err.Error(), "Server.Port")
+		assert.Contains(t, err.Error(), "App.LogLevel")
+	})
+}
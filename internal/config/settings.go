@@ -0,0 +1,141 @@
+package config
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// RedactedValue replaces the value of a secret setting in redacted settings
+const RedactedValue = "[REDACTED]"
+
+// secretNameWords mark the settings holding a secret: a setting is secret when a word of its name, between
+// underscores, is one of them, e.g. DB_PASSWORD or REVERSE_EXCHANGE_SIGNING_KEY
+var secretNameWords = map[string]bool{
+	"PASSWORD":   true,
+	"PASSWD":     true,
+	"SECRET":     true,
+	"KEY":        true,
+	"TOKEN":      true,
+	"CREDENTIAL": true,
+	"DSN":        true,
+}
+
+// isSecretName reports whether the name of a setting marks it as a secret
+func isSecretName(name string) bool {
+	for _, word := range strings.Split(name, "_") {
+		if secretNameWords[strings.TrimSuffix(word, "S")] {
+			return true
+		}
+	}
+	return false
+}
+
+// runtimeSettings are the settings a reload applies without a restart
+var runtimeSettings = map[string]bool{
+	"LOG_LEVEL":                       true,
+	"TRACING_SAMPLING_RATIO":          true,
+	"SPEND_LIMIT_MAX_PER_TRANSACTION": true,
+	"SPEND_LIMIT_DAILY_CAP":           true,
+	"SPEND_LIMIT_WEEKLY_CAP":          true,
+	"SPEND_LIMIT_MAX_PER_MINUTE":      true,
+	"RATE_LIMIT_ENABLED":              true,
+	"RATE_LIMIT_DEFAULT":              true,
+	"RATE_LIMIT_ROUTES":               true,
+	"RATE_LIMIT_IP":                   true,
+	"RATE_LIMIT_SERVICE_ROLES":        true,
+	"RATE_LIMIT_SERVICE_FACTOR":       true,
+}
+
+// ValidationError reports every problem found while loading the configuration
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid configuration: " + strings.Join(e.Problems, "; ")
+}
+
+// Setting is a setting with its effective value, named by its environment variable
+type Setting struct {
+	Name  string
+	Value string
+
+	// fromFile is set when the value was read from the file named by the setting's _FILE variable
+	fromFile bool
+}
+
+// IsSecret reports whether the setting holds a secret: its name marks it as one, or its value was read
+// from a secret file
+func (s Setting) IsSecret() bool {
+	return s.fromFile || isSecretName(s.Name)
+}
+
+// effectiveSettings returns the effective values of keys, sorted by name. fromFile holds the keys whose
+// values were read from secret files.
+func effectiveSettings(keys []string, fromFile map[string]bool) []Setting {
+	settings := make([]Setting, 0, len(keys))
+	for _, key := range keys {
+		settings = append(settings, Setting{
+			Name:     strings.ToUpper(key),
+			Value:    viper.GetString(key),
+			fromFile: fromFile[key],
+		})
+	}
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Name < settings[j].Name })
+	return settings
+}
+
+// Settings returns every setting with the value the configuration was loaded with, sorted by name. Secrets
+// are replaced by RedactedValue when redacted is set.
+func (c *Config) Settings(redacted bool) []Setting {
+	settings := make([]Setting, len(c.settings))
+	for i, setting := range c.settings {
+		if redacted && setting.IsSecret() && setting.Value != "" {
+			setting.Value = RedactedValue
+		}
+		settings[i] = setting
+	}
+	return settings
+}
+
+// Files returns the configuration and secret files the configuration was read from
+func (c *Config) Files() []string {
+	return append([]string(nil), c.files...)
+}
+
+// Change is a setting whose value differs between two configurations. Secret values are redacted.
+type Change struct {
+	Name string
+	Old  string
+	New  string
+}
+
+// Runtime reports whether the change can be applied without a restart
+func (c Change) Runtime() bool {
+	return runtimeSettings[c.Name]
+}
+
+// Diff returns the settings whose values differ from one configuration to the next, sorted by name
+func Diff(from, to *Config) []Change {
+	previous := make(map[string]Setting, len(from.settings))
+	for _, setting := range from.settings {
+		previous[setting.Name] = setting
+	}
+
+	var changes []Change
+	for _, setting := range to.settings {
+		old, ok := previous[setting.Name]
+		if ok && old.Value == setting.Value {
+			continue
+		}
+
+		change := Change{Name: setting.Name, Old: old.Value, New: setting.Value}
+		if setting.IsSecret() || old.IsSecret() {
+			change.Old, change.New = RedactedValue, RedactedValue
+		}
+		changes = append(changes, change)
+	}
+	return changes
+}
@@ -0,0 +1,200 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/viper"
+)
+
+// Environment variables that select the configuration files
+const (
+	// EnvConfigFile names an explicit configuration file; the --config flag sets it
+	EnvConfigFile = "CONFIG_FILE"
+	// EnvProfile names the profile read on top of the default profile, e.g. development, staging or production
+	EnvProfile = "APP_PROFILE"
+	// EnvConfigDir is the directory holding the profiles
+	EnvConfigDir = "CONFIG_DIR"
+)
+
+const (
+	defaultProfile     = "default"
+	defaultProfilesDir = "profiles"
+	// secretFileSuffix marks the variable naming a file that holds a setting, e.g. DB_PASSWORD_FILE
+	secretFileSuffix = "_FILE"
+)
+
+// profileExtensions are the file formats of a profile, in lookup order
+var profileExtensions = []string{".yaml", ".yml", ".toml", ".json", ".env"}
+
+// Source names the configuration files read before environment variables
+type Source struct {
+	// File is an explicit configuration file. Profiles are not read when it is set.
+	File string
+	// Profile is read from Dir on top of the default profile
+	Profile string
+	// Dir holds the profiles as <name>.yaml, .yml, .toml, .json or .env. A relative Dir that does not exist
+	// in the working directory is looked up next to the executable.
+	Dir string
+}
+
+// SourceFromEnv returns the source named by CONFIG_FILE, APP_PROFILE and CONFIG_DIR
+func SourceFromEnv() Source {
+	return Source{
+		File:    os.Getenv(EnvConfigFile),
+		Profile: os.Getenv(EnvProfile),
+		Dir:     os.Getenv(EnvConfigDir),
+	}
+}
+
+// files returns the configuration files of the source in the order they are read. The default profile is
+// optional; an explicit file or a named profile must exist.
+func (s Source) files() ([]string, error) {
+	if s.File != "" {
+		if _, err := os.Stat(s.File); err != nil {
+			return nil, fmt.Errorf("config file: %w", err)
+		}
+		return []string{s.File}, nil
+	}
+
+	dir := profilesDir(s.Dir)
+
+	var files []string
+	if path, ok := findProfile(dir, defaultProfile); ok {
+		files = append(files, path)
+	}
+
+	if s.Profile != "" && s.Profile != defaultProfile {
+		path, ok := findProfile(dir, s.Profile)
+		if !ok {
+			return nil, fmt.Errorf("profile %q not found in %s", s.Profile, dir)
+		}
+		files = append(files, path)
+	}
+
+	return files, nil
+}
+
+// profilesDir resolves the profiles directory, falling back to the directory next to the executable so a
+// binary started from another working directory still finds its profiles
+func profilesDir(dir string) string {
+	if dir == "" {
+		dir = defaultProfilesDir
+	}
+	if filepath.IsAbs(dir) || isDir(dir) {
+		return dir
+	}
+
+	if executable, err := os.Executable(); err == nil {
+		if candidate := filepath.Join(filepath.Dir(executable), dir); isDir(candidate) {
+			return candidate
+		}
+	}
+	return dir
+}
+
+// findProfile returns the file of a profile in dir
+func findProfile(dir, name string) (string, bool) {
+	for _, ext := range profileExtensions {
+		path := filepath.Join(dir, name+ext)
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
+		}
+	}
+	return "", false
+}
+
+func isDir(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.IsDir()
+}
+
+// readConfigFile reads a YAML, TOML, JSON or env file into settings named like their environment variables.
+// Nested keys are joined with underscores, so host under db sets DB_HOST, and lists become comma separated
+// values.
+func readConfigFile(path string) (map[string]interface{}, error) {
+	file := viper.New()
+	file.SetConfigFile(path)
+	if filepath.Ext(path) == ".env" {
+		file.SetConfigType("env")
+	}
+
+	if err := file.ReadInConfig(); err != nil {
+		return nil, fmt.Errorf("read config file %s: %w", path, err)
+	}
+
+	settings := make(map[string]interface{})
+	for _, key := range file.AllKeys() {
+		value := file.Get(key)
+		if list, ok := value.([]interface{}); ok {
+			items := make([]string, len(list))
+			for i, item := range list {
+				items[i] = fmt.Sprint(item)
+			}
+			value = strings.Join(items, ",")
+		}
+		settings[strings.ReplaceAll(key, ".", "_")] = value
+	}
+	return settings, nil
+}
+
+// readSource merges the files of the source over the defaults. It returns the files read and a problem for
+// every setting that is not known.
+func readSource(source Source, known map[string]bool) ([]string, []string, error) {
+	files, err := source.files()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []string
+	for _, path := range files {
+		settings, err := readConfigFile(path)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		var unknown []string
+		for key := range settings {
+			if !known[key] && !known[strings.TrimSuffix(key, strings.ToLower(secretFileSuffix))] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		for _, key := range unknown {
+			problems = append(problems, fmt.Sprintf("%s: unknown setting %s", path, strings.ToUpper(key)))
+		}
+
+		if err := viper.MergeConfigMap(settings); err != nil {
+			return nil, nil, fmt.Errorf("merge config file %s: %w", path, err)
+		}
+	}
+
+	return files, problems, nil
+}
+
+// readSecretFiles replaces every setting whose <NAME>_FILE variable or setting is set with the content of
+// that file, without its trailing newline. It returns the files named, the keys of the settings they are
+// set for and a problem for every file that cannot be read.
+func readSecretFiles(known []string) ([]string, map[string]bool, []string) {
+	var files, problems []string
+	keys := make(map[string]bool)
+	for _, key := range known {
+		path := viper.GetString(key + strings.ToLower(secretFileSuffix))
+		if path == "" {
+			continue
+		}
+
+		files = append(files, path)
+		keys[key] = true
+		value, err := os.ReadFile(path)
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s%s: %v", strings.ToUpper(key), secretFileSuffix, err))
+			continue
+		}
+		viper.Set(key, strings.TrimRight(string(value), "\r\n"))
+	}
+	return files, keys, problems
+}
@@ -0,0 +1,125 @@
+// Package feature evaluates the feature flags used to roll out wallet operations gradually
+package feature
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// namePattern restricts flag names to lowercase words separated by dots or underscores,
+// e.g. transfers or spend_reason.competition_entry
+var namePattern = regexp.MustCompile(`^[a-z0-9_]+(\.[a-z0-9_]+)*$`)
+
+// Flag decides for whom a feature is on. A disabled flag is off for everyone; an enabled flag is on for the
+// subjects holding one of its roles, acting for one of its games, or whose user falls in its percentage.
+type Flag struct {
+	// Name identifies the flag in code, the admin API and overrides
+	Name string `json:"name"`
+
+	// Description tells operators what the flag controls
+	Description string `json:"description,omitempty"`
+
+	// Enabled switches the flag on for the subjects it targets
+	Enabled bool `json:"enabled"`
+
+	// Percentage of users the flag is on for, from 0 to 100. A user stays in or out of a flag as the
+	// percentage changes: raising it only adds users.
+	Percentage int `json:"percentage"`
+
+	// Roles the flag is on for, whatever the percentage
+	Roles []string `json:"roles,omitempty"`
+
+	// GameIDs the flag is on for, whatever the percentage, when the operation concerns a game
+	GameIDs []string `json:"game_ids,omitempty"`
+}
+
+// Subject is who a flag is evaluated for
+type Subject struct {
+	UserID int
+	Role   string
+	GameID string
+}
+
+type flagsFile struct {
+	Flags []*Flag `json:"flags"`
+}
+
+// Validate reports the first invalid field of the flag
+func (f *Flag) Validate() error {
+	if !namePattern.MatchString(f.Name) {
+		return fmt.Errorf("invalid name %q", f.Name)
+	}
+	if f.Percentage < 0 || f.Percentage > 100 {
+		return fmt.Errorf("percentage must be between 0 and 100, got %d", f.Percentage)
+	}
+	return nil
+}
+
+// EnabledFor reports whether the flag is on for the subject
+func (f *Flag) EnabledFor(subject Subject) bool {
+	if !f.Enabled {
+		return false
+	}
+
+	if subject.Role != "" && contains(f.Roles, subject.Role) {
+		return true
+	}
+
+	if subject.GameID != "" && contains(f.GameIDs, subject.GameID) {
+		return true
+	}
+
+	return f.Percentage >= 100 || (subject.UserID > 0 && Bucket(f.Name, subject.UserID) < f.Percentage)
+}
+
+// Bucket places a user in one of 100 buckets of a flag. Buckets are stable, and differ from one flag to the
+// next so the same users are not always the first to get new features.
+func Bucket(name string, userID int) int {
+	hash := fnv.New32a()
+	hash.Write([]byte(name))
+	hash.Write([]byte{':'})
+	hash.Write([]byte(strconv.Itoa(userID)))
+	return int(hash.Sum32() % 100)
+}
+
+// LoadFlags reads the flags defined in a JSON file
+func LoadFlags(path string) ([]*Flag, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read feature flags: %w", err)
+	}
+
+	var file flagsFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse feature flags %s: %w", path, err)
+	}
+
+	seen := make(map[string]bool, len(file.Flags))
+	for i, flag := range file.Flags {
+		if flag == nil {
+			return nil, fmt.Errorf("invalid feature flags %s: flag %d is empty", path, i)
+		}
+		if err := flag.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid feature flags %s: flag %d: %w", path, i, err)
+		}
+		if seen[flag.Name] {
+			return nil, fmt.Errorf("invalid feature flags %s: duplicate flag %q", path, flag.Name)
+		}
+		seen[flag.Name] = true
+	}
+
+	return file.Flags, nil
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
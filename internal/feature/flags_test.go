@@ -0,0 +1,77 @@
+package feature
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFlagEnabledFor(t *testing.T) {
+	flag := &Flag{Name: "transfers", Enabled: true, Roles: []string{"admin"}, GameIDs: []string{"chess"}}
+
+	testCases := []struct {
+		name     string
+		flag     *Flag
+		subject  Subject
+		expected bool
+	}{
+		{"Disabled", &Flag{Name: "transfers", Percentage: 100}, Subject{UserID: 1}, false},
+		{"Everyone", &Flag{Name: "transfers", Enabled: true, Percentage: 100}, Subject{UserID: 1}, true},
+		{"Nobody", flag, Subject{UserID: 1, Role: "user"}, false},
+		{"Role", flag, Subject{UserID: 1, Role: "admin"}, true},
+		{"Game", flag, Subject{UserID: 1, Role: "user", GameID: "chess"}, true},
+		{"Other Game", flag, Subject{UserID: 1, Role: "user", GameID: "poker"}, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.flag.EnabledFor(tc.subject))
+		})
+	}
+}
+
+func TestFlagPercentage(t *testing.T) {
+	flag := &Flag{Name: "transfers", Enabled: true, Percentage: 30}
+
+	enabled := make(map[int]bool)
+	for userID := 1; userID <= 1000; userID++ {
+		if flag.EnabledFor(Subject{UserID: userID}) {
+			enabled[userID] = true
+		}
+	}
+	assert.InDelta(t, 300, len(enabled), 60)
+
+	// Raising the percentage keeps every user already in the rollout
+	flag.Percentage = 60
+	for userID := range enabled {
+		assert.True(t, flag.EnabledFor(Subject{UserID: userID}))
+	}
+
+	// Subjects without a user only get flags on for everyone
+	assert.False(t, flag.EnabledFor(Subject{}))
+}
+
+func TestLoadFlags(t *testing.T) {
+	write := func(content string) string {
+		path := filepath.Join(t.TempDir(), "flags.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	flags, err := LoadFlags(write(`{"flags": [{"name": "spend_reason.competition_entry", "enabled": true, "percentage": 10}]}`))
+	require.NoError(t, err)
+	require.Len(t, flags, 1)
+	assert.Equal(t, 10, flags[0].Percentage)
+
+	_, err = LoadFlags(write(`{"flags": [{"name": "Transfers"}]}`))
+	assert.Error(t, err)
+
+	_, err = LoadFlags(write(`{"flags": [{"name": "transfers", "percentage": 101}]}`))
+	assert.Error(t, err)
+
+	_, err = LoadFlags(write(`{"flags": [{"name": "transfers"}, {"name": "transfers"}]}`))
+	assert.Error(t, err)
+}
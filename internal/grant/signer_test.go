@@ -0,0 +1,100 @@
+package grant
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSignerSignAndVerify(t *testing.T) {
+	issuedAt := time.Date(2025, 5, 16, 20, 0, 0, 0, time.UTC)
+	claims := Claims{
+		GrantID:   "4f1c2a9e0b7d4c3f8a6e5d2b1c0f9e8d",
+		UserID:    123,
+		GameID:    "game-abc",
+		TokenType: "gold",
+		Amount:    450,
+		IssuedAt:  issuedAt,
+		ExpiresAt: issuedAt.Add(15 * time.Minute),
+	}
+
+	signer := NewSigner("0123456789abcdef0123456789abcdef")
+	token, err := signer.Sign(claims)
+	require.NoError(t, err)
+
+	again, err := signer.Sign(claims)
+	require.NoError(t, err)
+	assert.Equal(t, token, again)
+
+	payload, signature, _ := strings.Cut(token, ".")
+
+	testCases := []struct {
+		name          string
+		signer        *Signer
+		token         string
+		now           time.Time
+		expectedError error
+	}{
+		{
+			name:   "Valid Token",
+			signer: signer,
+			token:  token,
+			now:    issuedAt.Add(time.Minute),
+		},
+		{
+			name:          "Expired Token",
+			signer:        signer,
+			token:         token,
+			now:           issuedAt.Add(15 * time.Minute),
+			expectedError: ErrTokenExpired,
+		},
+		{
+			name:          "Other Key",
+			signer:        NewSigner("fedcba9876543210fedcba9876543210"),
+			token:         token,
+			now:           issuedAt,
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name:          "Tampered Claims",
+			signer:        signer,
+			token:         payload + "x." + signature,
+			now:           issuedAt,
+			expectedError: ErrInvalidToken,
+		},
+		{
+			name:          "Missing Signature",
+			signer:        signer,
+			token:         payload,
+			now:           issuedAt,
+			expectedError: ErrInvalidToken,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			verified, err := tc.signer.Verify(tc.token, tc.now)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, claims, *verified)
+		})
+	}
+}
+
+func TestNewGrantID(t *testing.T) {
+	first, err := NewGrantID()
+	require.NoError(t, err)
+	second, err := NewGrantID()
+	require.NoError(t, err)
+
+	assert.Len(t, first, 32)
+	assert.NotEqual(t, first, second)
+}
@@ -0,0 +1,208 @@
+// Package health runs the liveness and readiness checks behind the probe endpoints
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Probe and check statuses. A probe is degraded when only non-critical checks fail; it keeps passing.
+const (
+	StatusOK       = "ok"
+	StatusDegraded = "degraded"
+	StatusFailing  = "failing"
+)
+
+// CheckFunc checks a dependency. It returns a short detail for the probe response, or an error when the
+// dependency is not usable.
+type CheckFunc func(ctx context.Context) (string, error)
+
+// Result is the outcome of one check
+type Result struct {
+	Name      string
+	Status    string
+	Critical  bool
+	Detail    string
+	Error     string
+	Duration  time.Duration
+	CheckedAt time.Time
+}
+
+// Report is the outcome of the checks of a probe
+type Report struct {
+	Status string
+	Checks []Result
+}
+
+// Failing reports whether the probe fails
+func (r *Report) Failing() bool {
+	return r.Status == StatusFailing
+}
+
+type check struct {
+	name     string
+	critical bool
+	fn       CheckFunc
+}
+
+// Registry holds the checks of the liveness and readiness probes. Results are cached for a short time,
+// so frequent probes from several sources do not put load on the dependencies.
+type Registry struct {
+	timeout  time.Duration
+	cacheTTL time.Duration
+	now      func() time.Time
+
+	mu        sync.Mutex
+	liveness  []check
+	readiness []check
+	results   map[string]Result
+
+	shuttingDown atomic.Bool
+}
+
+// NewRegistry creates a registry without checks. Each check may run for up to timeout; its result is
+// reused for cacheTTL.
+func NewRegistry(timeout, cacheTTL time.Duration) *Registry {
+	return &Registry{
+		timeout:  timeout,
+		cacheTTL: cacheTTL,
+		now:      time.Now,
+		results:  make(map[string]Result),
+	}
+}
+
+// AddLivenessCheck adds a check to the liveness probe. A failing liveness check means the process cannot
+// recover on its own, so it should only cover the process itself and never an external dependency.
+func (r *Registry) AddLivenessCheck(name string, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.liveness = append(r.liveness, check{name: name, critical: true, fn: fn})
+}
+
+// AddReadinessCheck adds a check to the readiness probe. The probe fails when a critical check fails;
+// other failing checks are reported without taking the instance out of service.
+func (r *Registry) AddReadinessCheck(name string, critical bool, fn CheckFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.readiness = append(r.readiness, check{name: name, critical: critical, fn: fn})
+}
+
+// SetShuttingDown fails the readiness probe from now on, so load balancers stop sending requests
+// before the server drains its connections
+func (r *Registry) SetShuttingDown() {
+	r.shuttingDown.Store(true)
+}
+
+// Live runs the liveness checks
+func (r *Registry) Live(ctx context.Context) *Report {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.run(ctx, "live", r.liveness)
+}
+
+// Ready runs the readiness checks, or fails right away once the service is shutting down
+func (r *Registry) Ready(ctx context.Context) *Report {
+	if r.shuttingDown.Load() {
+		return &Report{
+			Status: StatusFailing,
+			Checks: []Result{{
+				Name:      "shutdown",
+				Status:    StatusFailing,
+				Critical:  true,
+				Error:     "service is shutting down",
+				CheckedAt: r.now(),
+			}},
+		}
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.run(ctx, "ready", r.readiness)
+}
+
+// run returns the cached result of each check, running the checks whose result expired concurrently.
+// The caller holds r.mu, so concurrent probes wait for one run and share its results.
+func (r *Registry) run(ctx context.Context, probe string, checks []check) *Report {
+	report := &Report{Status: StatusOK, Checks: make([]Result, len(checks))}
+
+	var wg sync.WaitGroup
+	for i, c := range checks {
+		key := probe + "/" + c.name
+		if cached, ok := r.results[key]; ok && r.now().Sub(cached.CheckedAt) < r.cacheTTL {
+			report.Checks[i] = cached
+			continue
+		}
+
+		wg.Add(1)
+		go func(i int, c check) {
+			defer wg.Done()
+			report.Checks[i] = r.runCheck(ctx, c)
+		}(i, c)
+	}
+	wg.Wait()
+
+	for i, c := range checks {
+		result := report.Checks[i]
+		r.results[probe+"/"+c.name] = result
+
+		if result.Status == StatusOK {
+			continue
+		}
+		if result.Critical {
+			report.Status = StatusFailing
+		} else if report.Status == StatusOK {
+			report.Status = StatusDegraded
+		}
+	}
+
+	return report
+}
+
+// runCheck runs a check with the registry's timeout. A check that ignores its context is abandoned
+// once the timeout expires.
+func (r *Registry) runCheck(ctx context.Context, c check) Result {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	type outcome struct {
+		detail string
+		err    error
+	}
+	done := make(chan outcome, 1)
+	start := r.now()
+
+	go func() {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				done <- outcome{err: fmt.Errorf("check panicked: %v", recovered)}
+			}
+		}()
+		detail, err := c.fn(ctx)
+		done <- outcome{detail: detail, err: err}
+	}()
+
+	var result outcome
+	select {
+	case result = <-done:
+	case <-ctx.Done():
+		result = outcome{err: fmt.Errorf("timed out after %s", r.timeout)}
+	}
+
+	checked := Result{
+		Name:      c.name,
+		Status:    StatusOK,
+		Critical:  c.critical,
+		Detail:    result.detail,
+		Duration:  r.now().Sub(start),
+		CheckedAt: r.now(),
+	}
+	if result.err != nil {
+		checked.Status = StatusFailing
+		checked.Error = result.err.Error()
+	}
+
+	return checked
+}
@@ -0,0 +1,158 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func passing(detail string) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		return detail, nil
+	}
+}
+
+func failing(message string) CheckFunc {
+	return func(ctx context.Context) (string, error) {
+		return "", errors.New(message)
+	}
+}
+
+func TestRegistryStatus(t *testing.T) {
+	testCases := []struct {
+		name     string
+		setup    func(r *Registry)
+		expected string
+	}{
+		{
+			name:     "No Checks",
+			setup:    func(r *Registry) {},
+			expected: StatusOK,
+		},
+		{
+			name: "All Passing",
+			setup: func(r *Registry) {
+				r.AddReadinessCheck("database", true, passing(""))
+				r.AddReadinessCheck("jobs", false, passing("0 jobs due"))
+			},
+			expected: StatusOK,
+		},
+		{
+			name: "Non-Critical Failing",
+			setup: func(r *Registry) {
+				r.AddReadinessCheck("database", true, passing(""))
+				r.AddReadinessCheck("jobs", false, failing("backlog"))
+			},
+			expected: StatusDegraded,
+		},
+		{
+			name: "Critical Failing",
+			setup: func(r *Registry) {
+				r.AddReadinessCheck("database", true, failing("connection refused"))
+				r.AddReadinessCheck("jobs", false, failing("backlog"))
+			},
+			expected: StatusFailing,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			registry := NewRegistry(time.Second, 0)
+			tc.setup(registry)
+
+			report := registry.Ready(context.Background())
+			assert.Equal(t, tc.expected, report.Status)
+			assert.Equal(t, tc.expected == StatusFailing, report.Failing())
+		})
+	}
+}
+
+func TestRegistryResults(t *testing.T) {
+	registry := NewRegistry(time.Second, 0)
+	registry.AddReadinessCheck("migrations", true, passing("version 17"))
+	registry.AddReadinessCheck("database", true, failing("connection refused"))
+
+	report := registry.Ready(context.Background())
+	require.Len(t, report.Checks, 2)
+
+	assert.Equal(t, "migrations", report.Checks[0].Name)
+	assert.Equal(t, StatusOK, report.Checks[0].Status)
+	assert.Equal(t, "version 17", report.Checks[0].Detail)
+	assert.Empty(t, report.Checks[0].Error)
+
+	assert.Equal(t, "database", report.Checks[1].Name)
+	assert.Equal(t, StatusFailing, report.Checks[1].Status)
+	assert.True(t, report.Checks[1].Critical)
+	assert.Equal(t, "connection refused", report.Checks[1].Error)
+}
+
+func TestRegistryCache(t *testing.T) {
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.UTC)
+	registry := NewRegistry(time.Second, 5*time.Second)
+	registry.now = func() time.Time { return now }
+
+	var runs atomic.Int32
+	registry.AddReadinessCheck("database", true, func(ctx context.Context) (string, error) {
+		runs.Add(1)
+		return "", nil
+	})
+
+	registry.Ready(context.Background())
+	registry.Ready(context.Background())
+	assert.Equal(t, int32(1), runs.Load())
+
+	now = now.Add(5 * time.Second)
+	registry.Ready(context.Background())
+	assert.Equal(t, int32(2), runs.Load())
+
+	// Liveness and readiness results are cached separately
+	registry.AddLivenessCheck("database", func(ctx context.Context) (string, error) {
+		runs.Add(1)
+		return "", nil
+	})
+	registry.Live(context.Background())
+	assert.Equal(t, int32(3), runs.Load())
+}
+
+func TestRegistryTimeout(t *testing.T) {
+	registry := NewRegistry(20*time.Millisecond, 0)
+	release := make(chan struct{})
+	defer close(release)
+
+	registry.AddReadinessCheck("stuck", true, func(ctx context.Context) (string, error) {
+		// Ignores its context
+		<-release
+		return "", nil
+	})
+	registry.AddReadinessCheck("panicking", false, func(ctx context.Context) (string, error) {
+		panic("boom")
+	})
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusFailing, report.Status)
+	assert.Contains(t, report.Checks[0].Error, "timed out")
+	assert.Contains(t, report.Checks[1].Error, "panicked")
+}
+
+func TestRegistryShutdown(t *testing.T) {
+	registry := NewRegistry(time.Second, 0)
+	registry.AddReadinessCheck("database", true, passing(""))
+	registry.AddLivenessCheck("process", passing(""))
+
+	require.Equal(t, StatusOK, registry.Ready(context.Background()).Status)
+
+	registry.SetShuttingDown()
+
+	report := registry.Ready(context.Background())
+	assert.Equal(t, StatusFailing, report.Status)
+	require.Len(t, report.Checks, 1)
+	assert.Equal(t, "shutdown", report.Checks[0].Name)
+
+	// The process stays alive while it drains
+	assert.Equal(t, StatusOK, registry.Live(context.Background()).Status)
+}
@@ -0,0 +1,47 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// Audit outcomes
+const (
+	AuditSucceeded = "succeeded"
+	AuditDenied    = "denied"
+	AuditFailed    = "failed"
+)
+
+// AuditGenesisHash is the previous hash of the first audit entry
+var AuditGenesisHash = strings.Repeat("0", 64)
+
+// AuditEntry records one privileged call: who made it, what it targeted and how it ended. Hash covers
+// the entry's fields and PrevHash, the hash of the entry before it, which chains the entries together.
+type AuditEntry struct {
+	ID            int64
+	PrincipalID   int
+	PrincipalRole string
+	Action        string
+	Method        string
+	Path          string
+	TargetUserID  *int
+	PayloadHash   string
+	RequestID     string
+	StatusCode    int
+	Outcome       string
+	CreatedAt     time.Time
+	PrevHash      string
+	Hash          string
+}
+
+// AuditFilter selects entries of the admin audit log; nil and empty fields match all entries
+type AuditFilter struct {
+	PrincipalID  *int
+	TargetUserID *int
+	Action       string
+	Outcome      string
+	From         *time.Time
+	To           *time.Time
+	Limit        int
+	Offset       int
+}
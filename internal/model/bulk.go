@@ -0,0 +1,56 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Bulk item operations
+const (
+	BulkOperationCredit = "credit"
+	BulkOperationSpend  = "spend"
+)
+
+// Bulk item results
+const (
+	BulkItemSucceeded = "succeeded"
+	BulkItemFailed    = "failed"
+	BulkItemDuplicate = "duplicate"
+)
+
+// Bulk job status
+const (
+	BulkJobPending   = "pending"
+	BulkJobRunning   = "running"
+	BulkJobCompleted = "completed"
+	BulkJobFailed    = "failed"
+)
+
+// IdempotencyKey records the bulk item applied under a key so a retried item is not applied twice.
+// NewBalance is set once the item is applied.
+type IdempotencyKey struct {
+	Key        string
+	UserID     int
+	Operation  string
+	Currency   string
+	Amount     float64
+	NewBalance *float64
+	CreatedAt  time.Time
+}
+
+// BulkJob represents a bulk operation processed in the background
+type BulkJob struct {
+	ID          int64
+	Status      string
+	TotalItems  int
+	Succeeded   int
+	Failed      int
+	Duplicates  int
+	Request     json.RawMessage
+	Results     json.RawMessage
+	Error       *string
+	RequestedBy int
+	CreatedAt   time.Time
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+}
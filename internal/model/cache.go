@@ -0,0 +1,21 @@
+package model
+
+// CacheInvalidationChannel is the Postgres notification channel changes of cached rows are announced on
+const CacheInvalidationChannel = "cache_invalidations"
+
+// Tables whose changes are announced on the cache invalidation channel
+const (
+	CacheInvalidationWallets       = "wallets"
+	CacheInvalidationExchangeRates = "exchange_rates"
+)
+
+// CacheInvalidation announces a committed change of a wallet or an exchange rate. Wallet changes carry
+// the user ID; rate changes carry the rate's ID, game, token type and currency.
+type CacheInvalidation struct {
+	Table     string `json:"table"`
+	UserID    int    `json:"user_id,omitempty"`
+	RateID    int64  `json:"rate_id,omitempty"`
+	GameID    string `json:"game_id,omitempty"`
+	TokenType string `json:"token_type,omitempty"`
+	Currency  string `json:"currency,omitempty"`
+}
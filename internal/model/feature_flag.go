@@ -0,0 +1,17 @@
+package model
+
+import (
+	"time"
+)
+
+// FeatureFlagOverride replaces the targeting of a feature flag defined in the feature flags file
+type FeatureFlagOverride struct {
+	Name       string
+	Enabled    bool
+	Percentage int
+	Roles      []string
+	GameIDs    []string
+	Reason     string
+	UpdatedBy  *int
+	UpdatedAt  time.Time
+}
@@ -0,0 +1,16 @@
+package model
+
+import "time"
+
+// MigrationState is the schema version recorded by the migration tool. A dirty state means the migration
+// to Version failed halfway and must be fixed by hand.
+type MigrationState struct {
+	Version int64
+	Dirty   bool
+}
+
+// JobBacklog counts the queued jobs that are due but not yet picked up by a worker
+type JobBacklog struct {
+	Due         int
+	OldestRunAt *time.Time
+}
@@ -0,0 +1,50 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job status
+const (
+	JobQueued    = "queued"
+	JobRunning   = "running"
+	JobSucceeded = "succeeded"
+	JobFailed    = "failed"
+	JobCancelled = "cancelled"
+)
+
+// Job kinds
+const (
+	JobKindBulk = "bulk"
+)
+
+// Job is a unit of background work of a registered kind. A running job is leased by one worker until
+// LockedUntil; the worker extends the lease with heartbeats while it runs the job.
+type Job struct {
+	ID              int64
+	Kind            string
+	Status          string
+	Payload         json.RawMessage
+	Result          json.RawMessage
+	Error           *string
+	Attempts        int
+	MaxAttempts     int
+	RunAt           time.Time
+	LockedBy        *string
+	LockedUntil     *time.Time
+	HeartbeatAt     *time.Time
+	CancelRequested bool
+	CreatedBy       *int
+	CreatedAt       time.Time
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+}
+
+// JobFilter selects jobs of the admin job list; empty fields match all jobs
+type JobFilter struct {
+	Kind   string
+	Status string
+	Limit  int
+	Offset int
+}
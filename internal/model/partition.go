@@ -0,0 +1,34 @@
+package model
+
+import (
+	"time"
+)
+
+// Archive modes of wallet_logs partitions
+const (
+	// WalletLogArchiveDetach keeps an archived partition as a table in the wallet_logs_archive schema
+	WalletLogArchiveDetach = "detach"
+	// WalletLogArchiveDrop drops an archived partition
+	WalletLogArchiveDrop = "drop"
+)
+
+// WalletLogPartition is a monthly partition of wallet_logs holding the logs created from From (inclusive)
+// to To (exclusive)
+type WalletLogPartition struct {
+	Name string
+	From time.Time
+	To   time.Time
+}
+
+// WalletLogArchive records a partition of wallet_logs that was archived. Its logs were added to the
+// archived balances of their wallets before the partition was detached.
+type WalletLogArchive struct {
+	ID            int64
+	PartitionName string
+	RangeStart    time.Time
+	RangeEnd      time.Time
+	LogCount      int
+	WalletCount   int
+	Mode          string
+	ArchivedAt    time.Time
+}
@@ -0,0 +1,24 @@
+package model
+
+import "context"
+
+// Principal identifies who performs an operation
+type Principal struct {
+	UserID int
+	Role   string
+	// GameID is the game a game server acts for, from the X-Game-Id header; empty for other callers
+	GameID string
+}
+
+type principalContextKey struct{}
+
+// WithPrincipal returns a copy of ctx carrying the principal performing the operation
+func WithPrincipal(ctx context.Context, principal Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(Principal)
+	return principal, ok
+}
@@ -0,0 +1,57 @@
+package model
+
+import (
+	"time"
+)
+
+// WalletLedgerBalance pairs a wallet's stored balance with the balance implied by its logs
+type WalletLedgerBalance struct {
+	WalletID      int64
+	UserID        int
+	Balance       float64
+	LedgerBalance float64
+	Status        string
+}
+
+// ReconciliationRun represents a single pass of the reconciliation job over all wallets
+type ReconciliationRun struct {
+	ID             int64
+	Trigger        string
+	Status         string
+	WalletsChecked int
+	Mismatches     int
+	TotalDrift     float64
+	Error          *string
+	StartedAt      time.Time
+	FinishedAt     *time.Time
+}
+
+// ReconciliationMismatch represents a wallet whose balance drifted from its log history
+type ReconciliationMismatch struct {
+	ID             int64
+	RunID          int64
+	WalletID       int64
+	UserID         int
+	Balance        float64
+	LedgerBalance  float64
+	Drift          float64
+	Frozen         bool
+	ResolvedBy     *int
+	ResolvedAt     *time.Time
+	ResolutionNote *string
+	CreatedAt      time.Time
+}
+
+// Reconciliation triggers
+const (
+	ReconciliationTriggerSchedule = "schedule"
+	ReconciliationTriggerAdmin    = "admin"
+	ReconciliationTriggerCLI      = "cli"
+)
+
+// Reconciliation run status
+const (
+	ReconciliationRunning   = "running"
+	ReconciliationCompleted = "completed"
+	ReconciliationFailed    = "failed"
+)
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"time"
+)
+
+// ReportFilter selects the days of a report: From is inclusive and To exclusive.
+// Unset optional fields match all rows.
+type ReportFilter struct {
+	Currency  string
+	From      time.Time
+	To        time.Time
+	GameID    *string
+	TokenType *string
+}
+
+// RollupState is the position of the rollup job in wallet_logs. Logs are rolled up in the order of the
+// transaction that wrote them, then of their ID; LastLogAt is the latest creation time rolled up.
+type RollupState struct {
+	LastXactID uint64
+	LastLogID  int64
+	LastLogAt  *time.Time
+	UpdatedAt  time.Time
+}
+
+// RollupBatch describes the wallet logs added to the rollups in one pass
+type RollupBatch struct {
+	Logs       int
+	LastXactID uint64
+	LastLogID  int64
+	LastLogAt  *time.Time
+}
+
+// ExchangeVolume is the amount of a game token exchanged into platform tokens on one day
+type ExchangeVolume struct {
+	Day            time.Time
+	GameID         string
+	TokenType      string
+	Exchanges      int
+	Amount         float64
+	PlatformAmount float64
+}
+
+// SpendVolume is the amount spent for one reason within a report's range
+type SpendVolume struct {
+	Reason string
+	Spends int
+	Amount float64
+}
+
+// SupplyPoint is the platform tokens credited and debited on one day and the supply at its end
+type SupplyPoint struct {
+	Day     time.Time
+	Credits float64
+	Debits  float64
+	Supply  float64
+}
+
+// ActiveWalletCount is the number of wallets with at least one log on one day
+type ActiveWalletCount struct {
+	Day     time.Time
+	Wallets int
+}
@@ -0,0 +1,63 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Risk decisions, ordered from least to most severe
+const (
+	RiskDecisionAllow  = "allow"
+	RiskDecisionReview = "review"
+	RiskDecisionDeny   = "deny"
+)
+
+// Risk review status
+const (
+	RiskReviewPending  = "pending"
+	RiskReviewApproved = "approved"
+	RiskReviewRejected = "rejected"
+	RiskReviewFailed   = "failed"
+)
+
+// RiskOperation describes a mutating wallet operation submitted for risk evaluation
+type RiskOperation struct {
+	Operation       string
+	UserID          int
+	Principal       Principal
+	Currency        string
+	Amount          float64
+	PlatformAmount  float64
+	GameID          *string
+	TokenType       *string
+	Reason          *string
+	Balance         float64
+	WalletCreatedAt *time.Time
+	RecentHistory   []*WalletLog
+	Now             time.Time
+}
+
+// RiskAssessment is the outcome of a risk evaluation
+type RiskAssessment struct {
+	Decision     string
+	MatchedRules []string
+}
+
+// RiskReview is an operation held for manual review
+type RiskReview struct {
+	ID             int64
+	Operation      string
+	UserID         int
+	PrincipalID    int
+	PrincipalRole  string
+	Amount         float64
+	PlatformAmount float64
+	MatchedRules   string
+	Payload        json.RawMessage
+	Status         string
+	ReviewedBy     *int
+	ReviewedAt     *time.Time
+	ReviewNote     *string
+	ResultError    *string
+	CreatedAt      time.Time
+}
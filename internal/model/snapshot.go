@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// BalanceSnapshot records the balance of a wallet implied by its logs created up to TakenAt
+type BalanceSnapshot struct {
+	ID        int64
+	WalletID  int64
+	UserID    int
+	Currency  string
+	Balance   float64
+	TakenAt   time.Time
+	CreatedAt time.Time
+}
+
+// PointInTimeBalance is a wallet balance at a past time, computed from the nearest snapshot before it
+// and the logs created after the snapshot. SnapshotAt is nil when no snapshot was old enough.
+// LastArchivedLogAt is the time of the wallet's newest archived log; the balance of an earlier time
+// cannot be computed.
+type PointInTimeBalance struct {
+	Balance           float64
+	SnapshotAt        *time.Time
+	LogsApplied       int
+	LastArchivedLogAt *time.Time
+}
@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+)
+
+// SpendLimits holds the limits applied to a user's spends; a zero value means unlimited
+type SpendLimits struct {
+	MaxPerTransaction  float64
+	DailyCap           float64
+	WeeklyCap          float64
+	MaxSpendsPerMinute int
+}
+
+// SpendLimitOverride holds per-user limits; nil fields fall back to the configured defaults
+type SpendLimitOverride struct {
+	UserID             int
+	MaxPerTransaction  *float64
+	DailyCap           *float64
+	WeeklyCap          *float64
+	MaxSpendsPerMinute *int
+	Reason             string
+	UpdatedBy          *int
+	UpdatedAt          time.Time
+}
+
+// SpendUsage summarizes a user's recent spends over the rolling limit windows
+type SpendUsage struct {
+	SpentLastDay     float64
+	SpentLastWeek    float64
+	SpendsLastMinute int
+}
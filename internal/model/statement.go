@@ -0,0 +1,34 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// WalletStatement summarizes a wallet's logs of one calendar month (UTC).
+// Statements are stored once generated and never change; Entries holds the statement lines as JSON.
+type WalletStatement struct {
+	ID             int64
+	WalletID       int64
+	UserID         int
+	Currency       string
+	Period         string
+	PeriodStart    time.Time
+	PeriodEnd      time.Time
+	OpeningBalance float64
+	ClosingBalance float64
+	TotalCredits   float64
+	TotalDebits    float64
+	EntryCount     int
+	Entries        json.RawMessage
+	GeneratedAt    time.Time
+}
+
+// StatementLedger holds what a statement is generated from: the wallet's balance at the start of the
+// period and its logs within the period, oldest first. LastArchivedLogAt is the time of the wallet's newest
+// archived log; the ledger of a period starting at or before it is incomplete.
+type StatementLedger struct {
+	OpeningBalance    float64
+	Logs              []*WalletLog
+	LastArchivedLogAt *time.Time
+}
@@ -0,0 +1,11 @@
+package model
+
+// WalletEventChannel is the Postgres notification channel new wallet logs are announced on
+const WalletEventChannel = "wallet_events"
+
+// WalletEvent announces a committed wallet log
+type WalletEvent struct {
+	LogID    int64 `json:"log_id"`
+	UserID   int   `json:"user_id"`
+	WalletID int64 `json:"wallet_id"`
+}
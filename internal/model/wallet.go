@@ -5,21 +5,72 @@ import (
 	"time"
 )
 
-// Wallet represents a user's wallet with platform tokens
+// Wallet represents a user's balance in one platform currency.
+// A user holds one wallet per currency; all of them share the same status.
 type Wallet struct {
 	ID        int64
 	UserID    int
-	Balance   float64
-	CreatedAt time.Time
+	Currency        string
+	Balance         float64
+	Status          string
+	StatusReason    *string
+	StatusChangedAt *time.Time
+	CreatedAt       time.Time
+}
+
+// WalletStatusChange represents a change of a wallet's status
+type WalletStatusChange struct {
+	ID         int64
+	WalletID   int64
+	UserID     int
+	FromStatus string
+	ToStatus   string
+	Reason     string
+	ChangedBy  *int
+	CreatedAt  time.Time
 }
 
-// ExchangeRate represents the exchange rate for a game token
+// ExchangeRate represents the exchange rate of a game token into a platform currency.
+// Caps are expressed in game tokens and reverse caps in platform tokens; nil means unlimited.
+// A nil FromPlatformRatio means platform tokens cannot be exchanged back into the game token.
 type ExchangeRate struct {
-	ID              int64
-	GameID          string
-	TokenType       string
-	ToPlatformRatio float64
-	CreatedAt       time.Time
+	ID                       int64
+	GameID                   string
+	TokenType                string
+	Currency                 string
+	ToPlatformRatio          float64
+	FromPlatformRatio        *float64
+	MaxPerTransaction        *float64
+	UserDailyCap             *float64
+	GameDailyCap             *float64
+	ReverseMaxPerTransaction *float64
+	ReverseUserDailyCap      *float64
+	CreatedAt                time.Time
+}
+
+// ExchangeUsage summarizes recent exchanges of a game token over the rolling daily window
+type ExchangeUsage struct {
+	UserExchangedLastDay float64
+	GameExchangedLastDay float64
+}
+
+// ReverseExchangeGrant is a grant of game tokens issued when platform tokens are exchanged back.
+// The game server redeems it once, before it expires.
+type ReverseExchangeGrant struct {
+	ID             int64
+	GrantID        string
+	WalletID       int64
+	UserID         int
+	GameID         string
+	TokenType      string
+	Currency       string
+	PlatformAmount float64
+	GameAmount     float64
+	ExpiresAt      time.Time
+	RedeemedAt     *time.Time
+	RedeemedBy     *int
+	RefundedAt     *time.Time
+	CreatedAt      time.Time
 }
 
 // WalletLog represents a log of wallet transactions
@@ -27,6 +78,7 @@ type WalletLog struct {
 	ID             int64
 	WalletID       int64
 	UserID         int
+	Currency       string
 	GameID         *string
 	TokenType      *string
 	Amount         float64
@@ -36,11 +88,51 @@ type WalletLog struct {
 	CreatedAt      time.Time
 }
 
+// WalletLogFilter selects the wallet logs of an export; unset fields match all logs.
+// From is inclusive and To exclusive.
+type WalletLogFilter struct {
+	UserID    *int
+	GameID    *string
+	TokenType *string
+	Currency  *string
+	Source    *string
+	From      *time.Time
+	To        *time.Time
+}
+
+// BalanceLot is a part of a wallet balance with a common origin and an optional expiry date.
+// The remaining amounts of a wallet's lots sum up to its balance.
+type BalanceLot struct {
+	ID          int64
+	WalletID    int64
+	Amount      float64
+	Remaining   float64
+	Source      string
+	ReferenceID *string
+	ExpiresAt   *time.Time
+	ExpiredAt   *time.Time
+	CreatedAt   time.Time
+}
+
 // Transaction types
 const (
 	TransactionExchange = "exchange"
 	TransactionSpend    = "spend"
 	TransactionBonus    = "bonus"
+	TransactionExpiry   = "expiry"
+
+	TransactionReverseExchange       = "reverse_exchange"
+	TransactionReverseExchangeRefund = "reverse_exchange_refund"
+)
+
+// CurrencyPlatform is the currency of wallets created before multi-currency support
+const CurrencyPlatform = "platform"
+
+// Wallet status
+const (
+	WalletStatusActive = "active"
+	WalletStatusFrozen = "frozen"
+	WalletStatusClosed = "closed"
 )
 
 // Operation status
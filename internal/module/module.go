@@ -4,8 +4,10 @@ import (
 	"github.com/playconomy/wallet-service/database"
 	_ "github.com/playconomy/wallet-service/docs" // Import for swagger
 	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/health"
 	"github.com/playconomy/wallet-service/internal/observability"
 	"github.com/playconomy/wallet-service/internal/observability/middleware"
+	"github.com/playconomy/wallet-service/internal/repository"
 	"github.com/playconomy/wallet-service/internal/server"
 	"github.com/playconomy/wallet-service/internal/server/handler"
 	"github.com/playconomy/wallet-service/internal/server/router"
@@ -17,8 +19,9 @@ import (
 	"go.uber.org/zap"
 )
 
-// Module combines all application modules
-var Module = fx.Options(
+// CoreModule provides configuration, observability, storage and business services
+// without the HTTP server, so that CLI commands can reuse them
+var CoreModule = fx.Options(
 	// Include the observability module
 	observability.Module,
 
@@ -26,9 +29,35 @@ var Module = fx.Options(
 		// Config
 		config.LoadConfig,
 
+		// Database
+		database.NewConnection,
+		database.NewReplicas,
+
+		// Lets the server close the wallet event streams before it shuts down
+		func(b *service.WalletEventBroker) server.StreamCloser { return b },
+	),
+
+	// Repositories, behind the wallet and exchange rate cache
+	repository.Module,
+
+	// Services and job workers
+	service.Module,
+)
+
+// Module combines all application modules
+var Module = fx.Options(
+	CoreModule,
+
+	fx.Provide(
 		// Server
-		func(obs *observability.Observability) *fiber.App {
+		func(cfg *config.Config, obs *observability.Observability) *fiber.App {
 			app := fiber.New(fiber.Config{
+				// Behind a proxy, client IP addresses are read from the proxy header
+				ProxyHeader:             cfg.Server.ProxyHeader,
+				EnableIPValidation:      cfg.Server.ProxyHeader != "",
+				EnableTrustedProxyCheck: len(cfg.Server.TrustedProxies) > 0,
+				TrustedProxies:          cfg.Server.TrustedProxies,
+
 				ErrorHandler: func(ctx *fiber.Ctx, err error) error {
 					code := fiber.StatusInternalServerError
 					if e, ok := err.(*fiber.Error); ok {
@@ -66,16 +95,39 @@ var Module = fx.Options(
 
 			return app
 		},
-		server.NewServer,
-
-		// Database
-		database.NewConnection,
-
-		// Services
-		service.NewWalletService,
+		// Health checks
+		func(cfg *config.Config) *health.Registry {
+			return health.NewRegistry(cfg.Health.CheckTimeout, cfg.Health.CacheTTL)
+		},
 
 		// Handlers
 		handler.NewWalletHandler,
+		handler.NewReconciliationHandler,
+		func(h *handler.ReconciliationHandler) handler.ReconciliationHandlerInterface { return h },
+		handler.NewRiskReviewHandler,
+		func(h *handler.RiskReviewHandler) handler.RiskReviewHandlerInterface { return h },
+		handler.NewBulkHandler,
+		func(h *handler.BulkHandler) handler.BulkHandlerInterface { return h },
+		handler.NewJobHandler,
+		func(h *handler.JobHandler) handler.JobHandlerInterface { return h },
+		handler.NewExportHandler,
+		func(h *handler.ExportHandler) handler.ExportHandlerInterface { return h },
+		handler.NewStatementHandler,
+		func(h *handler.StatementHandler) handler.StatementHandlerInterface { return h },
+		handler.NewSnapshotHandler,
+		func(h *handler.SnapshotHandler) handler.SnapshotHandlerInterface { return h },
+		handler.NewReportHandler,
+		func(h *handler.ReportHandler) handler.ReportHandlerInterface { return h },
+		handler.NewStreamHandler,
+		func(h *handler.StreamHandler) handler.StreamHandlerInterface { return h },
+		handler.NewAuditHandler,
+		func(h *handler.AuditHandler) handler.AuditHandlerInterface { return h },
+		handler.NewFeatureFlagHandler,
+		func(h *handler.FeatureFlagHandler) handler.FeatureFlagHandlerInterface { return h },
+		handler.NewRateLimitHandler,
+		func(h *handler.RateLimitHandler) handler.RateLimitHandlerInterface { return h },
+		handler.NewHealthHandler,
+		func(h *handler.HealthHandler) handler.HealthHandlerInterface { return h },
 
 		// Router
 		router.NewRouter,
@@ -85,5 +137,15 @@ var Module = fx.Options(
 	fx.Invoke(
 		router.SetupRoutes,
 		observability.SetupMetricsEndpoint,
+		service.NewReconciliationScheduler,
+		service.NewExpiryScheduler,
+		service.NewSnapshotScheduler,
+		service.NewReportRollupScheduler,
+		service.NewPartitionScheduler,
+		service.NewConfigWatcher,
+		service.NewJobRunner,
+		service.RegisterHealthChecks,
+		// Started last so it is stopped first, before the components requests depend on
+		server.NewServer,
 	),
 )
@@ -2,6 +2,8 @@
 package logger
 
 import (
+	"errors"
+
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 )
@@ -12,6 +14,7 @@ var log *zap.Logger
 type Logger struct {
 	*zap.Logger
 	fields []zap.Field
+	level  zap.AtomicLevel
 }
 
 // NewLogger creates a new logger instance with the given level
@@ -21,8 +24,10 @@ func NewLogger(level string) (*Logger, error) {
 		return nil, err
 	}
 
+	atomicLevel := zap.NewAtomicLevelAt(logLevel)
+
 	logConfig := zap.Config{
-		Level:             zap.NewAtomicLevelAt(logLevel),
+		Level:             atomicLevel,
 		Development:       false,
 		Encoding:          "json",
 		EncoderConfig:     getEncoderConfig(),
@@ -44,6 +49,7 @@ func NewLogger(level string) (*Logger, error) {
 	return &Logger{
 		Logger: logger,
 		fields: []zap.Field{},
+		level:  atomicLevel,
 	}, nil
 }
 
@@ -63,6 +69,7 @@ func (l *Logger) With(fields ...zap.Field) *Logger {
 	return &Logger{
 		Logger: l.Logger.With(fields...),
 		fields: append(l.fields, fields...),
+		level:  l.level,
 	}
 }
 
@@ -71,6 +78,21 @@ func (l *Logger) WithField(key string, value interface{}) *Logger {
 	return l.With(zap.Any(key, value))
 }
 
+// SetLevel changes the level of the logger and of every logger derived from it while it is in use
+func (l *Logger) SetLevel(level string) error {
+	if l.level == (zap.AtomicLevel{}) {
+		return errors.New("logger level cannot be changed")
+	}
+
+	logLevel, err := getLogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	l.level.SetLevel(logLevel)
+	return nil
+}
+
 // getLogLevel converts string level to zapcore.Level
 func getLogLevel(level string) (zapcore.Level, error) {
 	switch level {
@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"database/sql"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// DBStatsSource provides the statistics of a connection pool, like *sql.DB
+type DBStatsSource interface {
+	Stats() sql.DBStats
+}
+
+// dbStatsCollector exports the statistics of a connection pool, read when metrics are scraped
+type dbStatsCollector struct {
+	source DBStatsSource
+
+	maxOpen           *prometheus.Desc
+	open              *prometheus.Desc
+	inUse             *prometheus.Desc
+	idle              *prometheus.Desc
+	waitCount         *prometheus.Desc
+	waitDuration      *prometheus.Desc
+	closedMaxIdle     *prometheus.Desc
+	closedMaxIdleTime *prometheus.Desc
+	closedMaxLifetime *prometheus.Desc
+}
+
+func newDBStatsCollector(source DBStatsSource) *dbStatsCollector {
+	return &dbStatsCollector{
+		source: source,
+		maxOpen: prometheus.NewDesc("db_pool_max_open_connections",
+			"Maximum number of open connections to the database", nil, nil),
+		open: prometheus.NewDesc("db_pool_open_connections",
+			"Number of established connections, in use and idle", nil, nil),
+		inUse: prometheus.NewDesc("db_pool_in_use_connections",
+			"Number of connections currently in use", nil, nil),
+		idle: prometheus.NewDesc("db_pool_idle_connections",
+			"Number of idle connections", nil, nil),
+		waitCount: prometheus.NewDesc("db_pool_wait_count_total",
+			"Total number of connections waited for because the pool was exhausted", nil, nil),
+		waitDuration: prometheus.NewDesc("db_pool_wait_duration_seconds_total",
+			"Total time spent waiting for a connection in seconds", nil, nil),
+		closedMaxIdle: prometheus.NewDesc("db_pool_closed_max_idle_total",
+			"Total number of connections closed because the pool had too many idle connections", nil, nil),
+		closedMaxIdleTime: prometheus.NewDesc("db_pool_closed_max_idle_time_total",
+			"Total number of connections closed after being idle for too long", nil, nil),
+		closedMaxLifetime: prometheus.NewDesc("db_pool_closed_max_lifetime_total",
+			"Total number of connections closed after reaching their maximum lifetime", nil, nil),
+	}
+}
+
+// Describe sends the descriptors of the pool metrics
+func (c *dbStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxOpen
+	ch <- c.open
+	ch <- c.inUse
+	ch <- c.idle
+	ch <- c.waitCount
+	ch <- c.waitDuration
+	ch <- c.closedMaxIdle
+	ch <- c.closedMaxIdleTime
+	ch <- c.closedMaxLifetime
+}
+
+// Collect reads the pool statistics and sends them as metrics
+func (c *dbStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	stats := c.source.Stats()
+
+	ch <- prometheus.MustNewConstMetric(c.maxOpen, prometheus.GaugeValue, float64(stats.MaxOpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.open, prometheus.GaugeValue, float64(stats.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.inUse, prometheus.GaugeValue, float64(stats.InUse))
+	ch <- prometheus.MustNewConstMetric(c.idle, prometheus.GaugeValue, float64(stats.Idle))
+	ch <- prometheus.MustNewConstMetric(c.waitCount, prometheus.CounterValue, float64(stats.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.waitDuration, prometheus.CounterValue, stats.WaitDuration.Seconds())
+	ch <- prometheus.MustNewConstMetric(c.closedMaxIdle, prometheus.CounterValue, float64(stats.MaxIdleClosed))
+	ch <- prometheus.MustNewConstMetric(c.closedMaxIdleTime, prometheus.CounterValue, float64(stats.MaxIdleTimeClosed))
+	ch <- prometheus.MustNewConstMetric(c.closedMaxLifetime, prometheus.CounterValue, float64(stats.MaxLifetimeClosed))
+}
+
+// MonitorDBPool exports the statistics of a connection pool. The active_connections gauge follows the
+// number of connections in use, refreshed whenever metrics are scraped.
+func (m *Metrics) MonitorDBPool(source DBStatsSource) {
+	m.registry.MustRegister(newDBStatsCollector(source))
+	m.dbPool = source
+}
@@ -0,0 +1,54 @@
+package metrics
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDBStats sql.DBStats
+
+func (f fakeDBStats) Stats() sql.DBStats {
+	return sql.DBStats(f)
+}
+
+func TestMonitorDBPool(t *testing.T) {
+	m := NewMetrics()
+	m.MonitorDBPool(fakeDBStats{
+		MaxOpenConnections: 25,
+		OpenConnections:    7,
+		InUse:              5,
+		Idle:               2,
+		WaitCount:          12,
+		WaitDuration:       1500 * time.Millisecond,
+		MaxLifetimeClosed:  3,
+	})
+
+	expected := `
+# HELP db_pool_in_use_connections Number of connections currently in use
+# TYPE db_pool_in_use_connections gauge
+db_pool_in_use_connections 5
+# HELP db_pool_wait_count_total Total number of connections waited for because the pool was exhausted
+# TYPE db_pool_wait_count_total counter
+db_pool_wait_count_total 12
+# HELP db_pool_wait_duration_seconds_total Total time spent waiting for a connection in seconds
+# TYPE db_pool_wait_duration_seconds_total counter
+db_pool_wait_duration_seconds_total 1.5
+# HELP db_pool_closed_max_lifetime_total Total number of connections closed after reaching their maximum lifetime
+# TYPE db_pool_closed_max_lifetime_total counter
+db_pool_closed_max_lifetime_total 3
+`
+	err := testutil.GatherAndCompare(m.registry, strings.NewReader(expected),
+		"db_pool_in_use_connections", "db_pool_wait_count_total", "db_pool_wait_duration_seconds_total",
+		"db_pool_closed_max_lifetime_total")
+	require.NoError(t, err)
+
+	count, err := testutil.GatherAndCount(m.registry, "db_pool_max_open_connections", "db_pool_idle_connections")
+	require.NoError(t, err)
+	assert.Equal(t, 2, count)
+}
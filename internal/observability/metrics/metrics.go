@@ -20,6 +20,33 @@ type Metrics struct {
 	activeConnections  prometheus.Gauge
 	walletOperations   *prometheus.CounterVec
 	walletBalanceTotal *prometheus.GaugeVec
+
+	reconciliationRuns        *prometheus.CounterVec
+	reconciliationMismatches  prometheus.Gauge
+	reconciliationDrift       prometheus.Gauge
+	reconciliationChecked     prometheus.Gauge
+	reconciliationLastSuccess prometheus.Gauge
+	reconciliationFrozen      prometheus.Counter
+
+	exchangeCapHits *prometheus.CounterVec
+	riskDecisions   *prometheus.CounterVec
+	tokensExpired   *prometheus.CounterVec
+
+	jobRuns     *prometheus.CounterVec
+	jobDuration *prometheus.HistogramVec
+
+	openStreams prometheus.Gauge
+
+	replicaLag     *prometheus.GaugeVec
+	replicaHealthy *prometheus.GaugeVec
+	dbReads        *prometheus.CounterVec
+
+	cacheLookups       *prometheus.CounterVec
+	cacheInvalidations *prometheus.CounterVec
+
+	rateLimitRejections *prometheus.CounterVec
+
+	dbPool DBStatsSource
 }
 
 // NewMetrics creates and registers all application metrics
@@ -79,6 +106,154 @@ func NewMetrics() *Metrics {
 		[]string{"user_id", "currency"},
 	)
 
+	// Reconciliation metrics
+	reconciliationRuns := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "wallet_reconciliation_runs_total",
+			Help: "Total number of wallet reconciliation runs",
+		},
+		[]string{"trigger", "status"},
+	)
+
+	reconciliationMismatches := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "wallet_reconciliation_mismatches",
+			Help: "Number of drifted wallets found by the last reconciliation run",
+		},
+	)
+
+	reconciliationDrift := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "wallet_reconciliation_drift_total",
+			Help: "Sum of absolute drift between wallet balances and log history in the last reconciliation run",
+		},
+	)
+
+	reconciliationChecked := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "wallet_reconciliation_wallets_checked",
+			Help: "Number of wallets checked by the last reconciliation run",
+		},
+	)
+
+	reconciliationLastSuccess := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "wallet_reconciliation_last_success_timestamp_seconds",
+			Help: "Unix timestamp of the last successful reconciliation run",
+		},
+	)
+
+	reconciliationFrozen := prometheus.NewCounter(
+		prometheus.CounterOpts{
+			Name: "wallet_reconciliation_frozen_wallets_total",
+			Help: "Total number of wallets frozen by reconciliation",
+		},
+	)
+
+	// Exchange cap metrics
+	exchangeCapHits := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "wallet_exchange_cap_hits_total",
+			Help: "Total number of exchanges rejected by an exchange cap",
+		},
+		[]string{"game_id", "token_type", "cap"},
+	)
+
+	// Risk metrics
+	riskDecisions := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "wallet_risk_decisions_total",
+			Help: "Total number of risk evaluations by operation and decision",
+		},
+		[]string{"operation", "decision"},
+	)
+
+	// Expiry metrics
+	tokensExpired := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "wallet_tokens_expired_total",
+			Help: "Total amount of tokens removed from wallets by expiring balance lots",
+		},
+		[]string{"currency"},
+	)
+
+	// Job metrics
+	jobRuns := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "wallet_job_runs_total",
+			Help: "Total number of background job attempts by kind and outcome",
+		},
+		[]string{"kind", "outcome"},
+	)
+
+	jobDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "wallet_job_duration_seconds",
+			Help:    "Duration of background job attempts in seconds",
+			Buckets: []float64{0.1, 0.5, 1, 5, 15, 60, 300, 900, 3600},
+		},
+		[]string{"kind"},
+	)
+
+	// Stream metrics
+	openStreams := prometheus.NewGauge(
+		prometheus.GaugeOpts{
+			Name: "wallet_streams_open",
+			Help: "Number of open wallet event streams",
+		},
+	)
+
+	// Replica metrics
+	replicaLag := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_lag_seconds",
+			Help: "Replication lag of a read replica at its last check in seconds",
+		},
+		[]string{"replica"},
+	)
+
+	replicaHealthy := prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "db_replica_healthy",
+			Help: "Whether a read replica serves reads (1) or is skipped as unreachable or lagging (0)",
+		},
+		[]string{"replica"},
+	)
+
+	dbReads := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "db_reads_total",
+			Help: "Total number of routed reads by target and the reason it was chosen",
+		},
+		[]string{"target", "reason"},
+	)
+
+	// Cache metrics
+	cacheLookups := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_lookups_total",
+			Help: "Total number of cache lookups by cache and result (hit, remote_hit, miss)",
+		},
+		[]string{"cache", "result"},
+	)
+
+	cacheInvalidations := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "cache_invalidations_total",
+			Help: "Total number of cache entries dropped because the cached rows changed",
+		},
+		[]string{"cache"},
+	)
+
+	// Rate limit metrics
+	rateLimitRejections := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "http_rate_limit_rejections_total",
+			Help: "Total number of requests rejected by the rate limits by route and the bucket that was empty (user, service, ip)",
+		},
+		[]string{"route", "key_type"},
+	)
+
 	// Register all metrics
 	registry.MustRegister(
 		requestsTotal,
@@ -87,6 +262,24 @@ func NewMetrics() *Metrics {
 		activeConnections,
 		walletOperations,
 		walletBalanceTotal,
+		reconciliationRuns,
+		reconciliationMismatches,
+		reconciliationDrift,
+		reconciliationChecked,
+		reconciliationLastSuccess,
+		reconciliationFrozen,
+		exchangeCapHits,
+		riskDecisions,
+		tokensExpired,
+		jobRuns,
+		jobDuration,
+		openStreams,
+		replicaLag,
+		replicaHealthy,
+		dbReads,
+		cacheLookups,
+		cacheInvalidations,
+		rateLimitRejections,
 	)
 
 	return &Metrics{
@@ -97,6 +290,31 @@ func NewMetrics() *Metrics {
 		activeConnections:  activeConnections,
 		walletOperations:   walletOperations,
 		walletBalanceTotal: walletBalanceTotal,
+
+		reconciliationRuns:        reconciliationRuns,
+		reconciliationMismatches:  reconciliationMismatches,
+		reconciliationDrift:       reconciliationDrift,
+		reconciliationChecked:     reconciliationChecked,
+		reconciliationLastSuccess: reconciliationLastSuccess,
+		reconciliationFrozen:      reconciliationFrozen,
+
+		exchangeCapHits: exchangeCapHits,
+		riskDecisions:   riskDecisions,
+		tokensExpired:   tokensExpired,
+
+		jobRuns:     jobRuns,
+		jobDuration: jobDuration,
+
+		openStreams: openStreams,
+
+		replicaLag:     replicaLag,
+		replicaHealthy: replicaHealthy,
+		dbReads:        dbReads,
+
+		cacheLookups:       cacheLookups,
+		cacheInvalidations: cacheInvalidations,
+
+		rateLimitRejections: rateLimitRejections,
 	}
 }
 
@@ -130,6 +348,79 @@ func (m *Metrics) SetWalletBalance(userID, currency string, balance float64) {
 	m.walletBalanceTotal.WithLabelValues(userID, currency).Set(balance)
 }
 
+// RecordReconciliationRun records the outcome of a reconciliation run
+func (m *Metrics) RecordReconciliationRun(trigger, status string, walletsChecked, mismatches int, totalDrift float64) {
+	m.reconciliationRuns.WithLabelValues(trigger, status).Inc()
+	if status != "completed" {
+		return
+	}
+	m.reconciliationChecked.Set(float64(walletsChecked))
+	m.reconciliationMismatches.Set(float64(mismatches))
+	m.reconciliationDrift.Set(totalDrift)
+	m.reconciliationLastSuccess.SetToCurrentTime()
+}
+
+// RecordReconciliationFreeze records a wallet frozen by reconciliation
+func (m *Metrics) RecordReconciliationFreeze() {
+	m.reconciliationFrozen.Inc()
+}
+
+// RecordExchangeCapHit records an exchange rejected by one of the caps of a game token
+func (m *Metrics) RecordExchangeCapHit(gameID, tokenType, capName string) {
+	m.exchangeCapHits.WithLabelValues(gameID, tokenType, capName).Inc()
+}
+
+// RecordRiskDecision records the decision of a risk evaluation
+func (m *Metrics) RecordRiskDecision(operation, decision string) {
+	m.riskDecisions.WithLabelValues(operation, decision).Inc()
+}
+
+// RecordTokensExpired records an amount of tokens removed from wallets by expiring balance lots
+func (m *Metrics) RecordTokensExpired(currency string, amount float64) {
+	m.tokensExpired.WithLabelValues(currency).Add(amount)
+}
+
+// RecordJobRun records the outcome and duration of a background job attempt
+func (m *Metrics) RecordJobRun(kind, outcome string, duration float64) {
+	m.jobRuns.WithLabelValues(kind, outcome).Inc()
+	m.jobDuration.WithLabelValues(kind).Observe(duration)
+}
+
+// AddOpenStreams adjusts the number of open wallet event streams by delta
+func (m *Metrics) AddOpenStreams(delta int) {
+	m.openStreams.Add(float64(delta))
+}
+
+// SetReplicaStatus records the outcome of a read replica check
+func (m *Metrics) SetReplicaStatus(replica string, healthy bool, lag float64) {
+	m.replicaLag.WithLabelValues(replica).Set(lag)
+	if healthy {
+		m.replicaHealthy.WithLabelValues(replica).Set(1)
+	} else {
+		m.replicaHealthy.WithLabelValues(replica).Set(0)
+	}
+}
+
+// RecordDBRead records where a routed read was sent and why
+func (m *Metrics) RecordDBRead(target, reason string) {
+	m.dbReads.WithLabelValues(target, reason).Inc()
+}
+
+// RecordCacheLookup records the result of a cache lookup
+func (m *Metrics) RecordCacheLookup(cache, result string) {
+	m.cacheLookups.WithLabelValues(cache, result).Inc()
+}
+
+// RecordCacheInvalidation records a cache entry dropped because the cached rows changed
+func (m *Metrics) RecordCacheInvalidation(cache string) {
+	m.cacheInvalidations.WithLabelValues(cache).Inc()
+}
+
+// RecordRateLimitRejection records a request rejected because the bucket of its route and key type was empty
+func (m *Metrics) RecordRateLimitRejection(route, keyType string) {
+	m.rateLimitRejections.WithLabelValues(route, keyType).Inc()
+}
+
 // SetupMetricsEndpoint sets up a metrics endpoint for Prometheus
 func SetupMetricsEndpoint(app *fiber.App, metrics *Metrics) {
 	// Create a new HTTP handler for prometheus metrics
@@ -137,6 +428,11 @@ func SetupMetricsEndpoint(app *fiber.App, metrics *Metrics) {
 
 	// Create an adapter for using the HTTP handler with Fiber
 	app.Get("/metrics", func(c *fiber.Ctx) error {
+		// Refresh the gauges that are not updated as things happen
+		if metrics.dbPool != nil {
+			metrics.SetActiveConnections(metrics.dbPool.Stats().InUse)
+		}
+
 		// Use fasthttpadaptor to convert the fiber context to an http handler
 		handler := fasthttpadaptor.NewFastHTTPHandler(handler)
 		handler(c.Context())
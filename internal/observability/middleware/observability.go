@@ -8,6 +8,7 @@ import (
 	"github.com/playconomy/wallet-service/internal/observability/metrics"
 
 	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
 	"go.uber.org/zap"
 )
 
@@ -92,6 +93,5 @@ func RequestIDMiddleware() fiber.Handler {
 
 // generateRequestID creates a unique request ID
 func generateRequestID() string {
-	// Simple UUID v4 implementation - in production, use a proper UUID library
-	return fiber.UUID()
+	return fiberutils.UUIDv4()
 }
@@ -11,11 +11,12 @@ import (
 // NewTestObservability creates a minimal Observability instance for testing
 func NewTestObservability() *Observability {
 	// Create a no-op logger
-	zapLogger, _ := zap.NewDevelopment()
+	zapLogger := zap.NewNop()
 	testLogger := &logger.Logger{Logger: zapLogger}
 	
-	// Create no-op metrics and tracer
-	testMetrics := &metrics.Metrics{}
+	// Metrics are registered on a registry of their own, so every test can create them; the zero tracer
+	// creates no-op spans
+	testMetrics := metrics.NewMetrics()
 	testTracer := &tracing.Tracer{}
 	
 	return &Observability{
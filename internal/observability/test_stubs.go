@@ -1,7 +1,11 @@
 // Package observability provides tools for logging, metrics, and tracing
 package observability
 
-import "go.uber.org/zap"
+import (
+	"github.com/playconomy/wallet-service/internal/observability/logger"
+
+	"go.uber.org/zap"
+)
 
 // Metrics is a simple no-op implementation for testing
 type Metrics struct{}
@@ -27,7 +31,6 @@ type noopSpan struct{}
 func (s noopSpan) End() {}
 
 // NewNoopLogger creates a no-op logger for testing
-func NewNoopLogger() (*Logger, error) {
-	noopLogger, _ := zap.NewDevelopment()
-	return &Logger{Logger: noopLogger}, nil
+func NewNoopLogger() (*logger.Logger, error) {
+	return &logger.Logger{Logger: zap.NewNop()}, nil
 }
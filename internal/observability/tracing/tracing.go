@@ -4,6 +4,8 @@ package tracing
 import (
 	"context"
 	"fmt"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"go.opentelemetry.io/otel"
@@ -13,12 +15,59 @@ import (
 	sdktrace "go.opentelemetry.io/otel/sdk/trace"
 	semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
 	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 // Tracer holds the OpenTelemetry tracer
 type Tracer struct {
 	provider *sdktrace.TracerProvider
 	tracer   trace.Tracer
+	exporter *exportTracker
+	sampler  *ratioSampler
+}
+
+// ratioSampler samples a ratio of the traces that can be changed while spans are being started
+type ratioSampler struct {
+	current atomic.Pointer[sdktrace.Sampler]
+}
+
+func newRatioSampler(ratio float64) *ratioSampler {
+	sampler := &ratioSampler{}
+	sampler.setRatio(ratio)
+	return sampler
+}
+
+func (s *ratioSampler) setRatio(ratio float64) {
+	sampler := sdktrace.TraceIDRatioBased(ratio)
+	s.current.Store(&sampler)
+}
+
+// ShouldSample samples with the current ratio
+func (s *ratioSampler) ShouldSample(parameters sdktrace.SamplingParameters) sdktrace.SamplingResult {
+	return (*s.current.Load()).ShouldSample(parameters)
+}
+
+// Description describes the current ratio
+func (s *ratioSampler) Description() string {
+	return (*s.current.Load()).Description()
+}
+
+// exportTracker wraps a span exporter and remembers the outcome of its last export
+type exportTracker struct {
+	sdktrace.SpanExporter
+	mu      sync.Mutex
+	lastErr error
+}
+
+// ExportSpans exports spans with the wrapped exporter and records the result
+func (e *exportTracker) ExportSpans(ctx context.Context, spans []sdktrace.ReadOnlySpan) error {
+	err := e.SpanExporter.ExportSpans(ctx, spans)
+
+	e.mu.Lock()
+	e.lastErr = err
+	e.mu.Unlock()
+
+	return err
 }
 
 // NewTracer creates a new tracer with OpenTelemetry
@@ -33,6 +82,9 @@ func NewTracer(serviceName, serviceVersion, endpoint string, sampler float64) (*
 		return nil, fmt.Errorf("creating OTLP trace exporter: %w", err)
 	}
 
+	tracker := &exportTracker{SpanExporter: exporter}
+	ratio := newRatioSampler(sampler)
+
 	// Create resource with service information
 	res := resource.NewWithAttributes(
 		semconv.SchemaURL,
@@ -42,8 +94,8 @@ func NewTracer(serviceName, serviceVersion, endpoint string, sampler float64) (*
 
 	// Configure trace provider
 	traceProvider := sdktrace.NewTracerProvider(
-		sdktrace.WithSampler(sdktrace.TraceIDRatioBased(sampler)),
-		sdktrace.WithBatcher(exporter, 
+		sdktrace.WithSampler(ratio),
+		sdktrace.WithBatcher(tracker, 
 			sdktrace.WithMaxExportBatchSize(512),
 			sdktrace.WithBatchTimeout(5*time.Second),
 		),
@@ -65,6 +117,8 @@ func NewTracer(serviceName, serviceVersion, endpoint string, sampler float64) (*
 	return &Tracer{
 		provider: traceProvider,
 		tracer:   tracer,
+		exporter: tracker,
+		sampler:  ratio,
 	}, nil
 }
 
@@ -73,6 +127,25 @@ func (t *Tracer) Tracer() trace.Tracer {
 	return t.tracer
 }
 
+// SetSamplingRatio changes the ratio of traces sampled from now on. It does nothing when tracing is disabled.
+func (t *Tracer) SetSamplingRatio(ratio float64) {
+	if t == nil || t.sampler == nil {
+		return
+	}
+	t.sampler.setRatio(ratio)
+}
+
+// ExportError returns the error of the last span export, or nil when it succeeded or nothing was exported yet
+func (t *Tracer) ExportError() error {
+	if t == nil || t.exporter == nil {
+		return nil
+	}
+
+	t.exporter.mu.Lock()
+	defer t.exporter.mu.Unlock()
+	return t.exporter.lastErr
+}
+
 // Shutdown stops the tracer provider with a context timeout
 func (t *Tracer) Shutdown(ctx context.Context) error {
 	if t == nil || t.provider == nil {
@@ -92,6 +165,10 @@ func (t *Tracer) Shutdown(ctx context.Context) error {
 
 // StartSpan starts a new span with the given name and options
 func (t *Tracer) StartSpan(ctx context.Context, name string, opts ...trace.SpanStartOption) (context.Context, trace.Span) {
+	if t.tracer == nil {
+		// A zero Tracer, as used in tests, records nothing
+		return noop.NewTracerProvider().Tracer("").Start(ctx, name, opts...)
+	}
 	return t.tracer.Start(ctx, name, opts...)
 }
 
@@ -0,0 +1,52 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryStore keeps the buckets in the memory of the instance. Each instance limits requests on its own, so
+// behind a load balancer spreading requests over n instances a client may send up to n times its limit.
+type MemoryStore struct {
+	mu      sync.Mutex
+	buckets map[string]State
+	now     func() time.Time
+}
+
+// Compile-time verification that MemoryStore implements Store
+var _ Store = (*MemoryStore)(nil)
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		buckets: make(map[string]State),
+		now:     time.Now,
+	}
+}
+
+// Take takes a token from the bucket of key for limit
+func (s *MemoryStore) Take(ctx context.Context, key string, limit Limit) (Result, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state, result := Take(s.buckets[key], limit, s.now())
+	s.buckets[key] = state
+	return result, nil
+}
+
+// Prune removes the buckets unused for longer than idle
+func (s *MemoryStore) Prune(ctx context.Context, idle time.Duration) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	cutoff := s.now().Add(-idle)
+	removed := 0
+	for key, state := range s.buckets {
+		if state.Updated.Before(cutoff) {
+			delete(s.buckets, key)
+			removed++
+		}
+	}
+	return removed, nil
+}
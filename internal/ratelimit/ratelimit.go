@@ -0,0 +1,147 @@
+// Package ratelimit provides the token buckets that limit the requests of clients
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Limit allows Requests requests per Period. Its bucket holds Requests tokens and refills continuously, so a
+// client that stayed idle for a period may send Requests requests at once. The zero Limit is unlimited.
+type Limit struct {
+	Requests int
+	Period   time.Duration
+}
+
+// ParseLimit parses a limit written as <requests>/<period>, e.g. 10/1m. An empty value or 0 is unlimited.
+func ParseLimit(value string) (Limit, error) {
+	value = strings.TrimSpace(value)
+	if value == "" || value == "0" {
+		return Limit{}, nil
+	}
+
+	requests, period, ok := strings.Cut(value, "/")
+	if !ok {
+		return Limit{}, fmt.Errorf("invalid limit %q: expected <requests>/<period>", value)
+	}
+
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if err != nil || n <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: requests must be a positive integer", value)
+	}
+
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		return Limit{}, fmt.Errorf("invalid limit %q: period must be a positive duration", value)
+	}
+
+	return Limit{Requests: n, Period: d}, nil
+}
+
+// IsZero reports whether the limit is unlimited
+func (l Limit) IsZero() bool {
+	return l.Requests <= 0 || l.Period <= 0
+}
+
+// Scale returns the limit allowing factor times as many requests per period
+func (l Limit) Scale(factor int) Limit {
+	return Limit{Requests: l.Requests * factor, Period: l.Period}
+}
+
+// String formats the limit the way ParseLimit reads it
+func (l Limit) String() string {
+	if l.IsZero() {
+		return "0"
+	}
+	return fmt.Sprintf("%d/%s", l.Requests, l.Period)
+}
+
+// rate returns the tokens added to the bucket per second
+func (l Limit) rate() float64 {
+	return float64(l.Requests) / l.Period.Seconds()
+}
+
+// Result is the state of a bucket once a request took a token from it, or was refused one
+type Result struct {
+	Allowed bool
+	Limit   Limit
+	// Remaining is the number of whole tokens left in the bucket
+	Remaining int
+	// Reset is the time until the bucket is full again
+	Reset time.Duration
+	// RetryAfter is the time until a token is available; it is zero when the request was allowed
+	RetryAfter time.Duration
+}
+
+// NewResult describes a bucket of limit left with tokens after a request was allowed or refused
+func NewResult(limit Limit, tokens float64, allowed bool) Result {
+	result := Result{
+		Allowed:   allowed,
+		Limit:     limit,
+		Remaining: int(math.Max(0, math.Floor(tokens))),
+		Reset:     secondsDuration((float64(limit.Requests) - tokens) / limit.rate()),
+	}
+	if !allowed {
+		result.RetryAfter = secondsDuration((1 - tokens) / limit.rate())
+	}
+	return result
+}
+
+func secondsDuration(seconds float64) time.Duration {
+	if seconds <= 0 {
+		return 0
+	}
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// State is the content of a bucket at a point in time
+type State struct {
+	Tokens  float64
+	Updated time.Time
+}
+
+// Take refills a bucket up to now and takes a token from it when one is available. A zero state is a full
+// bucket. Stores that keep buckets elsewhere, such as a remote cache updated with compare-and-set, use it to
+// compute the next state of a bucket.
+func Take(state State, limit Limit, now time.Time) (State, Result) {
+	tokens := float64(limit.Requests)
+	if !state.Updated.IsZero() {
+		elapsed := now.Sub(state.Updated).Seconds()
+		tokens = math.Min(tokens, state.Tokens+math.Max(0, elapsed)*limit.rate())
+	}
+
+	allowed := tokens >= 1
+	if allowed {
+		tokens--
+	}
+
+	return State{Tokens: tokens, Updated: now}, NewResult(limit, tokens, allowed)
+}
+
+// Request identifies a request to limit: the route it was sent to and who sent it
+type Request struct {
+	Method string
+	Path   string
+	// UserID and Role identify the authenticated principal; UserID is 0 when the request is anonymous
+	UserID int
+	Role   string
+	// IP is the address of the client; it is empty when the IP limit is not to be taken, e.g. because it was
+	// taken before the request authenticated
+	IP string
+}
+
+// Store keeps the token buckets of the clients. Take must be atomic for a key, so concurrent requests
+// never share a token; stores shared by instances, such as Postgres or a remote cache, make the limits
+// apply to the service as a whole rather than to each instance.
+type Store interface {
+	// Take takes a token from the bucket of key for limit, refilling the bucket first
+	Take(ctx context.Context, key string, limit Limit) (Result, error)
+
+	// Prune removes the buckets unused for longer than idle and returns how many were removed. A bucket
+	// unused for the period of its limit is full, so removing it changes nothing.
+	Prune(ctx context.Context, idle time.Duration) (int, error)
+}
@@ -0,0 +1,109 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseLimit(t *testing.T) {
+	limit, err := ParseLimit("10/1m")
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 10, Period: time.Minute}, limit)
+	assert.Equal(t, "10/1m0s", limit.String())
+
+	limit, err = ParseLimit("0")
+	require.NoError(t, err)
+	assert.True(t, limit.IsZero())
+
+	for _, value := range []string{"10", "ten/1m", "-1/1m", "10/soon", "10/0s"} {
+		_, err := ParseLimit(value)
+		assert.Error(t, err, value)
+	}
+}
+
+func TestTake(t *testing.T) {
+	limit := Limit{Requests: 2, Period: time.Second}
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	state, result := Take(State{}, limit, now)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+	assert.Equal(t, 500*time.Millisecond, result.Reset)
+
+	state, result = Take(state, limit, now)
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 0, result.Remaining)
+
+	state, result = Take(state, limit, now.Add(250*time.Millisecond))
+	assert.False(t, result.Allowed)
+	assert.Equal(t, 250*time.Millisecond, result.RetryAfter)
+
+	// Half a second adds a token, and a bucket never holds more than the limit
+	_, result = Take(state, limit, now.Add(500*time.Millisecond))
+	assert.True(t, result.Allowed)
+
+	_, result = Take(state, limit, now.Add(time.Hour))
+	assert.True(t, result.Allowed)
+	assert.Equal(t, 1, result.Remaining)
+}
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore()
+	store.now = func() time.Time { return now }
+	limit := Limit{Requests: 1, Period: time.Minute}
+
+	result, err := store.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	result, err = store.Take(context.Background(), "user:1", limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+
+	// Buckets are independent
+	result, err = store.Take(context.Background(), "user:2", limit)
+	require.NoError(t, err)
+	assert.True(t, result.Allowed)
+
+	now = now.Add(2 * time.Minute)
+	removed, err := store.Prune(context.Background(), time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+}
+
+func TestRoutes(t *testing.T) {
+	limits, err := ParseRouteLimits([]string{"post /exchange=10/1m", "GET /:user_id/logs=30/1m", "POST /exchange/:kind=5/1m"})
+	require.NoError(t, err)
+	assert.Equal(t, Limit{Requests: 10, Period: time.Minute}, limits["POST /exchange"])
+
+	routes := NewRoutes([]string{"POST /exchange", "GET /:user_id/logs", "POST /exchange/:kind", "POST /exchange/reverse"})
+
+	testCases := []struct {
+		method string
+		path   string
+		route  string
+	}{
+		{"POST", "/exchange", "POST /exchange"},
+		{"POST", "/exchange/", "POST /exchange"},
+		{"POST", "/exchange/reverse", "POST /exchange/reverse"},
+		{"POST", "/exchange/other", "POST /exchange/:kind"},
+		{"GET", "/42/logs", "GET /:user_id/logs"},
+		{"GET", "/exchange", ""},
+		{"GET", "/42/logs/extra", ""},
+	}
+
+	for _, tc := range testCases {
+		route, _ := routes.Match(tc.method, tc.path)
+		assert.Equal(t, tc.route, route, "%s %s", tc.method, tc.path)
+	}
+
+	_, err = ParseRouteLimits([]string{"/exchange=10/1m"})
+	assert.Error(t, err)
+	_, err = ParseRouteLimits([]string{"POST /exchange=10/1m", "POST /exchange=20/1m"})
+	assert.Error(t, err)
+}
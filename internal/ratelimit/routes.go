@@ -0,0 +1,116 @@
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ParseRouteLimits parses route limits written as <METHOD> <path>=<limit>, e.g. POST /exchange=10/1m.
+// Paths are written as they are registered with the router: :name matches one segment and * the rest of
+// the path, e.g. GET /:user_id/logs=30/1m.
+func ParseRouteLimits(values []string) (map[string]Limit, error) {
+	limits := make(map[string]Limit, len(values))
+	for _, value := range values {
+		route, spec, ok := strings.Cut(value, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid route limit %q: expected <METHOD> <path>=<limit>", value)
+		}
+
+		route, err := normalizeRoute(route)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route limit %q: %w", value, err)
+		}
+
+		limit, err := ParseLimit(spec)
+		if err != nil {
+			return nil, fmt.Errorf("invalid route limit %q: %w", value, err)
+		}
+
+		if _, ok := limits[route]; ok {
+			return nil, fmt.Errorf("duplicate route limit %q", route)
+		}
+		limits[route] = limit
+	}
+	return limits, nil
+}
+
+// normalizeRoute returns a route as <METHOD> <path> with an upper case method
+func normalizeRoute(route string) (string, error) {
+	method, path, ok := strings.Cut(strings.TrimSpace(route), " ")
+	path = strings.TrimSpace(path)
+	if !ok || method == "" || !strings.HasPrefix(path, "/") {
+		return "", fmt.Errorf("route must be <METHOD> <path>")
+	}
+	return strings.ToUpper(method) + " " + path, nil
+}
+
+// Routes matches requests against the routes given limits
+type Routes struct {
+	patterns []routePattern
+}
+
+type routePattern struct {
+	route    string
+	method   string
+	segments []string
+	literals int
+}
+
+// NewRoutes compiles routes written as <METHOD> <path>. When several routes match a request, the one with
+// the most literal segments wins, so POST /exchange/reverse is preferred over POST /exchange/:kind.
+func NewRoutes(routes []string) *Routes {
+	r := &Routes{}
+	for _, route := range routes {
+		method, path, _ := strings.Cut(route, " ")
+		pattern := routePattern{route: route, method: method, segments: splitPath(path)}
+		for _, segment := range pattern.segments {
+			if !strings.HasPrefix(segment, ":") && segment != "*" {
+				pattern.literals++
+			}
+		}
+		r.patterns = append(r.patterns, pattern)
+	}
+
+	sort.SliceStable(r.patterns, func(i, j int) bool {
+		if r.patterns[i].literals != r.patterns[j].literals {
+			return r.patterns[i].literals > r.patterns[j].literals
+		}
+		return r.patterns[i].route < r.patterns[j].route
+	})
+	return r
+}
+
+// Match returns the route matching a request
+func (r *Routes) Match(method, path string) (string, bool) {
+	segments := splitPath(path)
+	for _, pattern := range r.patterns {
+		if pattern.method == method && pattern.matches(segments) {
+			return pattern.route, true
+		}
+	}
+	return "", false
+}
+
+func (p routePattern) matches(segments []string) bool {
+	for i, segment := range p.segments {
+		if segment == "*" {
+			return true
+		}
+		if i >= len(segments) {
+			return false
+		}
+		if !strings.HasPrefix(segment, ":") && segment != segments[i] {
+			return false
+		}
+	}
+	return len(segments) == len(p.segments)
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
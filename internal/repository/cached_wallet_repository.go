@@ -0,0 +1,401 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/cache"
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+const (
+	// Names of the caches in metrics
+	walletCacheName = "wallets"
+	rateCacheName   = "exchange_rates"
+
+	// Results of cache lookups in metrics
+	cacheResultHit       = "hit"
+	cacheResultRemoteHit = "remote_hit"
+	cacheResultMiss      = "miss"
+
+	// remoteCachePrefix namespaces the keys of the service in a shared remote cache
+	remoteCachePrefix = "wallet-service:"
+
+	// remoteCacheTimeout bounds the removal of changed entries from the remote cache
+	remoteCacheTimeout = time.Second
+
+	// invalidationRetryDelay is the wait before listening for cache invalidations again after the
+	// listener failed
+	invalidationRetryDelay = 5 * time.Second
+)
+
+// CachedWalletRepositoryParams are the dependencies of the wallet repository cache. The remote cache is
+// optional; without one, only the in-process cache is used.
+type CachedWalletRepositoryParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Repo      WalletRepository
+	Listener  CacheInvalidationListener
+	Remote    cache.Remote `optional:"true"`
+	Config    *config.Config
+	Obs       *observability.Observability
+}
+
+// CachedWalletRepository decorates a WalletRepository with caches of users' wallets and exchange rates,
+// in process and optionally in a remote cache. Wallets written through the repository are dropped when
+// their transaction commits; changes committed elsewhere, by other instances or by hand, are dropped when
+// their notification arrives. The other methods go straight to the decorated repository.
+//
+// Invalidations arrive on their own connection, in no order with other notifications: a reader woken by a
+// wallet event may still find the wallet cached from before the change. Such readers, like balance streams,
+// read from the StreamRepository instead.
+type CachedWalletRepository struct {
+	WalletRepository
+
+	wallets   *cache.LRU[int, []*model.Wallet]
+	rates     *cache.LRU[string, *model.ExchangeRate]
+	remote    cache.Remote
+	walletTTL time.Duration
+	rateTTL   time.Duration
+
+	listener CacheInvalidationListener
+	logger   *zap.Logger
+	metrics  *metrics.Metrics
+	cancel   context.CancelFunc
+	done     chan struct{}
+}
+
+// NewCachedWalletRepository decorates the wallet repository with caching when it is enabled in the
+// configuration, and registers the lifecycle hooks that listen for invalidations
+func NewCachedWalletRepository(params CachedWalletRepositoryParams) WalletRepository {
+	if !params.Config.Cache.Enabled {
+		return params.Repo
+	}
+
+	repo := newCachedWalletRepository(params.Repo, params.Remote, params.Config.Cache, params.Obs)
+	repo.listener = params.Listener
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			repo.logger.Info("Starting cache invalidation listener",
+				zap.Duration("wallet_ttl", repo.walletTTL),
+				zap.Duration("rate_ttl", repo.rateTTL),
+				zap.Bool("remote", repo.remote != nil))
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			repo.cancel = cancel
+			repo.done = make(chan struct{})
+			go repo.loop(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			repo.logger.Info("Stopping cache invalidation listener")
+			repo.cancel()
+
+			select {
+			case <-repo.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return repo
+}
+
+func newCachedWalletRepository(repo WalletRepository, remote cache.Remote, cfg config.CacheConfig,
+	obs *observability.Observability) *CachedWalletRepository {
+
+	return &CachedWalletRepository{
+		WalletRepository: repo,
+		wallets:          cache.NewLRU[int, []*model.Wallet](cfg.WalletSize, cfg.WalletTTL),
+		rates:            cache.NewLRU[string, *model.ExchangeRate](cfg.RateSize, cfg.RateTTL),
+		remote:           remote,
+		walletTTL:        cfg.WalletTTL,
+		rateTTL:          cfg.RateTTL,
+		logger:           obs.Logger.Logger.With(zap.String("component", "wallet_cache")),
+		metrics:          obs.Metrics,
+	}
+}
+
+// GetWalletByUserID retrieves a user's wallet in the given currency from the user's cached wallets
+func (c *CachedWalletRepository) GetWalletByUserID(ctx context.Context, userID int, currency string) (*model.Wallet, error) {
+	wallets, err := c.cachedWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, wallet := range wallets {
+		if wallet.Currency == currency {
+			copied := *wallet
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// GetWalletsByUserID retrieves all currency wallets of a user, oldest first, from the cache
+func (c *CachedWalletRepository) GetWalletsByUserID(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	wallets, err := c.cachedWallets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	copied := make([]*model.Wallet, len(wallets))
+	for i, wallet := range wallets {
+		walletCopy := *wallet
+		copied[i] = &walletCopy
+	}
+	return copied, nil
+}
+
+// cachedWallets returns the cached wallets of a user, which callers must not modify
+func (c *CachedWalletRepository) cachedWallets(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	return lookup(ctx, c, walletCacheName, c.wallets, userID, walletsRemoteKey(userID), c.walletTTL,
+		func() ([]*model.Wallet, bool, error) {
+			wallets, err := c.WalletRepository.GetWalletsByUserID(ctx, userID)
+			return wallets, true, err
+		})
+}
+
+// CreateWallet creates a wallet and drops the user's cached wallets once the transaction commits
+func (c *CachedWalletRepository) CreateWallet(
+	ctx context.Context, userID int, currency string, initialBalance float64, tx Transaction) (*model.Wallet, error) {
+
+	c.invalidateWalletsOnCommit(tx, userID)
+	return c.WalletRepository.CreateWallet(ctx, userID, currency, initialBalance, tx)
+}
+
+// UpdateWalletBalance updates a wallet's balance and drops the user's cached wallets once the
+// transaction commits
+func (c *CachedWalletRepository) UpdateWalletBalance(
+	ctx context.Context, userID int, currency string, newBalance float64, tx Transaction) (*model.Wallet, error) {
+
+	c.invalidateWalletsOnCommit(tx, userID)
+	return c.WalletRepository.UpdateWalletBalance(ctx, userID, currency, newBalance, tx)
+}
+
+// SpendFromWallet spends from a wallet and drops the user's cached wallets once the transaction commits
+func (c *CachedWalletRepository) SpendFromWallet(
+	ctx context.Context, userID int, currency string, amount float64, tx Transaction) (*model.Wallet, error) {
+
+	c.invalidateWalletsOnCommit(tx, userID)
+	return c.WalletRepository.SpendFromWallet(ctx, userID, currency, amount, tx)
+}
+
+// UpdateWalletStatus changes a user's wallet status and drops the user's cached wallets once the
+// transaction commits
+func (c *CachedWalletRepository) UpdateWalletStatus(
+	ctx context.Context, userID int, status, reason string, tx Transaction) (*model.Wallet, error) {
+
+	c.invalidateWalletsOnCommit(tx, userID)
+	return c.WalletRepository.UpdateWalletStatus(ctx, userID, status, reason, tx)
+}
+
+// GetExchangeRate retrieves the exchange rate of a game token into a currency from the cache
+func (c *CachedWalletRepository) GetExchangeRate(
+	ctx context.Context, gameID, tokenType, currency string) (*model.ExchangeRate, error) {
+
+	key := rateKey(gameID, tokenType, currency)
+	rate, err := lookup(ctx, c, rateCacheName, c.rates, key, remoteCachePrefix+key, c.rateTTL,
+		func() (*model.ExchangeRate, bool, error) {
+			rate, err := c.WalletRepository.GetExchangeRate(ctx, gameID, tokenType, currency)
+			return rate, rate != nil, err
+		})
+	return copyRate(rate), err
+}
+
+// GetExchangeRateByID retrieves an exchange rate by ID from the cache
+func (c *CachedWalletRepository) GetExchangeRateByID(ctx context.Context, id int64) (*model.ExchangeRate, error) {
+	key := rateIDKey(id)
+	rate, err := lookup(ctx, c, rateCacheName, c.rates, key, remoteCachePrefix+key, c.rateTTL,
+		func() (*model.ExchangeRate, bool, error) {
+			rate, err := c.WalletRepository.GetExchangeRateByID(ctx, id)
+			return rate, rate != nil, err
+		})
+	return copyRate(rate), err
+}
+
+// lookup returns a value from the in-process cache, then from the remote cache, and otherwise loads it
+// and caches it when load reports it cacheable. Failures of the remote cache count as misses.
+func lookup[K comparable, V any](ctx context.Context, c *CachedWalletRepository, name string,
+	local *cache.LRU[K, V], key K, remoteKey string, ttl time.Duration, load func() (V, bool, error)) (V, error) {
+
+	if value, ok := local.Get(key); ok {
+		c.metrics.RecordCacheLookup(name, cacheResultHit)
+		return value, nil
+	}
+
+	// A change committed while the value is loaded keeps it out of the cache
+	version := local.Version()
+
+	if value, ok := getRemote[V](ctx, c, remoteKey); ok {
+		c.metrics.RecordCacheLookup(name, cacheResultRemoteHit)
+		local.AddSince(version, key, value)
+		return value, nil
+	}
+
+	c.metrics.RecordCacheLookup(name, cacheResultMiss)
+	value, cacheable, err := load()
+	if err != nil || !cacheable {
+		return value, err
+	}
+
+	if local.AddSince(version, key, value) {
+		c.setRemote(ctx, remoteKey, value, ttl)
+	}
+	return value, nil
+}
+
+func getRemote[V any](ctx context.Context, c *CachedWalletRepository, key string) (V, bool) {
+	var value V
+	if c.remote == nil {
+		return value, false
+	}
+
+	data, ok, err := c.remote.Get(ctx, key)
+	if err != nil {
+		c.logger.Warn("Failed to read from the remote cache", zap.String("key", key), zap.Error(err))
+		return value, false
+	}
+	if !ok {
+		return value, false
+	}
+
+	if err := json.Unmarshal(data, &value); err != nil {
+		c.logger.Warn("Ignoring malformed remote cache entry", zap.String("key", key), zap.Error(err))
+		return value, false
+	}
+	return value, true
+}
+
+func (c *CachedWalletRepository) setRemote(ctx context.Context, key string, value any, ttl time.Duration) {
+	if c.remote == nil {
+		return
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		c.logger.Warn("Failed to encode remote cache entry", zap.String("key", key), zap.Error(err))
+		return
+	}
+	if err := c.remote.Set(ctx, key, data, ttl); err != nil {
+		c.logger.Warn("Failed to write to the remote cache", zap.String("key", key), zap.Error(err))
+	}
+}
+
+func (c *CachedWalletRepository) deleteRemote(keys ...string) {
+	if c.remote == nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), remoteCacheTimeout)
+	defer cancel()
+
+	if err := c.remote.Delete(ctx, keys...); err != nil {
+		c.logger.Warn("Failed to drop changed entries from the remote cache",
+			zap.Strings("keys", keys),
+			zap.Error(err))
+	}
+}
+
+// invalidateWalletsOnCommit drops the user's cached wallets once the transaction writing them commits
+func (c *CachedWalletRepository) invalidateWalletsOnCommit(tx Transaction, userID int) {
+	if pTx, ok := tx.(*PostgresTransaction); ok {
+		pTx.onCommit(func() { c.invalidateWallets(userID) })
+		return
+	}
+	c.invalidateWallets(userID)
+}
+
+func (c *CachedWalletRepository) invalidateWallets(userID int) {
+	c.wallets.Remove(userID)
+	c.metrics.RecordCacheInvalidation(walletCacheName)
+	c.deleteRemote(walletsRemoteKey(userID))
+}
+
+func (c *CachedWalletRepository) invalidateRate(invalidation *model.CacheInvalidation) {
+	keys := []string{
+		rateKey(invalidation.GameID, invalidation.TokenType, invalidation.Currency),
+		rateIDKey(invalidation.RateID),
+	}
+	for _, key := range keys {
+		c.rates.Remove(key)
+	}
+	c.metrics.RecordCacheInvalidation(rateCacheName)
+
+	remoteKeys := make([]string, len(keys))
+	for i, key := range keys {
+		remoteKeys[i] = remoteCachePrefix + key
+	}
+	c.deleteRemote(remoteKeys...)
+}
+
+// invalidate drops the cached copies of a changed row, or the whole in-process cache when invalidation
+// is nil because changes may have been missed
+func (c *CachedWalletRepository) invalidate(invalidation *model.CacheInvalidation) {
+	if invalidation == nil {
+		c.logger.Warn("Cache invalidations may have been lost, clearing the in-process caches")
+		c.wallets.Purge()
+		c.rates.Purge()
+		return
+	}
+
+	switch invalidation.Table {
+	case model.CacheInvalidationWallets:
+		c.invalidateWallets(invalidation.UserID)
+	case model.CacheInvalidationExchangeRates:
+		c.invalidateRate(invalidation)
+	}
+}
+
+func (c *CachedWalletRepository) loop(ctx context.Context) {
+	defer close(c.done)
+
+	for {
+		err := c.listener.Listen(ctx, c.invalidate)
+		if ctx.Err() != nil {
+			return
+		}
+
+		c.logger.Error("Listening for cache invalidations failed", zap.Error(err))
+		// Changes may have been missed while no connection was listening
+		c.invalidate(nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(invalidationRetryDelay):
+		}
+	}
+}
+
+func walletsRemoteKey(userID int) string {
+	return fmt.Sprintf("%swallets:%d", remoteCachePrefix, userID)
+}
+
+func rateKey(gameID, tokenType, currency string) string {
+	return fmt.Sprintf("rate:%q:%q:%q", gameID, tokenType, currency)
+}
+
+func rateIDKey(id int64) string {
+	return fmt.Sprintf("rate_id:%d", id)
+}
+
+func copyRate(rate *model.ExchangeRate) *model.ExchangeRate {
+	if rate == nil {
+		return nil
+	}
+	copied := *rate
+	return &copied
+}
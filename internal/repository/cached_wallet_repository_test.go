@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/cache"
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/logger"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// countingWalletRepository serves fixed wallets and rates and counts the reads that reach it
+type countingWalletRepository struct {
+	WalletRepository
+
+	wallets     map[int][]*model.Wallet
+	rates       map[int64]*model.ExchangeRate
+	walletReads int
+	rateReads   int
+}
+
+func (r *countingWalletRepository) GetWalletsByUserID(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	r.walletReads++
+
+	// Like the database, every read returns new wallets
+	wallets := make([]*model.Wallet, 0, len(r.wallets[userID]))
+	for _, wallet := range r.wallets[userID] {
+		copied := *wallet
+		wallets = append(wallets, &copied)
+	}
+	return wallets, nil
+}
+
+func (r *countingWalletRepository) UpdateWalletBalance(
+	ctx context.Context, userID int, currency string, newBalance float64, tx Transaction) (*model.Wallet, error) {
+
+	for _, wallet := range r.wallets[userID] {
+		if wallet.Currency == currency {
+			wallet.Balance = newBalance
+			return wallet, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *countingWalletRepository) GetExchangeRate(
+	ctx context.Context, gameID, tokenType, currency string) (*model.ExchangeRate, error) {
+
+	r.rateReads++
+	for _, rate := range r.rates {
+		if rate.GameID == gameID && rate.TokenType == tokenType && rate.Currency == currency {
+			copied := *rate
+			return &copied, nil
+		}
+	}
+	return nil, nil
+}
+
+// memoryRemoteCache is a remote cache kept in a map
+type memoryRemoteCache map[string][]byte
+
+func (m memoryRemoteCache) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	value, ok := m[key]
+	return value, ok, nil
+}
+
+func (m memoryRemoteCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m[key] = value
+	return nil
+}
+
+func (m memoryRemoteCache) Delete(ctx context.Context, keys ...string) error {
+	for _, key := range keys {
+		delete(m, key)
+	}
+	return nil
+}
+
+func newTestCachedRepository(remote cache.Remote) (*CachedWalletRepository, *countingWalletRepository) {
+	inner := &countingWalletRepository{
+		wallets: map[int][]*model.Wallet{
+			1: {
+				{ID: 10, UserID: 1, Currency: "platform", Balance: 100},
+				{ID: 11, UserID: 1, Currency: "gems", Balance: 5},
+			},
+		},
+		rates: map[int64]*model.ExchangeRate{
+			3: {ID: 3, GameID: "game1", TokenType: "gold", Currency: "platform", ToPlatformRatio: 0.5},
+		},
+	}
+
+	obs := &observability.Observability{
+		Logger:  &logger.Logger{Logger: zap.NewNop()},
+		Metrics: metrics.NewMetrics(),
+	}
+	cfg := config.CacheConfig{WalletTTL: time.Minute, WalletSize: 10, RateTTL: time.Minute, RateSize: 10}
+
+	return newCachedWalletRepository(inner, remote, cfg, obs), inner
+}
+
+// staticTransaction is a transaction that is not a Postgres transaction
+type staticTransaction struct{}
+
+func (staticTransaction) Commit() error   { return nil }
+func (staticTransaction) Rollback() error { return nil }
+
+func TestCachedWalletRepositoryWallets(t *testing.T) {
+	ctx := context.Background()
+	repo, inner := newTestCachedRepository(nil)
+
+	wallet, err := repo.GetWalletByUserID(ctx, 1, "gems")
+	require.NoError(t, err)
+	assert.Equal(t, 5.0, wallet.Balance)
+
+	wallets, err := repo.GetWalletsByUserID(ctx, 1)
+	require.NoError(t, err)
+	assert.Len(t, wallets, 2)
+
+	wallet, err = repo.GetWalletByUserID(ctx, 1, "coins")
+	require.NoError(t, err)
+	assert.Nil(t, wallet)
+	assert.Equal(t, 1, inner.walletReads, "all lookups of the user are served by one read")
+
+	// Callers get copies they may change
+	wallets[0].Balance = 0
+	wallet, _ = repo.GetWalletByUserID(ctx, 1, "platform")
+	assert.Equal(t, 100.0, wallet.Balance)
+
+	_, err = repo.UpdateWalletBalance(ctx, 1, "platform", 80, staticTransaction{})
+	require.NoError(t, err)
+
+	wallet, _ = repo.GetWalletByUserID(ctx, 1, "platform")
+	assert.Equal(t, 80.0, wallet.Balance)
+	assert.Equal(t, 2, inner.walletReads)
+}
+
+func TestCachedWalletRepositoryInvalidatesOnCommit(t *testing.T) {
+	ctx := context.Background()
+	repo, inner := newTestCachedRepository(nil)
+
+	repo.GetWalletsByUserID(ctx, 1)
+
+	tx := &PostgresTransaction{}
+	_, err := repo.UpdateWalletBalance(ctx, 1, "platform", 80, tx)
+	require.NoError(t, err)
+
+	// Until the transaction commits, readers see the committed balance
+	wallet, _ := repo.GetWalletByUserID(ctx, 1, "platform")
+	assert.Equal(t, 100.0, wallet.Balance)
+	assert.Equal(t, 1, inner.walletReads)
+
+	for _, fn := range tx.afterCommit {
+		fn()
+	}
+
+	wallet, _ = repo.GetWalletByUserID(ctx, 1, "platform")
+	assert.Equal(t, 80.0, wallet.Balance)
+	assert.Equal(t, 2, inner.walletReads)
+}
+
+func TestCachedWalletRepositoryRates(t *testing.T) {
+	ctx := context.Background()
+	remote := memoryRemoteCache{}
+	repo, inner := newTestCachedRepository(remote)
+
+	rate, err := repo.GetExchangeRate(ctx, "game1", "gold", "platform")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, rate.ToPlatformRatio)
+	repo.GetExchangeRate(ctx, "game1", "gold", "platform")
+	assert.Equal(t, 1, inner.rateReads)
+	assert.Len(t, remote, 1)
+
+	// Missing rates are not cached
+	rate, err = repo.GetExchangeRate(ctx, "game2", "gold", "platform")
+	require.NoError(t, err)
+	assert.Nil(t, rate)
+	repo.GetExchangeRate(ctx, "game2", "gold", "platform")
+	assert.Equal(t, 3, inner.rateReads)
+
+	// Another instance finds the rate in the remote cache
+	other, otherInner := newTestCachedRepository(remote)
+	rate, err = other.GetExchangeRate(ctx, "game1", "gold", "platform")
+	require.NoError(t, err)
+	assert.Equal(t, 0.5, rate.ToPlatformRatio)
+	assert.Equal(t, 0, otherInner.rateReads)
+
+	inner.rates[3].ToPlatformRatio = 0.75
+	repo.invalidate(&model.CacheInvalidation{
+		Table: model.CacheInvalidationExchangeRates, RateID: 3, GameID: "game1", TokenType: "gold", Currency: "platform",
+	})
+	assert.Empty(t, remote)
+
+	rate, _ = repo.GetExchangeRate(ctx, "game1", "gold", "platform")
+	assert.Equal(t, 0.75, rate.ToPlatformRatio)
+}
+
+func TestCachedWalletRepositoryLostInvalidations(t *testing.T) {
+	ctx := context.Background()
+	repo, inner := newTestCachedRepository(nil)
+
+	repo.GetWalletsByUserID(ctx, 1)
+	repo.GetExchangeRate(ctx, "game1", "gold", "platform")
+
+	repo.invalidate(nil)
+
+	repo.GetWalletsByUserID(ctx, 1)
+	repo.GetExchangeRate(ctx, "game1", "gold", "platform")
+	assert.Equal(t, 2, inner.walletReads)
+	assert.Equal(t, 2, inner.rateReads)
+}
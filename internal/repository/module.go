@@ -4,17 +4,120 @@ package repository
 import (
 	"database/sql"
 
+	"github.com/playconomy/wallet-service/internal/config"
 	"github.com/playconomy/wallet-service/internal/observability"
 
 	"go.uber.org/fx"
 )
 
-// Module provides repository dependencies for the application
+// Module provides repository dependencies for the application; module.CoreModule includes it.
+// The wallet repository is decorated with the wallet and exchange rate cache.
 var Module = fx.Options(
+	fx.Provide(NewReplicaRouter),
 	fx.Provide(NewWalletRepository),
+	fx.Provide(NewReconciliationRepository),
+	fx.Provide(NewRiskReviewRepository),
+	fx.Provide(NewBulkRepository),
+	fx.Provide(NewJobRepository),
+	fx.Provide(NewExportRepository),
+	fx.Provide(NewStatementRepository),
+	fx.Provide(NewSnapshotRepository),
+	fx.Provide(NewReportRepository),
+	fx.Provide(NewStreamRepository),
+	fx.Provide(NewWalletEventListener),
+	fx.Provide(NewCacheInvalidationListener),
+	fx.Decorate(NewCachedWalletRepository),
+	fx.Provide(NewAuditRepository),
+	fx.Provide(NewHealthRepository),
+	fx.Provide(NewPartitionRepository),
+	fx.Provide(NewFeatureFlagRepository),
+	fx.Provide(NewRateLimitRepository),
 )
 
-// NewWalletRepository creates a new wallet repository implementation
-func NewWalletRepository(db *sql.DB, obs *observability.Observability) WalletRepository {
+// NewWalletRepository creates a new wallet repository implementation that reads wallets and logs through
+// the replica router
+func NewWalletRepository(db *sql.DB, router *ReplicaRouter, obs *observability.Observability) WalletRepository {
+	repo := NewPostgresRepository(db, obs)
+	repo.router = router
+	return repo
+}
+
+// NewReconciliationRepository creates a new reconciliation repository implementation
+func NewReconciliationRepository(db *sql.DB, obs *observability.Observability) ReconciliationRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewRiskReviewRepository creates a new risk review repository implementation
+func NewRiskReviewRepository(db *sql.DB, obs *observability.Observability) RiskReviewRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewBulkRepository creates a new bulk repository implementation
+func NewBulkRepository(db *sql.DB, obs *observability.Observability) BulkRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewJobRepository creates a new job repository implementation
+func NewJobRepository(db *sql.DB, obs *observability.Observability) JobRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewExportRepository creates a new export repository implementation
+func NewExportRepository(db *sql.DB, obs *observability.Observability) ExportRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewStatementRepository creates a new statement repository implementation
+func NewStatementRepository(db *sql.DB, obs *observability.Observability) StatementRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewSnapshotRepository creates a new balance snapshot repository implementation
+func NewSnapshotRepository(db *sql.DB, obs *observability.Observability) SnapshotRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewReportRepository creates a new report repository implementation
+func NewReportRepository(db *sql.DB, obs *observability.Observability) ReportRepository {
 	return NewPostgresRepository(db, obs)
 }
+
+// NewStreamRepository creates a new stream repository implementation
+func NewStreamRepository(db *sql.DB, obs *observability.Observability) StreamRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewAuditRepository creates a new audit repository implementation
+func NewAuditRepository(db *sql.DB, obs *observability.Observability) AuditRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewHealthRepository creates a new health repository implementation
+func NewHealthRepository(db *sql.DB, obs *observability.Observability) HealthRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewPartitionRepository creates a new wallet log partition repository implementation
+func NewPartitionRepository(db *sql.DB, obs *observability.Observability) PartitionRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewFeatureFlagRepository creates a new feature flag override repository implementation
+func NewFeatureFlagRepository(db *sql.DB, obs *observability.Observability) FeatureFlagRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewRateLimitRepository creates a new rate limit bucket repository implementation
+func NewRateLimitRepository(db *sql.DB, obs *observability.Observability) RateLimitRepository {
+	return NewPostgresRepository(db, obs)
+}
+
+// NewWalletEventListener creates a new wallet event listener on the configured database
+func NewWalletEventListener(cfg *config.Config, obs *observability.Observability) WalletEventListener {
+	return NewPostgresWalletEventListener(cfg.Database.GetDSN(), obs)
+}
+
+// NewCacheInvalidationListener creates a new cache invalidation listener on the configured database
+func NewCacheInvalidationListener(cfg *config.Config, obs *observability.Observability) CacheInvalidationListener {
+	return NewPostgresCacheInvalidationListener(cfg.Database.GetDSN(), obs)
+}
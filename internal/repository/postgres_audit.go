@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanAuditEntry(row scanner) (*model.AuditEntry, error) {
+	var entry model.AuditEntry
+	if err := row.Scan(
+		&entry.ID, &entry.PrincipalID, &entry.PrincipalRole, &entry.Action, &entry.Method, &entry.Path,
+		&entry.TargetUserID, &entry.PayloadHash, &entry.RequestID, &entry.StatusCode, &entry.Outcome,
+		&entry.CreatedAt, &entry.PrevHash, &entry.Hash); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// AppendAuditEntry appends an entry to the audit log. Appends are serialized, so the entry's PrevHash is
+// the hash of the last committed entry; seal computes the entry's hash once PrevHash is set.
+func (r *PostgresRepository) AppendAuditEntry(
+	ctx context.Context, entry *model.AuditEntry, seal func(*model.AuditEntry) string) (*model.AuditEntry, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.AppendAuditEntry",
+		trace.WithAttributes(
+			attribute.Int("principal_id", entry.PrincipalID),
+			attribute.String("action", entry.Action),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin audit transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin audit transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, QueryLockAuditChain); err != nil {
+		r.logger.Error("Failed to lock audit chain", zap.Error(err))
+		return nil, fmt.Errorf("lock audit chain: %w", err)
+	}
+
+	prevHash := model.AuditGenesisHash
+	err = tx.QueryRowContext(ctx, QueryGetLastAuditHash).Scan(&prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		r.logger.Error("Failed to get last audit hash", zap.Error(err))
+		return nil, fmt.Errorf("get last audit hash: %w", err)
+	}
+
+	appended := *entry
+	appended.PrevHash = prevHash
+	appended.Hash = seal(&appended)
+
+	err = tx.QueryRowContext(ctx, QueryInsertAuditEntry,
+		appended.PrincipalID, appended.PrincipalRole, appended.Action, appended.Method, appended.Path,
+		appended.TargetUserID, appended.PayloadHash, appended.RequestID, appended.StatusCode, appended.Outcome,
+		appended.CreatedAt, appended.PrevHash, appended.Hash).Scan(&appended.ID)
+	if err != nil {
+		r.logger.Error("Failed to insert audit entry",
+			zap.Int("principal_id", appended.PrincipalID),
+			zap.String("action", appended.Action),
+			zap.Error(err))
+		return nil, fmt.Errorf("insert audit entry: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit audit transaction", zap.Error(err))
+		return nil, fmt.Errorf("commit audit transaction: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "admin_audit_log", duration)
+
+	return &appended, nil
+}
+
+// ListAuditEntries lists the audit entries matching the filter, newest first
+func (r *PostgresRepository) ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListAuditEntries",
+		trace.WithAttributes(
+			attribute.String("action", filter.Action),
+			attribute.String("outcome", filter.Outcome),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListAuditEntries,
+		filter.PrincipalID, filter.TargetUserID, filter.Action, filter.Outcome, filter.From, filter.To,
+		filter.Limit, filter.Offset)
+	if err != nil {
+		r.logger.Error("Failed to list audit entries", zap.Error(err))
+		return nil, fmt.Errorf("list audit entries: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := r.scanAuditEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "admin_audit_log", duration)
+
+	return entries, nil
+}
+
+// ListAuditChain lists up to limit audit entries with an ID above afterID, oldest first
+func (r *PostgresRepository) ListAuditChain(ctx context.Context, afterID int64, limit int) ([]*model.AuditEntry, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListAuditChain",
+		trace.WithAttributes(
+			attribute.Int64("after_id", afterID),
+			attribute.Int("limit", limit),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListAuditChain, afterID, limit)
+	if err != nil {
+		r.logger.Error("Failed to list audit chain",
+			zap.Int64("after_id", afterID),
+			zap.Error(err))
+		return nil, fmt.Errorf("list audit chain: %w", err)
+	}
+	defer rows.Close()
+
+	entries, err := r.scanAuditEntries(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "admin_audit_log", duration)
+
+	return entries, nil
+}
+
+func (r *PostgresRepository) scanAuditEntries(rows *sql.Rows) ([]*model.AuditEntry, error) {
+	var entries []*model.AuditEntry
+	for rows.Next() {
+		entry, err := scanAuditEntry(rows)
+		if err != nil {
+			r.logger.Error("Error scanning audit entry row", zap.Error(err))
+			return nil, fmt.Errorf("scan audit entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating audit entries", zap.Error(err))
+		return nil, fmt.Errorf("iterate audit entries: %w", err)
+	}
+
+	return entries, nil
+}
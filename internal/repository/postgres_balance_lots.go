@@ -0,0 +1,231 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// CreateBalanceLot adds a lot to a wallet; the lot starts with its full amount remaining
+func (r *PostgresRepository) CreateBalanceLot(
+	ctx context.Context, lot *model.BalanceLot, tx Transaction) (*model.BalanceLot, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateBalanceLot",
+		trace.WithAttributes(
+			attribute.Int64("wallet_id", lot.WalletID),
+			attribute.Float64("amount", lot.Amount),
+			attribute.String("source", lot.Source),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	created, err := scanBalanceLot(pTx.tx.QueryRowContext(ctx, QueryCreateBalanceLot,
+		lot.WalletID, lot.Amount, lot.Source, lot.ReferenceID, lot.ExpiresAt))
+	if err != nil {
+		r.logger.Error("Failed to create balance lot",
+			zap.Int64("wallet_id", lot.WalletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("create balance lot: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "wallet_balance_lots", duration)
+
+	return created, nil
+}
+
+// GetSpendableBalanceLotsForUpdate locks the open, unexpired lots of a wallet and returns them
+// in consumption order: lots that expire first, then lots without expiry, oldest first
+func (r *PostgresRepository) GetSpendableBalanceLotsForUpdate(
+	ctx context.Context, walletID int64, now time.Time, tx Transaction) ([]*model.BalanceLot, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetSpendableBalanceLotsForUpdate",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	rows, err := pTx.tx.QueryContext(ctx, QueryGetSpendableBalanceLotsForUpdate, walletID, now)
+	if err != nil {
+		r.logger.Error("Failed to get spendable balance lots",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get spendable balance lots: %w", err)
+	}
+	defer rows.Close()
+
+	lots, err := scanBalanceLots(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan balance lots: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_balance_lots", duration)
+
+	return lots, nil
+}
+
+// UpdateBalanceLotRemaining sets the amount a lot still holds
+func (r *PostgresRepository) UpdateBalanceLotRemaining(
+	ctx context.Context, lotID int64, remaining float64, tx Transaction) error {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.UpdateBalanceLotRemaining",
+		trace.WithAttributes(
+			attribute.Int64("lot_id", lotID),
+			attribute.Float64("remaining", remaining),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return fmt.Errorf("invalid transaction type")
+	}
+
+	if _, err := pTx.tx.ExecContext(ctx, QueryUpdateBalanceLotRemaining, lotID, remaining); err != nil {
+		r.logger.Error("Failed to update balance lot",
+			zap.Int64("lot_id", lotID),
+			zap.Error(err))
+		return fmt.Errorf("update balance lot: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "wallet_balance_lots", duration)
+
+	return nil
+}
+
+// ExpireBalanceLots empties the open lots of a wallet that expired at or before now.
+// The returned lots carry in Remaining the amount each of them held when it expired.
+func (r *PostgresRepository) ExpireBalanceLots(
+	ctx context.Context, walletID int64, now time.Time, tx Transaction) ([]*model.BalanceLot, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ExpireBalanceLots",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	rows, err := pTx.tx.QueryContext(ctx, QueryExpireBalanceLots, walletID, now)
+	if err != nil {
+		r.logger.Error("Failed to expire balance lots",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("expire balance lots: %w", err)
+	}
+	defer rows.Close()
+
+	lots, err := scanBalanceLots(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan balance lots: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "wallet_balance_lots", duration)
+
+	return lots, nil
+}
+
+// ListExpiringBalanceLots retrieves the open lots of a wallet that expire after from and until to, soonest first
+func (r *PostgresRepository) ListExpiringBalanceLots(
+	ctx context.Context, walletID int64, from, to time.Time) ([]*model.BalanceLot, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListExpiringBalanceLots",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListExpiringBalanceLots, walletID, from, to)
+	if err != nil {
+		r.logger.Error("Failed to list expiring balance lots",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("list expiring balance lots: %w", err)
+	}
+	defer rows.Close()
+
+	lots, err := scanBalanceLots(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan balance lots: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_balance_lots", duration)
+
+	return lots, nil
+}
+
+// ListWalletsWithDueBalanceLots retrieves up to limit wallets holding open lots that expired at or before now
+func (r *PostgresRepository) ListWalletsWithDueBalanceLots(
+	ctx context.Context, now time.Time, limit int) ([]*model.Wallet, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListWalletsWithDueBalanceLots",
+		trace.WithAttributes(attribute.Int("limit", limit)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListWalletsWithDueBalanceLots, now, limit)
+	if err != nil {
+		r.logger.Error("Failed to list wallets with due balance lots", zap.Error(err))
+		return nil, fmt.Errorf("list wallets with due balance lots: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_balance_lots", duration)
+
+	return wallets, nil
+}
+
+func scanBalanceLot(row scanner) (*model.BalanceLot, error) {
+	var lot model.BalanceLot
+	if err := row.Scan(
+		&lot.ID, &lot.WalletID, &lot.Amount, &lot.Remaining, &lot.Source, &lot.ReferenceID,
+		&lot.ExpiresAt, &lot.ExpiredAt, &lot.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &lot, nil
+}
+
+func scanBalanceLots(rows *sql.Rows) ([]*model.BalanceLot, error) {
+	var lots []*model.BalanceLot
+	for rows.Next() {
+		lot, err := scanBalanceLot(rows)
+		if err != nil {
+			return nil, err
+		}
+		lots = append(lots, lot)
+	}
+	return lots, rows.Err()
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanBulkJob(row scanner) (*model.BulkJob, error) {
+	var job model.BulkJob
+	var request, results []byte
+	if err := row.Scan(
+		&job.ID, &job.Status, &job.TotalItems, &job.Succeeded, &job.Failed, &job.Duplicates,
+		&request, &results, &job.Error, &job.RequestedBy, &job.CreatedAt, &job.StartedAt, &job.FinishedAt); err != nil {
+		return nil, err
+	}
+	job.Request = request
+	job.Results = results
+	return &job, nil
+}
+
+// ClaimIdempotencyKey records a key for the item applied in tx. It returns false when the key was
+// already claimed; a concurrent claim of the same key waits until the other transaction ends.
+func (r *PostgresRepository) ClaimIdempotencyKey(
+	ctx context.Context, key *model.IdempotencyKey, tx Transaction) (bool, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ClaimIdempotencyKey",
+		trace.WithAttributes(
+			attribute.Int("user_id", key.UserID),
+			attribute.String("operation", key.Operation),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return false, fmt.Errorf("invalid transaction type")
+	}
+
+	var claimed string
+	err := pTx.tx.QueryRowContext(ctx, QueryClaimIdempotencyKey,
+		key.Key, key.UserID, key.Operation, key.Currency, key.Amount).Scan(&claimed)
+	if err == sql.ErrNoRows {
+		return false, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to claim idempotency key",
+			zap.String("key", key.Key),
+			zap.Error(err))
+		return false, fmt.Errorf("claim idempotency key: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "idempotency_keys", duration)
+
+	return true, nil
+}
+
+// GetIdempotencyKey retrieves a claimed key
+func (r *PostgresRepository) GetIdempotencyKey(
+	ctx context.Context, key string, tx Transaction) (*model.IdempotencyKey, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetIdempotencyKey")
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	var claimed model.IdempotencyKey
+	err := pTx.tx.QueryRowContext(ctx, QueryGetIdempotencyKey, key).Scan(
+		&claimed.Key, &claimed.UserID, &claimed.Operation, &claimed.Currency, &claimed.Amount,
+		&claimed.NewBalance, &claimed.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get idempotency key",
+			zap.String("key", key),
+			zap.Error(err))
+		return nil, fmt.Errorf("get idempotency key: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "idempotency_keys", duration)
+
+	return &claimed, nil
+}
+
+// CompleteIdempotencyKey records the wallet balance left by the item applied under a key
+func (r *PostgresRepository) CompleteIdempotencyKey(
+	ctx context.Context, key string, newBalance float64, tx Transaction) error {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CompleteIdempotencyKey")
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return fmt.Errorf("invalid transaction type")
+	}
+
+	if _, err := pTx.tx.ExecContext(ctx, QueryCompleteIdempotencyKey, key, newBalance); err != nil {
+		r.logger.Error("Failed to complete idempotency key",
+			zap.String("key", key),
+			zap.Error(err))
+		return fmt.Errorf("complete idempotency key: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "idempotency_keys", duration)
+
+	return nil
+}
+
+// ReleaseIdempotencyKey removes a key whose item was rejected, so the item can be retried
+func (r *PostgresRepository) ReleaseIdempotencyKey(ctx context.Context, key string, tx Transaction) error {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ReleaseIdempotencyKey")
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return fmt.Errorf("invalid transaction type")
+	}
+
+	if _, err := pTx.tx.ExecContext(ctx, QueryReleaseIdempotencyKey, key); err != nil {
+		r.logger.Error("Failed to release idempotency key",
+			zap.String("key", key),
+			zap.Error(err))
+		return fmt.Errorf("release idempotency key: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("delete", "idempotency_keys", duration)
+
+	return nil
+}
+
+// CreateBulkJob records a pending bulk job
+func (r *PostgresRepository) CreateBulkJob(ctx context.Context, job *model.BulkJob) (*model.BulkJob, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateBulkJob",
+		trace.WithAttributes(
+			attribute.Int("total_items", job.TotalItems),
+			attribute.Int("requested_by", job.RequestedBy),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	created, err := scanBulkJob(r.db.QueryRowContext(ctx, QueryCreateBulkJob,
+		job.TotalItems, []byte(job.Request), job.RequestedBy))
+	if err != nil {
+		r.logger.Error("Failed to create bulk job", zap.Error(err))
+		return nil, fmt.Errorf("create bulk job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "bulk_jobs", duration)
+
+	return created, nil
+}
+
+// GetBulkJob retrieves a bulk job by ID
+func (r *PostgresRepository) GetBulkJob(ctx context.Context, id int64) (*model.BulkJob, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetBulkJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	job, err := scanBulkJob(r.db.QueryRowContext(ctx, QueryGetBulkJob, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get bulk job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("get bulk job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "bulk_jobs", duration)
+
+	return job, nil
+}
+
+// StartBulkJob marks a bulk job running
+func (r *PostgresRepository) StartBulkJob(ctx context.Context, id int64) error {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.StartBulkJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	if _, err := r.db.ExecContext(ctx, QueryStartBulkJob, id); err != nil {
+		r.logger.Error("Failed to start bulk job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return fmt.Errorf("start bulk job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "bulk_jobs", duration)
+
+	return nil
+}
+
+// FinishBulkJob records the status, counters and item results of a bulk job
+func (r *PostgresRepository) FinishBulkJob(ctx context.Context, job *model.BulkJob) (*model.BulkJob, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.FinishBulkJob",
+		trace.WithAttributes(
+			attribute.Int64("job_id", job.ID),
+			attribute.String("status", job.Status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var results []byte
+	if job.Results != nil {
+		results = job.Results
+	}
+
+	finished, err := scanBulkJob(r.db.QueryRowContext(ctx, QueryFinishBulkJob,
+		job.ID, job.Status, job.Succeeded, job.Failed, job.Duplicates, results, job.Error))
+	if err != nil {
+		r.logger.Error("Failed to finish bulk job",
+			zap.Int64("job_id", job.ID),
+			zap.Error(err))
+		return nil, fmt.Errorf("finish bulk job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "bulk_jobs", duration)
+
+	return finished, nil
+}
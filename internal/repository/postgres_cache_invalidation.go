@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/zap"
+)
+
+// PostgresCacheInvalidationListener receives cache invalidations through Postgres LISTEN/NOTIFY on a
+// dedicated connection outside the pool
+type PostgresCacheInvalidationListener struct {
+	dsn    string
+	logger *zap.Logger
+}
+
+// Compile-time verification that PostgresCacheInvalidationListener implements CacheInvalidationListener
+var _ CacheInvalidationListener = (*PostgresCacheInvalidationListener)(nil)
+
+// NewPostgresCacheInvalidationListener creates a cache invalidation listener connecting with the given DSN
+func NewPostgresCacheInvalidationListener(dsn string, obs *observability.Observability) *PostgresCacheInvalidationListener {
+	return &PostgresCacheInvalidationListener{
+		dsn:    dsn,
+		logger: obs.Logger.Logger.With(zap.String("component", "cache_invalidation_listener")),
+	}
+}
+
+// Listen calls fn for every announced change until ctx is done. A lost connection is reconnected in the
+// background; fn is then called with nil, since changes made in the meantime are lost.
+func (l *PostgresCacheInvalidationListener) Listen(ctx context.Context, fn func(*model.CacheInvalidation)) error {
+	return listenNotifications(ctx, l.dsn, model.CacheInvalidationChannel, l.logger, func(payload string, lost bool) {
+		if lost {
+			fn(nil)
+			return
+		}
+
+		var invalidation model.CacheInvalidation
+		if err := json.Unmarshal([]byte(payload), &invalidation); err != nil {
+			l.logger.Warn("Ignoring malformed cache invalidation",
+				zap.String("payload", payload),
+				zap.Error(err))
+			return
+		}
+		fn(&invalidation)
+	})
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// exchangeDailyWindow is the rolling window over which exchange caps are measured
+const exchangeDailyWindow = 24 * time.Hour
+
+// LockExchangeRate locks an exchange rate row so exchanges of the same game token are serialized
+func (r *PostgresRepository) LockExchangeRate(ctx context.Context, id int64, tx Transaction) error {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.LockExchangeRate",
+		trace.WithAttributes(attribute.Int64("exchange_rate_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return fmt.Errorf("invalid transaction type")
+	}
+
+	var lockedID int64
+	if err := pTx.tx.QueryRowContext(ctx, QueryLockExchangeRate, id).Scan(&lockedID); err != nil {
+		r.logger.Error("Failed to lock exchange rate",
+			zap.Int64("exchange_rate_id", id),
+			zap.Error(err))
+		return fmt.Errorf("lock exchange rate: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select_for_update", "exchange_rates", duration)
+
+	return nil
+}
+
+// GetExchangeUsage sums the game tokens exchanged into a currency in the last day by a user and by all users
+func (r *PostgresRepository) GetExchangeUsage(ctx context.Context, userID int, gameID, tokenType, currency string,
+	now time.Time, tx Transaction) (*model.ExchangeUsage, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetExchangeUsage",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("game_id", gameID),
+			attribute.String("token_type", tokenType),
+			attribute.String("currency", currency),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	var usage model.ExchangeUsage
+	err := pTx.tx.QueryRowContext(ctx, QueryGetExchangeUsage,
+		userID, gameID, tokenType, currency, model.TransactionExchange, now.Add(-exchangeDailyWindow)).Scan(
+		&usage.UserExchangedLastDay, &usage.GameExchangedLastDay)
+
+	if err != nil {
+		r.logger.Error("Failed to get exchange usage",
+			zap.Int("user_id", userID),
+			zap.String("game_id", gameID),
+			zap.String("token_type", tokenType),
+			zap.String("currency", currency),
+			zap.Error(err))
+		return nil, fmt.Errorf("get exchange usage: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return &usage, nil
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// StreamWalletLogs reads the logs of an export row by row inside a read-only repeatable read
+// transaction, so logs written while the export runs are not part of it
+func (r *PostgresRepository) StreamWalletLogs(
+	ctx context.Context, filter model.WalletLogFilter, fn func(*model.WalletLog) error) error {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.StreamWalletLogs")
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		r.logger.Error("Failed to begin export transaction", zap.Error(err))
+		return fmt.Errorf("begin export transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, QueryStreamWalletLogs,
+		filter.UserID, filter.GameID, filter.TokenType, filter.Currency, filter.Source, filter.From, filter.To)
+	if err != nil {
+		r.logger.Error("Failed to stream wallet logs", zap.Error(err))
+		return fmt.Errorf("stream wallet logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log model.WalletLog
+		if err := rows.Scan(
+			&log.ID, &log.WalletID, &log.UserID, &log.Currency, &log.GameID, &log.TokenType,
+			&log.Amount, &log.PlatformAmount, &log.Source, &log.ReferenceID, &log.CreatedAt); err != nil {
+			r.logger.Error("Error scanning wallet log row", zap.Error(err))
+			return fmt.Errorf("scan wallet log: %w", err)
+		}
+
+		if err := fn(&log); err != nil {
+			return err
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating wallet logs", zap.Error(err))
+		return fmt.Errorf("iterate wallet logs: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return nil
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ListFeatureFlagOverrides lists every feature flag override by name
+func (r *PostgresRepository) ListFeatureFlagOverrides(ctx context.Context) ([]*model.FeatureFlagOverride, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListFeatureFlagOverrides")
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListFeatureFlagOverrides)
+	if err != nil {
+		r.logger.Error("Failed to list feature flag overrides", zap.Error(err))
+		return nil, fmt.Errorf("list feature flag overrides: %w", err)
+	}
+	defer rows.Close()
+
+	var overrides []*model.FeatureFlagOverride
+	for rows.Next() {
+		override, err := scanFeatureFlagOverride(rows)
+		if err != nil {
+			r.logger.Error("Error scanning feature flag override row", zap.Error(err))
+			return nil, fmt.Errorf("scan feature flag override: %w", err)
+		}
+		overrides = append(overrides, override)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating feature flag overrides", zap.Error(err))
+		return nil, fmt.Errorf("iterate feature flag overrides: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "feature_flag_overrides", duration)
+
+	return overrides, nil
+}
+
+// UpsertFeatureFlagOverride creates or replaces the override of a feature flag
+func (r *PostgresRepository) UpsertFeatureFlagOverride(
+	ctx context.Context, override *model.FeatureFlagOverride) (*model.FeatureFlagOverride, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.UpsertFeatureFlagOverride",
+		trace.WithAttributes(attribute.String("flag", override.Name)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	saved, err := scanFeatureFlagOverride(r.db.QueryRowContext(ctx, QueryUpsertFeatureFlagOverride,
+		override.Name, override.Enabled, override.Percentage, pq.Array(override.Roles), pq.Array(override.GameIDs),
+		override.Reason, override.UpdatedBy))
+
+	if err != nil {
+		r.logger.Error("Failed to upsert feature flag override",
+			zap.String("flag", override.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("upsert feature flag override: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("upsert", "feature_flag_overrides", duration)
+
+	return saved, nil
+}
+
+// DeleteFeatureFlagOverride removes the override of a feature flag and reports whether one existed
+func (r *PostgresRepository) DeleteFeatureFlagOverride(ctx context.Context, name string) (bool, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.DeleteFeatureFlagOverride",
+		trace.WithAttributes(attribute.String("flag", name)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	result, err := r.db.ExecContext(ctx, QueryDeleteFeatureFlagOverride, name)
+	if err != nil {
+		r.logger.Error("Failed to delete feature flag override",
+			zap.String("flag", name),
+			zap.Error(err))
+		return false, fmt.Errorf("delete feature flag override: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete feature flag override: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("delete", "feature_flag_overrides", duration)
+
+	return affected > 0, nil
+}
+
+func scanFeatureFlagOverride(row scanner) (*model.FeatureFlagOverride, error) {
+	var override model.FeatureFlagOverride
+	if err := row.Scan(
+		&override.Name, &override.Enabled, &override.Percentage, pq.Array(&override.Roles),
+		pq.Array(&override.GameIDs), &override.Reason, &override.UpdatedBy, &override.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
@@ -0,0 +1,77 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.uber.org/zap"
+)
+
+// Ping checks that a connection to the database can be used
+func (r *PostgresRepository) Ping(ctx context.Context) error {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.Ping")
+	defer span.End()
+
+	startTime := time.Now()
+
+	if err := r.db.PingContext(ctx); err != nil {
+		r.logger.Warn("Failed to ping database", zap.Error(err))
+		return fmt.Errorf("ping database: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("ping", "", duration)
+
+	return nil
+}
+
+// GetMigrationState reads the schema version recorded by the migration tool
+func (r *PostgresRepository) GetMigrationState(ctx context.Context) (*model.MigrationState, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetMigrationState")
+	defer span.End()
+
+	startTime := time.Now()
+
+	var state model.MigrationState
+	err := r.db.QueryRowContext(ctx, QueryGetMigrationState).Scan(&state.Version, &state.Dirty)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Warn("Failed to get migration state", zap.Error(err))
+		return nil, fmt.Errorf("get migration state: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "schema_migrations", duration)
+
+	return &state, nil
+}
+
+// GetJobBacklog counts the queued jobs that are due
+func (r *PostgresRepository) GetJobBacklog(ctx context.Context) (*model.JobBacklog, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetJobBacklog")
+	defer span.End()
+
+	startTime := time.Now()
+
+	var backlog model.JobBacklog
+	var oldestRunAt sql.NullTime
+	if err := r.db.QueryRowContext(ctx, QueryGetJobBacklog).Scan(&backlog.Due, &oldestRunAt); err != nil {
+		r.logger.Warn("Failed to get job backlog", zap.Error(err))
+		return nil, fmt.Errorf("get job backlog: %w", err)
+	}
+	if oldestRunAt.Valid {
+		backlog.OldestRunAt = &oldestRunAt.Time
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "jobs", duration)
+
+	return &backlog, nil
+}
@@ -0,0 +1,292 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanJob(row scanner) (*model.Job, error) {
+	var job model.Job
+	var payload, result []byte
+	if err := row.Scan(
+		&job.ID, &job.Kind, &job.Status, &payload, &result, &job.Error, &job.Attempts, &job.MaxAttempts,
+		&job.RunAt, &job.LockedBy, &job.LockedUntil, &job.HeartbeatAt, &job.CancelRequested, &job.CreatedBy,
+		&job.CreatedAt, &job.StartedAt, &job.FinishedAt); err != nil {
+		return nil, err
+	}
+	job.Payload = payload
+	job.Result = result
+	return &job, nil
+}
+
+// EnqueueJob records a queued job that becomes due at job.RunAt
+func (r *PostgresRepository) EnqueueJob(ctx context.Context, job *model.Job) (*model.Job, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.EnqueueJob",
+		trace.WithAttributes(attribute.String("kind", job.Kind)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	created, err := scanJob(r.db.QueryRowContext(ctx, QueryEnqueueJob,
+		job.Kind, []byte(job.Payload), job.MaxAttempts, job.RunAt, job.CreatedBy))
+	if err != nil {
+		r.logger.Error("Failed to enqueue job",
+			zap.String("kind", job.Kind),
+			zap.Error(err))
+		return nil, fmt.Errorf("enqueue job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "jobs", duration)
+
+	return created, nil
+}
+
+// GetJob retrieves a job by ID
+func (r *PostgresRepository) GetJob(ctx context.Context, id int64) (*model.Job, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, QueryGetJob, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("get job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "jobs", duration)
+
+	return job, nil
+}
+
+// ListJobs retrieves jobs matching the filter, newest first
+func (r *PostgresRepository) ListJobs(ctx context.Context, filter model.JobFilter) ([]*model.Job, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListJobs",
+		trace.WithAttributes(
+			attribute.String("kind", filter.Kind),
+			attribute.String("status", filter.Status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListJobs, filter.Kind, filter.Status, filter.Limit, filter.Offset)
+	if err != nil {
+		r.logger.Error("Failed to list jobs", zap.Error(err))
+		return nil, fmt.Errorf("list jobs: %w", err)
+	}
+	defer rows.Close()
+
+	var jobs []*model.Job
+	for rows.Next() {
+		job, err := scanJob(rows)
+		if err != nil {
+			r.logger.Error("Error scanning job row", zap.Error(err))
+			return nil, fmt.Errorf("scan job: %w", err)
+		}
+		jobs = append(jobs, job)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating jobs", zap.Error(err))
+		return nil, fmt.Errorf("iterate jobs: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "jobs", duration)
+
+	return jobs, nil
+}
+
+// CancelJob cancels a queued job, or flags a running job so its worker stops it.
+// It returns nil when the job does not exist or already finished.
+func (r *PostgresRepository) CancelJob(ctx context.Context, id int64) (*model.Job, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CancelJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, QueryCancelJob, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to cancel job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("cancel job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return job, nil
+}
+
+// LeaseJob leases the oldest due job of the given kinds to a worker for the lease duration.
+// It returns nil when no job is due.
+func (r *PostgresRepository) LeaseJob(
+	ctx context.Context, kinds []string, workerID string, lease time.Duration) (*model.Job, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.LeaseJob",
+		trace.WithAttributes(attribute.String("worker_id", workerID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, QueryLeaseJob,
+		pq.Array(kinds), workerID, lease.Milliseconds()))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to lease job",
+			zap.String("worker_id", workerID),
+			zap.Error(err))
+		return nil, fmt.Errorf("lease job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return job, nil
+}
+
+// HeartbeatJob extends the lease of a running job. held is false when the worker lost the lease;
+// cancelRequested reports whether an admin asked to cancel the job.
+func (r *PostgresRepository) HeartbeatJob(
+	ctx context.Context, id int64, workerID string, lease time.Duration) (bool, bool, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.HeartbeatJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var cancelRequested bool
+	err := r.db.QueryRowContext(ctx, QueryHeartbeatJob, id, workerID, lease.Milliseconds()).Scan(&cancelRequested)
+	if err == sql.ErrNoRows {
+		return false, false, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to heartbeat job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return false, false, fmt.Errorf("heartbeat job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return true, cancelRequested, nil
+}
+
+// FinishJob records the final status and result of a running job.
+// It returns nil when the worker no longer holds the lease.
+func (r *PostgresRepository) FinishJob(ctx context.Context, id int64, workerID string, status string,
+	result json.RawMessage, jobError *string) (*model.Job, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.FinishJob",
+		trace.WithAttributes(
+			attribute.Int64("job_id", id),
+			attribute.String("status", status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var resultValue []byte
+	if result != nil {
+		resultValue = result
+	}
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, QueryFinishJob, id, workerID, status, resultValue, jobError))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to finish job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("finish job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return job, nil
+}
+
+// RetryJob queues a failed attempt of a running job again at runAt.
+// It returns nil when the worker no longer holds the lease.
+func (r *PostgresRepository) RetryJob(ctx context.Context, id int64, workerID string,
+	runAt time.Time, jobError string) (*model.Job, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.RetryJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	job, err := scanJob(r.db.QueryRowContext(ctx, QueryRetryJob, id, workerID, runAt, jobError))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to retry job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("retry job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return job, nil
+}
+
+// ReleaseJob hands a running job back to the queue without counting the attempt, for a worker that stops
+func (r *PostgresRepository) ReleaseJob(ctx context.Context, id int64, workerID string) error {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ReleaseJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	if _, err := r.db.ExecContext(ctx, QueryReleaseJob, id, workerID); err != nil {
+		r.logger.Error("Failed to release job",
+			zap.Int64("job_id", id),
+			zap.Error(err))
+		return fmt.Errorf("release job: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "jobs", duration)
+
+	return nil
+}
@@ -0,0 +1,283 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// partitionBoundLayout formats the bounds of a partition in DDL, which takes no query parameters
+const partitionBoundLayout = "2006-01-02 15:04:05"
+
+// ListWalletLogPartitions lists the monthly partitions of wallet_logs, oldest first. The default partition
+// is not part of the list.
+func (r *PostgresRepository) ListWalletLogPartitions(ctx context.Context) ([]*model.WalletLogPartition, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListWalletLogPartitions")
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListWalletLogPartitions)
+	if err != nil {
+		r.logger.Error("Failed to list wallet log partitions", zap.Error(err))
+		return nil, fmt.Errorf("list wallet log partitions: %w", err)
+	}
+	defer rows.Close()
+
+	var partitions []*model.WalletLogPartition
+	for rows.Next() {
+		var partition model.WalletLogPartition
+		if err := rows.Scan(&partition.Name, &partition.From, &partition.To); err != nil {
+			r.logger.Error("Error scanning wallet log partition row", zap.Error(err))
+			return nil, fmt.Errorf("scan wallet log partition: %w", err)
+		}
+		partitions = append(partitions, &partition)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating wallet log partitions", zap.Error(err))
+		return nil, fmt.Errorf("iterate wallet log partitions: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "pg_inherits", duration)
+
+	return partitions, nil
+}
+
+// CreateWalletLogPartition creates a partition as a plain table, moves the logs of its range from the
+// default partition into it and attaches it, in one transaction
+func (r *PostgresRepository) CreateWalletLogPartition(
+	ctx context.Context, partition *model.WalletLogPartition) (bool, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateWalletLogPartition",
+		trace.WithAttributes(attribute.String("partition", partition.Name)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.lockWalletLogPartitions(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, QueryWalletLogPartitionExists, partition.Name).Scan(&exists); err != nil {
+		r.logger.Error("Failed to check wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return false, fmt.Errorf("check wallet log partition: %w", err)
+	}
+
+	if exists {
+		return false, nil
+	}
+
+	name := pq.QuoteIdentifier(partition.Name)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(QueryCreateWalletLogPartitionTable, name)); err != nil {
+		r.logger.Error("Failed to create wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return false, fmt.Errorf("create wallet log partition: %w", err)
+	}
+
+	moved, err := tx.ExecContext(ctx, fmt.Sprintf(QueryMoveDefaultWalletLogs, name), partition.From, partition.To)
+	if err != nil {
+		r.logger.Error("Failed to move wallet logs out of the default partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return false, fmt.Errorf("move default wallet logs: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, fmt.Sprintf(QueryAttachWalletLogPartition, name,
+		partition.From.Format(partitionBoundLayout), partition.To.Format(partitionBoundLayout)))
+	if err != nil {
+		r.logger.Error("Failed to attach wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return false, fmt.Errorf("attach wallet log partition: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit partition transaction", zap.Error(err))
+		return false, fmt.Errorf("commit partition transaction: %w", err)
+	}
+
+	if count, err := moved.RowsAffected(); err == nil && count > 0 {
+		r.logger.Warn("Moved wallet logs out of the default partition",
+			zap.String("partition", partition.Name),
+			zap.Int64("logs", count))
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("create", "wallet_logs", duration)
+
+	return true, nil
+}
+
+// CountWalletLogsNotRolledUp counts the logs in a partition's range that the report rollups do not include yet
+func (r *PostgresRepository) CountWalletLogsNotRolledUp(
+	ctx context.Context, partition *model.WalletLogPartition) (int64, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CountWalletLogsNotRolledUp",
+		trace.WithAttributes(attribute.String("partition", partition.Name)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var count int64
+	err := r.db.QueryRowContext(ctx, QueryCountWalletLogsNotRolledUp, partition.From, partition.To).Scan(&count)
+	if err != nil {
+		r.logger.Error("Failed to count wallet logs of partition not rolled up",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return 0, fmt.Errorf("count wallet logs not rolled up: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return count, nil
+}
+
+// ListWalletsMissingStatement lists up to limit wallets with an ID above afterWalletID that have logs in a
+// partition but no statement for period
+func (r *PostgresRepository) ListWalletsMissingStatement(ctx context.Context, partition *model.WalletLogPartition,
+	period string, afterWalletID int64, limit int) ([]*model.Wallet, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListWalletsMissingStatement",
+		trace.WithAttributes(
+			attribute.String("partition", partition.Name),
+			attribute.Int64("after_wallet_id", afterWalletID),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListWalletsMissingStatement,
+		partition.From, partition.To, period, afterWalletID, limit)
+	if err != nil {
+		r.logger.Error("Failed to list wallets missing a statement",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("list wallets missing statement: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		return nil, fmt.Errorf("scan wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_statements", duration)
+
+	return wallets, nil
+}
+
+// ArchiveWalletLogPartition archives a partition in one transaction, so the archived balances never miss
+// or double count its logs. It returns nil when another instance archived the partition first.
+func (r *PostgresRepository) ArchiveWalletLogPartition(
+	ctx context.Context, partition *model.WalletLogPartition, mode string) (*model.WalletLogArchive, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ArchiveWalletLogPartition",
+		trace.WithAttributes(
+			attribute.String("partition", partition.Name),
+			attribute.String("mode", mode),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.lockWalletLogPartitions(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	if err := tx.QueryRowContext(ctx, QueryWalletLogPartitionExists, partition.Name).Scan(&exists); err != nil {
+		r.logger.Error("Failed to check wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("check wallet log partition: %w", err)
+	}
+
+	if !exists {
+		return nil, nil
+	}
+
+	if _, err := tx.ExecContext(ctx, QueryArchiveWalletLogBalances, partition.From, partition.To); err != nil {
+		r.logger.Error("Failed to archive wallet log balances",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("archive wallet log balances: %w", err)
+	}
+
+	var archive model.WalletLogArchive
+	err = tx.QueryRowContext(ctx, QueryCreateWalletLogArchive, partition.Name, partition.From, partition.To, mode).
+		Scan(&archive.ID, &archive.PartitionName, &archive.RangeStart, &archive.RangeEnd, &archive.LogCount,
+			&archive.WalletCount, &archive.Mode, &archive.ArchivedAt)
+	if err != nil {
+		r.logger.Error("Failed to record wallet log archive",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("create wallet log archive: %w", err)
+	}
+
+	name := pq.QuoteIdentifier(partition.Name)
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(QueryDetachWalletLogPartition, name)); err != nil {
+		r.logger.Error("Failed to detach wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.Error(err))
+		return nil, fmt.Errorf("detach wallet log partition: %w", err)
+	}
+
+	query := QueryMoveWalletLogPartitionToArchive
+	if mode == model.WalletLogArchiveDrop {
+		query = QueryDropWalletLogPartition
+	}
+	if _, err := tx.ExecContext(ctx, fmt.Sprintf(query, name)); err != nil {
+		r.logger.Error("Failed to archive wallet log partition",
+			zap.String("partition", partition.Name),
+			zap.String("mode", mode),
+			zap.Error(err))
+		return nil, fmt.Errorf("archive wallet log partition: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit partition transaction", zap.Error(err))
+		return nil, fmt.Errorf("commit partition transaction: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("archive", "wallet_logs", duration)
+
+	return &archive, nil
+}
+
+// lockWalletLogPartitions begins a transaction holding the lock on partition maintenance
+func (r *PostgresRepository) lockWalletLogPartitions(ctx context.Context) (*sql.Tx, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin partition transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin partition transaction: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, QueryLockWalletLogPartitions); err != nil {
+		tx.Rollback()
+		r.logger.Error("Failed to lock wallet log partitions", zap.Error(err))
+		return nil, fmt.Errorf("lock wallet log partitions: %w", err)
+	}
+
+	return tx, nil
+}
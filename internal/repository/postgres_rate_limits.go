@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/ratelimit"
+
+	"go.uber.org/zap"
+)
+
+// TakeRateLimitToken takes a token from the bucket of key for limit in a single statement, so concurrent
+// requests of every instance share the bucket without locking it for longer than the update
+func (r *PostgresRepository) TakeRateLimitToken(
+	ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.TakeRateLimitToken")
+	defer span.End()
+
+	startTime := time.Now()
+
+	var tokens float64
+	var granted bool
+	rate := float64(limit.Requests) / limit.Period.Seconds()
+	if err := r.db.QueryRowContext(ctx, QueryTakeRateLimitToken, key, limit.Requests, rate).Scan(
+		&tokens, &granted); err != nil {
+		r.logger.Error("Failed to take rate limit token",
+			zap.String("key", key),
+			zap.Error(err))
+		return ratelimit.Result{}, fmt.Errorf("take rate limit token: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("upsert", "rate_limit_buckets", duration)
+
+	return ratelimit.NewResult(limit, tokens, granted), nil
+}
+
+// PruneRateLimitBuckets removes the buckets unused for longer than idle
+func (r *PostgresRepository) PruneRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.PruneRateLimitBuckets")
+	defer span.End()
+
+	startTime := time.Now()
+
+	result, err := r.db.ExecContext(ctx, QueryPruneRateLimitBuckets, idle.Seconds())
+	if err != nil {
+		r.logger.Error("Failed to prune rate limit buckets", zap.Error(err))
+		return 0, fmt.Errorf("prune rate limit buckets: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("prune rate limit buckets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("delete", "rate_limit_buckets", duration)
+
+	return int(affected), nil
+}
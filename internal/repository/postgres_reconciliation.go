@@ -0,0 +1,390 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Compile-time verification that PostgresRepository implements ReconciliationRepository
+var _ ReconciliationRepository = (*PostgresRepository)(nil)
+
+// scanner is satisfied by both *sql.Row and *sql.Rows
+type scanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanReconciliationRun(row scanner) (*model.ReconciliationRun, error) {
+	var run model.ReconciliationRun
+	err := row.Scan(
+		&run.ID, &run.Trigger, &run.Status, &run.WalletsChecked, &run.Mismatches,
+		&run.TotalDrift, &run.Error, &run.StartedAt, &run.FinishedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &run, nil
+}
+
+func scanReconciliationMismatch(row scanner) (*model.ReconciliationMismatch, error) {
+	var mismatch model.ReconciliationMismatch
+	err := row.Scan(
+		&mismatch.ID, &mismatch.RunID, &mismatch.WalletID, &mismatch.UserID,
+		&mismatch.Balance, &mismatch.LedgerBalance, &mismatch.Drift, &mismatch.Frozen,
+		&mismatch.ResolvedBy, &mismatch.ResolvedAt, &mismatch.ResolutionNote, &mismatch.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &mismatch, nil
+}
+
+// ListWalletLedgerBalances retrieves a page of wallets with the balance implied by their logs.
+// Pages are keyed by wallet ID so that a full pass never holds a long-running query open.
+func (r *PostgresRepository) ListWalletLedgerBalances(
+	ctx context.Context, afterWalletID int64, limit int) ([]*model.WalletLedgerBalance, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListWalletLedgerBalances",
+		trace.WithAttributes(
+			attribute.Int64("after_wallet_id", afterWalletID),
+			attribute.Int("limit", limit),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+	r.logger.Debug("Listing wallet ledger balances",
+		zap.Int64("after_wallet_id", afterWalletID),
+		zap.Int("limit", limit))
+
+	rows, err := r.db.QueryContext(ctx, QueryListWalletLedgerBalances, afterWalletID, limit)
+	if err != nil {
+		r.logger.Error("Failed to list wallet ledger balances",
+			zap.Int64("after_wallet_id", afterWalletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("list wallet ledger balances: %w", err)
+	}
+	defer rows.Close()
+
+	var balances []*model.WalletLedgerBalance
+	for rows.Next() {
+		var balance model.WalletLedgerBalance
+		if err := rows.Scan(
+			&balance.WalletID, &balance.UserID, &balance.Balance,
+			&balance.LedgerBalance, &balance.Status); err != nil {
+			r.logger.Error("Error scanning wallet ledger balance row", zap.Error(err))
+			return nil, fmt.Errorf("scan wallet ledger balance: %w", err)
+		}
+		balances = append(balances, &balance)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating wallet ledger balances", zap.Error(err))
+		return nil, fmt.Errorf("iterate wallet ledger balances: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return balances, nil
+}
+
+// TryLockReconciliation takes the lock that allows one reconciliation run at a time across all instances.
+// The lock belongs to a connection taken out of the pool, which unlock releases; if the connection is lost
+// Postgres releases the lock with it. acquired is false when another run holds the lock.
+func (r *PostgresRepository) TryLockReconciliation(ctx context.Context) (func(), bool, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.TryLockReconciliation")
+	defer span.End()
+
+	startTime := time.Now()
+
+	conn, err := r.db.Conn(ctx)
+	if err != nil {
+		r.logger.Error("Failed to get connection for reconciliation lock", zap.Error(err))
+		return nil, false, fmt.Errorf("get reconciliation lock connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, QueryTryLockReconciliation).Scan(&acquired); err != nil {
+		conn.Close()
+		r.logger.Error("Failed to lock reconciliation", zap.Error(err))
+		return nil, false, fmt.Errorf("lock reconciliation: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("lock", "reconciliation_runs", duration)
+
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	unlock := func() {
+		defer conn.Close()
+		// The run may end because its context was cancelled, the lock must still be released
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), QueryUnlockReconciliation); err != nil {
+			r.logger.Error("Failed to unlock reconciliation", zap.Error(err))
+		}
+	}
+
+	return unlock, true, nil
+}
+
+// CreateReconciliationRun records the start of a reconciliation run
+func (r *PostgresRepository) CreateReconciliationRun(
+	ctx context.Context, trigger string) (*model.ReconciliationRun, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateReconciliationRun",
+		trace.WithAttributes(attribute.String("trigger", trigger)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	run, err := scanReconciliationRun(r.db.QueryRowContext(ctx, QueryCreateReconciliationRun,
+		trigger, model.ReconciliationRunning))
+	if err != nil {
+		r.logger.Error("Failed to create reconciliation run",
+			zap.String("trigger", trigger),
+			zap.Error(err))
+		return nil, fmt.Errorf("create reconciliation run: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "reconciliation_runs", duration)
+
+	return run, nil
+}
+
+// CompleteReconciliationRun stores the final status and totals of a reconciliation run
+func (r *PostgresRepository) CompleteReconciliationRun(
+	ctx context.Context, run *model.ReconciliationRun) (*model.ReconciliationRun, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CompleteReconciliationRun",
+		trace.WithAttributes(
+			attribute.Int64("run_id", run.ID),
+			attribute.String("status", run.Status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	completed, err := scanReconciliationRun(r.db.QueryRowContext(ctx, QueryCompleteReconciliationRun,
+		run.ID, run.Status, run.WalletsChecked, run.Mismatches, run.TotalDrift, run.Error))
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Reconciliation run not found", zap.Int64("run_id", run.ID))
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to complete reconciliation run",
+			zap.Int64("run_id", run.ID),
+			zap.Error(err))
+		return nil, fmt.Errorf("complete reconciliation run: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "reconciliation_runs", duration)
+
+	return completed, nil
+}
+
+// GetReconciliationRun retrieves a reconciliation run by ID
+func (r *PostgresRepository) GetReconciliationRun(ctx context.Context, id int64) (*model.ReconciliationRun, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetReconciliationRun",
+		trace.WithAttributes(attribute.Int64("run_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	run, err := scanReconciliationRun(r.db.QueryRowContext(ctx, QueryGetReconciliationRun, id))
+	if err == sql.ErrNoRows {
+		r.logger.Debug("Reconciliation run not found", zap.Int64("run_id", id))
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get reconciliation run",
+			zap.Int64("run_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("get reconciliation run: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reconciliation_runs", duration)
+
+	return run, nil
+}
+
+// GetLatestReconciliationRun retrieves the most recently started reconciliation run
+func (r *PostgresRepository) GetLatestReconciliationRun(ctx context.Context) (*model.ReconciliationRun, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetLatestReconciliationRun")
+	defer span.End()
+
+	startTime := time.Now()
+
+	run, err := scanReconciliationRun(r.db.QueryRowContext(ctx, QueryGetLatestReconciliationRun))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get latest reconciliation run", zap.Error(err))
+		return nil, fmt.Errorf("get latest reconciliation run: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reconciliation_runs", duration)
+
+	return run, nil
+}
+
+// CreateReconciliationMismatch records a drifted wallet found during a run
+func (r *PostgresRepository) CreateReconciliationMismatch(
+	ctx context.Context, mismatch *model.ReconciliationMismatch, tx Transaction) (*model.ReconciliationMismatch, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateReconciliationMismatch",
+		trace.WithAttributes(
+			attribute.Int64("run_id", mismatch.RunID),
+			attribute.Int64("wallet_id", mismatch.WalletID),
+			attribute.Float64("drift", mismatch.Drift),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	created, err := scanReconciliationMismatch(pTx.tx.QueryRowContext(ctx, QueryCreateReconciliationMismatch,
+		mismatch.RunID, mismatch.WalletID, mismatch.UserID, mismatch.Balance,
+		mismatch.LedgerBalance, mismatch.Drift, mismatch.Frozen))
+	if err != nil {
+		r.logger.Error("Failed to create reconciliation mismatch",
+			zap.Int64("run_id", mismatch.RunID),
+			zap.Int64("wallet_id", mismatch.WalletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("create reconciliation mismatch: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "reconciliation_mismatches", duration)
+
+	return created, nil
+}
+
+// ListReconciliationMismatches retrieves all mismatches recorded by a run
+func (r *PostgresRepository) ListReconciliationMismatches(
+	ctx context.Context, runID int64) ([]*model.ReconciliationMismatch, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListReconciliationMismatches",
+		trace.WithAttributes(attribute.Int64("run_id", runID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListReconciliationMismatches, runID)
+	if err != nil {
+		r.logger.Error("Failed to list reconciliation mismatches",
+			zap.Int64("run_id", runID),
+			zap.Error(err))
+		return nil, fmt.Errorf("list reconciliation mismatches: %w", err)
+	}
+	defer rows.Close()
+
+	var mismatches []*model.ReconciliationMismatch
+	for rows.Next() {
+		mismatch, err := scanReconciliationMismatch(rows)
+		if err != nil {
+			r.logger.Error("Error scanning reconciliation mismatch row",
+				zap.Int64("run_id", runID),
+				zap.Error(err))
+			return nil, fmt.Errorf("scan reconciliation mismatch: %w", err)
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating reconciliation mismatches",
+			zap.Int64("run_id", runID),
+			zap.Error(err))
+		return nil, fmt.Errorf("iterate reconciliation mismatches: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reconciliation_mismatches", duration)
+
+	return mismatches, nil
+}
+
+// ResolveReconciliationMismatch marks an unresolved mismatch as reviewed
+func (r *PostgresRepository) ResolveReconciliationMismatch(
+	ctx context.Context, id int64, resolvedBy int, note string, tx Transaction) (*model.ReconciliationMismatch, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ResolveReconciliationMismatch",
+		trace.WithAttributes(
+			attribute.Int64("mismatch_id", id),
+			attribute.Int("resolved_by", resolvedBy),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	mismatch, err := scanReconciliationMismatch(pTx.tx.QueryRowContext(ctx, QueryResolveReconciliationMismatch,
+		id, resolvedBy, note))
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Unresolved reconciliation mismatch not found", zap.Int64("mismatch_id", id))
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to resolve reconciliation mismatch",
+			zap.Int64("mismatch_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("resolve reconciliation mismatch: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "reconciliation_mismatches", duration)
+
+	return mismatch, nil
+}
+
+// CountUnresolvedMismatches counts the open mismatches recorded against a wallet
+func (r *PostgresRepository) CountUnresolvedMismatches(
+	ctx context.Context, walletID int64, tx Transaction) (int, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CountUnresolvedMismatches",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return 0, fmt.Errorf("invalid transaction type")
+	}
+
+	var count int
+	if err := pTx.tx.QueryRowContext(ctx, QueryCountUnresolvedMismatches, walletID).Scan(&count); err != nil {
+		r.logger.Error("Failed to count unresolved mismatches",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return 0, fmt.Errorf("count unresolved mismatches: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reconciliation_mismatches", duration)
+
+	return count, nil
+}
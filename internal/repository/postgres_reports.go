@@ -0,0 +1,304 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanRollupState(row scanner) (*model.RollupState, error) {
+	var state model.RollupState
+	if err := row.Scan(&state.LastXactID, &state.LastLogID, &state.LastLogAt, &state.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &state, nil
+}
+
+// RollUpWalletLogs adds the next batch of logs created before before to the daily rollups and advances the
+// rollup state, in one transaction. The state row is locked first, so concurrent instances roll up one
+// after another and never count a log twice. Until the rollups go past transaction ID 0, each batch first
+// gives that ID to the next logs written before transaction IDs were recorded: a batch then only reaches
+// later logs once none of those is left.
+func (r *PostgresRepository) RollUpWalletLogs(ctx context.Context, before time.Time, limit int) (*model.RollupBatch, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.RollUpWalletLogs",
+		trace.WithAttributes(attribute.Int("limit", limit)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		r.logger.Error("Failed to begin rollup transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin rollup transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	state, err := scanRollupState(tx.QueryRowContext(ctx, QueryGetRollupStateForUpdate))
+	if err != nil {
+		r.logger.Error("Failed to lock rollup state", zap.Error(err))
+		return nil, fmt.Errorf("lock rollup state: %w", err)
+	}
+
+	if state.LastXactID == 0 {
+		if _, err := tx.ExecContext(ctx, QueryBackfillWalletLogXactIDs, state.LastLogID, limit); err != nil {
+			r.logger.Error("Failed to backfill wallet log transaction IDs",
+				zap.Int64("after_log_id", state.LastLogID),
+				zap.Error(err))
+			return nil, fmt.Errorf("backfill wallet log transaction ids: %w", err)
+		}
+	}
+
+	batch := &model.RollupBatch{
+		LastXactID: state.LastXactID,
+		LastLogID:  state.LastLogID,
+		LastLogAt:  state.LastLogAt,
+	}
+	var lastXactID sql.NullInt64
+	var lastLogID sql.NullInt64
+	var lastLogAt sql.NullTime
+	err = tx.QueryRowContext(ctx, QueryRollUpWalletLogs, state.LastXactID, state.LastLogID, before, limit).
+		Scan(&batch.Logs, &lastXactID, &lastLogID, &lastLogAt)
+	if err != nil {
+		r.logger.Error("Failed to roll up wallet logs",
+			zap.Uint64("after_xact_id", state.LastXactID),
+			zap.Int64("after_log_id", state.LastLogID),
+			zap.Error(err))
+		return nil, fmt.Errorf("roll up wallet logs: %w", err)
+	}
+
+	if batch.Logs == 0 {
+		return batch, nil
+	}
+
+	batch.LastXactID = uint64(lastXactID.Int64)
+	batch.LastLogID = lastLogID.Int64
+	var rolledUpThrough time.Time
+	err = tx.QueryRowContext(ctx, QueryUpdateRollupState, batch.LastXactID, batch.LastLogID, lastLogAt.Time).
+		Scan(&rolledUpThrough)
+	if err != nil {
+		r.logger.Error("Failed to update rollup state",
+			zap.Uint64("last_xact_id", batch.LastXactID),
+			zap.Int64("last_log_id", batch.LastLogID),
+			zap.Error(err))
+		return nil, fmt.Errorf("update rollup state: %w", err)
+	}
+	batch.LastLogAt = &rolledUpThrough
+
+	if err := tx.Commit(); err != nil {
+		r.logger.Error("Failed to commit rollup transaction", zap.Error(err))
+		return nil, fmt.Errorf("commit rollup transaction: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "report_daily_activity", duration)
+
+	return batch, nil
+}
+
+// GetRollupState retrieves the position of the rollup job in wallet_logs
+func (r *PostgresRepository) GetRollupState(ctx context.Context) (*model.RollupState, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetRollupState")
+	defer span.End()
+
+	startTime := time.Now()
+
+	state, err := scanRollupState(r.db.QueryRowContext(ctx, QueryGetRollupState))
+	if err != nil {
+		r.logger.Error("Failed to get rollup state", zap.Error(err))
+		return nil, fmt.Errorf("get rollup state: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "report_rollup_state", duration)
+
+	return state, nil
+}
+
+// ListExchangeVolumes lists the daily exchange volume of each game token within the filter's days
+func (r *PostgresRepository) ListExchangeVolumes(ctx context.Context, filter model.ReportFilter) ([]*model.ExchangeVolume, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListExchangeVolumes",
+		trace.WithAttributes(attribute.String("currency", filter.Currency)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListExchangeVolumes,
+		filter.Currency, filter.From, filter.To, filter.GameID, filter.TokenType)
+	if err != nil {
+		r.logger.Error("Failed to list exchange volumes", zap.Error(err))
+		return nil, fmt.Errorf("list exchange volumes: %w", err)
+	}
+	defer rows.Close()
+
+	var volumes []*model.ExchangeVolume
+	for rows.Next() {
+		var volume model.ExchangeVolume
+		if err := rows.Scan(&volume.Day, &volume.GameID, &volume.TokenType, &volume.Exchanges,
+			&volume.Amount, &volume.PlatformAmount); err != nil {
+			r.logger.Error("Error scanning exchange volume row", zap.Error(err))
+			return nil, fmt.Errorf("scan exchange volume: %w", err)
+		}
+		volumes = append(volumes, &volume)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating exchange volumes", zap.Error(err))
+		return nil, fmt.Errorf("iterate exchange volumes: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "report_daily_activity", duration)
+
+	return volumes, nil
+}
+
+// ListSpendVolumes lists the platform tokens debited per source within the filter's days, ignoring the
+// sources in excludedSources
+func (r *PostgresRepository) ListSpendVolumes(
+	ctx context.Context, filter model.ReportFilter, excludedSources []string) ([]*model.SpendVolume, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListSpendVolumes",
+		trace.WithAttributes(attribute.String("currency", filter.Currency)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListSpendVolumes,
+		filter.Currency, filter.From, filter.To, pq.Array(excludedSources))
+	if err != nil {
+		r.logger.Error("Failed to list spend volumes", zap.Error(err))
+		return nil, fmt.Errorf("list spend volumes: %w", err)
+	}
+	defer rows.Close()
+
+	var volumes []*model.SpendVolume
+	for rows.Next() {
+		var volume model.SpendVolume
+		if err := rows.Scan(&volume.Reason, &volume.Spends, &volume.Amount); err != nil {
+			r.logger.Error("Error scanning spend volume row", zap.Error(err))
+			return nil, fmt.Errorf("scan spend volume: %w", err)
+		}
+		volumes = append(volumes, &volume)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating spend volumes", zap.Error(err))
+		return nil, fmt.Errorf("iterate spend volumes: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "report_daily_activity", duration)
+
+	return volumes, nil
+}
+
+// ListTokenSupply lists the platform tokens credited and debited on each day within the filter's days and
+// the supply at the end of the day
+func (r *PostgresRepository) ListTokenSupply(ctx context.Context, filter model.ReportFilter) ([]*model.SupplyPoint, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListTokenSupply",
+		trace.WithAttributes(attribute.String("currency", filter.Currency)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListTokenSupply, filter.Currency, filter.From, filter.To)
+	if err != nil {
+		r.logger.Error("Failed to list token supply", zap.Error(err))
+		return nil, fmt.Errorf("list token supply: %w", err)
+	}
+	defer rows.Close()
+
+	var points []*model.SupplyPoint
+	for rows.Next() {
+		var point model.SupplyPoint
+		if err := rows.Scan(&point.Day, &point.Credits, &point.Debits, &point.Supply); err != nil {
+			r.logger.Error("Error scanning token supply row", zap.Error(err))
+			return nil, fmt.Errorf("scan token supply: %w", err)
+		}
+		points = append(points, &point)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating token supply", zap.Error(err))
+		return nil, fmt.Errorf("iterate token supply: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "report_daily_activity", duration)
+
+	return points, nil
+}
+
+// ListActiveWalletCounts lists the number of wallets with at least one log on each day within the filter's days
+func (r *PostgresRepository) ListActiveWalletCounts(ctx context.Context, filter model.ReportFilter) ([]*model.ActiveWalletCount, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListActiveWalletCounts",
+		trace.WithAttributes(attribute.String("currency", filter.Currency)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListActiveWalletCounts, filter.Currency, filter.From, filter.To)
+	if err != nil {
+		r.logger.Error("Failed to list active wallet counts", zap.Error(err))
+		return nil, fmt.Errorf("list active wallet counts: %w", err)
+	}
+	defer rows.Close()
+
+	var counts []*model.ActiveWalletCount
+	for rows.Next() {
+		var count model.ActiveWalletCount
+		if err := rows.Scan(&count.Day, &count.Wallets); err != nil {
+			r.logger.Error("Error scanning active wallet count row", zap.Error(err))
+			return nil, fmt.Errorf("scan active wallet count: %w", err)
+		}
+		counts = append(counts, &count)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating active wallet counts", zap.Error(err))
+		return nil, fmt.Errorf("iterate active wallet counts: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "report_daily_active_wallets", duration)
+
+	return counts, nil
+}
+
+// ListTopWallets lists the wallets of a currency with the largest balances
+func (r *PostgresRepository) ListTopWallets(ctx context.Context, currency string, limit int) ([]*model.Wallet, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListTopWallets",
+		trace.WithAttributes(
+			attribute.String("currency", currency),
+			attribute.Int("limit", limit),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListTopWallets, currency, limit)
+	if err != nil {
+		r.logger.Error("Failed to list top wallets", zap.Error(err))
+		return nil, fmt.Errorf("list top wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		r.logger.Error("Error scanning top wallets", zap.Error(err))
+		return nil, fmt.Errorf("scan top wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
+
+	return wallets, nil
+}
@@ -20,6 +20,7 @@ import (
 // PostgresRepository implements WalletRepository interface for PostgreSQL
 type PostgresRepository struct {
 	db      *sql.DB
+	router  *ReplicaRouter
 	logger  *zap.Logger
 	metrics *metrics.Metrics
 	tracer  *tracing.Tracer
@@ -28,6 +29,9 @@ type PostgresRepository struct {
 // PostgresTransaction represents a PostgreSQL transaction
 type PostgresTransaction struct {
 	tx *sql.Tx
+
+	// afterCommit runs once the transaction committed, e.g. to drop cached copies of the rows it wrote
+	afterCommit []func()
 }
 
 // NewPostgresRepository creates a new PostgreSQL repository
@@ -56,7 +60,14 @@ func (r *PostgresRepository) BeginTx(ctx context.Context) (Transaction, error) {
 
 // Commit commits the transaction
 func (t *PostgresTransaction) Commit() error {
-	return t.tx.Commit()
+	if err := t.tx.Commit(); err != nil {
+		return err
+	}
+
+	for _, fn := range t.afterCommit {
+		fn()
+	}
+	return nil
 }
 
 // Rollback rolls back the transaction
@@ -64,66 +75,146 @@ func (t *PostgresTransaction) Rollback() error {
 	return t.tx.Rollback()
 }
 
-// GetWalletByUserID retrieves a wallet by user ID
-func (r *PostgresRepository) GetWalletByUserID(ctx context.Context, userID int) (*model.Wallet, error) {
+// onCommit registers fn to run after the transaction commits
+func (t *PostgresTransaction) onCommit(fn func()) {
+	t.afterCommit = append(t.afterCommit, fn)
+}
+
+// recordWrite starts the read-your-writes window of the user whose wallet the transaction wrote once it
+// commits
+func (t *PostgresTransaction) recordWrite(router *ReplicaRouter, userID int) {
+	if router == nil {
+		return
+	}
+	t.onCommit(func() { router.markWrite(userID) })
+}
+
+// reader returns the connection pool for a non-transactional read of the user's data: a read replica
+// when the repository has one that may serve the read, the primary otherwise
+func (r *PostgresRepository) reader(userID int) *sql.DB {
+	if r.router == nil {
+		return r.db
+	}
+	return r.router.reader(userID)
+}
+
+func scanWallet(row scanner) (*model.Wallet, error) {
+	var wallet model.Wallet
+	if err := row.Scan(
+		&wallet.ID, &wallet.UserID, &wallet.Currency, &wallet.Balance, &wallet.Status, &wallet.StatusReason,
+		&wallet.StatusChangedAt, &wallet.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &wallet, nil
+}
+
+// GetWalletByUserID retrieves a user's wallet in the given currency
+func (r *PostgresRepository) GetWalletByUserID(ctx context.Context, userID int, currency string) (*model.Wallet, error) {
 	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletByUserID",
-		trace.WithAttributes(attribute.Int("user_id", userID)))
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
+		))
 	defer span.End()
 
 	startTime := time.Now()
-	r.logger.Debug("Getting wallet for user", zap.Int("user_id", userID))
+	r.logger.Debug("Getting wallet for user",
+		zap.Int("user_id", userID),
+		zap.String("currency", currency))
 
-	var wallet model.Wallet
-	err := r.db.QueryRowContext(ctx, QueryGetWalletByUserID, userID).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.CreatedAt)
+	wallet, err := scanWallet(r.reader(userID).QueryRowContext(ctx, QueryGetWalletByUserID, userID, currency))
 
 	if err == sql.ErrNoRows {
-		r.logger.Debug("Wallet not found for user", zap.Int("user_id", userID))
+		r.logger.Debug("Wallet not found for user",
+			zap.Int("user_id", userID),
+			zap.String("currency", currency))
 		return nil, nil
 	}
 
 	if err != nil {
 		r.logger.Error("Error retrieving wallet",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Error(err))
 		return nil, fmt.Errorf("get wallet: %w", err)
 	}
 
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
-	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), "platform", wallet.Balance)
+	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), wallet.Currency, wallet.Balance)
 
-	return &wallet, nil
+	return wallet, nil
+}
+
+// GetWalletsByUserID retrieves all currency wallets of a user, oldest first
+func (r *PostgresRepository) GetWalletsByUserID(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletsByUserID",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+	r.logger.Debug("Getting wallets for user", zap.Int("user_id", userID))
+
+	rows, err := r.reader(userID).QueryContext(ctx, QueryGetWalletsByUserID, userID)
+	if err != nil {
+		r.logger.Error("Error retrieving wallets",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get wallets: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		r.logger.Error("Error scanning wallets",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("scan wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
+	for _, wallet := range wallets {
+		r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), wallet.Currency, wallet.Balance)
+	}
+
+	return wallets, nil
 }
 
-// GetWalletByUserIDForUpdate retrieves a wallet by user ID with a lock for update
+// GetWalletByUserIDForUpdate retrieves a user's wallet in the given currency with a lock for update
 func (r *PostgresRepository) GetWalletByUserIDForUpdate(
-	ctx context.Context, userID int, tx Transaction) (*model.Wallet, error) {
-	
+	ctx context.Context, userID int, currency string, tx Transaction) (*model.Wallet, error) {
+
 	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletByUserIDForUpdate",
-		trace.WithAttributes(attribute.Int("user_id", userID)))
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
+		))
 	defer span.End()
 
 	startTime := time.Now()
-	r.logger.Debug("Getting wallet for update", zap.Int("user_id", userID))
+	r.logger.Debug("Getting wallet for update",
+		zap.Int("user_id", userID),
+		zap.String("currency", currency))
 
 	pTx, ok := tx.(*PostgresTransaction)
 	if !ok {
 		return nil, fmt.Errorf("invalid transaction type")
 	}
 
-	var wallet model.Wallet
-	err := pTx.tx.QueryRowContext(ctx, QueryGetWalletByUserIDForUpdate, userID).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.CreatedAt)
+	wallet, err := scanWallet(pTx.tx.QueryRowContext(ctx, QueryGetWalletByUserIDForUpdate, userID, currency))
 
 	if err == sql.ErrNoRows {
-		r.logger.Debug("Wallet not found for update", zap.Int("user_id", userID))
+		r.logger.Debug("Wallet not found for update",
+			zap.Int("user_id", userID),
+			zap.String("currency", currency))
 		return nil, nil
 	}
 
 	if err != nil {
 		r.logger.Error("Error retrieving wallet for update",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Error(err))
 		return nil, fmt.Errorf("get wallet for update: %w", err)
 	}
@@ -131,16 +222,69 @@ func (r *PostgresRepository) GetWalletByUserIDForUpdate(
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
 
-	return &wallet, nil
+	return wallet, nil
+}
+
+// GetWalletsByUserIDForUpdate locks all currency wallets of a user and returns them, oldest first
+func (r *PostgresRepository) GetWalletsByUserIDForUpdate(
+	ctx context.Context, userID int, tx Transaction) ([]*model.Wallet, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletsByUserIDForUpdate",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+	r.logger.Debug("Getting wallets for update", zap.Int("user_id", userID))
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	rows, err := pTx.tx.QueryContext(ctx, QueryGetWalletsByUserIDForUpdate, userID)
+	if err != nil {
+		r.logger.Error("Error retrieving wallets for update",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get wallets for update: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		r.logger.Error("Error scanning wallets for update",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("scan wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
+
+	return wallets, nil
 }
 
-// CreateWallet creates a new wallet
+func scanWallets(rows *sql.Rows) ([]*model.Wallet, error) {
+	var wallets []*model.Wallet
+	for rows.Next() {
+		wallet, err := scanWallet(rows)
+		if err != nil {
+			return nil, err
+		}
+		wallets = append(wallets, wallet)
+	}
+	return wallets, rows.Err()
+}
+
+// CreateWallet creates a new wallet in the given currency.
+// The wallet inherits the status of the user's existing wallets.
 func (r *PostgresRepository) CreateWallet(
-	ctx context.Context, userID int, initialBalance float64, tx Transaction) (*model.Wallet, error) {
-	
+	ctx context.Context, userID int, currency string, initialBalance float64, tx Transaction) (*model.Wallet, error) {
+
 	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateWallet",
 		trace.WithAttributes(
 			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
 			attribute.Float64("initial_balance", initialBalance),
 		))
 	defer span.End()
@@ -148,6 +292,7 @@ func (r *PostgresRepository) CreateWallet(
 	startTime := time.Now()
 	r.logger.Info("Creating new wallet",
 		zap.Int("user_id", userID),
+		zap.String("currency", currency),
 		zap.Float64("initial_balance", initialBalance))
 
 	pTx, ok := tx.(*PostgresTransaction)
@@ -155,31 +300,32 @@ func (r *PostgresRepository) CreateWallet(
 		return nil, fmt.Errorf("invalid transaction type")
 	}
 
-	var wallet model.Wallet
-	err := pTx.tx.QueryRowContext(ctx, QueryCreateWallet, userID, initialBalance).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.CreatedAt)
+	wallet, err := scanWallet(pTx.tx.QueryRowContext(ctx, QueryCreateWallet, userID, currency, initialBalance))
 
 	if err != nil {
 		r.logger.Error("Failed to create wallet",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Error(err))
 		return nil, fmt.Errorf("create wallet: %w", err)
 	}
 
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("insert", "wallets", duration)
-	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), "platform", wallet.Balance)
+	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), wallet.Currency, wallet.Balance)
+	pTx.recordWrite(r.router, userID)
 
-	return &wallet, nil
+	return wallet, nil
 }
 
-// UpdateWalletBalance updates a wallet's balance
+// UpdateWalletBalance updates the balance of a user's wallet in the given currency
 func (r *PostgresRepository) UpdateWalletBalance(
-	ctx context.Context, userID int, newBalance float64, tx Transaction) (*model.Wallet, error) {
-	
+	ctx context.Context, userID int, currency string, newBalance float64, tx Transaction) (*model.Wallet, error) {
+
 	ctx, span := r.tracer.StartSpan(ctx, "Repository.UpdateWalletBalance",
 		trace.WithAttributes(
 			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
 			attribute.Float64("new_balance", newBalance),
 		))
 	defer span.End()
@@ -187,6 +333,7 @@ func (r *PostgresRepository) UpdateWalletBalance(
 	startTime := time.Now()
 	r.logger.Debug("Updating wallet balance",
 		zap.Int("user_id", userID),
+		zap.String("currency", currency),
 		zap.Float64("new_balance", newBalance))
 
 	pTx, ok := tx.(*PostgresTransaction)
@@ -194,37 +341,39 @@ func (r *PostgresRepository) UpdateWalletBalance(
 		return nil, fmt.Errorf("invalid transaction type")
 	}
 
-	var wallet model.Wallet
-	err := pTx.tx.QueryRowContext(ctx, QueryUpdateWalletBalance, userID, newBalance).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.CreatedAt)
+	wallet, err := scanWallet(pTx.tx.QueryRowContext(ctx, QueryUpdateWalletBalance, userID, currency, newBalance))
 
 	if err == sql.ErrNoRows {
 		r.logger.Warn("Wallet not found for update",
-			zap.Int("user_id", userID))
+			zap.Int("user_id", userID),
+			zap.String("currency", currency))
 		return nil, nil
 	}
 
 	if err != nil {
 		r.logger.Error("Failed to update wallet balance",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Error(err))
 		return nil, fmt.Errorf("update wallet balance: %w", err)
 	}
 
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("update", "wallets", duration)
-	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), "platform", wallet.Balance)
+	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), wallet.Currency, wallet.Balance)
+	pTx.recordWrite(r.router, userID)
 
-	return &wallet, nil
+	return wallet, nil
 }
 
-// SpendFromWallet spends tokens from a wallet
+// SpendFromWallet spends tokens from a user's wallet in the given currency
 func (r *PostgresRepository) SpendFromWallet(
-	ctx context.Context, userID int, amount float64, tx Transaction) (*model.Wallet, error) {
-	
-	ctx, span := r.tracer.StartSpan(ctx, "Repository.SpendFromWallet", 
+	ctx context.Context, userID int, currency string, amount float64, tx Transaction) (*model.Wallet, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.SpendFromWallet",
 		trace.WithAttributes(
 			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
 			attribute.Float64("amount", amount),
 		))
 	defer span.End()
@@ -232,6 +381,7 @@ func (r *PostgresRepository) SpendFromWallet(
 	startTime := time.Now()
 	r.logger.Debug("Spending from wallet",
 		zap.Int("user_id", userID),
+		zap.String("currency", currency),
 		zap.Float64("amount", amount))
 
 	pTx, ok := tx.(*PostgresTransaction)
@@ -239,13 +389,12 @@ func (r *PostgresRepository) SpendFromWallet(
 		return nil, fmt.Errorf("invalid transaction type")
 	}
 
-	var wallet model.Wallet
-	err := pTx.tx.QueryRowContext(ctx, QuerySpendFromWallet, userID, amount).Scan(
-		&wallet.ID, &wallet.UserID, &wallet.Balance, &wallet.CreatedAt)
+	wallet, err := scanWallet(pTx.tx.QueryRowContext(ctx, QuerySpendFromWallet, userID, currency, amount))
 
 	if err == sql.ErrNoRows {
 		r.logger.Warn("Insufficient funds or wallet not found",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Float64("amount", amount))
 		return nil, fmt.Errorf("insufficient funds")
 	}
@@ -253,6 +402,7 @@ func (r *PostgresRepository) SpendFromWallet(
 	if err != nil {
 		r.logger.Error("Failed to spend from wallet",
 			zap.Int("user_id", userID),
+			zap.String("currency", currency),
 			zap.Float64("amount", amount),
 			zap.Error(err))
 		return nil, fmt.Errorf("spend from wallet: %w", err)
@@ -260,33 +410,41 @@ func (r *PostgresRepository) SpendFromWallet(
 
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("update", "wallets", duration)
-	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), "platform", wallet.Balance)
+	r.metrics.SetWalletBalance(fmt.Sprintf("%d", userID), wallet.Currency, wallet.Balance)
+	pTx.recordWrite(r.router, userID)
 
-	return &wallet, nil
+	return wallet, nil
 }
 
-// GetExchangeRate retrieves an exchange rate
-func (r *PostgresRepository) GetExchangeRate(ctx context.Context, gameID, tokenType string) (*model.ExchangeRate, error) {
+// GetExchangeRate retrieves the exchange rate of a game token into a currency
+func (r *PostgresRepository) GetExchangeRate(
+	ctx context.Context, gameID, tokenType, currency string) (*model.ExchangeRate, error) {
+
 	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetExchangeRate",
 		trace.WithAttributes(
 			attribute.String("game_id", gameID),
 			attribute.String("token_type", tokenType),
+			attribute.String("currency", currency),
 		))
 	defer span.End()
 
 	startTime := time.Now()
 	r.logger.Debug("Getting exchange rate",
 		zap.String("game_id", gameID),
-		zap.String("token_type", tokenType))
+		zap.String("token_type", tokenType),
+		zap.String("currency", currency))
 
 	var rate model.ExchangeRate
-	err := r.db.QueryRowContext(ctx, QueryGetExchangeRate, gameID, tokenType).Scan(
-		&rate.ID, &rate.GameID, &rate.TokenType, &rate.ToPlatformRatio, &rate.CreatedAt)
+	err := r.db.QueryRowContext(ctx, QueryGetExchangeRate, gameID, tokenType, currency).Scan(
+		&rate.ID, &rate.GameID, &rate.TokenType, &rate.Currency, &rate.ToPlatformRatio, &rate.FromPlatformRatio,
+		&rate.MaxPerTransaction, &rate.UserDailyCap, &rate.GameDailyCap,
+		&rate.ReverseMaxPerTransaction, &rate.ReverseUserDailyCap, &rate.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		r.logger.Warn("Exchange rate not found",
 			zap.String("game_id", gameID),
-			zap.String("token_type", tokenType))
+			zap.String("token_type", tokenType),
+			zap.String("currency", currency))
 		return nil, nil
 	}
 
@@ -294,6 +452,7 @@ func (r *PostgresRepository) GetExchangeRate(ctx context.Context, gameID, tokenT
 		r.logger.Error("Failed to get exchange rate",
 			zap.String("game_id", gameID),
 			zap.String("token_type", tokenType),
+			zap.String("currency", currency),
 			zap.Error(err))
 		return nil, fmt.Errorf("get exchange rate: %w", err)
 	}
@@ -315,7 +474,9 @@ func (r *PostgresRepository) GetExchangeRateByID(ctx context.Context, id int64)
 
 	var rate model.ExchangeRate
 	err := r.db.QueryRowContext(ctx, QueryGetExchangeRateByID, id).Scan(
-		&rate.ID, &rate.GameID, &rate.TokenType, &rate.ToPlatformRatio, &rate.CreatedAt)
+		&rate.ID, &rate.GameID, &rate.TokenType, &rate.Currency, &rate.ToPlatformRatio, &rate.FromPlatformRatio,
+		&rate.MaxPerTransaction, &rate.UserDailyCap, &rate.GameDailyCap,
+		&rate.ReverseMaxPerTransaction, &rate.ReverseUserDailyCap, &rate.CreatedAt)
 
 	if err == sql.ErrNoRows {
 		r.logger.Warn("Exchange rate not found", zap.Int64("id", id))
@@ -346,6 +507,7 @@ func (r *PostgresRepository) CreateWalletLog(
 	r.logger.Debug("Creating wallet log entry",
 		zap.Int64("wallet_id", log.WalletID),
 		zap.Int("user_id", log.UserID),
+		zap.String("currency", log.Currency),
 		zap.Float64("amount", log.Amount),
 		zap.Float64("platform_amount", log.PlatformAmount),
 		zap.String("source", log.Source))
@@ -357,9 +519,9 @@ func (r *PostgresRepository) CreateWalletLog(
 
 	var newLog model.WalletLog
 	err := pTx.tx.QueryRowContext(ctx, QueryCreateWalletLog,
-		log.WalletID, log.UserID, log.GameID, log.TokenType,
+		log.WalletID, log.UserID, log.Currency, log.GameID, log.TokenType,
 		log.Amount, log.PlatformAmount, log.Source, log.ReferenceID).Scan(
-		&newLog.ID, &newLog.WalletID, &newLog.UserID, &newLog.GameID, &newLog.TokenType,
+		&newLog.ID, &newLog.WalletID, &newLog.UserID, &newLog.Currency, &newLog.GameID, &newLog.TokenType,
 		&newLog.Amount, &newLog.PlatformAmount, &newLog.Source, &newLog.ReferenceID, &newLog.CreatedAt)
 
 	if err != nil {
@@ -371,6 +533,7 @@ func (r *PostgresRepository) CreateWalletLog(
 
 	duration := time.Since(startTime).Seconds()
 	r.metrics.ObserveDBQueryDuration("insert", "wallet_logs", duration)
+	pTx.recordWrite(r.router, log.UserID)
 
 	return &newLog, nil
 }
@@ -397,7 +560,7 @@ func (r *PostgresRepository) GetWalletLogs(
 		limit = 50 // Default limit
 	}
 
-	rows, err := r.db.QueryContext(ctx, QueryGetWalletLogs, userID, limit, offset)
+	rows, err := r.reader(userID).QueryContext(ctx, QueryGetWalletLogs, userID, limit, offset)
 	if err != nil {
 		r.logger.Error("Failed to get wallet logs",
 			zap.Int("user_id", userID),
@@ -410,7 +573,7 @@ func (r *PostgresRepository) GetWalletLogs(
 	for rows.Next() {
 		var log model.WalletLog
 		if err := rows.Scan(
-			&log.ID, &log.WalletID, &log.UserID, &log.GameID, &log.TokenType,
+			&log.ID, &log.WalletID, &log.UserID, &log.Currency, &log.GameID, &log.TokenType,
 			&log.Amount, &log.PlatformAmount, &log.Source, &log.ReferenceID, &log.CreatedAt); err != nil {
 			r.logger.Error("Error scanning wallet log row",
 				zap.Int("user_id", userID),
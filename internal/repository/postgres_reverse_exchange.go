@@ -0,0 +1,270 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// GetReverseExchangeUsage sums the platform tokens a user exchanged back into a game token in the last day
+func (r *PostgresRepository) GetReverseExchangeUsage(ctx context.Context, userID int, gameID, tokenType, currency string,
+	now time.Time, tx Transaction) (float64, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetReverseExchangeUsage",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("game_id", gameID),
+			attribute.String("token_type", tokenType),
+			attribute.String("currency", currency),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return 0, fmt.Errorf("invalid transaction type")
+	}
+
+	var used float64
+	err := pTx.tx.QueryRowContext(ctx, QueryGetReverseExchangeUsage,
+		userID, gameID, tokenType, currency, model.TransactionReverseExchange, now.Add(-exchangeDailyWindow)).Scan(&used)
+
+	if err != nil {
+		r.logger.Error("Failed to get reverse exchange usage",
+			zap.Int("user_id", userID),
+			zap.String("game_id", gameID),
+			zap.String("token_type", tokenType),
+			zap.String("currency", currency),
+			zap.Error(err))
+		return 0, fmt.Errorf("get reverse exchange usage: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return used, nil
+}
+
+// CreateReverseExchangeGrant records a grant issued by a reverse exchange
+func (r *PostgresRepository) CreateReverseExchangeGrant(ctx context.Context, g *model.ReverseExchangeGrant,
+	tx Transaction) (*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateReverseExchangeGrant",
+		trace.WithAttributes(
+			attribute.String("grant_id", g.GrantID),
+			attribute.Int("user_id", g.UserID),
+			attribute.Float64("game_amount", g.GameAmount),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	created, err := scanReverseExchangeGrant(pTx.tx.QueryRowContext(ctx, QueryCreateReverseExchangeGrant,
+		g.GrantID, g.WalletID, g.UserID, g.GameID, g.TokenType, g.Currency, g.PlatformAmount, g.GameAmount,
+		g.ExpiresAt))
+	if err != nil {
+		r.logger.Error("Failed to create reverse exchange grant",
+			zap.String("grant_id", g.GrantID),
+			zap.Int("user_id", g.UserID),
+			zap.Error(err))
+		return nil, fmt.Errorf("create reverse exchange grant: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "reverse_exchange_grants", duration)
+
+	return created, nil
+}
+
+// GetReverseExchangeGrant retrieves a grant by its grant ID
+func (r *PostgresRepository) GetReverseExchangeGrant(ctx context.Context,
+	grantID string) (*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetReverseExchangeGrant",
+		trace.WithAttributes(attribute.String("grant_id", grantID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	g, err := scanReverseExchangeGrant(r.db.QueryRowContext(ctx, QueryGetReverseExchangeGrant, grantID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get reverse exchange grant",
+			zap.String("grant_id", grantID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get reverse exchange grant: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reverse_exchange_grants", duration)
+
+	return g, nil
+}
+
+// RedeemReverseExchangeGrant marks a grant redeemed at now when it is neither redeemed nor expired.
+// It returns nil when the grant cannot be redeemed.
+func (r *PostgresRepository) RedeemReverseExchangeGrant(ctx context.Context, grantID string, redeemedBy int,
+	now time.Time) (*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.RedeemReverseExchangeGrant",
+		trace.WithAttributes(
+			attribute.String("grant_id", grantID),
+			attribute.Int("redeemed_by", redeemedBy),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	g, err := scanReverseExchangeGrant(r.db.QueryRowContext(ctx, QueryRedeemReverseExchangeGrant,
+		grantID, now, redeemedBy))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to redeem reverse exchange grant",
+			zap.String("grant_id", grantID),
+			zap.Error(err))
+		return nil, fmt.Errorf("redeem reverse exchange grant: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "reverse_exchange_grants", duration)
+
+	return g, nil
+}
+
+// ListOpenReverseExchangeGrants retrieves the grants of a user that are neither redeemed nor expired, newest first
+func (r *PostgresRepository) ListOpenReverseExchangeGrants(ctx context.Context, userID int,
+	now time.Time) ([]*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListOpenReverseExchangeGrants",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListOpenReverseExchangeGrants, userID, now)
+	if err != nil {
+		r.logger.Error("Failed to list reverse exchange grants",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("list reverse exchange grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*model.ReverseExchangeGrant
+	for rows.Next() {
+		g, err := scanReverseExchangeGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reverse exchange grant: %w", err)
+		}
+		grants = append(grants, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan reverse exchange grants: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reverse_exchange_grants", duration)
+
+	return grants, nil
+}
+
+// ListRefundableReverseExchangeGrants retrieves up to limit grants that expired at or before now without being
+// redeemed or refunded, oldest expiry first
+func (r *PostgresRepository) ListRefundableReverseExchangeGrants(ctx context.Context, now time.Time,
+	limit int) ([]*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListRefundableReverseExchangeGrants",
+		trace.WithAttributes(attribute.Int("limit", limit)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListRefundableReverseExchangeGrants, now, limit)
+	if err != nil {
+		r.logger.Error("Failed to list refundable reverse exchange grants", zap.Error(err))
+		return nil, fmt.Errorf("list refundable reverse exchange grants: %w", err)
+	}
+	defer rows.Close()
+
+	var grants []*model.ReverseExchangeGrant
+	for rows.Next() {
+		g, err := scanReverseExchangeGrant(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scan reverse exchange grant: %w", err)
+		}
+		grants = append(grants, g)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("scan reverse exchange grants: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "reverse_exchange_grants", duration)
+
+	return grants, nil
+}
+
+// RefundReverseExchangeGrant marks a grant refunded at now when it expired without being redeemed or refunded.
+// It returns nil when the grant cannot be refunded; the update locks the grant until tx ends, so a grant
+// is refunded once even when several instances run the refund job.
+func (r *PostgresRepository) RefundReverseExchangeGrant(ctx context.Context, grantID string, now time.Time,
+	tx Transaction) (*model.ReverseExchangeGrant, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.RefundReverseExchangeGrant",
+		trace.WithAttributes(attribute.String("grant_id", grantID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	g, err := scanReverseExchangeGrant(pTx.tx.QueryRowContext(ctx, QueryRefundReverseExchangeGrant, grantID, now))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to refund reverse exchange grant",
+			zap.String("grant_id", grantID),
+			zap.Error(err))
+		return nil, fmt.Errorf("refund reverse exchange grant: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "reverse_exchange_grants", duration)
+
+	return g, nil
+}
+
+func scanReverseExchangeGrant(row scanner) (*model.ReverseExchangeGrant, error) {
+	var g model.ReverseExchangeGrant
+	if err := row.Scan(
+		&g.ID, &g.GrantID, &g.WalletID, &g.UserID, &g.GameID, &g.TokenType, &g.Currency,
+		&g.PlatformAmount, &g.GameAmount, &g.ExpiresAt, &g.RedeemedAt, &g.RedeemedBy, &g.RefundedAt,
+		&g.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &g, nil
+}
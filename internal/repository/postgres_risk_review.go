@@ -0,0 +1,177 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanRiskReview(row scanner) (*model.RiskReview, error) {
+	var review model.RiskReview
+	var payload []byte
+	if err := row.Scan(
+		&review.ID, &review.Operation, &review.UserID, &review.PrincipalID, &review.PrincipalRole,
+		&review.Amount, &review.PlatformAmount, &review.MatchedRules, &payload, &review.Status,
+		&review.ReviewedBy, &review.ReviewedAt, &review.ReviewNote, &review.ResultError, &review.CreatedAt); err != nil {
+		return nil, err
+	}
+	review.Payload = payload
+	return &review, nil
+}
+
+// CreateRiskReview places an operation in the manual review queue
+func (r *PostgresRepository) CreateRiskReview(ctx context.Context, review *model.RiskReview) (*model.RiskReview, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateRiskReview",
+		trace.WithAttributes(
+			attribute.String("operation", review.Operation),
+			attribute.Int("user_id", review.UserID),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	created, err := scanRiskReview(r.db.QueryRowContext(ctx, QueryCreateRiskReview,
+		review.Operation, review.UserID, review.PrincipalID, review.PrincipalRole,
+		review.Amount, review.PlatformAmount, review.MatchedRules, []byte(review.Payload)))
+
+	if err != nil {
+		r.logger.Error("Failed to create risk review",
+			zap.String("operation", review.Operation),
+			zap.Int("user_id", review.UserID),
+			zap.Error(err))
+		return nil, fmt.Errorf("create risk review: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "risk_reviews", duration)
+
+	return created, nil
+}
+
+// GetRiskReview retrieves a risk review by ID
+func (r *PostgresRepository) GetRiskReview(ctx context.Context, id int64) (*model.RiskReview, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetRiskReview",
+		trace.WithAttributes(attribute.Int64("review_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	review, err := scanRiskReview(r.db.QueryRowContext(ctx, QueryGetRiskReview, id))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get risk review",
+			zap.Int64("review_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("get risk review: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "risk_reviews", duration)
+
+	return review, nil
+}
+
+// ListRiskReviews retrieves risk reviews with the given status, oldest first
+func (r *PostgresRepository) ListRiskReviews(
+	ctx context.Context, status string, limit, offset int) ([]*model.RiskReview, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListRiskReviews",
+		trace.WithAttributes(attribute.String("status", status)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListRiskReviews, status, limit, offset)
+	if err != nil {
+		r.logger.Error("Failed to list risk reviews",
+			zap.String("status", status),
+			zap.Error(err))
+		return nil, fmt.Errorf("list risk reviews: %w", err)
+	}
+	defer rows.Close()
+
+	var reviews []*model.RiskReview
+	for rows.Next() {
+		review, err := scanRiskReview(rows)
+		if err != nil {
+			r.logger.Error("Error scanning risk review row", zap.Error(err))
+			return nil, fmt.Errorf("scan risk review: %w", err)
+		}
+		reviews = append(reviews, review)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating risk reviews", zap.Error(err))
+		return nil, fmt.Errorf("iterate risk reviews: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "risk_reviews", duration)
+
+	return reviews, nil
+}
+
+// DecideRiskReview approves or rejects a pending risk review.
+// It returns nil when the review does not exist or was already decided.
+func (r *PostgresRepository) DecideRiskReview(
+	ctx context.Context, id int64, status string, reviewedBy int, note string) (*model.RiskReview, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.DecideRiskReview",
+		trace.WithAttributes(
+			attribute.Int64("review_id", id),
+			attribute.String("status", status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	review, err := scanRiskReview(r.db.QueryRowContext(ctx, QueryDecideRiskReview, id, status, reviewedBy, note))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to decide risk review",
+			zap.Int64("review_id", id),
+			zap.String("status", status),
+			zap.Error(err))
+		return nil, fmt.Errorf("decide risk review: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "risk_reviews", duration)
+
+	return review, nil
+}
+
+// FailRiskReview records that an approved operation could not be executed
+func (r *PostgresRepository) FailRiskReview(ctx context.Context, id int64, resultError string) (*model.RiskReview, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.FailRiskReview",
+		trace.WithAttributes(attribute.Int64("review_id", id)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	review, err := scanRiskReview(r.db.QueryRowContext(ctx, QueryFailRiskReview, id, resultError))
+	if err != nil {
+		r.logger.Error("Failed to mark risk review as failed",
+			zap.Int64("review_id", id),
+			zap.Error(err))
+		return nil, fmt.Errorf("fail risk review: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "risk_reviews", duration)
+
+	return review, nil
+}
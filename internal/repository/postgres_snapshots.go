@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// CreateBalanceSnapshots snapshots the next batch of wallets at takenAt. Snapshots that already exist for
+// takenAt are kept, so instances snapshotting the same point in time do not conflict.
+func (r *PostgresRepository) CreateBalanceSnapshots(
+	ctx context.Context, takenAt time.Time, afterWalletID int64, limit int) (int, int64, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateBalanceSnapshots",
+		trace.WithAttributes(
+			attribute.Int64("after_wallet_id", afterWalletID),
+			attribute.Int("limit", limit),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var created int
+	var lastWalletID sql.NullInt64
+	err := r.db.QueryRowContext(ctx, QueryCreateBalanceSnapshots, takenAt, afterWalletID, limit).
+		Scan(&created, &lastWalletID)
+	if err != nil {
+		r.logger.Error("Failed to create balance snapshots",
+			zap.Int64("after_wallet_id", afterWalletID),
+			zap.Error(err))
+		return 0, 0, fmt.Errorf("create balance snapshots: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "wallet_balance_snapshots", duration)
+
+	return created, lastWalletID.Int64, nil
+}
+
+// GetBalanceAt computes a wallet's balance at a point in time from its nearest snapshot, or its archived
+// balance, and later logs
+func (r *PostgresRepository) GetBalanceAt(
+	ctx context.Context, walletID int64, at time.Time) (*model.PointInTimeBalance, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetBalanceAt",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	var balance model.PointInTimeBalance
+	err := r.db.QueryRowContext(ctx, QueryGetBalanceAt, walletID, at).
+		Scan(&balance.Balance, &balance.SnapshotAt, &balance.LogsApplied, &balance.LastArchivedLogAt)
+	if err != nil {
+		r.logger.Error("Failed to get balance at point in time",
+			zap.Int64("wallet_id", walletID),
+			zap.Time("at", at),
+			zap.Error(err))
+		return nil, fmt.Errorf("get balance at: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_balance_snapshots", duration)
+
+	return &balance, nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Rolling windows over which spend usage is measured
+const (
+	spendVelocityWindow = time.Minute
+	spendDailyWindow    = 24 * time.Hour
+	spendWeeklyWindow   = 7 * 24 * time.Hour
+)
+
+// GetSpendLimitOverride retrieves the spend limit override of a user
+func (r *PostgresRepository) GetSpendLimitOverride(ctx context.Context, userID int) (*model.SpendLimitOverride, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetSpendLimitOverride",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	override, err := scanSpendLimitOverride(r.db.QueryRowContext(ctx, QueryGetSpendLimitOverride, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get spend limit override",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get spend limit override: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "spend_limit_overrides", duration)
+
+	return override, nil
+}
+
+// UpsertSpendLimitOverride creates or replaces the spend limit override of a user
+func (r *PostgresRepository) UpsertSpendLimitOverride(
+	ctx context.Context, override *model.SpendLimitOverride) (*model.SpendLimitOverride, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.UpsertSpendLimitOverride",
+		trace.WithAttributes(attribute.Int("user_id", override.UserID)))
+	defer span.End()
+
+	startTime := time.Now()
+	r.logger.Debug("Upserting spend limit override", zap.Int("user_id", override.UserID))
+
+	saved, err := scanSpendLimitOverride(r.db.QueryRowContext(ctx, QueryUpsertSpendLimitOverride,
+		override.UserID, override.MaxPerTransaction, override.DailyCap, override.WeeklyCap,
+		override.MaxSpendsPerMinute, override.Reason, override.UpdatedBy))
+
+	if err != nil {
+		r.logger.Error("Failed to upsert spend limit override",
+			zap.Int("user_id", override.UserID),
+			zap.Error(err))
+		return nil, fmt.Errorf("upsert spend limit override: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("upsert", "spend_limit_overrides", duration)
+
+	return saved, nil
+}
+
+// DeleteSpendLimitOverride removes the spend limit override of a user and reports whether one existed
+func (r *PostgresRepository) DeleteSpendLimitOverride(ctx context.Context, userID int) (bool, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.DeleteSpendLimitOverride",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	result, err := r.db.ExecContext(ctx, QueryDeleteSpendLimitOverride, userID)
+	if err != nil {
+		r.logger.Error("Failed to delete spend limit override",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return false, fmt.Errorf("delete spend limit override: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("delete spend limit override: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("delete", "spend_limit_overrides", duration)
+
+	return affected > 0, nil
+}
+
+// GetSpendUsage sums a user's spends in one currency over the rolling limit windows ending at now.
+// When tx is not nil the usage is read inside the transaction holding the wallet lock.
+func (r *PostgresRepository) GetSpendUsage(
+	ctx context.Context, userID int, currency string, now time.Time, tx Transaction) (*model.SpendUsage, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetSpendUsage",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	args := []interface{}{
+		userID,
+		now.Add(-spendDailyWindow),
+		now.Add(-spendWeeklyWindow),
+		now.Add(-spendVelocityWindow),
+		currency,
+	}
+
+	var row *sql.Row
+	if tx == nil {
+		row = r.db.QueryRowContext(ctx, QueryGetSpendUsage, args...)
+	} else {
+		pTx, ok := tx.(*PostgresTransaction)
+		if !ok {
+			return nil, fmt.Errorf("invalid transaction type")
+		}
+		row = pTx.tx.QueryRowContext(ctx, QueryGetSpendUsage, args...)
+	}
+
+	var usage model.SpendUsage
+	if err := row.Scan(&usage.SpentLastDay, &usage.SpentLastWeek, &usage.SpendsLastMinute); err != nil {
+		r.logger.Error("Failed to get spend usage",
+			zap.Int("user_id", userID),
+			zap.String("currency", currency),
+			zap.Error(err))
+		return nil, fmt.Errorf("get spend usage: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return &usage, nil
+}
+
+func scanSpendLimitOverride(row scanner) (*model.SpendLimitOverride, error) {
+	var override model.SpendLimitOverride
+	if err := row.Scan(
+		&override.UserID, &override.MaxPerTransaction, &override.DailyCap, &override.WeeklyCap,
+		&override.MaxSpendsPerMinute, &override.Reason, &override.UpdatedBy, &override.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &override, nil
+}
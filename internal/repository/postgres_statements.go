@@ -0,0 +1,159 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func scanWalletStatement(row scanner) (*model.WalletStatement, error) {
+	var statement model.WalletStatement
+	var entries []byte
+	if err := row.Scan(
+		&statement.ID, &statement.WalletID, &statement.UserID, &statement.Currency, &statement.Period,
+		&statement.PeriodStart, &statement.PeriodEnd, &statement.OpeningBalance, &statement.ClosingBalance,
+		&statement.TotalCredits, &statement.TotalDebits, &statement.EntryCount, &entries,
+		&statement.GeneratedAt); err != nil {
+		return nil, err
+	}
+	statement.Entries = entries
+	return &statement, nil
+}
+
+// GetWalletStatement retrieves the stored statement of a wallet for a period, or nil if none was generated
+func (r *PostgresRepository) GetWalletStatement(
+	ctx context.Context, walletID int64, period string) (*model.WalletStatement, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletStatement",
+		trace.WithAttributes(
+			attribute.Int64("wallet_id", walletID),
+			attribute.String("period", period),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	statement, err := scanWalletStatement(r.db.QueryRowContext(ctx, QueryGetWalletStatement, walletID, period))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to get wallet statement",
+			zap.Int64("wallet_id", walletID),
+			zap.String("period", period),
+			zap.Error(err))
+		return nil, fmt.Errorf("get wallet statement: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_statements", duration)
+
+	return statement, nil
+}
+
+// CreateWalletStatement stores a statement. It returns nil when a statement of the same wallet and period
+// was stored first, which then stays the statement of record.
+func (r *PostgresRepository) CreateWalletStatement(
+	ctx context.Context, statement *model.WalletStatement) (*model.WalletStatement, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateWalletStatement",
+		trace.WithAttributes(
+			attribute.Int64("wallet_id", statement.WalletID),
+			attribute.String("period", statement.Period),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	created, err := scanWalletStatement(r.db.QueryRowContext(ctx, QueryCreateWalletStatement,
+		statement.WalletID, statement.UserID, statement.Currency, statement.Period,
+		statement.PeriodStart, statement.PeriodEnd, statement.OpeningBalance, statement.ClosingBalance,
+		statement.TotalCredits, statement.TotalDebits, statement.EntryCount, []byte(statement.Entries)))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to create wallet statement",
+			zap.Int64("wallet_id", statement.WalletID),
+			zap.String("period", statement.Period),
+			zap.Error(err))
+		return nil, fmt.Errorf("create wallet statement: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "wallet_statements", duration)
+
+	return created, nil
+}
+
+// GetStatementLedger reads a wallet's balance at from and its logs from from (inclusive) to to (exclusive)
+// in one read-only repeatable read transaction, so both come from the same snapshot
+func (r *PostgresRepository) GetStatementLedger(
+	ctx context.Context, walletID int64, from, to time.Time) (*model.StatementLedger, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetStatementLedger",
+		trace.WithAttributes(attribute.Int64("wallet_id", walletID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	tx, err := r.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		r.logger.Error("Failed to begin statement transaction", zap.Error(err))
+		return nil, fmt.Errorf("begin statement transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var ledger model.StatementLedger
+	err = tx.QueryRowContext(ctx, QueryGetWalletBalanceBefore, walletID, from).
+		Scan(&ledger.OpeningBalance, &ledger.LastArchivedLogAt)
+	if err != nil {
+		r.logger.Error("Failed to get opening balance",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get opening balance: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, QueryGetWalletLogsInRange, walletID, from, to)
+	if err != nil {
+		r.logger.Error("Failed to get statement logs",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get statement logs: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var log model.WalletLog
+		if err := rows.Scan(
+			&log.ID, &log.WalletID, &log.UserID, &log.Currency, &log.GameID, &log.TokenType,
+			&log.Amount, &log.PlatformAmount, &log.Source, &log.ReferenceID, &log.CreatedAt); err != nil {
+			r.logger.Error("Error scanning wallet log row",
+				zap.Int64("wallet_id", walletID),
+				zap.Error(err))
+			return nil, fmt.Errorf("scan wallet log: %w", err)
+		}
+		ledger.Logs = append(ledger.Logs, &log)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating statement logs",
+			zap.Int64("wallet_id", walletID),
+			zap.Error(err))
+		return nil, fmt.Errorf("iterate statement logs: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return &ledger, nil
+}
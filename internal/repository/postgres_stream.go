@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"github.com/lib/pq"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// listenerMinReconnect and listenerMaxReconnect bound the wait between attempts to reconnect a lost
+	// notification connection
+	listenerMinReconnect = time.Second
+	listenerMaxReconnect = 30 * time.Second
+
+	// listenerPingInterval is how often an idle notification connection is checked, so a silently dropped
+	// connection is noticed and reconnected
+	listenerPingInterval = 90 * time.Second
+)
+
+// ListWalletLogsBetween retrieves up to limit logs of a user with an ID above afterID that were committed after
+// snapshot since and before snapshot until, oldest first
+func (r *PostgresRepository) ListWalletLogsBetween(ctx context.Context, userID int, since, until string,
+	afterID int64, limit int) ([]*model.WalletLog, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.ListWalletLogsBetween",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.Int64("after_id", afterID),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryListWalletLogsBetween, userID, since, until, afterID, limit)
+	if err != nil {
+		r.logger.Error("Failed to list wallet logs between snapshots",
+			zap.Int("user_id", userID),
+			zap.String("since", since),
+			zap.String("until", until),
+			zap.Error(err))
+		return nil, fmt.Errorf("list wallet logs between snapshots: %w", err)
+	}
+	defer rows.Close()
+
+	var logs []*model.WalletLog
+	for rows.Next() {
+		var log model.WalletLog
+		if err := rows.Scan(
+			&log.ID, &log.WalletID, &log.UserID, &log.Currency, &log.GameID, &log.TokenType,
+			&log.Amount, &log.PlatformAmount, &log.Source, &log.ReferenceID, &log.CreatedAt); err != nil {
+			r.logger.Error("Error scanning wallet log row",
+				zap.Int("user_id", userID),
+				zap.Error(err))
+			return nil, fmt.Errorf("scan wallet log: %w", err)
+		}
+		logs = append(logs, &log)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating wallet logs",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("iterate wallet logs: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_logs", duration)
+
+	return logs, nil
+}
+
+// GetCurrentSnapshot retrieves the current snapshot of the primary database in its text form
+func (r *PostgresRepository) GetCurrentSnapshot(ctx context.Context) (string, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetCurrentSnapshot")
+	defer span.End()
+
+	startTime := time.Now()
+
+	var snapshot string
+	if err := r.db.QueryRowContext(ctx, QueryGetCurrentSnapshot).Scan(&snapshot); err != nil {
+		r.logger.Error("Failed to get current snapshot", zap.Error(err))
+		return "", fmt.Errorf("get current snapshot: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "pg_snapshot", duration)
+
+	return snapshot, nil
+}
+
+// GetWalletBalances retrieves a user's wallets from the primary database. Replicas and the wallet cache may
+// lag behind the commit of a log a stream was just notified of, so stream balances never use them.
+func (r *PostgresRepository) GetWalletBalances(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletBalances",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryGetWalletsByUserID, userID)
+	if err != nil {
+		r.logger.Error("Failed to get wallet balances",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get wallet balances: %w", err)
+	}
+	defer rows.Close()
+
+	wallets, err := scanWallets(rows)
+	if err != nil {
+		r.logger.Error("Error scanning wallets",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("scan wallets: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallets", duration)
+
+	return wallets, nil
+}
+
+// PostgresWalletEventListener receives wallet events through Postgres LISTEN/NOTIFY on a dedicated
+// connection outside the pool
+type PostgresWalletEventListener struct {
+	dsn    string
+	logger *zap.Logger
+}
+
+// Compile-time verification that PostgresWalletEventListener implements WalletEventListener
+var _ WalletEventListener = (*PostgresWalletEventListener)(nil)
+
+// NewPostgresWalletEventListener creates a wallet event listener connecting with the given DSN
+func NewPostgresWalletEventListener(dsn string, obs *observability.Observability) *PostgresWalletEventListener {
+	return &PostgresWalletEventListener{
+		dsn:    dsn,
+		logger: obs.Logger.Logger.With(zap.String("component", "wallet_event_listener")),
+	}
+}
+
+// Listen calls fn for every wallet event until ctx is done. A lost connection is reconnected in the
+// background; fn is then called with nil, since events sent in the meantime are lost.
+func (l *PostgresWalletEventListener) Listen(ctx context.Context, fn func(*model.WalletEvent)) error {
+	return listenNotifications(ctx, l.dsn, model.WalletEventChannel, l.logger, func(payload string, lost bool) {
+		if lost {
+			fn(nil)
+			return
+		}
+
+		var event model.WalletEvent
+		if err := json.Unmarshal([]byte(payload), &event); err != nil {
+			l.logger.Warn("Ignoring malformed wallet event",
+				zap.String("payload", payload),
+				zap.Error(err))
+			return
+		}
+		fn(&event)
+	})
+}
+
+// listenNotifications calls fn with the payload of every notification on a channel until ctx is done. A
+// lost connection is reconnected in the background; fn is then called with lost set, since notifications
+// sent in the meantime are lost.
+func listenNotifications(ctx context.Context, dsn, channel string, logger *zap.Logger,
+	fn func(payload string, lost bool)) error {
+
+	listener := pq.NewListener(dsn, listenerMinReconnect, listenerMaxReconnect,
+		func(event pq.ListenerEventType, err error) {
+			switch event {
+			case pq.ListenerEventDisconnected:
+				logger.Warn("Notification connection lost", zap.Error(err))
+			case pq.ListenerEventReconnected:
+				logger.Info("Notification connection restored")
+			case pq.ListenerEventConnectionAttemptFailed:
+				logger.Warn("Failed to connect for notifications", zap.Error(err))
+			}
+		})
+	defer listener.Close()
+
+	if err := listener.Listen(channel); err != nil {
+		return fmt.Errorf("listen on %s: %w", channel, err)
+	}
+
+	ping := time.NewTicker(listenerPingInterval)
+	defer ping.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case notification := <-listener.Notify:
+			if notification == nil {
+				fn("", true)
+				continue
+			}
+			fn(notification.Extra, false)
+		case <-ping.C:
+			if err := listener.Ping(); err != nil {
+				logger.Warn("Notification connection ping failed", zap.Error(err))
+			}
+		}
+	}
+}
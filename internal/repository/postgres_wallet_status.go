@@ -0,0 +1,142 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// UpdateWalletStatus changes the status of all of a user's currency wallets and records the reason on them.
+// It returns the user's oldest wallet.
+func (r *PostgresRepository) UpdateWalletStatus(
+	ctx context.Context, userID int, status, reason string, tx Transaction) (*model.Wallet, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.UpdateWalletStatus",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("status", status),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+	r.logger.Debug("Updating wallet status",
+		zap.Int("user_id", userID),
+		zap.String("status", status))
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	wallet, err := scanWallet(pTx.tx.QueryRowContext(ctx, QueryUpdateWalletStatus, userID, status, reason))
+
+	if err == sql.ErrNoRows {
+		r.logger.Warn("Wallet not found for status update", zap.Int("user_id", userID))
+		return nil, nil
+	}
+
+	if err != nil {
+		r.logger.Error("Failed to update wallet status",
+			zap.Int("user_id", userID),
+			zap.String("status", status),
+			zap.Error(err))
+		return nil, fmt.Errorf("update wallet status: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("update", "wallets", duration)
+	pTx.recordWrite(r.router, userID)
+
+	return wallet, nil
+}
+
+// CreateWalletStatusChange records a wallet status transition
+func (r *PostgresRepository) CreateWalletStatusChange(
+	ctx context.Context, change *model.WalletStatusChange, tx Transaction) (*model.WalletStatusChange, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.CreateWalletStatusChange",
+		trace.WithAttributes(
+			attribute.Int("user_id", change.UserID),
+			attribute.String("from_status", change.FromStatus),
+			attribute.String("to_status", change.ToStatus),
+		))
+	defer span.End()
+
+	startTime := time.Now()
+
+	pTx, ok := tx.(*PostgresTransaction)
+	if !ok {
+		return nil, fmt.Errorf("invalid transaction type")
+	}
+
+	var created model.WalletStatusChange
+	err := pTx.tx.QueryRowContext(ctx, QueryCreateWalletStatusChange,
+		change.WalletID, change.UserID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedBy).Scan(
+		&created.ID, &created.WalletID, &created.UserID, &created.FromStatus, &created.ToStatus,
+		&created.Reason, &created.ChangedBy, &created.CreatedAt)
+
+	if err != nil {
+		r.logger.Error("Failed to create wallet status change",
+			zap.Int("user_id", change.UserID),
+			zap.Error(err))
+		return nil, fmt.Errorf("create wallet status change: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("insert", "wallet_status_changes", duration)
+
+	return &created, nil
+}
+
+// GetWalletStatusChanges retrieves the status history of a user's wallet, newest first
+func (r *PostgresRepository) GetWalletStatusChanges(
+	ctx context.Context, userID int) ([]*model.WalletStatusChange, error) {
+
+	ctx, span := r.tracer.StartSpan(ctx, "Repository.GetWalletStatusChanges",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	startTime := time.Now()
+
+	rows, err := r.db.QueryContext(ctx, QueryGetWalletStatusChanges, userID)
+	if err != nil {
+		r.logger.Error("Failed to get wallet status changes",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("get wallet status changes: %w", err)
+	}
+	defer rows.Close()
+
+	var changes []*model.WalletStatusChange
+	for rows.Next() {
+		var change model.WalletStatusChange
+		if err := rows.Scan(
+			&change.ID, &change.WalletID, &change.UserID, &change.FromStatus, &change.ToStatus,
+			&change.Reason, &change.ChangedBy, &change.CreatedAt); err != nil {
+			r.logger.Error("Error scanning wallet status change row",
+				zap.Int("user_id", userID),
+				zap.Error(err))
+			return nil, fmt.Errorf("scan wallet status change: %w", err)
+		}
+		changes = append(changes, &change)
+	}
+
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Error iterating wallet status changes",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, fmt.Errorf("iterate wallet status changes: %w", err)
+	}
+
+	duration := time.Since(startTime).Seconds()
+	r.metrics.ObserveDBQueryDuration("select", "wallet_status_changes", duration)
+
+	return changes, nil
+}
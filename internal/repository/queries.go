@@ -5,55 +5,887 @@ package repository
 const (
 	// Wallet queries
 	QueryGetWalletByUserID = `
-		SELECT id, user_id, balance, created_at 
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
 		FROM wallets 
-		WHERE user_id = $1`
+		WHERE user_id = $1 AND currency = $2`
+
+	QueryGetWalletsByUserID = `
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
+		FROM wallets 
+		WHERE user_id = $1 
+		ORDER BY id`
 
 	QueryGetWalletByUserIDForUpdate = `
-		SELECT id, user_id, balance, created_at 
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
+		FROM wallets 
+		WHERE user_id = $1 AND currency = $2 
+		FOR UPDATE`
+
+	QueryGetWalletsByUserIDForUpdate = `
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
 		FROM wallets 
 		WHERE user_id = $1 
+		ORDER BY id 
 		FOR UPDATE`
 
+	// New currency wallets inherit the status shared by the user's existing wallets
 	QueryCreateWallet = `
-		INSERT INTO wallets (user_id, balance) 
-		VALUES ($1, $2) 
-		RETURNING id, user_id, balance, created_at`
+		INSERT INTO wallets (user_id, currency, balance, status, status_reason, status_changed_at) 
+		SELECT $1, $2, $3, COALESCE(w.status, 'active'), w.status_reason, w.status_changed_at 
+		FROM (SELECT 1) AS one 
+		LEFT JOIN LATERAL ( 
+			SELECT status, status_reason, status_changed_at 
+			FROM wallets 
+			WHERE user_id = $1 
+			ORDER BY id 
+			LIMIT 1 
+		) w ON true 
+		RETURNING id, user_id, currency, balance, status, status_reason, status_changed_at, created_at`
 
 	QueryUpdateWalletBalance = `
 		UPDATE wallets 
-		SET balance = $2 
+		SET balance = $3 
+		WHERE user_id = $1 AND currency = $2 
+		RETURNING id, user_id, currency, balance, status, status_reason, status_changed_at, created_at`
+
+	// The status applies to every currency wallet of the user; the oldest wallet is returned
+	QueryUpdateWalletStatus = `
+		WITH updated AS ( 
+			UPDATE wallets 
+			SET status = $2, status_reason = $3, status_changed_at = CURRENT_TIMESTAMP 
+			WHERE user_id = $1 
+			RETURNING id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
+		) 
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
+		FROM updated 
+		ORDER BY id 
+		LIMIT 1`
+
+	// Wallet status history queries
+	QueryCreateWalletStatusChange = `
+		INSERT INTO wallet_status_changes (wallet_id, user_id, from_status, to_status, reason, changed_by) 
+		VALUES ($1, $2, $3, $4, $5, $6) 
+		RETURNING id, wallet_id, user_id, from_status, to_status, reason, changed_by, created_at`
+
+	QueryGetWalletStatusChanges = `
+		SELECT id, wallet_id, user_id, from_status, to_status, reason, changed_by, created_at 
+		FROM wallet_status_changes 
 		WHERE user_id = $1 
-		RETURNING id, user_id, balance, created_at`
+		ORDER BY created_at DESC, id DESC`
 
 	// Exchange rate queries
 	QueryGetExchangeRate = `
-		SELECT id, game_id, token_type, to_platform_ratio, created_at 
+		SELECT id, game_id, token_type, currency, to_platform_ratio, from_platform_ratio, max_per_transaction, user_daily_cap, game_daily_cap, reverse_max_per_transaction, reverse_user_daily_cap, created_at 
 		FROM exchange_rates 
-		WHERE game_id = $1 AND token_type = $2`
+		WHERE game_id = $1 AND token_type = $2 AND currency = $3`
 
 	QueryGetExchangeRateByID = `
-		SELECT id, game_id, token_type, to_platform_ratio, created_at 
+		SELECT id, game_id, token_type, currency, to_platform_ratio, from_platform_ratio, max_per_transaction, user_daily_cap, game_daily_cap, reverse_max_per_transaction, reverse_user_daily_cap, created_at 
 		FROM exchange_rates 
 		WHERE id = $1`
 
+	QueryLockExchangeRate = `
+		SELECT id 
+		FROM exchange_rates 
+		WHERE id = $1 
+		FOR UPDATE`
+
+	QueryGetExchangeUsage = `
+		SELECT 
+			COALESCE(SUM(amount) FILTER (WHERE user_id = $1), 0), 
+			COALESCE(SUM(amount), 0) 
+		FROM wallet_logs 
+		WHERE game_id = $2 AND token_type = $3 AND currency = $4 AND source = $5 AND created_at >= $6`
+
+	QueryGetReverseExchangeUsage = `
+		SELECT COALESCE(SUM(-platform_amount), 0) 
+		FROM wallet_logs 
+		WHERE user_id = $1 AND game_id = $2 AND token_type = $3 AND currency = $4 AND source = $5 AND created_at >= $6`
+
+	// Reverse exchange grant queries
+	QueryCreateReverseExchangeGrant = `
+		INSERT INTO reverse_exchange_grants (grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) 
+		RETURNING id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at`
+
+	QueryGetReverseExchangeGrant = `
+		SELECT id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at 
+		FROM reverse_exchange_grants 
+		WHERE grant_id = $1`
+
+	QueryRedeemReverseExchangeGrant = `
+		UPDATE reverse_exchange_grants 
+		SET redeemed_at = $2, redeemed_by = $3 
+		WHERE grant_id = $1 AND redeemed_at IS NULL AND refunded_at IS NULL AND expires_at > $2 
+		RETURNING id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at`
+
+	QueryListOpenReverseExchangeGrants = `
+		SELECT id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at 
+		FROM reverse_exchange_grants 
+		WHERE user_id = $1 AND redeemed_at IS NULL AND refunded_at IS NULL AND expires_at > $2 
+		ORDER BY created_at DESC`
+
+	// QueryListRefundableReverseExchangeGrants lists the grants that expired without being redeemed or refunded
+	QueryListRefundableReverseExchangeGrants = `
+		SELECT id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at 
+		FROM reverse_exchange_grants 
+		WHERE redeemed_at IS NULL AND refunded_at IS NULL AND expires_at <= $1 
+		ORDER BY expires_at, id 
+		LIMIT $2`
+
+	// QueryRefundReverseExchangeGrant marks an expired grant refunded unless it was redeemed or refunded before
+	QueryRefundReverseExchangeGrant = `
+		UPDATE reverse_exchange_grants 
+		SET refunded_at = $2 
+		WHERE grant_id = $1 AND redeemed_at IS NULL AND refunded_at IS NULL AND expires_at <= $2 
+		RETURNING id, grant_id, wallet_id, user_id, game_id, token_type, currency, platform_amount, game_amount, expires_at, redeemed_at, redeemed_by, refunded_at, created_at`
+
 	// Wallet logs queries
 	QueryCreateWalletLog = `
-		INSERT INTO wallet_logs (wallet_id, user_id, game_id, token_type, amount, platform_amount, source, reference_id) 
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-		RETURNING id, wallet_id, user_id, game_id, token_type, amount, platform_amount, source, reference_id, created_at`
+		INSERT INTO wallet_logs (wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		RETURNING id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at`
 
 	QueryGetWalletLogs = `
-		SELECT id, wallet_id, user_id, game_id, token_type, amount, platform_amount, source, reference_id, created_at 
+		SELECT id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at 
 		FROM wallet_logs 
 		WHERE user_id = $1 
-		ORDER BY created_at DESC 
+		ORDER BY created_at DESC, id DESC 
 		LIMIT $2 OFFSET $3`
 
+	// QueryListWalletLogsBetween lists the logs of a user committed between two snapshots, visible in $3 but not
+	// in $2, after log $4. Every transaction older than the first snapshot is visible in it, so only the logs of
+	// later transactions are read.
+	QueryListWalletLogsBetween = `
+		SELECT id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at 
+		FROM wallet_logs 
+		WHERE user_id = $1 AND xact_id >= pg_snapshot_xmin($2::pg_snapshot) 
+			AND NOT pg_visible_in_snapshot(xact_id, $2::pg_snapshot) 
+			AND pg_visible_in_snapshot(xact_id, $3::pg_snapshot) 
+			AND id > $4 
+		ORDER BY id 
+		LIMIT $5`
+
+	QueryGetCurrentSnapshot = `
+		SELECT pg_current_snapshot()::text`
+
+	// QueryStreamWalletLogs selects the logs of an export, oldest first; NULL parameters match all logs
+	QueryStreamWalletLogs = `
+		SELECT id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at 
+		FROM wallet_logs 
+		WHERE ($1::int IS NULL OR user_id = $1) 
+			AND ($2::text IS NULL OR game_id = $2) 
+			AND ($3::text IS NULL OR token_type = $3) 
+			AND ($4::text IS NULL OR currency = $4) 
+			AND ($5::text IS NULL OR source = $5) 
+			AND ($6::timestamp IS NULL OR created_at >= $6) 
+			AND ($7::timestamp IS NULL OR created_at < $7) 
+		ORDER BY created_at, id`
+
 	// Spend queries
 	QuerySpendFromWallet = `
 		UPDATE wallets 
-		SET balance = balance - $2 
-		WHERE user_id = $1 AND balance >= $2 
-		RETURNING id, user_id, balance, created_at`
+		SET balance = balance - $3 
+		WHERE user_id = $1 AND currency = $2 AND balance >= $3 
+		RETURNING id, user_id, currency, balance, status, status_reason, status_changed_at, created_at`
+
+	// Balance lot queries
+	QueryCreateBalanceLot = `
+		INSERT INTO wallet_balance_lots (wallet_id, amount, remaining, source, reference_id, expires_at) 
+		VALUES ($1, $2, $2, $3, $4, $5) 
+		RETURNING id, wallet_id, amount, remaining, source, reference_id, expires_at, expired_at, created_at`
+
+	// Open lots in consumption order: expiring lots first, soonest expiry first, then oldest first
+	QueryGetSpendableBalanceLotsForUpdate = `
+		SELECT id, wallet_id, amount, remaining, source, reference_id, expires_at, expired_at, created_at 
+		FROM wallet_balance_lots 
+		WHERE wallet_id = $1 AND remaining > 0 AND (expires_at IS NULL OR expires_at > $2) 
+		ORDER BY expires_at ASC NULLS LAST, id 
+		FOR UPDATE`
+
+	QueryUpdateBalanceLotRemaining = `
+		UPDATE wallet_balance_lots 
+		SET remaining = $2 
+		WHERE id = $1`
+
+	// Returns the expired lots with the amount they still held before expiring
+	QueryExpireBalanceLots = `
+		WITH due AS ( 
+			SELECT id, remaining 
+			FROM wallet_balance_lots 
+			WHERE wallet_id = $1 AND remaining > 0 AND expires_at <= $2 
+			FOR UPDATE 
+		) 
+		UPDATE wallet_balance_lots l 
+		SET remaining = 0, expired_at = $2 
+		FROM due 
+		WHERE l.id = due.id 
+		RETURNING l.id, l.wallet_id, l.amount, due.remaining, l.source, l.reference_id, l.expires_at, l.expired_at, l.created_at`
+
+	QueryListExpiringBalanceLots = `
+		SELECT id, wallet_id, amount, remaining, source, reference_id, expires_at, expired_at, created_at 
+		FROM wallet_balance_lots 
+		WHERE wallet_id = $1 AND remaining > 0 AND expires_at > $2 AND expires_at <= $3 
+		ORDER BY expires_at, id`
+
+	QueryListWalletsWithDueBalanceLots = `
+		SELECT w.id, w.user_id, w.currency, w.balance, w.status, w.status_reason, w.status_changed_at, w.created_at 
+		FROM wallets w 
+		WHERE EXISTS ( 
+			SELECT 1 
+			FROM wallet_balance_lots l 
+			WHERE l.wallet_id = w.id AND l.remaining > 0 AND l.expires_at <= $1 
+		) 
+		ORDER BY w.id 
+		LIMIT $2`
+
+	// Reconciliation queries
+
+	// QueryListWalletLedgerBalances sums the archived and the remaining logs of each wallet
+	QueryListWalletLedgerBalances = `
+		SELECT w.id, w.user_id, w.balance, COALESCE(a.balance, 0) + COALESCE(SUM(l.platform_amount), 0), w.status
+		FROM wallets w
+		LEFT JOIN wallet_log_archive_balances a ON a.wallet_id = w.id
+		LEFT JOIN wallet_logs l ON l.wallet_id = w.id
+		WHERE w.id > $1
+		GROUP BY w.id, w.user_id, w.balance, w.status, a.balance
+		ORDER BY w.id
+		LIMIT $2`
+
+	// QueryTryLockReconciliation takes the session lock that keeps reconciliation runs of all instances from
+	// overlapping; it returns false at once when another session holds it
+	QueryTryLockReconciliation = `
+		SELECT pg_try_advisory_lock(hashtext('reconciliation'))`
+
+	QueryUnlockReconciliation = `
+		SELECT pg_advisory_unlock(hashtext('reconciliation'))`
+
+	QueryCreateReconciliationRun = `
+		INSERT INTO reconciliation_runs (trigger, status) 
+		VALUES ($1, $2) 
+		RETURNING id, trigger, status, wallets_checked, mismatches, total_drift, error, started_at, finished_at`
+
+	QueryCompleteReconciliationRun = `
+		UPDATE reconciliation_runs 
+		SET status = $2, wallets_checked = $3, mismatches = $4, total_drift = $5, error = $6, finished_at = CURRENT_TIMESTAMP 
+		WHERE id = $1 
+		RETURNING id, trigger, status, wallets_checked, mismatches, total_drift, error, started_at, finished_at`
+
+	QueryGetReconciliationRun = `
+		SELECT id, trigger, status, wallets_checked, mismatches, total_drift, error, started_at, finished_at 
+		FROM reconciliation_runs 
+		WHERE id = $1`
+
+	QueryGetLatestReconciliationRun = `
+		SELECT id, trigger, status, wallets_checked, mismatches, total_drift, error, started_at, finished_at 
+		FROM reconciliation_runs 
+		ORDER BY id DESC 
+		LIMIT 1`
+
+	QueryCreateReconciliationMismatch = `
+		INSERT INTO reconciliation_mismatches (run_id, wallet_id, user_id, balance, ledger_balance, drift, frozen) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		RETURNING id, run_id, wallet_id, user_id, balance, ledger_balance, drift, frozen, resolved_by, resolved_at, resolution_note, created_at`
+
+	QueryListReconciliationMismatches = `
+		SELECT id, run_id, wallet_id, user_id, balance, ledger_balance, drift, frozen, resolved_by, resolved_at, resolution_note, created_at 
+		FROM reconciliation_mismatches 
+		WHERE run_id = $1 
+		ORDER BY id`
+
+	QueryResolveReconciliationMismatch = `
+		UPDATE reconciliation_mismatches 
+		SET resolved_by = $2, resolved_at = CURRENT_TIMESTAMP, resolution_note = $3 
+		WHERE id = $1 AND resolved_at IS NULL 
+		RETURNING id, run_id, wallet_id, user_id, balance, ledger_balance, drift, frozen, resolved_by, resolved_at, resolution_note, created_at`
+
+	QueryCountUnresolvedMismatches = `
+		SELECT COUNT(*) 
+		FROM reconciliation_mismatches 
+		WHERE wallet_id = $1 AND resolved_at IS NULL`
+
+	// Spend limit queries
+	QueryGetSpendLimitOverride = `
+		SELECT user_id, max_per_transaction, daily_cap, weekly_cap, max_spends_per_minute, reason, updated_by, updated_at 
+		FROM spend_limit_overrides 
+		WHERE user_id = $1`
+
+	QueryUpsertSpendLimitOverride = `
+		INSERT INTO spend_limit_overrides (user_id, max_per_transaction, daily_cap, weekly_cap, max_spends_per_minute, reason, updated_by) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		ON CONFLICT (user_id) DO UPDATE 
+		SET max_per_transaction = EXCLUDED.max_per_transaction, daily_cap = EXCLUDED.daily_cap, weekly_cap = EXCLUDED.weekly_cap, 
+			max_spends_per_minute = EXCLUDED.max_spends_per_minute, reason = EXCLUDED.reason, updated_by = EXCLUDED.updated_by, 
+			updated_at = CURRENT_TIMESTAMP 
+		RETURNING user_id, max_per_transaction, daily_cap, weekly_cap, max_spends_per_minute, reason, updated_by, updated_at`
+
+	QueryDeleteSpendLimitOverride = `
+		DELETE FROM spend_limit_overrides 
+		WHERE user_id = $1`
+
+	// Expired tokens are debits but not spends
+	QueryGetSpendUsage = `
+		SELECT 
+			COALESCE(SUM(-platform_amount) FILTER (WHERE created_at >= $2), 0), 
+			COALESCE(SUM(-platform_amount), 0), 
+			COUNT(*) FILTER (WHERE created_at >= $4) 
+		FROM wallet_logs 
+		WHERE user_id = $1 AND currency = $5 AND platform_amount < 0 AND source NOT IN ('expiry', 'reverse_exchange') AND created_at >= $3`
+
+	// Risk review queries
+	QueryCreateRiskReview = `
+		INSERT INTO risk_reviews (operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8) 
+		RETURNING id, operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload, status, reviewed_by, reviewed_at, review_note, result_error, created_at`
+
+	QueryGetRiskReview = `
+		SELECT id, operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload, status, reviewed_by, reviewed_at, review_note, result_error, created_at 
+		FROM risk_reviews 
+		WHERE id = $1`
+
+	QueryListRiskReviews = `
+		SELECT id, operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload, status, reviewed_by, reviewed_at, review_note, result_error, created_at 
+		FROM risk_reviews 
+		WHERE status = $1 
+		ORDER BY created_at, id 
+		LIMIT $2 OFFSET $3`
+
+	QueryDecideRiskReview = `
+		UPDATE risk_reviews 
+		SET status = $2, reviewed_by = $3, reviewed_at = CURRENT_TIMESTAMP, review_note = $4 
+		WHERE id = $1 AND status = 'pending' 
+		RETURNING id, operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload, status, reviewed_by, reviewed_at, review_note, result_error, created_at`
+
+	QueryFailRiskReview = `
+		UPDATE risk_reviews 
+		SET status = 'failed', result_error = $2 
+		WHERE id = $1 
+		RETURNING id, operation, user_id, principal_id, principal_role, amount, platform_amount, matched_rules, payload, status, reviewed_by, reviewed_at, review_note, result_error, created_at`
+
+	// Idempotency key queries
+	QueryClaimIdempotencyKey = `
+		INSERT INTO idempotency_keys (key, user_id, operation, currency, amount) 
+		VALUES ($1, $2, $3, $4, $5) 
+		ON CONFLICT (key) DO NOTHING 
+		RETURNING key`
+
+	QueryGetIdempotencyKey = `
+		SELECT key, user_id, operation, currency, amount, new_balance, created_at 
+		FROM idempotency_keys 
+		WHERE key = $1`
+
+	QueryCompleteIdempotencyKey = `
+		UPDATE idempotency_keys 
+		SET new_balance = $2 
+		WHERE key = $1`
+
+	QueryReleaseIdempotencyKey = `
+		DELETE FROM idempotency_keys 
+		WHERE key = $1`
+
+	// Bulk job queries
+	QueryCreateBulkJob = `
+		INSERT INTO bulk_jobs (total_items, request, requested_by) 
+		VALUES ($1, $2, $3) 
+		RETURNING id, status, total_items, succeeded, failed, duplicates, request, results, error, requested_by, created_at, started_at, finished_at`
+
+	QueryGetBulkJob = `
+		SELECT id, status, total_items, succeeded, failed, duplicates, request, results, error, requested_by, created_at, started_at, finished_at 
+		FROM bulk_jobs 
+		WHERE id = $1`
+
+	QueryStartBulkJob = `
+		UPDATE bulk_jobs 
+		SET status = 'running', started_at = CURRENT_TIMESTAMP 
+		WHERE id = $1`
+
+	QueryFinishBulkJob = `
+		UPDATE bulk_jobs 
+		SET status = $2, succeeded = $3, failed = $4, duplicates = $5, results = $6, error = $7, finished_at = CURRENT_TIMESTAMP 
+		WHERE id = $1 
+		RETURNING id, status, total_items, succeeded, failed, duplicates, request, results, error, requested_by, created_at, started_at, finished_at`
+
+	// Job queries
+	QueryEnqueueJob = `
+		INSERT INTO jobs (kind, payload, max_attempts, run_at, created_by) 
+		VALUES ($1, $2, $3, $4, $5) 
+		RETURNING id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at`
+
+	QueryGetJob = `
+		SELECT id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at 
+		FROM jobs 
+		WHERE id = $1`
+
+	QueryListJobs = `
+		SELECT id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at 
+		FROM jobs 
+		WHERE ($1::text = '' OR kind = $1) AND ($2::text = '' OR status = $2) 
+		ORDER BY created_at DESC, id DESC 
+		LIMIT $3 OFFSET $4`
+
+	// QueryLeaseJob takes the oldest due job of the given kinds, either queued or running with an expired
+	// lease. SKIP LOCKED lets concurrent workers lease different jobs without waiting on each other.
+	QueryLeaseJob = `
+		UPDATE jobs 
+		SET status = 'running', attempts = attempts + 1, locked_by = $2, 
+			locked_until = CURRENT_TIMESTAMP + $3::float8 * INTERVAL '1 millisecond', heartbeat_at = CURRENT_TIMESTAMP, 
+			started_at = COALESCE(started_at, CURRENT_TIMESTAMP) 
+		WHERE id = ( 
+			SELECT id 
+			FROM jobs 
+			WHERE kind = ANY($1) 
+				AND ((status = 'queued' AND run_at <= CURRENT_TIMESTAMP) OR (status = 'running' AND locked_until < CURRENT_TIMESTAMP)) 
+			ORDER BY run_at, id 
+			LIMIT 1 
+			FOR UPDATE SKIP LOCKED 
+		) 
+		RETURNING id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at`
+
+	QueryHeartbeatJob = `
+		UPDATE jobs 
+		SET locked_until = CURRENT_TIMESTAMP + $3::float8 * INTERVAL '1 millisecond', heartbeat_at = CURRENT_TIMESTAMP 
+		WHERE id = $1 AND locked_by = $2 AND status = 'running' 
+		RETURNING cancel_requested`
+
+	QueryFinishJob = `
+		UPDATE jobs 
+		SET status = $3, result = $4, error = $5, locked_by = NULL, locked_until = NULL, finished_at = CURRENT_TIMESTAMP 
+		WHERE id = $1 AND locked_by = $2 AND status = 'running' 
+		RETURNING id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at`
+
+	QueryRetryJob = `
+		UPDATE jobs 
+		SET status = 'queued', run_at = $3, error = $4, locked_by = NULL, locked_until = NULL 
+		WHERE id = $1 AND locked_by = $2 AND status = 'running' 
+		RETURNING id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at`
+
+	QueryReleaseJob = `
+		UPDATE jobs 
+		SET status = 'queued', attempts = attempts - 1, locked_by = NULL, locked_until = NULL 
+		WHERE id = $1 AND locked_by = $2 AND status = 'running'`
+
+	// QueryCancelJob cancels a queued job right away and asks the worker of a running job to stop it
+	QueryCancelJob = `
+		UPDATE jobs 
+		SET status = CASE WHEN status = 'queued' THEN 'cancelled' ELSE status END, 
+			finished_at = CASE WHEN status = 'queued' THEN CURRENT_TIMESTAMP ELSE finished_at END, 
+			cancel_requested = TRUE 
+		WHERE id = $1 AND status IN ('queued', 'running') 
+		RETURNING id, kind, status, payload, result, error, attempts, max_attempts, run_at, locked_by, locked_until, heartbeat_at, cancel_requested, created_by, created_at, started_at, finished_at`
+
+	// Statement queries
+	QueryGetWalletStatement = `
+		SELECT id, wallet_id, user_id, currency, period, period_start, period_end, opening_balance, closing_balance, total_credits, total_debits, entry_count, entries, generated_at 
+		FROM wallet_statements 
+		WHERE wallet_id = $1 AND period = $2`
+
+	// QueryCreateWalletStatement stores a statement unless one was already stored for its wallet and period
+	QueryCreateWalletStatement = `
+		INSERT INTO wallet_statements (wallet_id, user_id, currency, period, period_start, period_end, opening_balance, closing_balance, total_credits, total_debits, entry_count, entries) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12) 
+		ON CONFLICT (wallet_id, period) DO NOTHING 
+		RETURNING id, wallet_id, user_id, currency, period, period_start, period_end, opening_balance, closing_balance, total_credits, total_debits, entry_count, entries, generated_at`
+
+	// QueryGetWalletBalanceBefore adds the logs created before $2 to the wallet's archived balance and returns
+	// the time of its newest archived log; the balance is only right when $2 is after that time
+	QueryGetWalletBalanceBefore = `
+		SELECT COALESCE(a.balance, 0) + COALESCE(( 
+			SELECT SUM(l.platform_amount) 
+			FROM wallet_logs l 
+			WHERE l.wallet_id = w.id AND l.created_at < $2 
+		), 0), a.last_log_at 
+		FROM (SELECT $1::int AS id) w 
+		LEFT JOIN wallet_log_archive_balances a ON a.wallet_id = w.id`
+
+	QueryGetWalletLogsInRange = `
+		SELECT id, wallet_id, user_id, currency, game_id, token_type, amount, platform_amount, source, reference_id, created_at 
+		FROM wallet_logs 
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3 
+		ORDER BY created_at, id`
+
+	// Balance snapshot queries
+
+	// QueryCreateBalanceSnapshots snapshots the next batch of wallets created up to $1 and returns the number
+	// of snapshots taken and the last wallet ID of the batch. Each balance continues from the wallet's latest
+	// earlier snapshot, or from its archived balance when logs after that snapshot were archived; wallets
+	// already snapshotted at $1 are skipped.
+	QueryCreateBalanceSnapshots = `
+		WITH batch AS (
+			SELECT id, user_id, currency 
+			FROM wallets 
+			WHERE id > $2 AND created_at <= $1 
+			ORDER BY id 
+			LIMIT $3
+		), inserted AS (
+			INSERT INTO wallet_balance_snapshots (wallet_id, user_id, currency, balance, taken_at) 
+			SELECT b.id, b.user_id, b.currency, 
+				COALESCE(a.balance, prev.balance, 0) + COALESCE((
+					SELECT SUM(l.platform_amount) 
+					FROM wallet_logs l 
+					WHERE l.wallet_id = b.id AND l.created_at <= $1 
+						AND (a.wallet_id IS NOT NULL OR prev.taken_at IS NULL OR l.created_at > prev.taken_at)
+				), 0), 
+				$1 
+			FROM batch b 
+			LEFT JOIN LATERAL (
+				SELECT s.balance, s.taken_at 
+				FROM wallet_balance_snapshots s 
+				WHERE s.wallet_id = b.id AND s.taken_at < $1 
+				ORDER BY s.taken_at DESC 
+				LIMIT 1
+			) prev ON TRUE 
+			LEFT JOIN wallet_log_archive_balances a 
+				ON a.wallet_id = b.id AND (prev.taken_at IS NULL OR prev.taken_at < a.last_log_at) 
+			ON CONFLICT (wallet_id, taken_at) DO NOTHING 
+			RETURNING wallet_id
+		)
+		SELECT (SELECT COUNT(*) FROM inserted), (SELECT MAX(id) FROM batch)`
+
+	// QueryGetBalanceAt adds the logs created up to $2 after the latest snapshot taken up to $2 to its balance.
+	// When logs after that snapshot were archived, it starts from the archived balance instead and returns
+	// the time of the newest archived log; the balance is only right when $2 is not before that time.
+	QueryGetBalanceAt = `
+		WITH snapshot AS (
+			SELECT balance, taken_at 
+			FROM wallet_balance_snapshots 
+			WHERE wallet_id = $1 AND taken_at <= $2 
+			ORDER BY taken_at DESC 
+			LIMIT 1
+		), archive AS (
+			SELECT balance, last_log_at 
+			FROM wallet_log_archive_balances 
+			WHERE wallet_id = $1
+		), base AS (
+			SELECT balance, taken_at, FALSE AS archived 
+			FROM snapshot 
+			WHERE NOT EXISTS (SELECT 1 FROM archive WHERE archive.last_log_at > snapshot.taken_at) 
+			UNION ALL 
+			SELECT balance, last_log_at, TRUE 
+			FROM archive 
+			WHERE NOT EXISTS (SELECT 1 FROM snapshot WHERE snapshot.taken_at >= archive.last_log_at)
+		), logs AS (
+			SELECT COALESCE(SUM(platform_amount), 0) AS amount, COUNT(*) AS count 
+			FROM wallet_logs 
+			WHERE wallet_id = $1 AND created_at <= $2 
+				AND created_at > COALESCE((SELECT taken_at FROM base), '-infinity'::timestamp)
+		)
+		SELECT COALESCE((SELECT balance FROM base), 0) + logs.amount, (SELECT taken_at FROM base WHERE NOT archived), 
+			logs.count, (SELECT last_log_at FROM archive) 
+		FROM logs`
+
+	// Report queries
+	QueryGetRollupStateForUpdate = `
+		SELECT last_xact_id, last_log_id, last_log_at, updated_at 
+		FROM report_rollup_state 
+		WHERE name = 'wallet_logs' 
+		FOR UPDATE`
+
+	QueryGetRollupState = `
+		SELECT last_xact_id, last_log_id, last_log_at, updated_at 
+		FROM report_rollup_state 
+		WHERE name = 'wallet_logs'`
+
+	// QueryBackfillWalletLogXactIDs gives transaction ID 0 to up to $2 logs after log $1 written before
+	// transaction IDs were recorded, so they are rolled up ahead of every later log
+	QueryBackfillWalletLogXactIDs = `
+		UPDATE wallet_logs 
+		SET xact_id = '0' 
+		WHERE id IN (
+			SELECT id 
+			FROM wallet_logs 
+			WHERE xact_id IS NULL AND id > $1 
+			ORDER BY id 
+			LIMIT $2
+		)`
+
+	// QueryRollUpWalletLogs adds the next batch of logs after transaction $1 and log $2 to the daily rollups
+	// and returns the number of logs, the transaction and ID of the last one and the latest creation time.
+	// Only the logs of transactions older than every running one are read, as logs commit out of ID order and
+	// later transactions may still add logs before them. The batch ends before the first log created at or
+	// after $3, so a log is never skipped in favor of a later one.
+	QueryRollUpWalletLogs = `
+		WITH horizon AS (
+			SELECT xact_id, id 
+			FROM wallet_logs 
+			WHERE (xact_id, id) > ($1::xid8, $2::bigint) AND xact_id < pg_snapshot_xmin(pg_current_snapshot()) 
+				AND created_at >= $3 
+			ORDER BY xact_id, id 
+			LIMIT 1
+		), batch AS (
+			SELECT xact_id, id, wallet_id, currency, game_id, token_type, amount, platform_amount, source, created_at 
+			FROM wallet_logs 
+			WHERE (xact_id, id) > ($1::xid8, $2::bigint) AND xact_id < pg_snapshot_xmin(pg_current_snapshot()) 
+				AND ((xact_id, id) < (SELECT xact_id, id FROM horizon) OR NOT EXISTS (SELECT 1 FROM horizon)) 
+			ORDER BY xact_id, id 
+			LIMIT $4
+		), activity AS (
+			INSERT INTO report_daily_activity (day, currency, source, game_id, token_type, log_count, amount, platform_credits, platform_debits) 
+			SELECT created_at::date, currency, source, COALESCE(game_id, ''), COALESCE(token_type, ''), COUNT(*), SUM(amount), 
+				SUM(GREATEST(platform_amount, 0)), SUM(GREATEST(-platform_amount, 0)) 
+			FROM batch 
+			GROUP BY 1, 2, 3, 4, 5 
+			ON CONFLICT (day, currency, source, game_id, token_type) DO UPDATE 
+			SET log_count = report_daily_activity.log_count + EXCLUDED.log_count, 
+				amount = report_daily_activity.amount + EXCLUDED.amount, 
+				platform_credits = report_daily_activity.platform_credits + EXCLUDED.platform_credits, 
+				platform_debits = report_daily_activity.platform_debits + EXCLUDED.platform_debits
+		), active AS (
+			INSERT INTO report_daily_active_wallets (day, currency, wallet_id) 
+			SELECT DISTINCT created_at::date, currency, wallet_id 
+			FROM batch 
+			ON CONFLICT DO NOTHING
+		), last AS (
+			SELECT xact_id, id 
+			FROM batch 
+			ORDER BY xact_id DESC, id DESC 
+			LIMIT 1
+		)
+		SELECT (SELECT COUNT(*) FROM batch), (SELECT xact_id FROM last), (SELECT id FROM last), 
+			(SELECT MAX(created_at) FROM batch)`
+
+	// QueryUpdateRollupState advances the rollup state; the latest creation time rolled up never goes back
+	QueryUpdateRollupState = `
+		UPDATE report_rollup_state 
+		SET last_xact_id = $1, last_log_id = $2, last_log_at = GREATEST(last_log_at, $3), 
+			updated_at = CURRENT_TIMESTAMP 
+		WHERE name = 'wallet_logs' 
+		RETURNING last_log_at`
+
+	// QueryCountWalletLogsNotRolledUp counts the logs created from $1 to $2 that the rollups do not include
+	// yet; logs without a transaction ID were written before it was recorded and are rolled up when they
+	// come after the last log rolled up while the job has not gone past transaction ID 0
+	QueryCountWalletLogsNotRolledUp = `
+		SELECT COUNT(*) 
+		FROM wallet_logs l 
+		JOIN report_rollup_state s ON s.name = 'wallet_logs' 
+		WHERE l.created_at >= $1 AND l.created_at < $2 
+			AND ((l.xact_id IS NOT NULL AND (l.xact_id, l.id) > (s.last_xact_id, s.last_log_id)) 
+				OR (l.xact_id IS NULL AND s.last_xact_id = '0' AND l.id > s.last_log_id))`
+
+	QueryListExchangeVolumes = `
+		SELECT day, game_id, token_type, log_count, amount, platform_credits 
+		FROM report_daily_activity 
+		WHERE source = 'exchange' AND currency = $1 AND day >= $2 AND day < $3 
+			AND ($4::text IS NULL OR game_id = $4) 
+			AND ($5::text IS NULL OR token_type = $5) 
+		ORDER BY day, game_id, token_type`
+
+	// QueryListSpendVolumes sums the debits of every source that is not in $4, largest first
+	QueryListSpendVolumes = `
+		SELECT source, SUM(log_count), SUM(platform_debits) 
+		FROM report_daily_activity 
+		WHERE currency = $1 AND day >= $2 AND day < $3 AND source <> ALL($4) AND platform_debits > 0 
+		GROUP BY source 
+		ORDER BY 3 DESC, source`
+
+	// QueryListTokenSupply sums the credits and debits per day; the supply includes all days before $2
+	QueryListTokenSupply = `
+		SELECT day, credits, debits, supply 
+		FROM (
+			SELECT day, SUM(platform_credits) AS credits, SUM(platform_debits) AS debits, 
+				SUM(SUM(platform_credits) - SUM(platform_debits)) OVER (ORDER BY day) AS supply 
+			FROM report_daily_activity 
+			WHERE currency = $1 AND day < $3 
+			GROUP BY day
+		) daily 
+		WHERE day >= $2 
+		ORDER BY day`
+
+	QueryListActiveWalletCounts = `
+		SELECT day, COUNT(*) 
+		FROM report_daily_active_wallets 
+		WHERE currency = $1 AND day >= $2 AND day < $3 
+		GROUP BY day 
+		ORDER BY day`
+
+	QueryListTopWallets = `
+		SELECT id, user_id, currency, balance, status, status_reason, status_changed_at, created_at 
+		FROM wallets 
+		WHERE currency = $1 
+		ORDER BY balance DESC, id 
+		LIMIT $2`
+
+	// Audit log queries
+
+	// QueryLockAuditChain serializes appends to the audit log until the end of the transaction, so every
+	// entry is chained to the one committed before it
+	QueryLockAuditChain = `
+		SELECT pg_advisory_xact_lock(hashtext('admin_audit_log'))`
+
+	QueryGetLastAuditHash = `
+		SELECT hash 
+		FROM admin_audit_log 
+		ORDER BY id DESC 
+		LIMIT 1`
+
+	QueryInsertAuditEntry = `
+		INSERT INTO admin_audit_log (principal_id, principal_role, action, method, path, target_user_id, payload_hash, request_id, status_code, outcome, created_at, prev_hash, hash) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13) 
+		RETURNING id`
+
+	QueryListAuditEntries = `
+		SELECT id, principal_id, principal_role, action, method, path, target_user_id, payload_hash, request_id, status_code, outcome, created_at, prev_hash, hash 
+		FROM admin_audit_log 
+		WHERE ($1::int IS NULL OR principal_id = $1) AND ($2::int IS NULL OR target_user_id = $2) 
+			AND ($3::text = '' OR action = $3) AND ($4::text = '' OR outcome = $4) 
+			AND ($5::timestamp IS NULL OR created_at >= $5) AND ($6::timestamp IS NULL OR created_at < $6) 
+		ORDER BY id DESC 
+		LIMIT $7 OFFSET $8`
+
+	QueryListAuditChain = `
+		SELECT id, principal_id, principal_role, action, method, path, target_user_id, payload_hash, request_id, status_code, outcome, created_at, prev_hash, hash 
+		FROM admin_audit_log 
+		WHERE id > $1 
+		ORDER BY id 
+		LIMIT $2`
+
+	// Health check queries
+	QueryGetMigrationState = `
+		SELECT version, dirty 
+		FROM schema_migrations 
+		LIMIT 1`
+
+	QueryGetJobBacklog = `
+		SELECT COUNT(*), MIN(run_at) 
+		FROM jobs 
+		WHERE status = 'queued' AND run_at <= CURRENT_TIMESTAMP`
+
+	// Replica queries
+	// The lag is zero when the replica has replayed everything it received, so an idle primary does not
+	// make its replicas look stale
+	QueryGetReplicationLag = `
+		SELECT CASE 
+			WHEN NOT pg_is_in_recovery() OR pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0 
+			ELSE COALESCE(EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - pg_last_xact_replay_timestamp()), 0) 
+		END`
+
+	// Partition queries
+
+	// QueryLockWalletLogPartitions serializes the maintenance of wallet_logs partitions across instances until
+	// the end of the transaction
+	QueryLockWalletLogPartitions = `
+		SELECT pg_advisory_xact_lock(hashtext('wallet_logs_partitions'))`
+
+	// QueryListWalletLogPartitions lists the monthly partitions of wallet_logs with their bounds, oldest first
+	QueryListWalletLogPartitions = `
+		SELECT name, range_start, range_end 
+		FROM ( 
+			SELECT c.relname AS name, 
+				substring(pg_get_expr(c.relpartbound, c.oid) FROM 'FROM \(''([^'']+)''\)')::timestamp AS range_start, 
+				substring(pg_get_expr(c.relpartbound, c.oid) FROM 'TO \(''([^'']+)''\)')::timestamp AS range_end 
+			FROM pg_inherits i 
+			JOIN pg_class c ON c.oid = i.inhrelid 
+			WHERE i.inhparent = 'wallet_logs'::regclass 
+		) partitions 
+		WHERE range_start IS NOT NULL 
+		ORDER BY range_start`
+
+	QueryWalletLogPartitionExists = `
+		SELECT to_regclass($1) IS NOT NULL`
+
+	// The DDL queries below take the quoted partition name, and the attach query its bounds, as format arguments
+
+	QueryCreateWalletLogPartitionTable = `
+		CREATE TABLE %s (LIKE wallet_logs INCLUDING DEFAULTS INCLUDING CONSTRAINTS)`
+
+	// QueryMoveDefaultWalletLogs moves the logs of a new partition's range out of the default partition, which
+	// may only be attached once the default partition holds none of them
+	QueryMoveDefaultWalletLogs = `
+		WITH moved AS ( 
+			DELETE FROM wallet_logs_default 
+			WHERE created_at >= $1 AND created_at < $2 
+			RETURNING * 
+		) 
+		INSERT INTO %s 
+		SELECT * FROM moved`
+
+	QueryAttachWalletLogPartition = `
+		ALTER TABLE wallet_logs ATTACH PARTITION %s FOR VALUES FROM ('%s') TO ('%s')`
+
+	QueryDetachWalletLogPartition = `
+		ALTER TABLE wallet_logs DETACH PARTITION %s`
+
+	QueryMoveWalletLogPartitionToArchive = `
+		ALTER TABLE %s SET SCHEMA wallet_logs_archive`
+
+	QueryDropWalletLogPartition = `
+		DROP TABLE %s`
+
+	// QueryListWalletsMissingStatement lists the wallets with logs from $1 to $2 but no statement for period $3
+	QueryListWalletsMissingStatement = `
+		SELECT w.id, w.user_id, w.currency, w.balance, w.status, w.status_reason, w.status_changed_at, w.created_at 
+		FROM wallets w 
+		WHERE w.id > $4 
+			AND EXISTS ( 
+				SELECT 1 
+				FROM wallet_logs l 
+				WHERE l.wallet_id = w.id AND l.created_at >= $1 AND l.created_at < $2 
+			) 
+			AND NOT EXISTS ( 
+				SELECT 1 
+				FROM wallet_statements s 
+				WHERE s.wallet_id = w.id AND s.period = $3 
+			) 
+		ORDER BY w.id 
+		LIMIT $5`
+
+	// QueryArchiveWalletLogBalances adds the logs from $1 to $2 to the archived balances of their wallets
+	QueryArchiveWalletLogBalances = `
+		INSERT INTO wallet_log_archive_balances (wallet_id, user_id, currency, balance, log_count, last_log_at) 
+		SELECT wallet_id, user_id, currency, SUM(platform_amount), COUNT(*), MAX(created_at) 
+		FROM wallet_logs 
+		WHERE created_at >= $1 AND created_at < $2 
+		GROUP BY wallet_id, user_id, currency 
+		ON CONFLICT (wallet_id) DO UPDATE 
+		SET balance = wallet_log_archive_balances.balance + EXCLUDED.balance, 
+			log_count = wallet_log_archive_balances.log_count + EXCLUDED.log_count, 
+			last_log_at = GREATEST(wallet_log_archive_balances.last_log_at, EXCLUDED.last_log_at), 
+			updated_at = CURRENT_TIMESTAMP`
+
+	QueryCreateWalletLogArchive = `
+		INSERT INTO wallet_log_archives (partition_name, range_start, range_end, log_count, wallet_count, mode) 
+		SELECT $1::varchar, $2::timestamp, $3::timestamp, COUNT(*), COUNT(DISTINCT wallet_id), $4::varchar 
+		FROM wallet_logs 
+		WHERE created_at >= $2 AND created_at < $3 
+		RETURNING id, partition_name, range_start, range_end, log_count, wallet_count, mode, archived_at`
+
+	// Feature flag queries
+	QueryListFeatureFlagOverrides = `
+		SELECT name, enabled, percentage, roles, game_ids, reason, updated_by, updated_at 
+		FROM feature_flag_overrides 
+		ORDER BY name`
+
+	QueryUpsertFeatureFlagOverride = `
+		INSERT INTO feature_flag_overrides (name, enabled, percentage, roles, game_ids, reason, updated_by) 
+		VALUES ($1, $2, $3, $4, $5, $6, $7) 
+		ON CONFLICT (name) DO UPDATE 
+		SET enabled = EXCLUDED.enabled, percentage = EXCLUDED.percentage, roles = EXCLUDED.roles, 
+			game_ids = EXCLUDED.game_ids, reason = EXCLUDED.reason, updated_by = EXCLUDED.updated_by, 
+			updated_at = CURRENT_TIMESTAMP 
+		RETURNING name, enabled, percentage, roles, game_ids, reason, updated_by, updated_at`
+
+	QueryDeleteFeatureFlagOverride = `
+		DELETE FROM feature_flag_overrides 
+		WHERE name = $1`
+
+	// Rate limit queries
+	// The bucket is refilled for the time since its last update, capped at the limit, and gives a token
+	// when it holds a whole one; granted records whether it did
+	QueryTakeRateLimitToken = `
+		INSERT INTO rate_limit_buckets (key, tokens, granted, updated_at) 
+		VALUES ($1, $2::float8 - 1, TRUE, CURRENT_TIMESTAMP) 
+		ON CONFLICT (key) DO UPDATE 
+		SET granted = LEAST($2::float8, rate_limit_buckets.tokens + 
+				EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - rate_limit_buckets.updated_at)::float8 * $3::float8) >= 1, 
+			tokens = LEAST($2::float8, rate_limit_buckets.tokens + 
+				EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - rate_limit_buckets.updated_at)::float8 * $3::float8) - 
+				CASE WHEN LEAST($2::float8, rate_limit_buckets.tokens + 
+					EXTRACT(EPOCH FROM CURRENT_TIMESTAMP - rate_limit_buckets.updated_at)::float8 * $3::float8) >= 1 
+				THEN 1 ELSE 0 END, 
+			updated_at = CURRENT_TIMESTAMP 
+		RETURNING tokens, granted`
+
+	QueryPruneRateLimitBuckets = `
+		DELETE FROM rate_limit_buckets 
+		WHERE updated_at < CURRENT_TIMESTAMP - $1::float8 * INTERVAL '1 second'`
 )
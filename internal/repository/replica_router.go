@@ -0,0 +1,278 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/playconomy/wallet-service/database"
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Targets and reasons of routed reads, as recorded in metrics
+const (
+	readTargetPrimary = "primary"
+	readTargetReplica = "replica"
+
+	readReasonReplica       = "replica"
+	readReasonNoReplicas    = "no_replicas"
+	readReasonReadYourWrite = "read_your_writes"
+	readReasonUnhealthy     = "replicas_unhealthy"
+)
+
+// replicaState is a read replica with the outcome of its last check. Replicas start unhealthy, so
+// reads stay on the primary until a replica passes its first check.
+type replicaState struct {
+	name string
+	db   *sql.DB
+
+	mu      sync.RWMutex
+	healthy bool
+	lag     time.Duration
+}
+
+func (s *replicaState) isHealthy() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.healthy
+}
+
+// setStatus records the outcome of a check and reports whether the replica's health changed
+func (s *replicaState) setStatus(healthy bool, lag time.Duration) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	changed := s.healthy != healthy
+	s.healthy = healthy
+	s.lag = lag
+	return changed
+}
+
+// ReplicaRouter picks the connection pool of non-transactional reads. Reads go to a healthy replica in
+// turn; a replica is healthy while it answers its checks with a replication lag of at most the
+// configured maximum. Reads of a user whose wallet was written on this instance within the
+// read-your-writes window go to the primary, and so do all reads when no replica is healthy.
+type ReplicaRouter struct {
+	primary  *sql.DB
+	replicas []*replicaState
+	next     atomic.Uint64
+
+	maxLag               time.Duration
+	readYourWritesWindow time.Duration
+	checkInterval        time.Duration
+
+	writesMu   sync.Mutex
+	lastWrites map[int]time.Time
+	now        func() time.Time
+
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewReplicaRouter creates the router of reads between the primary and the read replicas, checking the
+// replicas in the background while the application runs
+func NewReplicaRouter(lc fx.Lifecycle, db *sql.DB, replicas []*database.Replica, cfg *config.Config,
+	obs *observability.Observability) *ReplicaRouter {
+
+	router := newReplicaRouter(db, replicas, cfg.Replicas)
+	router.logger = obs.Logger.Logger.With(zap.String("component", "replica_router"))
+	router.metrics = obs.Metrics
+
+	if len(router.replicas) == 0 {
+		return router
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			router.logger.Info("Starting read replica checks",
+				zap.Int("replicas", len(router.replicas)),
+				zap.Duration("max_lag", router.maxLag),
+				zap.Duration("interval", router.checkInterval))
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			router.cancel = cancel
+			router.done = make(chan struct{})
+			go router.loop(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			router.cancel()
+
+			select {
+			case <-router.done:
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+
+			for _, replica := range router.replicas {
+				replica.db.Close()
+			}
+			return nil
+		},
+	})
+
+	return router
+}
+
+func newReplicaRouter(db *sql.DB, replicas []*database.Replica, cfg config.ReplicaConfig) *ReplicaRouter {
+	router := &ReplicaRouter{
+		primary:              db,
+		replicas:             make([]*replicaState, 0, len(replicas)),
+		maxLag:               cfg.MaxLag,
+		readYourWritesWindow: cfg.ReadYourWritesWindow,
+		checkInterval:        cfg.CheckInterval,
+		lastWrites:           make(map[int]time.Time),
+		now:                  time.Now,
+	}
+	for _, replica := range replicas {
+		router.replicas = append(router.replicas, &replicaState{name: replica.Name, db: replica.DB})
+	}
+	return router
+}
+
+// reader returns the connection pool for a read of the user's data
+func (r *ReplicaRouter) reader(userID int) *sql.DB {
+	db, reason := r.route(userID)
+
+	target := readTargetReplica
+	if db == r.primary {
+		target = readTargetPrimary
+	}
+	r.metrics.RecordDBRead(target, reason)
+
+	return db
+}
+
+// route picks the connection pool for a read of the user's data and the reason it was picked
+func (r *ReplicaRouter) route(userID int) (*sql.DB, string) {
+	if len(r.replicas) == 0 {
+		return r.primary, readReasonNoReplicas
+	}
+	if r.wroteRecently(userID) {
+		return r.primary, readReasonReadYourWrite
+	}
+
+	count := uint64(len(r.replicas))
+	start := r.next.Add(1)
+	for i := uint64(0); i < count; i++ {
+		replica := r.replicas[(start+i)%count]
+		if replica.isHealthy() {
+			return replica.db, readReasonReplica
+		}
+	}
+
+	return r.primary, readReasonUnhealthy
+}
+
+// markWrite starts the read-your-writes window of a user whose wallet was just written
+func (r *ReplicaRouter) markWrite(userID int) {
+	if len(r.replicas) == 0 || r.readYourWritesWindow <= 0 {
+		return
+	}
+
+	now := r.now()
+	r.writesMu.Lock()
+	defer r.writesMu.Unlock()
+	r.lastWrites[userID] = now
+}
+
+func (r *ReplicaRouter) wroteRecently(userID int) bool {
+	r.writesMu.Lock()
+	defer r.writesMu.Unlock()
+
+	writtenAt, ok := r.lastWrites[userID]
+	return ok && r.now().Sub(writtenAt) < r.readYourWritesWindow
+}
+
+// pruneWrites forgets the writes whose read-your-writes window has passed
+func (r *ReplicaRouter) pruneWrites() {
+	now := r.now()
+	r.writesMu.Lock()
+	defer r.writesMu.Unlock()
+
+	for userID, writtenAt := range r.lastWrites {
+		if now.Sub(writtenAt) >= r.readYourWritesWindow {
+			delete(r.lastWrites, userID)
+		}
+	}
+}
+
+// HealthyReplicas returns the number of replicas serving reads and the number configured
+func (r *ReplicaRouter) HealthyReplicas() (healthy, total int) {
+	for _, replica := range r.replicas {
+		if replica.isHealthy() {
+			healthy++
+		}
+	}
+	return healthy, len(r.replicas)
+}
+
+func (r *ReplicaRouter) loop(ctx context.Context) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(r.checkInterval)
+	defer ticker.Stop()
+
+	for {
+		r.checkReplicas(ctx)
+		r.pruneWrites()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkReplicas measures the replication lag of every replica and updates which ones serve reads
+func (r *ReplicaRouter) checkReplicas(ctx context.Context) {
+	for _, replica := range r.replicas {
+		lag, err := r.replicationLag(ctx, replica)
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil && lag <= r.maxLag
+		r.metrics.SetReplicaStatus(replica.name, healthy, lag.Seconds())
+		if !replica.setStatus(healthy, lag) {
+			continue
+		}
+
+		switch {
+		case healthy:
+			r.logger.Info("Read replica serving reads",
+				zap.String("replica", replica.name),
+				zap.Duration("lag", lag))
+		case err != nil:
+			r.logger.Warn("Read replica unreachable, its reads go elsewhere",
+				zap.String("replica", replica.name),
+				zap.Error(err))
+		default:
+			r.logger.Warn("Read replica lagging, its reads go elsewhere",
+				zap.String("replica", replica.name),
+				zap.Duration("lag", lag),
+				zap.Duration("max_lag", r.maxLag))
+		}
+	}
+}
+
+func (r *ReplicaRouter) replicationLag(ctx context.Context, replica *replicaState) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.checkInterval)
+	defer cancel()
+
+	var seconds float64
+	if err := replica.db.QueryRowContext(ctx, QueryGetReplicationLag).Scan(&seconds); err != nil {
+		return 0, err
+	}
+	return time.Duration(seconds * float64(time.Second)), nil
+}
@@ -0,0 +1,90 @@
+package repository
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/database"
+	"github.com/playconomy/wallet-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestReplicaRouter(replicaCount int) (*ReplicaRouter, *time.Time) {
+	replicas := make([]*database.Replica, replicaCount)
+	for i := range replicas {
+		replicas[i] = &database.Replica{Name: "replica", DB: &sql.DB{}}
+	}
+
+	router := newReplicaRouter(&sql.DB{}, replicas, config.ReplicaConfig{
+		MaxLag:               5 * time.Second,
+		ReadYourWritesWindow: 10 * time.Second,
+		CheckInterval:        5 * time.Second,
+	})
+
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.UTC)
+	router.now = func() time.Time { return now }
+	return router, &now
+}
+
+func TestReplicaRouterRoute(t *testing.T) {
+	router, _ := newTestReplicaRouter(0)
+	db, reason := router.route(1)
+	assert.Same(t, router.primary, db)
+	assert.Equal(t, readReasonNoReplicas, reason)
+
+	// Replicas serve no reads before their first successful check
+	router, _ = newTestReplicaRouter(2)
+	db, reason = router.route(1)
+	assert.Same(t, router.primary, db)
+	assert.Equal(t, readReasonUnhealthy, reason)
+
+	router.replicas[0].setStatus(true, time.Second)
+	router.replicas[1].setStatus(true, 0)
+
+	first, reason := router.route(1)
+	assert.Equal(t, readReasonReplica, reason)
+	second, _ := router.route(1)
+	assert.NotSame(t, first, second, "reads alternate between healthy replicas")
+
+	router.replicas[0].setStatus(false, 30*time.Second)
+	for i := 0; i < 3; i++ {
+		db, _ = router.route(1)
+		assert.Same(t, router.replicas[1].db, db)
+	}
+}
+
+func TestReplicaRouterReadYourWrites(t *testing.T) {
+	router, now := newTestReplicaRouter(1)
+	router.replicas[0].setStatus(true, 0)
+
+	router.markWrite(7)
+
+	db, reason := router.route(7)
+	assert.Same(t, router.primary, db)
+	assert.Equal(t, readReasonReadYourWrite, reason)
+
+	db, _ = router.route(8)
+	assert.Same(t, router.replicas[0].db, db, "other users keep reading from replicas")
+
+	*now = now.Add(10 * time.Second)
+	db, _ = router.route(7)
+	assert.Same(t, router.replicas[0].db, db)
+
+	router.pruneWrites()
+	assert.Empty(t, router.lastWrites)
+}
+
+func TestReplicaRouterHealthyReplicas(t *testing.T) {
+	router, _ := newTestReplicaRouter(3)
+	router.replicas[0].setStatus(true, 0)
+	router.replicas[2].setStatus(true, time.Second)
+
+	healthy, total := router.HealthyReplicas()
+	assert.Equal(t, 2, healthy)
+	assert.Equal(t, 3, total)
+
+	assert.True(t, router.replicas[2].setStatus(false, time.Minute), "losing health is a change")
+	assert.False(t, router.replicas[2].setStatus(false, time.Minute))
+}
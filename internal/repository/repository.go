@@ -3,22 +3,57 @@ package repository
 
 import (
 	"context"
+	"encoding/json"
+	"time"
 
 	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
 )
 
 // WalletRepository defines the interface for wallet data access
 type WalletRepository interface {
 	// Wallet operations
-	GetWalletByUserID(ctx context.Context, userID int) (*model.Wallet, error)
-	GetWalletByUserIDForUpdate(ctx context.Context, userID int, tx Transaction) (*model.Wallet, error)
-	CreateWallet(ctx context.Context, userID int, initialBalance float64, tx Transaction) (*model.Wallet, error)
-	UpdateWalletBalance(ctx context.Context, userID int, newBalance float64, tx Transaction) (*model.Wallet, error)
-	SpendFromWallet(ctx context.Context, userID int, amount float64, tx Transaction) (*model.Wallet, error)
+	GetWalletByUserID(ctx context.Context, userID int, currency string) (*model.Wallet, error)
+	GetWalletsByUserID(ctx context.Context, userID int) ([]*model.Wallet, error)
+	GetWalletByUserIDForUpdate(ctx context.Context, userID int, currency string, tx Transaction) (*model.Wallet, error)
+	GetWalletsByUserIDForUpdate(ctx context.Context, userID int, tx Transaction) ([]*model.Wallet, error)
+	CreateWallet(ctx context.Context, userID int, currency string, initialBalance float64, tx Transaction) (*model.Wallet, error)
+	UpdateWalletBalance(ctx context.Context, userID int, currency string, newBalance float64, tx Transaction) (*model.Wallet, error)
+	SpendFromWallet(ctx context.Context, userID int, currency string, amount float64, tx Transaction) (*model.Wallet, error)
+	UpdateWalletStatus(ctx context.Context, userID int, status, reason string, tx Transaction) (*model.Wallet, error)
+
+	// Balance lot operations; callers must hold the lock on the lot's wallet before changing lots
+	CreateBalanceLot(ctx context.Context, lot *model.BalanceLot, tx Transaction) (*model.BalanceLot, error)
+	GetSpendableBalanceLotsForUpdate(ctx context.Context, walletID int64, now time.Time, tx Transaction) ([]*model.BalanceLot, error)
+	UpdateBalanceLotRemaining(ctx context.Context, lotID int64, remaining float64, tx Transaction) error
+	ExpireBalanceLots(ctx context.Context, walletID int64, now time.Time, tx Transaction) ([]*model.BalanceLot, error)
+	ListExpiringBalanceLots(ctx context.Context, walletID int64, from, to time.Time) ([]*model.BalanceLot, error)
+	ListWalletsWithDueBalanceLots(ctx context.Context, now time.Time, limit int) ([]*model.Wallet, error)
+
+	// Wallet status history operations
+	CreateWalletStatusChange(ctx context.Context, change *model.WalletStatusChange, tx Transaction) (*model.WalletStatusChange, error)
+	GetWalletStatusChanges(ctx context.Context, userID int) ([]*model.WalletStatusChange, error)
+
+	// Spend limit operations
+	GetSpendLimitOverride(ctx context.Context, userID int) (*model.SpendLimitOverride, error)
+	UpsertSpendLimitOverride(ctx context.Context, override *model.SpendLimitOverride) (*model.SpendLimitOverride, error)
+	DeleteSpendLimitOverride(ctx context.Context, userID int) (bool, error)
+	GetSpendUsage(ctx context.Context, userID int, currency string, now time.Time, tx Transaction) (*model.SpendUsage, error)
 
 	// Exchange rate operations
-	GetExchangeRate(ctx context.Context, gameID, tokenType string) (*model.ExchangeRate, error)
+	GetExchangeRate(ctx context.Context, gameID, tokenType, currency string) (*model.ExchangeRate, error)
 	GetExchangeRateByID(ctx context.Context, id int64) (*model.ExchangeRate, error)
+	LockExchangeRate(ctx context.Context, id int64, tx Transaction) error
+	GetExchangeUsage(ctx context.Context, userID int, gameID, tokenType, currency string, now time.Time, tx Transaction) (*model.ExchangeUsage, error)
+	GetReverseExchangeUsage(ctx context.Context, userID int, gameID, tokenType, currency string, now time.Time, tx Transaction) (float64, error)
+
+	// Reverse exchange grant operations
+	CreateReverseExchangeGrant(ctx context.Context, grant *model.ReverseExchangeGrant, tx Transaction) (*model.ReverseExchangeGrant, error)
+	GetReverseExchangeGrant(ctx context.Context, grantID string) (*model.ReverseExchangeGrant, error)
+	RedeemReverseExchangeGrant(ctx context.Context, grantID string, redeemedBy int, now time.Time) (*model.ReverseExchangeGrant, error)
+	ListOpenReverseExchangeGrants(ctx context.Context, userID int, now time.Time) ([]*model.ReverseExchangeGrant, error)
+	ListRefundableReverseExchangeGrants(ctx context.Context, now time.Time, limit int) ([]*model.ReverseExchangeGrant, error)
+	RefundReverseExchangeGrant(ctx context.Context, grantID string, now time.Time, tx Transaction) (*model.ReverseExchangeGrant, error)
 
 	// Log operations
 	CreateWalletLog(ctx context.Context, log *model.WalletLog, tx Transaction) (*model.WalletLog, error)
@@ -28,6 +63,176 @@ type WalletRepository interface {
 	BeginTx(ctx context.Context) (Transaction, error)
 }
 
+// ReconciliationRepository defines the interface for reconciliation data access
+type ReconciliationRepository interface {
+	// Ledger operations
+	ListWalletLedgerBalances(ctx context.Context, afterWalletID int64, limit int) ([]*model.WalletLedgerBalance, error)
+
+	// Run operations
+	TryLockReconciliation(ctx context.Context) (unlock func(), acquired bool, err error)
+	CreateReconciliationRun(ctx context.Context, trigger string) (*model.ReconciliationRun, error)
+	CompleteReconciliationRun(ctx context.Context, run *model.ReconciliationRun) (*model.ReconciliationRun, error)
+	GetReconciliationRun(ctx context.Context, id int64) (*model.ReconciliationRun, error)
+	GetLatestReconciliationRun(ctx context.Context) (*model.ReconciliationRun, error)
+
+	// Mismatch operations
+	CreateReconciliationMismatch(ctx context.Context, mismatch *model.ReconciliationMismatch, tx Transaction) (*model.ReconciliationMismatch, error)
+	ListReconciliationMismatches(ctx context.Context, runID int64) ([]*model.ReconciliationMismatch, error)
+	ResolveReconciliationMismatch(ctx context.Context, id int64, resolvedBy int, note string, tx Transaction) (*model.ReconciliationMismatch, error)
+	CountUnresolvedMismatches(ctx context.Context, walletID int64, tx Transaction) (int, error)
+
+	// Transaction management
+	BeginTx(ctx context.Context) (Transaction, error)
+}
+
+// RiskReviewRepository defines the interface for the manual review queue of risky operations
+type RiskReviewRepository interface {
+	CreateRiskReview(ctx context.Context, review *model.RiskReview) (*model.RiskReview, error)
+	GetRiskReview(ctx context.Context, id int64) (*model.RiskReview, error)
+	ListRiskReviews(ctx context.Context, status string, limit, offset int) ([]*model.RiskReview, error)
+	DecideRiskReview(ctx context.Context, id int64, status string, reviewedBy int, note string) (*model.RiskReview, error)
+	FailRiskReview(ctx context.Context, id int64, resultError string) (*model.RiskReview, error)
+}
+
+// BulkRepository defines the interface for the idempotency keys of bulk items and background bulk jobs
+type BulkRepository interface {
+	// Idempotency key operations; a key is claimed in the transaction that applies its item
+	ClaimIdempotencyKey(ctx context.Context, key *model.IdempotencyKey, tx Transaction) (bool, error)
+	GetIdempotencyKey(ctx context.Context, key string, tx Transaction) (*model.IdempotencyKey, error)
+	CompleteIdempotencyKey(ctx context.Context, key string, newBalance float64, tx Transaction) error
+	ReleaseIdempotencyKey(ctx context.Context, key string, tx Transaction) error
+
+	// Bulk job operations
+	CreateBulkJob(ctx context.Context, job *model.BulkJob) (*model.BulkJob, error)
+	GetBulkJob(ctx context.Context, id int64) (*model.BulkJob, error)
+	StartBulkJob(ctx context.Context, id int64) error
+	FinishBulkJob(ctx context.Context, job *model.BulkJob) (*model.BulkJob, error)
+}
+
+// JobRepository defines the interface for background jobs and their leases.
+// Methods taking a workerID only change a job that worker still holds the lease of.
+type JobRepository interface {
+	EnqueueJob(ctx context.Context, job *model.Job) (*model.Job, error)
+	GetJob(ctx context.Context, id int64) (*model.Job, error)
+	ListJobs(ctx context.Context, filter model.JobFilter) ([]*model.Job, error)
+	CancelJob(ctx context.Context, id int64) (*model.Job, error)
+
+	// Worker operations
+	LeaseJob(ctx context.Context, kinds []string, workerID string, lease time.Duration) (*model.Job, error)
+	HeartbeatJob(ctx context.Context, id int64, workerID string, lease time.Duration) (held bool, cancelRequested bool, err error)
+	FinishJob(ctx context.Context, id int64, workerID string, status string, result json.RawMessage, jobError *string) (*model.Job, error)
+	RetryJob(ctx context.Context, id int64, workerID string, runAt time.Time, jobError string) (*model.Job, error)
+	ReleaseJob(ctx context.Context, id int64, workerID string) error
+}
+
+// ExportRepository defines the interface for exports of wallet data
+type ExportRepository interface {
+	// StreamWalletLogs calls fn for each log matching the filter, oldest first, without loading them all
+	// into memory. All logs are read from one snapshot; fn's error stops the export.
+	StreamWalletLogs(ctx context.Context, filter model.WalletLogFilter, fn func(*model.WalletLog) error) error
+}
+
+// StatementRepository defines the interface for monthly wallet statements
+type StatementRepository interface {
+	GetWalletStatement(ctx context.Context, walletID int64, period string) (*model.WalletStatement, error)
+	CreateWalletStatement(ctx context.Context, statement *model.WalletStatement) (*model.WalletStatement, error)
+	GetStatementLedger(ctx context.Context, walletID int64, from, to time.Time) (*model.StatementLedger, error)
+}
+
+// SnapshotRepository defines the interface for balance snapshots and point-in-time balances
+type SnapshotRepository interface {
+	// CreateBalanceSnapshots snapshots up to limit wallets with an ID above afterWalletID at takenAt. It returns
+	// the number of snapshots taken and the last wallet ID of the batch, which is 0 when no wallets were left.
+	CreateBalanceSnapshots(ctx context.Context, takenAt time.Time, afterWalletID int64, limit int) (int, int64, error)
+	GetBalanceAt(ctx context.Context, walletID int64, at time.Time) (*model.PointInTimeBalance, error)
+}
+
+// ReportRepository defines the interface for the daily rollups behind the reporting API
+type ReportRepository interface {
+	// RollUpWalletLogs adds up to limit logs created before before to the rollups. The returned batch
+	// has no logs once the rollups are up to date.
+	RollUpWalletLogs(ctx context.Context, before time.Time, limit int) (*model.RollupBatch, error)
+	GetRollupState(ctx context.Context) (*model.RollupState, error)
+
+	// Report operations
+	ListExchangeVolumes(ctx context.Context, filter model.ReportFilter) ([]*model.ExchangeVolume, error)
+	ListSpendVolumes(ctx context.Context, filter model.ReportFilter, excludedSources []string) ([]*model.SpendVolume, error)
+	ListTokenSupply(ctx context.Context, filter model.ReportFilter) ([]*model.SupplyPoint, error)
+	ListActiveWalletCounts(ctx context.Context, filter model.ReportFilter) ([]*model.ActiveWalletCount, error)
+	ListTopWallets(ctx context.Context, currency string, limit int) ([]*model.Wallet, error)
+}
+
+// StreamRepository defines the interface for the reads behind wallet event streams
+type StreamRepository interface {
+	// ListWalletLogsBetween lists up to limit logs of a user with an ID above afterID that were committed
+	// after snapshot since and before snapshot until, oldest first
+	ListWalletLogsBetween(ctx context.Context, userID int, since, until string, afterID int64,
+		limit int) ([]*model.WalletLog, error)
+	// GetCurrentSnapshot returns the current database snapshot, which tells the transactions committed so far
+	GetCurrentSnapshot(ctx context.Context) (string, error)
+	// GetWalletBalances reads a user's wallets from the primary database, so the balances include every
+	// committed log a stream was notified of
+	GetWalletBalances(ctx context.Context, userID int) ([]*model.Wallet, error)
+}
+
+// WalletEventListener receives the wallet events announced when wallet logs are committed
+type WalletEventListener interface {
+	// Listen calls fn for every wallet event until ctx is done, and with nil when events may have been lost
+	Listen(ctx context.Context, fn func(*model.WalletEvent)) error
+}
+
+// CacheInvalidationListener receives the changes of wallets and exchange rates announced when they are
+// committed
+type CacheInvalidationListener interface {
+	// Listen calls fn for every change until ctx is done, and with nil when changes may have been lost
+	Listen(ctx context.Context, fn func(*model.CacheInvalidation)) error
+}
+
+// AuditRepository defines the interface for the hash-chained audit log of privileged calls
+type AuditRepository interface {
+	AppendAuditEntry(ctx context.Context, entry *model.AuditEntry, seal func(*model.AuditEntry) string) (*model.AuditEntry, error)
+	ListAuditEntries(ctx context.Context, filter model.AuditFilter) ([]*model.AuditEntry, error)
+	ListAuditChain(ctx context.Context, afterID int64, limit int) ([]*model.AuditEntry, error)
+}
+
+// HealthRepository defines the interface for the database checks behind the readiness probe
+type HealthRepository interface {
+	Ping(ctx context.Context) error
+	// GetMigrationState returns nil when the migration tool has not recorded a version
+	GetMigrationState(ctx context.Context) (*model.MigrationState, error)
+	GetJobBacklog(ctx context.Context) (*model.JobBacklog, error)
+}
+
+// PartitionRepository defines the interface for the monthly partitions of wallet_logs and their archival
+type PartitionRepository interface {
+	ListWalletLogPartitions(ctx context.Context) ([]*model.WalletLogPartition, error)
+	// CreateWalletLogPartition creates and attaches a partition, moving its logs out of the default
+	// partition. It returns false when the partition already exists.
+	CreateWalletLogPartition(ctx context.Context, partition *model.WalletLogPartition) (bool, error)
+	CountWalletLogsNotRolledUp(ctx context.Context, partition *model.WalletLogPartition) (int64, error)
+	ListWalletsMissingStatement(ctx context.Context, partition *model.WalletLogPartition, period string,
+		afterWalletID int64, limit int) ([]*model.Wallet, error)
+	// ArchiveWalletLogPartition adds the logs of a partition to the archived balances of their wallets and
+	// detaches the partition, keeping it in the wallet_logs_archive schema or dropping it depending on mode
+	ArchiveWalletLogPartition(ctx context.Context, partition *model.WalletLogPartition, mode string) (*model.WalletLogArchive, error)
+}
+
+// FeatureFlagRepository defines the interface for the feature flag overrides set through the admin API
+type FeatureFlagRepository interface {
+	ListFeatureFlagOverrides(ctx context.Context) ([]*model.FeatureFlagOverride, error)
+	UpsertFeatureFlagOverride(ctx context.Context, override *model.FeatureFlagOverride) (*model.FeatureFlagOverride, error)
+	// DeleteFeatureFlagOverride removes the override of a flag and reports whether one existed
+	DeleteFeatureFlagOverride(ctx context.Context, name string) (bool, error)
+}
+
+// RateLimitRepository defines the interface for the rate limit buckets shared by the instances
+type RateLimitRepository interface {
+	// TakeRateLimitToken takes a token from the bucket of key for limit, refilling the bucket first
+	TakeRateLimitToken(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error)
+	// PruneRateLimitBuckets removes the buckets unused for longer than idle and returns how many were removed
+	PruneRateLimitBuckets(ctx context.Context, idle time.Duration) (int, error)
+}
+
 // Transaction represents a database transaction
 type Transaction interface {
 	Commit() error
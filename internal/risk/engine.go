@@ -0,0 +1,196 @@
+// Package risk provides the built-in rule engine used to score mutating wallet operations
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+)
+
+// Duration is a time.Duration read from a string such as "5m" in the rules file
+type Duration time.Duration
+
+// UnmarshalJSON parses a duration string
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var value string
+	if err := json.Unmarshal(data, &value); err != nil {
+		return fmt.Errorf("duration must be a string: %w", err)
+	}
+
+	parsed, err := time.ParseDuration(value)
+	if err != nil {
+		return err
+	}
+
+	*d = Duration(parsed)
+	return nil
+}
+
+// Rule matches an operation when every condition it sets holds
+type Rule struct {
+	// Name identifies the rule in reviews, logs and metrics
+	Name string `json:"name"`
+
+	// Operations restricts the rule to some operations; empty matches all
+	Operations []string `json:"operations"`
+
+	// MinAmount matches operations moving at least this many platform tokens
+	MinAmount float64 `json:"min_amount"`
+
+	// MaxWalletAge matches wallets created less than this long ago, or not created yet
+	MaxWalletAge Duration `json:"max_wallet_age"`
+
+	// ExchangeThenSpendWindow matches spends of tokens exchanged within this window
+	ExchangeThenSpendWindow Duration `json:"exchange_then_spend_window"`
+
+	// MinExchangedRatio is the share of the spend that must have been exchanged within the window
+	MinExchangedRatio float64 `json:"min_exchanged_ratio"`
+
+	// Decision is returned when the rule matches: review or deny
+	Decision string `json:"decision"`
+}
+
+// RuleEngine evaluates operations against a list of rules and returns the most severe decision matched
+type RuleEngine struct {
+	rules []Rule
+}
+
+type rulesFile struct {
+	Rules []Rule `json:"rules"`
+}
+
+// NewRuleEngine creates a rule engine after validating its rules
+func NewRuleEngine(rules []Rule) (*RuleEngine, error) {
+	for i, rule := range rules {
+		if err := validateRule(rule); err != nil {
+			return nil, fmt.Errorf("rule %d (%q): %w", i, rule.Name, err)
+		}
+	}
+	return &RuleEngine{rules: rules}, nil
+}
+
+// LoadRuleEngine reads rules from a JSON file
+func LoadRuleEngine(path string) (*RuleEngine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read risk rules: %w", err)
+	}
+
+	var file rulesFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("parse risk rules %s: %w", path, err)
+	}
+
+	engine, err := NewRuleEngine(file.Rules)
+	if err != nil {
+		return nil, fmt.Errorf("invalid risk rules %s: %w", path, err)
+	}
+
+	return engine, nil
+}
+
+// Rules returns the rules of the engine
+func (e *RuleEngine) Rules() []Rule {
+	return e.rules
+}
+
+// Evaluate returns the most severe decision of the rules matching the operation
+func (e *RuleEngine) Evaluate(ctx context.Context, op *model.RiskOperation) (*model.RiskAssessment, error) {
+	assessment := &model.RiskAssessment{Decision: model.RiskDecisionAllow}
+
+	for _, rule := range e.rules {
+		if !rule.matches(op) {
+			continue
+		}
+
+		assessment.MatchedRules = append(assessment.MatchedRules, rule.Name)
+		if severity(rule.Decision) > severity(assessment.Decision) {
+			assessment.Decision = rule.Decision
+		}
+	}
+
+	return assessment, nil
+}
+
+func (r Rule) matches(op *model.RiskOperation) bool {
+	if len(r.Operations) > 0 && !contains(r.Operations, op.Operation) {
+		return false
+	}
+
+	if r.MinAmount > 0 && op.PlatformAmount < r.MinAmount {
+		return false
+	}
+
+	if r.MaxWalletAge > 0 && op.WalletCreatedAt != nil &&
+		op.Now.Sub(*op.WalletCreatedAt) >= time.Duration(r.MaxWalletAge) {
+		return false
+	}
+
+	if r.ExchangeThenSpendWindow > 0 {
+		if op.Operation != model.TransactionSpend {
+			return false
+		}
+
+		exchanged := exchangedSince(op.RecentHistory, op.Now.Add(-time.Duration(r.ExchangeThenSpendWindow)))
+		if exchanged == 0 || exchanged < r.MinExchangedRatio*op.PlatformAmount {
+			return false
+		}
+	}
+
+	return true
+}
+
+// exchangedSince sums the platform tokens credited by exchanges since the given time
+func exchangedSince(history []*model.WalletLog, since time.Time) float64 {
+	var total float64
+	for _, log := range history {
+		if log.Source == model.TransactionExchange && !log.CreatedAt.Before(since) {
+			total += log.PlatformAmount
+		}
+	}
+	return total
+}
+
+func validateRule(rule Rule) error {
+	if rule.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+
+	if rule.Decision != model.RiskDecisionReview && rule.Decision != model.RiskDecisionDeny {
+		return fmt.Errorf("decision must be %q or %q", model.RiskDecisionReview, model.RiskDecisionDeny)
+	}
+
+	if rule.MinAmount < 0 || rule.MinExchangedRatio < 0 || rule.MaxWalletAge < 0 || rule.ExchangeThenSpendWindow < 0 {
+		return fmt.Errorf("conditions must not be negative")
+	}
+
+	if rule.MinAmount == 0 && rule.MaxWalletAge == 0 && rule.ExchangeThenSpendWindow == 0 {
+		return fmt.Errorf("at least one condition is required")
+	}
+
+	return nil
+}
+
+func severity(decision string) int {
+	switch decision {
+	case model.RiskDecisionDeny:
+		return 2
+	case model.RiskDecisionReview:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,161 @@
+package risk
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRuleEngineEvaluate(t *testing.T) {
+	now := time.Date(2025, 5, 16, 20, 0, 0, 0, time.UTC)
+	oldWallet := now.Add(-30 * 24 * time.Hour)
+	newWallet := now.Add(-time.Hour)
+
+	engine, err := NewRuleEngine([]Rule{
+		{Name: "large_spend", Operations: []string{model.TransactionSpend}, MinAmount: 1000, Decision: model.RiskDecisionReview},
+		{Name: "huge_exchange", Operations: []string{model.TransactionExchange}, MinAmount: 100000, Decision: model.RiskDecisionDeny},
+		{Name: "new_wallet", MaxWalletAge: Duration(24 * time.Hour), MinAmount: 200, Decision: model.RiskDecisionReview},
+		{
+			Name:                    "exchange_then_spend",
+			ExchangeThenSpendWindow: Duration(5 * time.Minute),
+			MinExchangedRatio:       0.8,
+			Decision:                model.RiskDecisionReview,
+		},
+	})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name             string
+		op               *model.RiskOperation
+		expectedDecision string
+		expectedRules    []string
+	}{
+		{
+			name: "Small Spend Allowed",
+			op: &model.RiskOperation{
+				Operation: model.TransactionSpend, PlatformAmount: 50, WalletCreatedAt: &oldWallet, Now: now,
+			},
+			expectedDecision: model.RiskDecisionAllow,
+		},
+		{
+			name: "Large Spend Reviewed",
+			op: &model.RiskOperation{
+				Operation: model.TransactionSpend, PlatformAmount: 1500, WalletCreatedAt: &oldWallet, Now: now,
+			},
+			expectedDecision: model.RiskDecisionReview,
+			expectedRules:    []string{"large_spend"},
+		},
+		{
+			name: "Deny Wins Over Review",
+			op: &model.RiskOperation{
+				Operation: model.TransactionExchange, PlatformAmount: 150000, Now: now,
+			},
+			expectedDecision: model.RiskDecisionDeny,
+			expectedRules:    []string{"huge_exchange", "new_wallet"},
+		},
+		{
+			name: "New Wallet Reviewed",
+			op: &model.RiskOperation{
+				Operation: model.TransactionSpend, PlatformAmount: 300, WalletCreatedAt: &newWallet, Now: now,
+			},
+			expectedDecision: model.RiskDecisionReview,
+			expectedRules:    []string{"new_wallet"},
+		},
+		{
+			name: "Rapid Exchange Then Spend Reviewed",
+			op: &model.RiskOperation{
+				Operation: model.TransactionSpend, PlatformAmount: 100, WalletCreatedAt: &oldWallet, Now: now,
+				RecentHistory: []*model.WalletLog{
+					{Source: model.TransactionExchange, PlatformAmount: 90, CreatedAt: now.Add(-2 * time.Minute)},
+				},
+			},
+			expectedDecision: model.RiskDecisionReview,
+			expectedRules:    []string{"exchange_then_spend"},
+		},
+		{
+			name: "Old Exchange Ignored",
+			op: &model.RiskOperation{
+				Operation: model.TransactionSpend, PlatformAmount: 100, WalletCreatedAt: &oldWallet, Now: now,
+				RecentHistory: []*model.WalletLog{
+					{Source: model.TransactionExchange, PlatformAmount: 90, CreatedAt: now.Add(-time.Hour)},
+				},
+			},
+			expectedDecision: model.RiskDecisionAllow,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assessment, err := engine.Evaluate(context.Background(), tc.op)
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expectedDecision, assessment.Decision)
+			assert.Equal(t, tc.expectedRules, assessment.MatchedRules)
+		})
+	}
+}
+
+func TestLoadRuleEngine(t *testing.T) {
+	dir := t.TempDir()
+
+	write := func(t *testing.T, content interface{}) string {
+		data, err := json.Marshal(content)
+		require.NoError(t, err)
+		path := filepath.Join(dir, t.Name()+".json")
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+		return path
+	}
+
+	t.Run("Valid File", func(t *testing.T) {
+		path := write(t, map[string]interface{}{
+			"rules": []map[string]interface{}{
+				{"name": "new_wallet", "max_wallet_age": "24h", "min_amount": 200, "decision": "review"},
+			},
+		})
+
+		engine, err := LoadRuleEngine(path)
+
+		require.NoError(t, err)
+		require.Len(t, engine.Rules(), 1)
+		assert.Equal(t, Duration(24*time.Hour), engine.Rules()[0].MaxWalletAge)
+	})
+
+	t.Run("Invalid Decision", func(t *testing.T) {
+		path := write(t, map[string]interface{}{
+			"rules": []map[string]interface{}{
+				{"name": "bad", "min_amount": 200, "decision": "block"},
+			},
+		})
+
+		_, err := LoadRuleEngine(path)
+
+		assert.ErrorContains(t, err, "decision must be")
+	})
+
+	t.Run("Rule Without Condition", func(t *testing.T) {
+		path := write(t, map[string]interface{}{
+			"rules": []map[string]interface{}{
+				{"name": "always", "decision": "deny"},
+			},
+		})
+
+		_, err := LoadRuleEngine(path)
+
+		assert.ErrorContains(t, err, "at least one condition")
+	})
+
+	t.Run("Missing File", func(t *testing.T) {
+		_, err := LoadRuleEngine(filepath.Join(dir, "missing.json"))
+
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,63 @@
+package dto
+
+import "time"
+
+// AuditLogRequest selects the entries of the admin audit log.
+// From and To accept RFC 3339 timestamps or dates (YYYY-MM-DD); From is inclusive and To exclusive.
+type AuditLogRequest struct {
+	PrincipalID  int    `query:"principal_id" validate:"omitempty,gt=0"`
+	TargetUserID int    `query:"target_user_id" validate:"omitempty,gt=0"`
+	Action       string `query:"action" validate:"omitempty,max=150"`
+	Outcome      string `query:"outcome" validate:"omitempty,oneof=succeeded denied failed"`
+	From         string `query:"from"`
+	To           string `query:"to"`
+	Limit        int    `query:"limit" validate:"omitempty,gte=1,lte=500"`
+	Offset       int    `query:"offset" validate:"omitempty,gte=0"`
+}
+
+// AuditEntry is one privileged call recorded in the audit log
+// @Description Audit log entry
+type AuditEntry struct {
+	ID            int64     `json:"id" example:"1024"`
+	PrincipalID   int       `json:"principal_id" example:"1"`
+	PrincipalRole string    `json:"principal_role" example:"admin"`
+	Action        string    `json:"action" example:"PUT /admin/wallets/:user_id/status"`
+	Method        string    `json:"method" example:"PUT"`
+	Path          string    `json:"path" example:"/admin/wallets/42/status"`
+	TargetUserID  *int      `json:"target_user_id,omitempty" example:"42"`
+	PayloadHash   string    `json:"payload_hash" example:"9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"`
+	RequestID     string    `json:"request_id" example:"4f9c1a52-6f0e-4c1b-9a51-0d3e2b7c8a11"`
+	StatusCode    int       `json:"status_code" example:"200"`
+	Outcome       string    `json:"outcome" example:"succeeded"`
+	CreatedAt     time.Time `json:"created_at" example:"2025-05-16T20:00:00Z"`
+	PrevHash      string    `json:"prev_hash" example:"3a7bd3e2360a3d29eea436fcfb7e44c735d117c42d1c1835420b6b9942dd4f1b"`
+	Hash          string    `json:"hash" example:"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"`
+}
+
+// AuditLogResponse is the response for the admin audit log
+// @Description Response containing audit log entries
+type AuditLogResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Data    []*AuditEntry `json:"data,omitempty"`
+	Error   string        `json:"error,omitempty" example:""`
+}
+
+// AuditVerification is the result of checking the hash chain of the audit log. When the chain is broken,
+// BrokenAt is the first entry that does not match its hash or the entry before it.
+// @Description Audit chain verification
+type AuditVerification struct {
+	Valid          bool   `json:"valid" example:"true"`
+	EntriesChecked int    `json:"entries_checked" example:"1024"`
+	LastID         int64  `json:"last_id" example:"1024"`
+	LastHash       string `json:"last_hash" example:"b5bb9d8014a0f9b1d61e21e796d78dccdf1352f23cd32812f4850b878ae4944c"`
+	BrokenAt       *int64 `json:"broken_at,omitempty" example:"512"`
+	Reason         string `json:"reason,omitempty" example:""`
+}
+
+// AuditVerificationResponse is the response for an audit chain verification
+// @Description Response containing an audit chain verification
+type AuditVerificationResponse struct {
+	Success bool               `json:"success" example:"true"`
+	Data    *AuditVerification `json:"data,omitempty"`
+	Error   string             `json:"error,omitempty" example:""`
+}
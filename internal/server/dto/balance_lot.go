@@ -0,0 +1,29 @@
+package dto
+
+import "time"
+
+// BalanceExpiration represents an amount of a wallet balance that expires at a given time
+// @Description Upcoming expiration of a part of the balance
+type BalanceExpiration struct {
+	Amount    float64   `json:"amount" example:"25"`
+	Source    string    `json:"source" example:"bonus"`
+	ExpiresAt time.Time `json:"expires_at" example:"2025-06-30T00:00:00Z"`
+}
+
+// GrantTokensRequest represents an admin request to grant tokens to a user.
+// Granted tokens expire at ExpiresAt when it is set, and are spent before tokens that never expire.
+// @Description Request for granting promotional tokens
+type GrantTokensRequest struct {
+	Amount      float64    `json:"amount" validate:"required,gt=0" example:"25"`
+	Currency    string     `json:"currency,omitempty" validate:"omitempty,max=32" example:"platform"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty" example:"2025-06-30T00:00:00Z"`
+	ReferenceID string     `json:"reference_id" validate:"required,min=1,max=50" example:"SUMMER-PROMO-2025"`
+}
+
+// ExpiryReport summarizes a run of the balance expiry job
+// @Description Result of expiring balance lots
+type ExpiryReport struct {
+	WalletsProcessed int     `json:"wallets_processed" example:"3"`
+	LotsExpired      int     `json:"lots_expired" example:"4"`
+	AmountExpired    float64 `json:"amount_expired" example:"75"`
+}
@@ -0,0 +1,79 @@
+package dto
+
+import "time"
+
+// BulkItem represents one credit or spend of a bulk request.
+// An item whose idempotency key was already applied is reported as a duplicate and not applied again.
+// @Description Credit or spend item of a bulk request
+type BulkItem struct {
+	IdempotencyKey string     `json:"idempotency_key" validate:"required,min=1,max=64" example:"season-12-reward-123"`
+	Operation      string     `json:"operation" validate:"required,oneof=credit spend" example:"credit"`
+	UserID         int        `json:"user_id" validate:"required,gt=0" example:"123"`
+	Amount         float64    `json:"amount" validate:"required,gt=0" example:"50"`
+	Currency       string     `json:"currency,omitempty" validate:"omitempty,max=32" example:"platform"`
+	Reason         string     `json:"reason,omitempty" validate:"omitempty,oneof=market_purchase competition_entry" example:"competition_entry"`
+	ReferenceID    string     `json:"reference_id,omitempty" validate:"omitempty,max=50" example:"SEASON-12"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty" example:"2025-06-30T00:00:00Z"`
+}
+
+// BulkRequest represents a list of credits and spends applied in chunked transactions
+// @Description Request for bulk credits and spends
+type BulkRequest struct {
+	Items []BulkItem `json:"items" validate:"required,min=1,dive"`
+	// Async processes the items in the background and returns a job to poll
+	Async bool `json:"async" example:"false"`
+}
+
+// BulkItemResult is the outcome of one bulk item
+// @Description Result of a bulk item
+type BulkItemResult struct {
+	Index          int      `json:"index" example:"0"`
+	IdempotencyKey string   `json:"idempotency_key" example:"season-12-reward-123"`
+	Status         string   `json:"status" example:"succeeded"`
+	Currency       string   `json:"currency,omitempty" example:"platform"`
+	NewBalance     *float64 `json:"new_balance,omitempty" example:"150"`
+	Error          string   `json:"error,omitempty" example:""`
+}
+
+// BulkReport summarizes a processed bulk request
+// @Description Result of a bulk request
+type BulkReport struct {
+	Total      int              `json:"total" example:"2"`
+	Succeeded  int              `json:"succeeded" example:"1"`
+	Failed     int              `json:"failed" example:"0"`
+	Duplicates int              `json:"duplicates" example:"1"`
+	Results    []BulkItemResult `json:"results"`
+}
+
+// BulkJob represents a bulk request processed in the background
+// @Description Background bulk job
+type BulkJob struct {
+	ID          int64            `json:"id" example:"7"`
+	Status      string           `json:"status" example:"completed"`
+	TotalItems  int              `json:"total_items" example:"25000"`
+	Succeeded   int              `json:"succeeded" example:"24990"`
+	Failed      int              `json:"failed" example:"4"`
+	Duplicates  int              `json:"duplicates" example:"6"`
+	Results     []BulkItemResult `json:"results,omitempty"`
+	Error       *string          `json:"error,omitempty"`
+	RequestedBy int              `json:"requested_by" example:"1"`
+	CreatedAt   time.Time        `json:"created_at" example:"2025-05-16T20:00:00Z"`
+	StartedAt   *time.Time       `json:"started_at,omitempty" example:"2025-05-16T20:00:01Z"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty" example:"2025-05-16T20:03:00Z"`
+}
+
+// BulkResponse is the response for a bulk request processed synchronously
+// @Description Response for bulk operations
+type BulkResponse struct {
+	Success bool        `json:"success" example:"true"`
+	Data    *BulkReport `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty" example:""`
+}
+
+// BulkJobResponse is the response for a background bulk job
+// @Description Response containing a background bulk job
+type BulkJobResponse struct {
+	Success bool     `json:"success" example:"true"`
+	Data    *BulkJob `json:"data,omitempty"`
+	Error   string   `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,31 @@
+package dto
+
+import "time"
+
+// WalletLogExportRequest selects the wallet logs of an export and its format.
+// From and To accept RFC 3339 timestamps or dates (YYYY-MM-DD); From is inclusive and To exclusive.
+type WalletLogExportRequest struct {
+	Format    string `query:"format" validate:"omitempty,oneof=csv ndjson"`
+	UserID    int    `query:"user_id" validate:"omitempty,gt=0"`
+	GameID    string `query:"game_id" validate:"omitempty,max=50"`
+	TokenType string `query:"token_type" validate:"omitempty,max=20"`
+	Currency  string `query:"currency" validate:"omitempty,max=32"`
+	Source    string `query:"source" validate:"omitempty,max=20"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+}
+
+// WalletLogExportRow is one wallet log of an NDJSON export; CSV exports have the same columns
+type WalletLogExportRow struct {
+	ID             int64     `json:"id"`
+	WalletID       int64     `json:"wallet_id"`
+	UserID         int       `json:"user_id"`
+	Currency       string    `json:"currency"`
+	GameID         *string   `json:"game_id"`
+	TokenType      *string   `json:"token_type"`
+	Amount         float64   `json:"amount"`
+	PlatformAmount float64   `json:"platform_amount"`
+	Source         string    `json:"source"`
+	ReferenceID    *string   `json:"reference_id"`
+	CreatedAt      time.Time `json:"created_at"`
+}
@@ -0,0 +1,62 @@
+package dto
+
+import (
+	"time"
+)
+
+// FeatureFlag represents a feature flag with its effective targeting
+// @Description Feature flag
+type FeatureFlag struct {
+	Name        string     `json:"name" example:"spend_reason.competition_entry"`
+	Description string     `json:"description,omitempty" example:"Spends for competition entries"`
+	Enabled     bool       `json:"enabled" example:"true"`
+	Percentage  int        `json:"percentage" example:"10"`
+	Roles       []string   `json:"roles" example:"admin"`
+	GameIDs     []string   `json:"game_ids" example:"game1"`
+	Overridden  bool       `json:"overridden" example:"true"`
+	Reason      string     `json:"reason,omitempty" example:"Beta for 10% of users"`
+	UpdatedBy   *int       `json:"updated_by,omitempty" example:"1"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty" example:"2025-05-16T20:00:00Z"`
+}
+
+// FeatureFlagResponse is the response for the feature flag override endpoint
+// @Description Response for a feature flag
+type FeatureFlagResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Data    *FeatureFlag `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty" example:""`
+}
+
+// FeatureFlagListResponse is the response for the feature flag list endpoint
+// @Description Response for the feature flags
+type FeatureFlagListResponse struct {
+	Success bool           `json:"success" example:"true"`
+	Data    []*FeatureFlag `json:"data,omitempty"`
+	Error   string         `json:"error,omitempty" example:""`
+}
+
+// UpdateFeatureFlagRequest represents an admin request to override the targeting of a feature flag.
+// The override replaces the targeting of the feature flags file until it is removed.
+// @Description Request for overriding a feature flag
+type UpdateFeatureFlagRequest struct {
+	Enabled    *bool    `json:"enabled" validate:"required" example:"true"`
+	Percentage int      `json:"percentage" validate:"gte=0,lte=100" example:"10"`
+	Roles      []string `json:"roles" validate:"omitempty,dive,required,max=50" example:"admin"`
+	GameIDs    []string `json:"game_ids" validate:"omitempty,dive,required,max=50" example:"game1"`
+	Reason     string   `json:"reason" validate:"required,min=3,max=500" example:"Beta for 10% of users"`
+}
+
+// UserFeatures lists whether each feature flag is on for a user
+// @Description Feature flags of a user
+type UserFeatures struct {
+	UserID   int             `json:"user_id" example:"123"`
+	Features map[string]bool `json:"features"`
+}
+
+// UserFeaturesResponse is the response for the user features endpoint
+// @Description Response for the feature flags of a user
+type UserFeaturesResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Data    *UserFeatures `json:"data,omitempty"`
+	Error   string        `json:"error,omitempty" example:""`
+}
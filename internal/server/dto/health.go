@@ -0,0 +1,22 @@
+package dto
+
+import "time"
+
+// HealthCheck is the outcome of one check of a probe
+// @Description Health check result
+type HealthCheck struct {
+	Name       string    `json:"name" example:"database"`
+	Status     string    `json:"status" example:"ok"`
+	Critical   bool      `json:"critical" example:"true"`
+	Detail     string    `json:"detail,omitempty" example:"version 17"`
+	Error      string    `json:"error,omitempty" example:""`
+	DurationMs float64   `json:"duration_ms" example:"1.8"`
+	CheckedAt  time.Time `json:"checked_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// HealthResponse is the response of the liveness and readiness probes
+// @Description Probe status with the result of each check
+type HealthResponse struct {
+	Status string        `json:"status" example:"ok"`
+	Checks []HealthCheck `json:"checks"`
+}
@@ -0,0 +1,44 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Job represents a background job
+// @Description Background job
+type Job struct {
+	ID              int64           `json:"id" example:"42"`
+	Kind            string          `json:"kind" example:"bulk"`
+	Status          string          `json:"status" example:"running"`
+	Payload         json.RawMessage `json:"payload" swaggertype:"object"`
+	Result          json.RawMessage `json:"result,omitempty" swaggertype:"object"`
+	Error           *string         `json:"error,omitempty" example:""`
+	Attempts        int             `json:"attempts" example:"1"`
+	MaxAttempts     int             `json:"max_attempts" example:"3"`
+	RunAt           time.Time       `json:"run_at" example:"2025-05-16T20:00:00Z"`
+	LockedBy        *string         `json:"locked_by,omitempty" example:"wallet-1:4821:0"`
+	LockedUntil     *time.Time      `json:"locked_until,omitempty" example:"2025-05-16T20:01:15Z"`
+	HeartbeatAt     *time.Time      `json:"heartbeat_at,omitempty" example:"2025-05-16T20:00:15Z"`
+	CancelRequested bool            `json:"cancel_requested" example:"false"`
+	CreatedBy       *int            `json:"created_by,omitempty" example:"1"`
+	CreatedAt       time.Time       `json:"created_at" example:"2025-05-16T20:00:00Z"`
+	StartedAt       *time.Time      `json:"started_at,omitempty" example:"2025-05-16T20:00:01Z"`
+	FinishedAt      *time.Time      `json:"finished_at,omitempty" example:"2025-05-16T20:03:00Z"`
+}
+
+// JobResponse is the response for a single background job
+// @Description Response containing a background job
+type JobResponse struct {
+	Success bool   `json:"success" example:"true"`
+	Data    *Job   `json:"data,omitempty"`
+	Error   string `json:"error,omitempty" example:""`
+}
+
+// JobListResponse is the response for the background job list
+// @Description Response containing background jobs
+type JobListResponse struct {
+	Success bool   `json:"success" example:"true"`
+	Data    []Job  `json:"data,omitempty"`
+	Error   string `json:"error,omitempty" example:""`
+}
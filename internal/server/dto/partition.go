@@ -0,0 +1,9 @@
+package dto
+
+// PartitionReport summarizes a run of the wallet log partition maintenance
+// @Description Result of maintaining the wallet log partitions
+type PartitionReport struct {
+	Created             []string `json:"created" example:"wallet_logs_2026_06"`
+	Archived            []string `json:"archived" example:"wallet_logs_2025_12"`
+	StatementsGenerated int      `json:"statements_generated" example:"340"`
+}
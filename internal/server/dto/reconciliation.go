@@ -0,0 +1,58 @@
+package dto
+
+import "time"
+
+// ReconciliationMismatch represents a wallet whose balance drifted from its log history
+// @Description Wallet balance drift found by reconciliation
+type ReconciliationMismatch struct {
+	ID             int64      `json:"id" example:"7"`
+	RunID          int64      `json:"run_id" example:"3"`
+	WalletID       int64      `json:"wallet_id" example:"1"`
+	UserID         int        `json:"user_id" example:"123"`
+	Balance        float64    `json:"balance" example:"150.50"`
+	LedgerBalance  float64    `json:"ledger_balance" example:"140.50"`
+	Drift          float64    `json:"drift" example:"10.00"`
+	Frozen         bool       `json:"frozen" example:"true"`
+	ResolvedBy     *int       `json:"resolved_by,omitempty" example:"1"`
+	ResolvedAt     *time.Time `json:"resolved_at,omitempty" example:"2025-05-16T21:00:00Z"`
+	ResolutionNote *string    `json:"resolution_note,omitempty" example:"Manual correction applied"`
+	CreatedAt      time.Time  `json:"created_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// ReconciliationReport summarises a reconciliation run and the mismatches it found
+// @Description Reconciliation run report
+type ReconciliationReport struct {
+	RunID          int64                    `json:"run_id" example:"3"`
+	Trigger        string                   `json:"trigger" example:"admin"`
+	Status         string                   `json:"status" example:"completed"`
+	WalletsChecked int                      `json:"wallets_checked" example:"1250"`
+	MismatchCount  int                      `json:"mismatch_count" example:"1"`
+	TotalDrift     float64                  `json:"total_drift" example:"10.00"`
+	Error          *string                  `json:"error,omitempty"`
+	StartedAt      time.Time                `json:"started_at" example:"2025-05-16T20:00:00Z"`
+	FinishedAt     *time.Time               `json:"finished_at,omitempty" example:"2025-05-16T20:00:05Z"`
+	Mismatches     []ReconciliationMismatch `json:"mismatches"`
+}
+
+// ReconciliationReportResponse is the response for reconciliation run endpoints
+// @Description Response for reconciliation runs
+type ReconciliationReportResponse struct {
+	Success bool                  `json:"success" example:"true"`
+	Data    *ReconciliationReport `json:"data,omitempty"`
+	Error   string                `json:"error,omitempty" example:""`
+}
+
+// ResolveMismatchRequest represents a review of a reconciliation mismatch
+// @Description Request for resolving a reconciliation mismatch
+type ResolveMismatchRequest struct {
+	Note     string `json:"note" validate:"required,min=1" example:"Manual correction applied"`
+	Unfreeze bool   `json:"unfreeze" example:"true"`
+}
+
+// ReconciliationMismatchResponse is the response for mismatch endpoints
+// @Description Response for reconciliation mismatch operations
+type ReconciliationMismatchResponse struct {
+	Success bool                    `json:"success" example:"true"`
+	Data    *ReconciliationMismatch `json:"data,omitempty"`
+	Error   string                  `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,140 @@
+package dto
+
+import "time"
+
+// ReportRequest selects the days of a report. From and To are dates (YYYY-MM-DD); From is inclusive and
+// To exclusive. GameID and TokenType only apply to the exchange report.
+type ReportRequest struct {
+	Currency  string `query:"currency" validate:"omitempty,max=32"`
+	From      string `query:"from"`
+	To        string `query:"to"`
+	GameID    string `query:"game_id" validate:"omitempty,max=50"`
+	TokenType string `query:"token_type" validate:"omitempty,max=20"`
+}
+
+// TopWalletsRequest selects the wallets of the top wallet report
+type TopWalletsRequest struct {
+	Currency string `query:"currency" validate:"omitempty,max=32"`
+	Limit    int    `query:"limit" validate:"omitempty,gte=1,lte=100"`
+}
+
+// ReportRange is the days a report covers and how far the rollups behind it are up to date.
+// Logs created after RolledUpThrough are not part of the report yet.
+type ReportRange struct {
+	Currency        string     `json:"currency" example:"platform"`
+	From            string     `json:"from" example:"2025-05-01"`
+	To              string     `json:"to" example:"2025-06-01"`
+	RolledUpThrough *time.Time `json:"rolled_up_through" example:"2025-05-16T12:29:00Z"`
+}
+
+// ExchangeVolume is the amount of a game token exchanged on one day
+// @Description Daily exchange volume of a game token
+type ExchangeVolume struct {
+	Day            string  `json:"day" example:"2025-05-16"`
+	GameID         string  `json:"game_id" example:"game-123"`
+	TokenType      string  `json:"token_type" example:"gold"`
+	Exchanges      int     `json:"exchanges" example:"42"`
+	Amount         float64 `json:"amount" example:"12500"`
+	PlatformAmount float64 `json:"platform_amount" example:"1250.00"`
+}
+
+// ExchangeReport lists the daily exchange volume of each game token
+// @Description Exchange volume per game, token type and day
+type ExchangeReport struct {
+	ReportRange
+	Volumes []ExchangeVolume `json:"volumes"`
+}
+
+// ExchangeReportResponse is the response for the exchange report endpoint
+// @Description Response containing the exchange report
+type ExchangeReportResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Data    *ExchangeReport `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty" example:""`
+}
+
+// SpendVolume is the amount spent for one reason
+// @Description Platform tokens spent for one reason
+type SpendVolume struct {
+	Reason string  `json:"reason" example:"item_purchase"`
+	Spends int     `json:"spends" example:"310"`
+	Amount float64 `json:"amount" example:"4820.50"`
+}
+
+// SpendReport lists the platform tokens spent per reason, largest first
+// @Description Spend per reason
+type SpendReport struct {
+	ReportRange
+	Total   float64       `json:"total" example:"6120.50"`
+	Volumes []SpendVolume `json:"volumes"`
+}
+
+// SpendReportResponse is the response for the spend report endpoint
+// @Description Response containing the spend report
+type SpendReportResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Data    *SpendReport `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty" example:""`
+}
+
+// SupplyPoint is the platform tokens credited and debited on one day and the supply at its end
+// @Description Net token supply on one day
+type SupplyPoint struct {
+	Day     string  `json:"day" example:"2025-05-16"`
+	Credits float64 `json:"credits" example:"1520.00"`
+	Debits  float64 `json:"debits" example:"980.50"`
+	Net     float64 `json:"net" example:"539.50"`
+	Supply  float64 `json:"supply" example:"250340.75"`
+}
+
+// SupplyReport lists the net token supply over time
+// @Description Net token supply per day
+type SupplyReport struct {
+	ReportRange
+	Points []SupplyPoint `json:"points"`
+}
+
+// SupplyReportResponse is the response for the token supply report endpoint
+// @Description Response containing the token supply report
+type SupplyReportResponse struct {
+	Success bool          `json:"success" example:"true"`
+	Data    *SupplyReport `json:"data,omitempty"`
+	Error   string        `json:"error,omitempty" example:""`
+}
+
+// ActiveWalletCount is the number of wallets with at least one log on one day
+// @Description Active wallets on one day
+type ActiveWalletCount struct {
+	Day     string `json:"day" example:"2025-05-16"`
+	Wallets int    `json:"wallets" example:"1830"`
+}
+
+// ActiveWalletReport lists the number of active wallets per day
+// @Description Active wallets per day
+type ActiveWalletReport struct {
+	ReportRange
+	Counts []ActiveWalletCount `json:"counts"`
+}
+
+// ActiveWalletReportResponse is the response for the active wallet report endpoint
+// @Description Response containing the active wallet report
+type ActiveWalletReportResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Data    *ActiveWalletReport `json:"data,omitempty"`
+	Error   string              `json:"error,omitempty" example:""`
+}
+
+// TopWalletsResponse is the response for the top wallet report endpoint
+// @Description Response containing the wallets with the largest balances
+type TopWalletsResponse struct {
+	Success bool      `json:"success" example:"true"`
+	Data    []*Wallet `json:"data,omitempty"`
+	Error   string    `json:"error,omitempty" example:""`
+}
+
+// RollupReport summarizes a run of the report rollup job
+// @Description Result of rolling up wallet logs
+type RollupReport struct {
+	LogsRolledUp    int        `json:"logs_rolled_up" example:"5120"`
+	RolledUpThrough *time.Time `json:"rolled_up_through" example:"2025-05-16T12:29:00Z"`
+}
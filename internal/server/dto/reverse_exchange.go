@@ -0,0 +1,75 @@
+package dto
+
+import "time"
+
+// ReverseExchangeRequest represents a request to cash platform tokens back into a game token
+// @Description Request for reverse exchange
+type ReverseExchangeRequest struct {
+	UserID    int     `json:"user_id" validate:"required,gt=0" example:"123"`
+	GameID    string  `json:"game_id" validate:"required,min=1,max=50" example:"game-abc"`
+	TokenType string  `json:"token_type" validate:"required,min=1,max=20" example:"gold"`
+	Amount    float64 `json:"amount" validate:"required,gt=0" example:"15"`
+	Currency  string  `json:"currency,omitempty" validate:"omitempty,max=32" example:"platform"`
+}
+
+// ReverseExchangeGrant represents game tokens granted by a reverse exchange.
+// The game server credits them when it redeems Token.
+// @Description Signed grant of game tokens
+type ReverseExchangeGrant struct {
+	GrantID        string     `json:"grant_id" example:"4f1c2a9e0b7d4c3f8a6e5d2b1c0f9e8d"`
+	UserID         int        `json:"user_id" example:"123"`
+	GameID         string     `json:"game_id" example:"game-abc"`
+	TokenType      string     `json:"token_type" example:"gold"`
+	Currency       string     `json:"currency" example:"platform"`
+	PlatformAmount float64    `json:"platform_amount" example:"15"`
+	GameAmount     float64    `json:"game_amount" example:"150"`
+	ExpiresAt      time.Time  `json:"expires_at" example:"2025-05-16T20:15:00Z"`
+	RedeemedAt     *time.Time `json:"redeemed_at,omitempty" example:"2025-05-16T20:01:00Z"`
+	RefundedAt     *time.Time `json:"refunded_at,omitempty" example:"2025-05-16T20:16:00Z"`
+	Token          string     `json:"token,omitempty" example:"eyJncmFudF9pZCI6IjRmMWMyYTllIn0.c2lnbmF0dXJl"`
+}
+
+// ReverseExchangeResult is the outcome of a reverse exchange
+type ReverseExchangeResult struct {
+	NewBalance float64
+	Grant      *ReverseExchangeGrant
+}
+
+// ReverseExchangeResponse is the response for the reverse exchange endpoint
+// @Description Response for reverse exchange operations
+type ReverseExchangeResponse struct {
+	Success    bool                  `json:"success" example:"true"`
+	NewBalance float64               `json:"new_balance,omitempty" example:"150.50"`
+	Currency   string                `json:"currency,omitempty" example:"platform"`
+	Grant      *ReverseExchangeGrant `json:"grant,omitempty"`
+	Error      string                `json:"error,omitempty" example:""`
+}
+
+// RedeemGrantRequest represents a game server request to redeem a grant
+// @Description Request for redeeming a reverse exchange grant
+type RedeemGrantRequest struct {
+	Token string `json:"token" validate:"required,min=1" example:"eyJncmFudF9pZCI6IjRmMWMyYTllIn0.c2lnbmF0dXJl"`
+}
+
+// ReverseExchangeGrantResponse is the response for a single grant
+// @Description Response containing a reverse exchange grant
+type ReverseExchangeGrantResponse struct {
+	Success bool                  `json:"success" example:"true"`
+	Data    *ReverseExchangeGrant `json:"data,omitempty"`
+	Error   string                `json:"error,omitempty" example:""`
+}
+
+// ReverseExchangeGrantsResponse is the response for listing grants
+// @Description Response containing the open reverse exchange grants of a user
+type ReverseExchangeGrantsResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Data    []ReverseExchangeGrant `json:"data,omitempty"`
+	Error   string                 `json:"error,omitempty" example:""`
+}
+
+// GrantRefundReport summarizes a run of the reverse exchange refund job
+// @Description Result of refunding expired reverse exchange grants
+type GrantRefundReport struct {
+	GrantsRefunded int     `json:"grants_refunded" example:"2"`
+	AmountRefunded float64 `json:"amount_refunded" example:"30"`
+}
@@ -0,0 +1,49 @@
+package dto
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// RiskReview represents an operation held for manual review
+// @Description Operation held for manual review
+type RiskReview struct {
+	ID             int64           `json:"id" example:"1"`
+	Operation      string          `json:"operation" example:"spend"`
+	UserID         int             `json:"user_id" example:"123"`
+	PrincipalID    int             `json:"principal_id" example:"123"`
+	PrincipalRole  string          `json:"principal_role" example:"user"`
+	Amount         float64         `json:"amount" example:"1500"`
+	PlatformAmount float64         `json:"platform_amount" example:"1500"`
+	MatchedRules   []string        `json:"matched_rules" example:"large_spend"`
+	Payload        json.RawMessage `json:"payload" swaggertype:"object"`
+	Status         string          `json:"status" example:"pending"`
+	ReviewedBy     *int            `json:"reviewed_by,omitempty" example:"1"`
+	ReviewedAt     *time.Time      `json:"reviewed_at,omitempty" example:"2025-05-16T20:05:00Z"`
+	ReviewNote     *string         `json:"review_note,omitempty" example:"Confirmed with the customer"`
+	ResultError    *string         `json:"result_error,omitempty" example:""`
+	NewBalance     *float64        `json:"new_balance,omitempty" example:"100.50"`
+	CreatedAt      time.Time       `json:"created_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// RiskReviewResponse is the response for the risk review decision endpoints
+// @Description Response for a risk review
+type RiskReviewResponse struct {
+	Success bool        `json:"success" example:"true"`
+	Data    *RiskReview `json:"data,omitempty"`
+	Error   string      `json:"error,omitempty" example:""`
+}
+
+// RiskReviewListResponse is the response for the risk review queue endpoint
+// @Description Response for the risk review queue
+type RiskReviewListResponse struct {
+	Success bool         `json:"success" example:"true"`
+	Data    []RiskReview `json:"data,omitempty"`
+	Error   string       `json:"error,omitempty" example:""`
+}
+
+// DecideRiskReviewRequest represents an admin decision on a held operation
+// @Description Request for approving or rejecting a held operation
+type DecideRiskReviewRequest struct {
+	Note string `json:"note" validate:"required,min=3,max=500" example:"Confirmed with the customer"`
+}
@@ -0,0 +1,28 @@
+package dto
+
+import "time"
+
+// PointInTimeBalance is a wallet balance at a past time
+// @Description Wallet balance at a point in time
+type PointInTimeBalance struct {
+	UserID     int        `json:"user_id" example:"123"`
+	Currency   string     `json:"currency" example:"platform"`
+	At         time.Time  `json:"at" example:"2025-03-01T00:00:00Z"`
+	Balance    float64    `json:"balance" example:"150.50"`
+	SnapshotAt *time.Time `json:"snapshot_at,omitempty" example:"2025-02-28T00:00:00Z"`
+}
+
+// PointInTimeBalanceResponse is the response for the point-in-time balance endpoint
+// @Description Response containing a wallet balance at a point in time
+type PointInTimeBalanceResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Data    *PointInTimeBalance `json:"data,omitempty"`
+	Error   string              `json:"error,omitempty" example:""`
+}
+
+// SnapshotReport summarizes a run of the balance snapshot job
+// @Description Result of taking balance snapshots
+type SnapshotReport struct {
+	TakenAt          time.Time `json:"taken_at" example:"2025-05-16T00:00:00Z"`
+	SnapshotsCreated int       `json:"snapshots_created" example:"1250"`
+}
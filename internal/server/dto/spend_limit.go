@@ -0,0 +1,69 @@
+package dto
+
+import "time"
+
+// SpendLimits represents the limits applied to a user's spends; 0 means unlimited
+// @Description Spend limits
+type SpendLimits struct {
+	MaxPerTransaction  float64 `json:"max_per_transaction" example:"500"`
+	DailyCap           float64 `json:"daily_cap" example:"1000"`
+	WeeklyCap          float64 `json:"weekly_cap" example:"5000"`
+	MaxSpendsPerMinute int     `json:"max_spends_per_minute" example:"5"`
+}
+
+// SpendAllowance represents a user's spend limits in one currency and what is left of them.
+// Remaining values are omitted when the corresponding limit is unlimited.
+// @Description Remaining spend allowance
+type SpendAllowance struct {
+	UserID                    int         `json:"user_id" example:"123"`
+	Currency                  string      `json:"currency" example:"platform"`
+	Limits                    SpendLimits `json:"limits"`
+	Overridden                bool        `json:"overridden" example:"false"`
+	SpentLastDay              float64     `json:"spent_last_day" example:"250"`
+	SpentLastWeek             float64     `json:"spent_last_week" example:"1250"`
+	SpendsLastMinute          int         `json:"spends_last_minute" example:"1"`
+	RemainingDaily            *float64    `json:"remaining_daily,omitempty" example:"750"`
+	RemainingWeekly           *float64    `json:"remaining_weekly,omitempty" example:"3750"`
+	RemainingSpendsThisMinute *int        `json:"remaining_spends_this_minute,omitempty" example:"4"`
+	MaxSpendable              *float64    `json:"max_spendable,omitempty" example:"500"`
+}
+
+// SpendAllowanceResponse is the response for the spend allowance endpoint
+// @Description Response for spend allowance
+type SpendAllowanceResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Data    *SpendAllowance `json:"data,omitempty"`
+	Error   string          `json:"error,omitempty" example:""`
+}
+
+// UpdateSpendLimitsRequest represents an admin request to override a user's spend limits.
+// Omitted limits fall back to the configured defaults; 0 removes the limit for the user.
+// @Description Request for overriding a user's spend limits
+type UpdateSpendLimitsRequest struct {
+	MaxPerTransaction  *float64 `json:"max_per_transaction" validate:"omitempty,gte=0" example:"500"`
+	DailyCap           *float64 `json:"daily_cap" validate:"omitempty,gte=0" example:"1000"`
+	WeeklyCap          *float64 `json:"weekly_cap" validate:"omitempty,gte=0" example:"5000"`
+	MaxSpendsPerMinute *int     `json:"max_spends_per_minute" validate:"omitempty,gte=0" example:"5"`
+	Reason             string   `json:"reason" validate:"required,min=3,max=500" example:"VIP customer"`
+}
+
+// SpendLimitOverride represents a user's spend limit override
+// @Description Spend limit override
+type SpendLimitOverride struct {
+	UserID             int       `json:"user_id" example:"123"`
+	MaxPerTransaction  *float64  `json:"max_per_transaction" example:"500"`
+	DailyCap           *float64  `json:"daily_cap" example:"1000"`
+	WeeklyCap          *float64  `json:"weekly_cap" example:"5000"`
+	MaxSpendsPerMinute *int      `json:"max_spends_per_minute" example:"5"`
+	Reason             string    `json:"reason" example:"VIP customer"`
+	UpdatedBy          *int      `json:"updated_by" example:"1"`
+	UpdatedAt          time.Time `json:"updated_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// SpendLimitOverrideResponse is the response for the spend limit override endpoints
+// @Description Response for spend limit overrides
+type SpendLimitOverrideResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Data    *SpendLimitOverride `json:"data,omitempty"`
+	Error   string              `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,42 @@
+package dto
+
+import "time"
+
+// StatementEntry is one wallet log of a statement with the balance after it
+// @Description Wallet statement line
+type StatementEntry struct {
+	LogID          int64     `json:"log_id" example:"981"`
+	Operation      string    `json:"operation" example:"exchange"`
+	GameID         *string   `json:"game_id,omitempty" example:"game-abc"`
+	TokenType      *string   `json:"token_type,omitempty" example:"gold"`
+	Amount         float64   `json:"amount" example:"150"`
+	PlatformAmount float64   `json:"platform_amount" example:"15"`
+	Balance        float64   `json:"balance" example:"115"`
+	Source         string    `json:"source" example:"exchange"`
+	ReferenceID    *string   `json:"reference_id,omitempty" example:"ORDER-99887"`
+	CreatedAt      time.Time `json:"created_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// WalletStatement summarizes a wallet's transactions of one calendar month (UTC)
+// @Description Monthly wallet statement
+type WalletStatement struct {
+	UserID         int              `json:"user_id" example:"123"`
+	Currency       string           `json:"currency" example:"platform"`
+	Period         string           `json:"period" example:"2025-05"`
+	PeriodStart    time.Time        `json:"period_start" example:"2025-05-01T00:00:00Z"`
+	PeriodEnd      time.Time        `json:"period_end" example:"2025-06-01T00:00:00Z"`
+	OpeningBalance float64          `json:"opening_balance" example:"100"`
+	TotalCredits   float64          `json:"total_credits" example:"15"`
+	TotalDebits    float64          `json:"total_debits" example:"40"`
+	ClosingBalance float64          `json:"closing_balance" example:"75"`
+	Entries        []StatementEntry `json:"entries"`
+	GeneratedAt    time.Time        `json:"generated_at" example:"2025-06-01T01:00:00Z"`
+}
+
+// WalletStatementResponse is the response for the statement endpoint
+// @Description Response containing a wallet statement
+type WalletStatementResponse struct {
+	Success bool             `json:"success" example:"true"`
+	Data    *WalletStatement `json:"data,omitempty"`
+	Error   string           `json:"error,omitempty" example:""`
+}
@@ -0,0 +1,9 @@
+package dto
+
+// WalletStreamLog is a new wallet log pushed on a wallet event stream. A resumed stream may push a log again;
+// its ID tells it apart.
+// @Description Wallet log pushed on a wallet event stream
+type WalletStreamLog struct {
+	ID int64 `json:"id" example:"1042"`
+	WalletLogEntry
+}
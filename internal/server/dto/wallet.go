@@ -2,13 +2,27 @@ package dto
 
 import "time"
 
-// Wallet represents user wallet information
+// Wallet represents user wallet information in one currency.
+// Balances lists the user's balance in every currency they hold and
+// UpcomingExpirations the parts of Balance that expire soon, soonest first.
 // @Description User wallet information
 type Wallet struct {
-	ID        int       `json:"id" validate:"required,gt=0" example:"1"`
-	UserID    int       `json:"user_id" validate:"required,gt=0" example:"123"`
-	Balance   float64   `json:"balance" validate:"gte=0" example:"150.50"`
-	CreatedAt time.Time `json:"created_at" example:"2025-05-16T20:00:00Z"`
+	ID                  int                 `json:"id" validate:"required,gt=0" example:"1"`
+	UserID              int                 `json:"user_id" validate:"required,gt=0" example:"123"`
+	Currency            string              `json:"currency" example:"platform"`
+	Balance             float64             `json:"balance" validate:"gte=0" example:"150.50"`
+	Balances            []CurrencyBalance   `json:"balances,omitempty"`
+	UpcomingExpirations []BalanceExpiration `json:"upcoming_expirations,omitempty"`
+	Status              string              `json:"status" example:"active"`
+	StatusReason        *string             `json:"status_reason,omitempty" example:"Suspected account takeover"`
+	CreatedAt           time.Time           `json:"created_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// CurrencyBalance represents a user's balance in one currency
+// @Description Balance in one currency
+type CurrencyBalance struct {
+	Currency string  `json:"currency" example:"gems"`
+	Balance  float64 `json:"balance" example:"20"`
 }
 
 // WalletResponse is the response for wallet endpoints
@@ -27,6 +41,7 @@ type ExchangeRequest struct {
 	TokenType string  `json:"token_type" validate:"required,min=1" example:"gold"`
 	Amount    float64 `json:"amount" validate:"required,gt=0" example:"150"`
 	Source    string  `json:"source" validate:"required,oneof=won purchased" example:"won"`
+	Currency  string  `json:"currency,omitempty" validate:"omitempty,max=32" example:"platform"`
 }
 
 // ExchangeResponse is the response for exchange endpoint
@@ -34,6 +49,7 @@ type ExchangeRequest struct {
 type ExchangeResponse struct {
 	Success    bool    `json:"success" example:"true"`
 	NewBalance float64 `json:"new_balance,omitempty" example:"165.50"`
+	Currency   string  `json:"currency,omitempty" example:"platform"`
 	Error      string  `json:"error,omitempty" example:""`
 }
 
@@ -44,6 +60,7 @@ type SpendRequest struct {
 	Amount      float64 `json:"amount" validate:"required,gt=0" example:"50.00"`
 	Reason      string  `json:"reason" validate:"required,oneof=market_purchase competition_entry" example:"market_purchase"`
 	ReferenceID string  `json:"reference_id" validate:"required,min=1" example:"ORDER-99887"`
+	Currency    string  `json:"currency,omitempty" validate:"omitempty,max=32" example:"platform"`
 }
 
 // SpendResponse is the response for spend endpoint
@@ -51,18 +68,20 @@ type SpendRequest struct {
 type SpendResponse struct {
 	Success    bool    `json:"success" example:"true"`
 	NewBalance float64 `json:"new_balance,omitempty" example:"100.50"`
+	Currency   string  `json:"currency,omitempty" example:"platform"`
 	Error      string  `json:"error,omitempty" example:""`
 }
 
 // WalletLogEntry represents a single wallet transaction log
 // @Description Wallet transaction log entry
 type WalletLogEntry struct {
+	Currency        string    `json:"currency" example:"platform"`
 	GameID          *string   `json:"game_id" example:"game-abc"`
 	TokenType       *string   `json:"token_type" example:"gold"`
 	Source          *string   `json:"source" example:"won"`
 	OriginalAmount  float64   `json:"original_amount" validate:"gte=0" example:"150"`
 	ConvertedAmount float64   `json:"converted_amount" example:"15"`
-	Operation       string    `json:"operation" validate:"required,oneof=exchange spend" example:"exchange"`
+	Operation       string    `json:"operation" validate:"required,oneof=exchange spend bonus expiry reverse_exchange" example:"exchange"`
 	ReferenceID     *string   `json:"reference_id" example:"ORDER-99887"`
 	CreatedAt       time.Time `json:"created_at" example:"2025-05-16T20:00:00Z"`
 }
@@ -75,6 +94,31 @@ type WalletLogsResponse struct {
 	Error   string           `json:"error,omitempty" example:""`
 }
 
+// UpdateWalletStatusRequest represents an admin request to change a wallet's status
+// @Description Request for changing a wallet's status
+type UpdateWalletStatusRequest struct {
+	Status string `json:"status" validate:"required,oneof=active frozen closed" example:"frozen"`
+	Reason string `json:"reason" validate:"required,min=3,max=500" example:"Suspected account takeover"`
+}
+
+// WalletStatusChange represents a single change of a wallet's status
+// @Description Wallet status change
+type WalletStatusChange struct {
+	FromStatus string    `json:"from_status" example:"active"`
+	ToStatus   string    `json:"to_status" example:"frozen"`
+	Reason     string    `json:"reason" example:"Suspected account takeover"`
+	ChangedBy  *int      `json:"changed_by" example:"1"`
+	CreatedAt  time.Time `json:"created_at" example:"2025-05-16T20:00:00Z"`
+}
+
+// WalletStatusHistoryResponse is the response for the wallet status history endpoint
+// @Description Response for wallet status history
+type WalletStatusHistoryResponse struct {
+	Success bool                 `json:"success" example:"true"`
+	Data    []WalletStatusChange `json:"data,omitempty"`
+	Error   string               `json:"error,omitempty" example:""`
+}
+
 // GenericResponse is a general purpose response
 // @Description Generic API response
 type GenericResponse struct {
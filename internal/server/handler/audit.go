@@ -0,0 +1,198 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	fiberutils "github.com/gofiber/fiber/v2/utils"
+	"go.uber.org/zap"
+)
+
+// AuditHandler records privileged calls and serves the admin endpoints of the audit log
+type AuditHandler struct {
+	auditService service.AuditServiceInterface
+	logger       *zap.Logger
+}
+
+// Compile-time verification that AuditHandler implements AuditHandlerInterface
+var _ AuditHandlerInterface = (*AuditHandler)(nil)
+
+func NewAuditHandler(auditService service.AuditServiceInterface, obs *observability.Observability) *AuditHandler {
+	return &AuditHandler{
+		auditService: auditService,
+		logger:       obs.Logger.Logger.With(zap.String("component", "audit_handler")),
+	}
+}
+
+// auditTarget returns the user whose wallet a call targets: the user_id path parameter, or else the
+// user_id field of a JSON body
+func auditTarget(c *fiber.Ctx, body []byte) *int {
+	if userID, err := strconv.Atoi(c.Params("user_id")); err == nil {
+		return &userID
+	}
+
+	var payload struct {
+		UserID *int `json:"user_id"`
+	}
+	if json.Unmarshal(body, &payload) == nil {
+		return payload.UserID
+	}
+
+	return nil
+}
+
+// RecordPrivilegedCall is a middleware that records the calls of audited roles once they are handled.
+// It must run after the authentication middleware. A call is not undone when it cannot be recorded;
+// the failure is logged instead.
+func (h *AuditHandler) RecordPrivilegedCall(c *fiber.Ctx) error {
+	principalID, _ := c.Locals("user_id").(int)
+	role, _ := c.Locals("user_role").(string)
+	if !h.auditService.IsAudited(role) {
+		return c.Next()
+	}
+
+	body := c.Body()
+	payloadHash := service.HashAuditPayload(body)
+
+	handlerErr := c.Next()
+
+	statusCode := c.Response().StatusCode()
+	if handlerErr != nil {
+		// The error handler writes the response after the middlewares returned
+		statusCode = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(handlerErr, &fiberErr) {
+			statusCode = fiberErr.Code
+		}
+	}
+
+	requestID, _ := c.Locals("requestid").(string)
+	entry := &model.AuditEntry{
+		PrincipalID:   principalID,
+		PrincipalRole: role,
+		Action:        c.Method() + " " + c.Route().Path,
+		Method:        c.Method(),
+		Path:          fiberutils.CopyString(c.OriginalURL()),
+		TargetUserID:  auditTarget(c, body),
+		PayloadHash:   payloadHash,
+		RequestID:     requestID,
+		StatusCode:    statusCode,
+		Outcome:       service.AuditOutcome(statusCode),
+	}
+
+	if _, err := h.auditService.RecordPrivilegedCall(c.Context(), entry); err != nil {
+		h.logger.Error("Failed to record privileged call",
+			zap.String("request_id", requestID),
+			zap.Int("principal_id", principalID),
+			zap.String("action", entry.Action),
+			zap.Int("status_code", statusCode),
+			zap.Error(err))
+	}
+
+	return handlerErr
+}
+
+// ListAuditEntries retrieves entries of the audit log
+//
+//	@Summary		List audit log
+//	@Description	Returns the recorded privileged calls matching the filters, newest first (admin only)
+//	@Tags			admin,audit
+//	@Produce		json
+//	@Param			principal_id	query		int						false	"User ID of the caller"
+//	@Param			target_user_id	query		int						false	"User ID of the targeted wallet"
+//	@Param			action			query		string					false	"Action (e.g. PUT /admin/wallets/:user_id/status)"
+//	@Param			outcome			query		string					false	"Outcome (succeeded, denied, failed)"
+//	@Param			from			query		string					false	"Start of the range, inclusive (RFC 3339 or YYYY-MM-DD)"
+//	@Param			to				query		string					false	"End of the range, exclusive (RFC 3339 or YYYY-MM-DD)"
+//	@Param			limit			query		int						false	"Maximum number of entries"	default(50)
+//	@Param			offset			query		int						false	"Number of entries to skip"	default(0)
+//	@Success		200				{object}	dto.AuditLogResponse	"Audit log entries"
+//	@Failure		400				{object}	dto.AuditLogResponse	"Invalid filter"
+//	@Failure		401				{object}	dto.GenericResponse		"Unauthorized"
+//	@Failure		403				{object}	dto.GenericResponse		"Forbidden"
+//	@Failure		500				{object}	dto.AuditLogResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/audit [get]
+func (h *AuditHandler) ListAuditEntries(c *fiber.Ctx) error {
+	var req dto.AuditLogRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.AuditLogResponse{
+			Success: false,
+			Error:   "Invalid query",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.AuditLogResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	entries, err := h.auditService.ListAuditEntries(c.Context(), &req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAuditFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.AuditLogResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		requestID, _ := c.Locals("requestid").(string)
+		h.logger.Error("Error listing audit entries",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.AuditLogResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.AuditLogResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
+
+// VerifyAuditChain checks the hash chain of the audit log
+//
+//	@Summary		Verify audit log
+//	@Description	Recomputes the hash of every audit log entry and checks that each one links to the entry before it. A broken chain means entries were changed or removed outside the service; broken_at is the first entry affected (admin only)
+//	@Tags			admin,audit
+//	@Produce		json
+//	@Success		200	{object}	dto.AuditVerificationResponse	"Verification result"
+//	@Failure		401	{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403	{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		500	{object}	dto.AuditVerificationResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/audit/verify [get]
+func (h *AuditHandler) VerifyAuditChain(c *fiber.Ctx) error {
+	verification, err := h.auditService.VerifyAuditChain(c.Context())
+	if err != nil {
+		requestID, _ := c.Locals("requestid").(string)
+		h.logger.Error("Error verifying audit chain",
+			zap.String("request_id", requestID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.AuditVerificationResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.AuditVerificationResponse{
+		Success: true,
+		Data:    verification,
+	})
+}
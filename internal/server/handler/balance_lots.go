@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GrantTokens credits promotional tokens to a user's wallet
+//
+//	@Summary		Grant tokens
+//	@Description	Credits promotional tokens to a user's wallet, creating it if needed; tokens with an expiry date are removed when it passes and are spent first (admin only)
+//	@Tags			admin,wallet
+//	@Accept			json
+//	@Produce		json
+//	@Param			user_id	path		int						true	"User ID"
+//	@Param			request	body		dto.GrantTokensRequest	true	"Grant"
+//	@Success		200		{object}	dto.WalletResponse		"Updated wallet"
+//	@Failure		400		{object}	dto.WalletResponse		"Invalid request, unsupported currency or expiry in the past"
+//	@Failure		401		{object}	dto.GenericResponse		"Unauthorized"
+//	@Failure		403		{object}	dto.WalletResponse		"Forbidden or wallet frozen/closed"
+//	@Failure		500		{object}	dto.WalletResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/wallets/{user_id}/grants [post]
+func (h *WalletHandler) GrantTokens(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	var req dto.GrantTokensRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	wallet, err := h.walletService.GrantTokens(c.Context(), userID, &req, adminUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUnsupportedCurrency), errors.Is(err, service.ErrExpiryNotInFuture):
+			return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		case errors.Is(err, service.ErrWalletFrozen), errors.Is(err, service.ErrWalletClosed):
+			return c.Status(fiber.StatusForbidden).JSON(dto.WalletResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Error granting tokens",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	logger.Info("Tokens granted",
+		zap.Int("user_id", userID),
+		zap.Float64("amount", req.Amount),
+		zap.String("currency", req.Currency),
+		zap.String("reference_id", req.ReferenceID))
+
+	return c.JSON(dto.WalletResponse{
+		Success: true,
+		Data:    wallet,
+	})
+}
@@ -0,0 +1,172 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// BulkHandler serves the admin endpoints of bulk credits and spends
+type BulkHandler struct {
+	bulkService service.BulkServiceInterface
+	logger      *zap.Logger
+}
+
+// Compile-time verification that BulkHandler implements BulkHandlerInterface
+var _ BulkHandlerInterface = (*BulkHandler)(nil)
+
+func NewBulkHandler(bulkService service.BulkServiceInterface, obs *observability.Observability) *BulkHandler {
+	return &BulkHandler{
+		bulkService: bulkService,
+		logger:      obs.Logger.Logger.With(zap.String("component", "bulk_handler")),
+	}
+}
+
+// ProcessBulk applies a list of credits and spends
+//
+//	@Summary		Bulk credits and spends
+//	@Description	Applies credits and spends in chunked transactions and reports each item; items whose idempotency key was already applied are reported as duplicates. With async the items are processed in the background and a job is returned (admin only)
+//	@Tags			admin,wallet
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.BulkRequest		true	"Bulk items"
+//	@Success		200		{object}	dto.BulkResponse	"Item results"
+//	@Success		202		{object}	dto.BulkJobResponse	"Background job started"
+//	@Failure		400		{object}	dto.BulkResponse	"Invalid request"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		413		{object}	dto.BulkResponse	"Too many items for the requested mode"
+//	@Failure		500		{object}	dto.BulkResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/bulk [post]
+func (h *BulkHandler) ProcessBulk(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	var req dto.BulkRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BulkResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BulkResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	if req.Async {
+		job, err := h.bulkService.SubmitBulkJob(c.Context(), &req, adminUserID)
+		if err != nil {
+			if errors.Is(err, service.ErrBulkTooLarge) {
+				return c.Status(fiber.StatusRequestEntityTooLarge).JSON(dto.BulkJobResponse{
+					Success: false,
+					Error:   err.Error(),
+				})
+			}
+
+			logger.Error("Error submitting bulk job", zap.Error(err))
+			return c.Status(fiber.StatusInternalServerError).JSON(dto.BulkJobResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		return c.Status(fiber.StatusAccepted).JSON(dto.BulkJobResponse{
+			Success: true,
+			Data:    job,
+		})
+	}
+
+	report, err := h.bulkService.ProcessBulk(c.Context(), &req, adminUserID)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkTooLarge) {
+			return c.Status(fiber.StatusRequestEntityTooLarge).JSON(dto.BulkResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Error processing bulk request", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BulkResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	logger.Info("Bulk request processed",
+		zap.Int("total", report.Total),
+		zap.Int("succeeded", report.Succeeded),
+		zap.Int("failed", report.Failed),
+		zap.Int("duplicates", report.Duplicates))
+
+	return c.JSON(dto.BulkResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetBulkJob retrieves a background bulk job
+//
+//	@Summary		Get bulk job
+//	@Description	Returns the status of a background bulk job and, once it finished, the result of each item (admin only)
+//	@Tags			admin,wallet
+//	@Produce		json
+//	@Param			job_id	path		int					true	"Job ID"
+//	@Success		200		{object}	dto.BulkJobResponse	"Bulk job"
+//	@Failure		400		{object}	dto.BulkJobResponse	"Invalid job ID"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		404		{object}	dto.BulkJobResponse	"Job not found"
+//	@Failure		500		{object}	dto.BulkJobResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/bulk/jobs/{job_id} [get]
+func (h *BulkHandler) GetBulkJob(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil || jobID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.BulkJobResponse{
+			Success: false,
+			Error:   "Invalid job ID format",
+		})
+	}
+
+	job, err := h.bulkService.GetBulkJob(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, service.ErrBulkJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.BulkJobResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		h.logger.Error("Error retrieving bulk job",
+			zap.Int64("job_id", jobID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.BulkJobResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.BulkJobResponse{
+		Success: true,
+		Data:    job,
+	})
+}
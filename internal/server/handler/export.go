@@ -0,0 +1,111 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// exportContentTypes maps export formats to their content types
+var exportContentTypes = map[string]string{
+	service.ExportFormatCSV:    "text/csv; charset=utf-8",
+	service.ExportFormatNDJSON: "application/x-ndjson",
+}
+
+// ExportHandler serves the admin export endpoints
+type ExportHandler struct {
+	exportService service.ExportServiceInterface
+	logger        *zap.Logger
+}
+
+// Compile-time verification that ExportHandler implements ExportHandlerInterface
+var _ ExportHandlerInterface = (*ExportHandler)(nil)
+
+func NewExportHandler(exportService service.ExportServiceInterface, obs *observability.Observability) *ExportHandler {
+	return &ExportHandler{
+		exportService: exportService,
+		logger:        obs.Logger.Logger.With(zap.String("component", "export_handler")),
+	}
+}
+
+// ExportWalletLogs streams wallet logs as CSV or NDJSON
+//
+//	@Summary		Export wallet logs
+//	@Description	Streams the wallet logs matching the filters, oldest first, as CSV with a header row or as NDJSON. The logs are read from one snapshot; a response that ends early was cut off by an error (admin only)
+//	@Tags			admin,logs
+//	@Produce		text/csv
+//	@Produce		application/x-ndjson
+//	@Param			format		query		string				false	"Export format (csv, ndjson)"	default(csv)
+//	@Param			user_id		query		int					false	"User ID"
+//	@Param			game_id		query		string				false	"Game ID"
+//	@Param			token_type	query		string				false	"Token type"
+//	@Param			currency	query		string				false	"Currency"
+//	@Param			source		query		string				false	"Log source (e.g. exchange, market_purchase)"
+//	@Param			from		query		string				false	"Start of the range, inclusive (RFC 3339 or YYYY-MM-DD)"
+//	@Param			to			query		string				false	"End of the range, exclusive (RFC 3339 or YYYY-MM-DD)"
+//	@Success		200			{file}		file				"Wallet logs"
+//	@Failure		400			{object}	dto.GenericResponse	"Invalid filter"
+//	@Failure		401			{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse	"Forbidden"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/exports/wallet-logs [get]
+func (h *ExportHandler) ExportWalletLogs(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	var req dto.WalletLogExportRequest
+	if err := c.QueryParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Invalid query",
+		})
+	}
+
+	if req.Format == "" {
+		req.Format = service.ExportFormatCSV
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	filter, err := service.NewWalletLogFilter(&req)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	filename := fmt.Sprintf("wallet-logs-%s.%s", time.Now().UTC().Format("20060102T150405Z"), req.Format)
+	c.Set(fiber.HeaderContentType, exportContentTypes[req.Format])
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	logger.Info("Exporting wallet logs", zap.String("format", req.Format))
+
+	// The body is written after the handler returned, when the request context is no longer usable
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if _, err := h.exportService.ExportWalletLogs(context.Background(), filter, req.Format, w); err != nil {
+			logger.Error("Wallet log export cut off", zap.Error(err))
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,219 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// FeatureFlagHandler serves the feature flags of users and the admin endpoints that toggle them
+type FeatureFlagHandler struct {
+	featureFlagService service.FeatureFlagServiceInterface
+	logger             *zap.Logger
+}
+
+// Compile-time verification that FeatureFlagHandler implements FeatureFlagHandlerInterface
+var _ FeatureFlagHandlerInterface = (*FeatureFlagHandler)(nil)
+
+func NewFeatureFlagHandler(
+	featureFlagService service.FeatureFlagServiceInterface, obs *observability.Observability) *FeatureFlagHandler {
+	return &FeatureFlagHandler{
+		featureFlagService: featureFlagService,
+		logger:             obs.Logger.Logger.With(zap.String("component", "feature_flag_handler")),
+	}
+}
+
+// GetUserFeatures reports which feature flags are on for a user
+//
+//	@Summary		Get user features
+//	@Description	Returns whether each feature flag is on for a user, so clients only offer the operations the user can make
+//	@Tags			wallet,features
+//	@Produce		json
+//	@Param			user_id	path		int							true	"User ID"
+//	@Param			game_id	query		string						false	"Game the user is playing, for flags targeting games"
+//	@Success		200		{object}	dto.UserFeaturesResponse	"User features"
+//	@Failure		400		{object}	dto.UserFeaturesResponse	"Invalid user ID"
+//	@Failure		401		{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403		{object}	dto.UserFeaturesResponse	"Forbidden"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/features [get]
+func (h *FeatureFlagHandler) GetUserFeatures(c *fiber.Ctx) error {
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.UserFeaturesResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	// Security check: users can only view their own features
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.UserFeaturesResponse{
+			Success: false,
+			Error:   "You can only access your own wallet",
+		})
+	}
+
+	// The caller's role only targets the caller: an admin looking at a user sees what the user sees
+	subject := feature.Subject{UserID: userID, GameID: c.Query("game_id")}
+	if authenticatedUserID == userID {
+		subject.Role = userRole
+	}
+
+	return c.JSON(dto.UserFeaturesResponse{
+		Success: true,
+		Data: &dto.UserFeatures{
+			UserID:   userID,
+			Features: h.featureFlagService.EvaluateFlags(subject),
+		},
+	})
+}
+
+// ListFeatureFlags retrieves the feature flags with their overrides
+//
+//	@Summary		List feature flags
+//	@Description	Returns every feature flag with its effective targeting and override (admin only)
+//	@Tags			admin,features
+//	@Produce		json
+//	@Success		200	{object}	dto.FeatureFlagListResponse	"Feature flags"
+//	@Failure		401	{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403	{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		500	{object}	dto.FeatureFlagListResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/feature-flags [get]
+func (h *FeatureFlagHandler) ListFeatureFlags(c *fiber.Ctx) error {
+	flags, err := h.featureFlagService.ListFeatureFlags(c.Context())
+	if err != nil {
+		h.logger.Error("Error listing feature flags", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.FeatureFlagListResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.FeatureFlagListResponse{
+		Success: true,
+		Data:    flags,
+	})
+}
+
+// SetFeatureFlagOverride overrides the targeting of a feature flag
+//
+//	@Summary		Override feature flag
+//	@Description	Creates or replaces the override of a feature flag; the override replaces the targeting of the feature flags file on every instance within the refresh interval (admin only)
+//	@Tags			admin,features
+//	@Accept			json
+//	@Produce		json
+//	@Param			name	path		string							true	"Feature flag name"
+//	@Param			request	body		dto.UpdateFeatureFlagRequest	true	"Feature flag targeting"
+//	@Success		200		{object}	dto.FeatureFlagResponse			"Overridden feature flag"
+//	@Failure		400		{object}	dto.FeatureFlagResponse			"Invalid request"
+//	@Failure		401		{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		404		{object}	dto.FeatureFlagResponse			"Feature flag not found"
+//	@Failure		500		{object}	dto.FeatureFlagResponse			"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/feature-flags/{name} [put]
+func (h *FeatureFlagHandler) SetFeatureFlagOverride(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	name := c.Params("name")
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID),
+		zap.String("flag", name))
+
+	var req dto.UpdateFeatureFlagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.FeatureFlagResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.FeatureFlagResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	flag, err := h.featureFlagService.SetFeatureFlagOverride(c.Context(), name, &req, adminUserID)
+	if err != nil {
+		if errors.Is(err, service.ErrFeatureFlagNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.FeatureFlagResponse{
+				Success: false,
+				Error:   "Feature flag not found",
+			})
+		}
+
+		logger.Error("Error saving feature flag override", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.FeatureFlagResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.FeatureFlagResponse{
+		Success: true,
+		Data:    flag,
+	})
+}
+
+// DeleteFeatureFlagOverride removes the override of a feature flag
+//
+//	@Summary		Remove feature flag override
+//	@Description	Removes the override of a feature flag so the feature flags file applies again (admin only)
+//	@Tags			admin,features
+//	@Produce		json
+//	@Param			name	path		string				true	"Feature flag name"
+//	@Success		200		{object}	dto.GenericResponse	"Override removed"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		404		{object}	dto.GenericResponse	"No override for feature flag"
+//	@Failure		500		{object}	dto.GenericResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/feature-flags/{name} [delete]
+func (h *FeatureFlagHandler) DeleteFeatureFlagOverride(c *fiber.Ctx) error {
+	name := c.Params("name")
+
+	deleted, err := h.featureFlagService.DeleteFeatureFlagOverride(c.Context(), name)
+	if err != nil {
+		h.logger.Error("Error removing feature flag override",
+			zap.String("flag", name),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Feature flag override not found",
+		})
+	}
+
+	return c.JSON(dto.GenericResponse{Success: true})
+}
@@ -1,10 +1,13 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
 	"strings"
 
+	"github.com/playconomy/wallet-service/internal/model"
 	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
 	"github.com/playconomy/wallet-service/internal/server/dto"
 	"github.com/playconomy/wallet-service/internal/service"
 	"github.com/playconomy/wallet-service/internal/utils"
@@ -19,25 +22,60 @@ var Module = fx.Options(
 	fx.Provide(NewWalletHandler),
 	// Provide interface implementation for dependency injection
 	fx.Provide(func(h *WalletHandler) WalletHandlerInterface { return h }),
+	fx.Provide(NewReconciliationHandler),
+	fx.Provide(func(h *ReconciliationHandler) ReconciliationHandlerInterface { return h }),
+	fx.Provide(NewRiskReviewHandler),
+	fx.Provide(func(h *RiskReviewHandler) RiskReviewHandlerInterface { return h }),
+	fx.Provide(NewBulkHandler),
+	fx.Provide(func(h *BulkHandler) BulkHandlerInterface { return h }),
+	fx.Provide(NewJobHandler),
+	fx.Provide(func(h *JobHandler) JobHandlerInterface { return h }),
+	fx.Provide(NewExportHandler),
+	fx.Provide(func(h *ExportHandler) ExportHandlerInterface { return h }),
+	fx.Provide(NewStatementHandler),
+	fx.Provide(func(h *StatementHandler) StatementHandlerInterface { return h }),
+	fx.Provide(NewSnapshotHandler),
+	fx.Provide(func(h *SnapshotHandler) SnapshotHandlerInterface { return h }),
+	fx.Provide(NewReportHandler),
+	fx.Provide(func(h *ReportHandler) ReportHandlerInterface { return h }),
+	fx.Provide(NewStreamHandler),
+	fx.Provide(func(h *StreamHandler) StreamHandlerInterface { return h }),
+	fx.Provide(NewAuditHandler),
+	fx.Provide(func(h *AuditHandler) AuditHandlerInterface { return h }),
+	fx.Provide(NewFeatureFlagHandler),
+	fx.Provide(func(h *FeatureFlagHandler) FeatureFlagHandlerInterface { return h }),
+	fx.Provide(NewRateLimitHandler),
+	fx.Provide(func(h *RateLimitHandler) RateLimitHandlerInterface { return h }),
+	fx.Provide(NewHealthHandler),
+	fx.Provide(func(h *HealthHandler) HealthHandlerInterface { return h }),
 )
 
 type WalletHandler struct {
 	walletService service.WalletServiceInterface
+	flags         service.FeatureFlagServiceInterface
 	logger        *zap.Logger
-	metrics       *observability.Metrics
+	metrics       *metrics.Metrics
 }
 
 // Compile-time verification that WalletHandler implements WalletHandlerInterface
 var _ WalletHandlerInterface = (*WalletHandler)(nil)
 
-func NewWalletHandler(walletService service.WalletServiceInterface, obs *observability.Observability) *WalletHandler {
+func NewWalletHandler(walletService service.WalletServiceInterface, flags service.FeatureFlagServiceInterface,
+	obs *observability.Observability) *WalletHandler {
 	return &WalletHandler{
 		walletService: walletService,
-		logger:        obs.Logger.With(zap.String("component", "wallet_handler")),
+		flags:         flags,
+		logger:        obs.Logger.Logger.With(zap.String("component", "wallet_handler")),
 		metrics:       obs.Metrics,
 	}
 }
 
+// callerGameID returns the game a game server acts for, from the X-Game-Id header read by the auth middleware
+func callerGameID(c *fiber.Ctx) string {
+	gameID, _ := c.Locals("game_id").(string)
+	return gameID
+}
+
 // GetWallet retrieves wallet information for a user
 //
 //	@Summary		Get wallet information
@@ -45,13 +83,14 @@ func NewWalletHandler(walletService service.WalletServiceInterface, obs *observa
 //	@Tags			wallet
 //	@Accept			json
 //	@Produce		json
-//	@Param			user_id	path		int					true	"User ID"
-//	@Success		200		{object}	dto.WalletResponse	"Wallet information"
-//	@Failure		400		{object}	dto.WalletResponse	"Invalid user ID"
-//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
-//	@Failure		403		{object}	dto.WalletResponse	"Forbidden"
-//	@Failure		404		{object}	dto.WalletResponse	"Wallet not found"
-//	@Failure		500		{object}	dto.WalletResponse	"Server error"
+//	@Param			user_id		path		int					true	"User ID"
+//	@Param			currency	query		string				false	"Currency of the wallet, defaults to the default currency"
+//	@Success		200			{object}	dto.WalletResponse	"Wallet information"
+//	@Failure		400			{object}	dto.WalletResponse	"Invalid user ID or unsupported currency"
+//	@Failure		401			{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403			{object}	dto.WalletResponse	"Forbidden"
+//	@Failure		404			{object}	dto.WalletResponse	"Wallet not found"
+//	@Failure		500			{object}	dto.WalletResponse	"Server error"
 //	@Security		ApiKeyAuth
 //	@Security		ApiEmailAuth
 //	@Security		ApiRoleAuth
@@ -80,13 +119,11 @@ func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
 	}
 
 	// Validate user ID
-	if err := utils.ValidateStruct(&dto.Wallet{UserID: userID}); err != nil {
-		logger.Warn("Invalid user ID", 
-			zap.Int("user_id", userID),
-			zap.Error(err))
+	if userID <= 0 {
+		logger.Warn("Invalid user ID", zap.Int("user_id", userID))
 		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   "Invalid user ID",
 		})
 	}
 
@@ -109,8 +146,19 @@ func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
 		zap.Int("user_id", userID),
 		zap.String("role", userRole))
 
-	wallet, err := h.walletService.GetWalletByUserID(ctx, userID)
+	wallet, err := h.walletService.GetWalletByUserID(ctx, userID, c.Query("currency"))
 	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			logger.Warn("Unsupported currency requested",
+				zap.Int("user_id", userID),
+				zap.Error(err))
+			h.metrics.RecordWalletOperation("view", "invalid_currency")
+			return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
 		logger.Error("Error getting wallet", 
 			zap.Int("user_id", userID),
 			zap.Error(err))
@@ -150,9 +198,11 @@ func (h *WalletHandler) GetWallet(c *fiber.Ctx) error {
 //	@Produce		json
 //	@Param			request	body		dto.ExchangeRequest		true	"Exchange request"
 //	@Success		200		{object}	dto.ExchangeResponse	"Exchange result"
-//	@Failure		400		{object}	dto.ExchangeResponse	"Invalid request or exchange rate not found"
+//	@Success		202		{object}	dto.ExchangeResponse	"Exchange held for manual review"
+//	@Failure		400		{object}	dto.ExchangeResponse	"Invalid request, unsupported currency or exchange rate not found"
 //	@Failure		401		{object}	dto.GenericResponse		"Unauthorized"
-//	@Failure		403		{object}	dto.ExchangeResponse	"Forbidden"
+//	@Failure		403		{object}	dto.ExchangeResponse	"Forbidden, wallet frozen/closed or denied by risk evaluation"
+//	@Failure		422		{object}	dto.ExchangeResponse	"Exchange cap exceeded"
 //	@Failure		500		{object}	dto.ExchangeResponse	"Server error"
 //	@Security		ApiKeyAuth
 //	@Security		ApiEmailAuth
@@ -216,8 +266,59 @@ func (h *WalletHandler) Exchange(c *fiber.Ctx) error {
 		zap.String("token_type", req.TokenType),
 		zap.Float64("amount", req.Amount))
 
-	newBalance, err := h.walletService.Exchange(ctx, &req)
+	principal := model.Principal{UserID: authenticatedUserID, Role: userRole, GameID: callerGameID(c)}
+	newBalance, err := h.walletService.Exchange(model.WithPrincipal(ctx, principal), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrOperationUnderReview) {
+			logger.Warn("Exchange held for manual review",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(fiber.StatusAccepted).JSON(dto.ExchangeResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrOperationDenied) {
+			logger.Warn("Exchange denied by risk evaluation",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(fiber.StatusForbidden).JSON(dto.ExchangeResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrWalletFrozen) || errors.Is(err, service.ErrWalletClosed) {
+			logger.Warn("Exchange rejected by wallet status",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(fiber.StatusForbidden).JSON(dto.ExchangeResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrExchangeCapExceeded) {
+			logger.Warn("Exchange rejected by exchange cap",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.ExchangeResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			logger.Warn("Exchange rejected for unsupported currency",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ExchangeResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
 		if strings.Contains(err.Error(), "exchange rate not found") {
 			logger.Error("Exchange rate not found", 
 				zap.String("game_id", req.GameID),
@@ -245,6 +346,7 @@ func (h *WalletHandler) Exchange(c *fiber.Ctx) error {
 	return c.JSON(dto.ExchangeResponse{
 		Success:    true,
 		NewBalance: newBalance,
+		Currency:   req.Currency,
 	})
 }
 
@@ -257,9 +359,12 @@ func (h *WalletHandler) Exchange(c *fiber.Ctx) error {
 //	@Produce		json
 //	@Param			request	body		dto.SpendRequest	true	"Spend request"
 //	@Success		200		{object}	dto.SpendResponse	"Spend result"
-//	@Failure		400		{object}	dto.SpendResponse	"Invalid request, insufficient funds, or wallet not found"
+//	@Success		202		{object}	dto.SpendResponse	"Spend held for manual review"
+//	@Failure		400		{object}	dto.SpendResponse	"Invalid request, unsupported currency, insufficient funds, or wallet not found"
 //	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
-//	@Failure		403		{object}	dto.SpendResponse	"Forbidden"
+//	@Failure		403		{object}	dto.SpendResponse	"Forbidden, wallet frozen/closed, denied by risk evaluation or spend reason not available"
+//	@Failure		422		{object}	dto.SpendResponse	"Spend limit exceeded"
+//	@Failure		429		{object}	dto.SpendResponse	"Too many spends per minute"
 //	@Failure		500		{object}	dto.SpendResponse	"Server error"
 //	@Security		ApiKeyAuth
 //	@Security		ApiEmailAuth
@@ -295,8 +400,51 @@ func (h *WalletHandler) Spend(c *fiber.Ctx) error {
 		})
 	}
 
-	newBalance, err := h.walletService.Spend(&req)
+	principal := model.Principal{UserID: authenticatedUserID, Role: userRole, GameID: callerGameID(c)}
+	newBalance, err := h.walletService.Spend(model.WithPrincipal(c.Context(), principal), &req)
 	if err != nil {
+		if errors.Is(err, service.ErrOperationUnderReview) {
+			return c.Status(fiber.StatusAccepted).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrOperationDenied) || errors.Is(err, service.ErrFeatureDisabled) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrWalletFrozen) || errors.Is(err, service.ErrWalletClosed) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrSpendVelocityExceeded) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrSpendLimitExceeded) {
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.SpendResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
 		if strings.Contains(err.Error(), "insufficient funds") ||
 			strings.Contains(err.Error(), "wallet not found") {
 			return c.Status(fiber.StatusBadRequest).JSON(dto.SpendResponse{
@@ -314,6 +462,7 @@ func (h *WalletHandler) Spend(c *fiber.Ctx) error {
 	return c.JSON(dto.SpendResponse{
 		Success:    true,
 		NewBalance: newBalance,
+		Currency:   req.Currency,
 	})
 }
 
@@ -348,10 +497,10 @@ func (h *WalletHandler) GetWalletLogs(c *fiber.Ctx) error {
 	}
 
 	// Validate user ID
-	if err := utils.ValidateStruct(&dto.Wallet{UserID: userID}); err != nil {
+	if userID <= 0 {
 		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletLogsResponse{
 			Success: false,
-			Error:   err.Error(),
+			Error:   "Invalid user ID",
 		})
 	}
 
@@ -365,7 +514,7 @@ func (h *WalletHandler) GetWalletLogs(c *fiber.Ctx) error {
 		})
 	}
 
-	logs, err := h.walletService.GetWalletLogs(userID)
+	logs, err := h.walletService.GetWalletLogs(c.Context(), userID)
 	if err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.WalletLogsResponse{
 			Success: false,
@@ -378,3 +527,130 @@ func (h *WalletHandler) GetWalletLogs(c *fiber.Ctx) error {
 		Data:    logs,
 	})
 }
+
+// UpdateWalletStatus changes the status of a user's wallet
+//
+//	@Summary		Change wallet status
+//	@Description	Freezes, reactivates or closes a wallet; a reason is mandatory (admin only)
+//	@Tags			admin,wallet
+//	@Accept			json
+//	@Produce		json
+//	@Param			user_id	path		int								true	"User ID"
+//	@Param			request	body		dto.UpdateWalletStatusRequest	true	"Status change"
+//	@Success		200		{object}	dto.WalletResponse				"Updated wallet"
+//	@Failure		400		{object}	dto.WalletResponse				"Invalid request"
+//	@Failure		401		{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		404		{object}	dto.WalletResponse				"Wallet not found"
+//	@Failure		409		{object}	dto.WalletResponse				"Invalid status transition"
+//	@Failure		500		{object}	dto.WalletResponse				"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/wallets/{user_id}/status [put]
+func (h *WalletHandler) UpdateWalletStatus(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	var req dto.UpdateWalletStatusRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	wallet, err := h.walletService.UpdateWalletStatus(c.Context(), userID, &req, adminUserID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWalletNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(dto.WalletResponse{
+				Success: false,
+				Error:   "Wallet not found",
+			})
+		case errors.Is(err, service.ErrInvalidStatusTransition):
+			return c.Status(fiber.StatusConflict).JSON(dto.WalletResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Error updating wallet status",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.WalletResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	logger.Info("Wallet status updated",
+		zap.Int("user_id", userID),
+		zap.String("status", wallet.Status),
+		zap.String("reason", req.Reason))
+
+	return c.JSON(dto.WalletResponse{
+		Success: true,
+		Data:    wallet,
+	})
+}
+
+// GetWalletStatusHistory retrieves the status changes of a user's wallet
+//
+//	@Summary		Get wallet status history
+//	@Description	Returns every status change of a wallet with its reason, newest first (admin only)
+//	@Tags			admin,wallet
+//	@Produce		json
+//	@Param			user_id	path		int								true	"User ID"
+//	@Success		200		{object}	dto.WalletStatusHistoryResponse	"Status history"
+//	@Failure		400		{object}	dto.WalletStatusHistoryResponse	"Invalid user ID"
+//	@Failure		401		{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		500		{object}	dto.WalletStatusHistoryResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/wallets/{user_id}/status-history [get]
+func (h *WalletHandler) GetWalletStatusHistory(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletStatusHistoryResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	history, err := h.walletService.GetWalletStatusHistory(c.Context(), userID)
+	if err != nil {
+		h.logger.Error("Error getting wallet status history",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.WalletStatusHistoryResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.WalletStatusHistoryResponse{
+		Success: true,
+		Data:    history,
+	})
+}
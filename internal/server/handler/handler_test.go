@@ -5,26 +5,35 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"net/http"
 	"net/http/httptest"
+	"strconv"
 	"testing"
+	"time"
 
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/feature"
 	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
 	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/server/middleware"
 	"github.com/playconomy/wallet-service/internal/service"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.uber.org/zap"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
 )
 
-// MockWalletService is a mock implementation of WalletServiceInterface for testing
+// MockWalletService is a mock implementation of WalletServiceInterface for testing. The context is not
+// recorded with the arguments: it is fiber's pooled request context, which later requests reuse.
 type MockWalletService struct {
 	mock.Mock
 }
 
-func (m *MockWalletService) GetWalletByUserID(ctx context.Context, userID int) (*dto.Wallet, error) {
-	args := m.Called(ctx, userID)
+func (m *MockWalletService) GetWalletByUserID(ctx context.Context, userID int, currency string) (*dto.Wallet, error) {
+	args := m.Called(userID, currency)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -32,56 +41,162 @@ func (m *MockWalletService) GetWalletByUserID(ctx context.Context, userID int) (
 }
 
 func (m *MockWalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest) (float64, error) {
-	args := m.Called(ctx, req)
+	args := m.Called(req)
 	return args.Get(0).(float64), args.Error(1)
 }
 
 func (m *MockWalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float64, error) {
-	args := m.Called(ctx, req)
+	args := m.Called(req)
 	return args.Get(0).(float64), args.Error(1)
 }
 
 func (m *MockWalletService) GetWalletLogs(ctx context.Context, userID int) ([]dto.WalletLogEntry, error) {
-	args := m.Called(ctx, userID)
+	args := m.Called(userID)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
 	return args.Get(0).([]dto.WalletLogEntry), args.Error(1)
 }
 
+func (m *MockWalletService) UpdateWalletStatus(ctx context.Context, userID int, req *dto.UpdateWalletStatusRequest, changedBy int) (*dto.Wallet, error) {
+	args := m.Called(userID, req, changedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Wallet), args.Error(1)
+}
+
+func (m *MockWalletService) GetWalletStatusHistory(ctx context.Context, userID int) ([]dto.WalletStatusChange, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.WalletStatusChange), args.Error(1)
+}
+
+func (m *MockWalletService) GetSpendAllowance(ctx context.Context, userID int, currency string) (*dto.SpendAllowance, error) {
+	args := m.Called(userID, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SpendAllowance), args.Error(1)
+}
+
+func (m *MockWalletService) SetSpendLimitOverride(ctx context.Context, userID int, req *dto.UpdateSpendLimitsRequest, updatedBy int) (*dto.SpendLimitOverride, error) {
+	args := m.Called(userID, req, updatedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.SpendLimitOverride), args.Error(1)
+}
+
+func (m *MockWalletService) DeleteSpendLimitOverride(ctx context.Context, userID int) (bool, error) {
+	args := m.Called(userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockWalletService) GrantTokens(ctx context.Context, userID int, req *dto.GrantTokensRequest, grantedBy int) (*dto.Wallet, error) {
+	args := m.Called(userID, req, grantedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.Wallet), args.Error(1)
+}
+
+func (m *MockWalletService) ReverseExchange(ctx context.Context, req *dto.ReverseExchangeRequest) (*dto.ReverseExchangeResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReverseExchangeResult), args.Error(1)
+}
+
+func (m *MockWalletService) RedeemReverseExchangeGrant(ctx context.Context, token string, redeemedBy int) (*dto.ReverseExchangeGrant, error) {
+	args := m.Called(token, redeemedBy)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ReverseExchangeGrant), args.Error(1)
+}
+
+func (m *MockWalletService) GetReverseExchangeGrants(ctx context.Context, userID int) ([]dto.ReverseExchangeGrant, error) {
+	args := m.Called(userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]dto.ReverseExchangeGrant), args.Error(1)
+}
+
+func (m *MockWalletService) ExpireBalanceLots(ctx context.Context) (*dto.ExpiryReport, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.ExpiryReport), args.Error(1)
+}
+
+func (m *MockWalletService) RefundExpiredReverseExchangeGrants(ctx context.Context) (*dto.GrantRefundReport, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*dto.GrantRefundReport), args.Error(1)
+}
+
 // Compile-time verification that MockWalletService implements WalletServiceInterface
 var _ service.WalletServiceInterface = (*MockWalletService)(nil)
 
-// Setup test app with mocked service
-func setupTestApp(t *testing.T) (*fiber.App, *MockWalletService, WalletHandlerInterface) {
+// newTestFeatureFlags returns a feature flag service defining the given flags, without overrides
+func newTestFeatureFlags(t *testing.T, flags ...*feature.Flag) *service.FeatureFlagService {
+	t.Helper()
+
+	featureFlags, err := service.NewFeatureFlagService(fxtest.NewLifecycle(t), nil, &config.Config{},
+		observability.NewTestObservability())
+	require.NoError(t, err)
+	featureFlags.SetDefinedFlags(flags)
+	return featureFlags
+}
+
+// setupTestApp serves the wallet routes with a mocked service behind the auth middleware
+func setupTestApp(t *testing.T, flags ...*feature.Flag) (*fiber.App, *MockWalletService) {
+	t.Helper()
+
 	app := fiber.New()
 	mockService := new(MockWalletService)
-	
-	// Create test logger
-	logger, _ := zap.NewDevelopment()
-	
-	// Create observability
-	obs := &observability.Observability{
-		Logger: &observability.Logger{Logger: logger},
-	}
-	
-	// Create handler interface
-	handler := NewWalletHandler(mockService, obs)
 
-	// Setup routes
-	app.Get("/:user_id", handler.GetWallet)
-	app.Post("/exchange", handler.Exchange)
-	app.Post("/spend", handler.Spend)
-	app.Get("/:user_id/logs", handler.GetWalletLogs)
+	handler := NewWalletHandler(mockService, newTestFeatureFlags(t, flags...), observability.NewTestObservability())
 
-	return app, mockService, handler
-}
+	// Setup routes the way the router does, with the request ID the handlers log
+	api := app.Group("/", func(c *fiber.Ctx) error {
+		c.Locals("requestid", "test-request-id")
+		return c.Next()
+	}, middleware.AuthMiddleware())
+	api.Get("/:user_id", handler.GetWallet)
+	api.Post("/exchange", handler.Exchange)
+	api.Post("/spend", handler.Spend)
+	api.Post("/exchange/reverse", handler.ReverseExchange)
+	api.Get("/:user_id/logs", handler.GetWalletLogs)
 
 	return app, mockService
 }
 
+// newAuthenticatedRequest creates a request carrying the auth headers of a user with the given role
+func newAuthenticatedRequest(method, target string, body interface{}, userID int, role string) *http.Request {
+	var payload []byte
+	if body != nil {
+		payload, _ = json.Marshal(body)
+	}
+
+	req := httptest.NewRequest(method, target, bytes.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-Id", strconv.Itoa(userID))
+	req.Header.Set("X-User-Email", "user@example.com")
+	req.Header.Set("X-User-Role", role)
+	return req
+}
+
 func TestGetWallet(t *testing.T) {
-	app, mockService, _ := setupTestApp(t)
+	app, mockService := setupTestApp(t)
 
 	t.Run("Success", func(t *testing.T) {
 		// Setup
@@ -90,21 +205,10 @@ func TestGetWallet(t *testing.T) {
 			UserID:  123,
 			Balance: 100.0,
 		}
-		mockService.On("GetWalletByUserID", mock.Anything, 123).Return(wallet, nil).Once()
-
-		// Create request
-		req := httptest.NewRequest("GET", "/123", nil)
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values that would be set by middleware
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("requestid", "test-request-id")
-		ctx.Locals("user_id", 123)
-		ctx.Locals("user_role", "user")
-		ctx.Params().Set("user_id", "123")
+		mockService.On("GetWalletByUserID", 123, "").Return(wallet, nil).Once()
 
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/123", nil, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -120,28 +224,17 @@ func TestGetWallet(t *testing.T) {
 		assert.Equal(t, wallet.ID, response.Data.ID)
 		assert.Equal(t, wallet.UserID, response.Data.UserID)
 		assert.Equal(t, wallet.Balance, response.Data.Balance)
-		
+
 		// Verify that all expected calls were made
 		mockService.AssertExpectations(t)
 	})
-	})
 
 	t.Run("Not Found", func(t *testing.T) {
 		// Setup
-		mockService.On("GetWalletByUserID", 456).Return(nil, nil).Once()
-
-		// Create request
-		req := httptest.NewRequest("GET", "/456", nil)
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 456)
-		ctx.Locals("user_role", "user")
-		ctx.Params().Set("user_id", "456")
+		mockService.On("GetWalletByUserID", 456, "").Return(nil, nil).Once()
 
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/456", nil, 456, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -157,18 +250,8 @@ func TestGetWallet(t *testing.T) {
 	})
 
 	t.Run("Forbidden Access", func(t *testing.T) {
-		// Create request
-		req := httptest.NewRequest("GET", "/789", nil)
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values - user trying to access another user's wallet
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 123) // Authenticated as user 123
-		ctx.Locals("user_role", "user")
-		ctx.Params().Set("user_id", "789") // But trying to access user 789's wallet
-
-		// Execute
-		resp, err := app.Test(req)
+		// Execute - user 123 trying to access user 789's wallet
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/789", nil, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -185,20 +268,10 @@ func TestGetWallet(t *testing.T) {
 
 	t.Run("Server Error", func(t *testing.T) {
 		// Setup
-		mockService.On("GetWalletByUserID", 999).Return(nil, errors.New("database error")).Once()
-
-		// Create request
-		req := httptest.NewRequest("GET", "/999", nil)
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 999)
-		ctx.Locals("user_role", "user")
-		ctx.Params().Set("user_id", "999")
+		mockService.On("GetWalletByUserID", 999, "").Return(nil, errors.New("database error")).Once()
 
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/999", nil, 999, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -221,18 +294,8 @@ func TestExchange(t *testing.T) {
 		mockService.On("Exchange", mock.AnythingOfType("*dto.ExchangeRequest")).
 			Return(250.0, nil).Once()
 
-		// Create request
-		reqBody, _ := json.Marshal(exchangeReq)
-		req := httptest.NewRequest("POST", "/exchange", bytes.NewReader(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 123)
-		ctx.Locals("user_role", "user")
-
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("POST", "/exchange", exchangeReq, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -262,18 +325,8 @@ func TestSpend(t *testing.T) {
 		mockService.On("Spend", mock.AnythingOfType("*dto.SpendRequest")).
 			Return(150.0, nil).Once()
 
-		// Create request
-		reqBody, _ := json.Marshal(spendReq)
-		req := httptest.NewRequest("POST", "/spend", bytes.NewReader(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 123)
-		ctx.Locals("user_role", "user")
-
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("POST", "/spend", spendReq, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -299,18 +352,8 @@ func TestSpend(t *testing.T) {
 		mockService.On("Spend", mock.AnythingOfType("*dto.SpendRequest")).
 			Return(0.0, errors.New("insufficient funds")).Once()
 
-		// Create request
-		reqBody, _ := json.Marshal(spendReq)
-		req := httptest.NewRequest("POST", "/spend", bytes.NewReader(reqBody))
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 123)
-		ctx.Locals("user_role", "user")
-
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("POST", "/spend", spendReq, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -337,18 +380,8 @@ func TestGetWalletLogs(t *testing.T) {
 		}
 		mockService.On("GetWalletLogs", 123).Return(logs, nil).Once()
 
-		// Create request
-		req := httptest.NewRequest("GET", "/123/logs", nil)
-		req.Header.Set("Content-Type", "application/json")
-
-		// Set auth context values
-		ctx := fiber.New().AcquireCtx(req)
-		ctx.Locals("user_id", 123)
-		ctx.Locals("user_role", "user")
-		ctx.Params().Set("user_id", "123")
-
 		// Execute
-		resp, err := app.Test(req)
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/123/logs", nil, 123, "user"))
 
 		// Check response
 		assert.NoError(t, err)
@@ -363,3 +396,99 @@ func TestGetWalletLogs(t *testing.T) {
 		assert.Len(t, response.Data, 2)
 	})
 }
+
+func TestReverseExchangeFeatureFlag(t *testing.T) {
+	// Reverse exchanges are rolled out to game1 only
+	app, mockService := setupTestApp(t, &feature.Flag{
+		Name:    reverseExchangeFlag,
+		Enabled: true,
+		GameIDs: []string{"game1"},
+	})
+
+	newRequest := func(gameID string) *http.Request {
+		return newAuthenticatedRequest("POST", "/exchange/reverse", &dto.ReverseExchangeRequest{
+			UserID:    123,
+			GameID:    gameID,
+			TokenType: "gold",
+			Amount:    15,
+		}, 123, "user")
+	}
+
+	t.Run("Game Targeted By The Flag", func(t *testing.T) {
+		mockService.On("ReverseExchange", mock.MatchedBy(func(req *dto.ReverseExchangeRequest) bool {
+			return req.GameID == "game1"
+		})).Return(&dto.ReverseExchangeResult{
+			NewBalance: 85,
+			Grant:      &dto.ReverseExchangeGrant{GrantID: "grant-1", GameID: "game1"},
+		}, nil).Once()
+
+		resp, err := app.Test(newRequest("game1"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusOK, resp.StatusCode)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("Other Game", func(t *testing.T) {
+		resp, err := app.Test(newRequest("game2"))
+
+		assert.NoError(t, err)
+		assert.Equal(t, fiber.StatusForbidden, resp.StatusCode)
+		mockService.AssertNotCalled(t, "ReverseExchange", mock.MatchedBy(func(req *dto.ReverseExchangeRequest) bool {
+			return req.GameID == "game2"
+		}))
+	})
+}
+
+func TestRateLimitMiddleware(t *testing.T) {
+	newApp := func(t *testing.T) *fiber.App {
+		cfg := &config.Config{Server: config.ServerConfig{RateLimit: config.RateLimitConfig{
+			Enabled:       true,
+			Store:         "memory",
+			Default:       ratelimit.Limit{Requests: 5, Period: time.Minute},
+			IP:            ratelimit.Limit{Requests: 2, Period: time.Minute},
+			ServiceFactor: 1,
+			PruneInterval: time.Minute,
+		}}}
+		obs := observability.NewTestObservability()
+		limiter := NewRateLimitHandler(service.NewRateLimitService(service.RateLimitServiceParams{
+			Lifecycle: fxtest.NewLifecycle(t),
+			Config:    cfg,
+			Obs:       obs,
+		}), obs)
+
+		// Chain the middlewares the way the router does
+		app := fiber.New()
+		api := app.Group("/", limiter.LimitIPRequests, middleware.AuthMiddleware(), limiter.LimitRequests)
+		api.Get("/ping", func(c *fiber.Ctx) error {
+			return c.SendStatus(fiber.StatusNoContent)
+		})
+		return app
+	}
+
+	t.Run("Unauthenticated Requests Are Limited By IP", func(t *testing.T) {
+		app := newApp(t)
+
+		for i := 0; i < 2; i++ {
+			resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+			require.NoError(t, err)
+			assert.Equal(t, fiber.StatusUnauthorized, resp.StatusCode)
+		}
+
+		resp, err := app.Test(httptest.NewRequest("GET", "/ping", nil))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusTooManyRequests, resp.StatusCode)
+		assert.NotEmpty(t, resp.Header.Get(fiber.HeaderRetryAfter))
+	})
+
+	t.Run("Headers Report The Most Constraining Bucket", func(t *testing.T) {
+		app := newApp(t)
+
+		resp, err := app.Test(newAuthenticatedRequest("GET", "/ping", nil, 123, "user"))
+		require.NoError(t, err)
+		assert.Equal(t, fiber.StatusNoContent, resp.StatusCode)
+		// The IP bucket has 1 of 2 tokens left, the principal bucket 4 of 5
+		assert.Equal(t, "2", resp.Header.Get(headerRateLimitLimit))
+		assert.Equal(t, "1", resp.Header.Get(headerRateLimitRemaining))
+	})
+}
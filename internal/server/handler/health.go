@@ -0,0 +1,73 @@
+package handler
+
+import (
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/health"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthHandler serves the liveness and readiness probes
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+// Compile-time verification that HealthHandler implements HealthHandlerInterface
+var _ HealthHandlerInterface = (*HealthHandler)(nil)
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{registry: registry}
+}
+
+// writeHealthReport writes a probe report, with 503 when the probe fails
+func writeHealthReport(c *fiber.Ctx, report *health.Report) error {
+	response := dto.HealthResponse{
+		Status: report.Status,
+		Checks: make([]dto.HealthCheck, 0, len(report.Checks)),
+	}
+	for _, result := range report.Checks {
+		response.Checks = append(response.Checks, dto.HealthCheck{
+			Name:       result.Name,
+			Status:     result.Status,
+			Critical:   result.Critical,
+			Detail:     result.Detail,
+			Error:      result.Error,
+			DurationMs: float64(result.Duration) / float64(time.Millisecond),
+			CheckedAt:  result.CheckedAt,
+		})
+	}
+
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	if report.Failing() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(response)
+	}
+	return c.JSON(response)
+}
+
+// Livez reports whether the process is alive
+//
+//	@Summary		Liveness probe
+//	@Description	Reports whether the process is alive. It does not check dependencies, so a failing database never gets the instance restarted
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	dto.HealthResponse	"Alive"
+//	@Failure		503	{object}	dto.HealthResponse	"A liveness check failed"
+//	@Router			/livez [get]
+func (h *HealthHandler) Livez(c *fiber.Ctx) error {
+	return writeHealthReport(c, h.registry.Live(c.Context()))
+}
+
+// Readyz reports whether the instance can serve requests
+//
+//	@Summary		Readiness probe
+//	@Description	Checks the dependencies of the service: database connectivity, schema version, job backlog, trace exporter and read replicas. Fails when a critical check fails or the service is shutting down; failing non-critical checks report the status degraded. Results are cached for HEALTH_CACHE_TTL
+//	@Tags			health
+//	@Produce		json
+//	@Success		200	{object}	dto.HealthResponse	"Ready or degraded"
+//	@Failure		503	{object}	dto.HealthResponse	"Not ready"
+//	@Router			/readyz [get]
+func (h *HealthHandler) Readyz(c *fiber.Ctx) error {
+	return writeHealthReport(c, h.registry.Ready(c.Context()))
+}
@@ -18,4 +18,163 @@ type WalletHandlerInterface interface {
 	
 	// GetWalletLogs retrieves transaction logs for a user's wallet
 	GetWalletLogs(c *fiber.Ctx) error
+
+	// UpdateWalletStatus changes the status of a user's wallet (admin only)
+	UpdateWalletStatus(c *fiber.Ctx) error
+
+	// GetWalletStatusHistory retrieves the status changes of a user's wallet (admin only)
+	GetWalletStatusHistory(c *fiber.Ctx) error
+
+	// GetSpendAllowance retrieves a user's spend limits and remaining allowance
+	GetSpendAllowance(c *fiber.Ctx) error
+
+	// SetSpendLimitOverride overrides a user's spend limits (admin only)
+	SetSpendLimitOverride(c *fiber.Ctx) error
+
+	// DeleteSpendLimitOverride removes a user's spend limit override (admin only)
+	DeleteSpendLimitOverride(c *fiber.Ctx) error
+
+	// GrantTokens credits promotional tokens to a user's wallet (admin only)
+	GrantTokens(c *fiber.Ctx) error
+
+	// ReverseExchange cashes platform tokens back into a signed grant of game tokens
+	ReverseExchange(c *fiber.Ctx) error
+
+	// RedeemReverseExchangeGrant redeems a reverse exchange grant (game servers and admins only)
+	RedeemReverseExchangeGrant(c *fiber.Ctx) error
+
+	// GetReverseExchangeGrants retrieves the open reverse exchange grants of a user
+	GetReverseExchangeGrants(c *fiber.Ctx) error
+}
+
+// ReconciliationHandlerInterface defines the interface for reconciliation admin handlers
+type ReconciliationHandlerInterface interface {
+	// RunReconciliation triggers a reconciliation run
+	RunReconciliation(c *fiber.Ctx) error
+
+	// GetReconciliationRun retrieves the report of a reconciliation run
+	GetReconciliationRun(c *fiber.Ctx) error
+
+	// ResolveMismatch marks a reconciliation mismatch as reviewed
+	ResolveMismatch(c *fiber.Ctx) error
+}
+
+// BulkHandlerInterface defines the interface for the bulk operation admin handlers
+type BulkHandlerInterface interface {
+	// ProcessBulk applies a list of credits and spends, or starts a background job for them
+	ProcessBulk(c *fiber.Ctx) error
+
+	// GetBulkJob retrieves a background bulk job
+	GetBulkJob(c *fiber.Ctx) error
+}
+
+// RiskReviewHandlerInterface defines the interface for the manual review queue handlers
+type RiskReviewHandlerInterface interface {
+	// ListRiskReviews retrieves the operations held for manual review
+	ListRiskReviews(c *fiber.Ctx) error
+
+	// ApproveRiskReview approves a held operation and executes it
+	ApproveRiskReview(c *fiber.Ctx) error
+
+	// RejectRiskReview rejects a held operation
+	RejectRiskReview(c *fiber.Ctx) error
+}
+
+// JobHandlerInterface defines the interface for the background job admin handlers
+type JobHandlerInterface interface {
+	// ListJobs retrieves background jobs, newest first
+	ListJobs(c *fiber.Ctx) error
+
+	// GetJob retrieves a background job
+	GetJob(c *fiber.Ctx) error
+
+	// CancelJob cancels a queued or running background job
+	CancelJob(c *fiber.Ctx) error
+}
+
+// ExportHandlerInterface defines the interface for the export admin handlers
+type ExportHandlerInterface interface {
+	// ExportWalletLogs streams wallet logs as CSV or NDJSON
+	ExportWalletLogs(c *fiber.Ctx) error
+}
+
+// StatementHandlerInterface defines the interface for the wallet statement handlers
+type StatementHandlerInterface interface {
+	// GetStatement returns a user's monthly wallet statement
+	GetStatement(c *fiber.Ctx) error
+}
+
+// SnapshotHandlerInterface defines the interface for the point-in-time balance handlers
+type SnapshotHandlerInterface interface {
+	// GetBalanceAt returns the balance a user's wallet had at a past point in time
+	GetBalanceAt(c *fiber.Ctx) error
+}
+
+// ReportHandlerInterface defines the interface for the admin reporting handlers
+type ReportHandlerInterface interface {
+	// GetExchangeReport returns the daily exchange volume of each game token
+	GetExchangeReport(c *fiber.Ctx) error
+
+	// GetSpendReport returns the platform tokens spent per reason
+	GetSpendReport(c *fiber.Ctx) error
+
+	// GetSupplyReport returns the net token supply per day
+	GetSupplyReport(c *fiber.Ctx) error
+
+	// GetActiveWalletReport returns the number of active wallets per day
+	GetActiveWalletReport(c *fiber.Ctx) error
+
+	// GetTopWallets returns the wallets with the largest balances
+	GetTopWallets(c *fiber.Ctx) error
+}
+
+// StreamHandlerInterface defines the interface for the wallet event stream handlers
+type StreamHandlerInterface interface {
+	// StreamWallet streams a user's balance changes as Server-Sent Events
+	StreamWallet(c *fiber.Ctx) error
+}
+
+// AuditHandlerInterface defines the interface for the audit log handlers
+type AuditHandlerInterface interface {
+	// RecordPrivilegedCall is a middleware that records the calls of audited roles
+	RecordPrivilegedCall(c *fiber.Ctx) error
+
+	// ListAuditEntries retrieves entries of the audit log
+	ListAuditEntries(c *fiber.Ctx) error
+
+	// VerifyAuditChain checks the hash chain of the audit log
+	VerifyAuditChain(c *fiber.Ctx) error
+}
+
+// FeatureFlagHandlerInterface defines the interface for the feature flag handlers
+type FeatureFlagHandlerInterface interface {
+	// GetUserFeatures reports which feature flags are on for a user
+	GetUserFeatures(c *fiber.Ctx) error
+
+	// ListFeatureFlags retrieves the feature flags with their overrides
+	ListFeatureFlags(c *fiber.Ctx) error
+
+	// SetFeatureFlagOverride overrides the targeting of a feature flag
+	SetFeatureFlagOverride(c *fiber.Ctx) error
+
+	// DeleteFeatureFlagOverride removes the override of a feature flag
+	DeleteFeatureFlagOverride(c *fiber.Ctx) error
+}
+
+// RateLimitHandlerInterface defines the interface for the rate limiting middleware
+type RateLimitHandlerInterface interface {
+	// LimitIPRequests is a middleware that rejects requests over the limit of their IP address
+	LimitIPRequests(c *fiber.Ctx) error
+
+	// LimitRequests is a middleware that rejects requests over the limits of their principal
+	LimitRequests(c *fiber.Ctx) error
+}
+
+// HealthHandlerInterface defines the interface for the probe handlers
+type HealthHandlerInterface interface {
+	// Livez reports whether the process is alive
+	Livez(c *fiber.Ctx) error
+
+	// Readyz reports whether the instance can serve requests
+	Readyz(c *fiber.Ctx) error
 }
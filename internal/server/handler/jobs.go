@@ -0,0 +1,216 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Paging of the job list
+const (
+	defaultJobLimit = 50
+	maxJobLimit     = 500
+)
+
+// JobHandler serves the admin endpoints of background jobs
+type JobHandler struct {
+	jobService service.JobServiceInterface
+	logger     *zap.Logger
+}
+
+// Compile-time verification that JobHandler implements JobHandlerInterface
+var _ JobHandlerInterface = (*JobHandler)(nil)
+
+func NewJobHandler(jobService service.JobServiceInterface, obs *observability.Observability) *JobHandler {
+	return &JobHandler{
+		jobService: jobService,
+		logger:     obs.Logger.Logger.With(zap.String("component", "job_handler")),
+	}
+}
+
+// ListJobs retrieves background jobs
+//
+//	@Summary		List jobs
+//	@Description	Returns background jobs, newest first (admin only)
+//	@Tags			admin,jobs
+//	@Produce		json
+//	@Param			kind	query		string				false	"Job kind (e.g. bulk)"
+//	@Param			status	query		string				false	"Job status (queued, running, succeeded, failed, cancelled)"
+//	@Param			limit	query		int					false	"Maximum number of jobs"	default(50)
+//	@Param			offset	query		int					false	"Number of jobs to skip"	default(0)
+//	@Success		200		{object}	dto.JobListResponse	"Jobs"
+//	@Failure		400		{object}	dto.JobListResponse	"Invalid query"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		500		{object}	dto.JobListResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/jobs [get]
+func (h *JobHandler) ListJobs(c *fiber.Ctx) error {
+	filter := model.JobFilter{
+		Kind:   c.Query("kind"),
+		Status: c.Query("status"),
+		Limit:  c.QueryInt("limit", defaultJobLimit),
+		Offset: c.QueryInt("offset", 0),
+	}
+
+	switch filter.Status {
+	case "", model.JobQueued, model.JobRunning, model.JobSucceeded, model.JobFailed, model.JobCancelled:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.JobListResponse{
+			Success: false,
+			Error:   "Invalid job status",
+		})
+	}
+
+	if filter.Limit <= 0 || filter.Limit > maxJobLimit || filter.Offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.JobListResponse{
+			Success: false,
+			Error:   "Invalid limit or offset",
+		})
+	}
+
+	jobs, err := h.jobService.ListJobs(c.Context(), filter)
+	if err != nil {
+		h.logger.Error("Error listing jobs",
+			zap.String("kind", filter.Kind),
+			zap.String("status", filter.Status),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.JobListResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.JobListResponse{
+		Success: true,
+		Data:    jobs,
+	})
+}
+
+// GetJob retrieves a background job
+//
+//	@Summary		Get job
+//	@Description	Returns a background job with its attempts, lease and result (admin only)
+//	@Tags			admin,jobs
+//	@Produce		json
+//	@Param			job_id	path		int					true	"Job ID"
+//	@Success		200		{object}	dto.JobResponse		"Job"
+//	@Failure		400		{object}	dto.JobResponse		"Invalid job ID"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		404		{object}	dto.JobResponse		"Job not found"
+//	@Failure		500		{object}	dto.JobResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/jobs/{job_id} [get]
+func (h *JobHandler) GetJob(c *fiber.Ctx) error {
+	jobID, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil || jobID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.JobResponse{
+			Success: false,
+			Error:   "Invalid job ID format",
+		})
+	}
+
+	job, err := h.jobService.GetJob(c.Context(), jobID)
+	if err != nil {
+		if errors.Is(err, service.ErrJobNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.JobResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		h.logger.Error("Error retrieving job",
+			zap.Int64("job_id", jobID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.JobResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.JobResponse{
+		Success: true,
+		Data:    job,
+	})
+}
+
+// CancelJob cancels a background job
+//
+//	@Summary		Cancel job
+//	@Description	Cancels a queued job right away; a running job is stopped by its worker at the next heartbeat and keeps the status running until then (admin only)
+//	@Tags			admin,jobs
+//	@Produce		json
+//	@Param			job_id	path		int					true	"Job ID"
+//	@Success		200		{object}	dto.JobResponse		"Cancelled job, or running job flagged for cancellation"
+//	@Failure		400		{object}	dto.JobResponse		"Invalid job ID"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		404		{object}	dto.JobResponse		"Job not found"
+//	@Failure		409		{object}	dto.JobResponse		"Job already finished"
+//	@Failure		500		{object}	dto.JobResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/jobs/{job_id}/cancel [post]
+func (h *JobHandler) CancelJob(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	jobID, err := strconv.ParseInt(c.Params("job_id"), 10, 64)
+	if err != nil || jobID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.JobResponse{
+			Success: false,
+			Error:   "Invalid job ID format",
+		})
+	}
+
+	job, err := h.jobService.CancelJob(c.Context(), jobID)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrJobNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrJobFinished):
+			status = fiber.StatusConflict
+		}
+
+		if status == fiber.StatusInternalServerError {
+			logger.Error("Error cancelling job",
+				zap.Int64("job_id", jobID),
+				zap.Error(err))
+			return c.Status(status).JSON(dto.JobResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		return c.Status(status).JSON(dto.JobResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	logger.Info("Job cancellation requested",
+		zap.Int64("job_id", jobID),
+		zap.String("status", job.Status))
+
+	return c.JSON(dto.JobResponse{
+		Success: true,
+		Data:    job,
+	})
+}
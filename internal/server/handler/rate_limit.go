@@ -0,0 +1,116 @@
+package handler
+
+import (
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Rate limit response headers, as in the IETF draft on RateLimit header fields
+const (
+	headerRateLimitLimit     = "RateLimit-Limit"
+	headerRateLimitRemaining = "RateLimit-Remaining"
+	headerRateLimitReset     = "RateLimit-Reset"
+	headerRateLimitPolicy    = "RateLimit-Policy"
+)
+
+// RateLimitHandler limits the requests of clients
+type RateLimitHandler struct {
+	rateLimitService service.RateLimitServiceInterface
+	logger           *zap.Logger
+}
+
+// Compile-time verification that RateLimitHandler implements RateLimitHandlerInterface
+var _ RateLimitHandlerInterface = (*RateLimitHandler)(nil)
+
+func NewRateLimitHandler(
+	rateLimitService service.RateLimitServiceInterface, obs *observability.Observability) *RateLimitHandler {
+	return &RateLimitHandler{
+		rateLimitService: rateLimitService,
+		logger:           obs.Logger.Logger.With(zap.String("component", "rate_limit_handler")),
+	}
+}
+
+// localIPRateLimit holds the result of the IP bucket of a request, so LimitRequests can report the most
+// constraining bucket
+const localIPRateLimit = "ip_rate_limit"
+
+// LimitIPRequests is a middleware that rejects requests over the limit of their IP address with 429 Too Many
+// Requests. It must run before the authentication middleware, so requests that fail to authenticate are
+// limited as well.
+func (h *RateLimitHandler) LimitIPRequests(c *fiber.Ctx) error {
+	result := h.rateLimitService.Allow(c.Context(), ratelimit.Request{
+		Method: c.Method(),
+		Path:   c.Path(),
+		IP:     c.IP(),
+	})
+	c.Locals(localIPRateLimit, result)
+
+	return h.respond(c, result, 0)
+}
+
+// LimitRequests is a middleware that rejects requests over the limits of their principal with 429 Too Many
+// Requests. It must run after the authentication middleware, which identifies the principal, and after
+// LimitIPRequests. Every limited response carries the RateLimit-* headers of the most constraining bucket.
+func (h *RateLimitHandler) LimitRequests(c *fiber.Ctx) error {
+	userID, _ := c.Locals("user_id").(int)
+	role, _ := c.Locals("user_role").(string)
+
+	result := h.rateLimitService.Allow(c.Context(), ratelimit.Request{
+		Method: c.Method(),
+		Path:   c.Path(),
+		UserID: userID,
+		Role:   role,
+	})
+
+	// Report the IP bucket instead when it has fewer tokens left
+	if ip, ok := c.Locals(localIPRateLimit).(ratelimit.Result); ok && result.Allowed && !ip.Limit.IsZero() &&
+		(result.Limit.IsZero() || ip.Remaining < result.Remaining) {
+		result = ip
+	}
+
+	return h.respond(c, result, userID)
+}
+
+// respond sets the rate limit headers of result, then rejects the request when it is not allowed or passes
+// it on otherwise
+func (h *RateLimitHandler) respond(c *fiber.Ctx, result ratelimit.Result, userID int) error {
+	if !result.Limit.IsZero() {
+		c.Set(headerRateLimitLimit, strconv.Itoa(result.Limit.Requests))
+		c.Set(headerRateLimitRemaining, strconv.Itoa(result.Remaining))
+		c.Set(headerRateLimitReset, strconv.Itoa(ceilSeconds(result.Reset)))
+		c.Set(headerRateLimitPolicy, strconv.Itoa(result.Limit.Requests)+";w="+
+			strconv.Itoa(ceilSeconds(result.Limit.Period)))
+	}
+
+	if !result.Allowed {
+		requestID, _ := c.Locals("requestid").(string)
+		h.logger.Debug("Request rate limited",
+			zap.String("request_id", requestID),
+			zap.String("method", c.Method()),
+			zap.String("path", c.Path()),
+			zap.String("ip", c.IP()),
+			zap.Int("user_id", userID))
+
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(ceilSeconds(result.RetryAfter)))
+		return c.Status(fiber.StatusTooManyRequests).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Too many requests",
+		})
+	}
+
+	return c.Next()
+}
+
+// ceilSeconds rounds a duration up to whole seconds, as the rate limit headers count seconds
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,214 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ReconciliationHandler serves the admin endpoints of the reconciliation job
+type ReconciliationHandler struct {
+	reconciliationService service.ReconciliationServiceInterface
+	logger                *zap.Logger
+}
+
+// Compile-time verification that ReconciliationHandler implements ReconciliationHandlerInterface
+var _ ReconciliationHandlerInterface = (*ReconciliationHandler)(nil)
+
+func NewReconciliationHandler(
+	reconciliationService service.ReconciliationServiceInterface, obs *observability.Observability) *ReconciliationHandler {
+	return &ReconciliationHandler{
+		reconciliationService: reconciliationService,
+		logger:                obs.Logger.Logger.With(zap.String("component", "reconciliation_handler")),
+	}
+}
+
+// RunReconciliation triggers a reconciliation run
+//
+//	@Summary		Run reconciliation
+//	@Description	Compares every wallet balance with its log history and returns the mismatches found (admin only)
+//	@Tags			admin,reconciliation
+//	@Produce		json
+//	@Success		200	{object}	dto.ReconciliationReportResponse	"Reconciliation report"
+//	@Failure		401	{object}	dto.GenericResponse					"Unauthorized"
+//	@Failure		403	{object}	dto.GenericResponse					"Forbidden"
+//	@Failure		409	{object}	dto.ReconciliationReportResponse	"Reconciliation already in progress"
+//	@Failure		500	{object}	dto.ReconciliationReportResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reconciliation/runs [post]
+func (h *ReconciliationHandler) RunReconciliation(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", c.Locals("user_id").(int)))
+
+	logger.Info("Reconciliation run requested")
+
+	report, err := h.reconciliationService.Run(c.Context(), model.ReconciliationTriggerAdmin)
+	if err != nil {
+		if errors.Is(err, service.ErrReconciliationInProgress) {
+			return c.Status(fiber.StatusConflict).JSON(dto.ReconciliationReportResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Reconciliation run failed", zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ReconciliationReportResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.ReconciliationReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetReconciliationRun retrieves the report of a reconciliation run
+//
+//	@Summary		Get reconciliation report
+//	@Description	Returns a reconciliation run and its mismatches; use "latest" for the most recent run (admin only)
+//	@Tags			admin,reconciliation
+//	@Produce		json
+//	@Param			run_id	path		string								true	"Run ID or latest"
+//	@Success		200		{object}	dto.ReconciliationReportResponse	"Reconciliation report"
+//	@Failure		400		{object}	dto.ReconciliationReportResponse	"Invalid run ID"
+//	@Failure		401		{object}	dto.GenericResponse					"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse					"Forbidden"
+//	@Failure		404		{object}	dto.ReconciliationReportResponse	"Run not found"
+//	@Failure		500		{object}	dto.ReconciliationReportResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reconciliation/runs/{run_id} [get]
+func (h *ReconciliationHandler) GetReconciliationRun(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(zap.String("request_id", requestID))
+
+	var report *dto.ReconciliationReport
+	var err error
+
+	if c.Params("run_id") == "latest" {
+		report, err = h.reconciliationService.GetLatestReport(c.Context())
+	} else {
+		runID, parseErr := strconv.ParseInt(c.Params("run_id"), 10, 64)
+		if parseErr != nil || runID <= 0 {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ReconciliationReportResponse{
+				Success: false,
+				Error:   "Invalid run ID format",
+			})
+		}
+		report, err = h.reconciliationService.GetReport(c.Context(), runID)
+	}
+
+	if err != nil {
+		logger.Error("Error getting reconciliation report",
+			zap.String("run_id", c.Params("run_id")),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ReconciliationReportResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	if report == nil {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ReconciliationReportResponse{
+			Success: false,
+			Error:   "Reconciliation run not found",
+		})
+	}
+
+	return c.JSON(dto.ReconciliationReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// ResolveMismatch marks a reconciliation mismatch as reviewed
+//
+//	@Summary		Resolve reconciliation mismatch
+//	@Description	Marks a mismatch as reviewed and optionally unfreezes the wallet once no open mismatches remain (admin only)
+//	@Tags			admin,reconciliation
+//	@Accept			json
+//	@Produce		json
+//	@Param			mismatch_id	path		int									true	"Mismatch ID"
+//	@Param			request		body		dto.ResolveMismatchRequest			true	"Resolution"
+//	@Success		200			{object}	dto.ReconciliationMismatchResponse	"Resolved mismatch"
+//	@Failure		400			{object}	dto.ReconciliationMismatchResponse	"Invalid request"
+//	@Failure		401			{object}	dto.GenericResponse					"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse					"Forbidden"
+//	@Failure		404			{object}	dto.ReconciliationMismatchResponse	"Mismatch not found or already resolved"
+//	@Failure		500			{object}	dto.ReconciliationMismatchResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reconciliation/mismatches/{mismatch_id}/resolve [post]
+func (h *ReconciliationHandler) ResolveMismatch(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	mismatchID, err := strconv.ParseInt(c.Params("mismatch_id"), 10, 64)
+	if err != nil || mismatchID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReconciliationMismatchResponse{
+			Success: false,
+			Error:   "Invalid mismatch ID format",
+		})
+	}
+
+	var req dto.ResolveMismatchRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReconciliationMismatchResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReconciliationMismatchResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	mismatch, err := h.reconciliationService.ResolveMismatch(c.Context(), mismatchID, adminUserID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrMismatchNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.ReconciliationMismatchResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Error resolving reconciliation mismatch",
+			zap.Int64("mismatch_id", mismatchID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ReconciliationMismatchResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	logger.Info("Reconciliation mismatch resolved",
+		zap.Int64("mismatch_id", mismatchID),
+		zap.Bool("unfreeze", req.Unfreeze))
+
+	return c.JSON(dto.ReconciliationMismatchResponse{
+		Success: true,
+		Data:    mismatch,
+	})
+}
@@ -0,0 +1,254 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// ReportHandler serves the admin reporting endpoints
+type ReportHandler struct {
+	reportService service.ReportServiceInterface
+	logger        *zap.Logger
+}
+
+// Compile-time verification that ReportHandler implements ReportHandlerInterface
+var _ ReportHandlerInterface = (*ReportHandler)(nil)
+
+func NewReportHandler(reportService service.ReportServiceInterface, obs *observability.Observability) *ReportHandler {
+	return &ReportHandler{
+		reportService: reportService,
+		logger:        obs.Logger.Logger.With(zap.String("component", "report_handler")),
+	}
+}
+
+// parseReportQuery parses and validates the query of a report request into req
+func parseReportQuery(c *fiber.Ctx, req interface{}) error {
+	if err := c.QueryParser(req); err != nil {
+		return errors.New("invalid query")
+	}
+	return utils.ValidateStruct(req)
+}
+
+// reportError writes the response for an error of the report service
+func (h *ReportHandler) reportError(c *fiber.Ctx, report string, err error) error {
+	status := fiber.StatusInternalServerError
+	switch {
+	case errors.Is(err, service.ErrInvalidReportRange), errors.Is(err, service.ErrUnsupportedCurrency):
+		status = fiber.StatusBadRequest
+	}
+
+	if status == fiber.StatusInternalServerError {
+		requestID, _ := c.Locals("requestid").(string)
+		h.logger.Error("Error building report",
+			zap.String("request_id", requestID),
+			zap.String("report", report),
+			zap.Error(err))
+		return c.Status(status).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.Status(status).JSON(dto.GenericResponse{
+		Success: false,
+		Error:   err.Error(),
+	})
+}
+
+// GetExchangeReport returns the daily exchange volume of each game token
+//
+//	@Summary		Exchange volume report
+//	@Description	Returns the game tokens exchanged into platform tokens per game, token type and day, from the daily rollups. Logs created after rolled_up_through are not included yet (admin only)
+//	@Tags			admin,reports
+//	@Produce		json
+//	@Param			currency	query		string						false	"Currency, defaults to the default currency"
+//	@Param			from		query		string						false	"First day, inclusive (YYYY-MM-DD), defaults to 30 days before to"
+//	@Param			to			query		string						false	"Last day, exclusive (YYYY-MM-DD), defaults to tomorrow"
+//	@Param			game_id		query		string						false	"Game ID"
+//	@Param			token_type	query		string						false	"Token type"
+//	@Success		200			{object}	dto.ExchangeReportResponse	"Exchange volume report"
+//	@Failure		400			{object}	dto.GenericResponse			"Invalid range or currency"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		500			{object}	dto.GenericResponse			"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reports/exchanges [get]
+func (h *ReportHandler) GetExchangeReport(c *fiber.Ctx) error {
+	var req dto.ReportRequest
+	if err := parseReportQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	report, err := h.reportService.GetExchangeReport(c.Context(), &req)
+	if err != nil {
+		return h.reportError(c, "exchanges", err)
+	}
+
+	return c.JSON(dto.ExchangeReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetSpendReport returns the platform tokens spent per reason
+//
+//	@Summary		Spend report
+//	@Description	Returns the platform tokens spent per reason within the range, largest first, from the daily rollups. Exchanges, bonuses, expiries and reverse exchanges are not spends (admin only)
+//	@Tags			admin,reports
+//	@Produce		json
+//	@Param			currency	query		string					false	"Currency, defaults to the default currency"
+//	@Param			from		query		string					false	"First day, inclusive (YYYY-MM-DD), defaults to 30 days before to"
+//	@Param			to			query		string					false	"Last day, exclusive (YYYY-MM-DD), defaults to tomorrow"
+//	@Success		200			{object}	dto.SpendReportResponse	"Spend report"
+//	@Failure		400			{object}	dto.GenericResponse		"Invalid range or currency"
+//	@Failure		401			{object}	dto.GenericResponse		"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse		"Forbidden"
+//	@Failure		500			{object}	dto.GenericResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reports/spend [get]
+func (h *ReportHandler) GetSpendReport(c *fiber.Ctx) error {
+	var req dto.ReportRequest
+	if err := parseReportQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	report, err := h.reportService.GetSpendReport(c.Context(), &req)
+	if err != nil {
+		return h.reportError(c, "spend", err)
+	}
+
+	return c.JSON(dto.SpendReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetSupplyReport returns the net token supply per day
+//
+//	@Summary		Token supply report
+//	@Description	Returns the platform tokens credited and debited per day and the total supply at the end of each day, from the daily rollups (admin only)
+//	@Tags			admin,reports
+//	@Produce		json
+//	@Param			currency	query		string						false	"Currency, defaults to the default currency"
+//	@Param			from		query		string						false	"First day, inclusive (YYYY-MM-DD), defaults to 30 days before to"
+//	@Param			to			query		string						false	"Last day, exclusive (YYYY-MM-DD), defaults to tomorrow"
+//	@Success		200			{object}	dto.SupplyReportResponse	"Token supply report"
+//	@Failure		400			{object}	dto.GenericResponse			"Invalid range or currency"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		500			{object}	dto.GenericResponse			"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reports/supply [get]
+func (h *ReportHandler) GetSupplyReport(c *fiber.Ctx) error {
+	var req dto.ReportRequest
+	if err := parseReportQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	report, err := h.reportService.GetSupplyReport(c.Context(), &req)
+	if err != nil {
+		return h.reportError(c, "supply", err)
+	}
+
+	return c.JSON(dto.SupplyReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetActiveWalletReport returns the number of active wallets per day
+//
+//	@Summary		Active wallet report
+//	@Description	Returns the number of wallets with at least one log per day, from the daily rollups (admin only)
+//	@Tags			admin,reports
+//	@Produce		json
+//	@Param			currency	query		string							false	"Currency, defaults to the default currency"
+//	@Param			from		query		string							false	"First day, inclusive (YYYY-MM-DD), defaults to 30 days before to"
+//	@Param			to			query		string							false	"Last day, exclusive (YYYY-MM-DD), defaults to tomorrow"
+//	@Success		200			{object}	dto.ActiveWalletReportResponse	"Active wallet report"
+//	@Failure		400			{object}	dto.GenericResponse				"Invalid range or currency"
+//	@Failure		401			{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		500			{object}	dto.GenericResponse				"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reports/active-wallets [get]
+func (h *ReportHandler) GetActiveWalletReport(c *fiber.Ctx) error {
+	var req dto.ReportRequest
+	if err := parseReportQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	report, err := h.reportService.GetActiveWalletReport(c.Context(), &req)
+	if err != nil {
+		return h.reportError(c, "active_wallets", err)
+	}
+
+	return c.JSON(dto.ActiveWalletReportResponse{
+		Success: true,
+		Data:    report,
+	})
+}
+
+// GetTopWallets returns the wallets with the largest balances
+//
+//	@Summary		Top wallet report
+//	@Description	Returns the wallets of a currency with the largest current balances (admin only)
+//	@Tags			admin,reports
+//	@Produce		json
+//	@Param			currency	query		string					false	"Currency, defaults to the default currency"
+//	@Param			limit		query		int						false	"Number of wallets (1-100)"	default(10)
+//	@Success		200			{object}	dto.TopWalletsResponse	"Top wallets"
+//	@Failure		400			{object}	dto.GenericResponse		"Invalid limit or currency"
+//	@Failure		401			{object}	dto.GenericResponse		"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse		"Forbidden"
+//	@Failure		500			{object}	dto.GenericResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reports/top-wallets [get]
+func (h *ReportHandler) GetTopWallets(c *fiber.Ctx) error {
+	var req dto.TopWalletsRequest
+	if err := parseReportQuery(c, &req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	wallets, err := h.reportService.GetTopWallets(c.Context(), &req)
+	if err != nil {
+		return h.reportError(c, "top_wallets", err)
+	}
+
+	return c.JSON(dto.TopWalletsResponse{
+		Success: true,
+		Data:    wallets,
+	})
+}
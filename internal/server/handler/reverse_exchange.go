@@ -0,0 +1,288 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// reverseExchangeFlag rolls out reverse exchanges, for instance one game at a time through its game_ids.
+// Reverse exchanges are available to all when the flag is not defined.
+const reverseExchangeFlag = "reverse_exchange"
+
+// ReverseExchange cashes platform tokens back into game tokens
+//
+//	@Summary		Reverse exchange
+//	@Description	Debits platform tokens from the wallet and returns a signed grant of game tokens that the game server redeems
+//	@Tags			wallet,exchange
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.ReverseExchangeRequest	true	"Reverse exchange request"
+//	@Success		200		{object}	dto.ReverseExchangeResponse	"Reverse exchange result with the grant"
+//	@Success		202		{object}	dto.ReverseExchangeResponse	"Reverse exchange held for manual review"
+//	@Failure		400		{object}	dto.ReverseExchangeResponse	"Invalid request, unsupported currency, no reverse rate, wallet not found or insufficient funds"
+//	@Failure		401		{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403		{object}	dto.ReverseExchangeResponse	"Forbidden, reverse exchange off for the user or game, wallet frozen/closed or denied by risk evaluation"
+//	@Failure		404		{object}	dto.ReverseExchangeResponse	"Reverse exchange disabled"
+//	@Failure		422		{object}	dto.ReverseExchangeResponse	"Reverse exchange cap exceeded"
+//	@Failure		500		{object}	dto.ReverseExchangeResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/exchange/reverse [post]
+func (h *WalletHandler) ReverseExchange(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(zap.String("request_id", requestID))
+
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	var req dto.ReverseExchangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		h.metrics.RecordWalletOperation("reverse_exchange", "invalid_body")
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReverseExchangeResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		h.metrics.RecordWalletOperation("reverse_exchange", "validation_failed")
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReverseExchangeResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	// Security check: users can only exchange back from their own wallet
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != req.UserID && userRole != "admin" {
+		logger.Warn("Unauthorized reverse exchange attempt",
+			zap.Int("authenticated_user_id", authenticatedUserID),
+			zap.Int("requested_user_id", req.UserID),
+			zap.String("role", userRole))
+		return c.Status(fiber.StatusForbidden).JSON(dto.ReverseExchangeResponse{
+			Success: false,
+			Error:   "You can only exchange from your own wallet",
+		})
+	}
+
+	// The caller's role only targets the caller: an admin acting for a user gets what the user gets
+	subject := feature.Subject{UserID: req.UserID, GameID: req.GameID}
+	if authenticatedUserID == req.UserID {
+		subject.Role = userRole
+	}
+	if !h.flags.Allows(reverseExchangeFlag, subject) {
+		logger.Warn("Reverse exchange rejected by feature flag",
+			zap.Int("user_id", req.UserID),
+			zap.String("game_id", req.GameID))
+		h.metrics.RecordWalletOperation("reverse_exchange", "error_feature_disabled")
+		return c.Status(fiber.StatusForbidden).JSON(dto.ReverseExchangeResponse{
+			Success: false,
+			Error:   "Reverse exchange is not available for this game",
+		})
+	}
+
+	principal := model.Principal{UserID: authenticatedUserID, Role: userRole, GameID: callerGameID(c)}
+	result, err := h.walletService.ReverseExchange(model.WithPrincipal(c.Context(), principal), &req)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrOperationUnderReview):
+			status = fiber.StatusAccepted
+		case errors.Is(err, service.ErrOperationDenied),
+			errors.Is(err, service.ErrWalletFrozen), errors.Is(err, service.ErrWalletClosed):
+			status = fiber.StatusForbidden
+		case errors.Is(err, service.ErrReverseExchangeDisabled):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrExchangeCapExceeded):
+			status = fiber.StatusUnprocessableEntity
+		case errors.Is(err, service.ErrUnsupportedCurrency), errors.Is(err, service.ErrReverseExchangeNotSupported),
+			errors.Is(err, service.ErrWalletNotFound), errors.Is(err, service.ErrInsufficientFunds):
+			status = fiber.StatusBadRequest
+		}
+
+		if status == fiber.StatusInternalServerError {
+			logger.Error("Reverse exchange operation failed",
+				zap.Int("user_id", req.UserID),
+				zap.Error(err))
+			return c.Status(status).JSON(dto.ReverseExchangeResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		logger.Warn("Reverse exchange rejected",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		return c.Status(status).JSON(dto.ReverseExchangeResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	logger.Info("Reverse exchange successful",
+		zap.Int("user_id", req.UserID),
+		zap.String("grant_id", result.Grant.GrantID),
+		zap.Float64("new_balance", result.NewBalance))
+
+	return c.JSON(dto.ReverseExchangeResponse{
+		Success:    true,
+		NewBalance: result.NewBalance,
+		Currency:   req.Currency,
+		Grant:      result.Grant,
+	})
+}
+
+// RedeemReverseExchangeGrant redeems a grant issued by a reverse exchange
+//
+//	@Summary		Redeem reverse exchange grant
+//	@Description	Verifies a grant token and marks the grant redeemed; each grant can be redeemed once before it expires (game servers of the grant's game, identified by X-Game-Id, and admins only)
+//	@Tags			exchange
+//	@Accept			json
+//	@Produce		json
+//	@Param			request	body		dto.RedeemGrantRequest				true	"Grant token"
+//	@Success		200		{object}	dto.ReverseExchangeGrantResponse	"Redeemed grant; credit its game tokens"
+//	@Failure		400		{object}	dto.ReverseExchangeGrantResponse	"Invalid request or grant token"
+//	@Failure		401		{object}	dto.GenericResponse					"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse					"Forbidden, or grant issued for another game"
+//	@Failure		404		{object}	dto.ReverseExchangeGrantResponse	"Grant not found"
+//	@Failure		409		{object}	dto.ReverseExchangeGrantResponse	"Grant already redeemed"
+//	@Failure		410		{object}	dto.ReverseExchangeGrantResponse	"Grant expired"
+//	@Failure		500		{object}	dto.ReverseExchangeGrantResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/exchange/reverse/redeem [post]
+func (h *WalletHandler) RedeemReverseExchangeGrant(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	redeemedBy := c.Locals("user_id").(int)
+	userRole := c.Locals("user_role").(string)
+	gameID := callerGameID(c)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("redeemed_by", redeemedBy),
+		zap.String("game_id", gameID))
+
+	var req dto.RedeemGrantRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReverseExchangeGrantResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReverseExchangeGrantResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	principal := model.Principal{UserID: redeemedBy, Role: userRole, GameID: gameID}
+	redeemed, err := h.walletService.RedeemReverseExchangeGrant(model.WithPrincipal(c.Context(), principal),
+		req.Token, redeemedBy)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidGrant):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, service.ErrGrantGameMismatch):
+			status = fiber.StatusForbidden
+		case errors.Is(err, service.ErrGrantNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrGrantAlreadyRedeemed):
+			status = fiber.StatusConflict
+		case errors.Is(err, service.ErrGrantExpired):
+			status = fiber.StatusGone
+		}
+
+		if status == fiber.StatusInternalServerError {
+			logger.Error("Error redeeming grant", zap.Error(err))
+			return c.Status(status).JSON(dto.ReverseExchangeGrantResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		logger.Warn("Grant redemption rejected", zap.Error(err))
+		return c.Status(status).JSON(dto.ReverseExchangeGrantResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	logger.Info("Grant redeemed",
+		zap.String("grant_id", redeemed.GrantID),
+		zap.Int("user_id", redeemed.UserID))
+
+	return c.JSON(dto.ReverseExchangeGrantResponse{
+		Success: true,
+		Data:    redeemed,
+	})
+}
+
+// GetReverseExchangeGrants retrieves the open reverse exchange grants of a user
+//
+//	@Summary		Get open reverse exchange grants
+//	@Description	Returns the grants of a user that are neither redeemed nor expired, with their tokens
+//	@Tags			wallet,exchange
+//	@Produce		json
+//	@Param			user_id	path		int									true	"User ID"
+//	@Success		200		{object}	dto.ReverseExchangeGrantsResponse	"Open grants"
+//	@Failure		400		{object}	dto.ReverseExchangeGrantsResponse	"Invalid user ID"
+//	@Failure		401		{object}	dto.GenericResponse					"Unauthorized"
+//	@Failure		403		{object}	dto.ReverseExchangeGrantsResponse	"Forbidden"
+//	@Failure		500		{object}	dto.ReverseExchangeGrantsResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/reverse-grants [get]
+func (h *WalletHandler) GetReverseExchangeGrants(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(zap.String("request_id", requestID))
+
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ReverseExchangeGrantsResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	// Security check: users can only view their own grants
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.ReverseExchangeGrantsResponse{
+			Success: false,
+			Error:   "You can only access your own grants",
+		})
+	}
+
+	grants, err := h.walletService.GetReverseExchangeGrants(c.Context(), userID)
+	if err != nil {
+		logger.Error("Error retrieving reverse exchange grants",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ReverseExchangeGrantsResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.ReverseExchangeGrantsResponse{
+		Success: true,
+		Data:    grants,
+	})
+}
@@ -0,0 +1,201 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// Paging of the risk review queue
+const (
+	defaultRiskReviewLimit = 50
+	maxRiskReviewLimit     = 500
+)
+
+// RiskReviewHandler serves the admin endpoints of the manual review queue
+type RiskReviewHandler struct {
+	riskReviewService service.RiskReviewServiceInterface
+	logger            *zap.Logger
+}
+
+// Compile-time verification that RiskReviewHandler implements RiskReviewHandlerInterface
+var _ RiskReviewHandlerInterface = (*RiskReviewHandler)(nil)
+
+func NewRiskReviewHandler(
+	riskReviewService service.RiskReviewServiceInterface, obs *observability.Observability) *RiskReviewHandler {
+	return &RiskReviewHandler{
+		riskReviewService: riskReviewService,
+		logger:            obs.Logger.Logger.With(zap.String("component", "risk_review_handler")),
+	}
+}
+
+// ListRiskReviews retrieves the operations held for manual review
+//
+//	@Summary		List risk reviews
+//	@Description	Returns operations held by the risk evaluator, oldest first (admin only)
+//	@Tags			admin,risk
+//	@Produce		json
+//	@Param			status	query		string						false	"Review status (pending, approved, rejected, failed)"	default(pending)
+//	@Param			limit	query		int							false	"Maximum number of reviews"								default(50)
+//	@Param			offset	query		int							false	"Number of reviews to skip"								default(0)
+//	@Success		200		{object}	dto.RiskReviewListResponse	"Risk reviews"
+//	@Failure		400		{object}	dto.RiskReviewListResponse	"Invalid query"
+//	@Failure		401		{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		500		{object}	dto.RiskReviewListResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reviews [get]
+func (h *RiskReviewHandler) ListRiskReviews(c *fiber.Ctx) error {
+	status := c.Query("status", model.RiskReviewPending)
+	switch status {
+	case model.RiskReviewPending, model.RiskReviewApproved, model.RiskReviewRejected, model.RiskReviewFailed:
+	default:
+		return c.Status(fiber.StatusBadRequest).JSON(dto.RiskReviewListResponse{
+			Success: false,
+			Error:   "Invalid review status",
+		})
+	}
+
+	limit := c.QueryInt("limit", defaultRiskReviewLimit)
+	offset := c.QueryInt("offset", 0)
+	if limit <= 0 || limit > maxRiskReviewLimit || offset < 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.RiskReviewListResponse{
+			Success: false,
+			Error:   "Invalid limit or offset",
+		})
+	}
+
+	reviews, err := h.riskReviewService.ListReviews(c.Context(), status, limit, offset)
+	if err != nil {
+		h.logger.Error("Error listing risk reviews",
+			zap.String("status", status),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.RiskReviewListResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.RiskReviewListResponse{
+		Success: true,
+		Data:    reviews,
+	})
+}
+
+// ApproveRiskReview approves a held operation and executes it
+//
+//	@Summary		Approve risk review
+//	@Description	Approves a held operation and executes it; if execution fails the review is marked as failed (admin only)
+//	@Tags			admin,risk
+//	@Accept			json
+//	@Produce		json
+//	@Param			review_id	path		int							true	"Review ID"
+//	@Param			request		body		dto.DecideRiskReviewRequest	true	"Decision"
+//	@Success		200			{object}	dto.RiskReviewResponse		"Decided review"
+//	@Failure		400			{object}	dto.RiskReviewResponse		"Invalid request"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		404			{object}	dto.RiskReviewResponse		"Review not found or already decided"
+//	@Failure		500			{object}	dto.RiskReviewResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reviews/{review_id}/approve [post]
+func (h *RiskReviewHandler) ApproveRiskReview(c *fiber.Ctx) error {
+	return h.decide(c, h.riskReviewService.ApproveReview)
+}
+
+// RejectRiskReview rejects a held operation
+//
+//	@Summary		Reject risk review
+//	@Description	Rejects a held operation; it is never executed (admin only)
+//	@Tags			admin,risk
+//	@Accept			json
+//	@Produce		json
+//	@Param			review_id	path		int							true	"Review ID"
+//	@Param			request		body		dto.DecideRiskReviewRequest	true	"Decision"
+//	@Success		200			{object}	dto.RiskReviewResponse		"Decided review"
+//	@Failure		400			{object}	dto.RiskReviewResponse		"Invalid request"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.GenericResponse			"Forbidden"
+//	@Failure		404			{object}	dto.RiskReviewResponse		"Review not found or already decided"
+//	@Failure		500			{object}	dto.RiskReviewResponse		"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/reviews/{review_id}/reject [post]
+func (h *RiskReviewHandler) RejectRiskReview(c *fiber.Ctx) error {
+	return h.decide(c, h.riskReviewService.RejectReview)
+}
+
+type riskReviewDecision func(ctx context.Context, reviewID int64, reviewedBy int,
+	req *dto.DecideRiskReviewRequest) (*dto.RiskReview, error)
+
+func (h *RiskReviewHandler) decide(c *fiber.Ctx, decision riskReviewDecision) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	reviewID, err := strconv.ParseInt(c.Params("review_id"), 10, 64)
+	if err != nil || reviewID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.RiskReviewResponse{
+			Success: false,
+			Error:   "Invalid review ID format",
+		})
+	}
+
+	var req dto.DecideRiskReviewRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.RiskReviewResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.RiskReviewResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	review, err := decision(c.Context(), reviewID, adminUserID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrRiskReviewNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(dto.RiskReviewResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		logger.Error("Error deciding risk review",
+			zap.Int64("review_id", reviewID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.RiskReviewResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	logger.Info("Risk review decided",
+		zap.Int64("review_id", reviewID),
+		zap.String("status", review.Status))
+
+	return c.JSON(dto.RiskReviewResponse{
+		Success: true,
+		Data:    review,
+	})
+}
@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// SnapshotHandler serves the point-in-time balance endpoint
+type SnapshotHandler struct {
+	snapshotService service.SnapshotServiceInterface
+	logger          *zap.Logger
+}
+
+// Compile-time verification that SnapshotHandler implements SnapshotHandlerInterface
+var _ SnapshotHandlerInterface = (*SnapshotHandler)(nil)
+
+func NewSnapshotHandler(snapshotService service.SnapshotServiceInterface, obs *observability.Observability) *SnapshotHandler {
+	return &SnapshotHandler{
+		snapshotService: snapshotService,
+		logger:          obs.Logger.Logger.With(zap.String("component", "snapshot_handler")),
+	}
+}
+
+// GetBalanceAt returns the balance a user's wallet had at a past point in time
+//
+//	@Summary		Get balance at a point in time
+//	@Description	Returns the balance of a wallet at a past time, computed from the nearest earlier balance snapshot and the logs after it
+//	@Tags			wallet
+//	@Produce		json
+//	@Param			user_id		path		int								true	"User ID"
+//	@Param			at			query		string							false	"Point in time (RFC 3339 or YYYY-MM-DD for midnight UTC), defaults to now"
+//	@Param			currency	query		string							false	"Currency of the wallet, defaults to the default currency"
+//	@Success		200			{object}	dto.PointInTimeBalanceResponse	"Balance at the point in time"
+//	@Failure		400			{object}	dto.PointInTimeBalanceResponse	"Invalid user ID, time or currency"
+//	@Failure		401			{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403			{object}	dto.PointInTimeBalanceResponse	"Forbidden"
+//	@Failure		404			{object}	dto.PointInTimeBalanceResponse	"Wallet not found"
+//	@Failure		410			{object}	dto.PointInTimeBalanceResponse	"Logs before the time were archived"
+//	@Failure		500			{object}	dto.PointInTimeBalanceResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/balance [get]
+func (h *SnapshotHandler) GetBalanceAt(c *fiber.Ctx) error {
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.PointInTimeBalanceResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	at := time.Now()
+	if value := c.Query("at"); value != "" {
+		at, err = service.ParseTimestamp(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.PointInTimeBalanceResponse{
+				Success: false,
+				Error:   "at must be an RFC 3339 timestamp or a date (YYYY-MM-DD)",
+			})
+		}
+	}
+
+	// Security check: users can only view their own balance history
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.PointInTimeBalanceResponse{
+			Success: false,
+			Error:   "You can only access your own wallet",
+		})
+	}
+
+	balance, err := h.snapshotService.GetBalanceAt(c.Context(), userID, c.Query("currency"), at)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidBalanceTime), errors.Is(err, service.ErrUnsupportedCurrency):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, service.ErrWalletNotFound):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrBalanceArchived):
+			status = fiber.StatusGone
+		}
+
+		if status == fiber.StatusInternalServerError {
+			h.logger.Error("Error getting point-in-time balance",
+				zap.Int("user_id", userID),
+				zap.Time("at", at),
+				zap.Error(err))
+			return c.Status(status).JSON(dto.PointInTimeBalanceResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		return c.Status(status).JSON(dto.PointInTimeBalanceResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	return c.JSON(dto.PointInTimeBalanceResponse{
+		Success: true,
+		Data:    balance,
+	})
+}
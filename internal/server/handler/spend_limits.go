@@ -0,0 +1,187 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+	"github.com/playconomy/wallet-service/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// GetSpendAllowance retrieves a user's spend limits and remaining allowance
+//
+//	@Summary		Get spend allowance
+//	@Description	Returns the spend limits of a user and what is left of them in the rolling daily, weekly and per-minute windows
+//	@Tags			wallet,limits
+//	@Produce		json
+//	@Param			user_id		path		int							true	"User ID"
+//	@Param			currency	query		string						false	"Currency of the limits, defaults to the default currency"
+//	@Success		200			{object}	dto.SpendAllowanceResponse	"Spend allowance"
+//	@Failure		400			{object}	dto.SpendAllowanceResponse	"Invalid user ID or unsupported currency"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.SpendAllowanceResponse	"Forbidden"
+//	@Failure		500			{object}	dto.SpendAllowanceResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/spend-limits [get]
+func (h *WalletHandler) GetSpendAllowance(c *fiber.Ctx) error {
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.SpendAllowanceResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	// Security check: users can only view their own allowance
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.SpendAllowanceResponse{
+			Success: false,
+			Error:   "You can only access your own wallet",
+		})
+	}
+
+	allowance, err := h.walletService.GetSpendAllowance(c.Context(), userID, c.Query("currency"))
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedCurrency) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.SpendAllowanceResponse{
+				Success: false,
+				Error:   err.Error(),
+			})
+		}
+
+		h.logger.Error("Error getting spend allowance",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.SpendAllowanceResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.SpendAllowanceResponse{
+		Success: true,
+		Data:    allowance,
+	})
+}
+
+// SetSpendLimitOverride overrides a user's spend limits
+//
+//	@Summary		Override spend limits
+//	@Description	Creates or replaces a user's spend limit override; omitted limits use the defaults and 0 removes a limit (admin only)
+//	@Tags			admin,limits
+//	@Accept			json
+//	@Produce		json
+//	@Param			user_id	path		int								true	"User ID"
+//	@Param			request	body		dto.UpdateSpendLimitsRequest	true	"Spend limits"
+//	@Success		200		{object}	dto.SpendLimitOverrideResponse	"Saved override"
+//	@Failure		400		{object}	dto.SpendLimitOverrideResponse	"Invalid request"
+//	@Failure		401		{object}	dto.GenericResponse				"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse				"Forbidden"
+//	@Failure		500		{object}	dto.SpendLimitOverrideResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/wallets/{user_id}/spend-limits [put]
+func (h *WalletHandler) SetSpendLimitOverride(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	adminUserID := c.Locals("user_id").(int)
+	logger := h.logger.With(
+		zap.String("request_id", requestID),
+		zap.Int("admin_user_id", adminUserID))
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.SpendLimitOverrideResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	var req dto.UpdateSpendLimitsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.SpendLimitOverrideResponse{
+			Success: false,
+			Error:   "Invalid request body",
+		})
+	}
+
+	if err := utils.ValidateStruct(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.SpendLimitOverrideResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	override, err := h.walletService.SetSpendLimitOverride(c.Context(), userID, &req, adminUserID)
+	if err != nil {
+		logger.Error("Error saving spend limit override",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.SpendLimitOverrideResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return c.JSON(dto.SpendLimitOverrideResponse{
+		Success: true,
+		Data:    override,
+	})
+}
+
+// DeleteSpendLimitOverride removes a user's spend limit override
+//
+//	@Summary		Remove spend limit override
+//	@Description	Removes a user's spend limit override so the default limits apply again (admin only)
+//	@Tags			admin,limits
+//	@Produce		json
+//	@Param			user_id	path		int					true	"User ID"
+//	@Success		200		{object}	dto.GenericResponse	"Override removed"
+//	@Failure		400		{object}	dto.GenericResponse	"Invalid user ID"
+//	@Failure		401		{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403		{object}	dto.GenericResponse	"Forbidden"
+//	@Failure		404		{object}	dto.GenericResponse	"No override for user"
+//	@Failure		500		{object}	dto.GenericResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/admin/wallets/{user_id}/spend-limits [delete]
+func (h *WalletHandler) DeleteSpendLimitOverride(c *fiber.Ctx) error {
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	deleted, err := h.walletService.DeleteSpendLimitOverride(c.Context(), userID)
+	if err != nil {
+		h.logger.Error("Error removing spend limit override",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	if !deleted {
+		return c.Status(fiber.StatusNotFound).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Spend limit override not found",
+		})
+	}
+
+	return c.JSON(dto.GenericResponse{Success: true})
+}
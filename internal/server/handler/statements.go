@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// statementContentTypes maps the rendered statement formats to their content types
+var statementContentTypes = map[string]string{
+	service.StatementFormatText: fiber.MIMETextPlainCharsetUTF8,
+	service.StatementFormatHTML: fiber.MIMETextHTMLCharsetUTF8,
+}
+
+// StatementHandler serves the wallet statement endpoints
+type StatementHandler struct {
+	statementService service.StatementServiceInterface
+	logger           *zap.Logger
+}
+
+// Compile-time verification that StatementHandler implements StatementHandlerInterface
+var _ StatementHandlerInterface = (*StatementHandler)(nil)
+
+func NewStatementHandler(statementService service.StatementServiceInterface, obs *observability.Observability) *StatementHandler {
+	return &StatementHandler{
+		statementService: statementService,
+		logger:           obs.Logger.Logger.With(zap.String("component", "statement_handler")),
+	}
+}
+
+// GetStatement returns a user's monthly wallet statement
+//
+//	@Summary		Get wallet statement
+//	@Description	Returns the statement of a wallet for a calendar month (UTC): opening balance, every credit and debit with the running balance, and closing balance. A statement is available once the month has ended and never changes afterwards
+//	@Tags			wallet,statements
+//	@Produce		json
+//	@Produce		plain
+//	@Produce		html
+//	@Param			user_id		path		int							true	"User ID"
+//	@Param			period		path		string						true	"Month of the statement (YYYY-MM)"
+//	@Param			currency	query		string						false	"Currency of the wallet, defaults to the default currency"
+//	@Param			format		query		string						false	"Output format (json, text, html)"	default(json)
+//	@Success		200			{object}	dto.WalletStatementResponse	"Wallet statement"
+//	@Failure		400			{object}	dto.WalletStatementResponse	"Invalid user ID, period, format or currency"
+//	@Failure		401			{object}	dto.GenericResponse			"Unauthorized"
+//	@Failure		403			{object}	dto.WalletStatementResponse	"Forbidden"
+//	@Failure		404			{object}	dto.WalletStatementResponse	"Wallet not found or no statement for the period"
+//	@Failure		409			{object}	dto.WalletStatementResponse	"Period has not ended yet"
+//	@Failure		410			{object}	dto.WalletStatementResponse	"Logs of the period were archived"
+//	@Failure		500			{object}	dto.WalletStatementResponse	"Server error"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/statements/{period} [get]
+func (h *StatementHandler) GetStatement(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(zap.String("request_id", requestID))
+
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletStatementResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	format := c.Query("format", service.StatementFormatJSON)
+	if _, ok := statementContentTypes[format]; !ok && format != service.StatementFormatJSON {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.WalletStatementResponse{
+			Success: false,
+			Error:   "format must be one of json, text, html",
+		})
+	}
+
+	// Security check: users can only view their own statements
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.WalletStatementResponse{
+			Success: false,
+			Error:   "You can only access your own wallet statements",
+		})
+	}
+
+	period := c.Params("period")
+	statement, err := h.statementService.GetStatement(c.Context(), userID, c.Query("currency"), period)
+	if err != nil {
+		status := fiber.StatusInternalServerError
+		switch {
+		case errors.Is(err, service.ErrInvalidStatementPeriod), errors.Is(err, service.ErrUnsupportedCurrency):
+			status = fiber.StatusBadRequest
+		case errors.Is(err, service.ErrWalletNotFound), errors.Is(err, service.ErrStatementNotAvailable):
+			status = fiber.StatusNotFound
+		case errors.Is(err, service.ErrStatementPeriodOpen):
+			status = fiber.StatusConflict
+		case errors.Is(err, service.ErrStatementArchived):
+			status = fiber.StatusGone
+		}
+
+		if status == fiber.StatusInternalServerError {
+			logger.Error("Error getting wallet statement",
+				zap.Int("user_id", userID),
+				zap.String("period", period),
+				zap.Error(err))
+			return c.Status(status).JSON(dto.WalletStatementResponse{
+				Success: false,
+				Error:   "Internal server error",
+			})
+		}
+
+		return c.Status(status).JSON(dto.WalletStatementResponse{
+			Success: false,
+			Error:   err.Error(),
+		})
+	}
+
+	if format == service.StatementFormatJSON {
+		return c.JSON(dto.WalletStatementResponse{
+			Success: true,
+			Data:    statement,
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, statementContentTypes[format])
+	if err := service.RenderStatement(c.Response().BodyWriter(), statement, format); err != nil {
+		logger.Error("Error rendering wallet statement",
+			zap.Int("user_id", userID),
+			zap.String("period", period),
+			zap.Error(err))
+		c.Response().ResetBody()
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.WalletStatementResponse{
+			Success: false,
+			Error:   "Internal server error",
+		})
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package handler
+
+import (
+	"bufio"
+	"context"
+	"strconv"
+
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+	"github.com/playconomy/wallet-service/internal/service"
+
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+// StreamHandler serves the Server-Sent Events streams of wallet changes
+type StreamHandler struct {
+	streamService service.StreamServiceInterface
+	logger        *zap.Logger
+}
+
+// Compile-time verification that StreamHandler implements StreamHandlerInterface
+var _ StreamHandlerInterface = (*StreamHandler)(nil)
+
+func NewStreamHandler(streamService service.StreamServiceInterface, obs *observability.Observability) *StreamHandler {
+	return &StreamHandler{
+		streamService: streamService,
+		logger:        obs.Logger.Logger.With(zap.String("component", "stream_handler")),
+	}
+}
+
+// StreamWallet streams a user's balance changes as Server-Sent Events
+//
+//	@Summary		Stream wallet changes
+//	@Description	Opens a Server-Sent Events stream of the user's wallet changes. Every new wallet log is pushed as a wallet_log event, followed by balance events with the new balance of the affected currencies. The last log of each batch carries an opaque event ID. A new stream starts with a balance event per currency; a client reconnecting with Last-Event-ID first receives the logs committed since, which may repeat logs of an interrupted batch. Idle streams receive a heartbeat comment
+//	@Tags			wallet,stream
+//	@Produce		text/event-stream
+//	@Param			user_id			path		int					true	"User ID"
+//	@Param			Last-Event-ID	header		string				false	"ID of the last event received, to resume a stream"
+//	@Param			last_event_id	query		string				false	"Same as Last-Event-ID, for clients that cannot set headers"
+//	@Success		200				{string}	string				"Event stream"
+//	@Failure		400				{object}	dto.GenericResponse	"Invalid user ID or last event ID"
+//	@Failure		401				{object}	dto.GenericResponse	"Unauthorized"
+//	@Failure		403				{object}	dto.GenericResponse	"Forbidden"
+//	@Security		ApiKeyAuth
+//	@Security		ApiEmailAuth
+//	@Security		ApiRoleAuth
+//	@Router			/{user_id}/stream [get]
+func (h *StreamHandler) StreamWallet(c *fiber.Ctx) error {
+	requestID, _ := c.Locals("requestid").(string)
+	logger := h.logger.With(zap.String("request_id", requestID))
+
+	authenticatedUserID := c.Locals("user_id").(int)
+
+	userID, err := strconv.Atoi(c.Params("user_id"))
+	if err != nil || userID <= 0 {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Invalid user ID format",
+		})
+	}
+
+	// Security check: users can only stream their own wallet
+	// Unless they have admin role
+	userRole := c.Locals("user_role").(string)
+	if authenticatedUserID != userID && userRole != "admin" {
+		return c.Status(fiber.StatusForbidden).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "You can only stream your own wallet",
+		})
+	}
+
+	lastEventID := c.Get("Last-Event-ID", c.Query("last_event_id"))
+	if _, err := strconv.ParseInt(lastEventID, 10, 64); err == nil {
+		// Streams used to be resumed from a log ID, which cannot tell the logs committed since; such
+		// a stream starts over with the current balances
+		lastEventID = ""
+	}
+	if lastEventID != "" && !service.ValidStreamCursor(lastEventID) {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Invalid last event ID",
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	// The body is written after the handler returned, when the request context is no longer usable
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.streamService.StreamWalletEvents(context.Background(), userID, lastEventID, w); err != nil {
+			logger.Error("Wallet stream failed",
+				zap.Int("user_id", userID),
+				zap.Error(err))
+		}
+	})
+
+	return nil
+}
@@ -9,7 +9,8 @@ import (
 )
 
 // AuthMiddleware checks if auth headers are present and valid
-// @Description Middleware to authenticate requests using X-User-Id, X-User-Email, and X-User-Role headers
+// @Description Middleware to authenticate requests using X-User-Id, X-User-Email, and X-User-Role headers,
+// plus X-Game-Id naming the game a game server acts for
 func AuthMiddleware() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		// Check required headers
@@ -28,7 +29,7 @@ func AuthMiddleware() fiber.Handler {
 		// Parse and validate user ID
 		id, err := strconv.Atoi(userID)
 		if err != nil || id <= 0 {
-			return c.Status(fiber.StatusUnauthorized).JSON(dto.GenericResponse{
+			return c.Status(fiber.StatusBadRequest).JSON(dto.GenericResponse{
 				Success: false,
 				Error:   "Invalid user ID",
 			})
@@ -38,8 +39,27 @@ func AuthMiddleware() fiber.Handler {
 		c.Locals("user_id", id)
 		c.Locals("user_email", userEmail)
 		c.Locals("user_role", userRole)
+		c.Locals("game_id", c.Get("X-Game-Id"))
 
 		// Continue with next handler
 		return c.Next()
 	}
 }
+
+// RequireRole restricts a route to authenticated users holding one of the given roles.
+// It must be registered after AuthMiddleware, which populates the user_role local.
+func RequireRole(roles ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userRole, _ := c.Locals("user_role").(string)
+		for _, role := range roles {
+			if userRole == role {
+				return c.Next()
+			}
+		}
+
+		return c.Status(fiber.StatusForbidden).JSON(dto.GenericResponse{
+			Success: false,
+			Error:   "Insufficient permissions",
+		})
+	}
+}
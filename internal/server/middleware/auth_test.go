@@ -111,3 +111,33 @@ func TestAuthMiddleware(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
 	})
 }
+
+func TestRequireRole(t *testing.T) {
+	app := fiber.New()
+	app.Use(middleware.AuthMiddleware())
+	app.Get("/admin", middleware.RequireRole("admin"), func(c *fiber.Ctx) error {
+		return c.SendString("OK")
+	})
+
+	t.Run("Admin Allowed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin", nil)
+		req.Header.Set("X-User-Id", "1")
+		req.Header.Set("X-User-Email", "admin@example.com")
+		req.Header.Set("X-User-Role", "admin")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+	})
+
+	t.Run("User Forbidden", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/admin", nil)
+		req.Header.Set("X-User-Id", "123")
+		req.Header.Set("X-User-Email", "user@example.com")
+		req.Header.Set("X-User-Role", "user")
+
+		resp, err := app.Test(req)
+		assert.NoError(t, err)
+		assert.Equal(t, http.StatusForbidden, resp.StatusCode)
+	})
+}
@@ -18,17 +18,59 @@ var Module = fx.Options(
 )
 
 type Router struct {
-	app           *fiber.App
-	walletHandler handler.WalletHandlerInterface
+	app                   *fiber.App
+	walletHandler         handler.WalletHandlerInterface
+	reconciliationHandler handler.ReconciliationHandlerInterface
+	riskReviewHandler     handler.RiskReviewHandlerInterface
+	bulkHandler           handler.BulkHandlerInterface
+	jobHandler            handler.JobHandlerInterface
+	exportHandler         handler.ExportHandlerInterface
+	statementHandler      handler.StatementHandlerInterface
+	snapshotHandler       handler.SnapshotHandlerInterface
+	reportHandler         handler.ReportHandlerInterface
+	streamHandler         handler.StreamHandlerInterface
+	auditHandler          handler.AuditHandlerInterface
+	featureFlagHandler    handler.FeatureFlagHandlerInterface
+	rateLimitHandler      handler.RateLimitHandlerInterface
+	healthHandler         handler.HealthHandlerInterface
 }
 
 // Compile-time verification that Router implements RouterInterface
 var _ RouterInterface = (*Router)(nil)
 
-func NewRouter(app *fiber.App, walletHandler handler.WalletHandlerInterface) *Router {
+func NewRouter(
+	app *fiber.App,
+	walletHandler handler.WalletHandlerInterface,
+	reconciliationHandler handler.ReconciliationHandlerInterface,
+	riskReviewHandler handler.RiskReviewHandlerInterface,
+	bulkHandler handler.BulkHandlerInterface,
+	jobHandler handler.JobHandlerInterface,
+	exportHandler handler.ExportHandlerInterface,
+	statementHandler handler.StatementHandlerInterface,
+	snapshotHandler handler.SnapshotHandlerInterface,
+	reportHandler handler.ReportHandlerInterface,
+	streamHandler handler.StreamHandlerInterface,
+	auditHandler handler.AuditHandlerInterface,
+	featureFlagHandler handler.FeatureFlagHandlerInterface,
+	rateLimitHandler handler.RateLimitHandlerInterface,
+	healthHandler handler.HealthHandlerInterface,
+) *Router {
 	return &Router{
-		app:           app,
-		walletHandler: walletHandler,
+		app:                   app,
+		walletHandler:         walletHandler,
+		reconciliationHandler: reconciliationHandler,
+		riskReviewHandler:     riskReviewHandler,
+		bulkHandler:           bulkHandler,
+		jobHandler:            jobHandler,
+		exportHandler:         exportHandler,
+		statementHandler:      statementHandler,
+		snapshotHandler:       snapshotHandler,
+		reportHandler:         reportHandler,
+		streamHandler:         streamHandler,
+		auditHandler:          auditHandler,
+		featureFlagHandler:    featureFlagHandler,
+		rateLimitHandler:      rateLimitHandler,
+		healthHandler:         healthHandler,
 	}
 }
 
@@ -64,12 +106,58 @@ func (r *Router) RegisterRoutes(app *fiber.App) {
 		return c.JSON(fiber.Map{"status": "ok"})
 	})
 
-	// Create a group with auth middleware
-	api := app.Group("/", middleware.AuthMiddleware())
+	// Probes (unprotected)
+	app.Get("/livez", r.healthHandler.Livez)
+	app.Get("/readyz", r.healthHandler.Readyz)
+
+	// Create a group with auth middleware; requests over the limit of their IP address are rejected before
+	// they authenticate and requests over the limits of their principal after, and calls of audited roles are
+	// recorded in the audit log
+	api := app.Group("/", r.rateLimitHandler.LimitIPRequests, middleware.AuthMiddleware(),
+		r.rateLimitHandler.LimitRequests, r.auditHandler.RecordPrivilegedCall)
+
+	// Admin routes (registered before /:user_id so "admin" is never parsed as a user ID)
+	admin := api.Group("/admin", middleware.RequireRole("admin"))
+	admin.Post("/reconciliation/runs", r.reconciliationHandler.RunReconciliation)
+	admin.Get("/reconciliation/runs/:run_id", r.reconciliationHandler.GetReconciliationRun)
+	admin.Post("/reconciliation/mismatches/:mismatch_id/resolve", r.reconciliationHandler.ResolveMismatch)
+	admin.Put("/wallets/:user_id/status", r.walletHandler.UpdateWalletStatus)
+	admin.Get("/wallets/:user_id/status-history", r.walletHandler.GetWalletStatusHistory)
+	admin.Put("/wallets/:user_id/spend-limits", r.walletHandler.SetSpendLimitOverride)
+	admin.Delete("/wallets/:user_id/spend-limits", r.walletHandler.DeleteSpendLimitOverride)
+	admin.Post("/wallets/:user_id/grants", r.walletHandler.GrantTokens)
+	admin.Get("/reviews", r.riskReviewHandler.ListRiskReviews)
+	admin.Post("/reviews/:review_id/approve", r.riskReviewHandler.ApproveRiskReview)
+	admin.Post("/reviews/:review_id/reject", r.riskReviewHandler.RejectRiskReview)
+	admin.Post("/bulk", r.bulkHandler.ProcessBulk)
+	admin.Get("/bulk/jobs/:job_id", r.bulkHandler.GetBulkJob)
+	admin.Get("/jobs", r.jobHandler.ListJobs)
+	admin.Get("/jobs/:job_id", r.jobHandler.GetJob)
+	admin.Post("/jobs/:job_id/cancel", r.jobHandler.CancelJob)
+	admin.Get("/exports/wallet-logs", r.exportHandler.ExportWalletLogs)
+	admin.Get("/reports/exchanges", r.reportHandler.GetExchangeReport)
+	admin.Get("/reports/spend", r.reportHandler.GetSpendReport)
+	admin.Get("/reports/supply", r.reportHandler.GetSupplyReport)
+	admin.Get("/reports/active-wallets", r.reportHandler.GetActiveWalletReport)
+	admin.Get("/reports/top-wallets", r.reportHandler.GetTopWallets)
+	admin.Get("/audit", r.auditHandler.ListAuditEntries)
+	admin.Get("/audit/verify", r.auditHandler.VerifyAuditChain)
+	admin.Get("/feature-flags", r.featureFlagHandler.ListFeatureFlags)
+	admin.Put("/feature-flags/:name", r.featureFlagHandler.SetFeatureFlagOverride)
+	admin.Delete("/feature-flags/:name", r.featureFlagHandler.DeleteFeatureFlagOverride)
 
 	// Protected routes
 	api.Get("/:user_id", r.walletHandler.GetWallet)
 	api.Get("/:user_id/logs", r.walletHandler.GetWalletLogs)
+	api.Get("/:user_id/balance", r.snapshotHandler.GetBalanceAt)
+	api.Get("/:user_id/stream", r.streamHandler.StreamWallet)
+	api.Get("/:user_id/spend-limits", r.walletHandler.GetSpendAllowance)
+	api.Get("/:user_id/reverse-grants", r.walletHandler.GetReverseExchangeGrants)
+	api.Get("/:user_id/statements/:period", r.statementHandler.GetStatement)
+	api.Get("/:user_id/features", r.featureFlagHandler.GetUserFeatures)
 	api.Post("/exchange", r.walletHandler.Exchange)
+	api.Post("/exchange/reverse", r.walletHandler.ReverseExchange)
+	api.Post("/exchange/reverse/redeem", middleware.RequireRole("game_server", "admin"),
+		r.walletHandler.RedeemReverseExchangeGrant)
 	api.Post("/spend", r.walletHandler.Spend)
 }
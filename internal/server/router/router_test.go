@@ -5,13 +5,11 @@ import (
 	"net/http/httptest"
 	"testing"
 
-	"github.com/playconomy/wallet-service/internal/observability"
 	"github.com/playconomy/wallet-service/internal/server/handler"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.uber.org/zap"
 )
 
 // MockWalletHandler is a mock implementation of WalletHandlerInterface for testing
@@ -39,14 +37,318 @@ func (m *MockWalletHandler) GetWalletLogs(c *fiber.Ctx) error {
 	return args.Error(0)
 }
 
+func (m *MockWalletHandler) UpdateWalletStatus(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) GetWalletStatusHistory(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) GetSpendAllowance(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) SetSpendLimitOverride(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) DeleteSpendLimitOverride(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) GrantTokens(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) ReverseExchange(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) RedeemReverseExchangeGrant(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockWalletHandler) GetReverseExchangeGrants(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
 // Compile-time verification that MockWalletHandler implements WalletHandlerInterface
 var _ handler.WalletHandlerInterface = (*MockWalletHandler)(nil)
 
+// MockReconciliationHandler is a mock implementation of ReconciliationHandlerInterface for testing
+type MockReconciliationHandler struct {
+	mock.Mock
+}
+
+func (m *MockReconciliationHandler) RunReconciliation(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationHandler) GetReconciliationRun(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReconciliationHandler) ResolveMismatch(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockReconciliationHandler implements ReconciliationHandlerInterface
+var _ handler.ReconciliationHandlerInterface = (*MockReconciliationHandler)(nil)
+
+// MockRiskReviewHandler is a mock implementation of RiskReviewHandlerInterface for testing
+type MockRiskReviewHandler struct {
+	mock.Mock
+}
+
+func (m *MockRiskReviewHandler) ListRiskReviews(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockRiskReviewHandler) ApproveRiskReview(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockRiskReviewHandler) RejectRiskReview(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockRiskReviewHandler implements RiskReviewHandlerInterface
+var _ handler.RiskReviewHandlerInterface = (*MockRiskReviewHandler)(nil)
+
+// MockBulkHandler is a mock implementation of BulkHandlerInterface for testing
+type MockBulkHandler struct {
+	mock.Mock
+}
+
+func (m *MockBulkHandler) ProcessBulk(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockBulkHandler) GetBulkJob(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockBulkHandler implements BulkHandlerInterface
+var _ handler.BulkHandlerInterface = (*MockBulkHandler)(nil)
+
+// MockJobHandler is a mock implementation of JobHandlerInterface for testing
+type MockJobHandler struct {
+	mock.Mock
+}
+
+func (m *MockJobHandler) ListJobs(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockJobHandler) GetJob(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockJobHandler) CancelJob(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockJobHandler implements JobHandlerInterface
+var _ handler.JobHandlerInterface = (*MockJobHandler)(nil)
+
+// MockExportHandler is a mock implementation of ExportHandlerInterface for testing
+type MockExportHandler struct {
+	mock.Mock
+}
+
+func (m *MockExportHandler) ExportWalletLogs(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockExportHandler implements ExportHandlerInterface
+var _ handler.ExportHandlerInterface = (*MockExportHandler)(nil)
+
+// MockStatementHandler is a mock implementation of StatementHandlerInterface for testing
+type MockStatementHandler struct {
+	mock.Mock
+}
+
+func (m *MockStatementHandler) GetStatement(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockStatementHandler implements StatementHandlerInterface
+var _ handler.StatementHandlerInterface = (*MockStatementHandler)(nil)
+
+// MockSnapshotHandler is a mock implementation of SnapshotHandlerInterface for testing
+type MockSnapshotHandler struct {
+	mock.Mock
+}
+
+func (m *MockSnapshotHandler) GetBalanceAt(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockSnapshotHandler implements SnapshotHandlerInterface
+var _ handler.SnapshotHandlerInterface = (*MockSnapshotHandler)(nil)
+
+// MockReportHandler is a mock implementation of ReportHandlerInterface for testing
+type MockReportHandler struct {
+	mock.Mock
+}
+
+func (m *MockReportHandler) GetExchangeReport(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReportHandler) GetSpendReport(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReportHandler) GetSupplyReport(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReportHandler) GetActiveWalletReport(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockReportHandler) GetTopWallets(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockReportHandler implements ReportHandlerInterface
+var _ handler.ReportHandlerInterface = (*MockReportHandler)(nil)
+
+// MockStreamHandler is a mock implementation of StreamHandlerInterface for testing
+type MockStreamHandler struct {
+	mock.Mock
+}
+
+func (m *MockStreamHandler) StreamWallet(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockStreamHandler implements StreamHandlerInterface
+var _ handler.StreamHandlerInterface = (*MockStreamHandler)(nil)
+
+// MockAuditHandler is a mock implementation of AuditHandlerInterface for testing
+type MockAuditHandler struct {
+	mock.Mock
+}
+
+func (m *MockAuditHandler) RecordPrivilegedCall(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockAuditHandler) ListAuditEntries(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockAuditHandler) VerifyAuditChain(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockAuditHandler implements AuditHandlerInterface
+var _ handler.AuditHandlerInterface = (*MockAuditHandler)(nil)
+
+// MockFeatureFlagHandler is a mock implementation of FeatureFlagHandlerInterface for testing
+type MockFeatureFlagHandler struct {
+	mock.Mock
+}
+
+func (m *MockFeatureFlagHandler) GetUserFeatures(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockFeatureFlagHandler) ListFeatureFlags(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockFeatureFlagHandler) SetFeatureFlagOverride(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockFeatureFlagHandler) DeleteFeatureFlagOverride(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockFeatureFlagHandler implements FeatureFlagHandlerInterface
+var _ handler.FeatureFlagHandlerInterface = (*MockFeatureFlagHandler)(nil)
+
+// MockRateLimitHandler is a mock implementation of RateLimitHandlerInterface for testing
+type MockRateLimitHandler struct {
+	mock.Mock
+}
+
+func (m *MockRateLimitHandler) LimitIPRequests(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockRateLimitHandler) LimitRequests(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockRateLimitHandler implements RateLimitHandlerInterface
+var _ handler.RateLimitHandlerInterface = (*MockRateLimitHandler)(nil)
+
+// MockHealthHandler is a mock implementation of HealthHandlerInterface for testing
+type MockHealthHandler struct {
+	mock.Mock
+}
+
+func (m *MockHealthHandler) Livez(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+func (m *MockHealthHandler) Readyz(c *fiber.Ctx) error {
+	args := m.Called(c)
+	return args.Error(0)
+}
+
+// Compile-time verification that MockHealthHandler implements HealthHandlerInterface
+var _ handler.HealthHandlerInterface = (*MockHealthHandler)(nil)
+
 // Setup test router
 func setupTestRouter(t *testing.T) (*fiber.App, *MockWalletHandler, RouterInterface) {
 	app := fiber.New()
 	mockHandler := new(MockWalletHandler)
-	router := NewRouter(app, mockHandler)
+	router := NewRouter(app, mockHandler, new(MockReconciliationHandler), new(MockRiskReviewHandler), new(MockBulkHandler), new(MockJobHandler), new(MockExportHandler), new(MockStatementHandler), new(MockSnapshotHandler), new(MockReportHandler), new(MockStreamHandler), new(MockAuditHandler), new(MockFeatureFlagHandler), new(MockRateLimitHandler), new(MockHealthHandler))
 	
 	return app, mockHandler, router
 }
@@ -71,7 +373,7 @@ func TestSetup(t *testing.T) {
 }
 
 func TestRegisterRoutes(t *testing.T) {
-	app, mockHandler, router := setupTestRouter(t)
+	app, _, router := setupTestRouter(t)
 	
 	// Call the method to test
 	router.RegisterRoutes(app)
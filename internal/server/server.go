@@ -3,8 +3,10 @@ package server
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/health"
 	"github.com/playconomy/wallet-service/internal/observability"
 
 	"github.com/gofiber/fiber/v2"
@@ -12,17 +14,29 @@ import (
 	"go.uber.org/zap"
 )
 
+// StreamCloser ends the streamed responses that only end when the server closes them, such as wallet event
+// streams. Shutting the server down waits for every open connection, so they are closed first.
+type StreamCloser interface {
+	CloseStreams()
+}
+
 type Server struct {
-	app    *fiber.App
-	config *config.Config
-	logger *zap.Logger
+	app      *fiber.App
+	config   *config.Config
+	registry *health.Registry
+	streams  StreamCloser
+	logger   *zap.Logger
 }
 
-func NewServer(lc fx.Lifecycle, app *fiber.App, cfg *config.Config, obs *observability.Observability) *Server {
+func NewServer(lc fx.Lifecycle, app *fiber.App, cfg *config.Config, registry *health.Registry,
+	streams StreamCloser, obs *observability.Observability) *Server {
+
 	server := &Server{
-		app:    app,
-		config: cfg,
-		logger: obs.Logger.With(zap.String("component", "server")),
+		app:      app,
+		config:   cfg,
+		registry: registry,
+		streams:  streams,
+		logger:   obs.Logger.Logger.With(zap.String("component", "server")),
 	}
 
 	lc.Append(fx.Hook{
@@ -40,8 +54,21 @@ func NewServer(lc fx.Lifecycle, app *fiber.App, cfg *config.Config, obs *observa
 			return nil
 		},
 		OnStop: func(ctx context.Context) error {
+			// Fail readiness first and keep serving while load balancers notice
+			server.registry.SetShuttingDown()
+			server.logger.Info("Readiness set to failing, waiting before shutdown",
+				zap.Duration("delay", cfg.Health.ShutdownDelay))
+
+			select {
+			case <-time.After(cfg.Health.ShutdownDelay):
+			case <-ctx.Done():
+			}
+
+			server.logger.Info("Closing open streams")
+			server.streams.CloseStreams()
+
 			server.logger.Info("Shutting down server...")
-			return server.app.Shutdown()
+			return server.app.ShutdownWithContext(ctx)
 		},
 	})
 
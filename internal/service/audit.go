@@ -0,0 +1,294 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// defaultAuditLimit is the number of audit entries listed when the request sets no limit
+const defaultAuditLimit = 50
+
+// AuditService records privileged calls in the hash-chained audit log and serves the admin operations on it
+type AuditService struct {
+	repo            repository.AuditRepository
+	roles           map[string]bool
+	verifyBatchSize int
+	logger          *zap.Logger
+	metrics         *metrics.Metrics
+	tracer          *tracing.Tracer
+}
+
+// Compile-time verification that AuditService implements AuditServiceInterface
+var _ AuditServiceInterface = (*AuditService)(nil)
+
+// NewAuditService creates a new audit service
+func NewAuditService(repo repository.AuditRepository, cfg *config.Config, obs *observability.Observability) *AuditService {
+	roles := make(map[string]bool, len(cfg.Audit.Roles))
+	for _, role := range cfg.Audit.Roles {
+		roles[role] = true
+	}
+
+	return &AuditService{
+		repo:            repo,
+		roles:           roles,
+		verifyBatchSize: cfg.Audit.VerifyBatchSize,
+		logger:          obs.Logger.Logger.With(zap.String("component", "audit_service")),
+		metrics:         obs.Metrics,
+		tracer:          obs.Tracer,
+	}
+}
+
+// IsAudited reports whether the calls of a role are recorded in the audit log
+func (s *AuditService) IsAudited(role string) bool {
+	return s.roles[role]
+}
+
+// auditHashInput lists the fields covered by the hash of an audit entry, in a fixed order. The ID is
+// assigned on insert and is not part of it; the chain of previous hashes fixes the order of the entries.
+type auditHashInput struct {
+	PrevHash      string `json:"prev_hash"`
+	PrincipalID   int    `json:"principal_id"`
+	PrincipalRole string `json:"principal_role"`
+	Action        string `json:"action"`
+	Method        string `json:"method"`
+	Path          string `json:"path"`
+	TargetUserID  *int   `json:"target_user_id"`
+	PayloadHash   string `json:"payload_hash"`
+	RequestID     string `json:"request_id"`
+	StatusCode    int    `json:"status_code"`
+	Outcome       string `json:"outcome"`
+	CreatedAt     string `json:"created_at"`
+}
+
+// auditEntryHash returns the SHA-256 hash of an entry's fields and previous hash, hex encoded
+func auditEntryHash(entry *model.AuditEntry) string {
+	// Encoding a struct gives the same bytes for the same fields, whatever their content
+	encoded, _ := json.Marshal(auditHashInput{
+		PrevHash:      entry.PrevHash,
+		PrincipalID:   entry.PrincipalID,
+		PrincipalRole: entry.PrincipalRole,
+		Action:        entry.Action,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		TargetUserID:  entry.TargetUserID,
+		PayloadHash:   entry.PayloadHash,
+		RequestID:     entry.RequestID,
+		StatusCode:    entry.StatusCode,
+		Outcome:       entry.Outcome,
+		CreatedAt:     entry.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+	sum := sha256.Sum256(encoded)
+	return hex.EncodeToString(sum[:])
+}
+
+// HashAuditPayload returns the SHA-256 hash of a request body, hex encoded
+func HashAuditPayload(body []byte) string {
+	sum := sha256.Sum256(body)
+	return hex.EncodeToString(sum[:])
+}
+
+// AuditOutcome classifies the response status of a privileged call
+func AuditOutcome(statusCode int) string {
+	switch {
+	case statusCode == 401 || statusCode == 403:
+		return model.AuditDenied
+	case statusCode >= 400:
+		return model.AuditFailed
+	default:
+		return model.AuditSucceeded
+	}
+}
+
+// RecordPrivilegedCall appends a privileged call to the audit log
+func (s *AuditService) RecordPrivilegedCall(ctx context.Context, entry *model.AuditEntry) (*model.AuditEntry, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "AuditService.RecordPrivilegedCall",
+		trace.WithAttributes(
+			attribute.Int("principal_id", entry.PrincipalID),
+			attribute.String("action", entry.Action),
+			attribute.String("outcome", entry.Outcome),
+		))
+	defer span.End()
+
+	// The database keeps microseconds, so the hash is computed over the time as it is stored
+	recorded := *entry
+	if recorded.CreatedAt.IsZero() {
+		recorded.CreatedAt = time.Now()
+	}
+	recorded.CreatedAt = recorded.CreatedAt.UTC().Truncate(time.Microsecond)
+
+	appended, err := s.repo.AppendAuditEntry(ctx, &recorded, auditEntryHash)
+	if err != nil {
+		s.metrics.RecordWalletOperation("audit", "error")
+		return nil, err
+	}
+
+	s.metrics.RecordWalletOperation("audit", "success")
+	return appended, nil
+}
+
+// newAuditFilter converts an audit log request, returning ErrInvalidAuditFilter when a date cannot be
+// parsed or the range is empty
+func newAuditFilter(req *dto.AuditLogRequest) (model.AuditFilter, error) {
+	filter := model.AuditFilter{
+		Action:  req.Action,
+		Outcome: req.Outcome,
+		Limit:   req.Limit,
+		Offset:  req.Offset,
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditLimit
+	}
+
+	if req.PrincipalID != 0 {
+		principalID := req.PrincipalID
+		filter.PrincipalID = &principalID
+	}
+	if req.TargetUserID != 0 {
+		targetUserID := req.TargetUserID
+		filter.TargetUserID = &targetUserID
+	}
+
+	for _, bound := range []struct {
+		name   string
+		value  string
+		target **time.Time
+	}{
+		{"from", req.From, &filter.From},
+		{"to", req.To, &filter.To},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := ParseTimestamp(bound.value)
+		if err != nil {
+			return model.AuditFilter{}, fmt.Errorf("%w: %s must be an RFC 3339 timestamp or a date (YYYY-MM-DD)",
+				ErrInvalidAuditFilter, bound.name)
+		}
+		*bound.target = &parsed
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return model.AuditFilter{}, fmt.Errorf("%w: from must be before to", ErrInvalidAuditFilter)
+	}
+
+	return filter, nil
+}
+
+// ListAuditEntries returns the audit entries matching the request, newest first
+func (s *AuditService) ListAuditEntries(ctx context.Context, req *dto.AuditLogRequest) ([]*dto.AuditEntry, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "AuditService.ListAuditEntries")
+	defer span.End()
+
+	filter, err := newAuditFilter(req)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := s.repo.ListAuditEntries(ctx, filter)
+	if err != nil {
+		s.metrics.RecordWalletOperation("audit_list", "error")
+		return nil, err
+	}
+
+	result := make([]*dto.AuditEntry, 0, len(entries))
+	for _, entry := range entries {
+		result = append(result, toAuditEntryDTO(entry))
+	}
+
+	s.metrics.RecordWalletOperation("audit_list", "success")
+	return result, nil
+}
+
+// verifyAuditEntries checks that each entry follows prevHash and matches its own hash. It returns the
+// number of entries that passed and, when one breaks the chain, that entry with the reason.
+func verifyAuditEntries(prevHash string, entries []*model.AuditEntry) (int, *model.AuditEntry, string) {
+	for i, entry := range entries {
+		if entry.PrevHash != prevHash {
+			return i, entry, "previous hash does not match the entry before it"
+		}
+		if auditEntryHash(entry) != entry.Hash {
+			return i, entry, "hash does not match the entry"
+		}
+		prevHash = entry.Hash
+	}
+	return len(entries), nil, ""
+}
+
+// VerifyAuditChain walks the audit log from its first entry and checks every link of the hash chain
+func (s *AuditService) VerifyAuditChain(ctx context.Context) (*dto.AuditVerification, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "AuditService.VerifyAuditChain")
+	defer span.End()
+
+	verification := &dto.AuditVerification{Valid: true, LastHash: model.AuditGenesisHash}
+	for {
+		entries, err := s.repo.ListAuditChain(ctx, verification.LastID, s.verifyBatchSize)
+		if err != nil {
+			s.metrics.RecordWalletOperation("audit_verify", "error")
+			return nil, err
+		}
+
+		checked, broken, reason := verifyAuditEntries(verification.LastHash, entries)
+		verification.EntriesChecked += checked
+		if checked > 0 {
+			verification.LastID = entries[checked-1].ID
+			verification.LastHash = entries[checked-1].Hash
+		}
+
+		if broken != nil {
+			verification.Valid = false
+			verification.BrokenAt = &broken.ID
+			verification.Reason = reason
+
+			s.logger.Error("Audit chain is broken",
+				zap.Int64("entry_id", broken.ID),
+				zap.String("reason", reason))
+			s.metrics.RecordWalletOperation("audit_verify", "broken")
+			return verification, nil
+		}
+
+		if len(entries) < s.verifyBatchSize {
+			break
+		}
+	}
+
+	s.logger.Info("Audit chain verified",
+		zap.Int("entries_checked", verification.EntriesChecked),
+		zap.Int64("last_id", verification.LastID))
+	s.metrics.RecordWalletOperation("audit_verify", "success")
+	return verification, nil
+}
+
+func toAuditEntryDTO(entry *model.AuditEntry) *dto.AuditEntry {
+	return &dto.AuditEntry{
+		ID:            entry.ID,
+		PrincipalID:   entry.PrincipalID,
+		PrincipalRole: entry.PrincipalRole,
+		Action:        entry.Action,
+		Method:        entry.Method,
+		Path:          entry.Path,
+		TargetUserID:  entry.TargetUserID,
+		PayloadHash:   entry.PayloadHash,
+		RequestID:     entry.RequestID,
+		StatusCode:    entry.StatusCode,
+		Outcome:       entry.Outcome,
+		CreatedAt:     entry.CreatedAt,
+		PrevHash:      entry.PrevHash,
+		Hash:          entry.Hash,
+	}
+}
@@ -0,0 +1,177 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildAuditChain links entries the way the repository appends them
+func buildAuditChain(entries ...*model.AuditEntry) []*model.AuditEntry {
+	prevHash := model.AuditGenesisHash
+	for i, entry := range entries {
+		entry.ID = int64(i + 1)
+		entry.PrevHash = prevHash
+		entry.Hash = auditEntryHash(entry)
+		prevHash = entry.Hash
+	}
+	return entries
+}
+
+func newTestAuditEntry(action string, statusCode int) *model.AuditEntry {
+	targetUserID := 42
+	return &model.AuditEntry{
+		PrincipalID:   1,
+		PrincipalRole: "admin",
+		Action:        action,
+		Method:        "PUT",
+		Path:          "/admin/wallets/42/status",
+		TargetUserID:  &targetUserID,
+		PayloadHash:   HashAuditPayload([]byte(`{"status":"frozen"}`)),
+		RequestID:     "req-1",
+		StatusCode:    statusCode,
+		Outcome:       AuditOutcome(statusCode),
+		CreatedAt:     time.Date(2026, 3, 15, 18, 30, 0, 123456000, time.UTC),
+	}
+}
+
+func TestAuditEntryHash(t *testing.T) {
+	entry := newTestAuditEntry("PUT /admin/wallets/:user_id/status", 200)
+	entry.PrevHash = model.AuditGenesisHash
+	hash := auditEntryHash(entry)
+
+	assert.Len(t, hash, 64)
+	assert.Equal(t, hash, auditEntryHash(entry))
+
+	t.Run("Same Instant In Another Zone", func(t *testing.T) {
+		moved := *entry
+		moved.CreatedAt = entry.CreatedAt.In(time.FixedZone("CET", 3600))
+		assert.Equal(t, hash, auditEntryHash(&moved))
+	})
+
+	t.Run("Changes With Any Field", func(t *testing.T) {
+		for name, change := range map[string]func(*model.AuditEntry){
+			"principal":    func(e *model.AuditEntry) { e.PrincipalID = 2 },
+			"target":       func(e *model.AuditEntry) { e.TargetUserID = nil },
+			"payload":      func(e *model.AuditEntry) { e.PayloadHash = HashAuditPayload(nil) },
+			"outcome":      func(e *model.AuditEntry) { e.Outcome = model.AuditFailed },
+			"created at":   func(e *model.AuditEntry) { e.CreatedAt = e.CreatedAt.Add(time.Microsecond) },
+			"previous":     func(e *model.AuditEntry) { e.PrevHash = hash },
+			"field border": func(e *model.AuditEntry) { e.Method, e.Path = "PUT/admin", "/wallets/42/status" },
+		} {
+			changed := *entry
+			change(&changed)
+			assert.NotEqual(t, hash, auditEntryHash(&changed), name)
+		}
+	})
+}
+
+func TestAuditOutcome(t *testing.T) {
+	assert.Equal(t, model.AuditSucceeded, AuditOutcome(200))
+	assert.Equal(t, model.AuditSucceeded, AuditOutcome(202))
+	assert.Equal(t, model.AuditDenied, AuditOutcome(401))
+	assert.Equal(t, model.AuditDenied, AuditOutcome(403))
+	assert.Equal(t, model.AuditFailed, AuditOutcome(400))
+	assert.Equal(t, model.AuditFailed, AuditOutcome(500))
+}
+
+func TestVerifyAuditEntries(t *testing.T) {
+	newChain := func() []*model.AuditEntry {
+		return buildAuditChain(
+			newTestAuditEntry("PUT /admin/wallets/:user_id/status", 200),
+			newTestAuditEntry("POST /admin/wallets/:user_id/grants", 403),
+			newTestAuditEntry("PUT /admin/wallets/:user_id/spend-limits", 500),
+		)
+	}
+
+	t.Run("Intact Chain", func(t *testing.T) {
+		checked, broken, _ := verifyAuditEntries(model.AuditGenesisHash, newChain())
+		assert.Equal(t, 3, checked)
+		assert.Nil(t, broken)
+	})
+
+	t.Run("Continues From Previous Batch", func(t *testing.T) {
+		chain := newChain()
+		checked, broken, _ := verifyAuditEntries(chain[0].Hash, chain[1:])
+		assert.Equal(t, 2, checked)
+		assert.Nil(t, broken)
+	})
+
+	t.Run("Changed Entry", func(t *testing.T) {
+		chain := newChain()
+		chain[1].Outcome = model.AuditSucceeded
+		checked, broken, reason := verifyAuditEntries(model.AuditGenesisHash, chain)
+		assert.Equal(t, 1, checked)
+		require.NotNil(t, broken)
+		assert.Equal(t, int64(2), broken.ID)
+		assert.Contains(t, reason, "hash does not match")
+	})
+
+	t.Run("Removed Entry", func(t *testing.T) {
+		chain := newChain()
+		checked, broken, reason := verifyAuditEntries(model.AuditGenesisHash, append(chain[:1], chain[2:]...))
+		assert.Equal(t, 1, checked)
+		require.NotNil(t, broken)
+		assert.Equal(t, int64(3), broken.ID)
+		assert.Contains(t, reason, "previous hash")
+	})
+
+	t.Run("Rehashed Entry", func(t *testing.T) {
+		chain := newChain()
+		chain[0].StatusCode = 500
+		chain[0].Hash = auditEntryHash(chain[0])
+		checked, broken, _ := verifyAuditEntries(model.AuditGenesisHash, chain)
+		assert.Equal(t, 1, checked)
+		require.NotNil(t, broken)
+		assert.Equal(t, int64(2), broken.ID)
+	})
+}
+
+func TestNewAuditFilter(t *testing.T) {
+	t.Run("Defaults", func(t *testing.T) {
+		filter, err := newAuditFilter(&dto.AuditLogRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, defaultAuditLimit, filter.Limit)
+		assert.Nil(t, filter.PrincipalID)
+		assert.Nil(t, filter.TargetUserID)
+		assert.Nil(t, filter.From)
+		assert.Nil(t, filter.To)
+	})
+
+	t.Run("Explicit Fields", func(t *testing.T) {
+		filter, err := newAuditFilter(&dto.AuditLogRequest{
+			PrincipalID:  1,
+			TargetUserID: 42,
+			Outcome:      model.AuditDenied,
+			From:         "2026-03-01",
+			To:           "2026-03-15T12:00:00Z",
+			Limit:        10,
+			Offset:       20,
+		})
+		require.NoError(t, err)
+		require.NotNil(t, filter.PrincipalID)
+		assert.Equal(t, 1, *filter.PrincipalID)
+		require.NotNil(t, filter.TargetUserID)
+		assert.Equal(t, 42, *filter.TargetUserID)
+		assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), *filter.From)
+		assert.Equal(t, time.Date(2026, 3, 15, 12, 0, 0, 0, time.UTC), *filter.To)
+		assert.Equal(t, 10, filter.Limit)
+		assert.Equal(t, 20, filter.Offset)
+	})
+
+	t.Run("Invalid Range", func(t *testing.T) {
+		for _, req := range []*dto.AuditLogRequest{
+			{From: "yesterday"},
+			{From: "2026-03-15", To: "2026-03-01"},
+		} {
+			_, err := newAuditFilter(req)
+			assert.True(t, errors.Is(err, ErrInvalidAuditFilter), req)
+		}
+	})
+}
@@ -0,0 +1,332 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// lotDebit is the new remaining amount of a balance lot a spend takes from
+type lotDebit struct {
+	lotID     int64
+	remaining float64
+}
+
+// consumeLots takes amount from lots in the given order, emptying each lot before moving to the next.
+// It returns the new remaining amount of every lot it touches and the part of amount the lots could not cover.
+func consumeLots(lots []*model.BalanceLot, amount float64) ([]lotDebit, float64) {
+	var debits []lotDebit
+	left := roundCents(amount)
+
+	for _, lot := range lots {
+		if left <= 0 {
+			break
+		}
+		if lot.Remaining <= 0 {
+			continue
+		}
+
+		taken := math.Min(lot.Remaining, left)
+		left = roundCents(left - taken)
+		debits = append(debits, lotDebit{lotID: lot.ID, remaining: roundCents(lot.Remaining - taken)})
+	}
+
+	return debits, left
+}
+
+// toBalanceExpirations converts expiring lots to their API representation
+func toBalanceExpirations(lots []*model.BalanceLot) []dto.BalanceExpiration {
+	var expirations []dto.BalanceExpiration
+	for _, lot := range lots {
+		if lot.ExpiresAt == nil {
+			continue
+		}
+		expirations = append(expirations, dto.BalanceExpiration{
+			Amount:    lot.Remaining,
+			Source:    lot.Source,
+			ExpiresAt: *lot.ExpiresAt,
+		})
+	}
+	return expirations
+}
+
+// consumeBalanceLots takes a spent amount from the open lots of a wallet, expiring lots first.
+// The caller must hold the wallet lock and have expired the wallet's due lots.
+func (s *WalletService) consumeBalanceLots(ctx context.Context, wallet *model.Wallet, amount float64,
+	now time.Time, tx repository.Transaction) error {
+
+	lots, err := s.repo.GetSpendableBalanceLotsForUpdate(ctx, wallet.ID, now, tx)
+	if err != nil {
+		return err
+	}
+
+	debits, uncovered := consumeLots(lots, amount)
+	for _, debit := range debits {
+		if err := s.repo.UpdateBalanceLotRemaining(ctx, debit.lotID, debit.remaining, tx); err != nil {
+			return err
+		}
+	}
+
+	// The balance is authoritative; reconciliation reports wallets whose lots drifted from it
+	if uncovered > 0 {
+		s.logger.Warn("Balance lots do not cover spend",
+			zap.Int64("wallet_id", wallet.ID),
+			zap.Float64("amount", amount),
+			zap.Float64("uncovered", uncovered))
+	}
+
+	return nil
+}
+
+// expireWalletLots empties the lots of a wallet that expired at or before now, lowers the balance by
+// the amount they still held and logs one expiry entry per lot. Expiry applies whatever the wallet status.
+// The caller must hold the wallet lock; the returned wallet carries the new balance.
+func (s *WalletService) expireWalletLots(ctx context.Context, wallet *model.Wallet, now time.Time,
+	tx repository.Transaction) (*model.Wallet, []*model.BalanceLot, error) {
+
+	lots, err := s.repo.ExpireBalanceLots(ctx, wallet.ID, now, tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if len(lots) == 0 {
+		return wallet, nil, nil
+	}
+
+	var expired float64
+	for _, lot := range lots {
+		expired += lot.Remaining
+	}
+	expired = roundCents(expired)
+
+	updated, err := s.repo.UpdateWalletBalance(ctx, wallet.UserID, wallet.Currency,
+		roundCents(wallet.Balance-expired), tx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, lot := range lots {
+		_, err := s.repo.CreateWalletLog(ctx, &model.WalletLog{
+			WalletID:       wallet.ID,
+			UserID:         wallet.UserID,
+			Currency:       wallet.Currency,
+			Amount:         -lot.Remaining,
+			PlatformAmount: -lot.Remaining,
+			Source:         model.TransactionExpiry,
+			ReferenceID:    lot.ReferenceID,
+		}, tx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	s.logger.Info("Expired balance lots",
+		zap.Int("user_id", wallet.UserID),
+		zap.String("currency", wallet.Currency),
+		zap.Int("lots", len(lots)),
+		zap.Float64("amount", expired),
+		zap.Float64("new_balance", updated.Balance))
+	s.metrics.RecordTokensExpired(wallet.Currency, expired)
+
+	return updated, lots, nil
+}
+
+// ExpireBalanceLots expires every balance lot past its expiry date, one wallet per transaction
+func (s *WalletService) ExpireBalanceLots(ctx context.Context) (*dto.ExpiryReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.ExpireBalanceLots")
+	defer span.End()
+
+	now := time.Now()
+	report := &dto.ExpiryReport{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		wallets, err := s.repo.ListWalletsWithDueBalanceLots(ctx, now, s.expiry.BatchSize)
+		if err != nil {
+			s.metrics.RecordWalletOperation("expire", "error_list")
+			return report, err
+		}
+
+		for _, wallet := range wallets {
+			lots, err := s.expireWallet(ctx, wallet, now)
+			if err != nil {
+				s.logger.Error("Failed to expire balance lots",
+					zap.Int("user_id", wallet.UserID),
+					zap.String("currency", wallet.Currency),
+					zap.Error(err))
+				s.metrics.RecordWalletOperation("expire", "error")
+				return report, err
+			}
+
+			report.WalletsProcessed++
+			report.LotsExpired += len(lots)
+			for _, lot := range lots {
+				report.AmountExpired += lot.Remaining
+			}
+		}
+
+		// Expired wallets drop out of the query, so a short page means nothing is left
+		if len(wallets) == 0 || len(wallets) < s.expiry.BatchSize {
+			break
+		}
+	}
+
+	report.AmountExpired = roundCents(report.AmountExpired)
+	s.metrics.RecordWalletOperation("expire", "success")
+
+	return report, nil
+}
+
+// expireWallet locks a wallet and expires its due lots in a transaction of its own
+func (s *WalletService) expireWallet(ctx context.Context, wallet *model.Wallet,
+	now time.Time) ([]*model.BalanceLot, error) {
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	locked, err := s.repo.GetWalletByUserIDForUpdate(ctx, wallet.UserID, wallet.Currency, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if locked == nil {
+		return nil, nil
+	}
+
+	_, lots, err := s.expireWalletLots(ctx, locked, now, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return lots, nil
+}
+
+// GrantTokens credits promotional tokens to a user's wallet on behalf of an admin, creating the wallet if needed.
+// The tokens are held in a lot of their own that expires at req.ExpiresAt when it is set.
+func (s *WalletService) GrantTokens(ctx context.Context, userID int,
+	req *dto.GrantTokensRequest, grantedBy int) (*dto.Wallet, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GrantTokens",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.Float64("amount", req.Amount),
+			attribute.String("currency", req.Currency),
+			attribute.Int("granted_by", grantedBy),
+		))
+	defer span.End()
+
+	s.logger.Info("Processing token grant",
+		zap.Int("user_id", userID),
+		zap.Float64("amount", req.Amount),
+		zap.String("currency", req.Currency),
+		zap.String("reference_id", req.ReferenceID),
+		zap.Int("granted_by", grantedBy))
+
+	currency, err := resolveCurrency(s.currencies, req.Currency)
+	if err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_unsupported_currency")
+		return nil, err
+	}
+	req.Currency = currency
+
+	if req.ExpiresAt != nil && !req.ExpiresAt.After(time.Now()) {
+		s.metrics.RecordWalletOperation("grant", "error_expiry")
+		return nil, fmt.Errorf("%w: %s", ErrExpiryNotInFuture, req.ExpiresAt.Format(time.RFC3339))
+	}
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, userID, currency, tx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_wallet_fetch")
+		return nil, err
+	}
+
+	// A new currency wallet inherits the status of the user's other wallets, so it is checked after creation
+	if wallet == nil {
+		wallet, err = s.repo.CreateWallet(ctx, userID, currency, req.Amount, tx)
+		if err != nil {
+			s.metrics.RecordWalletOperation("grant", "error_create_wallet")
+			return nil, err
+		}
+		if err := checkWalletMutable(wallet); err != nil {
+			s.metrics.RecordWalletOperation("grant", "error_wallet_"+wallet.Status)
+			return nil, err
+		}
+	} else {
+		if err := checkWalletMutable(wallet); err != nil {
+			s.metrics.RecordWalletOperation("grant", "error_wallet_"+wallet.Status)
+			return nil, err
+		}
+		wallet, err = s.repo.UpdateWalletBalance(ctx, userID, currency, wallet.Balance+req.Amount, tx)
+		if err != nil {
+			s.metrics.RecordWalletOperation("grant", "error_update_wallet")
+			return nil, err
+		}
+	}
+
+	_, err = s.repo.CreateBalanceLot(ctx, &model.BalanceLot{
+		WalletID:    wallet.ID,
+		Amount:      req.Amount,
+		Source:      model.TransactionBonus,
+		ReferenceID: &req.ReferenceID,
+		ExpiresAt:   req.ExpiresAt,
+	}, tx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_lot")
+		return nil, err
+	}
+
+	_, err = s.repo.CreateWalletLog(ctx, &model.WalletLog{
+		WalletID:       wallet.ID,
+		UserID:         userID,
+		Currency:       currency,
+		Amount:         req.Amount,
+		PlatformAmount: req.Amount,
+		Source:         model.TransactionBonus,
+		ReferenceID:    &req.ReferenceID,
+	}, tx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_log")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.metrics.RecordWalletOperation("grant", "error_commit")
+		return nil, err
+	}
+
+	s.logger.Info("Tokens granted",
+		zap.Int("user_id", userID),
+		zap.String("currency", currency),
+		zap.Float64("amount", req.Amount),
+		zap.Float64("new_balance", wallet.Balance),
+		zap.Int("granted_by", grantedBy))
+	s.metrics.RecordWalletOperation("grant", "success")
+
+	return toWalletDTO(wallet), nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConsumeLots(t *testing.T) {
+	lots := func() []*model.BalanceLot {
+		return []*model.BalanceLot{
+			{ID: 1, Remaining: 10},
+			{ID: 2, Remaining: 0.3},
+			{ID: 3, Remaining: 100},
+		}
+	}
+
+	testCases := []struct {
+		name              string
+		lots              []*model.BalanceLot
+		amount            float64
+		expectedDebits    []lotDebit
+		expectedUncovered float64
+	}{
+		{
+			name:           "Within First Lot",
+			lots:           lots(),
+			amount:         4,
+			expectedDebits: []lotDebit{{lotID: 1, remaining: 6}},
+		},
+		{
+			name:           "Empties Lots In Order",
+			lots:           lots(),
+			amount:         10.2,
+			expectedDebits: []lotDebit{{lotID: 1, remaining: 0}, {lotID: 2, remaining: 0.1}},
+		},
+		{
+			name:   "Spans All Lots",
+			lots:   lots(),
+			amount: 50.3,
+			expectedDebits: []lotDebit{
+				{lotID: 1, remaining: 0},
+				{lotID: 2, remaining: 0},
+				{lotID: 3, remaining: 60},
+			},
+		},
+		{
+			name:   "Skips Empty Lots",
+			lots:   []*model.BalanceLot{{ID: 1, Remaining: 0}, {ID: 2, Remaining: 5}},
+			amount: 5,
+			expectedDebits: []lotDebit{
+				{lotID: 2, remaining: 0},
+			},
+		},
+		{
+			name:   "Reports Uncovered Amount",
+			lots:   []*model.BalanceLot{{ID: 1, Remaining: 5}},
+			amount: 7.5,
+			expectedDebits: []lotDebit{
+				{lotID: 1, remaining: 0},
+			},
+			expectedUncovered: 2.5,
+		},
+		{
+			name:              "No Lots",
+			lots:              nil,
+			amount:            1,
+			expectedDebits:    nil,
+			expectedUncovered: 1,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			debits, uncovered := consumeLots(tc.lots, tc.amount)
+
+			assert.Equal(t, tc.expectedDebits, debits)
+			assert.Equal(t, tc.expectedUncovered, uncovered)
+		})
+	}
+}
+
+func TestToBalanceExpirations(t *testing.T) {
+	expiresAt := time.Date(2025, 6, 30, 0, 0, 0, 0, time.UTC)
+
+	expirations := toBalanceExpirations([]*model.BalanceLot{
+		{ID: 1, Remaining: 25, Source: model.TransactionBonus, ExpiresAt: &expiresAt},
+		{ID: 2, Remaining: 40, Source: model.TransactionExchange},
+	})
+
+	if assert.Len(t, expirations, 1) {
+		assert.Equal(t, 25.0, expirations[0].Amount)
+		assert.Equal(t, model.TransactionBonus, expirations[0].Source)
+		assert.Equal(t, expiresAt, expirations[0].ExpiresAt)
+	}
+}
@@ -0,0 +1,625 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// bulkJobFinishTimeout bounds the update that records the outcome of an interrupted bulk job
+const bulkJobFinishTimeout = 10 * time.Second
+
+// BulkService applies lists of credits and spends in chunked transactions, either within the request
+// or as a background job. Items are not submitted to risk evaluation; bulk requests are admin-only.
+type BulkService struct {
+	wallets *WalletService
+	repo    repository.BulkRepository
+	jobs    *JobService
+	config  config.BulkConfig
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	tracer  *tracing.Tracer
+}
+
+// Compile-time verification that BulkService implements BulkServiceInterface
+var _ BulkServiceInterface = (*BulkService)(nil)
+
+// NewBulkService creates a new bulk service
+func NewBulkService(wallets *WalletService, repo repository.BulkRepository, jobs *JobService,
+	cfg *config.Config, obs *observability.Observability) *BulkService {
+	return &BulkService{
+		wallets: wallets,
+		repo:    repo,
+		jobs:    jobs,
+		config:  cfg.Bulk,
+		logger:  obs.Logger.Logger.With(zap.String("component", "bulk_service")),
+		metrics: obs.Metrics,
+		tracer:  obs.Tracer,
+	}
+}
+
+// bulkChunkOrder returns the positions of items in the order a chunk applies them: by user and currency,
+// keeping the request order of the items of one wallet. Locking wallets in a fixed order keeps concurrent
+// chunks from deadlocking each other.
+func bulkChunkOrder(items []dto.BulkItem, defaultCurrency string) []int {
+	order := make([]int, len(items))
+	for i := range order {
+		order[i] = i
+	}
+
+	currency := func(item dto.BulkItem) string {
+		if item.Currency == "" {
+			return defaultCurrency
+		}
+		return item.Currency
+	}
+
+	sort.SliceStable(order, func(a, b int) bool {
+		left, right := items[order[a]], items[order[b]]
+		if left.UserID != right.UserID {
+			return left.UserID < right.UserID
+		}
+		return currency(left) < currency(right)
+	})
+
+	return order
+}
+
+// sameBulkItem reports whether a claimed idempotency key was claimed for the same item
+func sameBulkItem(key *model.IdempotencyKey, item dto.BulkItem, currency string) bool {
+	return key.UserID == item.UserID && key.Operation == item.Operation && key.Currency == currency &&
+		roundCents(key.Amount) == roundCents(item.Amount)
+}
+
+// isBulkItemError reports whether an error rejects a single item rather than its whole chunk
+func isBulkItemError(err error) bool {
+	for _, target := range []error{
+		ErrInvalidBulkItem, ErrIdempotencyKeyReused, ErrUnsupportedCurrency, ErrExpiryNotInFuture,
+		ErrWalletNotFound, ErrWalletFrozen, ErrWalletClosed, ErrInsufficientFunds, ErrSpendLimitExceeded,
+	} {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// newBulkReport counts the outcomes of item results
+func newBulkReport(results []dto.BulkItemResult) *dto.BulkReport {
+	report := &dto.BulkReport{Total: len(results), Results: results}
+	for _, result := range results {
+		switch result.Status {
+		case model.BulkItemSucceeded:
+			report.Succeeded++
+		case model.BulkItemDuplicate:
+			report.Duplicates++
+		default:
+			report.Failed++
+		}
+	}
+	return report
+}
+
+// ProcessBulk applies the items of a request within the call
+func (s *BulkService) ProcessBulk(ctx context.Context, req *dto.BulkRequest, requestedBy int) (*dto.BulkReport, error) {
+	if len(req.Items) > s.config.MaxSyncItems {
+		s.metrics.RecordWalletOperation("bulk", "error_too_large")
+		return nil, fmt.Errorf("%w: %d items, at most %d without async", ErrBulkTooLarge,
+			len(req.Items), s.config.MaxSyncItems)
+	}
+
+	report := s.process(ctx, req.Items, requestedBy)
+	s.metrics.RecordWalletOperation("bulk", "success")
+
+	return report, nil
+}
+
+// SubmitBulkJob records a bulk job for the items of a request and queues it for the job workers
+func (s *BulkService) SubmitBulkJob(ctx context.Context, req *dto.BulkRequest, requestedBy int) (*dto.BulkJob, error) {
+	if len(req.Items) > s.config.MaxAsyncItems {
+		s.metrics.RecordWalletOperation("bulk_job", "error_too_large")
+		return nil, fmt.Errorf("%w: %d items, at most %d", ErrBulkTooLarge, len(req.Items), s.config.MaxAsyncItems)
+	}
+
+	payload, err := json.Marshal(req.Items)
+	if err != nil {
+		return nil, fmt.Errorf("encode bulk items: %w", err)
+	}
+
+	job, err := s.repo.CreateBulkJob(ctx, &model.BulkJob{
+		TotalItems:  len(req.Items),
+		Request:     payload,
+		RequestedBy: requestedBy,
+	})
+	if err != nil {
+		s.metrics.RecordWalletOperation("bulk_job", "error_create")
+		return nil, err
+	}
+
+	if _, err := s.jobs.EnqueueJob(ctx, model.JobKindBulk, bulkJobPayload{BulkJobID: job.ID}, &requestedBy); err != nil {
+		message := "could not be queued"
+		job.Status = model.BulkJobFailed
+		job.Error = &message
+		if _, finishErr := s.repo.FinishBulkJob(ctx, job); finishErr != nil {
+			s.logger.Error("Failed to record bulk job outcome",
+				zap.Int64("bulk_job_id", job.ID),
+				zap.Error(finishErr))
+		}
+		s.metrics.RecordWalletOperation("bulk_job", "error_enqueue")
+		return nil, err
+	}
+
+	s.logger.Info("Bulk job submitted",
+		zap.Int64("bulk_job_id", job.ID),
+		zap.Int("items", job.TotalItems),
+		zap.Int("requested_by", requestedBy))
+	s.metrics.RecordWalletOperation("bulk_job", "submitted")
+
+	return toBulkJobDTO(job)
+}
+
+// GetBulkJob retrieves a background job with its item results once it finished
+func (s *BulkService) GetBulkJob(ctx context.Context, id int64) (*dto.BulkJob, error) {
+	job, err := s.repo.GetBulkJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("%w: job_id=%d", ErrBulkJobNotFound, id)
+	}
+
+	return toBulkJobDTO(job)
+}
+
+// bulkJobPayload is the payload of the background jobs that process bulk jobs
+type bulkJobPayload struct {
+	BulkJobID int64 `json:"bulk_job_id"`
+}
+
+// bulkJobResult is the result recorded on the background job of a bulk job; item results stay on the bulk job
+type bulkJobResult struct {
+	BulkJobID  int64 `json:"bulk_job_id"`
+	Succeeded  int   `json:"succeeded"`
+	Failed     int   `json:"failed"`
+	Duplicates int   `json:"duplicates"`
+}
+
+// runJob processes a bulk job until it is done or ctx ends. A job that is run again after an
+// interruption reports the items applied before as duplicates.
+func (s *BulkService) runJob(ctx context.Context, id int64) (*bulkJobResult, error) {
+	job, err := s.repo.GetBulkJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, fmt.Errorf("%w: %w: job_id=%d", ErrJobNotRetryable, ErrBulkJobNotFound, id)
+	}
+
+	var items []dto.BulkItem
+	if err := json.Unmarshal(job.Request, &items); err != nil {
+		return nil, fmt.Errorf("%w: decode bulk items: %v", ErrJobNotRetryable, err)
+	}
+
+	logger := s.logger.With(zap.Int64("bulk_job_id", job.ID))
+
+	if err := s.repo.StartBulkJob(ctx, job.ID); err != nil {
+		return nil, err
+	}
+
+	report := s.process(ctx, items, job.RequestedBy)
+
+	job.Status = model.BulkJobCompleted
+	job.Error = nil
+	if ctx.Err() != nil {
+		message := "interrupted; the job is run again unless it was cancelled"
+		job.Status = model.BulkJobFailed
+		job.Error = &message
+	}
+	job.Succeeded = report.Succeeded
+	job.Failed = report.Failed
+	job.Duplicates = report.Duplicates
+
+	results, err := json.Marshal(report.Results)
+	if err != nil {
+		logger.Error("Failed to encode bulk job results", zap.Error(err))
+	}
+	job.Results = results
+
+	// ctx may be cancelled already; the outcome is still recorded
+	finishCtx, cancel := context.WithTimeout(context.Background(), bulkJobFinishTimeout)
+	defer cancel()
+
+	if _, err := s.repo.FinishBulkJob(finishCtx, job); err != nil {
+		return nil, fmt.Errorf("record bulk job outcome: %w", err)
+	}
+
+	logger.Info("Bulk job finished",
+		zap.String("status", job.Status),
+		zap.Int("succeeded", job.Succeeded),
+		zap.Int("failed", job.Failed),
+		zap.Int("duplicates", job.Duplicates))
+	s.metrics.RecordWalletOperation("bulk_job", job.Status)
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	return &bulkJobResult{
+		BulkJobID:  job.ID,
+		Succeeded:  job.Succeeded,
+		Failed:     job.Failed,
+		Duplicates: job.Duplicates,
+	}, nil
+}
+
+// BulkJobWorker runs the background jobs of bulk requests
+type BulkJobWorker struct {
+	bulk *BulkService
+}
+
+// Compile-time verification that BulkJobWorker implements JobWorker
+var _ JobWorker = (*BulkJobWorker)(nil)
+
+// NewBulkJobWorker creates the job worker of bulk requests
+func NewBulkJobWorker(bulk *BulkService) JobWorker {
+	return &BulkJobWorker{bulk: bulk}
+}
+
+// Kind returns the job kind of bulk requests
+func (w *BulkJobWorker) Kind() string {
+	return model.JobKindBulk
+}
+
+// Run processes the bulk job named by the job payload
+func (w *BulkJobWorker) Run(ctx context.Context, job *model.Job) (json.RawMessage, error) {
+	var payload bulkJobPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("%w: decode payload: %v", ErrJobNotRetryable, err)
+	}
+
+	result, err := w.bulk.runJob(ctx, payload.BulkJobID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(result)
+}
+
+// process applies items chunk by chunk, one transaction per chunk. Items of chunks not started
+// before ctx ends are reported as failed.
+func (s *BulkService) process(ctx context.Context, items []dto.BulkItem, requestedBy int) *dto.BulkReport {
+	ctx, span := s.tracer.StartSpan(ctx, "BulkService.process",
+		trace.WithAttributes(
+			attribute.Int("items", len(items)),
+			attribute.Int("requested_by", requestedBy),
+		))
+	defer span.End()
+
+	results := make([]dto.BulkItemResult, len(items))
+	for start := 0; start < len(items); start += s.config.ChunkSize {
+		end := min(start+s.config.ChunkSize, len(items))
+
+		if err := ctx.Err(); err != nil {
+			for i := start; i < end; i++ {
+				results[i] = dto.BulkItemResult{
+					Index:          i,
+					IdempotencyKey: items[i].IdempotencyKey,
+					Status:         model.BulkItemFailed,
+					Error:          "not processed: " + err.Error(),
+				}
+			}
+			continue
+		}
+
+		copy(results[start:end], s.processChunk(ctx, items[start:end], start))
+	}
+
+	report := newBulkReport(results)
+	s.logger.Info("Bulk items processed",
+		zap.Int("requested_by", requestedBy),
+		zap.Int("total", report.Total),
+		zap.Int("succeeded", report.Succeeded),
+		zap.Int("failed", report.Failed),
+		zap.Int("duplicates", report.Duplicates))
+
+	return report
+}
+
+// processChunk applies the items of one chunk in a single transaction. Items rejected on their own are
+// reported as failed while the others are applied; any other error rolls the whole chunk back.
+func (s *BulkService) processChunk(ctx context.Context, items []dto.BulkItem, offset int) []dto.BulkItemResult {
+	results := make([]dto.BulkItemResult, len(items))
+	for i, item := range items {
+		results[i] = dto.BulkItemResult{Index: offset + i, IdempotencyKey: item.IdempotencyKey}
+	}
+
+	failChunk := func(err error) []dto.BulkItemResult {
+		s.logger.Error("Bulk chunk rolled back",
+			zap.Int("offset", offset),
+			zap.Int("items", len(items)),
+			zap.Error(err))
+		for i := range results {
+			results[i].Status = model.BulkItemFailed
+			results[i].NewBalance = nil
+			results[i].Error = "chunk rolled back: internal error"
+		}
+		return results
+	}
+
+	tx, err := s.wallets.repo.BeginTx(ctx)
+	if err != nil {
+		return failChunk(err)
+	}
+	defer tx.Rollback()
+
+	for _, i := range bulkChunkOrder(items, s.wallets.currencies.Default) {
+		currency, newBalance, duplicate, err := s.applyItem(ctx, items[i], tx)
+		results[i].Currency = currency
+
+		switch {
+		case err != nil && isBulkItemError(err):
+			results[i].Status = model.BulkItemFailed
+			results[i].Error = err.Error()
+			s.metrics.RecordWalletOperation("bulk_"+items[i].Operation, "error")
+		case err != nil:
+			return failChunk(err)
+		case duplicate:
+			results[i].Status = model.BulkItemDuplicate
+			results[i].NewBalance = newBalance
+			s.metrics.RecordWalletOperation("bulk_"+items[i].Operation, "duplicate")
+		default:
+			results[i].Status = model.BulkItemSucceeded
+			results[i].NewBalance = newBalance
+			s.metrics.RecordWalletOperation("bulk_"+items[i].Operation, "success")
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return failChunk(err)
+	}
+
+	return results
+}
+
+// applyItem applies one item under its idempotency key. A key already claimed for the same item makes
+// the item a duplicate carrying the balance recorded when it was applied.
+func (s *BulkService) applyItem(ctx context.Context, item dto.BulkItem,
+	tx repository.Transaction) (string, *float64, bool, error) {
+
+	currency, err := resolveCurrency(s.wallets.currencies, item.Currency)
+	if err != nil {
+		return "", nil, false, err
+	}
+
+	claimed, err := s.repo.ClaimIdempotencyKey(ctx, &model.IdempotencyKey{
+		Key:       item.IdempotencyKey,
+		UserID:    item.UserID,
+		Operation: item.Operation,
+		Currency:  currency,
+		Amount:    item.Amount,
+	}, tx)
+	if err != nil {
+		return currency, nil, false, err
+	}
+
+	if !claimed {
+		existing, err := s.repo.GetIdempotencyKey(ctx, item.IdempotencyKey, tx)
+		if err != nil {
+			return currency, nil, false, err
+		}
+		if existing == nil || !sameBulkItem(existing, item, currency) {
+			return currency, nil, false, fmt.Errorf("%w: %s", ErrIdempotencyKeyReused, item.IdempotencyKey)
+		}
+		return currency, existing.NewBalance, true, nil
+	}
+
+	var wallet *model.Wallet
+	switch item.Operation {
+	case model.BulkOperationCredit:
+		wallet, err = s.wallets.bulkCredit(ctx, item, currency, tx)
+	case model.BulkOperationSpend:
+		wallet, err = s.wallets.bulkSpend(ctx, item, currency, tx)
+	default:
+		err = fmt.Errorf("%w: unknown operation %q", ErrInvalidBulkItem, item.Operation)
+	}
+
+	if err != nil {
+		if isBulkItemError(err) {
+			if releaseErr := s.repo.ReleaseIdempotencyKey(ctx, item.IdempotencyKey, tx); releaseErr != nil {
+				return currency, nil, false, releaseErr
+			}
+		}
+		return currency, nil, false, err
+	}
+
+	if err := s.repo.CompleteIdempotencyKey(ctx, item.IdempotencyKey, wallet.Balance, tx); err != nil {
+		return currency, nil, false, err
+	}
+
+	return currency, &wallet.Balance, false, nil
+}
+
+// bulkCredit credits bonus tokens to a wallet, creating it if needed, in a lot that expires at
+// item.ExpiresAt when it is set. Every check runs before the first write so a rejected item
+// leaves nothing behind in the chunk.
+func (s *WalletService) bulkCredit(ctx context.Context, item dto.BulkItem, currency string,
+	tx repository.Transaction) (*model.Wallet, error) {
+
+	if item.ExpiresAt != nil && !item.ExpiresAt.After(time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrExpiryNotInFuture, item.ExpiresAt.Format(time.RFC3339))
+	}
+
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, item.UserID, currency, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		// A new currency wallet inherits the status of the user's oldest wallet
+		existing, err := s.repo.GetWalletsByUserIDForUpdate(ctx, item.UserID, tx)
+		if err != nil {
+			return nil, err
+		}
+		if len(existing) > 0 {
+			if err := checkWalletMutable(existing[0]); err != nil {
+				return nil, err
+			}
+		}
+
+		wallet, err = s.repo.CreateWallet(ctx, item.UserID, currency, item.Amount, tx)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		if err := checkWalletMutable(wallet); err != nil {
+			return nil, err
+		}
+
+		wallet, err = s.repo.UpdateWalletBalance(ctx, item.UserID, currency, wallet.Balance+item.Amount, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var referenceID *string
+	if item.ReferenceID != "" {
+		referenceID = &item.ReferenceID
+	}
+
+	_, err = s.repo.CreateBalanceLot(ctx, &model.BalanceLot{
+		WalletID:    wallet.ID,
+		Amount:      item.Amount,
+		Source:      model.TransactionBonus,
+		ReferenceID: referenceID,
+		ExpiresAt:   item.ExpiresAt,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.CreateWalletLog(ctx, &model.WalletLog{
+		WalletID:       wallet.ID,
+		UserID:         item.UserID,
+		Currency:       currency,
+		Amount:         item.Amount,
+		PlatformAmount: item.Amount,
+		Source:         model.TransactionBonus,
+		ReferenceID:    referenceID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return wallet, nil
+}
+
+// bulkSpend deducts tokens from a wallet under the user's spend limits. Due lots are expired first,
+// as for any spend; every other check runs before the first write.
+func (s *WalletService) bulkSpend(ctx context.Context, item dto.BulkItem, currency string,
+	tx repository.Transaction) (*model.Wallet, error) {
+
+	if item.Reason == "" {
+		return nil, fmt.Errorf("%w: reason is required for spend items", ErrInvalidBulkItem)
+	}
+
+	if err := s.checkSpendReason(ctx, item.UserID, item.Reason); err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, item.UserID, currency, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("%w for user_id=%d and currency=%s", ErrWalletNotFound, item.UserID, currency)
+	}
+
+	if err := checkWalletMutable(wallet); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	wallet, _, err = s.expireWalletLots(ctx, wallet, now, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.enforceSpendLimits(ctx, item.UserID, currency, item.Amount, tx); err != nil {
+		return nil, err
+	}
+
+	if wallet.Balance < item.Amount {
+		return nil, fmt.Errorf("%w: current balance %.2f, required %.2f",
+			ErrInsufficientFunds, wallet.Balance, item.Amount)
+	}
+
+	updated, err := s.repo.SpendFromWallet(ctx, item.UserID, currency, item.Amount, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.consumeBalanceLots(ctx, wallet, item.Amount, now, tx); err != nil {
+		return nil, err
+	}
+
+	var referenceID *string
+	if item.ReferenceID != "" {
+		referenceID = &item.ReferenceID
+	}
+
+	_, err = s.repo.CreateWalletLog(ctx, &model.WalletLog{
+		WalletID:       wallet.ID,
+		UserID:         item.UserID,
+		Currency:       currency,
+		Amount:         -item.Amount,
+		PlatformAmount: -item.Amount,
+		Source:         item.Reason,
+		ReferenceID:    referenceID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func toBulkJobDTO(job *model.BulkJob) (*dto.BulkJob, error) {
+	result := &dto.BulkJob{
+		ID:          job.ID,
+		Status:      job.Status,
+		TotalItems:  job.TotalItems,
+		Succeeded:   job.Succeeded,
+		Failed:      job.Failed,
+		Duplicates:  job.Duplicates,
+		Error:       job.Error,
+		RequestedBy: job.RequestedBy,
+		CreatedAt:   job.CreatedAt,
+		StartedAt:   job.StartedAt,
+		FinishedAt:  job.FinishedAt,
+	}
+
+	if len(job.Results) > 0 {
+		if err := json.Unmarshal(job.Results, &result.Results); err != nil {
+			return nil, fmt.Errorf("decode bulk job results: %w", err)
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,124 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkChunkOrder(t *testing.T) {
+	testCases := []struct {
+		name     string
+		items    []dto.BulkItem
+		expected []int
+	}{
+		{
+			name:     "Empty",
+			items:    nil,
+			expected: []int{},
+		},
+		{
+			name: "Sorted By User",
+			items: []dto.BulkItem{
+				{UserID: 3}, {UserID: 1}, {UserID: 2},
+			},
+			expected: []int{1, 2, 0},
+		},
+		{
+			name: "Same Wallet Keeps Request Order",
+			items: []dto.BulkItem{
+				{UserID: 2, Operation: "credit"}, {UserID: 1}, {UserID: 2, Operation: "spend"},
+			},
+			expected: []int{1, 0, 2},
+		},
+		{
+			name: "Default Currency Sorts With Explicit Currency",
+			items: []dto.BulkItem{
+				{UserID: 1, Currency: "USD"}, {UserID: 1}, {UserID: 1, Currency: "EUR"},
+			},
+			expected: []int{1, 2, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, bulkChunkOrder(tc.items, "EUR"))
+		})
+	}
+}
+
+func TestSameBulkItem(t *testing.T) {
+	key := &model.IdempotencyKey{Key: "k1", UserID: 1, Operation: model.BulkOperationCredit, Currency: "EUR", Amount: 10.5}
+
+	testCases := []struct {
+		name     string
+		item     dto.BulkItem
+		currency string
+		expected bool
+	}{
+		{
+			name:     "Same Item",
+			item:     dto.BulkItem{UserID: 1, Operation: model.BulkOperationCredit, Amount: 10.5},
+			currency: "EUR",
+			expected: true,
+		},
+		{
+			name:     "Amount Within Rounding",
+			item:     dto.BulkItem{UserID: 1, Operation: model.BulkOperationCredit, Amount: 10.5000001},
+			currency: "EUR",
+			expected: true,
+		},
+		{
+			name:     "Different User",
+			item:     dto.BulkItem{UserID: 2, Operation: model.BulkOperationCredit, Amount: 10.5},
+			currency: "EUR",
+		},
+		{
+			name:     "Different Operation",
+			item:     dto.BulkItem{UserID: 1, Operation: model.BulkOperationSpend, Amount: 10.5},
+			currency: "EUR",
+		},
+		{
+			name:     "Different Currency",
+			item:     dto.BulkItem{UserID: 1, Operation: model.BulkOperationCredit, Amount: 10.5},
+			currency: "USD",
+		},
+		{
+			name:     "Different Amount",
+			item:     dto.BulkItem{UserID: 1, Operation: model.BulkOperationCredit, Amount: 11},
+			currency: "EUR",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, sameBulkItem(key, tc.item, tc.currency))
+		})
+	}
+}
+
+func TestIsBulkItemError(t *testing.T) {
+	assert.True(t, isBulkItemError(ErrInsufficientFunds))
+	assert.True(t, isBulkItemError(fmt.Errorf("%w: key k1", ErrIdempotencyKeyReused)))
+	assert.False(t, isBulkItemError(errors.New("connection reset")))
+}
+
+func TestNewBulkReport(t *testing.T) {
+	report := newBulkReport([]dto.BulkItemResult{
+		{Index: 0, Status: model.BulkItemSucceeded},
+		{Index: 1, Status: model.BulkItemDuplicate},
+		{Index: 2, Status: model.BulkItemFailed},
+		{Index: 3, Status: model.BulkItemSucceeded},
+	})
+
+	assert.Equal(t, 4, report.Total)
+	assert.Equal(t, 2, report.Succeeded)
+	assert.Equal(t, 1, report.Duplicates)
+	assert.Equal(t, 1, report.Failed)
+	assert.Len(t, report.Results, 4)
+}
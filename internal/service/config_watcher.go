@@ -0,0 +1,214 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ConfigWatcher reloads the configuration when one of its files or the feature flags file changes and applies
+// the settings that can change at runtime: the log level, the tracing sampling ratio, the default spend limits,
+// the rate limits and the feature flags. Other changes are logged and take effect at the next restart. An
+// invalid configuration or feature flags file is rejected and the current one stays in effect.
+type ConfigWatcher struct {
+	source    config.Source
+	debounce  time.Duration
+	files     map[string]bool
+	flagsFile string
+	wallets   *WalletService
+	limiter   *RateLimitService
+	flags     *FeatureFlagService
+	obs       *observability.Observability
+	logger    *zap.Logger
+
+	mu      sync.Mutex
+	current *config.Config
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewConfigWatcher creates a watcher and registers its lifecycle hooks.
+// The watcher does nothing when reload is disabled in the configuration or no file was read. The feature
+// flags file is the one the application started with: a new FEATURE_FLAGS_FILE applies at the next restart.
+func NewConfigWatcher(lc fx.Lifecycle, cfg *config.Config, wallets *WalletService, limiter *RateLimitService,
+	flags *FeatureFlagService, obs *observability.Observability) *ConfigWatcher {
+
+	watcher := &ConfigWatcher{
+		source:    config.SourceFromEnv(),
+		debounce:  cfg.Reload.Debounce,
+		files:     make(map[string]bool),
+		flagsFile: cfg.FeatureFlags.File,
+		wallets:   wallets,
+		limiter:   limiter,
+		flags:     flags,
+		obs:       obs,
+		logger:    obs.Logger.Logger.With(zap.String("component", "config_watcher")),
+		current:   cfg,
+	}
+
+	for _, file := range cfg.Files() {
+		watcher.files[filepath.Clean(file)] = true
+	}
+	if watcher.flagsFile != "" {
+		watcher.files[filepath.Clean(watcher.flagsFile)] = true
+	}
+
+	if !cfg.Reload.Enabled || len(watcher.files) == 0 {
+		watcher.logger.Info("Configuration reload disabled", zap.Bool("enabled", cfg.Reload.Enabled))
+		return watcher
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// Files are replaced rather than written in place by editors and mounted volumes, so their
+			// directories are watched
+			fsWatcher, err := fsnotify.NewWatcher()
+			if err != nil {
+				return fmt.Errorf("create config watcher: %w", err)
+			}
+			for dir := range watcher.dirs() {
+				if err := fsWatcher.Add(dir); err != nil {
+					fsWatcher.Close()
+					return fmt.Errorf("watch config directory %s: %w", dir, err)
+				}
+			}
+
+			watcher.logger.Info("Starting config watcher",
+				zap.Strings("files", cfg.Files()),
+				zap.String("feature_flags_file", watcher.flagsFile),
+				zap.Duration("debounce", watcher.debounce))
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			watcher.cancel = cancel
+			watcher.done = make(chan struct{})
+			go watcher.loop(runCtx, fsWatcher)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			watcher.logger.Info("Stopping config watcher")
+			watcher.cancel()
+
+			select {
+			case <-watcher.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return watcher
+}
+
+// dirs returns the directories of the watched files
+func (w *ConfigWatcher) dirs() map[string]bool {
+	dirs := make(map[string]bool, len(w.files))
+	for file := range w.files {
+		dirs[filepath.Dir(file)] = true
+	}
+	return dirs
+}
+
+// concerns reports whether a change to path may change a watched file. Mounted volumes swap the files of a
+// directory by renaming hidden entries such as ..data.
+func (w *ConfigWatcher) concerns(path string) bool {
+	return w.files[filepath.Clean(path)] || strings.HasPrefix(filepath.Base(path), "..")
+}
+
+func (w *ConfigWatcher) loop(ctx context.Context, fsWatcher *fsnotify.Watcher) {
+	defer close(w.done)
+	defer fsWatcher.Close()
+
+	timer := time.NewTimer(w.debounce)
+	timer.Stop()
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-fsWatcher.Events:
+			if !ok {
+				return
+			}
+			if w.concerns(event.Name) {
+				timer.Reset(w.debounce)
+			}
+		case err, ok := <-fsWatcher.Errors:
+			if !ok {
+				return
+			}
+			w.logger.Error("Config watcher error", zap.Error(err))
+		case <-timer.C:
+			// Failures are logged by Reload
+			_, _ = w.Reload()
+		}
+	}
+}
+
+// Reload loads the configuration and the feature flags file again and applies the settings that can change
+// at runtime. It returns the settings that changed; the current configuration and flags are kept when either
+// the new configuration or the new flags are invalid.
+func (w *ConfigWatcher) Reload() ([]config.Change, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	next, err := config.Load(w.source)
+	if err != nil {
+		w.logger.Error("Configuration reload rejected, keeping the current configuration", zap.Error(err))
+		w.obs.Metrics.RecordWalletOperation("config_reload", "error")
+		return nil, err
+	}
+
+	var flags []*feature.Flag
+	if w.flagsFile != "" {
+		flags, err = feature.LoadFlags(w.flagsFile)
+		if err != nil {
+			w.logger.Error("Feature flags reload rejected, keeping the current configuration", zap.Error(err))
+			w.obs.Metrics.RecordWalletOperation("config_reload", "error")
+			return nil, err
+		}
+	}
+
+	changes := config.Diff(w.current, next)
+	for _, change := range changes {
+		fields := []zap.Field{
+			zap.String("setting", change.Name),
+			zap.String("old", change.Old),
+			zap.String("new", change.New),
+		}
+		if change.Runtime() {
+			w.logger.Info("Configuration setting changed", fields...)
+		} else {
+			w.logger.Warn("Configuration setting changed, applied at the next restart", fields...)
+		}
+	}
+
+	if err := w.obs.Logger.SetLevel(next.App.LogLevel); err != nil {
+		w.logger.Warn("Failed to change log level", zap.Error(err))
+	}
+	w.obs.Tracer.SetSamplingRatio(next.Observability.Tracing.SamplingRatio)
+	w.wallets.SetSpendLimits(next.SpendLimits)
+	w.limiter.SetLimits(next.Server.RateLimit)
+	if w.flagsFile != "" {
+		w.flags.SetDefinedFlags(flags)
+		w.logger.Info("Feature flags reloaded", zap.Strings("flags", w.flags.flagNames()))
+	}
+
+	w.current = next
+	w.obs.Metrics.RecordWalletOperation("config_reload", "success")
+
+	return changes, nil
+}
@@ -0,0 +1,97 @@
+package service
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+func TestConfigWatcherReload(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "wallet.yaml")
+	writeConfig := func(content string) {
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	}
+
+	writeConfig("spend_limit:\n  daily_cap: 100\n")
+	t.Setenv(config.EnvConfigFile, path)
+
+	flagsPath := filepath.Join(t.TempDir(), "feature_flags.json")
+	writeFlags := func(content string) {
+		require.NoError(t, os.WriteFile(flagsPath, []byte(content), 0o600))
+	}
+	writeFlags(`{"flags": [{"name": "bulk", "enabled": false}]}`)
+	t.Setenv("FEATURE_FLAGS_FILE", flagsPath)
+
+	cfg, err := config.LoadConfig()
+	require.NoError(t, err)
+
+	obs := observability.NewTestObservability()
+	wallets := &WalletService{}
+	wallets.SetSpendLimits(cfg.SpendLimits)
+
+	limiter := NewRateLimitService(RateLimitServiceParams{
+		Lifecycle: fxtest.NewLifecycle(t),
+		Config:    cfg,
+		Obs:       obs,
+	})
+
+	flags, err := NewFeatureFlagService(fxtest.NewLifecycle(t), nil, cfg, obs)
+	require.NoError(t, err)
+
+	watcher := NewConfigWatcher(fxtest.NewLifecycle(t), cfg, wallets, limiter, flags, obs)
+	assert.True(t, watcher.concerns(path))
+	assert.True(t, watcher.concerns(flagsPath))
+	assert.False(t, watcher.concerns(filepath.Join(filepath.Dir(path), "other.yaml")))
+
+	t.Run("Applies Runtime Settings", func(t *testing.T) {
+		writeConfig("spend_limit:\n  daily_cap: 200\nserver:\n  port: 4000\nrate_limit:\n  default: 5/1s\n")
+
+		changes, err := watcher.Reload()
+		require.NoError(t, err)
+		require.Len(t, changes, 3)
+		assert.Equal(t, "RATE_LIMIT_DEFAULT", changes[0].Name)
+		assert.True(t, changes[0].Runtime())
+		assert.Equal(t, config.Change{Name: "SERVER_PORT", Old: "3000", New: "4000"}, changes[1])
+		assert.False(t, changes[1].Runtime())
+		assert.Equal(t, "SPEND_LIMIT_DAILY_CAP", changes[2].Name)
+		assert.True(t, changes[2].Runtime())
+		assert.Equal(t, 200.0, wallets.spendLimits.Load().DailyCap)
+		assert.Equal(t, 5, limiter.limits.Load().config.Default.Requests)
+	})
+
+	t.Run("Rejects Invalid Configuration", func(t *testing.T) {
+		writeConfig("spend_limit:\n  daily_cap: -5\n")
+
+		_, err := watcher.Reload()
+		assert.Error(t, err)
+		assert.Equal(t, 200.0, wallets.spendLimits.Load().DailyCap)
+
+		writeConfig("spend_limit:\n  daily_cap: 200\nserver:\n  port: 4000\nrate_limit:\n  default: 5/1s\n")
+		changes, err := watcher.Reload()
+		require.NoError(t, err)
+		assert.Empty(t, changes, "the rejected configuration never replaced the current one")
+	})
+
+	t.Run("Reloads Feature Flags", func(t *testing.T) {
+		subject := feature.Subject{UserID: 1, Role: "user"}
+		assert.False(t, flags.IsEnabled("bulk", subject))
+
+		writeFlags(`{"flags": [{"name": "bulk", "enabled": true, "percentage": 100}]}`)
+		_, err := watcher.Reload()
+		require.NoError(t, err)
+		assert.True(t, flags.IsEnabled("bulk", subject))
+
+		writeFlags(`{"flags": [{"name": "bulk", "enabled": true, "percentage": 150}]}`)
+		_, err = watcher.Reload()
+		assert.Error(t, err)
+		assert.True(t, flags.IsEnabled("bulk", subject), "the rejected flags never replaced the current ones")
+	})
+}
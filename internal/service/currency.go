@@ -0,0 +1,33 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+)
+
+// currencySettings returns the configured currencies, falling back to the single platform currency
+// when none are configured
+func currencySettings(cfg config.CurrencyConfig) config.CurrencyConfig {
+	if cfg.Default == "" {
+		return config.CurrencyConfig{
+			Default:   model.CurrencyPlatform,
+			Supported: []string{model.CurrencyPlatform},
+		}
+	}
+	return cfg
+}
+
+// resolveCurrency returns the currency a request applies to: the default one when the request names none
+func resolveCurrency(cfg config.CurrencyConfig, currency string) (string, error) {
+	if currency == "" {
+		return cfg.Default, nil
+	}
+
+	if !cfg.IsSupported(currency) {
+		return "", fmt.Errorf("%w: %s", ErrUnsupportedCurrency, currency)
+	}
+
+	return currency, nil
+}
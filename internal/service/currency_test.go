@@ -0,0 +1,53 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveCurrency(t *testing.T) {
+	cfg := config.CurrencyConfig{Default: "coins", Supported: []string{"coins", "gems"}}
+
+	testCases := []struct {
+		name             string
+		currency         string
+		expectedCurrency string
+		expectedErr      error
+	}{
+		{name: "Default Currency", currency: "", expectedCurrency: "coins"},
+		{name: "Supported Currency", currency: "gems", expectedCurrency: "gems"},
+		{name: "Unsupported Currency", currency: "gold", expectedErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			currency, err := resolveCurrency(cfg, tc.currency)
+
+			if tc.expectedErr != nil {
+				assert.ErrorIs(t, err, tc.expectedErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expectedCurrency, currency)
+		})
+	}
+}
+
+func TestCurrencySettings(t *testing.T) {
+	t.Run("Unconfigured Falls Back To Platform", func(t *testing.T) {
+		cfg := currencySettings(config.CurrencyConfig{})
+
+		assert.Equal(t, model.CurrencyPlatform, cfg.Default)
+		assert.Equal(t, []string{model.CurrencyPlatform}, cfg.Supported)
+	})
+
+	t.Run("Configured Kept", func(t *testing.T) {
+		configured := config.CurrencyConfig{Default: "coins", Supported: []string{"coins", "gems"}}
+
+		assert.Equal(t, configured, currencySettings(configured))
+	})
+}
@@ -0,0 +1,157 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Domain errors returned by the services. Handlers map them to HTTP status codes with errors.Is.
+var (
+	// ErrWalletNotFound is returned when an operation requires an existing wallet
+	ErrWalletNotFound = errors.New("wallet not found")
+
+	// ErrWalletFrozen is returned when a mutating operation targets a frozen wallet
+	ErrWalletFrozen = errors.New("wallet is frozen")
+
+	// ErrWalletClosed is returned when a mutating operation targets a closed wallet
+	ErrWalletClosed = errors.New("wallet is closed")
+
+	// ErrUnsupportedCurrency is returned when a request names a currency wallets may not hold
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+
+	// ErrInsufficientFunds is returned when a debit is above the wallet balance
+	ErrInsufficientFunds = errors.New("insufficient funds")
+
+	// ErrExpiryNotInFuture is returned when a grant would expire at or before the time it is made
+	ErrExpiryNotInFuture = errors.New("expiry must be in the future")
+
+	// ErrInvalidStatusTransition is returned when a wallet cannot move to the requested status
+	ErrInvalidStatusTransition = errors.New("invalid wallet status transition")
+
+	// ErrSpendLimitExceeded is returned when a spend would break one of the user's spend limits
+	ErrSpendLimitExceeded = errors.New("spend limit exceeded")
+
+	// ErrExchangeCapExceeded is returned when an exchange would break one of the caps of a game token
+	ErrExchangeCapExceeded = errors.New("exchange cap exceeded")
+
+	// ErrReverseExchangeDisabled is returned when reverse exchanges are turned off
+	ErrReverseExchangeDisabled = errors.New("reverse exchange is disabled")
+
+	// ErrReverseExchangeNotSupported is returned when a game token has no rate from the platform currency
+	ErrReverseExchangeNotSupported = errors.New("reverse exchange not supported")
+
+	// ErrInvalidGrant is returned when a grant token is malformed or not signed by this service
+	ErrInvalidGrant = errors.New("invalid grant")
+
+	// ErrGrantNotFound is returned when a validly signed grant has no record
+	ErrGrantNotFound = errors.New("grant not found")
+
+	// ErrGrantAlreadyRedeemed is returned when a grant is redeemed a second time
+	ErrGrantAlreadyRedeemed = errors.New("grant already redeemed")
+
+	// ErrGrantExpired is returned when a grant is redeemed after it expired
+	ErrGrantExpired = errors.New("grant expired")
+
+	// ErrGrantGameMismatch is returned when a game server redeems a grant issued for another game
+	ErrGrantGameMismatch = errors.New("grant issued for another game")
+
+	// ErrBulkTooLarge is returned when a bulk request holds more items than its mode accepts
+	ErrBulkTooLarge = errors.New("too many bulk items")
+
+	// ErrBulkJobNotFound is returned when a bulk job does not exist
+	ErrBulkJobNotFound = errors.New("bulk job not found")
+
+	// ErrInvalidBulkItem is returned for a bulk item that cannot be applied as requested
+	ErrInvalidBulkItem = errors.New("invalid bulk item")
+
+	// ErrIdempotencyKeyReused is returned when an idempotency key was applied to a different item
+	ErrIdempotencyKeyReused = errors.New("idempotency key reused for a different item")
+
+	// ErrJobNotFound is returned when a background job does not exist
+	ErrJobNotFound = errors.New("job not found")
+
+	// ErrJobFinished is returned when cancelling a job that already finished
+	ErrJobFinished = errors.New("job already finished")
+
+	// ErrInvalidExportFilter is returned when the filter of an export cannot be parsed
+	ErrInvalidExportFilter = errors.New("invalid export filter")
+
+	// ErrInvalidStatementPeriod is returned when a statement period is not a month in the YYYY-MM format
+	ErrInvalidStatementPeriod = errors.New("invalid statement period")
+
+	// ErrStatementPeriodOpen is returned when a statement is requested before its month has ended and settled
+	ErrStatementPeriodOpen = errors.New("statement period has not ended")
+
+	// ErrStatementNotAvailable is returned for a statement of a month that ended before the wallet was created
+	ErrStatementNotAvailable = errors.New("no statement for a period before the wallet was created")
+
+	// ErrStatementArchived is returned for a statement that was not stored before the logs of its month were archived
+	ErrStatementArchived = errors.New("logs of the statement period were archived")
+
+	// ErrInvalidBalanceTime is returned when a point-in-time balance is requested for an unparsable or future time
+	ErrInvalidBalanceTime = errors.New("invalid balance time")
+
+	// ErrBalanceArchived is returned for a point-in-time balance of a time before the newest archived log of the wallet
+	ErrBalanceArchived = errors.New("logs before the balance time were archived")
+
+	// ErrInvalidReportRange is returned when the date range of a report cannot be parsed, is empty or is too long
+	ErrInvalidReportRange = errors.New("invalid report range")
+
+	// ErrInvalidAuditFilter is returned when the date range of an audit log query cannot be parsed or is empty
+	ErrInvalidAuditFilter = errors.New("invalid audit log filter")
+
+	// ErrJobNotRetryable is wrapped by job workers to fail a job without further attempts
+	ErrJobNotRetryable = errors.New("job cannot be retried")
+
+	// ErrOperationDenied is returned when the risk evaluator denies an operation
+	ErrOperationDenied = errors.New("operation denied by risk evaluation")
+
+	// ErrOperationUnderReview is returned when the risk evaluator holds an operation for manual review
+	ErrOperationUnderReview = errors.New("operation placed under manual review")
+
+	// ErrRiskReviewNotFound is returned when a risk review does not exist or was already decided
+	ErrRiskReviewNotFound = errors.New("risk review not found or already decided")
+
+	// ErrReconciliationInProgress is returned when a reconciliation run is already executing
+	ErrReconciliationInProgress = errors.New("reconciliation already in progress")
+
+	// ErrMismatchNotFound is returned when a reconciliation mismatch does not exist or is already resolved
+	ErrMismatchNotFound = errors.New("reconciliation mismatch not found or already resolved")
+
+	// ErrPartitionNotRolledUp is returned when a wallet log partition is due for archival before the report
+	// rollups covered all of its logs
+	ErrPartitionNotRolledUp = errors.New("wallet log partition is not rolled up yet")
+
+	// ErrFeatureDisabled is returned when an operation is behind a feature flag that is off for the user
+	ErrFeatureDisabled = errors.New("feature is not available")
+
+	// ErrFeatureFlagNotFound is returned when a feature flag is not defined in the feature flags file
+	ErrFeatureFlagNotFound = errors.New("feature flag not found")
+)
+
+// Spend limit errors; each wraps ErrSpendLimitExceeded
+var (
+	// ErrTransactionLimitExceeded is returned when a single spend is above the per-transaction maximum
+	ErrTransactionLimitExceeded = fmt.Errorf("%w: per-transaction maximum", ErrSpendLimitExceeded)
+
+	// ErrDailySpendLimitExceeded is returned when a spend would exceed the daily cap
+	ErrDailySpendLimitExceeded = fmt.Errorf("%w: daily cap", ErrSpendLimitExceeded)
+
+	// ErrWeeklySpendLimitExceeded is returned when a spend would exceed the weekly cap
+	ErrWeeklySpendLimitExceeded = fmt.Errorf("%w: weekly cap", ErrSpendLimitExceeded)
+
+	// ErrSpendVelocityExceeded is returned when a user spends more often than allowed per minute
+	ErrSpendVelocityExceeded = fmt.Errorf("%w: too many spends per minute", ErrSpendLimitExceeded)
+)
+
+// Exchange cap errors; each wraps ErrExchangeCapExceeded
+var (
+	// ErrExchangeTransactionCapExceeded is returned when a single exchange is above the per-transaction maximum
+	ErrExchangeTransactionCapExceeded = fmt.Errorf("%w: per-transaction maximum", ErrExchangeCapExceeded)
+
+	// ErrExchangeUserDailyCapExceeded is returned when a user would exchange more than the daily cap of a game token
+	ErrExchangeUserDailyCapExceeded = fmt.Errorf("%w: per-user daily cap", ErrExchangeCapExceeded)
+
+	// ErrExchangeGameDailyCapExceeded is returned when all users together would exchange more than the game's daily cap
+	ErrExchangeGameDailyCapExceeded = fmt.Errorf("%w: game daily cap", ErrExchangeCapExceeded)
+)
@@ -0,0 +1,78 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+)
+
+// Exchange cap names used in metrics
+const (
+	exchangeCapPerTransaction = "per_transaction"
+	exchangeCapUserDaily      = "user_daily"
+	exchangeCapGameDaily      = "game_daily"
+)
+
+// hasExchangeCaps reports whether any cap is configured for an exchange rate
+func hasExchangeCaps(rate *model.ExchangeRate) bool {
+	return rate.MaxPerTransaction != nil || rate.UserDailyCap != nil || rate.GameDailyCap != nil
+}
+
+// checkExchangeCaps returns the name of the cap an exchange of amount game tokens would break and its error
+func checkExchangeCaps(rate *model.ExchangeRate, usage model.ExchangeUsage, amount float64) (string, error) {
+	if rate.MaxPerTransaction != nil && amount > *rate.MaxPerTransaction {
+		return exchangeCapPerTransaction, fmt.Errorf("%w: amount %.2f is above %.2f for game_id=%s and token_type=%s",
+			ErrExchangeTransactionCapExceeded, amount, *rate.MaxPerTransaction, rate.GameID, rate.TokenType)
+	}
+
+	if rate.UserDailyCap != nil && roundCents(usage.UserExchangedLastDay+amount) > *rate.UserDailyCap {
+		return exchangeCapUserDaily, fmt.Errorf("%w: remaining %.2f, required %.2f for game_id=%s and token_type=%s",
+			ErrExchangeUserDailyCapExceeded, remainingAllowance(*rate.UserDailyCap, usage.UserExchangedLastDay),
+			amount, rate.GameID, rate.TokenType)
+	}
+
+	if rate.GameDailyCap != nil && roundCents(usage.GameExchangedLastDay+amount) > *rate.GameDailyCap {
+		return exchangeCapGameDaily, fmt.Errorf("%w: remaining %.2f, required %.2f for game_id=%s and token_type=%s",
+			ErrExchangeGameDailyCapExceeded, remainingAllowance(*rate.GameDailyCap, usage.GameExchangedLastDay),
+			amount, rate.GameID, rate.TokenType)
+	}
+
+	return "", nil
+}
+
+// enforceExchangeCaps checks an exchange against the caps of its game token. The caller must hold the wallet
+// lock so concurrent exchanges of the user are serialized. When a game-wide cap is set the rate row is locked
+// too so concurrent exchanges of different users cannot overshoot it together.
+func (s *WalletService) enforceExchangeCaps(ctx context.Context, userID int, rate *model.ExchangeRate,
+	amount float64, tx repository.Transaction) error {
+
+	if !hasExchangeCaps(rate) {
+		return nil
+	}
+
+	var usage model.ExchangeUsage
+	if rate.UserDailyCap != nil || rate.GameDailyCap != nil {
+		if rate.GameDailyCap != nil {
+			if err := s.repo.LockExchangeRate(ctx, rate.ID, tx); err != nil {
+				return err
+			}
+		}
+
+		current, err := s.repo.GetExchangeUsage(ctx, userID, rate.GameID, rate.TokenType, rate.Currency, time.Now(), tx)
+		if err != nil {
+			return err
+		}
+		usage = *current
+	}
+
+	capName, err := checkExchangeCaps(rate, usage, amount)
+	if err != nil {
+		s.metrics.RecordExchangeCapHit(rate.GameID, rate.TokenType, capName)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,193 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckExchangeCaps(t *testing.T) {
+	maxPerTransaction := 1000.0
+	userDailyCap := 5000.0
+	gameDailyCap := 100000.0
+
+	capped := &model.ExchangeRate{
+		GameID:            "game1",
+		TokenType:         "gold",
+		MaxPerTransaction: &maxPerTransaction,
+		UserDailyCap:      &userDailyCap,
+		GameDailyCap:      &gameDailyCap,
+	}
+
+	testCases := []struct {
+		name          string
+		rate          *model.ExchangeRate
+		usage         model.ExchangeUsage
+		amount        float64
+		expectedCap   string
+		expectedError error
+	}{
+		{
+			name:   "Within Caps",
+			rate:   capped,
+			usage:  model.ExchangeUsage{UserExchangedLastDay: 1000, GameExchangedLastDay: 50000},
+			amount: 500,
+		},
+		{
+			name:   "Exactly Reaches User Daily Cap",
+			rate:   capped,
+			usage:  model.ExchangeUsage{UserExchangedLastDay: 4000, GameExchangedLastDay: 4000},
+			amount: 1000,
+		},
+		{
+			name:          "Above Per-Transaction Maximum",
+			rate:          capped,
+			amount:        1000.01,
+			expectedCap:   exchangeCapPerTransaction,
+			expectedError: ErrExchangeTransactionCapExceeded,
+		},
+		{
+			name:          "User Daily Cap Exceeded",
+			rate:          capped,
+			usage:         model.ExchangeUsage{UserExchangedLastDay: 4500, GameExchangedLastDay: 4500},
+			amount:        600,
+			expectedCap:   exchangeCapUserDaily,
+			expectedError: ErrExchangeUserDailyCapExceeded,
+		},
+		{
+			name:          "Game Daily Cap Exceeded",
+			rate:          capped,
+			usage:         model.ExchangeUsage{UserExchangedLastDay: 0, GameExchangedLastDay: 99900},
+			amount:        200,
+			expectedCap:   exchangeCapGameDaily,
+			expectedError: ErrExchangeGameDailyCapExceeded,
+		},
+		{
+			name:   "No Caps",
+			rate:   &model.ExchangeRate{GameID: "game2", TokenType: "gems"},
+			usage:  model.ExchangeUsage{UserExchangedLastDay: 1e9, GameExchangedLastDay: 1e12},
+			amount: 1e9,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			capName, err := checkExchangeCaps(tc.rate, tc.usage, tc.amount)
+
+			assert.Equal(t, tc.expectedCap, capName)
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.ErrorIs(t, err, ErrExchangeCapExceeded)
+		})
+	}
+}
+
+func TestCheckReverseExchangeCaps(t *testing.T) {
+	maxPerTransaction := 100.0
+	userDailyCap := 250.0
+
+	capped := &model.ExchangeRate{
+		GameID:                   "game1",
+		TokenType:                "gold",
+		ReverseMaxPerTransaction: &maxPerTransaction,
+		ReverseUserDailyCap:      &userDailyCap,
+	}
+
+	testCases := []struct {
+		name          string
+		rate          *model.ExchangeRate
+		usedLastDay   float64
+		amount        float64
+		expectedCap   string
+		expectedError error
+	}{
+		{
+			name:        "Within Caps",
+			rate:        capped,
+			usedLastDay: 100,
+			amount:      50,
+		},
+		{
+			name:        "Exactly Reaches User Daily Cap",
+			rate:        capped,
+			usedLastDay: 150,
+			amount:      100,
+		},
+		{
+			name:          "Above Per-Transaction Maximum",
+			rate:          capped,
+			amount:        100.01,
+			expectedCap:   reverseExchangeCapPerTransaction,
+			expectedError: ErrExchangeTransactionCapExceeded,
+		},
+		{
+			name:          "User Daily Cap Exceeded",
+			rate:          capped,
+			usedLastDay:   200,
+			amount:        60,
+			expectedCap:   reverseExchangeCapUserDaily,
+			expectedError: ErrExchangeUserDailyCapExceeded,
+		},
+		{
+			name:        "Forward Caps Do Not Apply",
+			rate:        &model.ExchangeRate{GameID: "game2", TokenType: "gems", MaxPerTransaction: &maxPerTransaction},
+			usedLastDay: 1e9,
+			amount:      1e9,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			capName, err := checkReverseExchangeCaps(tc.rate, tc.usedLastDay, tc.amount)
+
+			assert.Equal(t, tc.expectedCap, capName)
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.ErrorIs(t, err, ErrExchangeCapExceeded)
+		})
+	}
+}
+
+func TestExchangeChecksCapsUnderWalletLock(t *testing.T) {
+	userDailyCap := 5000.0
+	repo := &stubExchangeRepository{
+		rate: &model.ExchangeRate{
+			ID:              1,
+			GameID:          "game1",
+			TokenType:       "gold",
+			Currency:        model.CurrencyPlatform,
+			ToPlatformRatio: 1,
+			UserDailyCap:    &userDailyCap,
+		},
+		usage: model.ExchangeUsage{UserExchangedLastDay: 4900},
+	}
+
+	obs := observability.NewTestObservability()
+	service := NewWalletService(repo, nil, nil, nil, &config.Config{}, obs)
+
+	_, err := service.Exchange(context.Background(), &dto.ExchangeRequest{
+		UserID:    1,
+		GameID:    "game1",
+		TokenType: "gold",
+		Amount:    200,
+		Source:    "won",
+	})
+	assert.ErrorIs(t, err, ErrExchangeUserDailyCapExceeded)
+
+	// The usage is read once the wallet is locked, or concurrent exchanges of the user could both pass
+	assert.Equal(t, []string{"BeginTx", "GetWalletByUserIDForUpdate", "GetExchangeUsage"}, repo.calls)
+}
@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ExpiryScheduler expires balance lots and refunds expired reverse exchange grants periodically
+// for the lifetime of the application
+type ExpiryScheduler struct {
+	service WalletServiceInterface
+	expire  bool
+	refund  bool
+	logger  *zap.Logger
+}
+
+// NewExpiryScheduler creates a scheduler and registers its lifecycle hooks.
+// Lots are only expired when expiry is enabled in the configuration, otherwise expired lots are
+// removed when their wallet spends. Grants are refunded whenever reverse exchange is enabled.
+func NewExpiryScheduler(lc fx.Lifecycle, svc WalletServiceInterface,
+	cfg *config.Config, obs *observability.Observability) *ExpiryScheduler {
+
+	scheduler := &ExpiryScheduler{
+		service: svc,
+		expire:  cfg.Expiry.Enabled,
+		refund:  cfg.ReverseExchange.Enabled,
+		logger:  obs.Logger.Logger.With(zap.String("component", "expiry_scheduler")),
+	}
+
+	if !scheduler.expire && !scheduler.refund {
+		scheduler.logger.Info("Scheduled balance expiry disabled")
+		return scheduler
+	}
+
+	startPeriodicTask(lc, scheduler.logger, periodicTask{
+		name:     "expiry scheduler",
+		schedule: every(cfg.Expiry.Interval),
+		run:      scheduler.run,
+	},
+		zap.Duration("interval", cfg.Expiry.Interval),
+		zap.Bool("expire_lots", scheduler.expire),
+		zap.Bool("refund_grants", scheduler.refund))
+
+	return scheduler
+}
+
+func (s *ExpiryScheduler) run(ctx context.Context) {
+	if s.expire {
+		s.expireLots(ctx)
+	}
+	if s.refund {
+		s.refundGrants(ctx)
+	}
+}
+
+func (s *ExpiryScheduler) expireLots(ctx context.Context) {
+	report, err := s.service.ExpireBalanceLots(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled balance expiry failed", zap.Error(err))
+		return
+	}
+	if report != nil && report.LotsExpired > 0 {
+		s.logger.Info("Scheduled balance expiry completed",
+			zap.Int("wallets_processed", report.WalletsProcessed),
+			zap.Int("lots_expired", report.LotsExpired),
+			zap.Float64("amount_expired", report.AmountExpired))
+	}
+}
+
+func (s *ExpiryScheduler) refundGrants(ctx context.Context) {
+	report, err := s.service.RefundExpiredReverseExchangeGrants(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled reverse exchange grant refund failed", zap.Error(err))
+		return
+	}
+	if report != nil && report.GrantsRefunded > 0 {
+		s.logger.Info("Scheduled reverse exchange grant refund completed",
+			zap.Int("grants_refunded", report.GrantsRefunded),
+			zap.Float64("amount_refunded", report.AmountRefunded))
+	}
+}
@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Export formats
+const (
+	ExportFormatCSV    = "csv"
+	ExportFormatNDJSON = "ndjson"
+)
+
+// walletLogColumns are the columns of CSV exports, in the order of dto.WalletLogExportRow
+var walletLogColumns = []string{
+	"id", "wallet_id", "user_id", "currency", "game_id", "token_type",
+	"amount", "platform_amount", "source", "reference_id", "created_at",
+}
+
+// ExportService streams wallet data to files for finance and support
+type ExportService struct {
+	repo    repository.ExportRepository
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	tracer  *tracing.Tracer
+}
+
+// Compile-time verification that ExportService implements ExportServiceInterface
+var _ ExportServiceInterface = (*ExportService)(nil)
+
+// NewExportService creates a new export service
+func NewExportService(repo repository.ExportRepository, obs *observability.Observability) *ExportService {
+	return &ExportService{
+		repo:    repo,
+		logger:  obs.Logger.Logger.With(zap.String("component", "export_service")),
+		metrics: obs.Metrics,
+		tracer:  obs.Tracer,
+	}
+}
+
+// NewWalletLogFilter converts the filter of an export request, returning ErrInvalidExportFilter
+// when a date cannot be parsed or the range is empty
+func NewWalletLogFilter(req *dto.WalletLogExportRequest) (model.WalletLogFilter, error) {
+	var filter model.WalletLogFilter
+
+	optional := func(value string) *string {
+		if value == "" {
+			return nil
+		}
+		return &value
+	}
+
+	if req.UserID != 0 {
+		userID := req.UserID
+		filter.UserID = &userID
+	}
+	filter.GameID = optional(req.GameID)
+	filter.TokenType = optional(req.TokenType)
+	filter.Currency = optional(req.Currency)
+	filter.Source = optional(req.Source)
+
+	for _, bound := range []struct {
+		name   string
+		value  string
+		target **time.Time
+	}{
+		{"from", req.From, &filter.From},
+		{"to", req.To, &filter.To},
+	} {
+		if bound.value == "" {
+			continue
+		}
+		parsed, err := ParseTimestamp(bound.value)
+		if err != nil {
+			return model.WalletLogFilter{}, fmt.Errorf("%w: %s must be an RFC 3339 timestamp or a date (YYYY-MM-DD)",
+				ErrInvalidExportFilter, bound.name)
+		}
+		*bound.target = &parsed
+	}
+
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return model.WalletLogFilter{}, fmt.Errorf("%w: from must be before to", ErrInvalidExportFilter)
+	}
+
+	return filter, nil
+}
+
+// ParseTimestamp parses an RFC 3339 timestamp or a date, which stands for its midnight in UTC
+func ParseTimestamp(value string) (time.Time, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed.UTC(), nil
+	}
+	return time.Parse(time.DateOnly, value)
+}
+
+// walletLogWriter encodes wallet logs one at a time
+type walletLogWriter interface {
+	Write(log *model.WalletLog) error
+	Flush() error
+}
+
+// newWalletLogWriter returns a writer of the format; CSV exports start with a header row
+func newWalletLogWriter(format string, w io.Writer) (walletLogWriter, error) {
+	switch format {
+	case ExportFormatCSV:
+		writer := csv.NewWriter(w)
+		if err := writer.Write(walletLogColumns); err != nil {
+			return nil, err
+		}
+		return &csvWalletLogWriter{writer: writer}, nil
+	case ExportFormatNDJSON:
+		return &ndjsonWalletLogWriter{encoder: json.NewEncoder(w)}, nil
+	default:
+		return nil, fmt.Errorf("%w: unknown format %q", ErrInvalidExportFilter, format)
+	}
+}
+
+type csvWalletLogWriter struct {
+	writer *csv.Writer
+}
+
+func (w *csvWalletLogWriter) Write(log *model.WalletLog) error {
+	optional := func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	}
+
+	return w.writer.Write([]string{
+		strconv.FormatInt(log.ID, 10),
+		strconv.FormatInt(log.WalletID, 10),
+		strconv.Itoa(log.UserID),
+		log.Currency,
+		optional(log.GameID),
+		optional(log.TokenType),
+		strconv.FormatFloat(log.Amount, 'f', 2, 64),
+		strconv.FormatFloat(log.PlatformAmount, 'f', 2, 64),
+		log.Source,
+		optional(log.ReferenceID),
+		log.CreatedAt.UTC().Format(time.RFC3339Nano),
+	})
+}
+
+func (w *csvWalletLogWriter) Flush() error {
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+type ndjsonWalletLogWriter struct {
+	encoder *json.Encoder
+}
+
+func (w *ndjsonWalletLogWriter) Write(log *model.WalletLog) error {
+	return w.encoder.Encode(dto.WalletLogExportRow{
+		ID:             log.ID,
+		WalletID:       log.WalletID,
+		UserID:         log.UserID,
+		Currency:       log.Currency,
+		GameID:         log.GameID,
+		TokenType:      log.TokenType,
+		Amount:         log.Amount,
+		PlatformAmount: log.PlatformAmount,
+		Source:         log.Source,
+		ReferenceID:    log.ReferenceID,
+		CreatedAt:      log.CreatedAt.UTC(),
+	})
+}
+
+func (w *ndjsonWalletLogWriter) Flush() error {
+	return nil
+}
+
+// ExportWalletLogs writes the logs matching the filter to w, oldest first, as they are read from the
+// database. The logs come from one snapshot, so logs written meanwhile are left out. When the export
+// fails midway w holds the logs written until then.
+func (s *ExportService) ExportWalletLogs(ctx context.Context, filter model.WalletLogFilter, format string,
+	w io.Writer) (int, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "ExportService.ExportWalletLogs",
+		trace.WithAttributes(attribute.String("format", format)))
+	defer span.End()
+
+	writer, err := newWalletLogWriter(format, w)
+	if err != nil {
+		s.metrics.RecordWalletOperation("export", "error")
+		return 0, err
+	}
+
+	count := 0
+	err = s.repo.StreamWalletLogs(ctx, filter, func(log *model.WalletLog) error {
+		if err := writer.Write(log); err != nil {
+			return fmt.Errorf("write wallet log: %w", err)
+		}
+		count++
+		return nil
+	})
+	if flushErr := writer.Flush(); err == nil && flushErr != nil {
+		err = fmt.Errorf("write wallet logs: %w", flushErr)
+	}
+
+	if err != nil {
+		s.logger.Error("Wallet log export failed",
+			zap.String("format", format),
+			zap.Int("logs_written", count),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("export", "error")
+		return count, err
+	}
+
+	s.logger.Info("Wallet logs exported",
+		zap.String("format", format),
+		zap.Int("logs", count))
+	s.metrics.RecordWalletOperation("export", "success")
+
+	return count, nil
+}
@@ -0,0 +1,103 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewWalletLogFilter(t *testing.T) {
+	t.Run("Empty Request Matches All", func(t *testing.T) {
+		filter, err := NewWalletLogFilter(&dto.WalletLogExportRequest{})
+		require.NoError(t, err)
+		assert.Equal(t, model.WalletLogFilter{}, filter)
+	})
+
+	t.Run("Fields And Dates", func(t *testing.T) {
+		filter, err := NewWalletLogFilter(&dto.WalletLogExportRequest{
+			UserID: 7,
+			GameID: "game-1",
+			From:   "2026-01-01",
+			To:     "2026-02-01T12:00:00+02:00",
+		})
+		require.NoError(t, err)
+		require.NotNil(t, filter.UserID)
+		assert.Equal(t, 7, *filter.UserID)
+		require.NotNil(t, filter.GameID)
+		assert.Equal(t, "game-1", *filter.GameID)
+		assert.Nil(t, filter.TokenType)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), *filter.From)
+		assert.Equal(t, time.Date(2026, 2, 1, 10, 0, 0, 0, time.UTC), *filter.To)
+	})
+
+	testCases := []struct {
+		name string
+		req  dto.WalletLogExportRequest
+	}{
+		{name: "Invalid From", req: dto.WalletLogExportRequest{From: "yesterday"}},
+		{name: "Invalid To", req: dto.WalletLogExportRequest{To: "2026-13-01"}},
+		{name: "Empty Range", req: dto.WalletLogExportRequest{From: "2026-01-02", To: "2026-01-02"}},
+		{name: "Reversed Range", req: dto.WalletLogExportRequest{From: "2026-01-02", To: "2026-01-01"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewWalletLogFilter(&tc.req)
+			assert.True(t, errors.Is(err, ErrInvalidExportFilter))
+		})
+	}
+}
+
+func TestWalletLogWriter(t *testing.T) {
+	gameID := "game-1"
+	log := &model.WalletLog{
+		ID:             1,
+		WalletID:       2,
+		UserID:         3,
+		Currency:       "platform",
+		GameID:         &gameID,
+		Amount:         100,
+		PlatformAmount: 10.5,
+		Source:         "exchange",
+		CreatedAt:      time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC),
+	}
+
+	testCases := []struct {
+		format   string
+		expected string
+	}{
+		{
+			format: ExportFormatCSV,
+			expected: "id,wallet_id,user_id,currency,game_id,token_type,amount,platform_amount,source,reference_id,created_at\n" +
+				"1,2,3,platform,game-1,,100.00,10.50,exchange,,2026-01-01T12:00:00Z\n",
+		},
+		{
+			format: ExportFormatNDJSON,
+			expected: `{"id":1,"wallet_id":2,"user_id":3,"currency":"platform","game_id":"game-1","token_type":null,` +
+				`"amount":100,"platform_amount":10.5,"source":"exchange","reference_id":null,"created_at":"2026-01-01T12:00:00Z"}` + "\n",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.format, func(t *testing.T) {
+			var buf bytes.Buffer
+			writer, err := newWalletLogWriter(tc.format, &buf)
+			require.NoError(t, err)
+			require.NoError(t, writer.Write(log))
+			require.NoError(t, writer.Flush())
+			assert.Equal(t, tc.expected, buf.String())
+		})
+	}
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		_, err := newWalletLogWriter("xml", &bytes.Buffer{})
+		assert.True(t, errors.Is(err, ErrInvalidExportFilter))
+	})
+}
@@ -0,0 +1,357 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// FeatureFlagService evaluates the feature flags defined in the feature flags file, which the config watcher
+// reloads when it changes. Admins override the targeting of a flag through the database; overrides are kept
+// in memory and refreshed periodically, so evaluating a flag never waits on the database and an override set
+// on another instance applies within the refresh interval.
+type FeatureFlagService struct {
+	repo    repository.FeatureFlagRepository
+	refresh time.Duration
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	tracer  *tracing.Tracer
+
+	mu        sync.RWMutex
+	defined   map[string]*feature.Flag
+	names     []string
+	overrides map[string]*model.FeatureFlagOverride
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// Compile-time verification that FeatureFlagService implements FeatureFlagServiceInterface
+var _ FeatureFlagServiceInterface = (*FeatureFlagService)(nil)
+
+// NewFeatureFlagService loads the flags of the feature flags file and registers the lifecycle hooks that
+// keep the overrides up to date
+func NewFeatureFlagService(lc fx.Lifecycle, repo repository.FeatureFlagRepository, cfg *config.Config,
+	obs *observability.Observability) (*FeatureFlagService, error) {
+
+	s := &FeatureFlagService{
+		repo:      repo,
+		defined:   make(map[string]*feature.Flag),
+		refresh:   cfg.FeatureFlags.RefreshInterval,
+		logger:    obs.Logger.Logger.With(zap.String("component", "feature_flags")),
+		metrics:   obs.Metrics,
+		tracer:    obs.Tracer,
+		overrides: make(map[string]*model.FeatureFlagOverride),
+	}
+
+	if cfg.FeatureFlags.File != "" {
+		flags, err := feature.LoadFlags(cfg.FeatureFlags.File)
+		if err != nil {
+			return nil, err
+		}
+		s.SetDefinedFlags(flags)
+	}
+
+	s.logger.Info("Feature flags loaded",
+		zap.String("file", cfg.FeatureFlags.File),
+		zap.Strings("flags", s.flagNames()))
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			// The flags of the file apply until the overrides can be read
+			if err := s.refreshOverrides(ctx); err != nil {
+				s.logger.Error("Failed to load feature flag overrides", zap.Error(err))
+			}
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			s.cancel = cancel
+			s.done = make(chan struct{})
+			go s.loop(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			s.cancel()
+
+			select {
+			case <-s.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return s, nil
+}
+
+func (s *FeatureFlagService) loop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.refresh)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.refreshOverrides(ctx); err != nil && !errors.Is(err, context.Canceled) {
+				s.logger.Error("Failed to refresh feature flag overrides", zap.Error(err))
+			}
+		}
+	}
+}
+
+// SetDefinedFlags replaces the flags defined by the feature flags file. Overrides of flags that are no
+// longer defined are kept and apply again once their flag is defined again.
+func (s *FeatureFlagService) SetDefinedFlags(flags []*feature.Flag) {
+	defined := make(map[string]*feature.Flag, len(flags))
+	names := make([]string, 0, len(flags))
+	for _, flag := range flags {
+		defined[flag.Name] = flag
+		names = append(names, flag.Name)
+	}
+	sort.Strings(names)
+
+	s.mu.Lock()
+	s.defined = defined
+	s.names = names
+	s.mu.Unlock()
+}
+
+// flagNames returns the names of the defined flags, sorted
+func (s *FeatureFlagService) flagNames() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.names
+}
+
+// refreshOverrides replaces the overrides in memory with the ones in the database
+func (s *FeatureFlagService) refreshOverrides(ctx context.Context) error {
+	overrides, err := s.repo.ListFeatureFlagOverrides(ctx)
+	if err != nil {
+		return err
+	}
+
+	byName := make(map[string]*model.FeatureFlagOverride, len(overrides))
+	for _, override := range overrides {
+		byName[override.Name] = override
+	}
+
+	s.mu.Lock()
+	s.overrides = byName
+	s.mu.Unlock()
+	return nil
+}
+
+// flag returns a defined flag with its override applied, or nil when the flag is not defined
+func (s *FeatureFlagService) flag(name string) (*feature.Flag, *model.FeatureFlagOverride) {
+	s.mu.RLock()
+	defined, ok := s.defined[name]
+	override := s.overrides[name]
+	s.mu.RUnlock()
+
+	if !ok {
+		return nil, nil
+	}
+
+	if override == nil {
+		return defined, nil
+	}
+
+	return &feature.Flag{
+		Name:        defined.Name,
+		Description: defined.Description,
+		Enabled:     override.Enabled,
+		Percentage:  override.Percentage,
+		Roles:       override.Roles,
+		GameIDs:     override.GameIDs,
+	}, override
+}
+
+// IsEnabled reports whether a flag is on for the subject. Flags that are not defined are off.
+func (s *FeatureFlagService) IsEnabled(name string, subject feature.Subject) bool {
+	if s == nil {
+		return false
+	}
+
+	flag, _ := s.flag(name)
+	return flag != nil && flag.EnabledFor(subject)
+}
+
+// Allows reports whether an operation behind a flag is allowed for the subject. Unlike IsEnabled, an
+// operation whose flag is not defined is allowed, so flags can be added to existing operations.
+func (s *FeatureFlagService) Allows(name string, subject feature.Subject) bool {
+	if s == nil {
+		return true
+	}
+
+	flag, _ := s.flag(name)
+	return flag == nil || flag.EnabledFor(subject)
+}
+
+// EvaluateFlags reports for every defined flag whether it is on for the subject
+func (s *FeatureFlagService) EvaluateFlags(subject feature.Subject) map[string]bool {
+	names := s.flagNames()
+	features := make(map[string]bool, len(names))
+	for _, name := range names {
+		features[name] = s.IsEnabled(name, subject)
+	}
+	return features
+}
+
+// ListFeatureFlags returns every defined flag with its override, reading the overrides from the database
+func (s *FeatureFlagService) ListFeatureFlags(ctx context.Context) ([]*dto.FeatureFlag, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "FeatureFlagService.ListFeatureFlags")
+	defer span.End()
+
+	if err := s.refreshOverrides(ctx); err != nil {
+		s.logger.Error("Error retrieving feature flag overrides", zap.Error(err))
+		return nil, err
+	}
+
+	names := s.flagNames()
+	flags := make([]*dto.FeatureFlag, 0, len(names))
+	for _, name := range names {
+		flag, override := s.flag(name)
+		if flag == nil {
+			// Removed by a reload of the feature flags file meanwhile
+			continue
+		}
+		flags = append(flags, toFeatureFlagDTO(flag, override))
+	}
+	return flags, nil
+}
+
+// SetFeatureFlagOverride overrides the targeting of a defined flag on behalf of an admin. The override
+// applies on this instance at once and on the others within the refresh interval.
+func (s *FeatureFlagService) SetFeatureFlagOverride(ctx context.Context, name string,
+	req *dto.UpdateFeatureFlagRequest, updatedBy int) (*dto.FeatureFlag, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "FeatureFlagService.SetFeatureFlagOverride",
+		trace.WithAttributes(
+			attribute.String("flag", name),
+			attribute.Int("updated_by", updatedBy),
+		))
+	defer span.End()
+
+	if flag, _ := s.flag(name); flag == nil {
+		return nil, fmt.Errorf("%w: %s", ErrFeatureFlagNotFound, name)
+	}
+
+	override := &model.FeatureFlagOverride{
+		Name:       name,
+		Enabled:    *req.Enabled,
+		Percentage: req.Percentage,
+		Roles:      nonNilStrings(req.Roles),
+		GameIDs:    nonNilStrings(req.GameIDs),
+		Reason:     req.Reason,
+		UpdatedBy:  &updatedBy,
+	}
+
+	saved, err := s.repo.UpsertFeatureFlagOverride(ctx, override)
+	if err != nil {
+		s.metrics.RecordWalletOperation("feature_flag_override", "error")
+		return nil, err
+	}
+
+	s.mu.Lock()
+	s.overrides[name] = saved
+	s.mu.Unlock()
+
+	s.logger.Info("Feature flag overridden",
+		zap.String("flag", name),
+		zap.Bool("enabled", saved.Enabled),
+		zap.Int("percentage", saved.Percentage),
+		zap.Strings("roles", saved.Roles),
+		zap.Strings("game_ids", saved.GameIDs),
+		zap.Int("updated_by", updatedBy))
+	s.metrics.RecordWalletOperation("feature_flag_override", "success")
+
+	return toFeatureFlagDTO(s.flag(name)), nil
+}
+
+// DeleteFeatureFlagOverride removes the override of a flag so the feature flags file applies again, and
+// reports whether one existed
+func (s *FeatureFlagService) DeleteFeatureFlagOverride(ctx context.Context, name string) (bool, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "FeatureFlagService.DeleteFeatureFlagOverride",
+		trace.WithAttributes(attribute.String("flag", name)))
+	defer span.End()
+
+	deleted, err := s.repo.DeleteFeatureFlagOverride(ctx, name)
+	if err != nil {
+		return false, err
+	}
+
+	s.mu.Lock()
+	delete(s.overrides, name)
+	s.mu.Unlock()
+
+	if deleted {
+		s.logger.Info("Feature flag override removed", zap.String("flag", name))
+	}
+
+	return deleted, nil
+}
+
+// spendReasonFlag names the flag that rolls out a spend reason. A reason without a flag is available to all.
+func spendReasonFlag(reason string) string {
+	return "spend_reason." + reason
+}
+
+// checkSpendReason returns ErrFeatureDisabled when the flag of a spend reason is off for the wallet's user.
+// A spend made by a game server concerns its game, so flags targeting that game apply.
+func (s *WalletService) checkSpendReason(ctx context.Context, userID int, reason string) error {
+	principal, _ := model.PrincipalFromContext(ctx)
+	subject := feature.Subject{UserID: userID, Role: principal.Role, GameID: principal.GameID}
+
+	if !s.flags.Allows(spendReasonFlag(reason), subject) {
+		return fmt.Errorf("%w: spend reason %s", ErrFeatureDisabled, reason)
+	}
+	return nil
+}
+
+func toFeatureFlagDTO(flag *feature.Flag, override *model.FeatureFlagOverride) *dto.FeatureFlag {
+	result := &dto.FeatureFlag{
+		Name:        flag.Name,
+		Description: flag.Description,
+		Enabled:     flag.Enabled,
+		Percentage:  flag.Percentage,
+		Roles:       nonNilStrings(flag.Roles),
+		GameIDs:     nonNilStrings(flag.GameIDs),
+	}
+
+	if override != nil {
+		result.Overridden = true
+		result.Reason = override.Reason
+		result.UpdatedBy = override.UpdatedBy
+		updatedAt := override.UpdatedAt
+		result.UpdatedAt = &updatedAt
+	}
+
+	return result
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
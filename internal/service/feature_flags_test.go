@@ -0,0 +1,105 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
+)
+
+func setupFeatureFlagService(t *testing.T) (*FeatureFlagService, *stubFeatureFlagRepository) {
+	path := filepath.Join(t.TempDir(), "feature_flags.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"flags": [
+		{"name": "transfers", "enabled": true, "roles": ["admin"]},
+		{"name": "spend_reason.competition_entry", "enabled": false}
+	]}`), 0o600))
+
+	repo := &stubFeatureFlagRepository{overrides: make(map[string]*model.FeatureFlagOverride)}
+	cfg := &config.Config{FeatureFlags: config.FeatureFlagsConfig{File: path}}
+
+	obs := observability.NewTestObservability()
+
+	flags, err := NewFeatureFlagService(fxtest.NewLifecycle(t), repo, cfg, obs)
+	require.NoError(t, err)
+	return flags, repo
+}
+
+func TestFeatureFlagService(t *testing.T) {
+	t.Run("Flags Of The File", func(t *testing.T) {
+		flags, _ := setupFeatureFlagService(t)
+
+		assert.True(t, flags.IsEnabled("transfers", feature.Subject{UserID: 1, Role: "admin"}))
+		assert.False(t, flags.IsEnabled("transfers", feature.Subject{UserID: 1, Role: "user"}))
+		assert.False(t, flags.IsEnabled("undefined", feature.Subject{UserID: 1, Role: "admin"}))
+		assert.Equal(t, map[string]bool{
+			"spend_reason.competition_entry": false,
+			"transfers":                      false,
+		}, flags.EvaluateFlags(feature.Subject{UserID: 1}))
+	})
+
+	t.Run("Override Replaces Targeting", func(t *testing.T) {
+		flags, repo := setupFeatureFlagService(t)
+		enabled := true
+
+		flag, err := flags.SetFeatureFlagOverride(context.Background(), "transfers", &dto.UpdateFeatureFlagRequest{
+			Enabled:    &enabled,
+			Percentage: 100,
+			Reason:     "General availability",
+		}, 1)
+		require.NoError(t, err)
+		assert.True(t, flag.Overridden)
+		assert.Equal(t, []string{}, flag.Roles)
+		assert.Contains(t, repo.overrides, "transfers")
+		assert.True(t, flags.IsEnabled("transfers", feature.Subject{UserID: 42, Role: "user"}))
+
+		deleted, err := flags.DeleteFeatureFlagOverride(context.Background(), "transfers")
+		require.NoError(t, err)
+		assert.True(t, deleted)
+		assert.False(t, flags.IsEnabled("transfers", feature.Subject{UserID: 42, Role: "user"}))
+	})
+
+	t.Run("Override Of Undefined Flag", func(t *testing.T) {
+		flags, _ := setupFeatureFlagService(t)
+		enabled := true
+
+		_, err := flags.SetFeatureFlagOverride(context.Background(), "undefined", &dto.UpdateFeatureFlagRequest{
+			Enabled: &enabled,
+			Reason:  "Typo",
+		}, 1)
+		assert.True(t, errors.Is(err, ErrFeatureFlagNotFound))
+	})
+
+	t.Run("Overrides Of Other Instances", func(t *testing.T) {
+		flags, repo := setupFeatureFlagService(t)
+		repo.overrides["transfers"] = &model.FeatureFlagOverride{Name: "transfers", Enabled: false}
+
+		require.NoError(t, flags.refreshOverrides(context.Background()))
+		assert.False(t, flags.IsEnabled("transfers", feature.Subject{UserID: 1, Role: "admin"}))
+	})
+}
+
+func TestCheckSpendReason(t *testing.T) {
+	flags, _ := setupFeatureFlagService(t)
+	wallets := &WalletService{flags: flags}
+	ctx := model.WithPrincipal(context.Background(), model.Principal{UserID: 1, Role: "user"})
+
+	err := wallets.checkSpendReason(ctx, 1, "competition_entry")
+	assert.True(t, errors.Is(err, ErrFeatureDisabled))
+
+	// Reasons without a flag are available to all
+	assert.NoError(t, wallets.checkSpendReason(ctx, 1, "market_purchase"))
+
+	// Without feature flags every reason is available
+	assert.NoError(t, (&WalletService{}).checkSpendReason(ctx, 1, "competition_entry"))
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/database"
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/health"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/repository"
+)
+
+// RegisterHealthChecks adds the checks of the service's dependencies to the readiness probe. The
+// database and its schema version are critical; a growing job backlog, a failing trace exporter and
+// read replicas that cannot serve reads are reported without taking the instance out of service.
+func RegisterHealthChecks(registry *health.Registry, repo repository.HealthRepository,
+	router *repository.ReplicaRouter, cfg *config.Config, obs *observability.Observability) error {
+
+	registry.AddReadinessCheck("database", true, func(ctx context.Context) (string, error) {
+		return "", repo.Ping(ctx)
+	})
+
+	if cfg.Health.CheckMigrations {
+		expected, err := database.LatestMigrationVersion()
+		if err != nil {
+			return err
+		}
+
+		registry.AddReadinessCheck("migrations", true, func(ctx context.Context) (string, error) {
+			state, err := repo.GetMigrationState(ctx)
+			if err != nil {
+				return "", err
+			}
+			return checkMigrationState(state, expected)
+		})
+	}
+
+	registry.AddReadinessCheck("jobs", false, func(ctx context.Context) (string, error) {
+		backlog, err := repo.GetJobBacklog(ctx)
+		if err != nil {
+			return "", err
+		}
+		return checkJobBacklog(backlog, cfg.Health.JobBacklogMaxAge, time.Now())
+	})
+
+	if _, total := router.HealthyReplicas(); total > 0 {
+		registry.AddReadinessCheck("replicas", false, func(ctx context.Context) (string, error) {
+			return checkReplicas(router.HealthyReplicas())
+		})
+	}
+
+	if obs.Tracer != nil {
+		registry.AddReadinessCheck("tracing", false, func(ctx context.Context) (string, error) {
+			if err := obs.Tracer.ExportError(); err != nil {
+				return "", fmt.Errorf("last span export failed: %w", err)
+			}
+			return "exporting", nil
+		})
+	}
+
+	return nil
+}
+
+// checkMigrationState fails when no version is recorded, the last migration did not complete or the
+// schema is older than the build expects. A newer schema passes, so instances of the previous release
+// stay ready while a rolling update applies the migrations of the next one.
+func checkMigrationState(state *model.MigrationState, expected int) (string, error) {
+	if state == nil {
+		return "", errors.New("no schema version recorded")
+	}
+
+	detail := fmt.Sprintf("version %d", state.Version)
+	if state.Dirty {
+		return detail, fmt.Errorf("migration %d did not complete", state.Version)
+	}
+	if state.Version < int64(expected) {
+		return detail, fmt.Errorf("schema version %d is older than the expected version %d", state.Version, expected)
+	}
+
+	return detail, nil
+}
+
+// checkJobBacklog fails when the oldest due job has waited for a worker for longer than maxAge
+func checkJobBacklog(backlog *model.JobBacklog, maxAge time.Duration, now time.Time) (string, error) {
+	detail := fmt.Sprintf("%d jobs due", backlog.Due)
+	if backlog.OldestRunAt == nil {
+		return detail, nil
+	}
+
+	if waiting := now.Sub(*backlog.OldestRunAt); waiting > maxAge {
+		return detail, fmt.Errorf("oldest due job has waited %s for a worker", waiting.Truncate(time.Second))
+	}
+
+	return detail, nil
+}
+
+// checkReplicas fails when no read replica serves reads, so every read falls back to the primary
+func checkReplicas(healthy, total int) (string, error) {
+	detail := fmt.Sprintf("%d of %d replicas serving reads", healthy, total)
+	if healthy == 0 {
+		return detail, errors.New("no replica serves reads, reads use the primary")
+	}
+	return detail, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckMigrationState(t *testing.T) {
+	testCases := []struct {
+		name          string
+		state         *model.MigrationState
+		expectedError string
+	}{
+		{name: "Current", state: &model.MigrationState{Version: 17}},
+		{name: "Newer", state: &model.MigrationState{Version: 18}},
+		{name: "Not Recorded", expectedError: "no schema version"},
+		{name: "Older", state: &model.MigrationState{Version: 16}, expectedError: "older than the expected version 17"},
+		{name: "Dirty", state: &model.MigrationState{Version: 17, Dirty: true}, expectedError: "did not complete"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := checkMigrationState(tc.state, 17)
+			if tc.expectedError == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorContains(t, err, tc.expectedError)
+		})
+	}
+}
+
+func TestCheckJobBacklog(t *testing.T) {
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.UTC)
+	at := func(ago time.Duration) *time.Time {
+		runAt := now.Add(-ago)
+		return &runAt
+	}
+
+	detail, err := checkJobBacklog(&model.JobBacklog{}, 5*time.Minute, now)
+	assert.NoError(t, err)
+	assert.Equal(t, "0 jobs due", detail)
+
+	_, err = checkJobBacklog(&model.JobBacklog{Due: 3, OldestRunAt: at(time.Minute)}, 5*time.Minute, now)
+	assert.NoError(t, err)
+
+	detail, err = checkJobBacklog(&model.JobBacklog{Due: 40, OldestRunAt: at(12 * time.Minute)}, 5*time.Minute, now)
+	assert.ErrorContains(t, err, "waited 12m0s")
+	assert.Equal(t, "40 jobs due", detail)
+}
+
+func TestCheckReplicas(t *testing.T) {
+	detail, err := checkReplicas(2, 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "2 of 3 replicas serving reads", detail)
+
+	_, err = checkReplicas(0, 2)
+	assert.ErrorContains(t, err, "reads use the primary")
+}
@@ -2,22 +2,217 @@
 package service
 
 import (
+	"bufio"
 	"context"
+	"io"
+	"time"
 	
+	"github.com/playconomy/wallet-service/internal/feature"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
 	"github.com/playconomy/wallet-service/internal/server/dto"
 )
 
 // WalletServiceInterface defines the interface for wallet service operations
 type WalletServiceInterface interface {
-	// GetWalletByUserID retrieves wallet information for a specific user
-	GetWalletByUserID(ctx context.Context, userID int) (*dto.Wallet, error)
+	// GetWalletByUserID retrieves wallet information for a specific user in one currency;
+	// an empty currency selects the default one
+	GetWalletByUserID(ctx context.Context, userID int, currency string) (*dto.Wallet, error)
 	
 	// Exchange converts game tokens to platform tokens
 	Exchange(ctx context.Context, req *dto.ExchangeRequest) (float64, error)
 	
 	// Spend deducts tokens from user's wallet
 	Spend(ctx context.Context, req *dto.SpendRequest) (float64, error)
+
+	// ReverseExchange debits platform tokens and issues a signed grant of game tokens for the game server to redeem
+	ReverseExchange(ctx context.Context, req *dto.ReverseExchangeRequest) (*dto.ReverseExchangeResult, error)
+
+	// RedeemReverseExchangeGrant verifies a grant token and marks its grant redeemed on behalf of a game server
+	RedeemReverseExchangeGrant(ctx context.Context, token string, redeemedBy int) (*dto.ReverseExchangeGrant, error)
+
+	// GetReverseExchangeGrants retrieves the grants of a user that are neither redeemed nor expired
+	GetReverseExchangeGrants(ctx context.Context, userID int) ([]dto.ReverseExchangeGrant, error)
 	
 	// GetWalletLogs retrieves transaction logs for a user's wallet
 	GetWalletLogs(ctx context.Context, userID int) ([]dto.WalletLogEntry, error)
+
+	// UpdateWalletStatus changes the status of a user's wallet on behalf of an admin
+	UpdateWalletStatus(ctx context.Context, userID int, req *dto.UpdateWalletStatusRequest, changedBy int) (*dto.Wallet, error)
+
+	// GetWalletStatusHistory retrieves the status changes of a user's wallet
+	GetWalletStatusHistory(ctx context.Context, userID int) ([]dto.WalletStatusChange, error)
+
+	// GetSpendAllowance retrieves a user's spend limits and the allowance left in each window for one currency
+	GetSpendAllowance(ctx context.Context, userID int, currency string) (*dto.SpendAllowance, error)
+
+	// SetSpendLimitOverride creates or replaces a user's spend limit override on behalf of an admin
+	SetSpendLimitOverride(ctx context.Context, userID int, req *dto.UpdateSpendLimitsRequest, updatedBy int) (*dto.SpendLimitOverride, error)
+
+	// DeleteSpendLimitOverride removes a user's spend limit override and reports whether one existed
+	DeleteSpendLimitOverride(ctx context.Context, userID int) (bool, error)
+
+	// GrantTokens credits promotional tokens, optionally expiring, to a user's wallet on behalf of an admin
+	GrantTokens(ctx context.Context, userID int, req *dto.GrantTokensRequest, grantedBy int) (*dto.Wallet, error)
+
+	// ExpireBalanceLots removes the tokens of every balance lot past its expiry date from their wallets
+	ExpireBalanceLots(ctx context.Context) (*dto.ExpiryReport, error)
+
+	// RefundExpiredReverseExchangeGrants credits the platform tokens of grants that expired unredeemed back to their wallets
+	RefundExpiredReverseExchangeGrants(ctx context.Context) (*dto.GrantRefundReport, error)
+}
+
+// ReconciliationServiceInterface defines the interface for wallet reconciliation operations
+type ReconciliationServiceInterface interface {
+	// Run compares every wallet balance with its log history and records drifted wallets
+	Run(ctx context.Context, trigger string) (*dto.ReconciliationReport, error)
+
+	// GetReport retrieves the report of a previous reconciliation run
+	GetReport(ctx context.Context, runID int64) (*dto.ReconciliationReport, error)
+
+	// GetLatestReport retrieves the report of the most recent reconciliation run
+	GetLatestReport(ctx context.Context) (*dto.ReconciliationReport, error)
+
+	// ResolveMismatch marks a mismatch as reviewed and optionally unfreezes the wallet
+	ResolveMismatch(ctx context.Context, mismatchID int64, resolvedBy int, req *dto.ResolveMismatchRequest) (*dto.ReconciliationMismatch, error)
+}
+
+// RiskEvaluator scores mutating wallet operations before they are applied
+type RiskEvaluator interface {
+	// Evaluate returns whether the operation is allowed, denied or placed under manual review
+	Evaluate(ctx context.Context, op *model.RiskOperation) (*model.RiskAssessment, error)
+}
+
+// RiskReviewServiceInterface defines the interface for the manual review queue of risky operations
+type RiskReviewServiceInterface interface {
+	// ListReviews retrieves reviews with the given status, oldest first
+	ListReviews(ctx context.Context, status string, limit, offset int) ([]dto.RiskReview, error)
+
+	// ApproveReview approves a pending review and executes the held operation
+	ApproveReview(ctx context.Context, reviewID int64, reviewedBy int, req *dto.DecideRiskReviewRequest) (*dto.RiskReview, error)
+
+	// RejectReview rejects a pending review; the held operation is never executed
+	RejectReview(ctx context.Context, reviewID int64, reviewedBy int, req *dto.DecideRiskReviewRequest) (*dto.RiskReview, error)
+}
+
+// BulkServiceInterface defines the interface for bulk credits and spends
+type BulkServiceInterface interface {
+	// ProcessBulk applies the items of a request in chunked transactions and reports the outcome of each item
+	ProcessBulk(ctx context.Context, req *dto.BulkRequest, requestedBy int) (*dto.BulkReport, error)
+
+	// SubmitBulkJob starts processing the items of a request in the background
+	SubmitBulkJob(ctx context.Context, req *dto.BulkRequest, requestedBy int) (*dto.BulkJob, error)
+
+	// GetBulkJob retrieves a background bulk job
+	GetBulkJob(ctx context.Context, id int64) (*dto.BulkJob, error)
+}
+
+// JobServiceInterface defines the interface for the admin operations on background jobs
+type JobServiceInterface interface {
+	// ListJobs retrieves jobs matching the filter, newest first
+	ListJobs(ctx context.Context, filter model.JobFilter) ([]dto.Job, error)
+
+	// GetJob retrieves a background job
+	GetJob(ctx context.Context, id int64) (*dto.Job, error)
+
+	// CancelJob cancels a queued job, or asks the worker of a running job to stop it
+	CancelJob(ctx context.Context, id int64) (*dto.Job, error)
+}
+
+// ExportServiceInterface defines the interface for exports of wallet data
+type ExportServiceInterface interface {
+	// ExportWalletLogs writes the logs matching the filter to w in the given format and returns the number of logs
+	ExportWalletLogs(ctx context.Context, filter model.WalletLogFilter, format string, w io.Writer) (int, error)
+}
+
+// StatementServiceInterface defines the interface for monthly wallet statements
+type StatementServiceInterface interface {
+	// GetStatement returns the statement of a user's wallet for a YYYY-MM period, generating it on first request
+	GetStatement(ctx context.Context, userID int, currency, period string) (*dto.WalletStatement, error)
+}
+
+// SnapshotServiceInterface defines the interface for balance snapshots and point-in-time balances
+type SnapshotServiceInterface interface {
+	// TakeBalanceSnapshots snapshots the balance of every wallet at the latest snapshot time
+	TakeBalanceSnapshots(ctx context.Context) (*dto.SnapshotReport, error)
+
+	// GetBalanceAt returns the balance a user's wallet had at a past point in time
+	GetBalanceAt(ctx context.Context, userID int, currency string, at time.Time) (*dto.PointInTimeBalance, error)
+}
+
+// ReportServiceInterface defines the interface for the reporting API and the rollups behind it
+type ReportServiceInterface interface {
+	// RollUp adds the wallet logs created since the last run to the daily rollups
+	RollUp(ctx context.Context) (*dto.RollupReport, error)
+
+	// GetExchangeReport returns the daily exchange volume of each game token
+	GetExchangeReport(ctx context.Context, req *dto.ReportRequest) (*dto.ExchangeReport, error)
+
+	// GetSpendReport returns the platform tokens spent per reason
+	GetSpendReport(ctx context.Context, req *dto.ReportRequest) (*dto.SpendReport, error)
+
+	// GetSupplyReport returns the net token supply per day
+	GetSupplyReport(ctx context.Context, req *dto.ReportRequest) (*dto.SupplyReport, error)
+
+	// GetActiveWalletReport returns the number of active wallets per day
+	GetActiveWalletReport(ctx context.Context, req *dto.ReportRequest) (*dto.ActiveWalletReport, error)
+
+	// GetTopWallets returns the wallets with the largest balances
+	GetTopWallets(ctx context.Context, req *dto.TopWalletsRequest) ([]*dto.Wallet, error)
+}
+
+// StreamServiceInterface defines the interface for the Server-Sent Events streams of wallet changes
+type StreamServiceInterface interface {
+	// StreamWalletEvents writes a user's new wallet logs and balances to w until the client goes away,
+	// resuming after lastEventID when it is not empty
+	StreamWalletEvents(ctx context.Context, userID int, lastEventID string, w *bufio.Writer) error
+}
+
+// AuditServiceInterface defines the interface for the audit log of privileged calls
+type AuditServiceInterface interface {
+	// IsAudited reports whether the calls of a role are recorded in the audit log
+	IsAudited(role string) bool
+
+	// RecordPrivilegedCall appends a privileged call to the hash-chained audit log
+	RecordPrivilegedCall(ctx context.Context, entry *model.AuditEntry) (*model.AuditEntry, error)
+
+	// ListAuditEntries returns the audit entries matching the request, newest first
+	ListAuditEntries(ctx context.Context, req *dto.AuditLogRequest) ([]*dto.AuditEntry, error)
+
+	// VerifyAuditChain checks the hash chain of the whole audit log
+	VerifyAuditChain(ctx context.Context) (*dto.AuditVerification, error)
+}
+
+// PartitionServiceInterface defines the interface for the maintenance of the wallet log partitions
+type PartitionServiceInterface interface {
+	// MaintainPartitions creates the partitions of the coming months and archives the partitions past retention
+	MaintainPartitions(ctx context.Context) (*dto.PartitionReport, error)
+}
+
+// FeatureFlagServiceInterface defines the interface for the feature flags that roll out wallet operations
+type FeatureFlagServiceInterface interface {
+	// IsEnabled reports whether a flag is on for the subject; flags that are not defined are off
+	IsEnabled(name string, subject feature.Subject) bool
+
+	// Allows reports whether an operation behind a flag is allowed for the subject; operations whose
+	// flag is not defined are allowed
+	Allows(name string, subject feature.Subject) bool
+
+	// EvaluateFlags reports for every defined flag whether it is on for the subject
+	EvaluateFlags(subject feature.Subject) map[string]bool
+
+	// ListFeatureFlags returns every defined flag with its override
+	ListFeatureFlags(ctx context.Context) ([]*dto.FeatureFlag, error)
+
+	// SetFeatureFlagOverride overrides the targeting of a defined flag on behalf of an admin
+	SetFeatureFlagOverride(ctx context.Context, name string, req *dto.UpdateFeatureFlagRequest, updatedBy int) (*dto.FeatureFlag, error)
+
+	// DeleteFeatureFlagOverride removes the override of a flag and reports whether one existed
+	DeleteFeatureFlagOverride(ctx context.Context, name string) (bool, error)
+}
+
+// RateLimitServiceInterface defines the interface for the rate limits of the API
+type RateLimitServiceInterface interface {
+	// Allow takes the tokens of a request and returns the result of the most constraining bucket
+	Allow(ctx context.Context, request ratelimit.Request) ratelimit.Result
 }
@@ -0,0 +1,331 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/repository"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// maxJobRetryDelay caps the delay between attempts of a failed job
+const maxJobRetryDelay = time.Hour
+
+// jobOutcomeTimeout bounds the update that records the outcome of an attempt during shutdown
+const jobOutcomeTimeout = 10 * time.Second
+
+// Outcomes of job attempts
+const (
+	jobOutcomeSucceeded = "succeeded"
+	jobOutcomeRetried   = "retried"
+	jobOutcomeFailed    = "failed"
+	jobOutcomeCancelled = "cancelled"
+	jobOutcomeReleased  = "released"
+	jobOutcomeLeaseLost = "lease_lost"
+)
+
+// Errors recorded on jobs that end without their worker failing
+const (
+	jobCancelledByAdmin  = "cancelled by admin"
+	jobLeaseExpiredFinal = "worker stopped responding during the last attempt"
+)
+
+// JobWorkers collects the workers provided to the fx graph in the job_workers group, e.g.
+//
+//	fx.Annotate(service.NewBulkJobWorker, fx.ResultTags(`group:"job_workers"`))
+type JobWorkers struct {
+	fx.In
+
+	Workers []JobWorker `group:"job_workers"`
+}
+
+// JobRunner leases background jobs of the registered kinds and runs them on a fixed number of
+// goroutines for the lifetime of the application
+type JobRunner struct {
+	repo    repository.JobRepository
+	workers map[string]JobWorker
+	kinds   []string
+	config  config.JobsConfig
+	id      string
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	cancel  context.CancelFunc
+	running sync.WaitGroup
+}
+
+// NewJobRunner creates a runner for the registered workers and registers its lifecycle hooks.
+// The runner does nothing when jobs are disabled in the configuration; jobs then wait in the
+// queue for an instance that runs them.
+func NewJobRunner(lc fx.Lifecycle, repo repository.JobRepository, workers JobWorkers,
+	cfg *config.Config, obs *observability.Observability) (*JobRunner, error) {
+
+	runner := &JobRunner{
+		repo:    repo,
+		workers: make(map[string]JobWorker, len(workers.Workers)),
+		config:  cfg.Jobs,
+		id:      jobRunnerID(),
+		logger:  obs.Logger.Logger.With(zap.String("component", "job_runner")),
+		metrics: obs.Metrics,
+	}
+
+	for _, worker := range workers.Workers {
+		if _, ok := runner.workers[worker.Kind()]; ok {
+			return nil, fmt.Errorf("job worker for kind %q registered twice", worker.Kind())
+		}
+		runner.workers[worker.Kind()] = worker
+		runner.kinds = append(runner.kinds, worker.Kind())
+	}
+	sort.Strings(runner.kinds)
+
+	if !cfg.Jobs.Enabled || len(runner.kinds) == 0 {
+		runner.logger.Info("Job workers disabled", zap.Strings("kinds", runner.kinds))
+		return runner, nil
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			runner.logger.Info("Starting job workers",
+				zap.String("runner_id", runner.id),
+				zap.Int("workers", runner.config.Workers),
+				zap.Strings("kinds", runner.kinds))
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			runner.cancel = cancel
+			for i := 0; i < runner.config.Workers; i++ {
+				runner.running.Add(1)
+				go runner.loop(runCtx, fmt.Sprintf("%s:%d", runner.id, i))
+			}
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			runner.logger.Info("Stopping job workers")
+			runner.cancel()
+
+			done := make(chan struct{})
+			go func() {
+				runner.running.Wait()
+				close(done)
+			}()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return runner, nil
+}
+
+// jobRunnerID identifies the process in job leases
+func jobRunnerID() string {
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	return fmt.Sprintf("%s:%d", host, os.Getpid())
+}
+
+// jobRetryDelay returns the delay before the attempt following a failed one: base after the first
+// attempt, doubling with every further attempt up to maxJobRetryDelay
+func jobRetryDelay(base time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt; i++ {
+		delay *= 2
+		if delay >= maxJobRetryDelay {
+			return maxJobRetryDelay
+		}
+	}
+	if delay > maxJobRetryDelay {
+		return maxJobRetryDelay
+	}
+	return delay
+}
+
+func (r *JobRunner) loop(ctx context.Context, workerID string) {
+	defer r.running.Done()
+
+	for {
+		job, err := r.repo.LeaseJob(ctx, r.kinds, workerID, r.config.LeaseDuration)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("Failed to lease job",
+				zap.String("worker_id", workerID),
+				zap.Error(err))
+		}
+
+		if job != nil {
+			r.run(ctx, workerID, job)
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// run runs one attempt of a leased job and records its outcome, unless the lease was lost meanwhile
+func (r *JobRunner) run(ctx context.Context, workerID string, job *model.Job) {
+	logger := r.logger.With(
+		zap.Int64("job_id", job.ID),
+		zap.String("kind", job.Kind),
+		zap.Int("attempt", job.Attempts),
+		zap.String("worker_id", workerID))
+
+	// A job is leased again after its worker stopped responding; it may have been cancelled meanwhile,
+	// or that worker may have been running its last attempt
+	switch {
+	case job.CancelRequested:
+		r.finish(logger, workerID, job, model.JobCancelled, jobOutcomeCancelled, nil, jobCancelledByAdmin)
+		return
+	case job.Attempts > job.MaxAttempts:
+		r.finish(logger, workerID, job, model.JobFailed, jobOutcomeFailed, nil, jobLeaseExpiredFinal)
+		return
+	}
+
+	logger.Info("Running job")
+	startTime := time.Now()
+
+	jobCtx, cancelJob := context.WithCancel(ctx)
+	defer cancelJob()
+
+	// Written by the heartbeat goroutine and read once it is done
+	var leaseLost, cancelled bool
+	stopHeartbeat := make(chan struct{})
+	heartbeatDone := make(chan struct{})
+	go func() {
+		defer close(heartbeatDone)
+
+		ticker := time.NewTicker(r.config.HeartbeatInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stopHeartbeat:
+				return
+			case <-ticker.C:
+				held, cancelRequested, err := r.repo.HeartbeatJob(jobCtx, job.ID, workerID, r.config.LeaseDuration)
+				if err != nil {
+					// The lease outlives a few missed heartbeats
+					logger.Warn("Job heartbeat failed", zap.Error(err))
+					continue
+				}
+				if !held || cancelRequested {
+					leaseLost, cancelled = !held, cancelRequested
+					cancelJob()
+					return
+				}
+			}
+		}
+	}()
+
+	result, err := r.runWorker(jobCtx, job)
+
+	close(stopHeartbeat)
+	<-heartbeatDone
+	duration := time.Since(startTime).Seconds()
+
+	switch {
+	case leaseLost:
+		logger.Warn("Job lease lost; another worker took the job over")
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeLeaseLost, duration)
+	case cancelled:
+		r.finish(logger, workerID, job, model.JobCancelled, jobOutcomeCancelled, nil, jobCancelledByAdmin)
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeCancelled, duration)
+	case err != nil && ctx.Err() != nil:
+		r.release(logger, workerID, job)
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeReleased, duration)
+	case err == nil:
+		r.finish(logger, workerID, job, model.JobSucceeded, jobOutcomeSucceeded, result, "")
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeSucceeded, duration)
+	case errors.Is(err, ErrJobNotRetryable) || job.Attempts >= job.MaxAttempts:
+		r.finish(logger, workerID, job, model.JobFailed, jobOutcomeFailed, nil, err.Error())
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeFailed, duration)
+	default:
+		r.retry(logger, workerID, job, err)
+		r.metrics.RecordJobRun(job.Kind, jobOutcomeRetried, duration)
+	}
+}
+
+// runWorker runs the worker of the job's kind, turning a panic into an error
+func (r *JobRunner) runWorker(ctx context.Context, job *model.Job) (result json.RawMessage, err error) {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("job worker panicked: %v", recovered)
+		}
+	}()
+
+	return r.workers[job.Kind].Run(ctx, job)
+}
+
+func (r *JobRunner) finish(logger *zap.Logger, workerID string, job *model.Job, status, outcome string,
+	result json.RawMessage, message string) {
+
+	// The runner may be stopping; the outcome is still recorded
+	ctx, cancel := context.WithTimeout(context.Background(), jobOutcomeTimeout)
+	defer cancel()
+
+	var jobError *string
+	if message != "" {
+		jobError = &message
+	}
+
+	finished, err := r.repo.FinishJob(ctx, job.ID, workerID, status, result, jobError)
+	if err != nil {
+		logger.Error("Failed to record job outcome", zap.String("status", status), zap.Error(err))
+		return
+	}
+	if finished == nil {
+		logger.Warn("Job lease lost before its outcome was recorded", zap.String("status", status))
+		return
+	}
+
+	if status == model.JobSucceeded {
+		logger.Info("Job finished", zap.String("outcome", outcome))
+		return
+	}
+	logger.Warn("Job finished", zap.String("outcome", outcome), zap.String("error", message))
+}
+
+func (r *JobRunner) retry(logger *zap.Logger, workerID string, job *model.Job, jobErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobOutcomeTimeout)
+	defer cancel()
+
+	runAt := time.Now().Add(jobRetryDelay(r.config.RetryBackoff, job.Attempts))
+	if _, err := r.repo.RetryJob(ctx, job.ID, workerID, runAt, jobErr.Error()); err != nil {
+		logger.Error("Failed to queue job for retry", zap.Error(err))
+		return
+	}
+
+	logger.Warn("Job attempt failed; retrying later",
+		zap.Time("run_at", runAt),
+		zap.Error(jobErr))
+}
+
+func (r *JobRunner) release(logger *zap.Logger, workerID string, job *model.Job) {
+	ctx, cancel := context.WithTimeout(context.Background(), jobOutcomeTimeout)
+	defer cancel()
+
+	if err := r.repo.ReleaseJob(ctx, job.ID, workerID); err != nil {
+		logger.Error("Failed to release job", zap.Error(err))
+		return
+	}
+
+	logger.Info("Job released for another worker")
+}
@@ -0,0 +1,29 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJobRetryDelay(t *testing.T) {
+	testCases := []struct {
+		name     string
+		base     time.Duration
+		attempt  int
+		expected time.Duration
+	}{
+		{name: "First Attempt", base: 30 * time.Second, attempt: 1, expected: 30 * time.Second},
+		{name: "Second Attempt Doubles", base: 30 * time.Second, attempt: 2, expected: time.Minute},
+		{name: "Fourth Attempt", base: 30 * time.Second, attempt: 4, expected: 4 * time.Minute},
+		{name: "Capped", base: 30 * time.Second, attempt: 20, expected: maxJobRetryDelay},
+		{name: "Base Above Cap", base: 2 * time.Hour, attempt: 1, expected: maxJobRetryDelay},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, jobRetryDelay(tc.base, tc.attempt))
+		})
+	}
+}
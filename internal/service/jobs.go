@@ -0,0 +1,170 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// JobWorker runs the background jobs of one kind. Run may be called again for a job whose earlier
+// attempt failed or whose worker stopped, so it must be safe to repeat. It should return promptly once
+// ctx is cancelled; errors wrapping ErrJobNotRetryable fail the job without further attempts.
+type JobWorker interface {
+	Kind() string
+	Run(ctx context.Context, job *model.Job) (json.RawMessage, error)
+}
+
+// JobService enqueues background jobs and serves the admin operations on them
+type JobService struct {
+	repo        repository.JobRepository
+	maxAttempts int
+	logger      *zap.Logger
+	metrics     *metrics.Metrics
+	tracer      *tracing.Tracer
+}
+
+// Compile-time verification that JobService implements JobServiceInterface
+var _ JobServiceInterface = (*JobService)(nil)
+
+// NewJobService creates a new job service
+func NewJobService(repo repository.JobRepository, cfg *config.Config, obs *observability.Observability) *JobService {
+	return &JobService{
+		repo:        repo,
+		maxAttempts: cfg.Jobs.MaxAttempts,
+		logger:      obs.Logger.Logger.With(zap.String("component", "job_service")),
+		metrics:     obs.Metrics,
+		tracer:      obs.Tracer,
+	}
+}
+
+// EnqueueJob queues a job of a kind with its payload encoded as JSON. The job runs once a worker of
+// the kind is free; createdBy is nil for jobs the service enqueues on its own.
+func (s *JobService) EnqueueJob(ctx context.Context, kind string, payload any, createdBy *int) (*model.Job, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "JobService.EnqueueJob",
+		trace.WithAttributes(attribute.String("kind", kind)))
+	defer span.End()
+
+	encoded, err := json.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("encode job payload: %w", err)
+	}
+
+	job, err := s.repo.EnqueueJob(ctx, &model.Job{
+		Kind:        kind,
+		Payload:     encoded,
+		MaxAttempts: s.maxAttempts,
+		RunAt:       time.Now(),
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Job enqueued",
+		zap.Int64("job_id", job.ID),
+		zap.String("kind", kind))
+
+	return job, nil
+}
+
+// ListJobs retrieves jobs matching the filter, newest first
+func (s *JobService) ListJobs(ctx context.Context, filter model.JobFilter) ([]dto.Job, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "JobService.ListJobs")
+	defer span.End()
+
+	jobs, err := s.repo.ListJobs(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.Job, len(jobs))
+	for i, job := range jobs {
+		result[i] = *toJobDTO(job)
+	}
+
+	return result, nil
+}
+
+// GetJob retrieves a background job
+func (s *JobService) GetJob(ctx context.Context, id int64) (*dto.Job, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "JobService.GetJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	job, err := s.repo.GetJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		return nil, fmt.Errorf("%w: job_id=%d", ErrJobNotFound, id)
+	}
+
+	return toJobDTO(job), nil
+}
+
+// CancelJob cancels a queued job right away. A running job is flagged and stopped by its worker at its
+// next heartbeat; the returned job is then still running with CancelRequested set.
+func (s *JobService) CancelJob(ctx context.Context, id int64) (*dto.Job, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "JobService.CancelJob",
+		trace.WithAttributes(attribute.Int64("job_id", id)))
+	defer span.End()
+
+	job, err := s.repo.CancelJob(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if job == nil {
+		existing, err := s.repo.GetJob(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if existing == nil {
+			return nil, fmt.Errorf("%w: job_id=%d", ErrJobNotFound, id)
+		}
+		return nil, fmt.Errorf("%w: job_id=%d status=%s", ErrJobFinished, id, existing.Status)
+	}
+
+	s.logger.Info("Job cancellation requested",
+		zap.Int64("job_id", id),
+		zap.String("kind", job.Kind),
+		zap.String("status", job.Status))
+
+	return toJobDTO(job), nil
+}
+
+func toJobDTO(job *model.Job) *dto.Job {
+	return &dto.Job{
+		ID:              job.ID,
+		Kind:            job.Kind,
+		Status:          job.Status,
+		Payload:         job.Payload,
+		Result:          job.Result,
+		Error:           job.Error,
+		Attempts:        job.Attempts,
+		MaxAttempts:     job.MaxAttempts,
+		RunAt:           job.RunAt,
+		LockedBy:        job.LockedBy,
+		LockedUntil:     job.LockedUntil,
+		HeartbeatAt:     job.HeartbeatAt,
+		CancelRequested: job.CancelRequested,
+		CreatedBy:       job.CreatedBy,
+		CreatedAt:       job.CreatedAt,
+		StartedAt:       job.StartedAt,
+		FinishedAt:      job.FinishedAt,
+	}
+}
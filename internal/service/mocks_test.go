@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+
+	"github.com/stretchr/testify/mock"
+)
+
+// mockRepository is a mock implementation of the wallet repository for testing. Calls to methods it does not
+// implement panic on the nil embedded repository.
+type mockRepository struct {
+	mock.Mock
+	repository.WalletRepository
+}
+
+func (m *mockRepository) BeginTx(ctx context.Context) (repository.Transaction, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(repository.Transaction), args.Error(1)
+}
+
+func (m *mockRepository) GetWalletsByUserID(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.Wallet), args.Error(1)
+}
+
+func (m *mockRepository) GetWalletByUserIDForUpdate(ctx context.Context, userID int, currency string,
+	tx repository.Transaction) (*model.Wallet, error) {
+	args := m.Called(ctx, userID, currency, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (m *mockRepository) UpdateWalletBalance(ctx context.Context, userID int, currency string, newBalance float64,
+	tx repository.Transaction) (*model.Wallet, error) {
+	args := m.Called(ctx, userID, currency, newBalance, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (m *mockRepository) SpendFromWallet(ctx context.Context, userID int, currency string, amount float64,
+	tx repository.Transaction) (*model.Wallet, error) {
+	args := m.Called(ctx, userID, currency, amount, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.Wallet), args.Error(1)
+}
+
+func (m *mockRepository) CreateBalanceLot(ctx context.Context, lot *model.BalanceLot,
+	tx repository.Transaction) (*model.BalanceLot, error) {
+	args := m.Called(ctx, lot, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.BalanceLot), args.Error(1)
+}
+
+func (m *mockRepository) GetSpendableBalanceLotsForUpdate(ctx context.Context, walletID int64, now time.Time,
+	tx repository.Transaction) ([]*model.BalanceLot, error) {
+	args := m.Called(ctx, walletID, now, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BalanceLot), args.Error(1)
+}
+
+func (m *mockRepository) UpdateBalanceLotRemaining(ctx context.Context, lotID int64, remaining float64,
+	tx repository.Transaction) error {
+	args := m.Called(ctx, lotID, remaining, tx)
+	return args.Error(0)
+}
+
+func (m *mockRepository) ExpireBalanceLots(ctx context.Context, walletID int64, now time.Time,
+	tx repository.Transaction) ([]*model.BalanceLot, error) {
+	args := m.Called(ctx, walletID, now, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BalanceLot), args.Error(1)
+}
+
+func (m *mockRepository) ListExpiringBalanceLots(ctx context.Context, walletID int64,
+	from, to time.Time) ([]*model.BalanceLot, error) {
+	args := m.Called(ctx, walletID, from, to)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.BalanceLot), args.Error(1)
+}
+
+func (m *mockRepository) GetSpendLimitOverride(ctx context.Context, userID int) (*model.SpendLimitOverride, error) {
+	args := m.Called(ctx, userID)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SpendLimitOverride), args.Error(1)
+}
+
+func (m *mockRepository) GetSpendUsage(ctx context.Context, userID int, currency string, now time.Time,
+	tx repository.Transaction) (*model.SpendUsage, error) {
+	args := m.Called(ctx, userID, currency, now, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.SpendUsage), args.Error(1)
+}
+
+func (m *mockRepository) GetExchangeRate(ctx context.Context, gameID, tokenType,
+	currency string) (*model.ExchangeRate, error) {
+	args := m.Called(ctx, gameID, tokenType, currency)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ExchangeRate), args.Error(1)
+}
+
+func (m *mockRepository) CreateWalletLog(ctx context.Context, log *model.WalletLog,
+	tx repository.Transaction) (*model.WalletLog, error) {
+	args := m.Called(ctx, log, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.WalletLog), args.Error(1)
+}
+
+func (m *mockRepository) GetWalletLogs(ctx context.Context, userID int, limit, offset int) ([]*model.WalletLog, error) {
+	args := m.Called(ctx, userID, limit, offset)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.WalletLog), args.Error(1)
+}
+
+func (m *mockRepository) RedeemReverseExchangeGrant(ctx context.Context, grantID string, redeemedBy int,
+	now time.Time) (*model.ReverseExchangeGrant, error) {
+	args := m.Called(ctx, grantID, redeemedBy, now)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReverseExchangeGrant), args.Error(1)
+}
+
+func (m *mockRepository) ListRefundableReverseExchangeGrants(ctx context.Context, now time.Time,
+	limit int) ([]*model.ReverseExchangeGrant, error) {
+	args := m.Called(ctx, now, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*model.ReverseExchangeGrant), args.Error(1)
+}
+
+func (m *mockRepository) RefundReverseExchangeGrant(ctx context.Context, grantID string, now time.Time,
+	tx repository.Transaction) (*model.ReverseExchangeGrant, error) {
+	args := m.Called(ctx, grantID, now, tx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*model.ReverseExchangeGrant), args.Error(1)
+}
+
+// mockTransaction is a mock implementation of repository.Transaction for testing
+type mockTransaction struct {
+	mock.Mock
+}
+
+func (m *mockTransaction) Commit() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+func (m *mockTransaction) Rollback() error {
+	args := m.Called()
+	return args.Error(0)
+}
+
+// stubTransaction is a transaction that does nothing
+type stubTransaction struct{}
+
+func (stubTransaction) Commit() error   { return nil }
+func (stubTransaction) Rollback() error { return nil }
+
+// stubExchangeRepository records the calls of an exchange up to its cap check. Calls to other methods
+// panic on the nil embedded repository.
+type stubExchangeRepository struct {
+	repository.WalletRepository
+	rate  *model.ExchangeRate
+	usage model.ExchangeUsage
+	calls []string
+}
+
+func (r *stubExchangeRepository) GetExchangeRate(ctx context.Context, gameID, tokenType,
+	currency string) (*model.ExchangeRate, error) {
+	return r.rate, nil
+}
+
+func (r *stubExchangeRepository) BeginTx(ctx context.Context) (repository.Transaction, error) {
+	r.calls = append(r.calls, "BeginTx")
+	return stubTransaction{}, nil
+}
+
+func (r *stubExchangeRepository) GetWalletByUserIDForUpdate(ctx context.Context, userID int, currency string,
+	tx repository.Transaction) (*model.Wallet, error) {
+	r.calls = append(r.calls, "GetWalletByUserIDForUpdate")
+	return &model.Wallet{ID: 1, UserID: userID, Currency: currency, Balance: 100,
+		Status: model.WalletStatusActive}, nil
+}
+
+func (r *stubExchangeRepository) LockExchangeRate(ctx context.Context, id int64, tx repository.Transaction) error {
+	r.calls = append(r.calls, "LockExchangeRate")
+	return nil
+}
+
+func (r *stubExchangeRepository) GetExchangeUsage(ctx context.Context, userID int, gameID, tokenType,
+	currency string, now time.Time, tx repository.Transaction) (*model.ExchangeUsage, error) {
+	r.calls = append(r.calls, "GetExchangeUsage")
+	usage := r.usage
+	return &usage, nil
+}
+
+// stubStreamRepository serves the logs committed between two snapshots and the wallets from memory
+type stubStreamRepository struct {
+	snapshot string
+	logs     []*model.WalletLog
+	wallets  []*model.Wallet
+}
+
+func (r *stubStreamRepository) ListWalletLogsBetween(ctx context.Context, userID int, since, until string,
+	afterID int64, limit int) ([]*model.WalletLog, error) {
+	var logs []*model.WalletLog
+	for _, log := range r.logs {
+		if log.ID > afterID && len(logs) < limit {
+			logs = append(logs, log)
+		}
+	}
+	return logs, nil
+}
+
+func (r *stubStreamRepository) GetCurrentSnapshot(ctx context.Context) (string, error) {
+	return r.snapshot, nil
+}
+
+func (r *stubStreamRepository) GetWalletBalances(ctx context.Context, userID int) ([]*model.Wallet, error) {
+	return r.wallets, nil
+}
+
+// stubFeatureFlagRepository keeps feature flag overrides in memory
+type stubFeatureFlagRepository struct {
+	overrides map[string]*model.FeatureFlagOverride
+}
+
+func (r *stubFeatureFlagRepository) ListFeatureFlagOverrides(ctx context.Context) ([]*model.FeatureFlagOverride, error) {
+	overrides := make([]*model.FeatureFlagOverride, 0, len(r.overrides))
+	for _, override := range r.overrides {
+		overrides = append(overrides, override)
+	}
+	return overrides, nil
+}
+
+func (r *stubFeatureFlagRepository) UpsertFeatureFlagOverride(ctx context.Context,
+	override *model.FeatureFlagOverride) (*model.FeatureFlagOverride, error) {
+	r.overrides[override.Name] = override
+	return override, nil
+}
+
+func (r *stubFeatureFlagRepository) DeleteFeatureFlagOverride(ctx context.Context, name string) (bool, error) {
+	_, ok := r.overrides[name]
+	delete(r.overrides, name)
+	return ok, nil
+}
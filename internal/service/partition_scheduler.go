@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// PartitionScheduler maintains the wallet log partitions when the application starts and then at every
+// interval for the lifetime of the application
+type PartitionScheduler struct {
+	service PartitionServiceInterface
+	logger  *zap.Logger
+}
+
+// NewPartitionScheduler creates a scheduler and registers its lifecycle hooks.
+// The scheduler does nothing when partition maintenance is disabled in the configuration;
+// logs of months without a partition then land in the default partition.
+func NewPartitionScheduler(lc fx.Lifecycle, svc PartitionServiceInterface,
+	cfg *config.Config, obs *observability.Observability) *PartitionScheduler {
+
+	scheduler := &PartitionScheduler{
+		service: svc,
+		logger:  obs.Logger.Logger.With(zap.String("component", "partition_scheduler")),
+	}
+
+	if !cfg.Partitions.Enabled {
+		scheduler.logger.Info("Scheduled partition maintenance disabled")
+		return scheduler
+	}
+
+	startPeriodicTask(lc, scheduler.logger, periodicTask{
+		name:       "partition scheduler",
+		schedule:   every(cfg.Partitions.Interval),
+		runAtStart: true,
+		run:        scheduler.run,
+	},
+		zap.Duration("interval", cfg.Partitions.Interval),
+		zap.Int("retention_months", cfg.Partitions.RetentionMonths))
+
+	return scheduler
+}
+
+func (s *PartitionScheduler) run(ctx context.Context) {
+	report, err := s.service.MaintainPartitions(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled partition maintenance failed", zap.Error(err))
+		return
+	}
+	if report != nil && (len(report.Created) > 0 || len(report.Archived) > 0) {
+		s.logger.Info("Scheduled partition maintenance completed",
+			zap.Strings("created", report.Created),
+			zap.Strings("archived", report.Archived),
+			zap.Int("statements_generated", report.StatementsGenerated))
+	}
+}
@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// PartitionService maintains the monthly partitions of wallet_logs: it creates the partitions of the coming
+// months and archives the partitions older than the retention period. Before a partition is archived, the
+// statements of its month are generated for every wallet with logs in it and the report rollups must
+// cover its logs; its logs are then added to the archived balances of their wallets, so reconciliation,
+// snapshots and later statements keep seeing them.
+type PartitionService struct {
+	repo             repository.PartitionRepository
+	statements       *StatementService
+	partitions       config.PartitionsConfig
+	reportingEnabled bool
+	settleDelay      time.Duration
+	logger           *zap.Logger
+	metrics          *metrics.Metrics
+	tracer           *tracing.Tracer
+}
+
+// Compile-time verification that PartitionService implements PartitionServiceInterface
+var _ PartitionServiceInterface = (*PartitionService)(nil)
+
+// NewPartitionService creates a new wallet log partition service
+func NewPartitionService(repo repository.PartitionRepository, statements *StatementService, cfg *config.Config,
+	obs *observability.Observability) *PartitionService {
+	return &PartitionService{
+		repo:             repo,
+		statements:       statements,
+		partitions:       cfg.Partitions,
+		reportingEnabled: cfg.Reporting.Enabled,
+		settleDelay:      cfg.Statements.SettleDelay,
+		logger:           obs.Logger.Logger.With(zap.String("component", "partition_service")),
+		metrics:          obs.Metrics,
+		tracer:           obs.Tracer,
+	}
+}
+
+// walletLogPartition returns the partition of the calendar month (UTC) that t falls in
+func walletLogPartition(t time.Time) *model.WalletLogPartition {
+	t = t.UTC()
+	from := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	return &model.WalletLogPartition{
+		Name: fmt.Sprintf("wallet_logs_%04d_%02d", from.Year(), int(from.Month())),
+		From: from,
+		To:   from.AddDate(0, 1, 0),
+	}
+}
+
+// missingWalletLogPartitions returns the partitions of the current month and the ahead months after it
+// that do not exist yet
+func missingWalletLogPartitions(existing []*model.WalletLogPartition, now time.Time,
+	ahead int) []*model.WalletLogPartition {
+
+	exists := make(map[time.Time]bool, len(existing))
+	for _, partition := range existing {
+		exists[partition.From.UTC()] = true
+	}
+
+	var missing []*model.WalletLogPartition
+	current := walletLogPartition(now).From
+	for i := 0; i <= ahead; i++ {
+		partition := walletLogPartition(current.AddDate(0, i, 0))
+		if !exists[partition.From] {
+			missing = append(missing, partition)
+		}
+	}
+	return missing
+}
+
+// expiredWalletLogPartitions returns the partitions that end before the retention period, which covers the
+// current month and the retentionMonths full months before it, oldest first. No partition expires when
+// retentionMonths is 0.
+func expiredWalletLogPartitions(existing []*model.WalletLogPartition, now time.Time,
+	retentionMonths int) []*model.WalletLogPartition {
+
+	if retentionMonths <= 0 {
+		return nil
+	}
+
+	cutoff := walletLogPartition(now).From.AddDate(0, -retentionMonths, 0)
+
+	var expired []*model.WalletLogPartition
+	for _, partition := range existing {
+		if !partition.To.After(cutoff) {
+			expired = append(expired, partition)
+		}
+	}
+	return expired
+}
+
+// MaintainPartitions creates the missing partitions of the current and coming months, then archives the
+// expired partitions oldest first. Archival stops at the first partition that cannot be archived, so
+// partitions are always archived in order.
+func (s *PartitionService) MaintainPartitions(ctx context.Context) (*dto.PartitionReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "PartitionService.MaintainPartitions")
+	defer span.End()
+
+	report := &dto.PartitionReport{Created: []string{}, Archived: []string{}}
+	now := time.Now()
+
+	partitions, err := s.repo.ListWalletLogPartitions(ctx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("partition_maintenance", "error")
+		return report, err
+	}
+
+	for _, partition := range missingWalletLogPartitions(partitions, now, s.partitions.Ahead) {
+		created, err := s.repo.CreateWalletLogPartition(ctx, partition)
+		if err != nil {
+			s.logger.Error("Failed to create wallet log partition",
+				zap.String("partition", partition.Name),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("partition_maintenance", "error")
+			return report, err
+		}
+		if created {
+			s.logger.Info("Wallet log partition created", zap.String("partition", partition.Name))
+			report.Created = append(report.Created, partition.Name)
+		}
+	}
+
+	for _, partition := range expiredWalletLogPartitions(partitions, now, s.partitions.RetentionMonths) {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		archived, err := s.archivePartition(ctx, partition, report)
+		if err != nil {
+			s.logger.Error("Failed to archive wallet log partition",
+				zap.String("partition", partition.Name),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("partition_maintenance", "error")
+			return report, err
+		}
+		if archived {
+			report.Archived = append(report.Archived, partition.Name)
+		}
+	}
+
+	s.metrics.RecordWalletOperation("partition_maintenance", "success")
+
+	return report, nil
+}
+
+// archivePartition generates the missing statements of a partition's month and archives the partition.
+// It reports false when another instance archived the partition first.
+func (s *PartitionService) archivePartition(ctx context.Context, partition *model.WalletLogPartition,
+	report *dto.PartitionReport) (bool, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "PartitionService.archivePartition",
+		trace.WithAttributes(attribute.String("partition", partition.Name)))
+	defer span.End()
+
+	if time.Now().Before(partition.To.Add(s.settleDelay)) {
+		return false, fmt.Errorf("%w: %s", ErrStatementPeriodOpen, partition.Name)
+	}
+
+	if s.reportingEnabled {
+		pending, err := s.repo.CountWalletLogsNotRolledUp(ctx, partition)
+		if err != nil {
+			return false, err
+		}
+
+		if pending > 0 {
+			return false, fmt.Errorf("%w: %s", ErrPartitionNotRolledUp, partition.Name)
+		}
+	}
+
+	period := partition.From.Format(statementPeriodLayout)
+	var afterWalletID int64
+	for {
+		wallets, err := s.repo.ListWalletsMissingStatement(ctx, partition, period, afterWalletID,
+			s.partitions.StatementBatchSize)
+		if err != nil {
+			return false, err
+		}
+		if len(wallets) == 0 {
+			break
+		}
+
+		for _, wallet := range wallets {
+			if _, err := s.statements.generateStatement(ctx, wallet, period, partition.From, partition.To); err != nil {
+				return false, fmt.Errorf("generate statement of wallet_id=%d for %s: %w", wallet.ID, period, err)
+			}
+			report.StatementsGenerated++
+		}
+		afterWalletID = wallets[len(wallets)-1].ID
+	}
+
+	archive, err := s.repo.ArchiveWalletLogPartition(ctx, partition, s.partitions.ArchiveMode)
+	if err != nil {
+		return false, err
+	}
+	if archive == nil {
+		return false, nil
+	}
+
+	s.logger.Info("Wallet log partition archived",
+		zap.String("partition", archive.PartitionName),
+		zap.String("mode", archive.Mode),
+		zap.Int("logs", archive.LogCount),
+		zap.Int("wallets", archive.WalletCount))
+
+	return true, nil
+}
@@ -0,0 +1,58 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func partitionNames(partitions []*model.WalletLogPartition) []string {
+	names := make([]string, 0, len(partitions))
+	for _, partition := range partitions {
+		names = append(names, partition.Name)
+	}
+	return names
+}
+
+func TestWalletLogPartition(t *testing.T) {
+	partition := walletLogPartition(time.Date(2026, 12, 31, 23, 59, 0, 0, time.UTC))
+	assert.Equal(t, "wallet_logs_2026_12", partition.Name)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), partition.From)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), partition.To)
+
+	// Months are calendar months in UTC
+	partition = walletLogPartition(time.Date(2026, 3, 1, 1, 0, 0, 0, time.FixedZone("CET", 3600)))
+	assert.Equal(t, "wallet_logs_2026_03", partition.Name)
+}
+
+func TestMissingWalletLogPartitions(t *testing.T) {
+	now := time.Date(2026, 11, 15, 18, 30, 0, 0, time.UTC)
+	existing := []*model.WalletLogPartition{
+		walletLogPartition(time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)),
+		walletLogPartition(time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)),
+		walletLogPartition(time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC)),
+	}
+
+	missing := missingWalletLogPartitions(existing, now, 3)
+	assert.Equal(t, []string{"wallet_logs_2026_12", "wallet_logs_2027_02"}, partitionNames(missing))
+
+	assert.Empty(t, missingWalletLogPartitions(existing, now, 0))
+}
+
+func TestExpiredWalletLogPartitions(t *testing.T) {
+	now := time.Date(2026, 5, 10, 0, 0, 0, 0, time.UTC)
+	var existing []*model.WalletLogPartition
+	for month := time.January; month <= time.June; month++ {
+		existing = append(existing, walletLogPartition(time.Date(2026, month, 1, 0, 0, 0, 0, time.UTC)))
+	}
+
+	// March and April are the two full months kept before May
+	expired := expiredWalletLogPartitions(existing, now, 2)
+	assert.Equal(t, []string{"wallet_logs_2026_01", "wallet_logs_2026_02"}, partitionNames(expired))
+
+	assert.Empty(t, expiredWalletLogPartitions(existing, now, 0), "0 keeps all logs")
+	assert.Empty(t, expiredWalletLogPartitions(existing, now, 12))
+}
@@ -0,0 +1,84 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// periodicSchedule returns when a periodic task runs next, given the current time
+type periodicSchedule func(now time.Time) time.Time
+
+// every schedules a periodic task at a fixed interval after its previous run
+func every(interval time.Duration) periodicSchedule {
+	return func(now time.Time) time.Time {
+		return now.Add(interval)
+	}
+}
+
+// periodicTask describes a task the schedulers run in the background for the lifetime of the application
+type periodicTask struct {
+	// name identifies the scheduler in its start and stop logs, e.g. "expiry scheduler"
+	name string
+	// schedule tells when the task runs next
+	schedule periodicSchedule
+	// runAtStart runs the task once right away instead of waiting for its first scheduled run
+	runAtStart bool
+	// run performs one run of the task and logs its outcome; it should return promptly once ctx is cancelled
+	run func(ctx context.Context)
+}
+
+// startPeriodicTask registers lifecycle hooks that run task on a goroutine of its own from the start of the
+// application until it stops. Runs never overlap: the next run is scheduled once the previous one returns.
+// fields are logged when the task starts.
+func startPeriodicTask(lc fx.Lifecycle, logger *zap.Logger, task periodicTask, fields ...zap.Field) {
+	var (
+		cancel context.CancelFunc
+		done   chan struct{}
+	)
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			logger.Info("Starting "+task.name, fields...)
+
+			var runCtx context.Context
+			runCtx, cancel = context.WithCancel(context.Background())
+			done = make(chan struct{})
+			go runPeriodicTask(runCtx, task, done)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			logger.Info("Stopping " + task.name)
+			cancel()
+
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+}
+
+func runPeriodicTask(ctx context.Context, task periodicTask, done chan struct{}) {
+	defer close(done)
+
+	if task.runAtStart {
+		task.run(ctx)
+	}
+
+	for {
+		timer := time.NewTimer(time.Until(task.schedule(time.Now())))
+
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+			task.run(ctx)
+		}
+	}
+}
@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunPeriodicTask(t *testing.T) {
+	t.Run("Runs On Schedule Until Cancelled", func(t *testing.T) {
+		var runs atomic.Int32
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan struct{})
+
+		go runPeriodicTask(ctx, periodicTask{
+			name:     "test scheduler",
+			schedule: every(time.Millisecond),
+			run:      func(context.Context) { runs.Add(1) },
+		}, done)
+
+		assert.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, time.Millisecond)
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("periodic task did not stop once cancelled")
+		}
+	})
+
+	t.Run("Runs At Start", func(t *testing.T) {
+		ran := make(chan struct{}, 1)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		done := make(chan struct{})
+
+		go runPeriodicTask(ctx, periodicTask{
+			name:       "test scheduler",
+			schedule:   every(time.Hour),
+			runAtStart: true,
+			run:        func(context.Context) { ran <- struct{}{} },
+		}, done)
+
+		select {
+		case <-ran:
+		case <-time.After(time.Second):
+			t.Fatal("periodic task did not run at start")
+		}
+	})
+}
@@ -0,0 +1,248 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
+	"github.com/playconomy/wallet-service/internal/repository"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// Kinds of rate limit buckets, used as the key_type label of rejections
+const (
+	rateLimitKeyUser    = "user"
+	rateLimitKeyService = "service"
+	rateLimitKeyIP      = "ip"
+)
+
+// defaultRateLimitRoute names the route of the bucket shared by the routes without a limit of their own
+const defaultRateLimitRoute = "default"
+
+// RateLimitServiceParams are the dependencies of the rate limiter. The store is optional; without one, the
+// store named in the configuration is used.
+type RateLimitServiceParams struct {
+	fx.In
+
+	Lifecycle fx.Lifecycle
+	Repo      repository.RateLimitRepository
+	Store     ratelimit.Store `optional:"true"`
+	Config    *config.Config
+	Obs       *observability.Observability
+}
+
+// RateLimitService limits the requests of clients with token buckets. A request takes a token from the bucket
+// of its IP address, shared by every route, then from the bucket of its principal for its route. Service
+// principals get their own buckets with larger limits. The limits can change at runtime; the store cannot.
+//
+// Requests are allowed when the store fails: an unavailable store must not take the API down with it.
+type RateLimitService struct {
+	store         ratelimit.Store
+	pruneInterval time.Duration
+	limits        atomic.Pointer[rateLimits]
+	logger        *zap.Logger
+	metrics       *metrics.Metrics
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// rateLimits are the limits in effect with their compiled routes
+type rateLimits struct {
+	config config.RateLimitConfig
+	routes *ratelimit.Routes
+}
+
+// Compile-time verification that RateLimitService implements RateLimitServiceInterface
+var _ RateLimitServiceInterface = (*RateLimitService)(nil)
+
+// rateLimitRepositoryStore keeps the buckets in the database, shared by every instance
+type rateLimitRepositoryStore struct {
+	repo repository.RateLimitRepository
+}
+
+func (s rateLimitRepositoryStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return s.repo.TakeRateLimitToken(ctx, key, limit)
+}
+
+func (s rateLimitRepositoryStore) Prune(ctx context.Context, idle time.Duration) (int, error) {
+	return s.repo.PruneRateLimitBuckets(ctx, idle)
+}
+
+// NewRateLimitService creates the rate limiter and registers the lifecycle hooks that remove unused buckets
+func NewRateLimitService(params RateLimitServiceParams) *RateLimitService {
+	cfg := params.Config.Server.RateLimit
+
+	store := params.Store
+	storeName := "custom"
+	if store == nil {
+		storeName = cfg.Store
+		if cfg.Store == "postgres" {
+			store = rateLimitRepositoryStore{repo: params.Repo}
+		} else {
+			store = ratelimit.NewMemoryStore()
+		}
+	}
+
+	s := &RateLimitService{
+		store:         store,
+		pruneInterval: cfg.PruneInterval,
+		logger:        params.Obs.Logger.Logger.With(zap.String("component", "rate_limiter")),
+		metrics:       params.Obs.Metrics,
+	}
+	s.SetLimits(cfg)
+
+	params.Lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			s.logger.Info("Starting rate limiter",
+				zap.Bool("enabled", cfg.Enabled),
+				zap.String("store", storeName),
+				zap.Duration("prune_interval", s.pruneInterval))
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			s.cancel = cancel
+			s.done = make(chan struct{})
+			go s.loop(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			s.logger.Info("Stopping rate limiter")
+			s.cancel()
+
+			select {
+			case <-s.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return s
+}
+
+// SetLimits replaces the limits in effect
+func (s *RateLimitService) SetLimits(cfg config.RateLimitConfig) {
+	routes := make([]string, 0, len(cfg.Routes))
+	for route := range cfg.Routes {
+		routes = append(routes, route)
+	}
+	s.limits.Store(&rateLimits{config: cfg, routes: ratelimit.NewRoutes(routes)})
+}
+
+func (s *RateLimitService) loop(ctx context.Context) {
+	defer close(s.done)
+
+	ticker := time.NewTicker(s.pruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune(ctx)
+		}
+	}
+}
+
+// prune removes the buckets unused for the longest period of the limits, which are full
+func (s *RateLimitService) prune(ctx context.Context) {
+	limits := s.limits.Load().config
+	idle := max(limits.Default.Period, limits.IP.Period)
+	for _, limit := range limits.Routes {
+		idle = max(idle, limit.Period)
+	}
+	if idle == 0 {
+		return
+	}
+
+	removed, err := s.store.Prune(ctx, idle)
+	if err != nil {
+		if !errors.Is(err, context.Canceled) {
+			s.logger.Error("Failed to prune rate limit buckets", zap.Error(err))
+		}
+		return
+	}
+	if removed > 0 {
+		s.logger.Debug("Pruned rate limit buckets", zap.Int("removed", removed))
+	}
+}
+
+// Allow takes the tokens of a request and returns the result of the bucket that refused it or, when the
+// request is allowed, of the bucket with the fewest tokens left. The result has a zero limit when no limit
+// applies. A request without an IP address or a principal takes no token from that bucket, so the IP
+// address can be limited before the request authenticates and the principal after.
+func (s *RateLimitService) Allow(ctx context.Context, request ratelimit.Request) ratelimit.Result {
+	limits := s.limits.Load()
+	cfg := limits.config
+	if !cfg.Enabled {
+		return ratelimit.Result{Allowed: true}
+	}
+
+	route, ok := limits.routes.Match(request.Method, request.Path)
+	limit := cfg.Routes[route]
+	if !ok {
+		route, limit = defaultRateLimitRoute, cfg.Default
+	}
+
+	var result ratelimit.Result
+	if request.IP != "" {
+		result = s.take(ctx, route, rateLimitKeyIP, "ip:"+request.IP, cfg.IP)
+		if !result.Allowed {
+			return result
+		}
+	}
+
+	if request.UserID > 0 {
+		keyType, key := rateLimitKeyUser, "user:"+strconv.Itoa(request.UserID)
+		if cfg.IsService(request.Role) {
+			keyType, key = rateLimitKeyService, "service:"+request.Role+":"+strconv.Itoa(request.UserID)
+			limit = limit.Scale(cfg.ServiceFactor)
+		}
+
+		principal := s.take(ctx, route, keyType, route+"|"+key, limit)
+		if !principal.Allowed {
+			return principal
+		}
+		if !principal.Limit.IsZero() && (result.Limit.IsZero() || principal.Remaining < result.Remaining) {
+			result = principal
+		}
+	}
+
+	if result.Limit.IsZero() {
+		result.Allowed = true
+	}
+	return result
+}
+
+// take takes a token from a bucket, allowing the request when the limit is zero or the store fails
+func (s *RateLimitService) take(ctx context.Context, route, keyType, key string,
+	limit ratelimit.Limit) ratelimit.Result {
+
+	if limit.IsZero() {
+		return ratelimit.Result{Allowed: true}
+	}
+
+	result, err := s.store.Take(ctx, key, limit)
+	if err != nil {
+		s.logger.Warn("Rate limit store failed, allowing request",
+			zap.String("route", route),
+			zap.String("key_type", keyType),
+			zap.Error(err))
+		return ratelimit.Result{Allowed: true}
+	}
+
+	if !result.Allowed {
+		s.metrics.RecordRateLimitRejection(route, keyType)
+	}
+	return result
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/fx/fxtest"
+)
+
+// failingRateLimitStore is a store that is unavailable
+type failingRateLimitStore struct{}
+
+func (failingRateLimitStore) Take(ctx context.Context, key string, limit ratelimit.Limit) (ratelimit.Result, error) {
+	return ratelimit.Result{}, errors.New("store unavailable")
+}
+
+func (failingRateLimitStore) Prune(ctx context.Context, idle time.Duration) (int, error) {
+	return 0, errors.New("store unavailable")
+}
+
+func setupRateLimitService(t *testing.T, store ratelimit.Store) *RateLimitService {
+	obs := observability.NewTestObservability()
+
+	cfg := &config.Config{Server: config.ServerConfig{RateLimit: config.RateLimitConfig{
+		Enabled: true,
+		Store:   "memory",
+		Default: ratelimit.Limit{Requests: 5, Period: time.Minute},
+		Routes: map[string]ratelimit.Limit{
+			"POST /exchange": {Requests: 2, Period: time.Minute},
+		},
+		IP:            ratelimit.Limit{Requests: 4, Period: time.Minute},
+		ServiceRoles:  []string{"game_server"},
+		ServiceFactor: 3,
+		PruneInterval: time.Minute,
+	}}}
+
+	return NewRateLimitService(RateLimitServiceParams{
+		Lifecycle: fxtest.NewLifecycle(t),
+		Store:     store,
+		Config:    cfg,
+		Obs:       obs,
+	})
+}
+
+func allowedCount(limiter *RateLimitService, request ratelimit.Request, attempts int) int {
+	allowed := 0
+	for i := 0; i < attempts; i++ {
+		if limiter.Allow(context.Background(), request).Allowed {
+			allowed++
+		}
+	}
+	return allowed
+}
+
+func TestRateLimitService(t *testing.T) {
+	exchange := ratelimit.Request{Method: "POST", Path: "/exchange", UserID: 1, Role: "user"}
+
+	t.Run("Route Limit Per User", func(t *testing.T) {
+		limiter := setupRateLimitService(t, nil)
+
+		assert.Equal(t, 2, allowedCount(limiter, exchange, 5))
+
+		other := exchange
+		other.UserID = 2
+		result := limiter.Allow(context.Background(), other)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 2, result.Limit.Requests)
+		assert.Equal(t, 1, result.Remaining)
+
+		// Routes without a limit of their own share the default bucket
+		wallet := ratelimit.Request{Method: "GET", Path: "/1", UserID: 1, Role: "user"}
+		assert.Equal(t, 5, allowedCount(limiter, wallet, 10))
+	})
+
+	t.Run("Service Principals", func(t *testing.T) {
+		limiter := setupRateLimitService(t, nil)
+
+		gameServer := exchange
+		gameServer.Role = "game_server"
+		assert.Equal(t, 6, allowedCount(limiter, gameServer, 10))
+	})
+
+	t.Run("IP Limit Over Every User", func(t *testing.T) {
+		limiter := setupRateLimitService(t, nil)
+
+		allowed := 0
+		for userID := 1; userID <= 10; userID++ {
+			request := exchange
+			request.UserID, request.IP = userID, "203.0.113.7"
+			if limiter.Allow(context.Background(), request).Allowed {
+				allowed++
+			}
+		}
+		assert.Equal(t, 4, allowed)
+
+		result := limiter.Allow(context.Background(), ratelimit.Request{Method: "GET", Path: "/1", IP: "203.0.113.7"})
+		assert.False(t, result.Allowed)
+		assert.Positive(t, result.RetryAfter)
+	})
+
+	t.Run("Limits Changed At Runtime", func(t *testing.T) {
+		limiter := setupRateLimitService(t, nil)
+
+		limits := limiter.limits.Load().config
+		limits.Enabled = false
+		limiter.SetLimits(limits)
+
+		result := limiter.Allow(context.Background(), exchange)
+		assert.True(t, result.Allowed)
+		assert.True(t, result.Limit.IsZero())
+		assert.Equal(t, 10, allowedCount(limiter, exchange, 10))
+	})
+
+	t.Run("Store Failure Allows Requests", func(t *testing.T) {
+		limiter := setupRateLimitService(t, failingRateLimitStore{})
+
+		assert.Equal(t, 5, allowedCount(limiter, exchange, 5))
+	})
+}
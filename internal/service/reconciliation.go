@@ -0,0 +1,379 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// ReconciliationService checks that every wallet balance equals the sum of its log entries
+type ReconciliationService struct {
+	repo       repository.ReconciliationRepository
+	walletRepo repository.WalletRepository
+	config     config.ReconciliationConfig
+	logger     *zap.Logger
+	metrics    *metrics.Metrics
+	tracer     *tracing.Tracer
+}
+
+// Compile-time verification that ReconciliationService implements ReconciliationServiceInterface
+var _ ReconciliationServiceInterface = (*ReconciliationService)(nil)
+
+// NewReconciliationService creates a new reconciliation service
+func NewReconciliationService(repo repository.ReconciliationRepository, walletRepo repository.WalletRepository,
+	cfg *config.Config, obs *observability.Observability) *ReconciliationService {
+	return &ReconciliationService{
+		repo:       repo,
+		walletRepo: walletRepo,
+		config:     cfg.Reconciliation,
+		logger:     obs.Logger.Logger.With(zap.String("component", "reconciliation_service")),
+		metrics:    obs.Metrics,
+		tracer:     obs.Tracer,
+	}
+}
+
+// Run compares every wallet balance with its log history and records drifted wallets
+func (s *ReconciliationService) Run(ctx context.Context, trigger string) (*dto.ReconciliationReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReconciliationService.Run",
+		trace.WithAttributes(attribute.String("trigger", trigger)))
+	defer span.End()
+
+	// The lock is held in the database, so runs from the scheduler, the admin endpoint and the CLI do not
+	// overlap on any instance
+	unlock, acquired, err := s.repo.TryLockReconciliation(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		s.logger.Warn("Reconciliation already in progress", zap.String("trigger", trigger))
+		return nil, ErrReconciliationInProgress
+	}
+	defer unlock()
+
+	s.logger.Info("Starting reconciliation run",
+		zap.String("trigger", trigger),
+		zap.Int("batch_size", s.config.BatchSize),
+		zap.Float64("tolerance", s.config.Tolerance),
+		zap.Bool("freeze_on_drift", s.config.FreezeOnDrift))
+
+	run, err := s.repo.CreateReconciliationRun(ctx, trigger)
+	if err != nil {
+		s.metrics.RecordReconciliationRun(trigger, model.ReconciliationFailed, 0, 0, 0)
+		return nil, err
+	}
+
+	mismatches, scanErr := s.scan(ctx, run)
+
+	run.Status = model.ReconciliationCompleted
+	if scanErr != nil {
+		message := scanErr.Error()
+		run.Status = model.ReconciliationFailed
+		run.Error = &message
+	}
+
+	// Record the outcome even if the caller's context was cancelled mid-run
+	completed, err := s.repo.CompleteReconciliationRun(context.WithoutCancel(ctx), run)
+	if err != nil {
+		s.logger.Error("Failed to store reconciliation run result",
+			zap.Int64("run_id", run.ID),
+			zap.Error(err))
+		return nil, err
+	}
+	if completed != nil {
+		run = completed
+	}
+
+	s.metrics.RecordReconciliationRun(trigger, run.Status, run.WalletsChecked, run.Mismatches, run.TotalDrift)
+
+	if scanErr != nil {
+		s.logger.Error("Reconciliation run failed",
+			zap.Int64("run_id", run.ID),
+			zap.Int("wallets_checked", run.WalletsChecked),
+			zap.Error(scanErr))
+		return nil, scanErr
+	}
+
+	s.logger.Info("Reconciliation run completed",
+		zap.Int64("run_id", run.ID),
+		zap.String("trigger", trigger),
+		zap.Int("wallets_checked", run.WalletsChecked),
+		zap.Int("mismatches", run.Mismatches),
+		zap.Float64("total_drift", run.TotalDrift))
+
+	return toReconciliationReport(run, mismatches), nil
+}
+
+// scan walks all wallets in pages and records a mismatch for every drifted wallet
+func (s *ReconciliationService) scan(
+	ctx context.Context, run *model.ReconciliationRun) ([]*model.ReconciliationMismatch, error) {
+
+	var mismatches []*model.ReconciliationMismatch
+	var afterWalletID int64
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return mismatches, err
+		}
+
+		balances, err := s.repo.ListWalletLedgerBalances(ctx, afterWalletID, s.config.BatchSize)
+		if err != nil {
+			return mismatches, err
+		}
+
+		for _, balance := range balances {
+			run.WalletsChecked++
+			afterWalletID = balance.WalletID
+
+			drift, drifted := walletDrift(balance.Balance, balance.LedgerBalance, s.config.Tolerance)
+			if !drifted {
+				continue
+			}
+
+			mismatch, err := s.recordMismatch(ctx, run.ID, balance, drift)
+			if err != nil {
+				return mismatches, err
+			}
+
+			run.Mismatches++
+			run.TotalDrift = roundCents(run.TotalDrift + math.Abs(drift))
+			mismatches = append(mismatches, mismatch)
+		}
+
+		if len(balances) < s.config.BatchSize {
+			return mismatches, nil
+		}
+	}
+}
+
+// recordMismatch persists a mismatch and, if configured, freezes the wallet in the same transaction
+func (s *ReconciliationService) recordMismatch(ctx context.Context, runID int64,
+	balance *model.WalletLedgerBalance, drift float64) (*model.ReconciliationMismatch, error) {
+
+	s.logger.Warn("Wallet balance drift detected",
+		zap.Int64("run_id", runID),
+		zap.Int64("wallet_id", balance.WalletID),
+		zap.Int("user_id", balance.UserID),
+		zap.Float64("balance", balance.Balance),
+		zap.Float64("ledger_balance", balance.LedgerBalance),
+		zap.Float64("drift", drift),
+		zap.String("status", balance.Status))
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Only active wallets are frozen; wallets already frozen or closed keep their status
+	freeze := false
+	if s.config.FreezeOnDrift && balance.Status == model.WalletStatusActive {
+		wallets, err := s.walletRepo.GetWalletsByUserIDForUpdate(ctx, balance.UserID, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(wallets) > 0 && wallets[0].Status == model.WalletStatusActive {
+			wallet := wallets[0]
+			reason := fmt.Sprintf("reconciliation run %d: balance drifted from log history by %.2f", runID, drift)
+			if _, err := changeWalletStatus(ctx, s.walletRepo, wallet, model.WalletStatusFrozen, reason, nil, tx); err != nil {
+				return nil, err
+			}
+			freeze = true
+		}
+	}
+
+	mismatch, err := s.repo.CreateReconciliationMismatch(ctx, &model.ReconciliationMismatch{
+		RunID:         runID,
+		WalletID:      balance.WalletID,
+		UserID:        balance.UserID,
+		Balance:       balance.Balance,
+		LedgerBalance: balance.LedgerBalance,
+		Drift:         drift,
+		Frozen:        freeze,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if freeze {
+		s.metrics.RecordReconciliationFreeze()
+	}
+
+	return mismatch, nil
+}
+
+// GetReport retrieves the report of a previous reconciliation run
+func (s *ReconciliationService) GetReport(ctx context.Context, runID int64) (*dto.ReconciliationReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReconciliationService.GetReport",
+		trace.WithAttributes(attribute.Int64("run_id", runID)))
+	defer span.End()
+
+	run, err := s.repo.GetReconciliationRun(ctx, runID)
+	if err != nil || run == nil {
+		return nil, err
+	}
+
+	return s.buildReport(ctx, run)
+}
+
+// GetLatestReport retrieves the report of the most recent reconciliation run
+func (s *ReconciliationService) GetLatestReport(ctx context.Context) (*dto.ReconciliationReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReconciliationService.GetLatestReport")
+	defer span.End()
+
+	run, err := s.repo.GetLatestReconciliationRun(ctx)
+	if err != nil || run == nil {
+		return nil, err
+	}
+
+	return s.buildReport(ctx, run)
+}
+
+func (s *ReconciliationService) buildReport(
+	ctx context.Context, run *model.ReconciliationRun) (*dto.ReconciliationReport, error) {
+
+	mismatches, err := s.repo.ListReconciliationMismatches(ctx, run.ID)
+	if err != nil {
+		s.logger.Error("Error retrieving reconciliation mismatches",
+			zap.Int64("run_id", run.ID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	return toReconciliationReport(run, mismatches), nil
+}
+
+// ResolveMismatch marks a mismatch as reviewed and optionally unfreezes the wallet.
+// A wallet stays frozen while any other mismatch recorded against it is still open.
+func (s *ReconciliationService) ResolveMismatch(ctx context.Context, mismatchID int64, resolvedBy int,
+	req *dto.ResolveMismatchRequest) (*dto.ReconciliationMismatch, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "ReconciliationService.ResolveMismatch",
+		trace.WithAttributes(
+			attribute.Int64("mismatch_id", mismatchID),
+			attribute.Int("resolved_by", resolvedBy),
+			attribute.Bool("unfreeze", req.Unfreeze),
+		))
+	defer span.End()
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	mismatch, err := s.repo.ResolveReconciliationMismatch(ctx, mismatchID, resolvedBy, req.Note, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if mismatch == nil {
+		return nil, ErrMismatchNotFound
+	}
+
+	unfrozen := false
+	if req.Unfreeze {
+		open, err := s.repo.CountUnresolvedMismatches(ctx, mismatch.WalletID, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		if open == 0 {
+			wallets, err := s.walletRepo.GetWalletsByUserIDForUpdate(ctx, mismatch.UserID, tx)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(wallets) > 0 && wallets[0].Status == model.WalletStatusFrozen {
+				wallet := wallets[0]
+				reason := fmt.Sprintf("reconciliation mismatch %d resolved: %s", mismatchID, req.Note)
+				if _, err := changeWalletStatus(ctx, s.walletRepo, wallet, model.WalletStatusActive,
+					reason, &resolvedBy, tx); err != nil {
+					return nil, err
+				}
+				unfrozen = true
+			}
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reconciliation mismatch resolved",
+		zap.Int64("mismatch_id", mismatchID),
+		zap.Int64("wallet_id", mismatch.WalletID),
+		zap.Int("resolved_by", resolvedBy),
+		zap.Bool("unfrozen", unfrozen))
+
+	result := toReconciliationMismatch(mismatch)
+	return &result, nil
+}
+
+// walletDrift returns the difference between a stored balance and its ledger balance,
+// rounded to the precision of the balance columns, and whether it exceeds the tolerance
+func walletDrift(balance, ledgerBalance, tolerance float64) (float64, bool) {
+	drift := roundCents(balance - ledgerBalance)
+	return drift, math.Abs(drift) > tolerance
+}
+
+// roundCents rounds an amount to the two decimal places stored by NUMERIC(20, 2) columns
+func roundCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func toReconciliationReport(
+	run *model.ReconciliationRun, mismatches []*model.ReconciliationMismatch) *dto.ReconciliationReport {
+
+	report := &dto.ReconciliationReport{
+		RunID:          run.ID,
+		Trigger:        run.Trigger,
+		Status:         run.Status,
+		WalletsChecked: run.WalletsChecked,
+		MismatchCount:  run.Mismatches,
+		TotalDrift:     run.TotalDrift,
+		Error:          run.Error,
+		StartedAt:      run.StartedAt,
+		FinishedAt:     run.FinishedAt,
+		Mismatches:     make([]dto.ReconciliationMismatch, len(mismatches)),
+	}
+
+	for i, mismatch := range mismatches {
+		report.Mismatches[i] = toReconciliationMismatch(mismatch)
+	}
+
+	return report
+}
+
+func toReconciliationMismatch(mismatch *model.ReconciliationMismatch) dto.ReconciliationMismatch {
+	return dto.ReconciliationMismatch{
+		ID:             mismatch.ID,
+		RunID:          mismatch.RunID,
+		WalletID:       mismatch.WalletID,
+		UserID:         mismatch.UserID,
+		Balance:        mismatch.Balance,
+		LedgerBalance:  mismatch.LedgerBalance,
+		Drift:          mismatch.Drift,
+		Frozen:         mismatch.Frozen,
+		ResolvedBy:     mismatch.ResolvedBy,
+		ResolvedAt:     mismatch.ResolvedAt,
+		ResolutionNote: mismatch.ResolutionNote,
+		CreatedAt:      mismatch.CreatedAt,
+	}
+}
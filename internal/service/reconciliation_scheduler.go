@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ReconciliationScheduler runs reconciliation periodically for the lifetime of the application
+type ReconciliationScheduler struct {
+	service ReconciliationServiceInterface
+	logger  *zap.Logger
+}
+
+// NewReconciliationScheduler creates a scheduler and registers its lifecycle hooks.
+// The scheduler does nothing when reconciliation is disabled in the configuration.
+func NewReconciliationScheduler(lc fx.Lifecycle, svc ReconciliationServiceInterface,
+	cfg *config.Config, obs *observability.Observability) *ReconciliationScheduler {
+
+	scheduler := &ReconciliationScheduler{
+		service: svc,
+		logger:  obs.Logger.Logger.With(zap.String("component", "reconciliation_scheduler")),
+	}
+
+	if !cfg.Reconciliation.Enabled {
+		scheduler.logger.Info("Scheduled reconciliation disabled")
+		return scheduler
+	}
+
+	startPeriodicTask(lc, scheduler.logger, periodicTask{
+		name:     "reconciliation scheduler",
+		schedule: every(cfg.Reconciliation.Interval),
+		run:      scheduler.run,
+	}, zap.Duration("interval", cfg.Reconciliation.Interval))
+
+	return scheduler
+}
+
+func (s *ReconciliationScheduler) run(ctx context.Context) {
+	_, err := s.service.Run(ctx, model.ReconciliationTriggerSchedule)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled reconciliation failed", zap.Error(err))
+	}
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWalletDrift(t *testing.T) {
+	testCases := []struct {
+		name          string
+		balance       float64
+		ledgerBalance float64
+		tolerance     float64
+		expectedDrift float64
+		expectDrifted bool
+	}{
+		{
+			name:          "Balanced",
+			balance:       150.50,
+			ledgerBalance: 150.50,
+			expectedDrift: 0,
+			expectDrifted: false,
+		},
+		{
+			name:          "Float Noise Is Ignored",
+			balance:       0.3,
+			ledgerBalance: 0.1 + 0.2,
+			expectedDrift: 0,
+			expectDrifted: false,
+		},
+		{
+			name:          "Balance Above Ledger",
+			balance:       160.50,
+			ledgerBalance: 150.50,
+			expectedDrift: 10,
+			expectDrifted: true,
+		},
+		{
+			name:          "Balance Below Ledger",
+			balance:       140.25,
+			ledgerBalance: 150.50,
+			expectedDrift: -10.25,
+			expectDrifted: true,
+		},
+		{
+			name:          "Within Tolerance",
+			balance:       100.01,
+			ledgerBalance: 100,
+			tolerance:     0.05,
+			expectedDrift: 0.01,
+			expectDrifted: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			drift, drifted := walletDrift(tc.balance, tc.ledgerBalance, tc.tolerance)
+
+			assert.InDelta(t, tc.expectedDrift, drift, 0.0001)
+			assert.Equal(t, tc.expectDrifted, drifted)
+		})
+	}
+}
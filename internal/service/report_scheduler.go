@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// ReportRollupScheduler keeps the report rollups up to date for the lifetime of the application
+type ReportRollupScheduler struct {
+	service ReportServiceInterface
+	logger  *zap.Logger
+}
+
+// NewReportRollupScheduler creates a scheduler and registers its lifecycle hooks.
+// The scheduler does nothing when reporting is disabled in the configuration;
+// reports then only cover the logs rolled up by other instances.
+func NewReportRollupScheduler(lc fx.Lifecycle, svc ReportServiceInterface,
+	cfg *config.Config, obs *observability.Observability) *ReportRollupScheduler {
+
+	scheduler := &ReportRollupScheduler{
+		service: svc,
+		logger:  obs.Logger.Logger.With(zap.String("component", "report_rollup_scheduler")),
+	}
+
+	if !cfg.Reporting.Enabled {
+		scheduler.logger.Info("Scheduled report rollups disabled")
+		return scheduler
+	}
+
+	startPeriodicTask(lc, scheduler.logger, periodicTask{
+		name:     "report rollup scheduler",
+		schedule: every(cfg.Reporting.Interval),
+		run:      scheduler.run,
+	}, zap.Duration("interval", cfg.Reporting.Interval))
+
+	return scheduler
+}
+
+func (s *ReportRollupScheduler) run(ctx context.Context) {
+	report, err := s.service.RollUp(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled report rollup failed", zap.Error(err))
+		return
+	}
+	if report != nil && report.LogsRolledUp > 0 {
+		s.logger.Debug("Scheduled report rollup completed",
+			zap.Int("logs_rolled_up", report.LogsRolledUp))
+	}
+}
@@ -0,0 +1,355 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const (
+	// defaultReportDays is the number of days a report covers when the request has no from date
+	defaultReportDays = 30
+
+	// defaultTopWallets is the number of wallets the top wallet report lists when the request has no limit
+	defaultTopWallets = 10
+)
+
+// nonSpendSources are the log sources that are not spends; every other debit is a spend for its reason
+var nonSpendSources = []string{
+	model.TransactionExchange,
+	model.TransactionBonus,
+	model.TransactionExpiry,
+	model.TransactionReverseExchange,
+	model.TransactionReverseExchangeRefund,
+}
+
+// ReportService maintains the daily rollups of wallet logs and serves the reports built from them
+type ReportService struct {
+	repo       repository.ReportRepository
+	currencies config.CurrencyConfig
+	reporting  config.ReportingConfig
+	logger     *zap.Logger
+	metrics    *metrics.Metrics
+	tracer     *tracing.Tracer
+}
+
+// Compile-time verification that ReportService implements ReportServiceInterface
+var _ ReportServiceInterface = (*ReportService)(nil)
+
+// NewReportService creates a new report service
+func NewReportService(repo repository.ReportRepository, cfg *config.Config, obs *observability.Observability) *ReportService {
+	return &ReportService{
+		repo:       repo,
+		currencies: currencySettings(cfg.Currency),
+		reporting:  cfg.Reporting,
+		logger:     obs.Logger.Logger.With(zap.String("component", "report_service")),
+		metrics:    obs.Metrics,
+		tracer:     obs.Tracer,
+	}
+}
+
+// newReportFilter converts the range of a report request. Without dates a report covers the last
+// defaultReportDays days including today (UTC). It returns ErrInvalidReportRange when a date cannot be
+// parsed, the range is empty or longer than maxRange.
+func newReportFilter(currencies config.CurrencyConfig, maxRange time.Duration, req *dto.ReportRequest,
+	now time.Time) (model.ReportFilter, error) {
+
+	currency, err := resolveCurrency(currencies, req.Currency)
+	if err != nil {
+		return model.ReportFilter{}, err
+	}
+
+	filter := model.ReportFilter{
+		Currency: currency,
+		To:       now.UTC().Truncate(24*time.Hour).AddDate(0, 0, 1),
+	}
+
+	if req.To != "" {
+		if filter.To, err = time.Parse(time.DateOnly, req.To); err != nil {
+			return model.ReportFilter{}, fmt.Errorf("%w: to must be a date (YYYY-MM-DD)", ErrInvalidReportRange)
+		}
+	}
+
+	filter.From = filter.To.AddDate(0, 0, -defaultReportDays)
+	if req.From != "" {
+		if filter.From, err = time.Parse(time.DateOnly, req.From); err != nil {
+			return model.ReportFilter{}, fmt.Errorf("%w: from must be a date (YYYY-MM-DD)", ErrInvalidReportRange)
+		}
+	}
+
+	if !filter.From.Before(filter.To) {
+		return model.ReportFilter{}, fmt.Errorf("%w: from must be before to", ErrInvalidReportRange)
+	}
+
+	if filter.To.Sub(filter.From) > maxRange {
+		return model.ReportFilter{}, fmt.Errorf("%w: a report covers at most %d days",
+			ErrInvalidReportRange, int(maxRange/(24*time.Hour)))
+	}
+
+	if req.GameID != "" {
+		filter.GameID = &req.GameID
+	}
+	if req.TokenType != "" {
+		filter.TokenType = &req.TokenType
+	}
+
+	return filter, nil
+}
+
+// formatDay formats a day of a report
+func formatDay(day time.Time) string {
+	return day.UTC().Format(time.DateOnly)
+}
+
+// reportRange describes the range of a report and how far the rollups are up to date
+func (s *ReportService) reportRange(ctx context.Context, filter model.ReportFilter) (dto.ReportRange, error) {
+	state, err := s.repo.GetRollupState(ctx)
+	if err != nil {
+		return dto.ReportRange{}, err
+	}
+
+	result := dto.ReportRange{
+		Currency: filter.Currency,
+		From:     formatDay(filter.From),
+		To:       formatDay(filter.To),
+	}
+	if state.LastLogAt != nil {
+		rolledUpThrough := state.LastLogAt.UTC()
+		result.RolledUpThrough = &rolledUpThrough
+	}
+
+	return result, nil
+}
+
+// RollUp adds the wallet logs created since the last run, up to the configured lag before now, to the
+// daily rollups, one batch per transaction
+func (s *ReportService) RollUp(ctx context.Context) (*dto.RollupReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.RollUp")
+	defer span.End()
+
+	before := time.Now().UTC().Add(-s.reporting.Lag)
+	report := &dto.RollupReport{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		batch, err := s.repo.RollUpWalletLogs(ctx, before, s.reporting.BatchSize)
+		if err != nil {
+			s.logger.Error("Failed to roll up wallet logs",
+				zap.Time("before", before),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("report_rollup", "error")
+			return report, err
+		}
+
+		if batch.LastLogAt != nil {
+			rolledUpThrough := batch.LastLogAt.UTC()
+			report.RolledUpThrough = &rolledUpThrough
+		}
+		if batch.Logs == 0 {
+			break
+		}
+		report.LogsRolledUp += batch.Logs
+	}
+
+	s.metrics.RecordWalletOperation("report_rollup", "success")
+
+	return report, nil
+}
+
+// GetExchangeReport returns the daily exchange volume of each game token
+func (s *ReportService) GetExchangeReport(ctx context.Context, req *dto.ReportRequest) (*dto.ExchangeReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.GetExchangeReport",
+		trace.WithAttributes(attribute.String("currency", req.Currency)))
+	defer span.End()
+
+	filter, err := newReportFilter(s.currencies, s.reporting.MaxRange, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reportRange, err := s.reportRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.ListExchangeVolumes(ctx, filter)
+	if err != nil {
+		s.metrics.RecordWalletOperation("report_exchanges", "error")
+		return nil, err
+	}
+
+	report := &dto.ExchangeReport{ReportRange: reportRange, Volumes: []dto.ExchangeVolume{}}
+	for _, volume := range volumes {
+		report.Volumes = append(report.Volumes, dto.ExchangeVolume{
+			Day:            formatDay(volume.Day),
+			GameID:         volume.GameID,
+			TokenType:      volume.TokenType,
+			Exchanges:      volume.Exchanges,
+			Amount:         volume.Amount,
+			PlatformAmount: volume.PlatformAmount,
+		})
+	}
+
+	s.metrics.RecordWalletOperation("report_exchanges", "success")
+
+	return report, nil
+}
+
+// GetSpendReport returns the platform tokens spent per reason, largest first
+func (s *ReportService) GetSpendReport(ctx context.Context, req *dto.ReportRequest) (*dto.SpendReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.GetSpendReport",
+		trace.WithAttributes(attribute.String("currency", req.Currency)))
+	defer span.End()
+
+	filter, err := newReportFilter(s.currencies, s.reporting.MaxRange, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reportRange, err := s.reportRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	volumes, err := s.repo.ListSpendVolumes(ctx, filter, nonSpendSources)
+	if err != nil {
+		s.metrics.RecordWalletOperation("report_spend", "error")
+		return nil, err
+	}
+
+	report := &dto.SpendReport{ReportRange: reportRange, Volumes: []dto.SpendVolume{}}
+	for _, volume := range volumes {
+		report.Total += volume.Amount
+		report.Volumes = append(report.Volumes, dto.SpendVolume{
+			Reason: volume.Reason,
+			Spends: volume.Spends,
+			Amount: volume.Amount,
+		})
+	}
+	report.Total = roundCents(report.Total)
+
+	s.metrics.RecordWalletOperation("report_spend", "success")
+
+	return report, nil
+}
+
+// GetSupplyReport returns the platform tokens credited and debited per day and the supply at the end of each day
+func (s *ReportService) GetSupplyReport(ctx context.Context, req *dto.ReportRequest) (*dto.SupplyReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.GetSupplyReport",
+		trace.WithAttributes(attribute.String("currency", req.Currency)))
+	defer span.End()
+
+	filter, err := newReportFilter(s.currencies, s.reporting.MaxRange, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reportRange, err := s.reportRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points, err := s.repo.ListTokenSupply(ctx, filter)
+	if err != nil {
+		s.metrics.RecordWalletOperation("report_supply", "error")
+		return nil, err
+	}
+
+	report := &dto.SupplyReport{ReportRange: reportRange, Points: []dto.SupplyPoint{}}
+	for _, point := range points {
+		report.Points = append(report.Points, dto.SupplyPoint{
+			Day:     formatDay(point.Day),
+			Credits: point.Credits,
+			Debits:  point.Debits,
+			Net:     roundCents(point.Credits - point.Debits),
+			Supply:  point.Supply,
+		})
+	}
+
+	s.metrics.RecordWalletOperation("report_supply", "success")
+
+	return report, nil
+}
+
+// GetActiveWalletReport returns the number of wallets with at least one log per day
+func (s *ReportService) GetActiveWalletReport(ctx context.Context, req *dto.ReportRequest) (*dto.ActiveWalletReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.GetActiveWalletReport",
+		trace.WithAttributes(attribute.String("currency", req.Currency)))
+	defer span.End()
+
+	filter, err := newReportFilter(s.currencies, s.reporting.MaxRange, req, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	reportRange, err := s.reportRange(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	counts, err := s.repo.ListActiveWalletCounts(ctx, filter)
+	if err != nil {
+		s.metrics.RecordWalletOperation("report_active_wallets", "error")
+		return nil, err
+	}
+
+	report := &dto.ActiveWalletReport{ReportRange: reportRange, Counts: []dto.ActiveWalletCount{}}
+	for _, count := range counts {
+		report.Counts = append(report.Counts, dto.ActiveWalletCount{
+			Day:     formatDay(count.Day),
+			Wallets: count.Wallets,
+		})
+	}
+
+	s.metrics.RecordWalletOperation("report_active_wallets", "success")
+
+	return report, nil
+}
+
+// GetTopWallets returns the wallets of a currency with the largest balances. Unlike the other reports it
+// reads the current balances, not the rollups.
+func (s *ReportService) GetTopWallets(ctx context.Context, req *dto.TopWalletsRequest) ([]*dto.Wallet, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "ReportService.GetTopWallets",
+		trace.WithAttributes(attribute.String("currency", req.Currency)))
+	defer span.End()
+
+	currency, err := resolveCurrency(s.currencies, req.Currency)
+	if err != nil {
+		return nil, err
+	}
+
+	limit := req.Limit
+	if limit == 0 {
+		limit = defaultTopWallets
+	}
+
+	wallets, err := s.repo.ListTopWallets(ctx, currency, limit)
+	if err != nil {
+		s.metrics.RecordWalletOperation("report_top_wallets", "error")
+		return nil, err
+	}
+
+	result := make([]*dto.Wallet, 0, len(wallets))
+	for _, wallet := range wallets {
+		result = append(result, toWalletDTO(wallet))
+	}
+
+	s.metrics.RecordWalletOperation("report_top_wallets", "success")
+
+	return result, nil
+}
@@ -0,0 +1,72 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewReportFilter(t *testing.T) {
+	currencies := config.CurrencyConfig{Default: "coins", Supported: []string{"coins", "gems"}}
+	maxRange := 90 * 24 * time.Hour
+	now := time.Date(2026, 3, 15, 18, 30, 0, 0, time.UTC)
+
+	t.Run("Defaults To Last 30 Days", func(t *testing.T) {
+		filter, err := newReportFilter(currencies, maxRange, &dto.ReportRequest{}, now)
+		require.NoError(t, err)
+		assert.Equal(t, "coins", filter.Currency)
+		assert.Equal(t, time.Date(2026, 2, 14, 0, 0, 0, 0, time.UTC), filter.From)
+		assert.Equal(t, time.Date(2026, 3, 16, 0, 0, 0, 0, time.UTC), filter.To)
+		assert.Nil(t, filter.GameID)
+		assert.Nil(t, filter.TokenType)
+	})
+
+	t.Run("Explicit Range And Fields", func(t *testing.T) {
+		filter, err := newReportFilter(currencies, maxRange, &dto.ReportRequest{
+			Currency:  "gems",
+			From:      "2026-01-01",
+			To:        "2026-02-01",
+			GameID:    "game-1",
+			TokenType: "gold",
+		}, now)
+		require.NoError(t, err)
+		assert.Equal(t, "gems", filter.Currency)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), filter.From)
+		assert.Equal(t, time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC), filter.To)
+		require.NotNil(t, filter.GameID)
+		assert.Equal(t, "game-1", *filter.GameID)
+		require.NotNil(t, filter.TokenType)
+		assert.Equal(t, "gold", *filter.TokenType)
+	})
+
+	t.Run("From Defaults Relative To To", func(t *testing.T) {
+		filter, err := newReportFilter(currencies, maxRange, &dto.ReportRequest{To: "2026-01-31"}, now)
+		require.NoError(t, err)
+		assert.Equal(t, time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC), filter.From)
+	})
+
+	testCases := []struct {
+		name        string
+		req         dto.ReportRequest
+		expectedErr error
+	}{
+		{name: "Invalid From", req: dto.ReportRequest{From: "2026-01-01T00:00:00Z"}, expectedErr: ErrInvalidReportRange},
+		{name: "Invalid To", req: dto.ReportRequest{To: "2026-13-01"}, expectedErr: ErrInvalidReportRange},
+		{name: "Empty Range", req: dto.ReportRequest{From: "2026-01-02", To: "2026-01-02"}, expectedErr: ErrInvalidReportRange},
+		{name: "Range Too Long", req: dto.ReportRequest{From: "2025-01-01", To: "2026-01-01"}, expectedErr: ErrInvalidReportRange},
+		{name: "Unsupported Currency", req: dto.ReportRequest{Currency: "gold"}, expectedErr: ErrUnsupportedCurrency},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := newReportFilter(currencies, maxRange, &tc.req, now)
+			assert.True(t, errors.Is(err, tc.expectedErr))
+		})
+	}
+}
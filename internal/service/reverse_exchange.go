@@ -0,0 +1,553 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/grant"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Reverse exchange cap names used in metrics
+const (
+	reverseExchangeCapPerTransaction = "reverse_per_transaction"
+	reverseExchangeCapUserDaily      = "reverse_user_daily"
+)
+
+// checkReverseExchangeCaps returns the name of the reverse cap an exchange of amount platform tokens would break
+// and its error. usedLastDay is the amount the user exchanged back into the game token in the last day.
+func checkReverseExchangeCaps(rate *model.ExchangeRate, usedLastDay, amount float64) (string, error) {
+	if rate.ReverseMaxPerTransaction != nil && amount > *rate.ReverseMaxPerTransaction {
+		return reverseExchangeCapPerTransaction, fmt.Errorf(
+			"%w: reverse amount %.2f is above %.2f for game_id=%s and token_type=%s",
+			ErrExchangeTransactionCapExceeded, amount, *rate.ReverseMaxPerTransaction, rate.GameID, rate.TokenType)
+	}
+
+	if rate.ReverseUserDailyCap != nil && roundCents(usedLastDay+amount) > *rate.ReverseUserDailyCap {
+		return reverseExchangeCapUserDaily, fmt.Errorf(
+			"%w: reverse remaining %.2f, required %.2f for game_id=%s and token_type=%s",
+			ErrExchangeUserDailyCapExceeded, remainingAllowance(*rate.ReverseUserDailyCap, usedLastDay),
+			amount, rate.GameID, rate.TokenType)
+	}
+
+	return "", nil
+}
+
+// enforceReverseExchangeCaps checks a reverse exchange against the reverse caps of its game token.
+// The caller must hold the wallet lock so concurrent reverse exchanges of the user are serialized.
+func (s *WalletService) enforceReverseExchangeCaps(ctx context.Context, userID int, rate *model.ExchangeRate,
+	amount float64, tx repository.Transaction) error {
+
+	if rate.ReverseMaxPerTransaction == nil && rate.ReverseUserDailyCap == nil {
+		return nil
+	}
+
+	var used float64
+	if rate.ReverseUserDailyCap != nil {
+		current, err := s.repo.GetReverseExchangeUsage(ctx, userID, rate.GameID, rate.TokenType, rate.Currency,
+			time.Now(), tx)
+		if err != nil {
+			return err
+		}
+		used = current
+	}
+
+	capName, err := checkReverseExchangeCaps(rate, used, amount)
+	if err != nil {
+		s.metrics.RecordExchangeCapHit(rate.GameID, rate.TokenType, capName)
+		return err
+	}
+
+	return nil
+}
+
+// toReverseExchangeGrantDTO converts a grant to its API representation, signing its token
+// unless it was already redeemed or refunded. The token only depends on the stored grant, so it can be
+// handed out again until the grant is redeemed.
+func (s *WalletService) toReverseExchangeGrantDTO(g *model.ReverseExchangeGrant) (*dto.ReverseExchangeGrant, error) {
+	result := &dto.ReverseExchangeGrant{
+		GrantID:        g.GrantID,
+		UserID:         g.UserID,
+		GameID:         g.GameID,
+		TokenType:      g.TokenType,
+		Currency:       g.Currency,
+		PlatformAmount: g.PlatformAmount,
+		GameAmount:     g.GameAmount,
+		ExpiresAt:      g.ExpiresAt,
+		RedeemedAt:     g.RedeemedAt,
+		RefundedAt:     g.RefundedAt,
+	}
+
+	if g.RedeemedAt == nil && g.RefundedAt == nil {
+		token, err := s.grants.Sign(grant.Claims{
+			GrantID:   g.GrantID,
+			UserID:    g.UserID,
+			GameID:    g.GameID,
+			TokenType: g.TokenType,
+			Amount:    g.GameAmount,
+			IssuedAt:  g.CreatedAt,
+			ExpiresAt: g.ExpiresAt,
+		})
+		if err != nil {
+			return nil, err
+		}
+		result.Token = token
+	}
+
+	return result, nil
+}
+
+// ReverseExchange debits platform tokens from the user's wallet and issues a signed grant of game tokens
+// at the rate's from_platform_ratio. The game server credits the game tokens when it redeems the grant.
+func (s *WalletService) ReverseExchange(ctx context.Context,
+	req *dto.ReverseExchangeRequest) (*dto.ReverseExchangeResult, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.ReverseExchange",
+		trace.WithAttributes(
+			attribute.String("game_id", req.GameID),
+			attribute.String("token_type", req.TokenType),
+			attribute.String("currency", req.Currency),
+			attribute.Float64("amount", req.Amount),
+			attribute.Int("user_id", req.UserID),
+		))
+	defer span.End()
+
+	s.logger.Info("Processing reverse exchange request",
+		zap.String("game_id", req.GameID),
+		zap.String("token_type", req.TokenType),
+		zap.String("currency", req.Currency),
+		zap.Float64("amount", req.Amount),
+		zap.Int("user_id", req.UserID))
+
+	if !s.reverseExchange.Enabled {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_disabled")
+		return nil, ErrReverseExchangeDisabled
+	}
+
+	currency, err := resolveCurrency(s.currencies, req.Currency)
+	if err != nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_unsupported_currency")
+		return nil, err
+	}
+	req.Currency = currency
+
+	rate, err := s.repo.GetExchangeRate(ctx, req.GameID, req.TokenType, currency)
+	if err != nil {
+		s.logger.Error("Error retrieving exchange rate",
+			zap.String("game_id", req.GameID),
+			zap.String("token_type", req.TokenType),
+			zap.String("currency", currency),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_db")
+		return nil, err
+	}
+
+	if rate == nil || rate.FromPlatformRatio == nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_rate_not_found")
+		return nil, fmt.Errorf("%w for game_id=%s, token_type=%s and currency=%s",
+			ErrReverseExchangeNotSupported, req.GameID, req.TokenType, currency)
+	}
+
+	gameAmount := roundCents(req.Amount * *rate.FromPlatformRatio)
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.logger.Error("Failed to begin transaction", zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, req.UserID, currency, tx)
+	if err != nil {
+		s.logger.Error("Error getting wallet for update",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_wallet_fetch")
+		return nil, err
+	}
+
+	if wallet == nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_wallet_not_found")
+		return nil, fmt.Errorf("%w for user_id=%d and currency=%s", ErrWalletNotFound, req.UserID, currency)
+	}
+
+	if err := checkWalletMutable(wallet); err != nil {
+		s.logger.Warn("Reverse exchange rejected by wallet status",
+			zap.Int("user_id", req.UserID),
+			zap.String("status", wallet.Status))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_wallet_"+wallet.Status)
+		return nil, err
+	}
+
+	// Expired tokens must not be exchanged back, even if the expiry job has not removed them yet
+	now := time.Now()
+	wallet, _, err = s.expireWalletLots(ctx, wallet, now, tx)
+	if err != nil {
+		s.logger.Error("Failed to expire balance lots",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_expire")
+		return nil, err
+	}
+
+	if err := s.enforceReverseExchangeCaps(ctx, req.UserID, rate, req.Amount, tx); err != nil {
+		if errors.Is(err, ErrExchangeCapExceeded) {
+			s.logger.Warn("Reverse exchange rejected by exchange cap",
+				zap.Int("user_id", req.UserID),
+				zap.String("game_id", req.GameID),
+				zap.String("token_type", req.TokenType),
+				zap.Float64("amount", req.Amount),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("reverse_exchange", "error_exchange_cap")
+			return nil, err
+		}
+
+		s.logger.Error("Error evaluating reverse exchange caps",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_exchange_cap_check")
+		return nil, err
+	}
+
+	if wallet.Balance < req.Amount {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_insufficient_funds")
+		return nil, fmt.Errorf("%w: current balance %.2f, required %.2f",
+			ErrInsufficientFunds, wallet.Balance, req.Amount)
+	}
+
+	// Submit the reverse exchange to the risk evaluator
+	riskOp := &model.RiskOperation{
+		Operation:       model.TransactionReverseExchange,
+		UserID:          req.UserID,
+		Currency:        currency,
+		Amount:          req.Amount,
+		PlatformAmount:  req.Amount,
+		GameID:          &req.GameID,
+		TokenType:       &req.TokenType,
+		Balance:         wallet.Balance,
+		WalletCreatedAt: &wallet.CreatedAt,
+	}
+
+	if err := s.evaluateRisk(ctx, riskOp, req); err != nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", riskErrorStatus(err))
+		return nil, err
+	}
+
+	updatedWallet, err := s.repo.SpendFromWallet(ctx, req.UserID, currency, req.Amount, tx)
+	if err != nil {
+		s.logger.Error("Failed to debit wallet",
+			zap.Int("user_id", req.UserID),
+			zap.Float64("amount", req.Amount),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_update_wallet")
+		return nil, err
+	}
+
+	if err := s.consumeBalanceLots(ctx, wallet, req.Amount, now, tx); err != nil {
+		s.logger.Error("Failed to consume balance lots",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_lot")
+		return nil, err
+	}
+
+	grantID, err := grant.NewGrantID()
+	if err != nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_grant")
+		return nil, err
+	}
+
+	_, err = s.repo.CreateWalletLog(ctx, &model.WalletLog{
+		WalletID:       wallet.ID,
+		UserID:         req.UserID,
+		Currency:       currency,
+		GameID:         &req.GameID,
+		TokenType:      &req.TokenType,
+		Amount:         gameAmount,
+		PlatformAmount: -req.Amount,
+		Source:         model.TransactionReverseExchange,
+		ReferenceID:    &grantID,
+	}, tx)
+	if err != nil {
+		s.logger.Error("Failed to create wallet log",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_log")
+		return nil, err
+	}
+
+	created, err := s.repo.CreateReverseExchangeGrant(ctx, &model.ReverseExchangeGrant{
+		GrantID:        grantID,
+		WalletID:       wallet.ID,
+		UserID:         req.UserID,
+		GameID:         req.GameID,
+		TokenType:      req.TokenType,
+		Currency:       currency,
+		PlatformAmount: req.Amount,
+		GameAmount:     gameAmount,
+		ExpiresAt:      now.Add(s.reverseExchange.GrantTTL),
+	}, tx)
+	if err != nil {
+		s.logger.Error("Failed to create reverse exchange grant",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_grant")
+		return nil, err
+	}
+
+	// Sign the stored grant so the token matches the one GetReverseExchangeGrants hands out later
+	result, err := s.toReverseExchangeGrantDTO(created)
+	if err != nil {
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_sign")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.logger.Error("Failed to commit transaction",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("reverse_exchange", "error_commit")
+		return nil, err
+	}
+
+	s.logger.Info("Reverse exchange completed successfully",
+		zap.Int("user_id", req.UserID),
+		zap.String("currency", currency),
+		zap.Float64("platform_amount", req.Amount),
+		zap.Float64("game_amount", gameAmount),
+		zap.String("grant_id", grantID),
+		zap.Float64("new_balance", updatedWallet.Balance))
+	s.metrics.RecordWalletOperation("reverse_exchange", "success")
+
+	return &dto.ReverseExchangeResult{NewBalance: updatedWallet.Balance, Grant: result}, nil
+}
+
+// RedeemReverseExchangeGrant verifies a grant token and marks its grant redeemed.
+// Each grant can be redeemed once, before it expires, by a game server of the grant's game or an admin;
+// the game server credits the game tokens on success.
+func (s *WalletService) RedeemReverseExchangeGrant(ctx context.Context, token string,
+	redeemedBy int) (*dto.ReverseExchangeGrant, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.RedeemReverseExchangeGrant",
+		trace.WithAttributes(attribute.Int("redeemed_by", redeemedBy)))
+	defer span.End()
+
+	now := time.Now()
+	claims, err := s.grants.Verify(token, now)
+	if err != nil {
+		if errors.Is(err, grant.ErrTokenExpired) {
+			s.metrics.RecordWalletOperation("redeem_grant", "error_expired")
+			return nil, fmt.Errorf("%w: grant_id=%s", ErrGrantExpired, claims.GrantID)
+		}
+		s.metrics.RecordWalletOperation("redeem_grant", "error_invalid")
+		return nil, fmt.Errorf("%w: %v", ErrInvalidGrant, err)
+	}
+
+	// A game server may only redeem the grants of its own game
+	principal, _ := model.PrincipalFromContext(ctx)
+	if principal.Role != principalRoleAdmin && principal.GameID != claims.GameID {
+		s.logger.Warn("Grant redemption by another game rejected",
+			zap.String("grant_id", claims.GrantID),
+			zap.String("grant_game_id", claims.GameID),
+			zap.String("caller_game_id", principal.GameID),
+			zap.Int("redeemed_by", redeemedBy))
+		s.metrics.RecordWalletOperation("redeem_grant", "error_game_mismatch")
+		return nil, fmt.Errorf("%w: grant_id=%s", ErrGrantGameMismatch, claims.GrantID)
+	}
+
+	redeemed, err := s.repo.RedeemReverseExchangeGrant(ctx, claims.GrantID, redeemedBy, now)
+	if err != nil {
+		s.metrics.RecordWalletOperation("redeem_grant", "error_db")
+		return nil, err
+	}
+
+	if redeemed == nil {
+		existing, err := s.repo.GetReverseExchangeGrant(ctx, claims.GrantID)
+		if err != nil {
+			s.metrics.RecordWalletOperation("redeem_grant", "error_db")
+			return nil, err
+		}
+
+		switch {
+		case existing == nil:
+			s.metrics.RecordWalletOperation("redeem_grant", "error_not_found")
+			return nil, fmt.Errorf("%w: grant_id=%s", ErrGrantNotFound, claims.GrantID)
+		case existing.RedeemedAt != nil:
+			s.metrics.RecordWalletOperation("redeem_grant", "error_already_redeemed")
+			return nil, fmt.Errorf("%w: grant_id=%s at %s", ErrGrantAlreadyRedeemed, claims.GrantID,
+				existing.RedeemedAt.Format(time.RFC3339))
+		default:
+			s.metrics.RecordWalletOperation("redeem_grant", "error_expired")
+			return nil, fmt.Errorf("%w: grant_id=%s", ErrGrantExpired, claims.GrantID)
+		}
+	}
+
+	s.logger.Info("Reverse exchange grant redeemed",
+		zap.String("grant_id", redeemed.GrantID),
+		zap.Int("user_id", redeemed.UserID),
+		zap.String("game_id", redeemed.GameID),
+		zap.Float64("game_amount", redeemed.GameAmount),
+		zap.Int("redeemed_by", redeemedBy))
+	s.metrics.RecordWalletOperation("redeem_grant", "success")
+
+	return s.toReverseExchangeGrantDTO(redeemed)
+}
+
+// GetReverseExchangeGrants retrieves the open grants of a user with their tokens, so a grant
+// issued after a manual review or lost by the client can still be handed to the game server
+func (s *WalletService) GetReverseExchangeGrants(ctx context.Context, userID int) ([]dto.ReverseExchangeGrant, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GetReverseExchangeGrants",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	grants, err := s.repo.ListOpenReverseExchangeGrants(ctx, userID, time.Now())
+	if err != nil {
+		s.metrics.RecordWalletOperation("get_grants", "error")
+		return nil, err
+	}
+
+	result := make([]dto.ReverseExchangeGrant, 0, len(grants))
+	for _, g := range grants {
+		converted, err := s.toReverseExchangeGrantDTO(g)
+		if err != nil {
+			s.metrics.RecordWalletOperation("get_grants", "error_sign")
+			return nil, err
+		}
+		result = append(result, *converted)
+	}
+
+	s.metrics.RecordWalletOperation("get_grants", "success")
+
+	return result, nil
+}
+
+// RefundExpiredReverseExchangeGrants credits the platform tokens of every grant that expired unredeemed
+// back to its wallet. Each refund writes a reverse_exchange_refund log and a balance lot and marks the
+// grant refunded in a transaction of its own, so a grant is refunded once and can no longer be redeemed.
+func (s *WalletService) RefundExpiredReverseExchangeGrants(ctx context.Context) (*dto.GrantRefundReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.RefundExpiredReverseExchangeGrants")
+	defer span.End()
+
+	now := time.Now()
+	report := &dto.GrantRefundReport{}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		grants, err := s.repo.ListRefundableReverseExchangeGrants(ctx, now, s.expiry.BatchSize)
+		if err != nil {
+			s.metrics.RecordWalletOperation("refund_grant", "error_list")
+			return report, err
+		}
+
+		for _, g := range grants {
+			refunded, err := s.refundReverseExchangeGrant(ctx, g, now)
+			if err != nil {
+				s.logger.Error("Failed to refund reverse exchange grant",
+					zap.String("grant_id", g.GrantID),
+					zap.Int("user_id", g.UserID),
+					zap.Error(err))
+				s.metrics.RecordWalletOperation("refund_grant", "error")
+				return report, err
+			}
+
+			if refunded == nil {
+				continue
+			}
+
+			report.GrantsRefunded++
+			report.AmountRefunded += refunded.PlatformAmount
+		}
+
+		// Refunded grants drop out of the query, so a short page means nothing is left
+		if len(grants) == 0 || len(grants) < s.expiry.BatchSize {
+			break
+		}
+	}
+
+	report.AmountRefunded = roundCents(report.AmountRefunded)
+	s.metrics.RecordWalletOperation("refund_grant", "success")
+
+	return report, nil
+}
+
+// refundReverseExchangeGrant locks the wallet of an expired grant and credits its platform tokens back
+// in a transaction of its own. It returns nil when the grant was redeemed or refunded in the meantime.
+func (s *WalletService) refundReverseExchangeGrant(ctx context.Context, g *model.ReverseExchangeGrant,
+	now time.Time) (*model.ReverseExchangeGrant, error) {
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, g.UserID, g.Currency, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("%w: user_id=%d currency=%s", ErrWalletNotFound, g.UserID, g.Currency)
+	}
+
+	refunded, err := s.repo.RefundReverseExchangeGrant(ctx, g.GrantID, now, tx)
+	if err != nil || refunded == nil {
+		return nil, err
+	}
+
+	updatedWallet, err := s.repo.UpdateWalletBalance(ctx, g.UserID, g.Currency,
+		roundCents(wallet.Balance+refunded.PlatformAmount), tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The refunded tokens are held in a lot of their own that never expires, like exchanged tokens
+	_, err = s.repo.CreateBalanceLot(ctx, &model.BalanceLot{
+		WalletID:    wallet.ID,
+		Amount:      refunded.PlatformAmount,
+		Source:      model.TransactionReverseExchangeRefund,
+		ReferenceID: &refunded.GrantID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.repo.CreateWalletLog(ctx, &model.WalletLog{
+		WalletID:       wallet.ID,
+		UserID:         g.UserID,
+		Currency:       g.Currency,
+		GameID:         &refunded.GameID,
+		TokenType:      &refunded.TokenType,
+		Amount:         -refunded.GameAmount,
+		PlatformAmount: refunded.PlatformAmount,
+		Source:         model.TransactionReverseExchangeRefund,
+		ReferenceID:    &refunded.GrantID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	s.logger.Info("Reverse exchange grant refunded",
+		zap.String("grant_id", refunded.GrantID),
+		zap.Int("user_id", refunded.UserID),
+		zap.Float64("platform_amount", refunded.PlatformAmount),
+		zap.Float64("new_balance", updatedWallet.Balance))
+
+	return refunded, nil
+}
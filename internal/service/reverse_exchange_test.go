@@ -0,0 +1,178 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/grant"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestRefundExpiredReverseExchangeGrants(t *testing.T) {
+	newService := func() (*mockRepository, *WalletService) {
+		mockRepo := new(mockRepository)
+		cfg := &config.Config{Expiry: config.ExpiryConfig{BatchSize: 10}}
+		return mockRepo, NewWalletService(mockRepo, nil, nil, nil, cfg, observability.NewTestObservability())
+	}
+
+	expired := func(grantID string) *model.ReverseExchangeGrant {
+		return &model.ReverseExchangeGrant{
+			GrantID:        grantID,
+			WalletID:       1,
+			UserID:         123,
+			GameID:         "game1",
+			TokenType:      "gold",
+			Currency:       model.CurrencyPlatform,
+			PlatformAmount: 15,
+			GameAmount:     30,
+			ExpiresAt:      time.Now().Add(-time.Minute),
+		}
+	}
+
+	wallet := &model.Wallet{ID: 1, UserID: 123, Currency: model.CurrencyPlatform, Balance: 5}
+
+	t.Run("Credits The Platform Tokens Back", func(t *testing.T) {
+		mockRepo, svc := newService()
+		mockTx := new(mockTransaction)
+		grant := expired("grant-1")
+		refunded := expired("grant-1")
+		refundedAt := time.Now()
+		refunded.RefundedAt = &refundedAt
+
+		mockRepo.On("ListRefundableReverseExchangeGrants", mock.Anything, mock.Anything, 10).
+			Return([]*model.ReverseExchangeGrant{grant}, nil).Once()
+		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, 123, model.CurrencyPlatform, mockTx).
+			Return(wallet, nil).Once()
+		mockRepo.On("RefundReverseExchangeGrant", mock.Anything, "grant-1", mock.Anything, mockTx).
+			Return(refunded, nil).Once()
+		mockRepo.On("UpdateWalletBalance", mock.Anything, 123, model.CurrencyPlatform, 20.0, mockTx).
+			Return(&model.Wallet{ID: 1, UserID: 123, Currency: model.CurrencyPlatform, Balance: 20}, nil).Once()
+		mockRepo.On("CreateBalanceLot", mock.Anything, mock.MatchedBy(func(lot *model.BalanceLot) bool {
+			return lot.Amount == 15 && lot.Source == model.TransactionReverseExchangeRefund &&
+				*lot.ReferenceID == "grant-1" && lot.ExpiresAt == nil
+		}), mockTx).Return(&model.BalanceLot{ID: 1}, nil).Once()
+		mockRepo.On("CreateWalletLog", mock.Anything, mock.MatchedBy(func(log *model.WalletLog) bool {
+			return log.PlatformAmount == 15 && log.Amount == -30 &&
+				log.Source == model.TransactionReverseExchangeRefund && *log.ReferenceID == "grant-1"
+		}), mockTx).Return(&model.WalletLog{ID: 1}, nil).Once()
+		mockTx.On("Commit").Return(nil).Once()
+		mockTx.On("Rollback").Return(nil).Maybe()
+
+		report, err := svc.RefundExpiredReverseExchangeGrants(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.GrantsRefunded)
+		assert.Equal(t, 15.0, report.AmountRefunded)
+		mockRepo.AssertExpectations(t)
+		mockTx.AssertExpectations(t)
+	})
+
+	t.Run("Skips A Grant Redeemed Or Refunded Meanwhile", func(t *testing.T) {
+		mockRepo, svc := newService()
+		mockTx := new(mockTransaction)
+
+		mockRepo.On("ListRefundableReverseExchangeGrants", mock.Anything, mock.Anything, 10).
+			Return([]*model.ReverseExchangeGrant{expired("grant-2")}, nil).Once()
+		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, 123, model.CurrencyPlatform, mockTx).
+			Return(wallet, nil).Once()
+		mockRepo.On("RefundReverseExchangeGrant", mock.Anything, "grant-2", mock.Anything, mockTx).
+			Return(nil, nil).Once()
+		mockTx.On("Rollback").Return(nil).Once()
+
+		report, err := svc.RefundExpiredReverseExchangeGrants(context.Background())
+
+		assert.NoError(t, err)
+		assert.Equal(t, 0, report.GrantsRefunded)
+		mockRepo.AssertNotCalled(t, "UpdateWalletBalance")
+		mockRepo.AssertNotCalled(t, "CreateWalletLog")
+		mockRepo.AssertExpectations(t)
+		mockTx.AssertExpectations(t)
+	})
+}
+
+func TestRedeemReverseExchangeGrantChecksGame(t *testing.T) {
+	const signingKey = "0123456789abcdef0123456789abcdef"
+
+	mockRepo := new(mockRepository)
+	cfg := &config.Config{ReverseExchange: config.ReverseExchangeConfig{SigningKey: signingKey}}
+	svc := NewWalletService(mockRepo, nil, nil, nil, cfg, observability.NewTestObservability())
+
+	now := time.Now()
+	token, err := grant.NewSigner(signingKey).Sign(grant.Claims{
+		GrantID:   "grant-1",
+		UserID:    123,
+		GameID:    "game1",
+		TokenType: "gold",
+		Amount:    30,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(time.Minute),
+	})
+	assert.NoError(t, err)
+
+	redeemed := &model.ReverseExchangeGrant{
+		GrantID:    "grant-1",
+		UserID:     123,
+		GameID:     "game1",
+		TokenType:  "gold",
+		GameAmount: 30,
+		ExpiresAt:  now.Add(time.Minute),
+		RedeemedAt: &now,
+	}
+
+	testCases := []struct {
+		name          string
+		principal     model.Principal
+		expectedError error
+	}{
+		{
+			name:      "Game Server Of The Grant's Game",
+			principal: model.Principal{UserID: 900, Role: "game_server", GameID: "game1"},
+		},
+		{
+			name:      "Admin",
+			principal: model.Principal{UserID: 1, Role: "admin"},
+		},
+		{
+			name:          "Game Server Of Another Game",
+			principal:     model.Principal{UserID: 901, Role: "game_server", GameID: "game2"},
+			expectedError: ErrGrantGameMismatch,
+		},
+		{
+			name:          "Game Server Without A Game",
+			principal:     model.Principal{UserID: 902, Role: "game_server"},
+			expectedError: ErrGrantGameMismatch,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if tc.expectedError == nil {
+				mockRepo.On("RedeemReverseExchangeGrant", mock.Anything, "grant-1", tc.principal.UserID, mock.Anything).
+					Return(redeemed, nil).Once()
+			}
+
+			result, err := svc.RedeemReverseExchangeGrant(model.WithPrincipal(context.Background(), tc.principal),
+				token, tc.principal.UserID)
+
+			if tc.expectedError != nil {
+				assert.ErrorIs(t, err, tc.expectedError)
+				assert.Nil(t, result)
+				mockRepo.AssertNotCalled(t, "RedeemReverseExchangeGrant", mock.Anything, "grant-1",
+					tc.principal.UserID, mock.Anything)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "grant-1", result.GrantID)
+			mockRepo.AssertExpectations(t)
+		})
+	}
+}
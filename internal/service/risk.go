@@ -0,0 +1,137 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/risk"
+
+	"go.uber.org/zap"
+)
+
+// principalRoleSystem identifies operations started outside an HTTP request, such as CLI commands
+const principalRoleSystem = "system"
+
+// principalRoleAdmin is the role of administrators, who may act on any user or game
+const principalRoleAdmin = "admin"
+
+// NewRiskEvaluator loads the built-in rule engine from the configured rules file.
+// It returns a nil evaluator when risk evaluation is disabled.
+func NewRiskEvaluator(cfg *config.Config, obs *observability.Observability) (RiskEvaluator, error) {
+	logger := obs.Logger.Logger.With(zap.String("component", "risk_evaluator"))
+
+	if !cfg.Risk.Enabled {
+		logger.Info("Risk evaluation disabled")
+		return nil, nil
+	}
+
+	engine, err := risk.LoadRuleEngine(cfg.Risk.RulesFile)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Info("Risk rules loaded",
+		zap.String("file", cfg.Risk.RulesFile),
+		zap.Int("rules", len(engine.Rules())))
+
+	return engine, nil
+}
+
+type skipRiskContextKey struct{}
+
+// withoutRiskEvaluation marks ctx so that operations replayed after a manual approval are not held again
+func withoutRiskEvaluation(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipRiskContextKey{}, true)
+}
+
+func riskEvaluationSkipped(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipRiskContextKey{}).(bool)
+	return skip
+}
+
+// evaluateRisk submits an operation to the risk evaluator. A denied operation returns ErrOperationDenied;
+// an operation to review is stored with its request payload and returns ErrOperationUnderReview.
+func (s *WalletService) evaluateRisk(ctx context.Context, op *model.RiskOperation, payload interface{}) error {
+	if s.risk == nil || riskEvaluationSkipped(ctx) {
+		return nil
+	}
+
+	principal, ok := model.PrincipalFromContext(ctx)
+	if !ok {
+		principal = model.Principal{Role: principalRoleSystem}
+	}
+	op.Principal = principal
+	op.Now = time.Now()
+
+	history, err := s.repo.GetWalletLogs(ctx, op.UserID, s.riskHistorySize, 0)
+	if err != nil {
+		return err
+	}
+	op.RecentHistory = history
+
+	assessment, err := s.risk.Evaluate(ctx, op)
+	if err != nil {
+		return fmt.Errorf("evaluate risk: %w", err)
+	}
+
+	s.metrics.RecordRiskDecision(op.Operation, assessment.Decision)
+	matchedRules := strings.Join(assessment.MatchedRules, ",")
+
+	switch assessment.Decision {
+	case model.RiskDecisionDeny:
+		s.logger.Warn("Operation denied by risk evaluation",
+			zap.String("operation", op.Operation),
+			zap.Int("user_id", op.UserID),
+			zap.Int("principal_id", principal.UserID),
+			zap.String("matched_rules", matchedRules))
+		return fmt.Errorf("%w: matched rules %s", ErrOperationDenied, matchedRules)
+
+	case model.RiskDecisionReview:
+		data, err := json.Marshal(payload)
+		if err != nil {
+			return fmt.Errorf("encode review payload: %w", err)
+		}
+
+		review, err := s.reviews.CreateRiskReview(ctx, &model.RiskReview{
+			Operation:      op.Operation,
+			UserID:         op.UserID,
+			PrincipalID:    principal.UserID,
+			PrincipalRole:  principal.Role,
+			Amount:         op.Amount,
+			PlatformAmount: op.PlatformAmount,
+			MatchedRules:   matchedRules,
+			Payload:        data,
+		})
+		if err != nil {
+			return err
+		}
+
+		s.logger.Warn("Operation placed under manual review",
+			zap.String("operation", op.Operation),
+			zap.Int("user_id", op.UserID),
+			zap.Int64("review_id", review.ID),
+			zap.String("matched_rules", matchedRules))
+		return fmt.Errorf("%w: review_id=%d", ErrOperationUnderReview, review.ID)
+	}
+
+	return nil
+}
+
+// riskErrorStatus maps the error of a risk evaluation to a wallet operation metric status
+func riskErrorStatus(err error) string {
+	switch {
+	case errors.Is(err, ErrOperationDenied):
+		return "error_risk_denied"
+	case errors.Is(err, ErrOperationUnderReview):
+		return "risk_review"
+	default:
+		return "error_risk_evaluation"
+	}
+}
@@ -0,0 +1,195 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// RiskReviewService manages operations held for manual review by the risk evaluator
+type RiskReviewService struct {
+	repo          repository.RiskReviewRepository
+	walletService WalletServiceInterface
+	logger        *zap.Logger
+	metrics       *metrics.Metrics
+	tracer        *tracing.Tracer
+}
+
+// Compile-time verification that RiskReviewService implements RiskReviewServiceInterface
+var _ RiskReviewServiceInterface = (*RiskReviewService)(nil)
+
+// NewRiskReviewService creates a new risk review service
+func NewRiskReviewService(repo repository.RiskReviewRepository, walletService WalletServiceInterface,
+	obs *observability.Observability) *RiskReviewService {
+	return &RiskReviewService{
+		repo:          repo,
+		walletService: walletService,
+		logger:        obs.Logger.Logger.With(zap.String("component", "risk_review_service")),
+		metrics:       obs.Metrics,
+		tracer:        obs.Tracer,
+	}
+}
+
+// ListReviews retrieves reviews with the given status, oldest first
+func (s *RiskReviewService) ListReviews(ctx context.Context, status string, limit, offset int) ([]dto.RiskReview, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "RiskReviewService.ListReviews",
+		trace.WithAttributes(attribute.String("status", status)))
+	defer span.End()
+
+	reviews, err := s.repo.ListRiskReviews(ctx, status, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]dto.RiskReview, len(reviews))
+	for i, review := range reviews {
+		result[i] = *toRiskReviewDTO(review)
+	}
+
+	return result, nil
+}
+
+// ApproveReview approves a pending review and executes the held operation without evaluating it again.
+// When the operation fails, for example because funds ran out meanwhile, the review is marked as failed.
+func (s *RiskReviewService) ApproveReview(ctx context.Context, reviewID int64, reviewedBy int,
+	req *dto.DecideRiskReviewRequest) (*dto.RiskReview, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "RiskReviewService.ApproveReview",
+		trace.WithAttributes(attribute.Int64("review_id", reviewID)))
+	defer span.End()
+
+	review, err := s.repo.DecideRiskReview(ctx, reviewID, model.RiskReviewApproved, reviewedBy, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil {
+		return nil, fmt.Errorf("%w: review_id=%d", ErrRiskReviewNotFound, reviewID)
+	}
+
+	execCtx := withoutRiskEvaluation(model.WithPrincipal(ctx, model.Principal{
+		UserID: review.PrincipalID,
+		Role:   review.PrincipalRole,
+	}))
+
+	newBalance, execErr := s.execute(execCtx, review)
+	if execErr != nil {
+		s.logger.Warn("Approved operation failed",
+			zap.Int64("review_id", reviewID),
+			zap.String("operation", review.Operation),
+			zap.Error(execErr))
+		s.metrics.RecordWalletOperation("risk_review_approve", "error_execute")
+
+		failed, err := s.repo.FailRiskReview(ctx, reviewID, execErr.Error())
+		if err != nil {
+			return nil, err
+		}
+		return toRiskReviewDTO(failed), nil
+	}
+
+	s.logger.Info("Risk review approved",
+		zap.Int64("review_id", reviewID),
+		zap.String("operation", review.Operation),
+		zap.Int("reviewed_by", reviewedBy))
+	s.metrics.RecordWalletOperation("risk_review_approve", "success")
+
+	result := toRiskReviewDTO(review)
+	result.NewBalance = &newBalance
+	return result, nil
+}
+
+// RejectReview rejects a pending review; the held operation is never executed
+func (s *RiskReviewService) RejectReview(ctx context.Context, reviewID int64, reviewedBy int,
+	req *dto.DecideRiskReviewRequest) (*dto.RiskReview, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "RiskReviewService.RejectReview",
+		trace.WithAttributes(attribute.Int64("review_id", reviewID)))
+	defer span.End()
+
+	review, err := s.repo.DecideRiskReview(ctx, reviewID, model.RiskReviewRejected, reviewedBy, req.Note)
+	if err != nil {
+		return nil, err
+	}
+
+	if review == nil {
+		return nil, fmt.Errorf("%w: review_id=%d", ErrRiskReviewNotFound, reviewID)
+	}
+
+	s.logger.Info("Risk review rejected",
+		zap.Int64("review_id", reviewID),
+		zap.String("operation", review.Operation),
+		zap.Int("reviewed_by", reviewedBy))
+	s.metrics.RecordWalletOperation("risk_review_reject", "success")
+
+	return toRiskReviewDTO(review), nil
+}
+
+// execute replays the request stored with a review
+func (s *RiskReviewService) execute(ctx context.Context, review *model.RiskReview) (float64, error) {
+	switch review.Operation {
+	case model.TransactionExchange:
+		var req dto.ExchangeRequest
+		if err := json.Unmarshal(review.Payload, &req); err != nil {
+			return 0, fmt.Errorf("decode review payload: %w", err)
+		}
+		return s.walletService.Exchange(ctx, &req)
+
+	case model.TransactionSpend:
+		var req dto.SpendRequest
+		if err := json.Unmarshal(review.Payload, &req); err != nil {
+			return 0, fmt.Errorf("decode review payload: %w", err)
+		}
+		return s.walletService.Spend(ctx, &req)
+
+	case model.TransactionReverseExchange:
+		var req dto.ReverseExchangeRequest
+		if err := json.Unmarshal(review.Payload, &req); err != nil {
+			return 0, fmt.Errorf("decode review payload: %w", err)
+		}
+		// The grant is listed by GET /:user_id/reverse-grants until it is redeemed
+		result, err := s.walletService.ReverseExchange(ctx, &req)
+		if err != nil {
+			return 0, err
+		}
+		return result.NewBalance, nil
+	}
+
+	return 0, fmt.Errorf("unsupported operation %q", review.Operation)
+}
+
+func toRiskReviewDTO(review *model.RiskReview) *dto.RiskReview {
+	var matchedRules []string
+	if review.MatchedRules != "" {
+		matchedRules = strings.Split(review.MatchedRules, ",")
+	}
+
+	return &dto.RiskReview{
+		ID:             review.ID,
+		Operation:      review.Operation,
+		UserID:         review.UserID,
+		PrincipalID:    review.PrincipalID,
+		PrincipalRole:  review.PrincipalRole,
+		Amount:         review.Amount,
+		PlatformAmount: review.PlatformAmount,
+		MatchedRules:   matchedRules,
+		Payload:        review.Payload,
+		Status:         review.Status,
+		ReviewedBy:     review.ReviewedBy,
+		ReviewedAt:     review.ReviewedAt,
+		ReviewNote:     review.ReviewNote,
+		ResultError:    review.ResultError,
+		CreatedAt:      review.CreatedAt,
+	}
+}
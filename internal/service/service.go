@@ -2,8 +2,13 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sync/atomic"
+	"time"
 
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/grant"
 	"github.com/playconomy/wallet-service/internal/model"
 	"github.com/playconomy/wallet-service/internal/observability"
 	"github.com/playconomy/wallet-service/internal/observability/metrics"
@@ -17,43 +22,111 @@ import (
 	"go.uber.org/zap"
 )
 
-// Module provides service dependencies
+// Module provides the business services and job workers; module.CoreModule includes it
 var Module = fx.Options(
 	fx.Provide(NewWalletService),
 	// Provide interface implementation for dependency injection
 	fx.Provide(func(s *WalletService) WalletServiceInterface { return s }),
+	fx.Provide(NewReconciliationService),
+	fx.Provide(func(s *ReconciliationService) ReconciliationServiceInterface { return s }),
+	fx.Provide(NewRiskEvaluator),
+	fx.Provide(NewRiskReviewService),
+	fx.Provide(func(s *RiskReviewService) RiskReviewServiceInterface { return s }),
+	fx.Provide(NewBulkService),
+	fx.Provide(func(s *BulkService) BulkServiceInterface { return s }),
+	fx.Provide(NewJobService),
+	fx.Provide(func(s *JobService) JobServiceInterface { return s }),
+	fx.Provide(NewExportService),
+	fx.Provide(func(s *ExportService) ExportServiceInterface { return s }),
+	fx.Provide(NewStatementService),
+	fx.Provide(func(s *StatementService) StatementServiceInterface { return s }),
+	fx.Provide(NewSnapshotService),
+	fx.Provide(func(s *SnapshotService) SnapshotServiceInterface { return s }),
+	fx.Provide(NewReportService),
+	fx.Provide(func(s *ReportService) ReportServiceInterface { return s }),
+	fx.Provide(NewWalletEventBroker),
+	fx.Provide(NewStreamService),
+	fx.Provide(func(s *StreamService) StreamServiceInterface { return s }),
+	fx.Provide(NewAuditService),
+	fx.Provide(func(s *AuditService) AuditServiceInterface { return s }),
+	fx.Provide(NewFeatureFlagService),
+	fx.Provide(func(s *FeatureFlagService) FeatureFlagServiceInterface { return s }),
+	fx.Provide(NewRateLimitService),
+	fx.Provide(func(s *RateLimitService) RateLimitServiceInterface { return s }),
+	fx.Provide(NewPartitionService),
+	fx.Provide(func(s *PartitionService) PartitionServiceInterface { return s }),
+	// Job workers, run by the JobRunner
+	fx.Provide(fx.Annotate(NewBulkJobWorker, fx.ResultTags(`group:"job_workers"`))),
 )
 
 type WalletService struct {
-	repo    repository.WalletRepository
-	logger  *zap.Logger
-	metrics *metrics.Metrics
-	tracer  *tracing.Tracer
+	repo            repository.WalletRepository
+	reviews         repository.RiskReviewRepository
+	risk            RiskEvaluator
+	flags           *FeatureFlagService
+	riskHistorySize int
+	spendLimits     atomic.Pointer[config.SpendLimitsConfig]
+	currencies      config.CurrencyConfig
+	expiry          config.ExpiryConfig
+	reverseExchange config.ReverseExchangeConfig
+	grants          *grant.Signer
+	logger          *zap.Logger
+	metrics         *metrics.Metrics
+	tracer          *tracing.Tracer
 }
 
 // Compile-time verification that WalletService implements WalletServiceInterface
 var _ WalletServiceInterface = (*WalletService)(nil)
 
 // Constructors for fx dependency injection
-func NewWalletService(repo repository.WalletRepository, obs *observability.Observability) *WalletService {
-	return &WalletService{
-		repo:    repo,
-		logger:  obs.Logger.Logger,
-		metrics: obs.Metrics,
-		tracer:  obs.Tracer,
+// A nil risk evaluator disables risk evaluation; without feature flags every operation is allowed.
+func NewWalletService(repo repository.WalletRepository, reviews repository.RiskReviewRepository,
+	riskEvaluator RiskEvaluator, flags *FeatureFlagService, cfg *config.Config,
+	obs *observability.Observability) *WalletService {
+	s := &WalletService{
+		repo:            repo,
+		reviews:         reviews,
+		risk:            riskEvaluator,
+		flags:           flags,
+		riskHistorySize: cfg.Risk.HistorySize,
+		currencies:      currencySettings(cfg.Currency),
+		expiry:          cfg.Expiry,
+		reverseExchange: cfg.ReverseExchange,
+		grants:          grant.NewSigner(cfg.ReverseExchange.SigningKey),
+		logger:          obs.Logger.Logger,
+		metrics:         obs.Metrics,
+		tracer:          obs.Tracer,
 	}
+	s.SetSpendLimits(cfg.SpendLimits)
+	return s
 }
 
-func (s *WalletService) GetWalletByUserID(ctx context.Context, userID int) (*dto.Wallet, error) {
+// SetSpendLimits replaces the default spend limits applied to users without an override
+func (s *WalletService) SetSpendLimits(limits config.SpendLimitsConfig) {
+	s.spendLimits.Store(&limits)
+}
+
+// GetWalletByUserID retrieves a user's wallet in the given currency, or the default one when currency is empty,
+// together with the balances of all currencies the user holds and the wallet's upcoming expirations
+func (s *WalletService) GetWalletByUserID(ctx context.Context, userID int, currency string) (*dto.Wallet, error) {
 	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GetWalletByUserID",
-		trace.WithAttributes(attribute.Int("user_id", userID)))
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
+		))
 	defer span.End()
 
-	s.logger.Info("Getting wallet for user", zap.Int("user_id", userID))
-	
-	// Get wallet from repository
-	wallet, err := s.repo.GetWalletByUserID(ctx, userID)
-	
+	s.logger.Info("Getting wallet for user",
+		zap.Int("user_id", userID),
+		zap.String("currency", currency))
+
+	currency, err := resolveCurrency(s.currencies, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all currency wallets of the user from repository
+	wallets, err := s.repo.GetWalletsByUserID(ctx, userID)
 	if err != nil {
 		s.logger.Error("Error retrieving wallet", 
 			zap.Int("user_id", userID),
@@ -61,29 +134,51 @@ func (s *WalletService) GetWalletByUserID(ctx context.Context, userID int) (*dto
 		return nil, err
 	}
 
+	var wallet *model.Wallet
+	balances := make([]dto.CurrencyBalance, len(wallets))
+	for i, w := range wallets {
+		balances[i] = dto.CurrencyBalance{Currency: w.Currency, Balance: w.Balance}
+		if w.Currency == currency {
+			wallet = w
+		}
+	}
+
 	if wallet == nil {
-		s.logger.Info("Wallet not found for user", zap.Int("user_id", userID))
+		s.logger.Info("Wallet not found for user",
+			zap.Int("user_id", userID),
+			zap.String("currency", currency))
 		return nil, nil
 	}
 
 	s.logger.Debug("Retrieved wallet successfully", 
 		zap.Int("user_id", userID),
+		zap.String("currency", currency),
 		zap.Float64("balance", wallet.Balance))
 
+	now := time.Now()
+	expiring, err := s.repo.ListExpiringBalanceLots(ctx, wallet.ID, now, now.Add(s.expiry.Lookahead))
+	if err != nil {
+		s.logger.Error("Error retrieving upcoming expirations",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, err
+	}
+
 	// Convert model to DTO
-	return &dto.Wallet{
-		ID:        int(wallet.ID),
-		UserID:    wallet.UserID,
-		Balance:   wallet.Balance,
-		CreatedAt: wallet.CreatedAt,
-	}, nil
+	result := toWalletDTO(wallet)
+	result.Balances = balances
+	result.UpcomingExpirations = toBalanceExpirations(expiring)
+	return result, nil
 }
 
+// Exchange converts game tokens into the requested currency, or the default one when the request names none.
+// req.Currency is set to the currency the exchange applies to.
 func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest) (float64, error) {
 	ctx, span := s.tracer.StartSpan(ctx, "WalletService.Exchange", 
 		trace.WithAttributes(
 			attribute.String("game_id", req.GameID),
 			attribute.String("token_type", req.TokenType),
+			attribute.String("currency", req.Currency),
 			attribute.Float64("amount", req.Amount),
 			attribute.Int("user_id", req.UserID),
 		))
@@ -92,15 +187,24 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 	s.logger.Info("Processing exchange request", 
 		zap.String("game_id", req.GameID),
 		zap.String("token_type", req.TokenType),
+		zap.String("currency", req.Currency),
 		zap.Float64("amount", req.Amount),
 		zap.Int("user_id", req.UserID))
 
+	currency, err := resolveCurrency(s.currencies, req.Currency)
+	if err != nil {
+		s.metrics.RecordWalletOperation("exchange", "error_unsupported_currency")
+		return 0, err
+	}
+	req.Currency = currency
+
 	// Get exchange rate
-	exchangeRate, err := s.repo.GetExchangeRate(ctx, req.GameID, req.TokenType)
+	exchangeRate, err := s.repo.GetExchangeRate(ctx, req.GameID, req.TokenType, currency)
 	if err != nil {
 		s.logger.Error("Error retrieving exchange rate", 
 			zap.String("game_id", req.GameID),
 			zap.String("token_type", req.TokenType),
+			zap.String("currency", currency),
 			zap.Error(err))
 		s.metrics.RecordWalletOperation("exchange", "error_db")
 		return 0, err
@@ -109,9 +213,11 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 	if exchangeRate == nil {
 		s.logger.Error("Exchange rate not found", 
 			zap.String("game_id", req.GameID),
-			zap.String("token_type", req.TokenType))
+			zap.String("token_type", req.TokenType),
+			zap.String("currency", currency))
 		s.metrics.RecordWalletOperation("exchange", "error_rate_not_found")
-		return 0, fmt.Errorf("exchange rate not found for game_id=%s and token_type=%s", req.GameID, req.TokenType)
+		return 0, fmt.Errorf("exchange rate not found for game_id=%s, token_type=%s and currency=%s",
+			req.GameID, req.TokenType, currency)
 	}
 
 	// Calculate platform amount
@@ -130,8 +236,8 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 	}
 	defer tx.Rollback()
 
-	// Try to get the wallet
-	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, req.UserID, tx)
+	// Try to get the wallet of the target currency
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, req.UserID, currency, tx)
 	if err != nil {
 		s.logger.Error("Error getting wallet for update", 
 			zap.Int("user_id", req.UserID),
@@ -140,15 +246,67 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 		return 0, err
 	}
 
+	if wallet != nil {
+		if err := checkWalletMutable(wallet); err != nil {
+			s.logger.Warn("Exchange rejected by wallet status",
+				zap.Int("user_id", req.UserID),
+				zap.String("status", wallet.Status))
+			s.metrics.RecordWalletOperation("exchange", "error_wallet_"+wallet.Status)
+			return 0, err
+		}
+	}
+
+	// Check the caps of the game token once the wallet is locked, so concurrent exchanges of the user
+	// cannot both pass the check against the same usage
+	if err := s.enforceExchangeCaps(ctx, req.UserID, exchangeRate, req.Amount, tx); err != nil {
+		if errors.Is(err, ErrExchangeCapExceeded) {
+			s.logger.Warn("Exchange rejected by exchange cap",
+				zap.Int("user_id", req.UserID),
+				zap.String("game_id", req.GameID),
+				zap.String("token_type", req.TokenType),
+				zap.Float64("amount", req.Amount),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("exchange", "error_exchange_cap")
+			return 0, err
+		}
+
+		s.logger.Error("Error evaluating exchange caps",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("exchange", "error_exchange_cap_check")
+		return 0, err
+	}
+
+	// Submit the exchange to the risk evaluator
+	riskOp := &model.RiskOperation{
+		Operation:      model.TransactionExchange,
+		UserID:         req.UserID,
+		Currency:       currency,
+		Amount:         req.Amount,
+		PlatformAmount: platformAmount,
+		GameID:         &req.GameID,
+		TokenType:      &req.TokenType,
+	}
+	if wallet != nil {
+		riskOp.Balance = wallet.Balance
+		riskOp.WalletCreatedAt = &wallet.CreatedAt
+	}
+
+	if err := s.evaluateRisk(ctx, riskOp, req); err != nil {
+		s.metrics.RecordWalletOperation("exchange", riskErrorStatus(err))
+		return 0, err
+	}
+
 	var newWallet *model.Wallet
 	
 	// If wallet doesn't exist, create a new one
 	if wallet == nil {
 		s.logger.Info("Creating new wallet for user", 
 			zap.Int("user_id", req.UserID),
+			zap.String("currency", currency),
 			zap.Float64("initial_balance", platformAmount))
 			
-		newWallet, err = s.repo.CreateWallet(ctx, req.UserID, platformAmount, tx)
+		newWallet, err = s.repo.CreateWallet(ctx, req.UserID, currency, platformAmount, tx)
 		if err != nil {
 			s.logger.Error("Failed to create wallet", 
 				zap.Int("user_id", req.UserID),
@@ -156,6 +314,15 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 			s.metrics.RecordWalletOperation("exchange", "error_create_wallet")
 			return 0, err
 		}
+
+		// A new currency wallet inherits the status of the user's other wallets
+		if err := checkWalletMutable(newWallet); err != nil {
+			s.logger.Warn("Exchange rejected by wallet status",
+				zap.Int("user_id", req.UserID),
+				zap.String("status", newWallet.Status))
+			s.metrics.RecordWalletOperation("exchange", "error_wallet_"+newWallet.Status)
+			return 0, err
+		}
 	} else {
 		// Update existing wallet
 		newBalance := wallet.Balance + platformAmount
@@ -165,7 +332,7 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 			zap.Float64("platform_amount", platformAmount),
 			zap.Float64("new_balance", newBalance))
 			
-		newWallet, err = s.repo.UpdateWalletBalance(ctx, req.UserID, newBalance, tx)
+		newWallet, err = s.repo.UpdateWalletBalance(ctx, req.UserID, currency, newBalance, tx)
 		if err != nil {
 			s.logger.Error("Failed to update wallet balance", 
 				zap.Int("user_id", req.UserID),
@@ -175,10 +342,25 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 		}
 	}
 
+	// Exchanged tokens never expire
+	_, err = s.repo.CreateBalanceLot(ctx, &model.BalanceLot{
+		WalletID: newWallet.ID,
+		Amount:   platformAmount,
+		Source:   model.TransactionExchange,
+	}, tx)
+	if err != nil {
+		s.logger.Error("Failed to create balance lot",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("exchange", "error_lot")
+		return 0, err
+	}
+
 	// Create wallet log
 	walletLog := &model.WalletLog{
 		WalletID:       newWallet.ID,
 		UserID:         req.UserID,
+		Currency:       currency,
 		GameID:         &req.GameID,
 		TokenType:      &req.TokenType,
 		Amount:         req.Amount,
@@ -206,6 +388,7 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 
 	s.logger.Info("Exchange completed successfully", 
 		zap.Int("user_id", req.UserID),
+		zap.String("currency", currency),
 		zap.Float64("game_amount", req.Amount),
 		zap.Float64("platform_amount", platformAmount),
 		zap.Float64("new_balance", newWallet.Balance))
@@ -214,10 +397,13 @@ func (s *WalletService) Exchange(ctx context.Context, req *dto.ExchangeRequest)
 	return newWallet.Balance, nil
 }
 
+// Spend deducts tokens from the user's wallet in the requested currency, or the default one when the request
+// names none. req.Currency is set to the currency the spend applies to.
 func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float64, error) {
 	ctx, span := s.tracer.StartSpan(ctx, "WalletService.Spend", 
 		trace.WithAttributes(
 			attribute.Int("user_id", req.UserID),
+			attribute.String("currency", req.Currency),
 			attribute.Float64("amount", req.Amount),
 			attribute.String("reason", req.Reason),
 		))
@@ -225,9 +411,25 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 
 	s.logger.Info("Processing spend request", 
 		zap.Int("user_id", req.UserID),
+		zap.String("currency", req.Currency),
 		zap.Float64("amount", req.Amount),
 		zap.String("reason", req.Reason))
 
+	currency, err := resolveCurrency(s.currencies, req.Currency)
+	if err != nil {
+		s.metrics.RecordWalletOperation("spend", "error_unsupported_currency")
+		return 0, err
+	}
+	req.Currency = currency
+
+	if err := s.checkSpendReason(ctx, req.UserID, req.Reason); err != nil {
+		s.logger.Warn("Spend rejected by feature flag",
+			zap.Int("user_id", req.UserID),
+			zap.String("reason", req.Reason))
+		s.metrics.RecordWalletOperation("spend", "error_feature_disabled")
+		return 0, err
+	}
+
 	// Start a transaction
 	tx, err := s.repo.BeginTx(ctx)
 	if err != nil {
@@ -238,7 +440,7 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 	defer tx.Rollback()
 
 	// Get wallet with lock
-	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, req.UserID, tx)
+	wallet, err := s.repo.GetWalletByUserIDForUpdate(ctx, req.UserID, currency, tx)
 	if err != nil {
 		s.logger.Error("Error getting wallet for update", 
 			zap.Int("user_id", req.UserID),
@@ -248,9 +450,48 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 	}
 
 	if wallet == nil {
-		s.logger.Error("Wallet not found for user", zap.Int("user_id", req.UserID))
+		s.logger.Error("Wallet not found for user",
+			zap.Int("user_id", req.UserID),
+			zap.String("currency", currency))
 		s.metrics.RecordWalletOperation("spend", "error_wallet_not_found")
-		return 0, fmt.Errorf("wallet not found for user_id=%d", req.UserID)
+		return 0, fmt.Errorf("%w for user_id=%d and currency=%s", ErrWalletNotFound, req.UserID, currency)
+	}
+
+	if err := checkWalletMutable(wallet); err != nil {
+		s.logger.Warn("Spend rejected by wallet status",
+			zap.Int("user_id", req.UserID),
+			zap.String("status", wallet.Status))
+		s.metrics.RecordWalletOperation("spend", "error_wallet_"+wallet.Status)
+		return 0, err
+	}
+
+	// Expired tokens must not be spent, even if the expiry job has not removed them yet
+	now := time.Now()
+	wallet, _, err = s.expireWalletLots(ctx, wallet, now, tx)
+	if err != nil {
+		s.logger.Error("Failed to expire balance lots",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("spend", "error_expire")
+		return 0, err
+	}
+
+	// Check spend limits while holding the wallet lock
+	if err := s.enforceSpendLimits(ctx, req.UserID, currency, req.Amount, tx); err != nil {
+		if errors.Is(err, ErrSpendLimitExceeded) {
+			s.logger.Warn("Spend rejected by spend limits",
+				zap.Int("user_id", req.UserID),
+				zap.Float64("amount", req.Amount),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("spend", "error_spend_limit")
+			return 0, err
+		}
+
+		s.logger.Error("Error evaluating spend limits",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("spend", "error_spend_limit_check")
+		return 0, err
 	}
 	
 	// Check if balance is sufficient
@@ -260,11 +501,28 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 			zap.Float64("current_balance", wallet.Balance),
 			zap.Float64("required_amount", req.Amount))
 		s.metrics.RecordWalletOperation("spend", "error_insufficient_funds")
-		return 0, fmt.Errorf("insufficient funds: current balance %.2f, required %.2f", wallet.Balance, req.Amount)
+		return 0, fmt.Errorf("%w: current balance %.2f, required %.2f", ErrInsufficientFunds, wallet.Balance, req.Amount)
+	}
+
+	// Submit the spend to the risk evaluator
+	riskOp := &model.RiskOperation{
+		Operation:       model.TransactionSpend,
+		UserID:          req.UserID,
+		Currency:        currency,
+		Amount:          req.Amount,
+		PlatformAmount:  req.Amount,
+		Reason:          &req.Reason,
+		Balance:         wallet.Balance,
+		WalletCreatedAt: &wallet.CreatedAt,
+	}
+
+	if err := s.evaluateRisk(ctx, riskOp, req); err != nil {
+		s.metrics.RecordWalletOperation("spend", riskErrorStatus(err))
+		return 0, err
 	}
 
 	// Update wallet balance
-	updatedWallet, err := s.repo.SpendFromWallet(ctx, req.UserID, req.Amount, tx)
+	updatedWallet, err := s.repo.SpendFromWallet(ctx, req.UserID, currency, req.Amount, tx)
 	if err != nil {
 		s.logger.Error("Failed to spend from wallet", 
 			zap.Int("user_id", req.UserID),
@@ -274,10 +532,20 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 		return 0, err
 	}
 
+	// Take the amount from the wallet's lots, expiring ones first
+	if err := s.consumeBalanceLots(ctx, wallet, req.Amount, now, tx); err != nil {
+		s.logger.Error("Failed to consume balance lots",
+			zap.Int("user_id", req.UserID),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("spend", "error_lot")
+		return 0, err
+	}
+
 	// Create wallet log
 	walletLog := &model.WalletLog{
 		WalletID:       wallet.ID,
 		UserID:         req.UserID,
+		Currency:       currency,
 		Amount:         -req.Amount,
 		PlatformAmount: -req.Amount,
 		Source:         req.Reason,
@@ -304,6 +572,7 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 
 	s.logger.Info("Spend completed successfully", 
 		zap.Int("user_id", req.UserID),
+		zap.String("currency", currency),
 		zap.Float64("amount", req.Amount),
 		zap.Float64("new_balance", updatedWallet.Balance))
 	s.metrics.RecordWalletOperation("spend", "success")
@@ -311,6 +580,19 @@ func (s *WalletService) Spend(ctx context.Context, req *dto.SpendRequest) (float
 	return updatedWallet.Balance, nil
 }
 
+// walletLogOperation returns the operation a wallet log was written by
+func walletLogOperation(log *model.WalletLog) string {
+	switch {
+	case log.Source == model.TransactionBonus || log.Source == model.TransactionExpiry ||
+		log.Source == model.TransactionReverseExchange || log.Source == model.TransactionReverseExchangeRefund:
+		return log.Source
+	case log.Amount < 0:
+		return model.TransactionSpend
+	default:
+		return model.TransactionExchange
+	}
+}
+
 func (s *WalletService) GetWalletLogs(ctx context.Context, userID int) ([]dto.WalletLogEntry, error) {
 	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GetWalletLogs",
 		trace.WithAttributes(attribute.Int("user_id", userID)))
@@ -341,35 +623,25 @@ func (s *WalletService) GetWalletLogs(ctx context.Context, userID int) ([]dto.Wa
 	// Convert model to DTO
 	result := make([]dto.WalletLogEntry, len(logs))
 	for i, log := range logs {
-		operation := model.TransactionExchange
-		if log.Amount < 0 {
-			operation = model.TransactionSpend
-		}
-		
-		source := log.Source
-		
-		entry := dto.WalletLogEntry{
-			OriginalAmount:  log.Amount,
-			ConvertedAmount: log.PlatformAmount,
-			CreatedAt:       log.CreatedAt,
-			Operation:       operation,
-			Source:          &source,
-		}
-		
-		if log.GameID != nil {
-			entry.GameID = log.GameID
-		}
-		
-		if log.TokenType != nil {
-			entry.TokenType = log.TokenType
-		}
-		
-		if log.ReferenceID != nil {
-			entry.ReferenceID = log.ReferenceID
-		}
-		
-		result[i] = entry
+		result[i] = toWalletLogEntry(log)
 	}
 
 	return result, nil
 }
+
+// toWalletLogEntry converts a wallet log to its API representation
+func toWalletLogEntry(log *model.WalletLog) dto.WalletLogEntry {
+	source := log.Source
+
+	return dto.WalletLogEntry{
+		Currency:        log.Currency,
+		GameID:          log.GameID,
+		TokenType:       log.TokenType,
+		OriginalAmount:  log.Amount,
+		ConvertedAmount: log.PlatformAmount,
+		CreatedAt:       log.CreatedAt,
+		Operation:       walletLogOperation(log),
+		Source:          &source,
+		ReferenceID:     log.ReferenceID,
+	}
+}
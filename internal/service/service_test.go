@@ -6,34 +6,24 @@ import (
 	"testing"
 	"time"
 
+	"github.com/playconomy/wallet-service/internal/config"
 	"github.com/playconomy/wallet-service/internal/model"
 	"github.com/playconomy/wallet-service/internal/observability"
-	"github.com/playconomy/wallet-service/internal/observability/metrics"
-	"github.com/playconomy/wallet-service/internal/observability/tracing"
-	"github.com/playconomy/wallet-service/internal/repository"
 	"github.com/playconomy/wallet-service/internal/server/dto"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
-	"go.uber.org/zap"
 )
 
-// Mock observability components for testing
-type mockObservability struct {
-	logger  *zap.Logger
-	metrics *metrics.Metrics
-	tracer  *tracing.Tracer
-}
-
-func setupTestService(t *testing.T) (*repository.MockRepository, WalletServiceInterface) {
+func setupTestService(t *testing.T) (*mockRepository, WalletServiceInterface) {
 	// Create mock repository
-	mockRepo := new(repository.MockRepository)
+	mockRepo := new(mockRepository)
 
 	// Create test observability
 	obs := observability.NewTestObservability()
 
 	// Create service with mock repository
-	service := NewWalletService(mockRepo, obs)
+	service := NewWalletService(mockRepo, nil, nil, nil, &config.Config{}, obs)
 	
 	// Return the service as an interface to ensure we're testing the interface not the implementation
 	return mockRepo, service
@@ -60,10 +50,13 @@ func TestGetWalletByUserID(t *testing.T) {
 				mockWallet := &model.Wallet{
 					ID:        1,
 					UserID:    123,
+					Currency:  model.CurrencyPlatform,
+					Status:    model.WalletStatusActive,
 					Balance:   500.50,
 					CreatedAt: time.Now(),
 				}
-				mockRepo.On("GetWalletByUserID", mock.Anything, 123).Return(mockWallet, nil).Once()
+				mockRepo.On("GetWalletsByUserID", mock.Anything, 123).Return([]*model.Wallet{mockWallet}, nil).Once()
+				mockRepo.On("ListExpiringBalanceLots", mock.Anything, int64(1), mock.Anything, mock.Anything).Return(nil, nil).Once()
 			},
 			expectedWallet: &dto.Wallet{
 				ID:      1,
@@ -76,7 +69,7 @@ func TestGetWalletByUserID(t *testing.T) {
 			name:   "Wallet Not Found",
 			userID: 456,
 			mockSetup: func() {
-				mockRepo.On("GetWalletByUserID", mock.Anything, 456).Return(nil, nil).Once()
+				mockRepo.On("GetWalletsByUserID", mock.Anything, 456).Return(nil, nil).Once()
 			},
 			expectedWallet: nil,
 			expectError:    false,
@@ -85,7 +78,7 @@ func TestGetWalletByUserID(t *testing.T) {
 			name:   "Database Error",
 			userID: 789,
 			mockSetup: func() {
-				mockRepo.On("GetWalletByUserID", mock.Anything, 789).Return(nil, fmt.Errorf("database connection lost")).Once()
+				mockRepo.On("GetWalletsByUserID", mock.Anything, 789).Return(nil, fmt.Errorf("database connection lost")).Once()
 			},
 			expectedWallet: nil,
 			expectError:    true,
@@ -98,7 +91,7 @@ func TestGetWalletByUserID(t *testing.T) {
 			tc.mockSetup()
 
 			// Call the service method
-			wallet, err := service.GetWalletByUserID(ctx, tc.userID)
+			wallet, err := service.GetWalletByUserID(ctx, tc.userID, "")
 
 			// Check results
 			if tc.expectError {
@@ -149,6 +142,8 @@ func TestExchange(t *testing.T) {
 		wallet := &model.Wallet{
 			ID:        1,
 			UserID:    123,
+			Currency:  model.CurrencyPlatform,
+			Status:    model.WalletStatusActive,
 			Balance:   200.0, // Current balance
 			CreatedAt: time.Now(),
 		}
@@ -156,11 +151,13 @@ func TestExchange(t *testing.T) {
 		updatedWallet := &model.Wallet{
 			ID:        1,
 			UserID:    123,
+			Currency:  model.CurrencyPlatform,
+			Status:    model.WalletStatusActive,
 			Balance:   450.0, // Balance after exchange (200 + 100*2.5)
 			CreatedAt: time.Now(),
 		}
 		
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 		
 		// Create expected wallet log
 		expectedLog := &model.WalletLog{
@@ -186,22 +183,27 @@ func TestExchange(t *testing.T) {
 		}
 
 		// Set up expectations
-		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType).Return(exchangeRate, nil).Once()
+		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType, model.CurrencyPlatform).Return(exchangeRate, nil).Once()
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(wallet, nil).Once()
-		mockRepo.On("UpdateWalletBalance", mock.Anything, req.UserID, 450.0, mockTx).Return(updatedWallet, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(wallet, nil).Once()
+		mockRepo.On("UpdateWalletBalance", mock.Anything, req.UserID, model.CurrencyPlatform, 450.0, mockTx).Return(updatedWallet, nil).Once()
+		mockRepo.On("CreateBalanceLot", mock.Anything, mock.MatchedBy(func(lot *model.BalanceLot) bool {
+			return lot.Amount == 250.0 && lot.ExpiresAt == nil
+		}), mockTx).Return(&model.BalanceLot{ID: 1, WalletID: 1, Amount: 250.0, Remaining: 250.0}, nil).Once()
 		mockRepo.On("CreateWalletLog", mock.Anything, mock.MatchedBy(func(log *model.WalletLog) bool {
 			return log.UserID == expectedLog.UserID && 
 				   log.PlatformAmount == expectedLog.PlatformAmount
 		}), mockTx).Return(returnedLog, nil).Once()
 		mockTx.On("Commit").Return(nil).Once()
+		// The deferred rollback of a committed transaction is a no-op
+		mockTx.On("Rollback").Return(nil).Maybe()
 
 		// Call the service method
-		platformAmount, err := service.Exchange(ctx, req)
+		newBalance, err := service.Exchange(ctx, req)
 
 		// Check results
 		assert.NoError(t, err)
-		assert.Equal(t, 250.0, platformAmount)
+		assert.Equal(t, 450.0, newBalance)
 		
 		// Verify that all expected calls were made
 		mockRepo.AssertExpectations(t)
@@ -218,7 +220,7 @@ func TestExchange(t *testing.T) {
 			Source:    "game_reward",
 		}
 
-		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType).Return(nil, nil).Once()
+		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType, model.CurrencyPlatform).Return(nil, nil).Once()
 
 		platformAmount, err := service.Exchange(ctx, req)
 
@@ -239,7 +241,7 @@ func TestExchange(t *testing.T) {
 			Source:    "game_reward",
 		}
 
-		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType).Return(nil, fmt.Errorf("database error")).Once()
+		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType, model.CurrencyPlatform).Return(nil, fmt.Errorf("database error")).Once()
 
 		platformAmount, err := service.Exchange(ctx, req)
 
@@ -267,11 +269,11 @@ func TestExchange(t *testing.T) {
 			CreatedAt:       time.Now(),
 		}
 		
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 
-		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType).Return(exchangeRate, nil).Once()
+		mockRepo.On("GetExchangeRate", mock.Anything, req.GameID, req.TokenType, model.CurrencyPlatform).Return(exchangeRate, nil).Once()
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(nil, fmt.Errorf("database error")).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(nil, fmt.Errorf("database error")).Once()
 		mockTx.On("Rollback").Return(nil).Once()
 
 		platformAmount, err := service.Exchange(ctx, req)
@@ -300,6 +302,8 @@ func TestSpend(t *testing.T) {
 		wallet := &model.Wallet{
 			ID:        1,
 			UserID:    123,
+			Currency:  model.CurrencyPlatform,
+			Status:    model.WalletStatusActive,
 			Balance:   200.0, // Current balance
 			CreatedAt: time.Now(),
 		}
@@ -307,11 +311,13 @@ func TestSpend(t *testing.T) {
 		updatedWallet := &model.Wallet{
 			ID:        1,
 			UserID:    123,
+			Currency:  model.CurrencyPlatform,
+			Status:    model.WalletStatusActive,
 			Balance:   150.0, // Balance after spend (200 - 50)
 			CreatedAt: time.Now(),
 		}
 		
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 		
 		// Create expected wallet log
 		expectedLog := &model.WalletLog{
@@ -336,14 +342,23 @@ func TestSpend(t *testing.T) {
 
 		// Set up expectations
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(wallet, nil).Once()
-		mockRepo.On("SpendFromWallet", mock.Anything, req.UserID, req.Amount, mockTx).Return(updatedWallet, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(wallet, nil).Once()
+		mockRepo.On("ExpireBalanceLots", mock.Anything, wallet.ID, mock.Anything, mockTx).Return(nil, nil).Once()
+		mockRepo.On("GetSpendLimitOverride", mock.Anything, req.UserID).Return(nil, nil).Once()
+		mockRepo.On("GetSpendUsage", mock.Anything, req.UserID, model.CurrencyPlatform, mock.Anything, mockTx).
+			Return(&model.SpendUsage{}, nil).Once()
+		mockRepo.On("SpendFromWallet", mock.Anything, req.UserID, model.CurrencyPlatform, req.Amount, mockTx).Return(updatedWallet, nil).Once()
+		mockRepo.On("GetSpendableBalanceLotsForUpdate", mock.Anything, wallet.ID, mock.Anything, mockTx).Return(
+			[]*model.BalanceLot{{ID: 1, WalletID: wallet.ID, Amount: wallet.Balance, Remaining: wallet.Balance}}, nil).Once()
+		mockRepo.On("UpdateBalanceLotRemaining", mock.Anything, int64(1), wallet.Balance-req.Amount, mockTx).Return(nil).Once()
 		mockRepo.On("CreateWalletLog", mock.Anything, mock.MatchedBy(func(log *model.WalletLog) bool {
 			return log.UserID == expectedLog.UserID && 
 				   log.PlatformAmount == expectedLog.PlatformAmount &&
 				   log.Source == expectedLog.Source
 		}), mockTx).Return(returnedLog, nil).Once()
 		mockTx.On("Commit").Return(nil).Once()
+		// The deferred rollback of a committed transaction is a no-op
+		mockTx.On("Rollback").Return(nil).Maybe()
 
 		// Call the service method
 		newBalance, err := service.Spend(ctx, req)
@@ -366,10 +381,10 @@ func TestSpend(t *testing.T) {
 			ReferenceID: "ORDER-456",
 		}
 
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(nil, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(nil, nil).Once()
 		mockTx.On("Rollback").Return(nil).Once()
 
 		newBalance, err := service.Spend(ctx, req)
@@ -394,14 +409,20 @@ func TestSpend(t *testing.T) {
 		wallet := &model.Wallet{
 			ID:        2,
 			UserID:    789,
+			Currency:  model.CurrencyPlatform,
+			Status:    model.WalletStatusActive,
 			Balance:   100.0, // Not enough balance for 300.0 spend
 			CreatedAt: time.Now(),
 		}
 		
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(wallet, nil).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(wallet, nil).Once()
+		mockRepo.On("ExpireBalanceLots", mock.Anything, wallet.ID, mock.Anything, mockTx).Return(nil, nil).Once()
+		mockRepo.On("GetSpendLimitOverride", mock.Anything, req.UserID).Return(nil, nil).Once()
+		mockRepo.On("GetSpendUsage", mock.Anything, req.UserID, model.CurrencyPlatform, mock.Anything, mockTx).
+			Return(&model.SpendUsage{}, nil).Once()
 		mockTx.On("Rollback").Return(nil).Once()
 
 		newBalance, err := service.Spend(ctx, req)
@@ -423,10 +444,10 @@ func TestSpend(t *testing.T) {
 			ReferenceID: "ORDER-123",
 		}
 
-		mockTx := new(repository.MockTransaction)
+		mockTx := new(mockTransaction)
 
 		mockRepo.On("BeginTx", mock.Anything).Return(mockTx, nil).Once()
-		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, mockTx).Return(nil, fmt.Errorf("database error")).Once()
+		mockRepo.On("GetWalletByUserIDForUpdate", mock.Anything, req.UserID, model.CurrencyPlatform, mockTx).Return(nil, fmt.Errorf("database error")).Once()
 		mockTx.On("Rollback").Return(nil).Once()
 
 		newBalance, err := service.Spend(ctx, req)
@@ -0,0 +1,68 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// SnapshotScheduler takes balance snapshots at every multiple of the snapshot interval, once the lag
+// has passed, for the lifetime of the application
+type SnapshotScheduler struct {
+	service SnapshotServiceInterface
+	logger  *zap.Logger
+}
+
+// NewSnapshotScheduler creates a scheduler and registers its lifecycle hooks.
+// The scheduler does nothing when snapshots are disabled in the configuration;
+// point-in-time balances are then computed from older snapshots and the logs.
+func NewSnapshotScheduler(lc fx.Lifecycle, svc SnapshotServiceInterface,
+	cfg *config.Config, obs *observability.Observability) *SnapshotScheduler {
+
+	scheduler := &SnapshotScheduler{
+		service: svc,
+		logger:  obs.Logger.Logger.With(zap.String("component", "snapshot_scheduler")),
+	}
+
+	if !cfg.Snapshots.Enabled {
+		scheduler.logger.Info("Scheduled balance snapshots disabled")
+		return scheduler
+	}
+
+	interval, lag := cfg.Snapshots.Interval, cfg.Snapshots.Lag
+	startPeriodicTask(lc, scheduler.logger, periodicTask{
+		name: "snapshot scheduler",
+		schedule: func(now time.Time) time.Time {
+			return nextSnapshotRun(now, interval, lag)
+		},
+		run: scheduler.run,
+	},
+		zap.Duration("interval", interval),
+		zap.Duration("lag", lag))
+
+	return scheduler
+}
+
+// nextSnapshotRun returns when the snapshot of the next interval boundary after now can be taken
+func nextSnapshotRun(now time.Time, interval, lag time.Duration) time.Time {
+	return snapshotTime(now, interval, lag).Add(interval).Add(lag)
+}
+
+func (s *SnapshotScheduler) run(ctx context.Context) {
+	report, err := s.service.TakeBalanceSnapshots(ctx)
+	if err != nil && !errors.Is(err, context.Canceled) {
+		s.logger.Error("Scheduled balance snapshot failed", zap.Error(err))
+		return
+	}
+	if report != nil {
+		s.logger.Info("Scheduled balance snapshot completed",
+			zap.Time("taken_at", report.TakenAt),
+			zap.Int("snapshots_created", report.SnapshotsCreated))
+	}
+}
@@ -0,0 +1,154 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// SnapshotService takes periodic balance snapshots and answers point-in-time balance queries from them
+type SnapshotService struct {
+	wallets    repository.WalletRepository
+	repo       repository.SnapshotRepository
+	currencies config.CurrencyConfig
+	snapshots  config.SnapshotsConfig
+	logger     *zap.Logger
+	metrics    *metrics.Metrics
+	tracer     *tracing.Tracer
+}
+
+// Compile-time verification that SnapshotService implements SnapshotServiceInterface
+var _ SnapshotServiceInterface = (*SnapshotService)(nil)
+
+// NewSnapshotService creates a new balance snapshot service
+func NewSnapshotService(wallets repository.WalletRepository, repo repository.SnapshotRepository,
+	cfg *config.Config, obs *observability.Observability) *SnapshotService {
+	return &SnapshotService{
+		wallets:    wallets,
+		repo:       repo,
+		currencies: currencySettings(cfg.Currency),
+		snapshots:  cfg.Snapshots,
+		logger:     obs.Logger.Logger.With(zap.String("component", "snapshot_service")),
+		metrics:    obs.Metrics,
+		tracer:     obs.Tracer,
+	}
+}
+
+// snapshotTime returns the point in time a snapshot taken now covers: the latest multiple of the interval
+// that is at least lag in the past
+func snapshotTime(now time.Time, interval, lag time.Duration) time.Time {
+	return now.UTC().Add(-lag).Truncate(interval)
+}
+
+// TakeBalanceSnapshots snapshots the balance of every wallet at the latest snapshot time, one batch of
+// wallets per query. Wallets already snapshotted at that time are skipped.
+func (s *SnapshotService) TakeBalanceSnapshots(ctx context.Context) (*dto.SnapshotReport, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "SnapshotService.TakeBalanceSnapshots")
+	defer span.End()
+
+	report := &dto.SnapshotReport{
+		TakenAt: snapshotTime(time.Now(), s.snapshots.Interval, s.snapshots.Lag),
+	}
+
+	var afterWalletID int64
+	for {
+		if err := ctx.Err(); err != nil {
+			return report, err
+		}
+
+		created, lastWalletID, err := s.repo.CreateBalanceSnapshots(ctx, report.TakenAt, afterWalletID,
+			s.snapshots.BatchSize)
+		if err != nil {
+			s.logger.Error("Failed to take balance snapshots",
+				zap.Time("taken_at", report.TakenAt),
+				zap.Int64("after_wallet_id", afterWalletID),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("snapshot", "error")
+			return report, err
+		}
+
+		report.SnapshotsCreated += created
+		if lastWalletID == 0 {
+			break
+		}
+		afterWalletID = lastWalletID
+	}
+
+	s.metrics.RecordWalletOperation("snapshot", "success")
+
+	return report, nil
+}
+
+// GetBalanceAt returns the balance a user's wallet in the given currency, or the default one when currency
+// is empty, had at a past point in time. The balance is the wallet's latest snapshot taken up to at plus the
+// logs created after that snapshot; it is 0 before the wallet's first log. Balances before the wallet's
+// newest archived log are not available.
+func (s *SnapshotService) GetBalanceAt(ctx context.Context, userID int, currency string,
+	at time.Time) (*dto.PointInTimeBalance, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "SnapshotService.GetBalanceAt",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	at = at.UTC()
+	if at.After(time.Now()) {
+		return nil, fmt.Errorf("%w: %s is in the future", ErrInvalidBalanceTime, at.Format(time.RFC3339))
+	}
+
+	currency, err := resolveCurrency(s.currencies, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.wallets.GetWalletByUserID(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("%w for user_id=%d and currency=%s", ErrWalletNotFound, userID, currency)
+	}
+
+	balance, err := s.repo.GetBalanceAt(ctx, wallet.ID, at)
+	if err != nil {
+		s.metrics.RecordWalletOperation("balance_at", "error")
+		return nil, err
+	}
+
+	if balance.LastArchivedLogAt != nil && at.Before(*balance.LastArchivedLogAt) {
+		return nil, fmt.Errorf("%w: the balance is available from %s", ErrBalanceArchived,
+			balance.LastArchivedLogAt.UTC().Format(time.RFC3339))
+	}
+
+	s.logger.Debug("Computed point-in-time balance",
+		zap.Int("user_id", userID),
+		zap.String("currency", currency),
+		zap.Time("at", at),
+		zap.Bool("from_snapshot", balance.SnapshotAt != nil),
+		zap.Int("logs_applied", balance.LogsApplied))
+	s.metrics.RecordWalletOperation("balance_at", "success")
+
+	result := &dto.PointInTimeBalance{
+		UserID:   userID,
+		Currency: currency,
+		At:       at,
+		Balance:  roundCents(balance.Balance),
+	}
+	if balance.SnapshotAt != nil {
+		snapshotAt := balance.SnapshotAt.UTC()
+		result.SnapshotAt = &snapshotAt
+	}
+
+	return result, nil
+}
@@ -0,0 +1,54 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnapshotTime(t *testing.T) {
+	testCases := []struct {
+		name     string
+		now      time.Time
+		interval time.Duration
+		lag      time.Duration
+		expected time.Time
+	}{
+		{
+			name:     "Daily After Lag",
+			now:      time.Date(2026, 3, 1, 0, 10, 0, 0, time.UTC),
+			interval: 24 * time.Hour,
+			lag:      5 * time.Minute,
+			expected: time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Daily Within Lag Takes Previous Day",
+			now:      time.Date(2026, 3, 1, 0, 2, 0, 0, time.UTC),
+			interval: 24 * time.Hour,
+			lag:      5 * time.Minute,
+			expected: time.Date(2026, 2, 28, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name:     "Hourly Converts To UTC",
+			now:      time.Date(2026, 3, 1, 12, 30, 0, 0, time.FixedZone("CET", 3600)),
+			interval: time.Hour,
+			lag:      0,
+			expected: time.Date(2026, 3, 1, 11, 0, 0, 0, time.UTC),
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, snapshotTime(tc.now, tc.interval, tc.lag))
+		})
+	}
+}
+
+func TestNextSnapshotRun(t *testing.T) {
+	now := time.Date(2026, 3, 1, 0, 2, 0, 0, time.UTC)
+	next := nextSnapshotRun(now, 24*time.Hour, 5*time.Minute)
+
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 5, 0, 0, time.UTC), next)
+	assert.Equal(t, time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC), snapshotTime(next, 24*time.Hour, 5*time.Minute))
+}
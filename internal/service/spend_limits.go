@@ -0,0 +1,242 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// effectiveSpendLimits applies a user's override on top of the configured default limits
+func effectiveSpendLimits(defaults config.SpendLimitsConfig, override *model.SpendLimitOverride) model.SpendLimits {
+	limits := model.SpendLimits{
+		MaxPerTransaction:  defaults.MaxPerTransaction,
+		DailyCap:           defaults.DailyCap,
+		WeeklyCap:          defaults.WeeklyCap,
+		MaxSpendsPerMinute: defaults.MaxSpendsPerMinute,
+	}
+
+	if override == nil {
+		return limits
+	}
+
+	if override.MaxPerTransaction != nil {
+		limits.MaxPerTransaction = *override.MaxPerTransaction
+	}
+	if override.DailyCap != nil {
+		limits.DailyCap = *override.DailyCap
+	}
+	if override.WeeklyCap != nil {
+		limits.WeeklyCap = *override.WeeklyCap
+	}
+	if override.MaxSpendsPerMinute != nil {
+		limits.MaxSpendsPerMinute = *override.MaxSpendsPerMinute
+	}
+
+	return limits
+}
+
+// checkSpendLimits returns the limit error a spend of amount would cause given the user's recent usage
+func checkSpendLimits(limits model.SpendLimits, usage model.SpendUsage, amount float64) error {
+	if limits.MaxPerTransaction > 0 && amount > limits.MaxPerTransaction {
+		return fmt.Errorf("%w: amount %.2f is above %.2f", ErrTransactionLimitExceeded, amount, limits.MaxPerTransaction)
+	}
+
+	if limits.MaxSpendsPerMinute > 0 && usage.SpendsLastMinute >= limits.MaxSpendsPerMinute {
+		return fmt.Errorf("%w: limit is %d", ErrSpendVelocityExceeded, limits.MaxSpendsPerMinute)
+	}
+
+	if limits.DailyCap > 0 && roundCents(usage.SpentLastDay+amount) > limits.DailyCap {
+		return fmt.Errorf("%w: remaining %.2f, required %.2f",
+			ErrDailySpendLimitExceeded, remainingAllowance(limits.DailyCap, usage.SpentLastDay), amount)
+	}
+
+	if limits.WeeklyCap > 0 && roundCents(usage.SpentLastWeek+amount) > limits.WeeklyCap {
+		return fmt.Errorf("%w: remaining %.2f, required %.2f",
+			ErrWeeklySpendLimitExceeded, remainingAllowance(limits.WeeklyCap, usage.SpentLastWeek), amount)
+	}
+
+	return nil
+}
+
+// remainingAllowance returns what is left of a cap, never below zero
+func remainingAllowance(limit, spent float64) float64 {
+	return math.Max(0, roundCents(limit-spent))
+}
+
+// toSpendAllowance describes the limits of a user and what is left of them
+func toSpendAllowance(userID int, limits model.SpendLimits, overridden bool, usage model.SpendUsage) *dto.SpendAllowance {
+	allowance := &dto.SpendAllowance{
+		UserID: userID,
+		Limits: dto.SpendLimits{
+			MaxPerTransaction:  limits.MaxPerTransaction,
+			DailyCap:           limits.DailyCap,
+			WeeklyCap:          limits.WeeklyCap,
+			MaxSpendsPerMinute: limits.MaxSpendsPerMinute,
+		},
+		Overridden:       overridden,
+		SpentLastDay:     usage.SpentLastDay,
+		SpentLastWeek:    usage.SpentLastWeek,
+		SpendsLastMinute: usage.SpendsLastMinute,
+	}
+
+	maxSpendable := math.Inf(1)
+
+	if limits.MaxPerTransaction > 0 {
+		maxSpendable = limits.MaxPerTransaction
+	}
+	if limits.DailyCap > 0 {
+		remaining := remainingAllowance(limits.DailyCap, usage.SpentLastDay)
+		allowance.RemainingDaily = &remaining
+		maxSpendable = math.Min(maxSpendable, remaining)
+	}
+	if limits.WeeklyCap > 0 {
+		remaining := remainingAllowance(limits.WeeklyCap, usage.SpentLastWeek)
+		allowance.RemainingWeekly = &remaining
+		maxSpendable = math.Min(maxSpendable, remaining)
+	}
+	if limits.MaxSpendsPerMinute > 0 {
+		remaining := limits.MaxSpendsPerMinute - usage.SpendsLastMinute
+		if remaining < 0 {
+			remaining = 0
+		}
+		allowance.RemainingSpendsThisMinute = &remaining
+		if remaining == 0 {
+			maxSpendable = 0
+		}
+	}
+
+	if !math.IsInf(maxSpendable, 1) {
+		allowance.MaxSpendable = &maxSpendable
+	}
+
+	return allowance
+}
+
+func toSpendLimitOverrideDTO(override *model.SpendLimitOverride) *dto.SpendLimitOverride {
+	return &dto.SpendLimitOverride{
+		UserID:             override.UserID,
+		MaxPerTransaction:  override.MaxPerTransaction,
+		DailyCap:           override.DailyCap,
+		WeeklyCap:          override.WeeklyCap,
+		MaxSpendsPerMinute: override.MaxSpendsPerMinute,
+		Reason:             override.Reason,
+		UpdatedBy:          override.UpdatedBy,
+		UpdatedAt:          override.UpdatedAt,
+	}
+}
+
+// enforceSpendLimits checks a spend against the user's limits inside the transaction holding the wallet lock,
+// so concurrent spends of the same user are evaluated one after another
+func (s *WalletService) enforceSpendLimits(ctx context.Context, userID int, currency string, amount float64,
+	tx repository.Transaction) error {
+
+	override, err := s.repo.GetSpendLimitOverride(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	usage, err := s.repo.GetSpendUsage(ctx, userID, currency, time.Now(), tx)
+	if err != nil {
+		return err
+	}
+
+	return checkSpendLimits(effectiveSpendLimits(*s.spendLimits.Load(), override), *usage, amount)
+}
+
+// GetSpendAllowance retrieves a user's spend limits and the allowance left in each window for one currency,
+// or the default one when currency is empty. Limits apply to each currency separately.
+func (s *WalletService) GetSpendAllowance(ctx context.Context, userID int, currency string) (*dto.SpendAllowance, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GetSpendAllowance",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("currency", currency),
+		))
+	defer span.End()
+
+	currency, err := resolveCurrency(s.currencies, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	override, err := s.repo.GetSpendLimitOverride(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error retrieving spend limit override",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	usage, err := s.repo.GetSpendUsage(ctx, userID, currency, time.Now(), nil)
+	if err != nil {
+		s.logger.Error("Error retrieving spend usage",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	limits := effectiveSpendLimits(*s.spendLimits.Load(), override)
+	allowance := toSpendAllowance(userID, limits, override != nil, *usage)
+	allowance.Currency = currency
+	return allowance, nil
+}
+
+// SetSpendLimitOverride creates or replaces a user's spend limit override on behalf of an admin
+func (s *WalletService) SetSpendLimitOverride(
+	ctx context.Context, userID int, req *dto.UpdateSpendLimitsRequest, updatedBy int) (*dto.SpendLimitOverride, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.SetSpendLimitOverride",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	override, err := s.repo.UpsertSpendLimitOverride(ctx, &model.SpendLimitOverride{
+		UserID:             userID,
+		MaxPerTransaction:  req.MaxPerTransaction,
+		DailyCap:           req.DailyCap,
+		WeeklyCap:          req.WeeklyCap,
+		MaxSpendsPerMinute: req.MaxSpendsPerMinute,
+		Reason:             req.Reason,
+		UpdatedBy:          &updatedBy,
+	})
+	if err != nil {
+		s.metrics.RecordWalletOperation("spend_limit_override", "error")
+		return nil, err
+	}
+
+	s.logger.Info("Spend limit override saved",
+		zap.Int("user_id", userID),
+		zap.Int("updated_by", updatedBy),
+		zap.String("reason", req.Reason))
+	s.metrics.RecordWalletOperation("spend_limit_override", "success")
+
+	return toSpendLimitOverrideDTO(override), nil
+}
+
+// DeleteSpendLimitOverride removes a user's spend limit override and reports whether one existed
+func (s *WalletService) DeleteSpendLimitOverride(ctx context.Context, userID int) (bool, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.DeleteSpendLimitOverride",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	deleted, err := s.repo.DeleteSpendLimitOverride(ctx, userID)
+	if err != nil {
+		s.metrics.RecordWalletOperation("spend_limit_override_delete", "error")
+		return false, err
+	}
+
+	if deleted {
+		s.logger.Info("Spend limit override removed", zap.Int("user_id", userID))
+	}
+	s.metrics.RecordWalletOperation("spend_limit_override_delete", "success")
+
+	return deleted, nil
+}
@@ -0,0 +1,163 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEffectiveSpendLimits(t *testing.T) {
+	defaults := config.SpendLimitsConfig{
+		MaxPerTransaction:  100,
+		DailyCap:           500,
+		WeeklyCap:          2000,
+		MaxSpendsPerMinute: 5,
+	}
+
+	t.Run("Defaults Without Override", func(t *testing.T) {
+		limits := effectiveSpendLimits(defaults, nil)
+
+		assert.Equal(t, model.SpendLimits{
+			MaxPerTransaction:  100,
+			DailyCap:           500,
+			WeeklyCap:          2000,
+			MaxSpendsPerMinute: 5,
+		}, limits)
+	})
+
+	t.Run("Override Replaces Set Fields Only", func(t *testing.T) {
+		dailyCap := 1000.0
+		maxPerMinute := 0
+
+		limits := effectiveSpendLimits(defaults, &model.SpendLimitOverride{
+			DailyCap:           &dailyCap,
+			MaxSpendsPerMinute: &maxPerMinute,
+		})
+
+		assert.Equal(t, model.SpendLimits{
+			MaxPerTransaction:  100,
+			DailyCap:           1000,
+			WeeklyCap:          2000,
+			MaxSpendsPerMinute: 0,
+		}, limits)
+	})
+}
+
+func TestCheckSpendLimits(t *testing.T) {
+	limits := model.SpendLimits{
+		MaxPerTransaction:  100,
+		DailyCap:           500,
+		WeeklyCap:          2000,
+		MaxSpendsPerMinute: 3,
+	}
+
+	testCases := []struct {
+		name          string
+		limits        model.SpendLimits
+		usage         model.SpendUsage
+		amount        float64
+		expectedError error
+	}{
+		{
+			name:   "Within Limits",
+			limits: limits,
+			usage:  model.SpendUsage{SpentLastDay: 100, SpentLastWeek: 300, SpendsLastMinute: 1},
+			amount: 50,
+		},
+		{
+			name:   "Exactly Reaches Daily Cap",
+			limits: limits,
+			usage:  model.SpendUsage{SpentLastDay: 400.1, SpentLastWeek: 400.1},
+			amount: 99.9,
+		},
+		{
+			name:          "Above Per-Transaction Maximum",
+			limits:        limits,
+			amount:        100.01,
+			expectedError: ErrTransactionLimitExceeded,
+		},
+		{
+			name:          "Too Many Spends Per Minute",
+			limits:        limits,
+			usage:         model.SpendUsage{SpendsLastMinute: 3},
+			amount:        10,
+			expectedError: ErrSpendVelocityExceeded,
+		},
+		{
+			name:          "Daily Cap Exceeded",
+			limits:        limits,
+			usage:         model.SpendUsage{SpentLastDay: 450, SpentLastWeek: 450},
+			amount:        60,
+			expectedError: ErrDailySpendLimitExceeded,
+		},
+		{
+			name:          "Weekly Cap Exceeded",
+			limits:        limits,
+			usage:         model.SpendUsage{SpentLastDay: 0, SpentLastWeek: 1950},
+			amount:        60,
+			expectedError: ErrWeeklySpendLimitExceeded,
+		},
+		{
+			name:   "Unlimited",
+			usage:  model.SpendUsage{SpentLastDay: 1e6, SpentLastWeek: 1e7, SpendsLastMinute: 100},
+			amount: 1e6,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := checkSpendLimits(tc.limits, tc.usage, tc.amount)
+
+			if tc.expectedError == nil {
+				assert.NoError(t, err)
+				return
+			}
+
+			assert.ErrorIs(t, err, tc.expectedError)
+			assert.ErrorIs(t, err, ErrSpendLimitExceeded)
+		})
+	}
+}
+
+func TestToSpendAllowance(t *testing.T) {
+	t.Run("Remaining Allowance", func(t *testing.T) {
+		limits := model.SpendLimits{MaxPerTransaction: 100, DailyCap: 500, WeeklyCap: 2000, MaxSpendsPerMinute: 3}
+		usage := model.SpendUsage{SpentLastDay: 450, SpentLastWeek: 1000, SpendsLastMinute: 1}
+
+		allowance := toSpendAllowance(123, limits, true, usage)
+
+		require.NotNil(t, allowance.RemainingDaily)
+		require.NotNil(t, allowance.RemainingWeekly)
+		require.NotNil(t, allowance.RemainingSpendsThisMinute)
+		require.NotNil(t, allowance.MaxSpendable)
+		assert.Equal(t, 50.0, *allowance.RemainingDaily)
+		assert.Equal(t, 1000.0, *allowance.RemainingWeekly)
+		assert.Equal(t, 2, *allowance.RemainingSpendsThisMinute)
+		assert.Equal(t, 50.0, *allowance.MaxSpendable)
+		assert.True(t, allowance.Overridden)
+	})
+
+	t.Run("Velocity Exhausted", func(t *testing.T) {
+		limits := model.SpendLimits{MaxSpendsPerMinute: 2}
+		usage := model.SpendUsage{SpendsLastMinute: 2}
+
+		allowance := toSpendAllowance(123, limits, false, usage)
+
+		require.NotNil(t, allowance.MaxSpendable)
+		assert.Equal(t, 0.0, *allowance.MaxSpendable)
+		assert.Nil(t, allowance.RemainingDaily)
+	})
+
+	t.Run("Unlimited", func(t *testing.T) {
+		allowance := toSpendAllowance(123, model.SpendLimits{}, false, model.SpendUsage{})
+
+		assert.Nil(t, allowance.RemainingDaily)
+		assert.Nil(t, allowance.RemainingWeekly)
+		assert.Nil(t, allowance.RemainingSpendsThisMinute)
+		assert.Nil(t, allowance.MaxSpendable)
+	})
+}
@@ -0,0 +1,351 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Statement formats
+const (
+	StatementFormatJSON = "json"
+	StatementFormatText = "text"
+	StatementFormatHTML = "html"
+)
+
+// statementPeriodLayout is the layout of statement periods, one calendar month in UTC
+const statementPeriodLayout = "2006-01"
+
+// StatementService generates and stores monthly wallet statements
+type StatementService struct {
+	wallets     repository.WalletRepository
+	repo        repository.StatementRepository
+	currencies  config.CurrencyConfig
+	settleDelay time.Duration
+	logger      *zap.Logger
+	metrics     *metrics.Metrics
+	tracer      *tracing.Tracer
+}
+
+// Compile-time verification that StatementService implements StatementServiceInterface
+var _ StatementServiceInterface = (*StatementService)(nil)
+
+// NewStatementService creates a new statement service
+func NewStatementService(wallets repository.WalletRepository, repo repository.StatementRepository,
+	cfg *config.Config, obs *observability.Observability) *StatementService {
+	return &StatementService{
+		wallets:     wallets,
+		repo:        repo,
+		currencies:  currencySettings(cfg.Currency),
+		settleDelay: cfg.Statements.SettleDelay,
+		logger:      obs.Logger.Logger.With(zap.String("component", "statement_service")),
+		metrics:     obs.Metrics,
+		tracer:      obs.Tracer,
+	}
+}
+
+// parseStatementPeriod returns the start (inclusive) and end (exclusive) of a YYYY-MM period in UTC
+func parseStatementPeriod(period string) (time.Time, time.Time, error) {
+	start, err := time.Parse(statementPeriodLayout, period)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("%w: %q, expected YYYY-MM", ErrInvalidStatementPeriod, period)
+	}
+	return start, start.AddDate(0, 1, 0), nil
+}
+
+// buildWalletStatement lists the logs of a ledger with the running balance and sums them up
+func buildWalletStatement(wallet *model.Wallet, period string, start, end time.Time,
+	ledger *model.StatementLedger) *dto.WalletStatement {
+
+	statement := &dto.WalletStatement{
+		UserID:         wallet.UserID,
+		Currency:       wallet.Currency,
+		Period:         period,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: roundCents(ledger.OpeningBalance),
+		Entries:        make([]dto.StatementEntry, 0, len(ledger.Logs)),
+	}
+
+	balance := statement.OpeningBalance
+	for _, log := range ledger.Logs {
+		if log.PlatformAmount >= 0 {
+			statement.TotalCredits += log.PlatformAmount
+		} else {
+			statement.TotalDebits -= log.PlatformAmount
+		}
+		balance = roundCents(balance + log.PlatformAmount)
+
+		statement.Entries = append(statement.Entries, dto.StatementEntry{
+			LogID:          log.ID,
+			Operation:      walletLogOperation(log),
+			GameID:         log.GameID,
+			TokenType:      log.TokenType,
+			Amount:         log.Amount,
+			PlatformAmount: log.PlatformAmount,
+			Balance:        balance,
+			Source:         log.Source,
+			ReferenceID:    log.ReferenceID,
+			CreatedAt:      log.CreatedAt.UTC(),
+		})
+	}
+
+	statement.TotalCredits = roundCents(statement.TotalCredits)
+	statement.TotalDebits = roundCents(statement.TotalDebits)
+	statement.ClosingBalance = balance
+
+	return statement
+}
+
+func toWalletStatementDTO(statement *model.WalletStatement) (*dto.WalletStatement, error) {
+	result := &dto.WalletStatement{
+		UserID:         statement.UserID,
+		Currency:       statement.Currency,
+		Period:         statement.Period,
+		PeriodStart:    statement.PeriodStart.UTC(),
+		PeriodEnd:      statement.PeriodEnd.UTC(),
+		OpeningBalance: statement.OpeningBalance,
+		TotalCredits:   statement.TotalCredits,
+		TotalDebits:    statement.TotalDebits,
+		ClosingBalance: statement.ClosingBalance,
+		GeneratedAt:    statement.GeneratedAt.UTC(),
+	}
+
+	if err := json.Unmarshal(statement.Entries, &result.Entries); err != nil {
+		return nil, fmt.Errorf("decode statement entries: %w", err)
+	}
+
+	return result, nil
+}
+
+// GetStatement returns the statement of a user's wallet in the given currency, or the default one when
+// currency is empty, for a YYYY-MM period. The statement is generated from the wallet logs the first time
+// it is requested after the month ended and SettleDelay passed; later requests return the stored statement.
+func (s *StatementService) GetStatement(ctx context.Context, userID int, currency, period string) (*dto.WalletStatement, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "StatementService.GetStatement",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("period", period),
+		))
+	defer span.End()
+
+	start, end, err := parseStatementPeriod(period)
+	if err != nil {
+		return nil, err
+	}
+
+	if time.Now().Before(end.Add(s.settleDelay)) {
+		return nil, fmt.Errorf("%w: %s is available from %s", ErrStatementPeriodOpen, period,
+			end.Add(s.settleDelay).Format(time.RFC3339))
+	}
+
+	currency, err = resolveCurrency(s.currencies, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	wallet, err := s.wallets.GetWalletByUserID(ctx, userID, currency)
+	if err != nil {
+		return nil, err
+	}
+
+	if wallet == nil {
+		return nil, fmt.Errorf("%w for user_id=%d and currency=%s", ErrWalletNotFound, userID, currency)
+	}
+
+	if !wallet.CreatedAt.Before(end) {
+		return nil, fmt.Errorf("%w: %s", ErrStatementNotAvailable, period)
+	}
+
+	stored, err := s.repo.GetWalletStatement(ctx, wallet.ID, period)
+	if err != nil {
+		s.metrics.RecordWalletOperation("statement", "error")
+		return nil, err
+	}
+
+	if stored == nil {
+		stored, err = s.generateStatement(ctx, wallet, period, start, end)
+		if err != nil {
+			s.logger.Error("Failed to generate wallet statement",
+				zap.Int("user_id", userID),
+				zap.String("currency", currency),
+				zap.String("period", period),
+				zap.Error(err))
+			s.metrics.RecordWalletOperation("statement", "error")
+			return nil, err
+		}
+	} else {
+		s.metrics.RecordWalletOperation("statement", "stored")
+	}
+
+	return toWalletStatementDTO(stored)
+}
+
+// generateStatement builds a wallet's statement from its logs and stores it. When a concurrent request
+// stored the statement first, that one is returned.
+func (s *StatementService) generateStatement(ctx context.Context, wallet *model.Wallet, period string,
+	start, end time.Time) (*model.WalletStatement, error) {
+
+	ledger, err := s.repo.GetStatementLedger(ctx, wallet.ID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Statements of archived months are generated before their logs are archived, except for the months
+	// a wallet has no logs in; those can be generated as long as the period starts after its newest
+	// archived log
+	if ledger.LastArchivedLogAt != nil && !start.After(*ledger.LastArchivedLogAt) {
+		return nil, fmt.Errorf("%w: %s", ErrStatementArchived, period)
+	}
+
+	statement := buildWalletStatement(wallet, period, start, end, ledger)
+	entries, err := json.Marshal(statement.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("encode statement entries: %w", err)
+	}
+
+	created, err := s.repo.CreateWalletStatement(ctx, &model.WalletStatement{
+		WalletID:       wallet.ID,
+		UserID:         wallet.UserID,
+		Currency:       wallet.Currency,
+		Period:         period,
+		PeriodStart:    start,
+		PeriodEnd:      end,
+		OpeningBalance: statement.OpeningBalance,
+		ClosingBalance: statement.ClosingBalance,
+		TotalCredits:   statement.TotalCredits,
+		TotalDebits:    statement.TotalDebits,
+		EntryCount:     len(statement.Entries),
+		Entries:        entries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if created == nil {
+		stored, err := s.repo.GetWalletStatement(ctx, wallet.ID, period)
+		if err != nil {
+			return nil, err
+		}
+		if stored == nil {
+			return nil, fmt.Errorf("statement of wallet_id=%d for %s vanished", wallet.ID, period)
+		}
+		return stored, nil
+	}
+
+	s.logger.Info("Wallet statement generated",
+		zap.Int("user_id", wallet.UserID),
+		zap.String("currency", wallet.Currency),
+		zap.String("period", period),
+		zap.Int("entries", created.EntryCount))
+	s.metrics.RecordWalletOperation("statement", "generated")
+
+	return created, nil
+}
+
+// statementHTMLTemplate renders a statement as a standalone HTML page
+var statementHTMLTemplate = template.Must(template.New("statement").Funcs(template.FuncMap{
+	"amount": func(value float64) string { return fmt.Sprintf("%.2f", value) },
+	"date":   func(value time.Time) string { return value.Format(time.DateOnly) },
+	"time":   func(value time.Time) string { return value.Format(time.DateTime) },
+	"last":   func(end time.Time) time.Time { return end.AddDate(0, 0, -1) },
+	"deref": func(value *string) string {
+		if value == nil {
+			return ""
+		}
+		return *value
+	},
+}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Wallet statement {{.Period}}</title>
+</head>
+<body>
+<h1>Wallet statement {{.Period}}</h1>
+<p>User {{.UserID}}, currency {{.Currency}}, {{date .PeriodStart}} to {{date (last .PeriodEnd)}} (UTC)</p>
+<table>
+<tr><th>Opening balance</th><td>{{amount .OpeningBalance}}</td></tr>
+<tr><th>Total credits</th><td>{{amount .TotalCredits}}</td></tr>
+<tr><th>Total debits</th><td>{{amount .TotalDebits}}</td></tr>
+<tr><th>Closing balance</th><td>{{amount .ClosingBalance}}</td></tr>
+</table>
+<table>
+<thead>
+<tr><th>Date</th><th>Operation</th><th>Game</th><th>Token type</th><th>Amount</th><th>Balance</th><th>Reference</th></tr>
+</thead>
+<tbody>
+{{- range .Entries}}
+<tr><td>{{time .CreatedAt}}</td><td>{{.Operation}}</td><td>{{deref .GameID}}</td><td>{{deref .TokenType}}</td><td>{{amount .PlatformAmount}}</td><td>{{amount .Balance}}</td><td>{{deref .ReferenceID}}</td></tr>
+{{- end}}
+</tbody>
+</table>
+<p>Generated {{time .GeneratedAt}} UTC</p>
+</body>
+</html>
+`))
+
+// RenderStatement writes a statement as plain text or HTML
+func RenderStatement(w io.Writer, statement *dto.WalletStatement, format string) error {
+	switch format {
+	case StatementFormatText:
+		return renderStatementText(w, statement)
+	case StatementFormatHTML:
+		return statementHTMLTemplate.Execute(w, statement)
+	default:
+		return fmt.Errorf("unknown statement format %q", format)
+	}
+}
+
+func renderStatementText(w io.Writer, statement *dto.WalletStatement) error {
+	optional := func(value *string) string {
+		if value == nil {
+			return "-"
+		}
+		return *value
+	}
+
+	fmt.Fprintf(w, "Wallet statement %s\n", statement.Period)
+	fmt.Fprintf(w, "User %d, currency %s, %s to %s (UTC)\n\n", statement.UserID, statement.Currency,
+		statement.PeriodStart.Format(time.DateOnly), statement.PeriodEnd.AddDate(0, 0, -1).Format(time.DateOnly))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "Opening balance\t%.2f\n", statement.OpeningBalance)
+	fmt.Fprintf(tw, "Total credits\t%.2f\n", statement.TotalCredits)
+	fmt.Fprintf(tw, "Total debits\t%.2f\n", statement.TotalDebits)
+	fmt.Fprintf(tw, "Closing balance\t%.2f\n", statement.ClosingBalance)
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	fmt.Fprintln(w)
+	tw = tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "Date\tOperation\tGame\tToken type\tAmount\tBalance\tReference")
+	for _, entry := range statement.Entries {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%.2f\t%.2f\t%s\n",
+			entry.CreatedAt.Format(time.DateTime), entry.Operation, optional(entry.GameID),
+			optional(entry.TokenType), entry.PlatformAmount, entry.Balance, optional(entry.ReferenceID))
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	_, err := fmt.Fprintf(w, "\nGenerated %s UTC\n", statement.GeneratedAt.Format(time.DateTime))
+	return err
+}
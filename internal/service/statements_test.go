@@ -0,0 +1,109 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseStatementPeriod(t *testing.T) {
+	start, end, err := parseStatementPeriod("2026-12")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), start)
+	assert.Equal(t, time.Date(2027, 1, 1, 0, 0, 0, 0, time.UTC), end)
+
+	for _, period := range []string{"", "2026-13", "2026-1", "2026-01-01", "latest"} {
+		t.Run(period, func(t *testing.T) {
+			_, _, err := parseStatementPeriod(period)
+			assert.True(t, errors.Is(err, ErrInvalidStatementPeriod))
+		})
+	}
+}
+
+func TestBuildWalletStatement(t *testing.T) {
+	wallet := &model.Wallet{ID: 1, UserID: 42, Currency: "platform"}
+	start, end, err := parseStatementPeriod("2026-09")
+	require.NoError(t, err)
+
+	gameID := "game-1"
+	ledger := &model.StatementLedger{
+		OpeningBalance: 100,
+		Logs: []*model.WalletLog{
+			{ID: 10, Amount: 150, PlatformAmount: 15.1, Source: model.TransactionExchange, GameID: &gameID},
+			{ID: 11, Amount: -40.2, PlatformAmount: -40.2, Source: "market_purchase"},
+			{ID: 12, Amount: 5, PlatformAmount: 5, Source: model.TransactionBonus},
+			{ID: 13, Amount: -5, PlatformAmount: -5, Source: model.TransactionExpiry},
+		},
+	}
+
+	statement := buildWalletStatement(wallet, "2026-09", start, end, ledger)
+
+	assert.Equal(t, 42, statement.UserID)
+	assert.Equal(t, 100.0, statement.OpeningBalance)
+	assert.Equal(t, 20.1, statement.TotalCredits)
+	assert.Equal(t, 45.2, statement.TotalDebits)
+	assert.Equal(t, 74.9, statement.ClosingBalance)
+
+	require.Len(t, statement.Entries, 4)
+	assert.Equal(t, []float64{115.1, 74.9, 79.9, 74.9}, []float64{
+		statement.Entries[0].Balance, statement.Entries[1].Balance,
+		statement.Entries[2].Balance, statement.Entries[3].Balance,
+	})
+	assert.Equal(t, []string{
+		model.TransactionExchange, model.TransactionSpend, model.TransactionBonus, model.TransactionExpiry,
+	}, []string{
+		statement.Entries[0].Operation, statement.Entries[1].Operation,
+		statement.Entries[2].Operation, statement.Entries[3].Operation,
+	})
+
+	t.Run("No Logs", func(t *testing.T) {
+		statement := buildWalletStatement(wallet, "2026-09", start, end, &model.StatementLedger{OpeningBalance: 12.5})
+		assert.Equal(t, 12.5, statement.ClosingBalance)
+		assert.NotNil(t, statement.Entries)
+		assert.Empty(t, statement.Entries)
+	})
+}
+
+func TestRenderStatement(t *testing.T) {
+	reference := "ORDER-<1>"
+	statement := &dto.WalletStatement{
+		UserID:         42,
+		Currency:       "platform",
+		Period:         "2026-09",
+		PeriodStart:    time.Date(2026, 9, 1, 0, 0, 0, 0, time.UTC),
+		PeriodEnd:      time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC),
+		OpeningBalance: 100,
+		TotalDebits:    40,
+		ClosingBalance: 60,
+		Entries: []dto.StatementEntry{
+			{Operation: model.TransactionSpend, PlatformAmount: -40, Balance: 60, ReferenceID: &reference,
+				CreatedAt: time.Date(2026, 9, 3, 12, 0, 0, 0, time.UTC)},
+		},
+	}
+
+	t.Run("Text", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, RenderStatement(&buf, statement, StatementFormatText))
+		assert.Contains(t, buf.String(), "2026-09-01 to 2026-09-30 (UTC)")
+		assert.Contains(t, buf.String(), "Closing balance  60.00")
+		assert.Contains(t, buf.String(), "2026-09-03 12:00:00  spend")
+	})
+
+	t.Run("HTML Escapes Values", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, RenderStatement(&buf, statement, StatementFormatHTML))
+		assert.Contains(t, buf.String(), "ORDER-&lt;1&gt;")
+		assert.Contains(t, buf.String(), "<td>-40.00</td><td>60.00</td>")
+	})
+
+	t.Run("Unknown Format", func(t *testing.T) {
+		assert.Error(t, RenderStatement(&bytes.Buffer{}, statement, "pdf"))
+	})
+}
@@ -0,0 +1,271 @@
+package service
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/observability/metrics"
+	"github.com/playconomy/wallet-service/internal/observability/tracing"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// Wallet stream event names
+const (
+	StreamEventWalletLog = "wallet_log"
+	StreamEventBalance   = "balance"
+)
+
+// errStreamClosed is returned by stream writes once the client has gone away
+var errStreamClosed = errors.New("stream closed by client")
+
+// StreamService pushes a user's new wallet logs and balances as Server-Sent Events
+type StreamService struct {
+	repo    repository.StreamRepository
+	broker  *WalletEventBroker
+	stream  config.StreamConfig
+	logger  *zap.Logger
+	metrics *metrics.Metrics
+	tracer  *tracing.Tracer
+}
+
+// Compile-time verification that StreamService implements StreamServiceInterface
+var _ StreamServiceInterface = (*StreamService)(nil)
+
+// NewStreamService creates a new wallet stream service
+func NewStreamService(repo repository.StreamRepository, broker *WalletEventBroker, cfg *config.Config,
+	obs *observability.Observability) *StreamService {
+	return &StreamService{
+		repo:    repo,
+		broker:  broker,
+		stream:  cfg.Stream,
+		logger:  obs.Logger.Logger.With(zap.String("component", "stream_service")),
+		metrics: obs.Metrics,
+		tracer:  obs.Tracer,
+	}
+}
+
+// ValidStreamCursor reports whether an event ID sent back by a client is a stream cursor: a database snapshot
+// written as xmin:xmax:xip,... with the transactions still running at the time, in order, from xmin to xmax
+func ValidStreamCursor(cursor string) bool {
+	parts := strings.Split(cursor, ":")
+	if len(parts) != 3 {
+		return false
+	}
+
+	xmin, err := strconv.ParseUint(parts[0], 10, 64)
+	if err != nil || xmin == 0 {
+		return false
+	}
+	xmax, err := strconv.ParseUint(parts[1], 10, 64)
+	if err != nil || xmax < xmin {
+		return false
+	}
+
+	if parts[2] == "" {
+		return true
+	}
+	last := xmin
+	for i, value := range strings.Split(parts[2], ",") {
+		xip, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || xip < last || xip >= xmax || (i > 0 && xip == last) {
+			return false
+		}
+		last = xip
+	}
+
+	return true
+}
+
+// writeStreamEvent writes one Server-Sent Event with a JSON payload; an empty id leaves the event ID unset
+func writeStreamEvent(w io.Writer, id string, event string, data interface{}) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("encode %s event: %w", event, err)
+	}
+
+	if id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", id); err != nil {
+			return errStreamClosed
+		}
+	}
+	if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload); err != nil {
+		return errStreamClosed
+	}
+
+	return nil
+}
+
+// StreamWalletEvents writes a user's wallet events to w until the client goes away or the service stops.
+// A new stream (empty lastEventID) starts with the current balances; a resumed stream first replays the logs
+// committed since lastEventID. Every batch of logs is followed by the new balances of their currencies.
+//
+// Log IDs are taken when a log is inserted, so logs commit out of ID order and an ID cannot tell which logs
+// a client has seen. The stream's position is a database snapshot instead: each batch holds the logs
+// committed since the previous snapshot, and only its last log carries the new snapshot as event ID. A client
+// that reconnects in the middle of a batch receives its first logs again.
+func (s *StreamService) StreamWalletEvents(ctx context.Context, userID int, lastEventID string, w *bufio.Writer) error {
+	events, unsubscribe := s.broker.Subscribe(userID)
+	defer unsubscribe()
+
+	s.metrics.AddOpenStreams(1)
+	defer s.metrics.AddOpenStreams(-1)
+
+	s.logger.Debug("Wallet stream opened",
+		zap.Int("user_id", userID),
+		zap.String("last_event_id", lastEventID))
+
+	err := s.streamWalletEvents(ctx, userID, lastEventID, events, w)
+	if errors.Is(err, errStreamClosed) {
+		s.logger.Debug("Wallet stream closed by client", zap.Int("user_id", userID))
+		return nil
+	}
+
+	return err
+}
+
+func (s *StreamService) streamWalletEvents(ctx context.Context, userID int, lastEventID string,
+	events <-chan struct{}, w *bufio.Writer) error {
+
+	if _, err := fmt.Fprintf(w, "retry: %d\n\n", s.stream.RetryDelay.Milliseconds()); err != nil {
+		return errStreamClosed
+	}
+
+	cursor := lastEventID
+	if cursor == "" {
+		var err error
+		if cursor, err = s.repo.GetCurrentSnapshot(ctx); err != nil {
+			return err
+		}
+		if err := s.sendBalances(ctx, w, userID, nil); err != nil {
+			return err
+		}
+	} else {
+		var err error
+		if cursor, err = s.sendWalletLogs(ctx, w, userID, cursor); err != nil {
+			return err
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		return errStreamClosed
+	}
+
+	heartbeat := time.NewTicker(s.stream.HeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case _, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			var err error
+			if cursor, err = s.sendWalletLogs(ctx, w, userID, cursor); err != nil {
+				return err
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return errStreamClosed
+			}
+		}
+
+		if err := w.Flush(); err != nil {
+			return errStreamClosed
+		}
+	}
+}
+
+// sendWalletLogs writes the logs of a user committed since snapshot since, followed by the balances of their
+// currencies, and returns the snapshot the logs were read up to
+func (s *StreamService) sendWalletLogs(ctx context.Context, w io.Writer, userID int, since string) (string, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "StreamService.SendWalletLogs",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("since", since),
+		))
+	defer span.End()
+
+	until, err := s.repo.GetCurrentSnapshot(ctx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("stream", "error")
+		return since, err
+	}
+
+	// Each log is written once the next one is read, so the last one can carry the new snapshot
+	var pending *dto.WalletStreamLog
+	var afterID int64
+	currencies := make(map[string]bool)
+	for {
+		logs, err := s.repo.ListWalletLogsBetween(ctx, userID, since, until, afterID, s.stream.ReplayLimit)
+		if err != nil {
+			s.metrics.RecordWalletOperation("stream", "error")
+			return since, err
+		}
+
+		for _, log := range logs {
+			if pending != nil {
+				if err := writeStreamEvent(w, "", StreamEventWalletLog, pending); err != nil {
+					return since, err
+				}
+			}
+			pending = &dto.WalletStreamLog{ID: log.ID, WalletLogEntry: toWalletLogEntry(log)}
+			afterID = log.ID
+			currencies[log.Currency] = true
+		}
+
+		if len(logs) < s.stream.ReplayLimit {
+			break
+		}
+	}
+
+	if pending == nil {
+		return until, nil
+	}
+
+	if err := writeStreamEvent(w, until, StreamEventWalletLog, pending); err != nil {
+		return since, err
+	}
+
+	return until, s.sendBalances(ctx, w, userID, currencies)
+}
+
+// sendBalances writes the current balance of each of a user's wallets in currencies, or of all of them
+// when currencies is nil. The balances are read from the primary database, as a replica may not have
+// replayed the logs just sent yet.
+func (s *StreamService) sendBalances(ctx context.Context, w io.Writer, userID int, currencies map[string]bool) error {
+	wallets, err := s.repo.GetWalletBalances(ctx, userID)
+	if err != nil {
+		s.metrics.RecordWalletOperation("stream", "error")
+		return err
+	}
+
+	for _, wallet := range wallets {
+		if currencies != nil && !currencies[wallet.Currency] {
+			continue
+		}
+
+		balance := dto.CurrencyBalance{Currency: wallet.Currency, Balance: wallet.Balance}
+		if err := writeStreamEvent(w, "", StreamEventBalance, balance); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,167 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/config"
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriteStreamEvent(t *testing.T) {
+	t.Run("With ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeStreamEvent(&buf, "740:745:742", StreamEventWalletLog, map[string]int{"id": 42}))
+		assert.Equal(t, "id: 740:745:742\nevent: wallet_log\ndata: {\"id\":42}\n\n", buf.String())
+	})
+
+	t.Run("Without ID", func(t *testing.T) {
+		var buf bytes.Buffer
+		balance := dto.CurrencyBalance{Currency: "platform", Balance: 10.5}
+		require.NoError(t, writeStreamEvent(&buf, "", StreamEventBalance, balance))
+		assert.Equal(t, "event: balance\ndata: {\"currency\":\"platform\",\"balance\":10.5}\n\n", buf.String())
+	})
+}
+
+func TestValidStreamCursor(t *testing.T) {
+	for _, cursor := range []string{"740:740:", "740:745:", "740:745:740,742,744"} {
+		assert.True(t, ValidStreamCursor(cursor), cursor)
+	}
+
+	for _, cursor := range []string{"", "1042", "0:5:", "745:740:", "740:745:745", "740:745:743,742",
+		"740:745:742,742", "740:745:x", "740:745:742:1"} {
+		assert.False(t, ValidStreamCursor(cursor), cursor)
+	}
+}
+
+func newTestBroker() *WalletEventBroker {
+	return &WalletEventBroker{subscribers: make(map[int]map[chan struct{}]struct{})}
+}
+
+// woken reports whether a subscription has a pending signal, consuming it
+func woken(ch <-chan struct{}) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
+
+func TestWalletEventBroker(t *testing.T) {
+	t.Run("Dispatches To Subscribers Of The User", func(t *testing.T) {
+		broker := newTestBroker()
+		first, unsubscribeFirst := broker.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := broker.Subscribe(1)
+		defer unsubscribeSecond()
+		other, unsubscribeOther := broker.Subscribe(2)
+		defer unsubscribeOther()
+
+		broker.dispatch(&model.WalletEvent{LogID: 10, UserID: 1})
+
+		assert.True(t, woken(first))
+		assert.True(t, woken(second))
+		assert.False(t, woken(other))
+	})
+
+	t.Run("Coalesces Pending Signals", func(t *testing.T) {
+		broker := newTestBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		defer unsubscribe()
+
+		broker.dispatch(&model.WalletEvent{LogID: 10, UserID: 1})
+		broker.dispatch(&model.WalletEvent{LogID: 11, UserID: 1})
+
+		assert.True(t, woken(events))
+		assert.False(t, woken(events))
+	})
+
+	t.Run("Nil Event Wakes Everyone", func(t *testing.T) {
+		broker := newTestBroker()
+		first, unsubscribeFirst := broker.Subscribe(1)
+		defer unsubscribeFirst()
+		second, unsubscribeSecond := broker.Subscribe(2)
+		defer unsubscribeSecond()
+
+		broker.dispatch(nil)
+
+		assert.True(t, woken(first))
+		assert.True(t, woken(second))
+	})
+
+	t.Run("Unsubscribe Closes The Subscription", func(t *testing.T) {
+		broker := newTestBroker()
+		events, unsubscribe := broker.Subscribe(1)
+		unsubscribe()
+		unsubscribe()
+
+		_, open := <-events
+		assert.False(t, open)
+		assert.Empty(t, broker.subscribers)
+	})
+
+	t.Run("Close Streams Ends All Subscriptions", func(t *testing.T) {
+		broker := newTestBroker()
+		events, unsubscribe := broker.Subscribe(1)
+
+		broker.CloseStreams()
+		unsubscribe()
+
+		_, open := <-events
+		assert.False(t, open)
+
+		late, _ := broker.Subscribe(2)
+		_, open = <-late
+		assert.False(t, open)
+	})
+}
+
+func TestSendWalletLogs(t *testing.T) {
+	repo := &stubStreamRepository{
+		snapshot: "750:750:",
+		logs: []*model.WalletLog{
+			{ID: 40, Currency: "gems", Source: "exchange", PlatformAmount: 5, CreatedAt: time.Now()},
+			{ID: 41, Currency: "platform", Source: "exchange", PlatformAmount: 10, CreatedAt: time.Now()},
+			{ID: 43, Currency: "platform", Source: "market_purchase", PlatformAmount: -3, CreatedAt: time.Now()},
+		},
+		wallets: []*model.Wallet{
+			{ID: 1, Currency: "platform", Balance: 7},
+			{ID: 2, Currency: "gems", Balance: 5},
+		},
+	}
+
+	obs := observability.NewTestObservability()
+	cfg := &config.Config{Stream: config.StreamConfig{ReplayLimit: 2}}
+	service := NewStreamService(repo, nil, cfg, obs)
+
+	t.Run("Last Log Carries The Snapshot", func(t *testing.T) {
+		var buf bytes.Buffer
+		cursor, err := service.sendWalletLogs(context.Background(), &buf, 1, "740:745:742")
+		require.NoError(t, err)
+		assert.Equal(t, "750:750:", cursor)
+
+		output := buf.String()
+		assert.Equal(t, 3, strings.Count(output, "event: "+StreamEventWalletLog))
+		assert.Equal(t, 2, strings.Count(output, "event: "+StreamEventBalance))
+		assert.Equal(t, 1, strings.Count(output, "id: "))
+		assert.Contains(t, output, "id: 750:750:\nevent: wallet_log\ndata: {\"id\":43,")
+	})
+
+	t.Run("No Logs", func(t *testing.T) {
+		repo.logs = nil
+		var buf bytes.Buffer
+		cursor, err := service.sendWalletLogs(context.Background(), &buf, 1, "740:745:742")
+		require.NoError(t, err)
+		assert.Equal(t, "750:750:", cursor)
+		assert.Empty(t, buf.String())
+	})
+}
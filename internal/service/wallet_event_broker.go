@@ -0,0 +1,165 @@
+package service
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/repository"
+
+	"go.uber.org/fx"
+	"go.uber.org/zap"
+)
+
+// listenRetryDelay is the wait before listening for wallet events again after the listener failed
+const listenRetryDelay = 5 * time.Second
+
+// WalletEventBroker fans the wallet events received on this instance's single notification connection
+// out to the streams of the users they belong to. Subscribers are only woken up, not handed the events,
+// so a slow stream never blocks the others: it reads everything it missed from the database.
+type WalletEventBroker struct {
+	listener    repository.WalletEventListener
+	logger      *zap.Logger
+	mu          sync.Mutex
+	subscribers map[int]map[chan struct{}]struct{}
+	stopped     bool
+	cancel      context.CancelFunc
+	done        chan struct{}
+}
+
+// NewWalletEventBroker creates a broker and registers its lifecycle hooks. Stopping the broker closes
+// every subscription, which ends the open streams.
+func NewWalletEventBroker(lc fx.Lifecycle, listener repository.WalletEventListener,
+	obs *observability.Observability) *WalletEventBroker {
+
+	broker := &WalletEventBroker{
+		listener:    listener,
+		logger:      obs.Logger.Logger.With(zap.String("component", "wallet_event_broker")),
+		subscribers: make(map[int]map[chan struct{}]struct{}),
+	}
+
+	lc.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			broker.logger.Info("Starting wallet event broker")
+
+			runCtx, cancel := context.WithCancel(context.Background())
+			broker.cancel = cancel
+			broker.done = make(chan struct{})
+			go broker.loop(runCtx)
+			return nil
+		},
+		OnStop: func(ctx context.Context) error {
+			broker.logger.Info("Stopping wallet event broker")
+			broker.cancel()
+			broker.CloseStreams()
+
+			select {
+			case <-broker.done:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		},
+	})
+
+	return broker
+}
+
+func (b *WalletEventBroker) loop(ctx context.Context) {
+	defer close(b.done)
+
+	for {
+		err := b.listener.Listen(ctx, b.dispatch)
+		if ctx.Err() != nil {
+			return
+		}
+
+		b.logger.Error("Listening for wallet events failed", zap.Error(err))
+		// Events may have been missed while no connection was listening
+		b.dispatch(nil)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(listenRetryDelay):
+		}
+	}
+}
+
+// Subscribe registers a stream for the events of a user. The returned channel receives a value when the
+// user may have new wallet logs and is closed when the broker stops; the returned function ends the
+// subscription.
+func (b *WalletEventBroker) Subscribe(userID int) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.stopped {
+		close(ch)
+		return ch, func() {}
+	}
+
+	if b.subscribers[userID] == nil {
+		b.subscribers[userID] = make(map[chan struct{}]struct{})
+	}
+	b.subscribers[userID][ch] = struct{}{}
+
+	return ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		if _, ok := b.subscribers[userID][ch]; !ok {
+			return
+		}
+		delete(b.subscribers[userID], ch)
+		if len(b.subscribers[userID]) == 0 {
+			delete(b.subscribers, userID)
+		}
+		close(ch)
+	}
+}
+
+// dispatch wakes the subscribers of the event's user, or every subscriber when event is nil
+func (b *WalletEventBroker) dispatch(event *model.WalletEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if event != nil {
+		for ch := range b.subscribers[event.UserID] {
+			wake(ch)
+		}
+		return
+	}
+
+	for _, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			wake(ch)
+		}
+	}
+}
+
+// wake signals a subscriber without blocking; a pending signal already covers the new event
+func wake(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
+
+// CloseStreams closes every subscription, which ends the open streams, and refuses new ones. The server calls
+// it before shutting down, since it waits for the connections of the streams to close.
+func (b *WalletEventBroker) CloseStreams() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.stopped = true
+	for userID, subscribers := range b.subscribers {
+		for ch := range subscribers {
+			close(ch)
+		}
+		delete(b.subscribers, userID)
+	}
+}
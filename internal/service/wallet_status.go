@@ -0,0 +1,182 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/playconomy/wallet-service/internal/model"
+	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/server/dto"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+// walletStatusTransitions lists the statuses each wallet status may move to. Closed is terminal.
+var walletStatusTransitions = map[string][]string{
+	model.WalletStatusActive: {model.WalletStatusFrozen, model.WalletStatusClosed},
+	model.WalletStatusFrozen: {model.WalletStatusActive, model.WalletStatusClosed},
+}
+
+// canTransitionWalletStatus reports whether a wallet may move from one status to another
+func canTransitionWalletStatus(from, to string) bool {
+	for _, allowed := range walletStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// checkWalletMutable returns a domain error if the wallet's status forbids balance changes.
+// Every operation that mutates a wallet must call it after locking the wallet row.
+func checkWalletMutable(wallet *model.Wallet) error {
+	switch wallet.Status {
+	case model.WalletStatusFrozen:
+		return ErrWalletFrozen
+	case model.WalletStatusClosed:
+		return ErrWalletClosed
+	default:
+		return nil
+	}
+}
+
+// changeWalletStatus moves all currency wallets of a user to a new status and records the transition.
+// The caller must hold the lock on every wallet of the user; wallet is the oldest of them.
+// changedBy is nil for changes made by the system, such as reconciliation.
+func changeWalletStatus(ctx context.Context, repo repository.WalletRepository, wallet *model.Wallet,
+	status, reason string, changedBy *int, tx repository.Transaction) (*model.Wallet, error) {
+
+	if !canTransitionWalletStatus(wallet.Status, status) {
+		return nil, fmt.Errorf("%w: %s to %s", ErrInvalidStatusTransition, wallet.Status, status)
+	}
+
+	updated, err := repo.UpdateWalletStatus(ctx, wallet.UserID, status, reason, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated == nil {
+		return nil, fmt.Errorf("%w for user_id=%d", ErrWalletNotFound, wallet.UserID)
+	}
+
+	_, err = repo.CreateWalletStatusChange(ctx, &model.WalletStatusChange{
+		WalletID:   wallet.ID,
+		UserID:     wallet.UserID,
+		FromStatus: wallet.Status,
+		ToStatus:   status,
+		Reason:     reason,
+		ChangedBy:  changedBy,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// UpdateWalletStatus changes the status of a user's wallet on behalf of an admin
+func (s *WalletService) UpdateWalletStatus(ctx context.Context, userID int,
+	req *dto.UpdateWalletStatusRequest, changedBy int) (*dto.Wallet, error) {
+
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.UpdateWalletStatus",
+		trace.WithAttributes(
+			attribute.Int("user_id", userID),
+			attribute.String("status", req.Status),
+			attribute.Int("changed_by", changedBy),
+		))
+	defer span.End()
+
+	s.logger.Info("Processing wallet status change",
+		zap.Int("user_id", userID),
+		zap.String("status", req.Status),
+		zap.String("reason", req.Reason),
+		zap.Int("changed_by", changedBy))
+
+	tx, err := s.repo.BeginTx(ctx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("status_change", "error_transaction")
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// The status is shared by all currency wallets of the user, so all of them are locked
+	wallets, err := s.repo.GetWalletsByUserIDForUpdate(ctx, userID, tx)
+	if err != nil {
+		s.metrics.RecordWalletOperation("status_change", "error_wallet_fetch")
+		return nil, err
+	}
+
+	if len(wallets) == 0 {
+		s.metrics.RecordWalletOperation("status_change", "error_wallet_not_found")
+		return nil, fmt.Errorf("%w for user_id=%d", ErrWalletNotFound, userID)
+	}
+	wallet := wallets[0]
+
+	updated, err := changeWalletStatus(ctx, s.repo, wallet, req.Status, req.Reason, &changedBy, tx)
+	if err != nil {
+		s.logger.Warn("Wallet status change failed",
+			zap.Int("user_id", userID),
+			zap.String("from_status", wallet.Status),
+			zap.String("to_status", req.Status),
+			zap.Error(err))
+		s.metrics.RecordWalletOperation("status_change", "error_update")
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		s.metrics.RecordWalletOperation("status_change", "error_commit")
+		return nil, err
+	}
+
+	s.logger.Info("Wallet status changed",
+		zap.Int("user_id", userID),
+		zap.String("from_status", wallet.Status),
+		zap.String("to_status", updated.Status),
+		zap.Int("changed_by", changedBy))
+	s.metrics.RecordWalletOperation("status_change", "success")
+
+	return toWalletDTO(updated), nil
+}
+
+// GetWalletStatusHistory retrieves the status changes of a user's wallet, newest first
+func (s *WalletService) GetWalletStatusHistory(ctx context.Context, userID int) ([]dto.WalletStatusChange, error) {
+	ctx, span := s.tracer.StartSpan(ctx, "WalletService.GetWalletStatusHistory",
+		trace.WithAttributes(attribute.Int("user_id", userID)))
+	defer span.End()
+
+	changes, err := s.repo.GetWalletStatusChanges(ctx, userID)
+	if err != nil {
+		s.logger.Error("Error retrieving wallet status history",
+			zap.Int("user_id", userID),
+			zap.Error(err))
+		return nil, err
+	}
+
+	result := make([]dto.WalletStatusChange, len(changes))
+	for i, change := range changes {
+		result[i] = dto.WalletStatusChange{
+			FromStatus: change.FromStatus,
+			ToStatus:   change.ToStatus,
+			Reason:     change.Reason,
+			ChangedBy:  change.ChangedBy,
+			CreatedAt:  change.CreatedAt,
+		}
+	}
+
+	return result, nil
+}
+
+// toWalletDTO converts a wallet model to its API representation
+func toWalletDTO(wallet *model.Wallet) *dto.Wallet {
+	return &dto.Wallet{
+		ID:           int(wallet.ID),
+		UserID:       wallet.UserID,
+		Currency:     wallet.Currency,
+		Balance:      wallet.Balance,
+		Status:       wallet.Status,
+		StatusReason: wallet.StatusReason,
+		CreatedAt:    wallet.CreatedAt,
+	}
+}
@@ -0,0 +1,37 @@
+package service
+
+import (
+	"testing"
+
+	"github.com/playconomy/wallet-service/internal/model"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCanTransitionWalletStatus(t *testing.T) {
+	testCases := []struct {
+		from     string
+		to       string
+		expected bool
+	}{
+		{model.WalletStatusActive, model.WalletStatusFrozen, true},
+		{model.WalletStatusActive, model.WalletStatusClosed, true},
+		{model.WalletStatusFrozen, model.WalletStatusActive, true},
+		{model.WalletStatusFrozen, model.WalletStatusClosed, true},
+		{model.WalletStatusActive, model.WalletStatusActive, false},
+		{model.WalletStatusClosed, model.WalletStatusActive, false},
+		{model.WalletStatusClosed, model.WalletStatusFrozen, false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.from+" to "+tc.to, func(t *testing.T) {
+			assert.Equal(t, tc.expected, canTransitionWalletStatus(tc.from, tc.to))
+		})
+	}
+}
+
+func TestCheckWalletMutable(t *testing.T) {
+	assert.NoError(t, checkWalletMutable(&model.Wallet{Status: model.WalletStatusActive}))
+	assert.ErrorIs(t, checkWalletMutable(&model.Wallet{Status: model.WalletStatusFrozen}), ErrWalletFrozen)
+	assert.ErrorIs(t, checkWalletMutable(&model.Wallet{Status: model.WalletStatusClosed}), ErrWalletClosed)
+}
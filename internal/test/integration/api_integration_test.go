@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"testing"
 
+	"github.com/playconomy/wallet-service/internal/config"
 	"github.com/playconomy/wallet-service/internal/server/dto"
 	"github.com/playconomy/wallet-service/internal/server/handler"
 	"github.com/playconomy/wallet-service/internal/server/middleware"
@@ -16,6 +17,7 @@ import (
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/fx/fxtest"
 )
 
 func setupTestApp(t *testing.T) *fiber.App {
@@ -26,8 +28,10 @@ func setupTestApp(t *testing.T) *fiber.App {
 	obs := service.GetTestObservability()
 	
 	// Create service and handler with interfaces
-	var walletService service.WalletServiceInterface = service.NewWalletService(testRepo, obs)
-	var walletHandler handler.WalletHandlerInterface = handler.NewWalletHandler(walletService, obs)
+	var walletService service.WalletServiceInterface = service.NewWalletService(testRepo, nil, nil, nil, &config.Config{}, obs)
+	featureFlags, err := service.NewFeatureFlagService(fxtest.NewLifecycle(t), nil, &config.Config{}, obs)
+	require.NoError(t, err)
+	var walletHandler handler.WalletHandlerInterface = handler.NewWalletHandler(walletService, featureFlags, obs)
 
 	// Setup test routes similar to actual app
 	api := app.Group("/", middleware.AuthMiddleware())
@@ -0,0 +1,507 @@
+// Package integration provides integration test utilities and tests for the wallet service
+package integration
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/ory/dockertest/v3"
+	"github.com/ory/dockertest/v3/docker"
+	"github.com/playconomy/wallet-service/internal/observability"
+	"github.com/playconomy/wallet-service/internal/repository"
+)
+
+var (
+	db       *sql.DB
+	dbName   = "testdb"
+	user     = "postgres"
+	password = "postgres"
+	port     = "5433" // Different from default to avoid conflicts
+)
+
+// TestMain prepares the test environment for integration tests, creating a temporary Postgres
+// database in a Docker container.
+func TestMain(m *testing.M) {
+	// The tests skip themselves in short mode, so no container is needed
+	flag.Parse()
+	if testing.Short() {
+		os.Exit(m.Run())
+	}
+
+	// Use a sensible default on Windows (tcp/http) and linux/osx (socket)
+	pool, err := dockertest.NewPool("")
+	if err != nil {
+		log.Fatalf("Could not connect to docker: %s", err)
+	}
+
+	// Pull and create postgres container
+	resource, err := pool.RunWithOptions(&dockertest.RunOptions{
+		Repository: "postgres",
+		Tag:        "14",
+		Env: []string{
+			fmt.Sprintf("POSTGRES_PASSWORD=%s", password),
+			fmt.Sprintf("POSTGRES_USER=%s", user),
+			fmt.Sprintf("POSTGRES_DB=%s", dbName),
+		},
+		ExposedPorts: []string{"5432/tcp"},
+		PortBindings: map[docker.Port][]docker.PortBinding{
+			"5432/tcp": {
+				{HostIP: "0.0.0.0", HostPort: port},
+			},
+		},
+	}, func(config *docker.HostConfig) {
+		// set AutoRemove to true so that stopped container goes away by itself
+		config.AutoRemove = true
+		config.RestartPolicy = docker.RestartPolicy{
+			Name: "no",
+		}
+	})
+	if err != nil {
+		log.Fatalf("Could not start resource: %s", err)
+	}
+
+	// Set cleanup function
+	defer func() {
+		if err := pool.Purge(resource); err != nil {
+			log.Fatalf("Could not purge resource: %s", err)
+		}
+	}()
+
+	// Exponential backoff-retry for the database to be ready
+	if err := pool.Retry(func() error {
+		var err error
+		db, err = sql.Open("postgres", fmt.Sprintf("postgres://%s:%s@localhost:%s/%s?sslmode=disable",
+			user, password, port, dbName))
+		if err != nil {
+			return err
+		}
+		return db.Ping()
+	}); err != nil {
+		log.Fatalf("Could not connect to database: %s", err)
+	}
+
+	// Run migrations
+	if err := runMigrations(); err != nil {
+		log.Fatalf("Could not run migrations: %s", err)
+	}
+
+	// Run tests
+	code := m.Run()
+
+	// Clean up
+	os.Exit(code)
+}
+
+// runMigrations applies database migrations needed for tests
+func runMigrations() error {
+	// Create wallets table
+	_, err := db.Exec(`
+		CREATE TABLE wallets (
+			id SERIAL PRIMARY KEY,
+			user_id INT NOT NULL,
+			currency VARCHAR(32) NOT NULL DEFAULT 'platform',
+			balance NUMERIC(20, 2) NOT NULL DEFAULT 0,
+			status VARCHAR(20) NOT NULL DEFAULT 'active',
+			status_reason TEXT,
+			status_changed_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(user_id, currency)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create exchange_rates table
+	_, err = db.Exec(`
+		CREATE TABLE exchange_rates (
+			id SERIAL PRIMARY KEY,
+			game_id VARCHAR(50) NOT NULL,
+			token_type VARCHAR(20) NOT NULL,
+			currency VARCHAR(32) NOT NULL DEFAULT 'platform',
+			to_platform_ratio NUMERIC(10, 4) NOT NULL,
+			from_platform_ratio NUMERIC(10, 4),
+			max_per_transaction NUMERIC(20, 2),
+			user_daily_cap NUMERIC(20, 2),
+			game_daily_cap NUMERIC(20, 2),
+			reverse_max_per_transaction NUMERIC(20, 2),
+			reverse_user_daily_cap NUMERIC(20, 2),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE(game_id, token_type, currency)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create wallet_logs table, partitioned like the migrated one, and the archived balances of its logs
+	_, err = db.Exec(`
+		CREATE TABLE wallet_logs (
+			id BIGSERIAL,
+			wallet_id INT NOT NULL,
+			user_id INT NOT NULL,
+			currency VARCHAR(32) NOT NULL DEFAULT 'platform',
+			game_id VARCHAR(50),
+			token_type VARCHAR(20),
+			amount NUMERIC(20, 2) NOT NULL,
+			platform_amount NUMERIC(20, 2) NOT NULL,
+			source VARCHAR(30) NOT NULL,
+			reference_id VARCHAR(50),
+			created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			xact_id xid8 DEFAULT pg_current_xact_id(),
+			PRIMARY KEY (id, created_at),
+			FOREIGN KEY (wallet_id) REFERENCES wallets(id)
+		) PARTITION BY RANGE (created_at);
+
+		CREATE TABLE wallet_logs_default PARTITION OF wallet_logs DEFAULT;
+
+		CREATE TABLE wallet_log_archive_balances (
+			wallet_id INT PRIMARY KEY REFERENCES wallets(id),
+			user_id INT NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			balance NUMERIC(20, 2) NOT NULL,
+			log_count INT NOT NULL,
+			last_log_at TIMESTAMP NOT NULL,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create wallet_balance_lots table
+	_, err = db.Exec(`
+		CREATE TABLE wallet_balance_lots (
+			id SERIAL PRIMARY KEY,
+			wallet_id INT NOT NULL REFERENCES wallets(id),
+			amount NUMERIC(20, 2) NOT NULL,
+			remaining NUMERIC(20, 2) NOT NULL,
+			source VARCHAR(30) NOT NULL,
+			reference_id VARCHAR(50),
+			expires_at TIMESTAMP,
+			expired_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create reverse_exchange_grants table
+	_, err = db.Exec(`
+		CREATE TABLE reverse_exchange_grants (
+			id SERIAL PRIMARY KEY,
+			grant_id VARCHAR(64) UNIQUE NOT NULL,
+			wallet_id INT NOT NULL REFERENCES wallets(id),
+			user_id INT NOT NULL,
+			game_id VARCHAR(50) NOT NULL,
+			token_type VARCHAR(20) NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			platform_amount NUMERIC(20, 2) NOT NULL,
+			game_amount NUMERIC(20, 2) NOT NULL,
+			expires_at TIMESTAMP NOT NULL,
+			redeemed_at TIMESTAMP,
+			redeemed_by INT,
+			refunded_at TIMESTAMP,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create idempotency_keys table
+	_, err = db.Exec(`
+		CREATE TABLE idempotency_keys (
+			key VARCHAR(64) PRIMARY KEY,
+			user_id INT NOT NULL,
+			operation VARCHAR(20) NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			amount NUMERIC(20, 2) NOT NULL,
+			new_balance NUMERIC(20, 2),
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create bulk_jobs table
+	_, err = db.Exec(`
+		CREATE TABLE bulk_jobs (
+			id SERIAL PRIMARY KEY,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			total_items INT NOT NULL,
+			succeeded INT NOT NULL DEFAULT 0,
+			failed INT NOT NULL DEFAULT 0,
+			duplicates INT NOT NULL DEFAULT 0,
+			request JSONB NOT NULL,
+			results JSONB,
+			error TEXT,
+			requested_by INT NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			started_at TIMESTAMP,
+			finished_at TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create jobs table
+	_, err = db.Exec(`
+		CREATE TABLE jobs (
+			id BIGSERIAL PRIMARY KEY,
+			kind VARCHAR(50) NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'queued',
+			payload JSONB NOT NULL,
+			result JSONB,
+			error TEXT,
+			attempts INT NOT NULL DEFAULT 0,
+			max_attempts INT NOT NULL,
+			run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+			locked_by VARCHAR(100),
+			locked_until TIMESTAMP,
+			heartbeat_at TIMESTAMP,
+			cancel_requested BOOLEAN NOT NULL DEFAULT FALSE,
+			created_by INT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			started_at TIMESTAMP,
+			finished_at TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create wallet_statements table
+	_, err = db.Exec(`
+		CREATE TABLE wallet_statements (
+			id SERIAL PRIMARY KEY,
+			wallet_id INT NOT NULL REFERENCES wallets(id),
+			user_id INT NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			period CHAR(7) NOT NULL,
+			period_start TIMESTAMP NOT NULL,
+			period_end TIMESTAMP NOT NULL,
+			opening_balance NUMERIC(20, 2) NOT NULL,
+			closing_balance NUMERIC(20, 2) NOT NULL,
+			total_credits NUMERIC(20, 2) NOT NULL,
+			total_debits NUMERIC(20, 2) NOT NULL,
+			entry_count INT NOT NULL,
+			entries JSONB NOT NULL,
+			generated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (wallet_id, period)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create wallet_balance_snapshots table
+	_, err = db.Exec(`
+		CREATE TABLE wallet_balance_snapshots (
+			id SERIAL PRIMARY KEY,
+			wallet_id INT NOT NULL REFERENCES wallets(id),
+			user_id INT NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			balance NUMERIC(20, 2) NOT NULL,
+			taken_at TIMESTAMP NOT NULL,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+			UNIQUE (wallet_id, taken_at)
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create report rollup tables
+	_, err = db.Exec(`
+		CREATE TABLE report_daily_activity (
+			day DATE NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			source VARCHAR(30) NOT NULL,
+			game_id VARCHAR(50) NOT NULL DEFAULT '',
+			token_type VARCHAR(20) NOT NULL DEFAULT '',
+			log_count INT NOT NULL,
+			amount NUMERIC(20, 2) NOT NULL,
+			platform_credits NUMERIC(20, 2) NOT NULL,
+			platform_debits NUMERIC(20, 2) NOT NULL,
+			PRIMARY KEY (day, currency, source, game_id, token_type)
+		);
+
+		CREATE TABLE report_daily_active_wallets (
+			day DATE NOT NULL,
+			currency VARCHAR(32) NOT NULL,
+			wallet_id INT NOT NULL,
+			PRIMARY KEY (day, currency, wallet_id)
+		);
+
+		CREATE TABLE report_rollup_state (
+			name VARCHAR(50) PRIMARY KEY,
+			last_log_id INT NOT NULL DEFAULT 0,
+			last_xact_id xid8 NOT NULL DEFAULT '0',
+			last_log_at TIMESTAMP,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+
+		INSERT INTO report_rollup_state (name) VALUES ('wallet_logs');
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create admin_audit_log table
+	_, err = db.Exec(`
+		CREATE TABLE admin_audit_log (
+			id BIGSERIAL PRIMARY KEY,
+			principal_id INT NOT NULL,
+			principal_role VARCHAR(20) NOT NULL,
+			action VARCHAR(150) NOT NULL,
+			method VARCHAR(10) NOT NULL,
+			path TEXT NOT NULL,
+			target_user_id INT,
+			payload_hash CHAR(64) NOT NULL,
+			request_id VARCHAR(64) NOT NULL DEFAULT '',
+			status_code INT NOT NULL,
+			outcome VARCHAR(20) NOT NULL,
+			created_at TIMESTAMP NOT NULL,
+			prev_hash CHAR(64) NOT NULL,
+			hash CHAR(64) NOT NULL UNIQUE
+		);
+
+		CREATE FUNCTION reject_admin_audit_log_change() RETURNS trigger AS $$
+		BEGIN
+			RAISE EXCEPTION 'the admin audit log is append-only';
+		END;
+		$$ LANGUAGE plpgsql;
+
+		CREATE TRIGGER admin_audit_log_append_only
+			BEFORE UPDATE OR DELETE ON admin_audit_log
+			FOR EACH ROW EXECUTE FUNCTION reject_admin_audit_log_change();
+
+		CREATE TRIGGER admin_audit_log_no_truncate
+			BEFORE TRUNCATE ON admin_audit_log
+			FOR EACH STATEMENT EXECUTE FUNCTION reject_admin_audit_log_change();
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create spend_limit_overrides table
+	_, err = db.Exec(`
+		CREATE TABLE spend_limit_overrides (
+			user_id INT PRIMARY KEY,
+			max_per_transaction NUMERIC(20, 2),
+			daily_cap NUMERIC(20, 2),
+			weekly_cap NUMERIC(20, 2),
+			max_spends_per_minute INT,
+			reason TEXT NOT NULL,
+			updated_by INT,
+			updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Create risk_reviews table
+	_, err = db.Exec(`
+		CREATE TABLE risk_reviews (
+			id SERIAL PRIMARY KEY,
+			operation VARCHAR(20) NOT NULL,
+			user_id INT NOT NULL,
+			principal_id INT NOT NULL,
+			principal_role VARCHAR(20) NOT NULL,
+			amount NUMERIC(20, 2) NOT NULL,
+			platform_amount NUMERIC(20, 2) NOT NULL,
+			matched_rules TEXT NOT NULL,
+			payload JSONB NOT NULL,
+			status VARCHAR(20) NOT NULL DEFAULT 'pending',
+			reviewed_by INT,
+			reviewed_at TIMESTAMP,
+			review_note TEXT,
+			result_error TEXT,
+			created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+		);
+	`)
+	if err != nil {
+		return err
+	}
+
+	// Insert test data - sample exchange rates
+	_, err = db.Exec(`
+		INSERT INTO exchange_rates (game_id, token_type, to_platform_ratio)
+		VALUES 
+			('game1', 'gold', 2.5),
+			('game2', 'gems', 5.0),
+			('game3', 'coins', 1.0);
+	`)
+
+	return err
+}
+
+// GetTestDB returns the test database instance for integration tests
+func GetTestDB() *sql.DB {
+	return db
+}
+
+// GetTestRepository returns a real postgres repository for integration tests
+func GetTestRepository(t *testing.T) repository.WalletRepository {
+	// Create observability for test
+	obs := observability.NewTestObservability()
+	
+	// Create repository with test DB
+	return repository.NewPostgresRepository(db, obs)
+}
+
+// ClearTestData deletes all test data between tests while preserving table structure
+func ClearTestData(t *testing.T) {
+	t.Helper()
+
+	// The audit log is append-only; its truncate trigger is switched off for the reset only. The statements
+	// run as one transaction, so the trigger is never left disabled.
+	_, err := db.Exec(`
+		ALTER TABLE admin_audit_log DISABLE TRIGGER admin_audit_log_no_truncate;
+		TRUNCATE wallet_logs, wallet_log_archive_balances, wallet_balance_lots, reverse_exchange_grants, wallets, spend_limit_overrides, risk_reviews, idempotency_keys, bulk_jobs, jobs, wallet_statements, wallet_balance_snapshots, report_daily_activity, report_daily_active_wallets, admin_audit_log RESTART IDENTITY CASCADE;
+		ALTER TABLE admin_audit_log ENABLE TRIGGER admin_audit_log_no_truncate;
+		UPDATE report_rollup_state SET last_xact_id = '0', last_log_id = 0, last_log_at = NULL;
+	`)
+	if err != nil {
+		t.Fatalf("Failed to clear test data: %v", err)
+	}
+}
+
+// CreateTestWallet creates a wallet for testing
+func CreateTestWallet(t *testing.T, userID int, balance float64) int {
+	t.Helper()
+
+	var walletID int
+	err := db.QueryRow(`
+		INSERT INTO wallets (user_id, balance, created_at)
+		VALUES ($1, $2, $3)
+		RETURNING id
+	`, userID, balance, time.Now()).Scan(&walletID)
+
+	if err != nil {
+		t.Fatalf("Failed to create test wallet: %v", err)
+	}
+
+	if balance > 0 {
+		_, err = db.Exec(`
+			INSERT INTO wallet_balance_lots (wallet_id, amount, remaining, source)
+			VALUES ($1, $2, $2, 'exchange')
+		`, walletID, balance)
+		if err != nil {
+			t.Fatalf("Failed to create test balance lot: %v", err)
+		}
+	}
+
+	return walletID
+}
@@ -5,14 +5,12 @@ import (
 	"testing"
 	"time"
 
-	"github.com/playconomy/wallet-service/internal/observability"
-	"github.com/playconomy/wallet-service/internal/repository"
+	"github.com/playconomy/wallet-service/internal/config"
 	"github.com/playconomy/wallet-service/internal/server/dto"
 	"github.com/playconomy/wallet-service/internal/service"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
-	"go.uber.org/zap"
 )
 
 func TestWalletServiceIntegration(t *testing.T) {
@@ -28,15 +26,10 @@ func TestWalletServiceIntegration(t *testing.T) {
 	testRepo := GetTestRepository(t)
 
 	// Create test observability
-	logger, _ := zap.NewDevelopment()
-	obs := &observability.Observability{
-		Logger:  &observability.Logger{Logger: logger},
-		Metrics: nil,
-		Tracer:  nil,
-	}
+	obs := service.GetTestObservability()
 
 	// Create wallet service with actual repository
-	var walletService service.WalletServiceInterface = service.NewWalletService(testRepo, obs)
+	var walletService service.WalletServiceInterface = service.NewWalletService(testRepo, nil, nil, nil, &config.Config{}, obs)
 
 	// Clear test data before each test
 	t.Run("GetWalletByUserID", func(t *testing.T) {
@@ -47,7 +40,7 @@ func TestWalletServiceIntegration(t *testing.T) {
 		CreateTestWallet(t, userID, 100.0)
 
 		// Test getting the wallet
-		wallet, err := walletService.GetWalletByUserID(ctx, userID)
+		wallet, err := walletService.GetWalletByUserID(ctx, userID, "")
 
 		// Assert
 		require.NoError(t, err)
@@ -57,7 +50,7 @@ func TestWalletServiceIntegration(t *testing.T) {
 
 		// Test non-existent wallet
 		nonExistentID := 999
-		wallet, err = walletService.GetWalletByUserID(ctx, nonExistentID)
+		wallet, err = walletService.GetWalletByUserID(ctx, nonExistentID, "")
 
 		require.NoError(t, err)
 		assert.Nil(t, wallet)
@@ -83,7 +76,7 @@ func TestWalletServiceIntegration(t *testing.T) {
 		assert.Equal(t, 250.0, newBalance) // 100 * 2.5
 
 		// Verify wallet was created with correct balance
-		wallet, err := walletService.GetWalletByUserID(ctx, req.UserID)
+		wallet, err := walletService.GetWalletByUserID(ctx, req.UserID, "")
 		require.NoError(t, err)
 		assert.NotNil(t, wallet)
 		assert.Equal(t, 250.0, wallet.Balance)
@@ -125,7 +118,7 @@ func TestWalletServiceIntegration(t *testing.T) {
 		assert.Equal(t, 300.0, newBalance) // 500 - 200
 
 		// Verify wallet was updated
-		wallet, err := walletService.GetWalletByUserID(ctx, userID)
+		wallet, err := walletService.GetWalletByUserID(ctx, userID, "")
 		require.NoError(t, err)
 		assert.NotNil(t, wallet)
 		assert.Equal(t, 300.0, wallet.Balance)
@@ -2,6 +2,7 @@ package utils
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -18,7 +19,7 @@ func ValidateStruct(s interface{}) error {
 		if validationErrors, ok := err.(validator.ValidationErrors); ok {
 			var errorMessages []string
 			for _, e := range validationErrors {
-				errorMessages = append(errorMessages, formatValidationError(e))
+				errorMessages = append(errorMessages, formatValidationError(e, e.Field()))
 			}
 			// Return first error for simplicity
 			if len(errorMessages) > 0 {
@@ -30,20 +31,44 @@ func ValidateStruct(s interface{}) error {
 	return nil
 }
 
-// formatValidationError formats validator.FieldError into a human-readable message
-func formatValidationError(e validator.FieldError) string {
+// ValidationProblems validates a struct using validator tags and returns a message for every invalid field.
+// Fields are named by their path below the struct, e.g. Database.Port.
+func ValidationProblems(s interface{}) ([]string, error) {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil, nil
+	}
+
+	validationErrors, ok := err.(validator.ValidationErrors)
+	if !ok {
+		return nil, err
+	}
+
+	problems := make([]string, 0, len(validationErrors))
+	for _, e := range validationErrors {
+		field := e.Namespace()
+		if i := strings.Index(field, "."); i >= 0 {
+			field = field[i+1:]
+		}
+		problems = append(problems, formatValidationError(e, field))
+	}
+	return problems, nil
+}
+
+// formatValidationError formats validator.FieldError into a human-readable message naming field
+func formatValidationError(e validator.FieldError, field string) string {
 	switch e.Tag() {
 	case "required":
-		return fmt.Sprintf("%s is required", e.Field())
+		return fmt.Sprintf("%s is required", field)
 	case "gt":
-		return fmt.Sprintf("%s must be greater than %s", e.Field(), e.Param())
+		return fmt.Sprintf("%s must be greater than %s", field, e.Param())
 	case "gte":
-		return fmt.Sprintf("%s must be greater than or equal to %s", e.Field(), e.Param())
+		return fmt.Sprintf("%s must be greater than or equal to %s", field, e.Param())
 	case "min":
-		return fmt.Sprintf("%s must be at least %s characters long", e.Field(), e.Param())
+		return fmt.Sprintf("%s must be at least %s characters long", field, e.Param())
 	case "oneof":
-		return fmt.Sprintf("%s must be one of: %s", e.Field(), e.Param())
+		return fmt.Sprintf("%s must be one of: %s", field, e.Param())
 	default:
-		return fmt.Sprintf("%s failed validation: %s", e.Field(), e.Tag())
+		return fmt.Sprintf("%s failed validation: %s", field, e.Tag())
 	}
 }
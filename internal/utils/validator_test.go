@@ -123,4 +123,33 @@ func TestValidator(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "ReferenceID")
 	})
+
+	t.Run("Validation Problems - Every Invalid Field", func(t *testing.T) {
+		req := &dto.ExchangeRequest{
+			UserID:    123,
+			GameID:    "game1",
+			TokenType: "gold",
+			Amount:    0.0,
+			Source:    "invalid",
+		}
+
+		problems, err := utils.ValidationProblems(req)
+		assert.NoError(t, err)
+		assert.Len(t, problems, 2)
+		assert.Contains(t, problems[0]+problems[1], "Amount")
+		assert.Contains(t, problems[0]+problems[1], "Source")
+	})
+
+	t.Run("Validation Problems - Valid Struct", func(t *testing.T) {
+		req := &dto.SpendRequest{
+			UserID:      123,
+			Amount:      50.0,
+			Reason:      "market_purchase",
+			ReferenceID: "ORDER-123",
+		}
+
+		problems, err := utils.ValidationProblems(req)
+		assert.NoError(t, err)
+		assert.Empty(t, problems)
+	})
 }